@@ -0,0 +1,151 @@
+// Package abuse provides shared abuse state for Verto. Bans decided by
+// one component (a rate limiter or quota, a honeypot) are recorded in a
+// shared Store and enforced for all routes by the Filter plugin, so a
+// client tripping one defence is locked out everywhere until the ban
+// expires. Administrators can inspect and lift bans through the
+// endpoints registered by Register.
+package abuse
+
+import (
+	"errors"
+	"fmt"
+	"github.com/boxtown/verto"
+	"github.com/boxtown/verto/plugins"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrNotFound is returned for keys that are not banned
+var ErrNotFound = errors.New("abuse: not found")
+
+// Entry is a ban of a client
+type Entry struct {
+	// Key identifies the client, usually its IP
+	Key string `json:"key"`
+
+	// Reason describes why the client was banned
+	Reason string `json:"reason"`
+
+	// Source identifies the component that banned the
+	// client (e.g. "honeypot" or "quota")
+	Source string `json:"source"`
+
+	// Created is the time the ban was decided
+	Created time.Time `json:"created"`
+
+	// Expires is the time the ban expires. A zero
+	// Expires never expires
+	Expires time.Time `json:"expires,omitempty"`
+}
+
+// Expired returns whether the ban has expired at now
+func (e *Entry) Expired(now time.Time) bool {
+	return !e.Expires.IsZero() && !now.Before(e.Expires)
+}
+
+// Ban bans key in store for ttl. A ttl of zero bans key indefinitely
+func Ban(store Store, key, source, reason string, ttl time.Duration) error {
+	now := time.Now().UTC()
+	entry := &Entry{Key: key, Reason: reason, Source: source, Created: now}
+	if ttl > 0 {
+		entry.Expires = now.Add(ttl)
+	}
+	return store.Ban(entry)
+}
+
+// Filter is a plugin that rejects requests from banned clients
+// with a 403 response. Bans with an expiry carry a Retry-After header.
+//
+// Example usage:
+//
+//	bans := abuse.NewMemoryStore()
+//	v.Use(abuse.NewFilter(bans))
+//	v.GetHandler("/wp-login.php", abuse.Honeypot(bans, 24*time.Hour))
+//	q.OnExceeded = abuse.BanOnExceeded(bans, time.Hour)
+type Filter struct {
+	// Core is the core functionality for plugins
+	plugins.Core
+
+	// Store is the shared abuse state
+	Store Store
+
+	// KeyFn identifies the client of a request.
+	// Defaults to the client IP
+	KeyFn func(c *verto.Context) string
+
+	// Proxies are the trusted reverse proxies used to find
+	// the client IP. Defaults to trusting no proxy
+	Proxies Proxies
+}
+
+// NewFilter returns a Filter plugin enforcing the bans in store
+func NewFilter(store Store) *Filter {
+	return &Filter{
+		Core:  plugins.Core{Id: "plugins.AbuseFilter"},
+		Store: store,
+	}
+}
+
+// Handle is called per web request to reject banned clients
+func (plugin *Filter) Handle(c *verto.Context, next http.HandlerFunc) {
+	plugin.Core.Handle(
+		func(c *verto.Context, next http.HandlerFunc) {
+			key := keyOf(c, plugin.KeyFn, plugin.Proxies)
+			entry, err := plugin.Store.Lookup(key)
+			if err != nil {
+				// Lookup failures should not take the API down
+				if err != ErrNotFound && c.Logger != nil {
+					c.Logger.Errorf("abuse: could not look up %s: %s", key, err.Error())
+				}
+				next(c.Response, c.Request)
+				return
+			}
+
+			if !entry.Expires.IsZero() {
+				retry := int64(time.Until(entry.Expires) / time.Second)
+				if retry < 1 {
+					retry = 1
+				}
+				c.Response.Header().Set("Retry-After", strconv.FormatInt(retry, 10))
+			}
+			c.Response.WriteHeader(http.StatusForbidden)
+			fmt.Fprint(c.Response, "Forbidden.")
+		}, c, next)
+}
+
+// Honeypot returns an http.Handler for trap routes that no legitimate
+// client requests. Clients requesting a trap route are banned for ttl
+// and receive a 404 response so the trap is not revealed. Clients are
+// identified by the remote address of the connection; use
+// Proxies.Honeypot behind reverse proxies
+func Honeypot(store Store, ttl time.Duration) http.Handler {
+	return Proxies(nil).Honeypot(store, ttl)
+}
+
+// BanOnExceeded returns a callback banning the IP of clients for ttl,
+// suitable for the OnExceeded hooks of rate limiting plugins such as
+// quota. Clients are identified by the remote address of the
+// connection; use Proxies.BanOnExceeded behind reverse proxies
+func BanOnExceeded(store Store, ttl time.Duration) func(key string, c *verto.Context) {
+	return Proxies(nil).BanOnExceeded(store, ttl)
+}
+
+// keyOf returns the client key of the request of c
+func keyOf(c *verto.Context, fn func(c *verto.Context) string, proxies Proxies) string {
+	if fn != nil {
+		return clientKey(fn(c))
+	}
+	return clientKey(proxies.ClientIP(c.Request))
+}
+
+// clientKey escapes client supplied keys that would otherwise
+// fall into the namespace of revocations so that clients cannot
+// forge revocations by getting themselves banned
+func clientKey(key string) string {
+	if strings.HasPrefix(key, revocationPrefix) {
+		return clientPrefix + key
+	}
+	return key
+}
@@ -0,0 +1,276 @@
+package abuse
+
+import (
+	"encoding/json"
+	"github.com/boxtown/verto"
+	"github.com/boxtown/verto/session"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMemoryStore(t *testing.T) {
+	defer func() {
+		err := recover()
+		if err != nil {
+			t.Errorf(err.(error).Error())
+		}
+	}()
+
+	err := "Failed memory store."
+
+	now := time.Date(2017, time.January, 1, 12, 0, 0, 0, time.UTC)
+	ms := NewMemoryStore()
+	ms.Now = func() time.Time { return now }
+	ms.Ban(&Entry{Key: "a", Expires: now.Add(time.Minute)})
+	ms.Ban(&Entry{Key: "b"})
+
+	if e, _ := ms.Lookup("a"); e == nil || e.Key != "a" {
+		t.Errorf(err)
+	}
+	if entries, _ := ms.List(); len(entries) != 2 {
+		t.Errorf(err)
+	}
+
+	// Test expiry
+	now = now.Add(time.Minute)
+	if _, e := ms.Lookup("a"); e != ErrNotFound {
+		t.Errorf(err)
+	}
+	if entries, _ := ms.List(); len(entries) != 1 || entries[0].Key != "b" {
+		t.Errorf(err)
+	}
+	if ms.Unban("a") != ErrNotFound || ms.Unban("b") != nil {
+		t.Errorf(err)
+	}
+}
+
+type testRedis struct {
+	values map[string]string
+}
+
+func (tr *testRedis) Set(key, value string, ttl time.Duration) error {
+	tr.values[key] = value
+	return nil
+}
+
+func (tr *testRedis) Get(key string) (string, bool, error) {
+	v, ok := tr.values[key]
+	return v, ok, nil
+}
+
+func (tr *testRedis) Del(key string) (bool, error) {
+	_, ok := tr.values[key]
+	delete(tr.values, key)
+	return ok, nil
+}
+
+func (tr *testRedis) Keys(prefix string) ([]string, error) {
+	keys := make([]string, 0)
+	for k := range tr.values {
+		if strings.HasPrefix(k, prefix) {
+			keys = append(keys, k)
+		}
+	}
+	return keys, nil
+}
+
+func TestRedisStore(t *testing.T) {
+	defer func() {
+		err := recover()
+		if err != nil {
+			t.Errorf(err.(error).Error())
+		}
+	}()
+
+	err := "Failed redis store."
+
+	client := &testRedis{values: make(map[string]string)}
+	rs := NewRedisStore(client)
+	Ban(rs, "10.0.0.1", "test", "testing", time.Hour)
+	if _, ok := client.values["verto:abuse:10.0.0.1"]; !ok {
+		t.Errorf(err)
+	}
+	if e, _ := rs.Lookup("10.0.0.1"); e == nil || e.Source != "test" || e.Expires.IsZero() {
+		t.Errorf(err)
+	}
+	if entries, _ := rs.List(); len(entries) != 1 {
+		t.Errorf(err)
+	}
+	if rs.Unban("10.0.0.1") != nil || rs.Unban("10.0.0.1") != ErrNotFound {
+		t.Errorf(err)
+	}
+}
+
+func TestFilter(t *testing.T) {
+	defer func() {
+		err := recover()
+		if err != nil {
+			t.Errorf(err.(error).Error())
+		}
+	}()
+
+	err := "Failed filter."
+
+	bans := NewMemoryStore()
+	v := verto.New()
+	v.Logger = &verto.NilLogger{}
+	v.Get("/data", func(c *verto.Context) (interface{}, error) {
+		return "data", nil
+	})
+	v.GetHandler("/wp-login.php", Honeypot(bans, time.Hour))
+	admin := verto.New()
+	admin.Logger = &verto.NilLogger{}
+	Register(admin, "/bans", bans)
+	v.Use(NewFilter(bans))
+	h := &verto.HttpHandler{v}
+	ah := &verto.HttpHandler{admin}
+
+	serve := func(h http.Handler, method, path, ip string) *httptest.ResponseRecorder {
+		r, _ := http.NewRequest(method, "http://test.com"+path, nil)
+		r.RemoteAddr = ip + ":1234"
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		return w
+	}
+
+	if serve(h, "GET", "/data", "10.0.0.1").Code != 200 {
+		t.Errorf(err)
+	}
+	if serve(h, "GET", "/wp-login.php", "10.0.0.1").Code != 404 {
+		t.Errorf(err)
+	}
+	w := serve(h, "GET", "/data", "10.0.0.1")
+	if w.Code != 403 || w.Header().Get("Retry-After") == "" {
+		t.Errorf(err)
+	}
+	if serve(h, "GET", "/data", "10.0.0.2").Code != 200 {
+		t.Errorf(err)
+	}
+
+	// Test admin endpoints
+	entries := make([]*Entry, 0)
+	json.Unmarshal(serve(ah, "GET", "/bans", "127.0.0.1").Body.Bytes(), &entries)
+	if len(entries) != 1 || entries[0].Key != "10.0.0.1" || entries[0].Source != "honeypot" {
+		t.Errorf(err)
+	}
+	if serve(ah, "GET", "/bans/10.0.0.1", "127.0.0.1").Code != 200 {
+		t.Errorf(err)
+	}
+	if serve(ah, "DELETE", "/bans/10.0.0.1", "127.0.0.1").Code != 204 {
+		t.Errorf(err)
+	}
+	if serve(ah, "DELETE", "/bans/10.0.0.1", "127.0.0.1").Code != 404 {
+		t.Errorf(err)
+	}
+	if serve(h, "GET", "/data", "10.0.0.1").Code != 200 {
+		t.Errorf(err)
+	}
+
+	// Test bans decided by quota callbacks
+	r, _ := http.NewRequest("GET", "http://test.com/data", nil)
+	r.RemoteAddr = "10.0.0.3:1234"
+	BanOnExceeded(bans, time.Hour)("key", verto.NewContext(nil, r, nil, nil))
+	if serve(h, "GET", "/data", "10.0.0.3").Code != 403 {
+		t.Errorf(err)
+	}
+
+	// Test forwarded addresses are ignored without trusted proxies
+	r, _ = http.NewRequest("GET", "http://test.com/wp-login.php", nil)
+	r.RemoteAddr = "10.0.0.4:1234"
+	r.Header.Set("X-Forwarded-For", "10.0.0.5")
+	h.ServeHTTP(httptest.NewRecorder(), r)
+	if _, e := bans.Lookup("10.0.0.5"); e != ErrNotFound {
+		t.Errorf(err)
+	}
+	if _, e := bans.Lookup("10.0.0.4"); e != nil {
+		t.Errorf(err)
+	}
+
+	// Test client keys cannot forge revocations
+	r, _ = http.NewRequest("GET", "http://test.com/wp-login.php", nil)
+	r.RemoteAddr = "10.0.0.6:1234"
+	r.Header.Set("X-Forwarded-For", "revoked:user:bob")
+	TrustProxies("10.0.0.6").Honeypot(bans, time.Hour).ServeHTTP(httptest.NewRecorder(), r)
+	if _, e := bans.Lookup("revoked:user:bob"); e != ErrNotFound {
+		t.Errorf(err)
+	}
+	if _, e := bans.Lookup("client:revoked:user:bob"); e != nil {
+		t.Errorf(err)
+	}
+}
+
+func TestProxiesClientIP(t *testing.T) {
+	defer func() {
+		err := recover()
+		if err != nil {
+			t.Errorf(err.(error).Error())
+		}
+	}()
+
+	err := "Failed proxies client IP."
+
+	ip := func(proxies Proxies, remote, forwarded string) string {
+		r, _ := http.NewRequest("GET", "http://test.com/", nil)
+		r.RemoteAddr = remote
+		if forwarded != "" {
+			r.Header.Set("X-Forwarded-For", forwarded)
+		}
+		return proxies.ClientIP(r)
+	}
+
+	proxies := TrustProxies("10.0.0.0/8", "::1")
+	if ip(nil, "1.2.3.4:80", "5.6.7.8") != "1.2.3.4" {
+		t.Errorf(err)
+	}
+	if ip(proxies, "1.2.3.4:80", "5.6.7.8") != "1.2.3.4" {
+		t.Errorf(err)
+	}
+	if ip(proxies, "10.0.0.1:80", "5.6.7.8") != "5.6.7.8" {
+		t.Errorf(err)
+	}
+	if ip(proxies, "[::1]:80", "9.9.9.9, 5.6.7.8, 10.0.0.2") != "5.6.7.8" {
+		t.Errorf(err)
+	}
+	if ip(proxies, "10.0.0.1:80", "") != "10.0.0.1" {
+		t.Errorf(err)
+	}
+}
+
+func TestRevocationList(t *testing.T) {
+	defer func() {
+		err := recover()
+		if err != nil {
+			t.Errorf(err.(error).Error())
+		}
+	}()
+
+	err := "Failed revocation list."
+
+	store := NewMemoryStore()
+	revocations := &session.Revocations{List: NewRevocationList(store)}
+	issued := time.Now()
+	time.Sleep(time.Millisecond)
+	if revocations.RevokeUser("bob") != nil || revocations.RevokeSession("a") != nil {
+		t.Fatalf(err)
+	}
+
+	// Test revocations apply by session id and by user
+	if !revocations.Revoked("a", nil, time.Now()) {
+		t.Errorf(err)
+	}
+	bob := map[interface{}]interface{}{session.USERKEY: "bob"}
+	if !revocations.Revoked("b", bob, issued) || revocations.Revoked("b", bob, time.Now()) {
+		t.Errorf(err)
+	}
+
+	// Test revocations do not collide with bans
+	if _, e := store.Lookup("bob"); e != ErrNotFound {
+		t.Errorf(err)
+	}
+	if e, _ := store.Lookup("revoked:user:bob"); e == nil || e.Source != "session" {
+		t.Errorf(err)
+	}
+}
@@ -0,0 +1,65 @@
+package abuse
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/boxtown/verto"
+	"github.com/boxtown/verto/mux"
+	"net/http"
+	"strings"
+)
+
+// Register registers ban management endpoints under prefix on v:
+//
+//	GET    prefix        lists active bans
+//	GET    prefix/{key}  returns the ban of key
+//	DELETE prefix/{key}  lifts the ban of key
+//
+// The endpoints should be protected, e.g. by admin groups with
+// authorization plugins covering prefix.
+func Register(v *verto.Verto, prefix string, store Store) {
+	prefix = strings.TrimRight(prefix, "/")
+	v.AddHandler("GET", prefix, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		entries, err := store.List()
+		if err != nil {
+			writeError(w, http.StatusInternalServerError)
+			return
+		}
+		writeJSON(w, entries)
+	}))
+	v.AddHandler("GET", prefix+"/{key}", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		entry, err := store.Lookup(mux.Param(r, "key"))
+		if err != nil {
+			writeResult(w, err)
+			return
+		}
+		writeJSON(w, entry)
+	}))
+	v.AddHandler("DELETE", prefix+"/{key}", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		writeResult(w, store.Unban(mux.Param(r, "key")))
+	}))
+}
+
+// writes a 204 response or the error response for err
+func writeResult(w http.ResponseWriter, err error) {
+	switch err {
+	case nil:
+		w.WriteHeader(http.StatusNoContent)
+	case ErrNotFound:
+		writeError(w, http.StatusNotFound)
+	default:
+		writeError(w, http.StatusInternalServerError)
+	}
+}
+
+// writes v as a JSON response
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(v)
+}
+
+// writes the error response for status
+func writeError(w http.ResponseWriter, status int) {
+	w.WriteHeader(status)
+	fmt.Fprint(w, http.StatusText(status)+".")
+}
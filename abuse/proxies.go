@@ -0,0 +1,106 @@
+package abuse
+
+import (
+	"fmt"
+	"github.com/boxtown/verto"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Proxies is a list of trusted reverse proxy networks. Bans are keyed
+// on the remote address of the connection unless the connection comes
+// from a trusted proxy, in which case the X-Forwarded-For header is
+// walked from the right to the first address that is not a trusted
+// proxy. The zero Proxies trusts no proxy and never reads the header,
+// which clients can set to anything.
+//
+// Example usage:
+//
+//	proxies := abuse.TrustProxies("10.0.0.0/8")
+//	filter := abuse.NewFilter(bans)
+//	filter.Proxies = proxies
+//	v.GetHandler("/wp-login.php", proxies.Honeypot(bans, 24*time.Hour))
+type Proxies []*net.IPNet
+
+// TrustProxies returns Proxies trusting the passed in networks. Networks
+// may be CIDR ranges or single IP addresses. TrustProxies panics if a
+// network cannot be parsed
+func TrustProxies(networks ...string) Proxies {
+	proxies := make(Proxies, len(networks))
+	for i, n := range networks {
+		if !strings.Contains(n, "/") {
+			if ip := net.ParseIP(n); ip != nil && ip.To4() != nil {
+				n += "/32"
+			} else {
+				n += "/128"
+			}
+		}
+		_, ipnet, err := net.ParseCIDR(n)
+		if err != nil {
+			panic("TrustProxies: " + err.Error())
+		}
+		proxies[i] = ipnet
+	}
+	return proxies
+}
+
+// ClientIP returns the IP of the client of r
+func (p Proxies) ClientIP(r *http.Request) string {
+	ip, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		ip = r.RemoteAddr
+	}
+	if !p.trusted(ip) {
+		return ip
+	}
+
+	hops := strings.Split(r.Header.Get("X-Forwarded-For"), ",")
+	for i := len(hops) - 1; i >= 0; i-- {
+		hop := strings.TrimSpace(hops[i])
+		if hop == "" {
+			break
+		}
+		ip = hop
+		if !p.trusted(ip) {
+			break
+		}
+	}
+	return ip
+}
+
+// Honeypot is like the package level Honeypot but
+// identifies clients behind the trusted proxies
+func (p Proxies) Honeypot(store Store, ttl time.Duration) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		Ban(store, clientKey(p.ClientIP(r)), "honeypot", "requested "+r.URL.Path, ttl)
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Fprint(w, "Not Found.")
+	})
+}
+
+// BanOnExceeded is like the package level BanOnExceeded
+// but identifies clients behind the trusted proxies
+func (p Proxies) BanOnExceeded(store Store, ttl time.Duration) func(key string, c *verto.Context) {
+	return func(key string, c *verto.Context) {
+		ip := clientKey(p.ClientIP(c.Request))
+		if err := Ban(store, ip, "quota", "exceeded quota of "+key, ttl); err != nil && c.Logger != nil {
+			c.Logger.Errorf("abuse: could not ban %s: %s", ip, err.Error())
+		}
+	}
+}
+
+// trusted returns whether ip is a trusted proxy
+func (p Proxies) trusted(ip string) bool {
+	parsed := net.ParseIP(ip)
+	if parsed == nil {
+		return false
+	}
+	for _, ipnet := range p {
+		if ipnet.Contains(parsed) {
+			return true
+		}
+	}
+	return false
+}
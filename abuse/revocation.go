@@ -0,0 +1,43 @@
+package abuse
+
+import (
+	"time"
+)
+
+// revocationPrefix is prepended to revoked keys. Client keys
+// of bans in this namespace are escaped with clientPrefix so
+// that revocations never match the client keys of bans
+const (
+	revocationPrefix = "revoked:"
+	clientPrefix     = "client:"
+)
+
+// RevocationList is a session.RevocationList keeping revocations
+// in a shared Store alongside bans. Revocations are listed by the
+// endpoints registered by Register and can be lifted like bans.
+type RevocationList struct {
+	// Store is the shared abuse-state store
+	Store Store
+}
+
+// NewRevocationList returns a RevocationList backed by store
+func NewRevocationList(store Store) *RevocationList {
+	return &RevocationList{Store: store}
+}
+
+// Revoke records the revocation of key for ttl
+func (rl *RevocationList) Revoke(key string, ttl time.Duration) error {
+	return Ban(rl.Store, revocationPrefix+key, "session", "revoked", ttl)
+}
+
+// RevokedAt returns the time key was revoked
+func (rl *RevocationList) RevokedAt(key string) (time.Time, bool, error) {
+	entry, err := rl.Store.Lookup(revocationPrefix + key)
+	if err == ErrNotFound {
+		return time.Time{}, false, nil
+	}
+	if err != nil {
+		return time.Time{}, false, err
+	}
+	return entry.Created, true, nil
+}
@@ -0,0 +1,211 @@
+package abuse
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Store is the interface for shared abuse-state storage backends.
+// Bans expire once their TTL elapses. Implementations backed by shared
+// storage (e.g. Redis) enforce bans across multiple instances
+type Store interface {
+	// Ban stores entry, replacing any existing ban of its key
+	Ban(entry *Entry) error
+
+	// Lookup returns the active ban of key or ErrNotFound
+	Lookup(key string) (*Entry, error)
+
+	// Unban removes the ban of key or returns ErrNotFound
+	Unban(key string) error
+
+	// List returns all active bans
+	List() ([]*Entry, error)
+}
+
+// MemoryStore is an in-memory Store. Expired bans are pruned
+// lazily. MemoryStore is thread-safe
+type MemoryStore struct {
+	// Now returns the current time. Defaults to time.Now
+	Now func() time.Time
+
+	entries map[string]*Entry
+	mutex   *sync.RWMutex
+}
+
+// NewMemoryStore returns an empty MemoryStore
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		entries: make(map[string]*Entry),
+		mutex:   &sync.RWMutex{},
+	}
+}
+
+// Ban stores a copy of entry
+func (ms *MemoryStore) Ban(entry *Entry) error {
+	ms.mutex.Lock()
+	defer ms.mutex.Unlock()
+
+	e := *entry
+	ms.entries[e.Key] = &e
+	ms.prune()
+	return nil
+}
+
+// Lookup returns a copy of the active ban of key
+func (ms *MemoryStore) Lookup(key string) (*Entry, error) {
+	ms.mutex.RLock()
+	defer ms.mutex.RUnlock()
+
+	e, ok := ms.entries[key]
+	if !ok || e.Expired(ms.now()) {
+		return nil, ErrNotFound
+	}
+	entry := *e
+	return &entry, nil
+}
+
+// Unban removes the ban of key
+func (ms *MemoryStore) Unban(key string) error {
+	ms.mutex.Lock()
+	defer ms.mutex.Unlock()
+
+	e, ok := ms.entries[key]
+	if !ok || e.Expired(ms.now()) {
+		return ErrNotFound
+	}
+	delete(ms.entries, key)
+	return nil
+}
+
+// List returns copies of all active bans ordered by key
+func (ms *MemoryStore) List() ([]*Entry, error) {
+	ms.mutex.RLock()
+	defer ms.mutex.RUnlock()
+
+	now := ms.now()
+	entries := make([]*Entry, 0, len(ms.entries))
+	for _, e := range ms.entries {
+		if !e.Expired(now) {
+			entry := *e
+			entries = append(entries, &entry)
+		}
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Key < entries[j].Key })
+	return entries, nil
+}
+
+// prune removes expired bans. The caller must hold the write lock
+func (ms *MemoryStore) prune() {
+	now := ms.now()
+	for key, e := range ms.entries {
+		if e.Expired(now) {
+			delete(ms.entries, key)
+		}
+	}
+}
+
+func (ms *MemoryStore) now() time.Time {
+	if ms.Now == nil {
+		return time.Now()
+	}
+	return ms.Now()
+}
+
+// RedisClient is the subset of a Redis client used by RedisStore.
+// It is satisfied by thin adapters around common Redis libraries
+type RedisClient interface {
+	// Set stores value at key expiring after ttl
+	Set(key, value string, ttl time.Duration) error
+
+	// Get returns the value at key and whether it exists
+	Get(key string) (string, bool, error)
+
+	// Del deletes key and returns whether it existed
+	Del(key string) (bool, error)
+
+	// Keys returns all keys starting with prefix
+	Keys(prefix string) ([]string, error)
+}
+
+// RedisStore is a Store persisting bans as JSON values in Redis.
+// Expiry is delegated to Redis key TTLs
+type RedisStore struct {
+	// Client is the Redis client
+	Client RedisClient
+
+	// Prefix is prepended to the Redis keys of bans.
+	// Defaults to "verto:abuse:"
+	Prefix string
+}
+
+// NewRedisStore returns a RedisStore using client
+func NewRedisStore(client RedisClient) *RedisStore {
+	return &RedisStore{Client: client, Prefix: "verto:abuse:"}
+}
+
+// Ban stores entry with its remaining TTL
+func (rs *RedisStore) Ban(entry *Entry) error {
+	b, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	var ttl time.Duration
+	if !entry.Expires.IsZero() {
+		if ttl = time.Until(entry.Expires); ttl <= 0 {
+			return nil
+		}
+	}
+	return rs.Client.Set(rs.Prefix+entry.Key, string(b), ttl)
+}
+
+// Lookup returns the active ban of key
+func (rs *RedisStore) Lookup(key string) (*Entry, error) {
+	value, ok, err := rs.Client.Get(rs.Prefix + key)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, ErrNotFound
+	}
+	entry := &Entry{}
+	if err := json.Unmarshal([]byte(value), entry); err != nil {
+		return nil, err
+	}
+	return entry, nil
+}
+
+// Unban removes the ban of key
+func (rs *RedisStore) Unban(key string) error {
+	ok, err := rs.Client.Del(rs.Prefix + key)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// List returns all active bans ordered by key
+func (rs *RedisStore) List() ([]*Entry, error) {
+	keys, err := rs.Client.Keys(rs.Prefix)
+	if err != nil {
+		return nil, err
+	}
+	entries := make([]*Entry, 0, len(keys))
+	for _, key := range keys {
+		entry, err := rs.Lookup(strings.TrimPrefix(key, rs.Prefix))
+		if err == ErrNotFound {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Key < entries[j].Key })
+	return entries, nil
+}
@@ -0,0 +1,218 @@
+package verto
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/boxtown/verto/mux"
+	"net/http"
+	"strings"
+)
+
+// AdminKey is the route metadata key marking admin API routes.
+// Admin routes keep being served in maintenance mode
+const AdminKey = "verto.admin"
+
+// Audit actions recorded by the admin API
+const (
+	AuditDrain       = "drain"
+	AuditMaintenance = "maintenance"
+	AuditLogLevel    = "log.level"
+	AuditReload      = "config.reload"
+)
+
+// admin is the configuration of an enabled admin API
+type admin struct {
+	prefix string
+	access []Plugin
+}
+
+// operations is the operational state of a Verto
+// instance shared with its staged route tables
+type operations struct {
+	maintenance bool
+	draining    bool
+}
+
+// RouteInfo describes a registered route
+type RouteInfo struct {
+	Method string `json:"method"`
+	Path   string `json:"path"`
+	Name   string `json:"name,omitempty"`
+	Host   string `json:"host,omitempty"`
+}
+
+// EnableAdmin mounts the admin control API under prefix. The API is served
+// as regular groups behind the passed in access plugins, which should
+// authenticate administrators. If no access plugins are given, only loopback
+// clients are allowed. The API consists of:
+//
+//	GET    <prefix>/routes       the route table as JSON
+//	GET    <prefix>/audit        the audit trail as JSON
+//	POST   <prefix>/shutdown     stops the server
+//	POST   <prefix>/drain        reports not ready so that traffic moves away
+//	GET    <prefix>/log-level    the logger's level
+//	PUT    <prefix>/log-level    sets the logger's level from the level parameter
+//	GET    <prefix>/maintenance  whether maintenance mode is enabled
+//	PUT    <prefix>/maintenance  enables maintenance mode
+//	DELETE <prefix>/maintenance  disables maintenance mode
+//	POST   <prefix>/reload       calls Verto's Reload function
+//
+// Example usage:
+//
+//	v.EnableAdmin("/admin", apikeys.New(v.Injections, m), authz.Require("admin"))
+func (v *Verto) EnableAdmin(prefix string, access ...Plugin) {
+	prefix = strings.TrimRight(prefix, "/")
+	if len(access) == 0 {
+		access = []Plugin{AllowIPs("127.0.0.0/8", "::1")}
+	}
+	v.admin = &admin{prefix: prefix, access: access}
+	v.registerAdmin(v.root(), v.admin)
+}
+
+// Drain marks Verto as draining. A draining instance reports
+// not ready so that load balancers move traffic away before
+// it is stopped, but keeps serving requests
+func (v *Verto) Drain() {
+	v.mutex.Lock()
+	v.ops.draining = true
+	v.mutex.Unlock()
+
+	v.audit(AuditDrain, AuditSourceAPI, "draining")
+}
+
+// SetMaintenance enables or disables maintenance mode. In maintenance
+// mode all routes except admin and management routes respond with
+// 503 Service Unavailable
+func (v *Verto) SetMaintenance(enabled bool) {
+	v.mutex.Lock()
+	v.ops.maintenance = enabled
+	v.mutex.Unlock()
+
+	v.audit(AuditMaintenance, AuditSourceAPI, fmt.Sprintf("maintenance: %t", enabled))
+}
+
+// Maintenance returns whether maintenance mode is enabled
+func (v *Verto) Maintenance() bool {
+	v.mutex.RLock()
+	defer v.mutex.RUnlock()
+
+	return v.ops.maintenance
+}
+
+// registerAdmin registers the admin API configured by cfg
+// on v's route table controlling the instance owner
+func (v *Verto) registerAdmin(owner *Verto, cfg *admin) {
+	methods := []string{"GET", "POST", "PUT", "DELETE"}
+	groups := make(map[string]*Group, len(methods))
+	for _, method := range methods {
+		groups[method] = v.Group(method, cfg.prefix)
+		for _, plugin := range cfg.access {
+			groups[method].Use(plugin)
+		}
+	}
+	add := func(method, path string, handler http.HandlerFunc) {
+		groups[method].AddHandler(path, handler).Meta(AdminKey, true)
+	}
+
+	add("GET", "/routes", func(w http.ResponseWriter, r *http.Request) {
+		routes := owner.Routes()
+		infos := make([]RouteInfo, len(routes))
+		for i, route := range routes {
+			infos[i] = RouteInfo{route.Method(), route.Path(), route.Name(), route.Host()}
+		}
+		writeAdminJSON(w, infos)
+	})
+	add("GET", "/audit", func(w http.ResponseWriter, r *http.Request) {
+		if owner.Audit == nil {
+			writeAdminJSON(w, []AuditEntry{})
+			return
+		}
+		owner.Audit.ServeHTTP(w, r)
+	})
+	add("POST", "/shutdown", func(w http.ResponseWriter, r *http.Request) {
+		owner.audit(AuditShutdown, GetIP(r), "shutdown requested")
+		w.WriteHeader(http.StatusAccepted)
+		fmt.Fprint(w, "Shutting down.")
+		owner.Stop()
+	})
+	add("POST", "/drain", func(w http.ResponseWriter, r *http.Request) {
+		owner.Drain()
+		w.WriteHeader(http.StatusAccepted)
+		fmt.Fprint(w, "Draining.")
+	})
+
+	logLevel := func(w http.ResponseWriter, r *http.Request) {
+		logger, ok := owner.Logger.(LevelLogger)
+		if !ok {
+			writeAdminError(w, http.StatusNotImplemented)
+			return
+		}
+		if r.Method == "PUT" {
+			level, err := ParseLevel(r.FormValue("level"))
+			if err != nil {
+				writeAdminError(w, http.StatusBadRequest)
+				return
+			}
+			logger.SetLevel(level)
+			owner.audit(AuditLogLevel, AuditSourceAPI, "log level: "+level.String())
+		}
+		writeAdminJSON(w, map[string]string{"level": logger.Level().String()})
+	}
+	add("GET", "/log-level", logLevel)
+	add("PUT", "/log-level", logLevel)
+
+	maintenance := func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case "PUT":
+			owner.SetMaintenance(true)
+		case "DELETE":
+			owner.SetMaintenance(false)
+		}
+		writeAdminJSON(w, map[string]bool{"enabled": owner.Maintenance()})
+	}
+	add("GET", "/maintenance", maintenance)
+	add("PUT", "/maintenance", maintenance)
+	add("DELETE", "/maintenance", maintenance)
+
+	add("POST", "/reload", func(w http.ResponseWriter, r *http.Request) {
+		if owner.Reload == nil {
+			writeAdminError(w, http.StatusNotImplemented)
+			return
+		}
+		if err := owner.Reload(); err != nil {
+			if owner.Logger != nil {
+				owner.Logger.Errorf("admin: reload failed: %s", err.Error())
+			}
+			writeAdminError(w, http.StatusInternalServerError)
+			return
+		}
+		owner.audit(AuditReload, AuditSourceAPI, "configuration reloaded")
+		fmt.Fprint(w, "Reloaded.")
+	})
+}
+
+// serveMaintenance responds with 503 if maintenance mode is enabled
+// and r is not for an admin route. Returns whether a response was written
+func (v *Verto) serveMaintenance(w http.ResponseWriter, r *http.Request) bool {
+	if v.parent != nil || !v.Maintenance() {
+		return false
+	}
+	if route := mux.CurrentRoute(r); route != nil {
+		if _, ok := route.Meta(AdminKey); ok {
+			return false
+		}
+	}
+	w.Header().Set("Retry-After", "60")
+	writeAdminError(w, http.StatusServiceUnavailable)
+	return true
+}
+
+func writeAdminJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeAdminError(w http.ResponseWriter, status int) {
+	w.WriteHeader(status)
+	fmt.Fprint(w, http.StatusText(status)+".")
+}
@@ -0,0 +1,113 @@
+package verto
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestAdmin(t *testing.T) {
+	defer func() {
+		err := recover()
+		if err != nil {
+			t.Errorf(err.(error).Error())
+		}
+	}()
+
+	err := "Failed admin."
+
+	v := New()
+	logger := NewLogger()
+	v.Logger = logger
+	reloads := 0
+	v.Reload = func() error {
+		reloads++
+		if reloads > 1 {
+			return errors.New("bad config")
+		}
+		return nil
+	}
+	v.Get("/users", func(c *Context) (interface{}, error) {
+		return "users", nil
+	}).Name("users")
+	v.EnableAdmin("/admin/")
+	h := &HttpHandler{v}
+
+	serve := func(method, path, remote string) *httptest.ResponseRecorder {
+		r, _ := http.NewRequest(method, "http://test.com"+path, nil)
+		r.RemoteAddr = remote
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		return w
+	}
+	local := "127.0.0.1:1"
+
+	// Test access is restricted and the old shutdown route is gone
+	if serve("GET", "/admin/routes", "10.0.0.1:1").Code != 403 || serve("GET", "/shutdown", local).Code != 404 {
+		t.Errorf(err)
+	}
+
+	routes := make([]RouteInfo, 0)
+	json.Unmarshal(serve("GET", "/admin/routes", local).Body.Bytes(), &routes)
+	found := false
+	for _, route := range routes {
+		found = found || (route == RouteInfo{"GET", "/users", "users", ""})
+	}
+	if !found {
+		t.Errorf(err)
+	}
+
+	// Test log level
+	if w := serve("PUT", "/admin/log-level?level=warn", local); w.Code != 200 || logger.Level() != LevelWarn {
+		t.Errorf(err)
+	}
+	if serve("PUT", "/admin/log-level?level=loud", local).Code != 400 {
+		t.Errorf(err)
+	}
+
+	// Test maintenance mode spares admin routes
+	serve("PUT", "/admin/maintenance", local)
+	if w := serve("GET", "/users", local); w.Code != 503 || w.Header().Get("Retry-After") == "" {
+		t.Errorf(err)
+	}
+	if w := serve("GET", "/admin/maintenance", local); w.Code != 200 || w.Body.String() != "{\"enabled\":true}\n" {
+		t.Errorf(err)
+	}
+	serve("DELETE", "/admin/maintenance", local)
+	if serve("GET", "/users", local).Code != 200 {
+		t.Errorf(err)
+	}
+
+	// Test drain
+	v.setReady(true)
+	if serve("POST", "/admin/drain", local).Code != 202 || v.Ready() {
+		t.Errorf(err)
+	}
+
+	// Test reload
+	if serve("POST", "/admin/reload", local).Code != 200 || serve("POST", "/admin/reload", local).Code != 500 {
+		t.Errorf(err)
+	}
+
+	// Test audit trail records admin actions
+	entries := make([]AuditEntry, 0)
+	json.Unmarshal(serve("GET", "/admin/audit", local).Body.Bytes(), &entries)
+	actions := make(map[string]bool)
+	for _, e := range entries {
+		actions[e.Action] = true
+	}
+	if !actions[AuditLogLevel] || !actions[AuditMaintenance] || !actions[AuditDrain] || !actions[AuditReload] {
+		t.Errorf(err)
+	}
+
+	// Test the admin API survives route table swaps
+	v.Swap(v.Stage())
+	if serve("GET", "/users", local).Code != 404 || serve("GET", "/admin/maintenance", local).Code != 200 {
+		t.Errorf(err)
+	}
+	if serve("POST", "/admin/shutdown", local).Code != 202 {
+		t.Errorf(err)
+	}
+}
@@ -0,0 +1,128 @@
+package verto
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net"
+	"net/http"
+	"strings"
+	"sync/atomic"
+)
+
+// Anonymizer scrubs personal data from access log and audit records.
+// Client IPs can be truncated to their network prefix or replaced with a
+// salted hash, and configured headers and fields are dropped entirely.
+// A nil Anonymizer leaves records untouched.
+//
+// Example usage:
+//
+//	a := &verto.Anonymizer{TruncateIPs: true, DropHeaders: []string{"Cookie"}}
+//	v.Audit.Anonymizer = a
+//	v.Use(accesslog.New(os.Stdout).Anonymize(a))
+type Anonymizer struct {
+	// TruncateIPs zeroes the host part of client IPs (the last
+	// octet of IPv4 and the last 80 bits of IPv6 addresses)
+	TruncateIPs bool
+
+	// HashIPs replaces client IPs with a salted hash so that
+	// requests by the same client can still be correlated.
+	// Hashing is applied after truncation
+	HashIPs bool
+
+	// Salt is mixed into IP hashes. It should be secret and
+	// rotated regularly
+	Salt string
+
+	// DropHeaders lists the headers removed from records
+	DropHeaders []string
+
+	// DropFields lists the record fields removed from records
+	// (e.g. "user_agent" or "query")
+	DropFields []string
+}
+
+// IP returns the anonymized form of ip. Values that are not IP
+// addresses (e.g. audit sources such as "api") are returned as is.
+// Comma separated X-Forwarded-For lists are anonymized per address
+func (a *Anonymizer) IP(ip string) string {
+	if a == nil || (!a.TruncateIPs && !a.HashIPs) {
+		return ip
+	}
+	if strings.Contains(ip, ",") {
+		parts := strings.Split(ip, ",")
+		for i, part := range parts {
+			parts[i] = a.IP(strings.TrimSpace(part))
+		}
+		return strings.Join(parts, ", ")
+	}
+
+	parsed := net.ParseIP(ip)
+	if parsed == nil {
+		return ip
+	}
+	if a.TruncateIPs {
+		if v4 := parsed.To4(); v4 != nil {
+			parsed = v4.Mask(net.CIDRMask(24, 32))
+		} else {
+			parsed = parsed.Mask(net.CIDRMask(48, 128))
+		}
+	}
+	if !a.HashIPs {
+		return parsed.String()
+	}
+	sum := sha256.Sum256([]byte(a.Salt + parsed.String()))
+	return hex.EncodeToString(sum[:8])
+}
+
+// Header returns a copy of h without the dropped headers
+func (a *Anonymizer) Header(h http.Header) http.Header {
+	clone := h.Clone()
+	if a == nil {
+		return clone
+	}
+	for _, name := range a.DropHeaders {
+		clone.Del(name)
+	}
+	return clone
+}
+
+// Drops returns whether field is dropped from records
+func (a *Anonymizer) Drops(field string) bool {
+	if a == nil {
+		return false
+	}
+	for _, f := range a.DropFields {
+		if strings.EqualFold(f, field) {
+			return true
+		}
+	}
+	return false
+}
+
+// WithLogFlag returns a copy of r carrying a do-not-log flag that
+// handlers can set with DoNotLog. Logging plugins attach the flag
+// before calling the rest of the chain and consult it with
+// LogSuppressed once the request is handled. The flag also
+// carries the promoted LogFields of the request
+func WithLogFlag(r *http.Request) *http.Request {
+	if requestLogState(r) != nil {
+		return r
+	}
+	return withLogState(r)
+}
+
+// DoNotLog flags the request of c as sensitive so that access
+// logging plugins do not record it. DoNotLog has no effect if no
+// logging plugin attached a flag to the request
+func DoNotLog(c *Context) {
+	if state := requestLogState(c.Request); state != nil {
+		atomic.StoreInt32(&state.suppressed, 1)
+	}
+}
+
+// LogSuppressed returns whether a handler flagged r
+// with DoNotLog
+func LogSuppressed(r *http.Request) bool {
+	state := requestLogState(r)
+	return state != nil && atomic.LoadInt32(&state.suppressed) == 1
+}
@@ -0,0 +1,84 @@
+package verto
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestAnonymizer(t *testing.T) {
+	defer func() {
+		err := recover()
+		if err != nil {
+			t.Errorf(err.(error).Error())
+		}
+	}()
+
+	err := "Failed anonymizer."
+
+	var none *Anonymizer
+	if none.IP("10.0.0.1") != "10.0.0.1" || none.Drops("ip") {
+		t.Errorf(err)
+	}
+
+	a := &Anonymizer{TruncateIPs: true}
+	if a.IP("192.168.1.77") != "192.168.1.0" || a.IP("2001:db8:1:2:3::4") != "2001:db8:1::" {
+		t.Errorf(err)
+	}
+	if a.IP("10.0.0.9, 10.0.1.9") != "10.0.0.0, 10.0.1.0" || a.IP(AuditSourceAPI) != AuditSourceAPI {
+		t.Errorf(err)
+	}
+
+	// Test hashes are stable, salted and applied after truncation
+	h := &Anonymizer{TruncateIPs: true, HashIPs: true, Salt: "s"}
+	if h.IP("10.0.0.1") != h.IP("10.0.0.2") || h.IP("10.0.0.1") == "10.0.0.0" {
+		t.Errorf(err)
+	}
+	if (&Anonymizer{HashIPs: true, Salt: "t"}).IP("10.0.0.0") == h.IP("10.0.0.1") {
+		t.Errorf(err)
+	}
+
+	d := &Anonymizer{DropHeaders: []string{"cookie"}, DropFields: []string{"Query"}}
+	header := http.Header{"Cookie": {"a"}, "Accept": {"b"}}
+	if d.Header(header).Get("Cookie") != "" || d.Header(header).Get("Accept") != "b" || header.Get("Cookie") != "a" {
+		t.Errorf(err)
+	}
+	if !d.Drops("query") || d.Drops("ip") {
+		t.Errorf(err)
+	}
+
+	// Test audit sources are anonymized
+	trail := NewAuditTrail(10)
+	trail.Anonymizer = a
+	trail.Record(AuditShutdown, "203.0.113.5", "shutdown requested")
+	if trail.Entries()[0].Source != "203.0.113.0" {
+		t.Errorf(err)
+	}
+}
+
+func TestDoNotLog(t *testing.T) {
+	defer func() {
+		err := recover()
+		if err != nil {
+			t.Errorf(err.(error).Error())
+		}
+	}()
+
+	err := "Failed do not log."
+
+	r, _ := http.NewRequest("GET", "http://test.com", nil)
+	c := NewContext(nil, r, nil, nil)
+	DoNotLog(c)
+	if LogSuppressed(r) {
+		t.Errorf(err)
+	}
+
+	r = WithLogFlag(r)
+	if WithLogFlag(r) != r || LogSuppressed(r) {
+		t.Errorf(err)
+	}
+	c = NewContext(nil, r, nil, nil)
+	DoNotLog(c)
+	if !LogSuppressed(r) {
+		t.Errorf(err)
+	}
+}
@@ -0,0 +1,64 @@
+package verto
+
+import (
+	"github.com/boxtown/verto/mux"
+	"net/http"
+)
+
+// Endpoints is a set of Endpoints registered together, such as
+// the per-method Endpoints of a route registered with Any. Plugins
+// and metadata added to Endpoints are added to each Endpoint
+type Endpoints []*Endpoint
+
+// Use adds plugin onto the chain of plugins of each Endpoint
+func (eps Endpoints) Use(plugin Plugin) Endpoints {
+	for i, ep := range eps {
+		eps[i] = ep.Use(plugin)
+	}
+	return eps
+}
+
+// UsePluginHandler adds handler onto the chain of plugins of each Endpoint
+func (eps Endpoints) UsePluginHandler(handler mux.PluginHandler) Endpoints {
+	for i, ep := range eps {
+		eps[i] = ep.UsePluginHandler(handler)
+	}
+	return eps
+}
+
+// UseHandler adds handler onto the chain of plugins of each Endpoint
+func (eps Endpoints) UseHandler(handler http.Handler) Endpoints {
+	for i, ep := range eps {
+		eps[i] = ep.UseHandler(handler)
+	}
+	return eps
+}
+
+// Meta associates a metadata value with key on each Endpoint
+func (eps Endpoints) Meta(key string, value interface{}) Endpoints {
+	for i, ep := range eps {
+		eps[i] = ep.Meta(key, value)
+	}
+	return eps
+}
+
+// Any registers rf as the handler of path for each of methods or, if
+// no methods are given, for each of mux.Methods. The returned Endpoints
+// share rf and can be given plugins at once.
+//
+// Example usage:
+//
+//	v.Any("/echo", echo)
+//	v.Any("/search", search, "GET", "POST").Use(auth)
+func (v *Verto) Any(path string, rf ResourceFunc, methods ...string) Endpoints {
+	return v.AnyHandler(path, v.resource(rf), methods...)
+}
+
+// AnyHandler is like Any but registers an http.Handler
+func (v *Verto) AnyHandler(path string, handler http.Handler, methods ...string) Endpoints {
+	eps := make(Endpoints, 0)
+	for _, ep := range v.table().Any(path, v.serve(handler), methods...) {
+		eps = append(eps, v.endpoint(ep, handler))
+	}
+	return eps
+}
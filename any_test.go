@@ -0,0 +1,51 @@
+package verto
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestAny(t *testing.T) {
+	defer func() {
+		err := recover()
+		if err != nil {
+			t.Errorf(err.(error).Error())
+		}
+	}()
+
+	err := "Failed any."
+
+	v := New()
+	v.Logger = &NilLogger{}
+	v.Any("/echo", func(c *Context) (interface{}, error) {
+		return c.Request.Method, nil
+	}).Meta("k", "v").UseHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Any", "1")
+	}))
+	v.Any("/search", func(c *Context) (interface{}, error) {
+		meta, _ := c.RouteMeta("k")
+		return meta, nil
+	}, "GET", "POST").Meta("k", "search")
+	h := &HttpHandler{v}
+
+	serve := func(method, path string) *httptest.ResponseRecorder {
+		r, _ := http.NewRequest(method, "http://test.com"+path, nil)
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		return w
+	}
+
+	for _, method := range []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"} {
+		w := serve(method, "/echo")
+		if w.Code != 200 || w.Body.String() != method || w.Header().Get("X-Any") != "1" {
+			t.Errorf(err)
+		}
+	}
+	if serve("POST", "/search").Body.String() != "search" {
+		t.Errorf(err)
+	}
+	if w := serve("PUT", "/search"); w.Code != 405 || w.Header().Get("Allow") != "GET, POST" {
+		t.Errorf(err)
+	}
+}
@@ -0,0 +1,187 @@
+// Package apikeys provides per-client API key management for Verto.
+// Keys are stored hashed in a pluggable Store, carry scopes and an
+// optional expiry and can be created, listed and revoked through
+// management endpoints. The Auth plugin authenticates requests by
+// API key and exposes the key as the request's authz.Principal.
+package apikeys
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"strings"
+	"time"
+)
+
+// KeyPrefix prefixes every generated API key secret so that
+// keys are easily recognizable (e.g. by secret scanners)
+const KeyPrefix = "vk_"
+
+// ErrNotFound is returned by Stores if a key does not exist
+var ErrNotFound = errors.New("apikeys: key not found")
+
+// ErrInvalidKey is returned by Authenticate if a secret
+// does not belong to any key
+var ErrInvalidKey = errors.New("apikeys: invalid key")
+
+// ErrExpired is returned by Authenticate for expired keys
+var ErrExpired = errors.New("apikeys: key expired")
+
+// ErrRevoked is returned by Authenticate for revoked keys
+var ErrRevoked = errors.New("apikeys: key revoked")
+
+// Key is an API key. The secret of a key is only available
+// when the key is created; stores only hold its hash.
+// Key implements authz.Principal with its scopes as roles
+type Key struct {
+	// Id is the public identifier of the key
+	Id string `json:"id"`
+
+	// Name is a human readable description of the key
+	Name string `json:"name"`
+
+	// Hash is the hex encoded SHA-256 hash of the key's secret
+	Hash string `json:"-"`
+
+	// Scopes lists the scopes granted to the key
+	Scopes []string `json:"scopes"`
+
+	// Created is the time the key was created
+	Created time.Time `json:"created"`
+
+	// Expires is the time the key expires. The zero
+	// time means the key does not expire
+	Expires time.Time `json:"expires,omitempty"`
+
+	// Revoked is true if the key has been revoked
+	Revoked bool `json:"revoked"`
+}
+
+// ID returns the id of the key
+func (k *Key) ID() string {
+	return k.Id
+}
+
+// Roles returns the scopes of the key
+func (k *Key) Roles() []string {
+	return k.Scopes
+}
+
+// HasScope returns whether the key was granted scope
+func (k *Key) HasScope(scope string) bool {
+	for _, s := range k.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// Expired returns whether the key has expired at t
+func (k *Key) Expired(t time.Time) bool {
+	return !k.Expires.IsZero() && !t.Before(k.Expires)
+}
+
+// Manager creates, authenticates and revokes API keys
+// backed by a Store
+type Manager struct {
+	// Store is the storage backend for keys
+	Store Store
+
+	// Now returns the current time. Defaults to time.Now
+	Now func() time.Time
+}
+
+// NewManager returns a Manager backed by store
+func NewManager(store Store) *Manager {
+	return &Manager{Store: store, Now: time.Now}
+}
+
+// Create generates and stores a new key with name and scopes that
+// expires after ttl, or never if ttl is zero. The returned secret is
+// the only copy of the key's secret and should be handed to the client
+func (m *Manager) Create(name string, scopes []string, ttl time.Duration) (string, *Key, error) {
+	id, err := randomHex(8)
+	if err != nil {
+		return "", nil, err
+	}
+	secret, err := randomHex(24)
+	if err != nil {
+		return "", nil, err
+	}
+	secret = KeyPrefix + id + "_" + secret
+
+	now := m.now().UTC()
+	key := &Key{
+		Id:      id,
+		Name:    name,
+		Hash:    Hash(secret),
+		Scopes:  scopes,
+		Created: now,
+	}
+	if ttl > 0 {
+		key.Expires = now.Add(ttl)
+	}
+	if err := m.Store.Create(key); err != nil {
+		return "", nil, err
+	}
+	return secret, key, nil
+}
+
+// Authenticate returns the key belonging to secret. Returns
+// ErrInvalidKey, ErrRevoked or ErrExpired if the secret
+// does not belong to a usable key
+func (m *Manager) Authenticate(secret string) (*Key, error) {
+	if !strings.HasPrefix(secret, KeyPrefix) {
+		return nil, ErrInvalidKey
+	}
+	key, err := m.Store.GetByHash(Hash(secret))
+	if err == ErrNotFound {
+		return nil, ErrInvalidKey
+	}
+	if err != nil {
+		return nil, err
+	}
+	if key.Revoked {
+		return nil, ErrRevoked
+	}
+	if key.Expired(m.now()) {
+		return nil, ErrExpired
+	}
+	return key, nil
+}
+
+// Revoke revokes the key with id
+func (m *Manager) Revoke(id string) error {
+	return m.Store.Revoke(id)
+}
+
+// List returns all keys
+func (m *Manager) List() ([]*Key, error) {
+	return m.Store.List()
+}
+
+func (m *Manager) now() time.Time {
+	if m.Now == nil {
+		return time.Now()
+	}
+	return m.Now()
+}
+
+// Hash returns the hex encoded SHA-256 hash of secret. Generated
+// secrets carry enough entropy that a fast hash is sufficient
+func Hash(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
+
+// randomHex returns n random bytes hex encoded
+func randomHex(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := io.ReadFull(rand.Reader, b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
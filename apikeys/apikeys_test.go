@@ -0,0 +1,155 @@
+package apikeys
+
+import (
+	"encoding/json"
+	"github.com/boxtown/verto"
+	"github.com/boxtown/verto/plugins/authz"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestManager(t *testing.T) {
+	defer func() {
+		err := recover()
+		if err != nil {
+			t.Errorf(err.(error).Error())
+		}
+	}()
+
+	err := "Failed manager."
+
+	now := time.Unix(1000, 0)
+	m := NewManager(NewMemoryStore())
+	m.Now = func() time.Time { return now }
+
+	secret, key, e := m.Create("ci", []string{"reports:read"}, time.Hour)
+	if e != nil || !strings.HasPrefix(secret, KeyPrefix+key.Id+"_") || key.Hash != Hash(secret) {
+		t.Fatalf(err)
+	}
+
+	k, e := m.Authenticate(secret)
+	if e != nil || k.Id != key.Id || !k.HasScope("reports:read") {
+		t.Errorf(err)
+	}
+	if _, e = m.Authenticate(secret + "x"); e != ErrInvalidKey {
+		t.Errorf(err)
+	}
+	if _, e = m.Authenticate("garbage"); e != ErrInvalidKey {
+		t.Errorf(err)
+	}
+
+	// Test expiry
+	now = now.Add(2 * time.Hour)
+	if _, e = m.Authenticate(secret); e != ErrExpired {
+		t.Errorf(err)
+	}
+
+	// Test revocation
+	secret, key, _ = m.Create("forever", nil, 0)
+	if e = m.Revoke(key.Id); e != nil {
+		t.Errorf(err)
+	}
+	if _, e = m.Authenticate(secret); e != ErrRevoked {
+		t.Errorf(err)
+	}
+	if e = m.Revoke("missing"); e != ErrNotFound {
+		t.Errorf(err)
+	}
+}
+
+func TestAuthAndManagement(t *testing.T) {
+	defer func() {
+		err := recover()
+		if err != nil {
+			t.Errorf(err.(error).Error())
+		}
+	}()
+
+	err := "Failed auth and management."
+
+	m := NewManager(NewMemoryStore())
+
+	v := verto.New()
+	v.Logger = &verto.NilLogger{}
+	m.Register(v, "/admin/keys")
+	v.Get("/reports", func(c *verto.Context) (interface{}, error) {
+		return From(c).Name, nil
+	}).Use(New(v.Injections, m)).Use(authz.Require("reports:read"))
+	h := &verto.HttpHandler{v}
+
+	// Test create through management endpoint
+	r, _ := http.NewRequest("POST", "http://test.com/admin/keys", strings.NewReader(`{"name":"ci","scopes":["reports:read"]}`))
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	if w.Code != http.StatusCreated {
+		t.Fatalf(err)
+	}
+	created := struct {
+		Id     string `json:"id"`
+		Secret string `json:"secret"`
+	}{}
+	json.Unmarshal(w.Body.Bytes(), &created)
+
+	// Test authenticated request
+	r, _ = http.NewRequest("GET", "http://test.com/reports", nil)
+	r.Header.Set("Authorization", "Bearer "+created.Secret)
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	if w.Code != 200 || w.Body.String() != "ci" {
+		t.Errorf(err)
+	}
+
+	// Test missing key
+	r, _ = http.NewRequest("GET", "http://test.com/reports", nil)
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf(err)
+	}
+
+	// Test list hides hashes
+	r, _ = http.NewRequest("GET", "http://test.com/admin/keys", nil)
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	if w.Code != 200 || !strings.Contains(w.Body.String(), created.Id) || strings.Contains(w.Body.String(), "hash") {
+		t.Errorf(err)
+	}
+
+	// Test revoke
+	r, _ = http.NewRequest("DELETE", "http://test.com/admin/keys/"+created.Id, nil)
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	if w.Code != http.StatusNoContent {
+		t.Errorf(err)
+	}
+	r, _ = http.NewRequest("GET", "http://test.com/reports", nil)
+	r.Header.Set(HeaderName, created.Secret)
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf(err)
+	}
+}
+
+func TestSQLStoreQuery(t *testing.T) {
+	defer func() {
+		err := recover()
+		if err != nil {
+			t.Errorf(err.(error).Error())
+		}
+	}()
+
+	err := "Failed SQL store query."
+
+	s := &SQLStore{Table: "keys", Dollar: true}
+	if s.query("UPDATE %t SET revoked = ? WHERE id = ?") != "UPDATE keys SET revoked = $1 WHERE id = $2" {
+		t.Errorf(err)
+	}
+	s.Dollar = false
+	if s.query("SELECT id FROM %t WHERE id = ?") != "SELECT id FROM keys WHERE id = ?" {
+		t.Errorf(err)
+	}
+}
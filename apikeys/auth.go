@@ -0,0 +1,141 @@
+package apikeys
+
+import (
+	"fmt"
+	"github.com/boxtown/verto"
+	"github.com/boxtown/verto/plugins"
+	"github.com/boxtown/verto/plugins/authz"
+	"net/http"
+	"strings"
+)
+
+// KEYKEY is the injection key under which the authenticated
+// key of a request is exposed
+const KEYKEY = "_VertoAPIKey"
+
+// HeaderName is the request header API keys are read from.
+// Keys are also accepted as bearer tokens in the Authorization header
+const HeaderName = "X-Api-Key"
+
+// result is the per-request authentication result
+type result struct {
+	key *Key
+	err error
+}
+
+// Auth is a plugin that authenticates requests by API key. Requests
+// without a valid key receive a 401 response. The authenticated key is
+// exposed at KEYKEY and as the request's principal at authz.PRINCIPALKEY
+// so that authz plugins can restrict routes by key scope.
+//
+// Example usage:
+//
+//	m := apikeys.NewManager(apikeys.NewMemoryStore())
+//	api := v.Group("GET", "/api").Use(apikeys.New(v.Injections, m))
+//	api.Add("/reports", handler).Use(authz.Require("reports:read"))
+type Auth struct {
+	// Core is the core functionality for plugins
+	plugins.Core
+
+	// Optional, if true requests without a key are passed
+	// through unauthenticated. Requests with an invalid
+	// key are still rejected
+	Optional bool
+
+	// OnDenied is an optional function for writing
+	// responses to unauthenticated requests
+	OnDenied func(err error, c *verto.Context)
+}
+
+// New registers per-request lazy authentication at KEYKEY and
+// authz.PRINCIPALKEY in i using m and returns a new Auth plugin
+func New(i verto.Injections, m *Manager) *Auth {
+	i.Lazy(
+		KEYKEY,
+		func(w http.ResponseWriter, r *http.Request, ri verto.ReadOnlyInjections) interface{} {
+			secret := Secret(r)
+			if secret == "" {
+				return &result{}
+			}
+			key, err := m.Authenticate(secret)
+			return &result{key, err}
+		},
+		verto.REQUEST)
+	i.Lazy(
+		authz.PRINCIPALKEY,
+		func(w http.ResponseWriter, r *http.Request, ri verto.ReadOnlyInjections) interface{} {
+			if res, ok := ri.Get(KEYKEY).(*result); ok && res.key != nil {
+				return res.key
+			}
+			return nil
+		},
+		verto.REQUEST)
+
+	return &Auth{Core: plugins.Core{Id: "plugins.APIKeys"}}
+}
+
+// Handle is called per web request to authenticate the request's API key
+func (plugin *Auth) Handle(c *verto.Context, next http.HandlerFunc) {
+	plugin.Core.Handle(
+		func(c *verto.Context, next http.HandlerFunc) {
+			res := lookup(c)
+			if res == nil || (res.key == nil && (res.err != nil || !plugin.Optional)) {
+				err := ErrInvalidKey
+				if res != nil && res.err != nil {
+					err = res.err
+				}
+				plugin.deny(err, c)
+				return
+			}
+			next(c.Response, c.Request)
+		}, c, next)
+}
+
+// deny writes an unauthorized response
+func (plugin *Auth) deny(err error, c *verto.Context) {
+	if plugin.OnDenied != nil {
+		plugin.OnDenied(err, c)
+		return
+	}
+	if err != ErrInvalidKey && err != ErrExpired && err != ErrRevoked && c.Logger != nil {
+		c.Logger.Errorf("apikeys: could not authenticate: %s", err.Error())
+	}
+	c.Response.Header().Set("WWW-Authenticate", `Bearer realm="api"`)
+	c.Response.WriteHeader(http.StatusUnauthorized)
+	fmt.Fprint(c.Response, "Unauthorized.")
+}
+
+// From retrieves the authenticated key of the request
+// or nil if the request was not authenticated
+func From(c *verto.Context) *Key {
+	if res := lookup(c); res != nil {
+		return res.key
+	}
+	return nil
+}
+
+// Secret returns the API key secret supplied with r in either
+// the X-Api-Key header or as an Authorization bearer token
+func Secret(r *http.Request) string {
+	if secret := r.Header.Get(HeaderName); secret != "" {
+		return secret
+	}
+	auth := r.Header.Get("Authorization")
+	if len(auth) > 7 && strings.EqualFold(auth[:7], "bearer ") {
+		return strings.TrimSpace(auth[7:])
+	}
+	return ""
+}
+
+// lookup retrieves the authentication result of the request
+func lookup(c *verto.Context) *result {
+	if c.Injections == nil {
+		return nil
+	}
+	i := c.Injections()
+	if i == nil {
+		return nil
+	}
+	res, _ := i.Get(KEYKEY).(*result)
+	return res
+}
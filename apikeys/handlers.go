@@ -0,0 +1,107 @@
+package apikeys
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/boxtown/verto"
+	"github.com/boxtown/verto/mux"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// createRequest is the body of key creation requests
+type createRequest struct {
+	Name   string   `json:"name"`
+	Scopes []string `json:"scopes"`
+
+	// TTL is the lifetime of the key in seconds
+	TTL int64 `json:"ttl"`
+}
+
+// createResponse is the body of key creation responses
+type createResponse struct {
+	*Key
+
+	// Secret is the key's secret. It is only returned once
+	Secret string `json:"secret"`
+}
+
+// Register registers key management endpoints under prefix on v:
+//
+//	POST   prefix       creates a key from {"name", "scopes", "ttl"}
+//	GET    prefix       lists all keys
+//	DELETE prefix/{id}  revokes a key
+//
+// The endpoints should be protected, e.g. by creating admin groups
+// with authorization plugins covering prefix.
+//
+// Example usage:
+//
+//	m.Register(v, "/admin/keys")
+//	for _, method := range []string{"GET", "POST", "DELETE"} {
+//		v.Group(method, "/admin").Use(authz.Require("admin"))
+//	}
+func (m *Manager) Register(v *verto.Verto, prefix string) {
+	prefix = strings.TrimRight(prefix, "/")
+	v.AddHandler("POST", prefix, http.HandlerFunc(m.create))
+	v.AddHandler("GET", prefix, http.HandlerFunc(m.list))
+	v.AddHandler("DELETE", prefix+"/{id}", http.HandlerFunc(m.revoke))
+}
+
+// create handles key creation requests
+func (m *Manager) create(w http.ResponseWriter, r *http.Request) {
+	req := createRequest{}
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 64<<10)).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest)
+		return
+	}
+	if req.Name == "" || req.TTL < 0 {
+		writeError(w, http.StatusBadRequest)
+		return
+	}
+
+	secret, key, err := m.Create(req.Name, req.Scopes, time.Duration(req.TTL)*time.Second)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusCreated, createResponse{key, secret})
+}
+
+// list handles key listing requests
+func (m *Manager) list(w http.ResponseWriter, r *http.Request) {
+	keys, err := m.List()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, keys)
+}
+
+// revoke handles key revocation requests
+func (m *Manager) revoke(w http.ResponseWriter, r *http.Request) {
+	err := m.Revoke(mux.Param(r, "id"))
+	if err == ErrNotFound {
+		writeError(w, http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// writes v as a JSON response with status
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+// writes a plain text error response with status
+func writeError(w http.ResponseWriter, status int) {
+	w.WriteHeader(status)
+	fmt.Fprint(w, http.StatusText(status)+".")
+}
@@ -0,0 +1,147 @@
+package apikeys
+
+import (
+	"database/sql"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Schema is the default schema for the SQLStore table
+const Schema = `CREATE TABLE IF NOT EXISTS api_keys (
+	id      VARCHAR(32)  PRIMARY KEY,
+	name    VARCHAR(255) NOT NULL,
+	hash    CHAR(64)     NOT NULL UNIQUE,
+	scopes  TEXT         NOT NULL,
+	created BIGINT       NOT NULL,
+	expires BIGINT       NOT NULL,
+	revoked BOOLEAN      NOT NULL
+)`
+
+// SQLStore is a Store backed by a SQL database. Scopes are
+// stored space separated and times as unix nanoseconds.
+type SQLStore struct {
+	// DB is the database keys are stored in
+	DB *sql.DB
+
+	// Table is the name of the table keys are stored in.
+	// Defaults to api_keys
+	Table string
+
+	// Dollar selects $n placeholders (e.g. PostgreSQL)
+	// instead of ? placeholders
+	Dollar bool
+}
+
+// NewSQLStore returns a SQLStore using db with default settings
+func NewSQLStore(db *sql.DB) *SQLStore {
+	return &SQLStore{DB: db, Table: "api_keys"}
+}
+
+// Create inserts key
+func (s *SQLStore) Create(key *Key) error {
+	_, err := s.DB.Exec(
+		s.query("INSERT INTO %t (id, name, hash, scopes, created, expires, revoked) VALUES (?, ?, ?, ?, ?, ?, ?)"),
+		key.Id, key.Name, key.Hash, strings.Join(key.Scopes, " "),
+		key.Created.UnixNano(), unixNano(key.Expires), key.Revoked)
+	return err
+}
+
+// Get returns the key with id
+func (s *SQLStore) Get(id string) (*Key, error) {
+	return s.scan(s.DB.QueryRow(s.query("SELECT id, name, hash, scopes, created, expires, revoked FROM %t WHERE id = ?"), id))
+}
+
+// GetByHash returns the key with the secret hash hash
+func (s *SQLStore) GetByHash(hash string) (*Key, error) {
+	return s.scan(s.DB.QueryRow(s.query("SELECT id, name, hash, scopes, created, expires, revoked FROM %t WHERE hash = ?"), hash))
+}
+
+// List returns all keys ordered by creation time
+func (s *SQLStore) List() ([]*Key, error) {
+	rows, err := s.DB.Query(s.query("SELECT id, name, hash, scopes, created, expires, revoked FROM %t ORDER BY created"))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	keys := make([]*Key, 0)
+	for rows.Next() {
+		k, err := s.scan(rows)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, k)
+	}
+	return keys, rows.Err()
+}
+
+// Revoke marks the key with id as revoked
+func (s *SQLStore) Revoke(id string) error {
+	res, err := s.DB.Exec(s.query("UPDATE %t SET revoked = ? WHERE id = ?"), true, id)
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// scanner is implemented by *sql.Row and *sql.Rows
+type scanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scan reads a key from row
+func (s *SQLStore) scan(row scanner) (*Key, error) {
+	var scopes string
+	var created, expires int64
+	k := &Key{}
+	err := row.Scan(&k.Id, &k.Name, &k.Hash, &scopes, &created, &expires, &k.Revoked)
+	if err == sql.ErrNoRows {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	k.Scopes = strings.Fields(scopes)
+	k.Created = time.Unix(0, created).UTC()
+	if expires != 0 {
+		k.Expires = time.Unix(0, expires).UTC()
+	}
+	return k, nil
+}
+
+// query substitutes the table name for %t and rewrites
+// placeholders for the configured dialect
+func (s *SQLStore) query(q string) string {
+	table := s.Table
+	if table == "" {
+		table = "api_keys"
+	}
+	q = strings.Replace(q, "%t", table, 1)
+	if !s.Dollar {
+		return q
+	}
+	out := make([]byte, 0, len(q)+8)
+	n := 0
+	for i := 0; i < len(q); i++ {
+		if q[i] == '?' {
+			n++
+			out = append(out, '$')
+			out = append(out, strconv.Itoa(n)...)
+			continue
+		}
+		out = append(out, q[i])
+	}
+	return string(out)
+}
+
+// unixNano returns t in unix nanoseconds or 0 for the zero time
+func unixNano(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+	return t.UnixNano()
+}
@@ -0,0 +1,107 @@
+package apikeys
+
+import (
+	"sort"
+	"sync"
+)
+
+// Store is the interface for API key storage backends
+type Store interface {
+	// Create stores a new key
+	Create(key *Key) error
+
+	// Get returns the key with id or ErrNotFound
+	Get(id string) (*Key, error)
+
+	// GetByHash returns the key with the secret hash
+	// hash or ErrNotFound
+	GetByHash(hash string) (*Key, error)
+
+	// List returns all stored keys
+	List() ([]*Key, error)
+
+	// Revoke marks the key with id as revoked
+	// or returns ErrNotFound
+	Revoke(id string) error
+}
+
+// MemoryStore is an in-memory Store. MemoryStore is thread-safe
+type MemoryStore struct {
+	keys   map[string]*Key
+	hashes map[string]string
+	mutex  *sync.RWMutex
+}
+
+// NewMemoryStore returns an empty MemoryStore
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		keys:   make(map[string]*Key),
+		hashes: make(map[string]string),
+		mutex:  &sync.RWMutex{},
+	}
+}
+
+// Create stores a copy of key
+func (ms *MemoryStore) Create(key *Key) error {
+	ms.mutex.Lock()
+	defer ms.mutex.Unlock()
+
+	k := *key
+	ms.keys[k.Id] = &k
+	ms.hashes[k.Hash] = k.Id
+	return nil
+}
+
+// Get returns a copy of the key with id
+func (ms *MemoryStore) Get(id string) (*Key, error) {
+	ms.mutex.RLock()
+	defer ms.mutex.RUnlock()
+
+	k, ok := ms.keys[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	c := *k
+	return &c, nil
+}
+
+// GetByHash returns a copy of the key with the secret hash hash
+func (ms *MemoryStore) GetByHash(hash string) (*Key, error) {
+	ms.mutex.RLock()
+	id, ok := ms.hashes[hash]
+	ms.mutex.RUnlock()
+
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return ms.Get(id)
+}
+
+// List returns copies of all keys ordered by creation time
+func (ms *MemoryStore) List() ([]*Key, error) {
+	ms.mutex.RLock()
+	defer ms.mutex.RUnlock()
+
+	keys := make([]*Key, 0, len(ms.keys))
+	for _, k := range ms.keys {
+		c := *k
+		keys = append(keys, &c)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return keys[i].Created.Before(keys[j].Created)
+	})
+	return keys, nil
+}
+
+// Revoke marks the key with id as revoked
+func (ms *MemoryStore) Revoke(id string) error {
+	ms.mutex.Lock()
+	defer ms.mutex.Unlock()
+
+	k, ok := ms.keys[id]
+	if !ok {
+		return ErrNotFound
+	}
+	k.Revoked = true
+	return nil
+}
@@ -0,0 +1,103 @@
+package verto
+
+import (
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Audit actions recorded by Verto for runtime framework mutations
+const (
+	AuditRouteAdded    = "route.added"
+	AuditRouteRemoved  = "route.removed"
+	AuditPluginAdded   = "plugin.added"
+	AuditConfigChanged = "config.changed"
+	AuditTableSwapped  = "table.swapped"
+	AuditShutdown      = "shutdown"
+)
+
+// AuditSourceAPI is the audit source for mutations made
+// programmatically through the Verto API
+const AuditSourceAPI = "api"
+
+// AuditEntry is a record of a single runtime mutation
+// of the framework
+type AuditEntry struct {
+	// Time is the time the mutation occurred
+	Time time.Time `json:"time"`
+
+	// Action is the kind of mutation (e.g. route.added)
+	Action string `json:"action"`
+
+	// Source identifies the initiator of the mutation. Mutations made
+	// through the Verto API have source "api" and mutations initiated
+	// through HTTP requests have the requester's IP as source
+	Source string `json:"source"`
+
+	// Detail is a human readable description of the mutation
+	Detail string `json:"detail"`
+}
+
+// AuditTrail is a bounded, thread-safe record of AuditEntries.
+// Once the trail reaches its capacity the oldest entries are
+// discarded. AuditTrail implements http.Handler and serves its
+// entries as JSON so it can be mounted as a control endpoint.
+type AuditTrail struct {
+	// Anonymizer optionally anonymizes the IP sources
+	// of recorded entries
+	Anonymizer *Anonymizer
+
+	entries []AuditEntry
+	max     int
+	mutex   *sync.RWMutex
+}
+
+// NewAuditTrail returns a newly initialized AuditTrail that
+// retains at most max entries
+func NewAuditTrail(max int) *AuditTrail {
+	return &AuditTrail{
+		entries: make([]AuditEntry, 0),
+		max:     max,
+		mutex:   &sync.RWMutex{},
+	}
+}
+
+// Record appends a new entry with the current time to the trail
+func (a *AuditTrail) Record(action, source, detail string) {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	if a.Anonymizer.Drops("source") {
+		source = ""
+	}
+	if a.Anonymizer.Drops("detail") {
+		detail = ""
+	}
+	a.entries = append(a.entries, AuditEntry{
+		Time:   time.Now().UTC(),
+		Action: action,
+		Source: a.Anonymizer.IP(source),
+		Detail: detail,
+	})
+	if a.max > 0 && len(a.entries) > a.max {
+		a.entries = a.entries[len(a.entries)-a.max:]
+	}
+}
+
+// Entries returns a copy of all entries in the trail
+// in the order they were recorded
+func (a *AuditTrail) Entries() []AuditEntry {
+	a.mutex.RLock()
+	defer a.mutex.RUnlock()
+
+	entries := make([]AuditEntry, len(a.entries))
+	copy(entries, a.entries)
+	return entries
+}
+
+// ServeHTTP writes all entries in the trail as a JSON array
+func (a *AuditTrail) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(a.Entries())
+}
@@ -0,0 +1,58 @@
+package verto
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestAuditTrailRecord(t *testing.T) {
+	defer func() {
+		err := recover()
+		if err != nil {
+			t.Errorf(err.(error).Error())
+		}
+	}()
+
+	err := "Failed audit trail record."
+
+	a := NewAuditTrail(2)
+	a.Record("a", AuditSourceAPI, "1")
+	a.Record("b", AuditSourceAPI, "2")
+	a.Record("c", AuditSourceAPI, "3")
+
+	entries := a.Entries()
+	if len(entries) != 2 {
+		t.Fatalf(err)
+	}
+	if entries[0].Action != "b" || entries[1].Action != "c" {
+		t.Errorf(err)
+	}
+}
+
+func TestVertoAudit(t *testing.T) {
+	defer func() {
+		err := recover()
+		if err != nil {
+			t.Errorf(err.(error).Error())
+		}
+	}()
+
+	err := "Failed verto audit."
+
+	v := New()
+	v.Logger = &NilLogger{}
+	v.Get("/a", func(c *Context) (interface{}, error) {
+		return nil, nil
+	}).UseHandler(http.NotFoundHandler())
+
+	entries := v.Audit.Entries()
+	if len(entries) != 2 {
+		t.Fatalf(err)
+	}
+	if entries[0].Action != AuditRouteAdded || entries[0].Detail != "GET /a" {
+		t.Errorf(err)
+	}
+	if entries[1].Action != AuditPluginAdded || entries[1].Source != AuditSourceAPI {
+		t.Errorf(err)
+	}
+}
@@ -0,0 +1,122 @@
+package credentials
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+	"golang.org/x/crypto/argon2"
+	"io"
+	"strings"
+)
+
+// Argon2id is a Hasher using argon2id. Hashes are encoded in the
+// PHC string format: $argon2id$v=19$m=<memory>,t=<time>,p=<threads>$<salt>$<key>
+type Argon2id struct {
+	// Time is the number of passes over the memory
+	Time uint32
+
+	// Memory is the memory size in KiB
+	Memory uint32
+
+	// Threads is the degree of parallelism
+	Threads uint8
+
+	// SaltLen is the salt length in bytes
+	SaltLen int
+
+	// KeyLen is the derived key length in bytes
+	KeyLen uint32
+}
+
+// NewArgon2id returns an Argon2id Hasher with the parameters
+// recommended by RFC 9106 for memory-constrained environments
+// (t=3, m=64MiB, p=4)
+func NewArgon2id() *Argon2id {
+	return &Argon2id{
+		Time:    3,
+		Memory:  64 * 1024,
+		Threads: 4,
+		SaltLen: 16,
+		KeyLen:  32,
+	}
+}
+
+// argon2Params are the parameters decoded from an argon2id hash
+type argon2Params struct {
+	time    uint32
+	memory  uint32
+	threads uint8
+	salt    []byte
+	key     []byte
+}
+
+// Hash returns the argon2id hash of password with a random salt
+func (a *Argon2id) Hash(password string) (string, error) {
+	salt := make([]byte, a.SaltLen)
+	if _, err := io.ReadFull(rand.Reader, salt); err != nil {
+		return "", err
+	}
+	key := argon2.IDKey([]byte(password), salt, a.Time, a.Memory, a.Threads, a.KeyLen)
+	return fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s",
+		argon2.Version, a.Memory, a.Time, a.Threads,
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(key)), nil
+}
+
+// Verify returns whether hash is the argon2id hash of password.
+// The derived keys are compared in constant time
+func (a *Argon2id) Verify(hash, password string) (bool, error) {
+	p, err := decodeArgon2(hash)
+	if err != nil {
+		return false, err
+	}
+	key := argon2.IDKey([]byte(password), p.salt, p.time, p.memory, p.threads, uint32(len(p.key)))
+	return subtle.ConstantTimeCompare(key, p.key) == 1, nil
+}
+
+// Identifies returns whether hash is an argon2id hash
+func (a *Argon2id) Identifies(hash string) bool {
+	return strings.HasPrefix(hash, "$argon2id$")
+}
+
+// Outdated returns whether hash was produced with weaker
+// parameters than those of a
+func (a *Argon2id) Outdated(hash string) bool {
+	p, err := decodeArgon2(hash)
+	if err != nil {
+		return true
+	}
+	return p.time < a.Time || p.memory < a.Memory || p.threads < a.Threads ||
+		len(p.salt) < a.SaltLen || uint32(len(p.key)) < a.KeyLen
+}
+
+// decodeArgon2 parses an argon2id hash in PHC string format
+func decodeArgon2(hash string) (*argon2Params, error) {
+	parts := strings.Split(hash, "$")
+	if len(parts) != 6 || parts[1] != "argon2id" {
+		return nil, ErrMalformedHash
+	}
+
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return nil, ErrMalformedHash
+	}
+
+	p := &argon2Params{}
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &p.memory, &p.time, &p.threads); err != nil {
+		return nil, ErrMalformedHash
+	}
+	if p.time == 0 || p.memory == 0 || p.threads == 0 {
+		return nil, ErrMalformedHash
+	}
+
+	var err error
+	if p.salt, err = base64.RawStdEncoding.DecodeString(parts[4]); err != nil {
+		return nil, ErrMalformedHash
+	}
+	if p.key, err = base64.RawStdEncoding.DecodeString(parts[5]); err != nil || len(p.key) == 0 {
+		return nil, ErrMalformedHash
+	}
+	return p, nil
+}
@@ -0,0 +1,49 @@
+package credentials
+
+import (
+	"golang.org/x/crypto/bcrypt"
+	"strings"
+)
+
+// Bcrypt is a Hasher using bcrypt
+type Bcrypt struct {
+	// Cost is the bcrypt cost factor
+	Cost int
+}
+
+// NewBcrypt returns a Bcrypt Hasher with a cost of 12
+func NewBcrypt() *Bcrypt {
+	return &Bcrypt{Cost: 12}
+}
+
+// Hash returns the bcrypt hash of password. Note that bcrypt
+// only considers the first 72 bytes of a password
+func (b *Bcrypt) Hash(password string) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), b.Cost)
+	return string(hash), err
+}
+
+// Verify returns whether hash is the bcrypt hash of password
+func (b *Bcrypt) Verify(hash, password string) (bool, error) {
+	err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(password))
+	if err == bcrypt.ErrMismatchedHashAndPassword {
+		return false, nil
+	}
+	if err != nil {
+		return false, ErrMalformedHash
+	}
+	return true, nil
+}
+
+// Identifies returns whether hash is a bcrypt hash
+func (b *Bcrypt) Identifies(hash string) bool {
+	return strings.HasPrefix(hash, "$2a$") ||
+		strings.HasPrefix(hash, "$2b$") ||
+		strings.HasPrefix(hash, "$2y$")
+}
+
+// Outdated returns whether hash has a lower cost than b
+func (b *Bcrypt) Outdated(hash string) bool {
+	cost, err := bcrypt.Cost([]byte(hash))
+	return err != nil || cost < b.Cost
+}
@@ -0,0 +1,123 @@
+// Package credentials provides password hashing for Verto applications.
+// Passwords are hashed with argon2id or bcrypt, verified in constant time
+// and transparently rehashed when they were hashed with an outdated
+// algorithm or weaker parameters than currently configured.
+//
+// Example usage:
+//
+//	pw := credentials.New()
+//	hash, err := pw.Hash(password)
+//
+//	// on login
+//	ok, rehash, err := pw.Verify(user.PasswordHash, password)
+//	if ok && rehash != "" {
+//		user.PasswordHash = rehash
+//		users.Save(user)
+//	}
+package credentials
+
+import (
+	"errors"
+	"sync"
+)
+
+// ErrUnknownHash is returned if a hash was not produced
+// by any of the configured Hashers
+var ErrUnknownHash = errors.New("credentials: unknown hash format")
+
+// ErrMalformedHash is returned if a hash could not be parsed
+var ErrMalformedHash = errors.New("credentials: malformed hash")
+
+// Hasher is the interface for password hashing algorithms
+type Hasher interface {
+	// Hash returns the encoded hash of password
+	Hash(password string) (string, error)
+
+	// Verify returns whether hash is the hash of password.
+	// Verify must compare in constant time
+	Verify(hash, password string) (bool, error)
+
+	// Identifies returns whether hash was produced by the
+	// algorithm of the Hasher
+	Identifies(hash string) bool
+
+	// Outdated returns whether hash was produced with weaker
+	// parameters than those of the Hasher
+	Outdated(hash string) bool
+}
+
+// Passwords hashes passwords with a current Hasher and verifies
+// hashes produced by the current or any of the legacy Hashers
+type Passwords struct {
+	// Current is the Hasher new hashes are produced with
+	Current Hasher
+
+	// Legacy lists Hashers whose hashes are still
+	// accepted but upgraded on verification
+	Legacy []Hasher
+
+	// dummy is a hash used to equalize verification
+	// timing for unknown users
+	dummy     string
+	dummyOnce sync.Once
+}
+
+// New returns Passwords hashing with argon2id using the default
+// parameters and accepting bcrypt hashes as legacy hashes
+func New() *Passwords {
+	return &Passwords{
+		Current: NewArgon2id(),
+		Legacy:  []Hasher{NewBcrypt()},
+	}
+}
+
+// Hash returns the hash of password produced by the current Hasher
+func (p *Passwords) Hash(password string) (string, error) {
+	return p.Current.Hash(password)
+}
+
+// Verify returns whether hash is the hash of password. If the password
+// matches and hash was produced by a legacy Hasher or with outdated
+// parameters, a new hash of password produced by the current Hasher is
+// returned as rehash. Callers should store rehash in place of hash
+func (p *Passwords) Verify(hash, password string) (ok bool, rehash string, err error) {
+	h := p.hasher(hash)
+	if h == nil {
+		return false, "", ErrUnknownHash
+	}
+	ok, err = h.Verify(hash, password)
+	if err != nil || !ok {
+		return false, "", err
+	}
+	if h != p.Current || p.Current.Outdated(hash) {
+		rehash, err = p.Current.Hash(password)
+		if err != nil {
+			return true, "", err
+		}
+	}
+	return true, rehash, nil
+}
+
+// VerifyMissing performs a verification against a dummy hash and returns
+// false. Login handlers should call VerifyMissing when a user does not
+// exist so that response timing does not reveal which users exist
+func (p *Passwords) VerifyMissing(password string) bool {
+	p.dummyOnce.Do(func() {
+		p.dummy, _ = p.Current.Hash("verto-dummy-password")
+	})
+	p.Current.Verify(p.dummy, password)
+	return false
+}
+
+// hasher returns the Hasher that produced hash or nil
+func (p *Passwords) hasher(hash string) Hasher {
+	if p.Current.Identifies(hash) {
+		return p.Current
+	}
+	for _, h := range p.Legacy {
+		if h.Identifies(hash) {
+			return h
+		}
+	}
+	return nil
+}
@@ -0,0 +1,87 @@
+package credentials
+
+import (
+	"strings"
+	"testing"
+)
+
+// fast returns Passwords with cheap parameters for testing
+func fast() *Passwords {
+	return &Passwords{
+		Current: &Argon2id{Time: 1, Memory: 1024, Threads: 1, SaltLen: 16, KeyLen: 32},
+		Legacy:  []Hasher{&Bcrypt{Cost: 4}},
+	}
+}
+
+func TestPasswords(t *testing.T) {
+	defer func() {
+		err := recover()
+		if err != nil {
+			t.Errorf(err.(error).Error())
+		}
+	}()
+
+	err := "Failed passwords."
+
+	pw := fast()
+	hash, e := pw.Hash("secret")
+	if e != nil || !strings.HasPrefix(hash, "$argon2id$v=19$m=1024,t=1,p=1$") {
+		t.Fatalf(err)
+	}
+	other, _ := pw.Hash("secret")
+	if other == hash {
+		t.Errorf(err)
+	}
+
+	ok, rehash, e := pw.Verify(hash, "secret")
+	if !ok || rehash != "" || e != nil {
+		t.Errorf(err)
+	}
+	ok, rehash, e = pw.Verify(hash, "wrong")
+	if ok || rehash != "" || e != nil {
+		t.Errorf(err)
+	}
+	if _, _, e = pw.Verify("plaintext", "secret"); e != ErrUnknownHash {
+		t.Errorf(err)
+	}
+	if _, _, e = pw.Verify("$argon2id$v=19$m=x$salt$key", "secret"); e != ErrMalformedHash {
+		t.Errorf(err)
+	}
+	if pw.VerifyMissing("secret") {
+		t.Errorf(err)
+	}
+}
+
+func TestPasswordsRehash(t *testing.T) {
+	defer func() {
+		err := recover()
+		if err != nil {
+			t.Errorf(err.(error).Error())
+		}
+	}()
+
+	err := "Failed passwords rehash."
+
+	pw := fast()
+
+	// Test legacy bcrypt hashes are upgraded
+	legacy, _ := (&Bcrypt{Cost: 4}).Hash("secret")
+	ok, rehash, e := pw.Verify(legacy, "secret")
+	if !ok || e != nil || !pw.Current.Identifies(rehash) {
+		t.Fatalf(err)
+	}
+	if ok, _, _ = pw.Verify(legacy, "wrong"); ok {
+		t.Errorf(err)
+	}
+
+	// Test hashes with outdated parameters are upgraded
+	weak, _ := pw.Hash("secret")
+	pw.Current.(*Argon2id).Time = 2
+	ok, rehash, e = pw.Verify(weak, "secret")
+	if !ok || e != nil || !strings.Contains(rehash, ",t=2,") {
+		t.Errorf(err)
+	}
+	if ok, rehash, _ = pw.Verify(rehash, "secret"); !ok || rehash != "" {
+		t.Errorf(err)
+	}
+}
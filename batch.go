@@ -0,0 +1,318 @@
+package verto
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// BatchLimits bound the work a single batch request can cause.
+// Zero values select the defaults
+type BatchLimits struct {
+	// MaxRequests is the maximum number of sub-requests
+	// in a batch. Defaults to 20
+	MaxRequests int
+
+	// MaxSize is the maximum size in bytes of
+	// the batch request body. Defaults to 1MB
+	MaxSize int64
+
+	// Concurrency is the maximum number of sub-requests
+	// dispatched concurrently. Defaults to 4
+	Concurrency int
+}
+
+// batchKey is the request context key marking batch sub-requests
+type batchKey struct{}
+
+// BatchRequest is a sub-request of a JSON batch
+type BatchRequest struct {
+	Method  string            `json:"method"`
+	Path    string            `json:"path"`
+	Headers map[string]string `json:"headers,omitempty"`
+	Body    json.RawMessage   `json:"body,omitempty"`
+}
+
+// BatchResponse is the response to a sub-request of a JSON batch.
+// Bodies that are not JSON are encoded as JSON strings
+type BatchResponse struct {
+	Status  int               `json:"status"`
+	Headers map[string]string `json:"headers,omitempty"`
+	Body    json.RawMessage   `json:"body,omitempty"`
+}
+
+// Batch registers a POST endpoint at path accepting multiple sub-requests
+// in one HTTP call. Each sub-request is dispatched in-process through the
+// route table, at most limits.Concurrency at a time, and the responses are
+// returned in the order of the sub-requests. Sub-requests pass through
+// the guards like top-level requests and inherit the headers of the batch
+// request (e.g. Authorization) unless they set their own. Batches may not
+// be nested.
+//
+// JSON batches are arrays of BatchRequests answered with arrays of
+// BatchResponses. multipart/mixed batches carry one application/http
+// part per sub-request and are answered with a multipart/mixed response
+// of application/http parts.
+//
+// Example usage:
+//
+//	v.Batch("/batch", verto.BatchLimits{MaxRequests: 10})
+//
+//	POST /batch
+//	[{"method": "GET", "path": "/users/1"}, {"method": "GET", "path": "/users/2"}]
+func (v *Verto) Batch(path string, limits BatchLimits) *Endpoint {
+	if limits.MaxRequests <= 0 {
+		limits.MaxRequests = 20
+	}
+	if limits.MaxSize <= 0 {
+		limits.MaxSize = 1 << 20
+	}
+	if limits.Concurrency <= 0 {
+		limits.Concurrency = 4
+	}
+
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		if r.Context().Value(batchKey{}) != nil {
+			batchError(w, http.StatusBadRequest)
+			return
+		}
+
+		body, err := ioutil.ReadAll(io.LimitReader(r.Body, limits.MaxSize+1))
+		if err != nil {
+			batchError(w, http.StatusBadRequest)
+			return
+		}
+		if int64(len(body)) > limits.MaxSize {
+			batchError(w, http.StatusRequestEntityTooLarge)
+			return
+		}
+
+		mediaType, params, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+		multi := mediaType == "multipart/mixed"
+
+		var subs []*http.Request
+		if multi {
+			subs, err = parseMultipartBatch(body, params["boundary"], limits.MaxRequests+1)
+		} else {
+			subs, err = parseJSONBatch(body)
+		}
+		if err != nil {
+			batchError(w, http.StatusBadRequest)
+			return
+		}
+		if len(subs) > limits.MaxRequests {
+			batchError(w, http.StatusRequestEntityTooLarge)
+			return
+		}
+
+		recorders := make([]*batchRecorder, len(subs))
+		sem := make(chan struct{}, limits.Concurrency)
+		wg := &sync.WaitGroup{}
+		for i, sub := range subs {
+			recorders[i] = newBatchRecorder()
+			for k, values := range r.Header {
+				if _, ok := sub.Header[k]; !ok && k != "Content-Type" && k != "Content-Length" {
+					sub.Header[k] = values
+				}
+			}
+			sub.RemoteAddr = r.RemoteAddr
+			sub.Host = r.Host
+			// Sub-requests are canceled with the batch request but
+			// do not see the route and params of the batch route
+			sub = sub.WithContext(context.WithValue(detach(r.Context()), batchKey{}, true))
+
+			wg.Add(1)
+			sem <- struct{}{}
+			go func(rec *batchRecorder, sub *http.Request) {
+				defer func() {
+					<-sem
+					wg.Done()
+				}()
+				v.guard(0, rec, sub)
+			}(recorders[i], sub)
+		}
+		wg.Wait()
+
+		if multi {
+			writeMultipartBatch(w, recorders)
+		} else {
+			writeJSONBatch(w, recorders)
+		}
+	}
+	return v.PostHandler(path, http.HandlerFunc(handler))
+}
+
+// detachedContext is a context carrying the cancellation and
+// deadline of its parent but only the values set by net/http
+type detachedContext struct {
+	context.Context
+}
+
+// detach returns a context canceled with ctx that does not
+// carry the values set on ctx while routing the request
+func detach(ctx context.Context) context.Context {
+	return &detachedContext{ctx}
+}
+
+func (ctx *detachedContext) Value(key interface{}) interface{} {
+	if key == http.ServerContextKey || key == http.LocalAddrContextKey {
+		return ctx.Context.Value(key)
+	}
+	return nil
+}
+
+// parseJSONBatch parses a JSON batch into sub-requests
+func parseJSONBatch(body []byte) ([]*http.Request, error) {
+	batch := make([]BatchRequest, 0)
+	if err := json.Unmarshal(body, &batch); err != nil {
+		return nil, err
+	}
+	subs := make([]*http.Request, 0, len(batch))
+	for _, b := range batch {
+		var r io.Reader
+		if len(b.Body) > 0 && string(b.Body) != "null" {
+			r = bytes.NewReader(b.Body)
+		}
+		method := b.Method
+		if method == "" {
+			method = "GET"
+		}
+		sub, err := http.NewRequest(strings.ToUpper(method), b.Path, r)
+		if err != nil {
+			return nil, err
+		}
+		for k, value := range b.Headers {
+			sub.Header.Set(k, value)
+		}
+		if r != nil && sub.Header.Get("Content-Type") == "" {
+			sub.Header.Set("Content-Type", "application/json")
+		}
+		subs = append(subs, sub)
+	}
+	return subs, nil
+}
+
+// parseMultipartBatch parses a multipart/mixed batch of application/http
+// parts into sub-requests. At most max sub-requests are parsed
+func parseMultipartBatch(body []byte, boundary string, max int) ([]*http.Request, error) {
+	if boundary == "" {
+		return nil, errors.New("verto: missing multipart boundary")
+	}
+	mr := multipart.NewReader(bytes.NewReader(body), boundary)
+	subs := make([]*http.Request, 0)
+	for len(subs) < max {
+		part, err := mr.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		sub, err := http.ReadRequest(bufio.NewReader(part))
+		if err != nil {
+			return nil, err
+		}
+		b, err := ioutil.ReadAll(sub.Body)
+		if err != nil {
+			return nil, err
+		}
+		sub.Body = ioutil.NopCloser(bytes.NewReader(b))
+		sub.RequestURI = ""
+		subs = append(subs, sub)
+	}
+	return subs, nil
+}
+
+// writeJSONBatch writes the recorded responses as a JSON array
+func writeJSONBatch(w http.ResponseWriter, recorders []*batchRecorder) {
+	responses := make([]BatchResponse, len(recorders))
+	for i, rec := range recorders {
+		res := BatchResponse{Status: rec.status, Headers: make(map[string]string)}
+		for k := range rec.header {
+			res.Headers[k] = rec.header.Get(k)
+		}
+		if rec.body.Len() > 0 {
+			if json.Valid(rec.body.Bytes()) {
+				res.Body = json.RawMessage(rec.body.Bytes())
+			} else {
+				res.Body, _ = json.Marshal(rec.body.String())
+			}
+		}
+		responses[i] = res
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(responses)
+}
+
+// writeMultipartBatch writes the recorded responses
+// as multipart/mixed application/http parts
+func writeMultipartBatch(w http.ResponseWriter, recorders []*batchRecorder) {
+	mw := multipart.NewWriter(w)
+	w.Header().Set("Content-Type", "multipart/mixed; boundary="+mw.Boundary())
+	for _, rec := range recorders {
+		part, err := mw.CreatePart(textproto.MIMEHeader{"Content-Type": {"application/http"}})
+		if err != nil {
+			return
+		}
+		fmt.Fprintf(part, "HTTP/1.1 %d %s\r\n", rec.status, http.StatusText(rec.status))
+		rec.header.Set("Content-Length", strconv.Itoa(rec.body.Len()))
+		rec.header.Write(part)
+		io.WriteString(part, "\r\n")
+		part.Write(rec.body.Bytes())
+	}
+	mw.Close()
+}
+
+// batchError writes an error response to a batch request
+func batchError(w http.ResponseWriter, status int) {
+	w.WriteHeader(status)
+	fmt.Fprint(w, http.StatusText(status)+".")
+}
+
+// batchRecorder is an http.ResponseWriter
+// recording the response to a sub-request
+type batchRecorder struct {
+	header      http.Header
+	body        *bytes.Buffer
+	status      int
+	wroteHeader bool
+}
+
+func newBatchRecorder() *batchRecorder {
+	return &batchRecorder{
+		header: make(http.Header),
+		body:   &bytes.Buffer{},
+		status: http.StatusOK,
+	}
+}
+
+func (rec *batchRecorder) Header() http.Header {
+	return rec.header
+}
+
+func (rec *batchRecorder) Write(b []byte) (int, error) {
+	if !rec.wroteHeader {
+		rec.WriteHeader(http.StatusOK)
+	}
+	return rec.body.Write(b)
+}
+
+func (rec *batchRecorder) WriteHeader(code int) {
+	if rec.wroteHeader {
+		return
+	}
+	rec.status = code
+	rec.wroteHeader = true
+}
@@ -0,0 +1,167 @@
+package verto
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestBatch(t *testing.T) {
+	defer func() {
+		err := recover()
+		if err != nil {
+			t.Errorf(err.(error).Error())
+		}
+	}()
+
+	err := "Failed batch."
+
+	v := New()
+	v.Logger = &NilLogger{}
+	v.Get("/users/{id}", func(c *Context) (interface{}, error) {
+		return `"user ` + c.Get("id") + `"`, nil
+	})
+	v.Post("/echo", func(c *Context) (interface{}, error) {
+		b, _ := ioutil.ReadAll(c.Request.Body)
+		return c.Request.Header.Get("Authorization") + ":" + string(b), nil
+	})
+	v.Batch("/batch", BatchLimits{MaxRequests: 3})
+	h := &HttpHandler{v}
+
+	// Test JSON batch
+	body := `[{"method": "GET", "path": "/users/1"},
+		{"method": "POST", "path": "/echo", "body": {"a": 1}},
+		{"path": "/missing"}]`
+	r, _ := http.NewRequest("POST", "http://test.com/batch", strings.NewReader(body))
+	r.Header.Set("Content-Type", "application/json")
+	r.Header.Set("Authorization", "token")
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+
+	responses := make([]BatchResponse, 0)
+	if e := json.Unmarshal(w.Body.Bytes(), &responses); e != nil || len(responses) != 3 {
+		t.Fatalf(err)
+	}
+	if responses[0].Status != 200 || string(responses[0].Body) != `"user 1"` {
+		t.Errorf(err)
+	}
+	if responses[1].Status != 200 || string(responses[1].Body) != `"token:{\"a\": 1}"` {
+		t.Errorf(err)
+	}
+	if responses[2].Status != 404 {
+		t.Errorf(err)
+	}
+
+	// Test limits
+	body = `[{"path": "/users/1"}, {"path": "/users/2"}, {"path": "/users/3"}, {"path": "/users/4"}]`
+	r, _ = http.NewRequest("POST", "http://test.com/batch", strings.NewReader(body))
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	if w.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf(err)
+	}
+
+	// Test nested batches are rejected
+	body = `[{"method": "POST", "path": "/batch", "body": []}]`
+	r, _ = http.NewRequest("POST", "http://test.com/batch", strings.NewReader(body))
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	responses = make([]BatchResponse, 0)
+	json.Unmarshal(w.Body.Bytes(), &responses)
+	if len(responses) != 1 || responses[0].Status != 400 {
+		t.Errorf(err)
+	}
+
+	// Test multipart/mixed batch
+	buf := &bytes.Buffer{}
+	mw := multipart.NewWriter(buf)
+	part, _ := mw.CreatePart(map[string][]string{"Content-Type": {"application/http"}})
+	part.Write([]byte("GET /users/2 HTTP/1.1\r\nHost: test.com\r\n\r\n"))
+	part, _ = mw.CreatePart(map[string][]string{"Content-Type": {"application/http"}})
+	part.Write([]byte("POST /echo HTTP/1.1\r\nHost: test.com\r\nContent-Length: 2\r\n\r\nhi"))
+	mw.Close()
+
+	r, _ = http.NewRequest("POST", "http://test.com/batch", buf)
+	r.Header.Set("Content-Type", "multipart/mixed; boundary="+mw.Boundary())
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+
+	_, params, _ := mime.ParseMediaType(w.Header().Get("Content-Type"))
+	mr := multipart.NewReader(w.Body, params["boundary"])
+	expected := []string{`"user 2"`, ":hi"}
+	for _, s := range expected {
+		p, e := mr.NextPart()
+		if e != nil {
+			t.Fatalf(err)
+		}
+		res, e := http.ReadResponse(bufio.NewReader(p), nil)
+		if e != nil {
+			t.Fatalf(err)
+		}
+		b, _ := ioutil.ReadAll(res.Body)
+		if res.StatusCode != 200 || string(b) != s {
+			t.Errorf(err)
+		}
+	}
+}
+
+func TestBatchSubRequests(t *testing.T) {
+	defer func() {
+		err := recover()
+		if err != nil {
+			t.Errorf(err.(error).Error())
+		}
+	}()
+
+	err := "Failed batch sub-requests."
+
+	v := New()
+	v.Logger = &NilLogger{}
+	v.Guard(PluginFunc(func(c *Context, next http.HandlerFunc) {
+		if c.Request.Header.Get("X-Blocked") != "" {
+			c.Response.WriteHeader(http.StatusForbidden)
+			return
+		}
+		next(c.Response, c.Request)
+	}))
+	v.Get("/whoami", func(c *Context) (interface{}, error) {
+		return `"` + c.Param("tenant") + ":" + c.RouteName() + `"`, nil
+	}).Name("whoami")
+	v.Batch("/tenants/{tenant}/batch", BatchLimits{}).Name("batch")
+	v.Batch("/other", BatchLimits{})
+	h := &HttpHandler{v}
+
+	body := `[{"path": "/whoami"},
+		{"path": "/whoami", "headers": {"X-Blocked": "1"}},
+		{"method": "POST", "path": "/other", "body": []}]`
+	r, _ := http.NewRequest("POST", "http://test.com/tenants/a/batch", strings.NewReader(body))
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+
+	responses := make([]BatchResponse, 0)
+	if e := json.Unmarshal(w.Body.Bytes(), &responses); e != nil || len(responses) != 3 {
+		t.Fatalf(err)
+	}
+
+	// Test sub-requests do not inherit the batch route
+	if responses[0].Status != 200 || string(responses[0].Body) != `":whoami"` {
+		t.Errorf(err)
+	}
+
+	// Test sub-requests pass through the guards
+	if responses[1].Status != http.StatusForbidden {
+		t.Errorf(err)
+	}
+
+	// Test batches on other routes cannot be nested
+	if responses[2].Status != http.StatusBadRequest {
+		t.Errorf(err)
+	}
+}
@@ -0,0 +1,161 @@
+package verto
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// Binder is the interface for request body decoders used by Context.Bind
+type Binder interface {
+	// Bind decodes the request body of c onto dst
+	Bind(c *Context, dst interface{}) error
+}
+
+// BinderFunc wraps functions so that they implement Binder
+type BinderFunc func(c *Context, dst interface{}) error
+
+// Bind calls the function wrapped by BinderFunc
+func (bf BinderFunc) Bind(c *Context, dst interface{}) error {
+	return bf(c, dst)
+}
+
+var (
+	binders = map[string]Binder{
+		"application/json":                  BinderFunc((*Context).BindJSON),
+		"application/xml":                   BinderFunc((*Context).BindXML),
+		"text/xml":                          BinderFunc((*Context).BindXML),
+		"application/x-www-form-urlencoded": BinderFunc((*Context).DecodeForm),
+		"multipart/form-data":               BinderFunc((*Context).DecodeForm),
+	}
+	bindersMutex = &sync.RWMutex{}
+)
+
+// RegisterBinder registers binder for requests with mediaType
+// (e.g. "application/msgpack"), replacing any binder
+// registered for it
+func RegisterBinder(mediaType string, binder Binder) {
+	bindersMutex.Lock()
+	defer bindersMutex.Unlock()
+
+	binders[strings.ToLower(mediaType)] = binder
+}
+
+// Bind decodes the request body onto dst with the binder registered for
+// the request's Content-Type. JSON, XML and URL-encoded and multipart forms
+// are supported by default, including structured syntax suffixes such as
+// application/vnd.api+json. Requests with other content types are rejected
+// with a 415 HTTPError. Further binders are added with RegisterBinder.
+//
+// Example usage:
+//
+//	var user User
+//	if err := c.Bind(&user); err != nil {
+//		return nil, err
+//	}
+func (c *Context) Bind(dst interface{}) error {
+	mediaType, _, err := mime.ParseMediaType(c.Request.Header.Get("Content-Type"))
+	if err != nil {
+		return NewError(http.StatusUnsupportedMediaType, "request must have a valid Content-Type")
+	}
+
+	bindersMutex.RLock()
+	binder, ok := binders[mediaType]
+	if !ok {
+		if i := strings.LastIndex(mediaType, "+"); i >= 0 {
+			binder, ok = binders["application/"+mediaType[i+1:]]
+		}
+	}
+	bindersMutex.RUnlock()
+
+	if !ok {
+		return NewError(http.StatusUnsupportedMediaType, fmt.Sprintf("unsupported Content-Type %s", mediaType))
+	}
+	return binder.Bind(c, dst)
+}
+
+// MaxBodySize is the maximum number of request body bytes
+// decoded by Context.BindJSON and Context.BindXML
+var MaxBodySize int64 = 1 << 20
+
+// BindJSON decodes the JSON request body onto dst. Bodies larger than
+// MaxBodySize are rejected. Errors are HTTPErrors with status 400 for
+// empty or malformed bodies, 413 for bodies that are too large and 422
+// for values that don't fit their fields. If dst has a Validate() error
+// method it is called after decoding and its error returned with status 422.
+//
+// Example usage:
+//
+//	var user User
+//	if err := c.BindJSON(&user); err != nil {
+//		return nil, err
+//	}
+func (c *Context) BindJSON(dst interface{}) error {
+	body := http.MaxBytesReader(c.Response, c.Request.Body, MaxBodySize)
+	dec := json.NewDecoder(body)
+	if err := dec.Decode(dst); err != nil {
+		return bindError(err)
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return NewError(http.StatusBadRequest, "request body must contain a single JSON value")
+	}
+	return validateBound(dst)
+}
+
+// BindXML decodes the XML request body onto dst. Size limits,
+// errors and validation are as described for BindJSON
+func (c *Context) BindXML(dst interface{}) error {
+	body := http.MaxBytesReader(c.Response, c.Request.Body, MaxBodySize)
+	if err := xml.NewDecoder(body).Decode(dst); err != nil {
+		return bindError(err)
+	}
+	return validateBound(dst)
+}
+
+// bindError converts a decoding error into an HTTPError
+// with a message describing the problem to the client
+func bindError(err error) error {
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	var xmlErr *xml.SyntaxError
+	var maxErr *http.MaxBytesError
+
+	switch {
+	case err == io.EOF:
+		err = errors.New("request body is empty")
+	case errors.Is(err, io.ErrUnexpectedEOF):
+		err = errors.New("request body is truncated")
+	case errors.As(err, &maxErr):
+		return NewError(http.StatusRequestEntityTooLarge, fmt.Sprintf("request body must not be larger than %d bytes", maxErr.Limit))
+	case errors.As(err, &syntaxErr):
+		err = fmt.Errorf("malformed JSON at offset %d: %s", syntaxErr.Offset, syntaxErr.Error())
+	case errors.As(err, &xmlErr):
+		err = fmt.Errorf("malformed XML at line %d: %s", xmlErr.Line, xmlErr.Msg)
+	case errors.As(err, &typeErr):
+		if typeErr.Field != "" {
+			err = fmt.Errorf("field %s must be of type %s", typeErr.Field, typeErr.Type)
+		} else {
+			err = fmt.Errorf("request body must be of type %s", typeErr.Type)
+		}
+		return &HTTPError{Status: http.StatusUnprocessableEntity, Message: err.Error(), Err: err}
+	}
+	return &HTTPError{Status: http.StatusBadRequest, Message: err.Error(), Err: err}
+}
+
+// validateBound calls the Validate method of dst if it has one
+func validateBound(dst interface{}) error {
+	if validator, ok := dst.(interface {
+		Validate() error
+	}); ok {
+		if err := validator.Validate(); err != nil {
+			return &HTTPError{Status: http.StatusUnprocessableEntity, Message: err.Error(), Err: err}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,162 @@
+package verto
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type bindUser struct {
+	Name string `json:"name" xml:"name"`
+	Age  int    `json:"age" xml:"age"`
+}
+
+func (u *bindUser) Validate() error {
+	if u.Name == "" {
+		return errors.New("name is required")
+	}
+	return nil
+}
+
+func TestBindJSON(t *testing.T) {
+	defer func() {
+		err := recover()
+		if err != nil {
+			t.Errorf(err.(error).Error())
+		}
+	}()
+
+	err := "Failed bind JSON."
+
+	bind := func(body string) (*bindUser, error) {
+		r, _ := http.NewRequest("POST", "http://test.com", strings.NewReader(body))
+		c := NewContext(httptest.NewRecorder(), r, nil, nil)
+		u := &bindUser{}
+		return u, c.BindJSON(u)
+	}
+	status := func(e error) int {
+		if se, ok := e.(*HTTPError); ok {
+			return se.Status
+		}
+		return 0
+	}
+
+	u, e := bind(`{"name":"bob","age":30}`)
+	if e != nil || u.Name != "bob" || u.Age != 30 {
+		t.Errorf(err)
+	}
+	if _, e = bind(``); status(e) != 400 || e.Error() != "request body is empty" {
+		t.Errorf(err)
+	}
+	if _, e = bind(`{"name":`); status(e) != 400 {
+		t.Errorf(err)
+	}
+	if _, e = bind(`{"name":"bob"}{}`); status(e) != 400 {
+		t.Errorf(err)
+	}
+	if _, e = bind(`{"name":"bob","age":"old"}`); status(e) != 422 || e.Error() != "field age must be of type int" {
+		t.Errorf(err)
+	}
+	if _, e = bind(`{"age":3}`); status(e) != 422 || e.Error() != "name is required" {
+		t.Errorf(err)
+	}
+
+	// Test size limit
+	max := MaxBodySize
+	defer func() { MaxBodySize = max }()
+	MaxBodySize = 10
+	if _, e = bind(`{"name":"bobbybobbybob"}`); status(e) != 413 {
+		t.Errorf(err)
+	}
+}
+
+func TestBindXML(t *testing.T) {
+	defer func() {
+		err := recover()
+		if err != nil {
+			t.Errorf(err.(error).Error())
+		}
+	}()
+
+	err := "Failed bind XML."
+
+	bind := func(body string) (*bindUser, error) {
+		r, _ := http.NewRequest("POST", "http://test.com", strings.NewReader(body))
+		c := NewContext(httptest.NewRecorder(), r, nil, nil)
+		u := &bindUser{}
+		return u, c.BindXML(u)
+	}
+
+	u, e := bind(`<user><name>bob</name><age>30</age></user>`)
+	if e != nil || u.Name != "bob" || u.Age != 30 {
+		t.Errorf(err)
+	}
+	if _, e = bind(`<user><name>bob</user>`); e == nil || e.(*HTTPError).Status != 400 {
+		t.Errorf(err)
+	}
+}
+
+func TestBind(t *testing.T) {
+	defer func() {
+		err := recover()
+		if err != nil {
+			t.Errorf(err.(error).Error())
+		}
+	}()
+
+	err := "Failed bind."
+
+	type formUser struct {
+		Name string `form:"name"`
+		Age  int    `form:"age"`
+	}
+
+	bind := func(contentType, body string, dst interface{}) error {
+		r, _ := http.NewRequest("POST", "http://test.com", strings.NewReader(body))
+		if contentType != "" {
+			r.Header.Set("Content-Type", contentType)
+		}
+		c := NewContext(httptest.NewRecorder(), r, nil, nil)
+		return c.Bind(dst)
+	}
+
+	u := &bindUser{}
+	if e := bind("application/json; charset=utf-8", `{"name":"bob"}`, u); e != nil || u.Name != "bob" {
+		t.Errorf(err)
+	}
+	u = &bindUser{}
+	if e := bind("application/vnd.api+json", `{"name":"ann"}`, u); e != nil || u.Name != "ann" {
+		t.Errorf(err)
+	}
+	u = &bindUser{}
+	if e := bind("text/xml", `<user><name>joe</name></user>`, u); e != nil || u.Name != "joe" {
+		t.Errorf(err)
+	}
+	f := &formUser{}
+	if e := bind("application/x-www-form-urlencoded", "name=sue&age=4", f); e != nil || f.Name != "sue" || f.Age != 4 {
+		t.Errorf(err)
+	}
+	if e := bind("application/msgpack", "", u); e == nil || e.(*HTTPError).Status != 415 {
+		t.Errorf(err)
+	}
+	if e := bind("", "", u); e == nil || e.(*HTTPError).Status != 415 {
+		t.Errorf(err)
+	}
+
+	// Test custom binders
+	RegisterBinder("application/msgpack", BinderFunc(func(c *Context, dst interface{}) error {
+		dst.(*bindUser).Name = "msgpack"
+		return nil
+	}))
+	defer func() {
+		bindersMutex.Lock()
+		delete(binders, "application/msgpack")
+		bindersMutex.Unlock()
+	}()
+	u = &bindUser{}
+	if e := bind("application/msgpack", "", u); e != nil || u.Name != "msgpack" {
+		t.Errorf(err)
+	}
+}
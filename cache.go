@@ -0,0 +1,122 @@
+package verto
+
+import (
+	"github.com/boxtown/verto/mux"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CachePolicy describes the Cache-Control and Expires
+// headers sent with responses
+type CachePolicy struct {
+	// MaxAge is the max-age directive. Responses with a
+	// positive MaxAge also carry a matching Expires header
+	MaxAge time.Duration
+
+	// SharedMaxAge is the s-maxage directive for shared caches
+	SharedMaxAge time.Duration
+
+	// StaleWhileRevalidate is the stale-while-revalidate directive
+	StaleWhileRevalidate time.Duration
+
+	// Public allows shared caches to store responses
+	// to authenticated requests
+	Public bool
+
+	// Private restricts storage to the client's cache
+	Private bool
+
+	// NoCache requires revalidation before cached responses are used
+	NoCache bool
+
+	// NoStore forbids storing responses
+	NoStore bool
+
+	// MustRevalidate forbids serving stale responses
+	MustRevalidate bool
+
+	// Immutable marks responses as never changing while fresh
+	Immutable bool
+}
+
+// NoStore is the policy applied to authenticated requests under
+// a CacheControl policy that does not explicitly allow caching of
+// authenticated responses through Private or Public
+var NoStore = CachePolicy{NoStore: true}
+
+// String returns the Cache-Control header value of the policy
+func (p CachePolicy) String() string {
+	directives := make([]string, 0)
+	add := func(ok bool, directive string) {
+		if ok {
+			directives = append(directives, directive)
+		}
+	}
+	seconds := func(d time.Duration) string {
+		return strconv.FormatInt(int64(d/time.Second), 10)
+	}
+
+	add(p.Public, "public")
+	add(p.Private, "private")
+	add(p.NoCache, "no-cache")
+	add(p.NoStore, "no-store")
+	add(p.MaxAge > 0, "max-age="+seconds(p.MaxAge))
+	add(p.SharedMaxAge > 0, "s-maxage="+seconds(p.SharedMaxAge))
+	add(p.StaleWhileRevalidate > 0, "stale-while-revalidate="+seconds(p.StaleWhileRevalidate))
+	add(p.MustRevalidate, "must-revalidate")
+	add(p.Immutable, "immutable")
+	return strings.Join(directives, ", ")
+}
+
+// CacheControl sets Cache-Control and Expires headers according to
+// policy on responses from all routes under the Group. Routes can
+// override the policy with Endpoint.CacheControl and handlers can
+// override it by setting the headers themselves. Authenticated
+// requests, those carrying an Authorization header, receive NoStore
+// unless policy is Private or Public.
+//
+// Example usage:
+//
+//	catalog := v.Group("GET", "/catalog")
+//	catalog.CacheControl(verto.CachePolicy{Public: true, MaxAge: time.Hour})
+//	catalog.Add("/specials", specials).CacheControl(verto.CachePolicy{MaxAge: time.Minute})
+func (g *Group) CacheControl(policy CachePolicy) *Group {
+	return g.UsePluginHandler(cachePlugin(policy))
+}
+
+// CacheControl sets Cache-Control and Expires headers according to
+// policy on responses from the route represented by the Endpoint,
+// overriding the policy of its Group
+func (ep *Endpoint) CacheControl(policy CachePolicy) *Endpoint {
+	return ep.UsePluginHandler(cachePlugin(policy))
+}
+
+// cachePlugin returns a plugin setting the headers of policy
+func cachePlugin(policy CachePolicy) mux.PluginFunc {
+	return func(w http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+		p := policy
+		if r.Header.Get("Authorization") != "" && !p.Private && !p.Public {
+			p = NoStore
+		}
+		setCacheHeaders(w.Header(), p, time.Now())
+		next(w, r)
+	}
+}
+
+// setCacheHeaders sets the Cache-Control and Expires headers of p on h
+func setCacheHeaders(h http.Header, p CachePolicy, now time.Time) {
+	value := p.String()
+	if value == "" {
+		h.Del("Cache-Control")
+		h.Del("Expires")
+		return
+	}
+	h.Set("Cache-Control", value)
+	if p.NoStore || p.NoCache || p.MaxAge <= 0 {
+		h.Set("Expires", time.Unix(0, 0).UTC().Format(http.TimeFormat))
+		return
+	}
+	h.Set("Expires", now.Add(p.MaxAge).UTC().Format(http.TimeFormat))
+}
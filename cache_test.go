@@ -0,0 +1,89 @@
+package verto
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestCachePolicy(t *testing.T) {
+	defer func() {
+		err := recover()
+		if err != nil {
+			t.Errorf(err.(error).Error())
+		}
+	}()
+
+	err := "Failed cache policy."
+
+	p := CachePolicy{Public: true, MaxAge: time.Hour, SharedMaxAge: time.Minute, MustRevalidate: true}
+	if p.String() != "public, max-age=3600, s-maxage=60, must-revalidate" {
+		t.Errorf(err)
+	}
+	if NoStore.String() != "no-store" || (CachePolicy{}).String() != "" {
+		t.Errorf(err)
+	}
+}
+
+func TestGroupCacheControl(t *testing.T) {
+	defer func() {
+		err := recover()
+		if err != nil {
+			t.Errorf(err.(error).Error())
+		}
+	}()
+
+	err := "Failed group cache control."
+
+	v := New()
+	v.Logger = &NilLogger{}
+	handler := func(c *Context) (interface{}, error) {
+		return "ok", nil
+	}
+	catalog := v.Group("GET", "/catalog")
+	catalog.CacheControl(CachePolicy{MaxAge: time.Hour})
+	catalog.Add("/items", handler)
+	catalog.Add("/specials", handler).CacheControl(CachePolicy{Public: true, MaxAge: time.Minute})
+	catalog.Add("/custom", func(c *Context) (interface{}, error) {
+		c.Response.Header().Set("Cache-Control", "no-cache")
+		return "ok", nil
+	})
+	h := &HttpHandler{v}
+
+	serve := func(path string, authorization string) *httptest.ResponseRecorder {
+		r, _ := http.NewRequest("GET", "http://test.com"+path, nil)
+		if authorization != "" {
+			r.Header.Set("Authorization", authorization)
+		}
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		return w
+	}
+
+	w := serve("/catalog/items", "")
+	if w.Header().Get("Cache-Control") != "max-age=3600" {
+		t.Errorf(err)
+	}
+	expires, e := http.ParseTime(w.Header().Get("Expires"))
+	if e != nil || expires.Sub(time.Now()) < 59*time.Minute {
+		t.Errorf(err)
+	}
+
+	// Test per-route overrides
+	if serve("/catalog/specials", "").Header().Get("Cache-Control") != "public, max-age=60" {
+		t.Errorf(err)
+	}
+	if serve("/catalog/custom", "").Header().Get("Cache-Control") != "no-cache" {
+		t.Errorf(err)
+	}
+
+	// Test authenticated requests are not stored unless allowed
+	w = serve("/catalog/items", "Bearer x")
+	if w.Header().Get("Cache-Control") != "no-store" || w.Header().Get("Expires") != "Thu, 01 Jan 1970 00:00:00 GMT" {
+		t.Errorf(err)
+	}
+	if serve("/catalog/specials", "Bearer x").Header().Get("Cache-Control") != "public, max-age=60" {
+		t.Errorf(err)
+	}
+}
@@ -0,0 +1,115 @@
+package client
+
+import (
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen is returned for requests to hosts whose circuit is open
+var ErrCircuitOpen = errors.New("client: circuit open")
+
+// CircuitBreaker tracks failures per host. After Threshold consecutive
+// failures the circuit of a host opens and requests to it fail fast with
+// ErrCircuitOpen. After Cooldown a single trial request is let through;
+// its success closes the circuit and its failure reopens it
+type CircuitBreaker struct {
+	// Threshold is the number of consecutive failures opening a circuit
+	Threshold int
+
+	// Cooldown is how long a circuit stays open
+	Cooldown time.Duration
+
+	// Now returns the current time. Defaults to time.Now
+	Now func() time.Time
+
+	hosts map[string]*circuit
+	mutex sync.Mutex
+}
+
+// circuit is the state of the circuit of a host
+type circuit struct {
+	failures int
+	openedAt time.Time
+	trial    bool
+}
+
+// NewCircuitBreaker returns a CircuitBreaker opening circuits after
+// threshold consecutive failures for cooldown
+func NewCircuitBreaker(threshold int, cooldown time.Duration) *CircuitBreaker {
+	return &CircuitBreaker{
+		Threshold: threshold,
+		Cooldown:  cooldown,
+		hosts:     make(map[string]*circuit),
+	}
+}
+
+// Open returns whether the circuit of host is open
+func (cb *CircuitBreaker) Open(host string) bool {
+	cb.mutex.Lock()
+	defer cb.mutex.Unlock()
+
+	c, ok := cb.hosts[host]
+	return ok && c.failures >= cb.Threshold && cb.now().Sub(c.openedAt) < cb.Cooldown
+}
+
+// allow returns whether a request to host may be sent
+func (cb *CircuitBreaker) allow(host string) bool {
+	cb.mutex.Lock()
+	defer cb.mutex.Unlock()
+
+	c, ok := cb.hosts[host]
+	if !ok || c.failures < cb.Threshold {
+		return true
+	}
+	if cb.now().Sub(c.openedAt) < cb.Cooldown || c.trial {
+		return false
+	}
+	c.trial = true
+	return true
+}
+
+// record records the outcome of a request to host
+func (cb *CircuitBreaker) record(host string, failed bool) {
+	cb.mutex.Lock()
+	defer cb.mutex.Unlock()
+
+	c, ok := cb.hosts[host]
+	if !ok {
+		c = &circuit{}
+		cb.hosts[host] = c
+	}
+	c.trial = false
+	if !failed {
+		c.failures = 0
+		return
+	}
+	c.failures++
+	if c.failures >= cb.Threshold {
+		c.openedAt = cb.now()
+	}
+}
+
+func (cb *CircuitBreaker) now() time.Time {
+	if cb.Now == nil {
+		return time.Now()
+	}
+	return cb.Now()
+}
+
+// Breaker returns Middleware failing requests to hosts whose circuit
+// in cb is open. Network errors and 5xx responses count as failures
+func Breaker(cb *CircuitBreaker) Middleware {
+	return func(next http.RoundTripper) http.RoundTripper {
+		return RoundTripperFunc(func(r *http.Request) (*http.Response, error) {
+			host := r.URL.Host
+			if !cb.allow(host) {
+				return nil, ErrCircuitOpen
+			}
+			resp, err := next.RoundTrip(r)
+			cb.record(host, err != nil || resp.StatusCode >= 500)
+			return resp, err
+		})
+	}
+}
@@ -0,0 +1,98 @@
+// Package client provides outbound HTTP client middleware for Verto.
+// Middleware wraps http.RoundTrippers the way plugins wrap handlers:
+// retries with backoff, per-host circuit breaking, propagation of request
+// and trace ids from the incoming request and metrics published on the
+// Verto instance's event bus. Requests sent through Context.Do with a
+// client built by New share observability with inbound request handling.
+package client
+
+import (
+	"github.com/boxtown/verto"
+	"net/http"
+	"time"
+)
+
+// Middleware wraps an http.RoundTripper
+type Middleware func(next http.RoundTripper) http.RoundTripper
+
+// RoundTripperFunc wraps functions so that they implement http.RoundTripper
+type RoundTripperFunc func(r *http.Request) (*http.Response, error)
+
+// RoundTrip calls the function wrapped by RoundTripperFunc
+func (f RoundTripperFunc) RoundTrip(r *http.Request) (*http.Response, error) {
+	return f(r)
+}
+
+// Chain wraps base in middleware. The first middleware is the
+// outermost and sees requests first. If base is nil,
+// http.DefaultTransport is used
+func Chain(base http.RoundTripper, middleware ...Middleware) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	for i := len(middleware) - 1; i >= 0; i-- {
+		base = middleware[i](base)
+	}
+	return base
+}
+
+// New returns an http.Client with the default middleware chain configured
+// from v and installs it as v's Client so that Context.Do uses it. The
+// default chain propagates request and trace ids, publishes metrics on v's
+// event bus, breaks circuits to failing hosts and retries failed idempotent
+// requests up to twice. Additional middleware is applied innermost.
+//
+// Example usage:
+//
+//	client.New(v)
+//	v.Get("/profile", func(c *verto.Context) (interface{}, error) {
+//		req, _ := http.NewRequest("GET", "http://users.internal/users/1", nil)
+//		resp, err := c.Do(req)
+//		...
+//	})
+func New(v *verto.Verto, middleware ...Middleware) *http.Client {
+	chain := append([]Middleware{
+		Propagate(),
+		Metrics(v.Events),
+		Breaker(NewCircuitBreaker(5, 30*time.Second)),
+		Retry(2, nil),
+	}, middleware...)
+
+	c := &http.Client{
+		Transport: Chain(nil, chain...),
+		Timeout:   30 * time.Second,
+	}
+	v.Client = c
+	return c
+}
+
+// Propagate returns Middleware copying headers of the incoming request to
+// outbound requests sent through Context.Do unless already set. If no
+// headers are given, X-Request-Id, Traceparent and Tracestate are propagated
+func Propagate(headers ...string) Middleware {
+	if len(headers) == 0 {
+		headers = []string{"X-Request-Id", "Traceparent", "Tracestate"}
+	}
+	return func(next http.RoundTripper) http.RoundTripper {
+		return RoundTripperFunc(func(r *http.Request) (*http.Response, error) {
+			inbound := verto.Inbound(r.Context())
+			if inbound == nil {
+				return next.RoundTrip(r)
+			}
+			copied := false
+			for _, h := range headers {
+				value := inbound.Header.Get(h)
+				if value == "" || r.Header.Get(h) != "" {
+					continue
+				}
+				if !copied {
+					// RoundTrippers must not modify the caller's request
+					r = r.Clone(r.Context())
+					copied = true
+				}
+				r.Header.Set(h, value)
+			}
+			return next.RoundTrip(r)
+		})
+	}
+}
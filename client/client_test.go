@@ -0,0 +1,149 @@
+package client
+
+import (
+	"errors"
+	"github.com/boxtown/verto"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestRetry(t *testing.T) {
+	defer func() {
+		err := recover()
+		if err != nil {
+			t.Errorf(err.(error).Error())
+		}
+	}()
+
+	err := "Failed retry."
+
+	calls := 0
+	base := RoundTripperFunc(func(r *http.Request) (*http.Response, error) {
+		calls++
+		if calls < 3 {
+			return &http.Response{StatusCode: 503, Header: http.Header{}, Body: http.NoBody}, nil
+		}
+		return &http.Response{StatusCode: 200, Header: http.Header{}, Body: http.NoBody}, nil
+	})
+	rt := Chain(base, Retry(2, func(n int) time.Duration { return time.Millisecond }))
+
+	r, _ := http.NewRequest("GET", "http://test.com/", nil)
+	resp, e := rt.RoundTrip(r)
+	if e != nil || resp.StatusCode != 200 || calls != 3 {
+		t.Errorf(err)
+	}
+
+	// Test non-idempotent requests are not retried
+	calls = 0
+	r, _ = http.NewRequest("POST", "http://test.com/", nil)
+	resp, e = rt.RoundTrip(r)
+	if e != nil || resp.StatusCode != 503 || calls != 1 {
+		t.Errorf(err)
+	}
+}
+
+func TestBreaker(t *testing.T) {
+	defer func() {
+		err := recover()
+		if err != nil {
+			t.Errorf(err.(error).Error())
+		}
+	}()
+
+	err := "Failed breaker."
+
+	now := time.Now()
+	cb := NewCircuitBreaker(2, time.Minute)
+	cb.Now = func() time.Time { return now }
+
+	fail := true
+	calls := 0
+	base := RoundTripperFunc(func(r *http.Request) (*http.Response, error) {
+		calls++
+		if fail {
+			return nil, errors.New("down")
+		}
+		return &http.Response{StatusCode: 200, Header: http.Header{}, Body: http.NoBody}, nil
+	})
+	rt := Chain(base, Breaker(cb))
+
+	r, _ := http.NewRequest("GET", "http://test.com/", nil)
+	rt.RoundTrip(r)
+	rt.RoundTrip(r)
+	if !cb.Open("test.com") {
+		t.Errorf(err)
+	}
+	if _, e := rt.RoundTrip(r); e != ErrCircuitOpen || calls != 2 {
+		t.Errorf(err)
+	}
+
+	// Test other hosts are unaffected
+	other, _ := http.NewRequest("GET", "http://other.com/", nil)
+	if _, e := rt.RoundTrip(other); e == ErrCircuitOpen {
+		t.Errorf(err)
+	}
+
+	// Test trial request closes the circuit
+	fail = false
+	now = now.Add(2 * time.Minute)
+	if resp, e := rt.RoundTrip(r); e != nil || resp.StatusCode != 200 || cb.Open("test.com") {
+		t.Errorf(err)
+	}
+}
+
+func TestNew(t *testing.T) {
+	defer func() {
+		err := recover()
+		if err != nil {
+			t.Errorf(err.(error).Error())
+		}
+	}()
+
+	err := "Failed new."
+
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(r.Header.Get("X-Request-Id")))
+	}))
+	defer upstream.Close()
+
+	v := verto.New()
+	v.Logger = &verto.NilLogger{}
+	New(v)
+
+	metrics := make(chan *Metric, 1)
+	v.Events.Subscribe(RequestEvent, func(e verto.Event) {
+		metrics <- e.Data.(*Metric)
+	})
+
+	v.Get("/proxy", func(c *verto.Context) (interface{}, error) {
+		req, _ := http.NewRequest("GET", upstream.URL+"/id", nil)
+		resp, e := c.Do(req)
+		if e != nil {
+			return nil, e
+		}
+		defer resp.Body.Close()
+		b := make([]byte, 16)
+		n, _ := resp.Body.Read(b)
+		return string(b[:n]), nil
+	})
+	h := &verto.HttpHandler{v}
+
+	r, _ := http.NewRequest("GET", "http://test.com/proxy", nil)
+	r.Header.Set("X-Request-Id", "abc")
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	if w.Body.String() != "abc" {
+		t.Errorf(err)
+	}
+
+	select {
+	case m := <-metrics:
+		if m.Method != "GET" || m.Path != "/id" || m.Status != 200 {
+			t.Errorf(err)
+		}
+	case <-time.After(time.Second):
+		t.Errorf(err)
+	}
+}
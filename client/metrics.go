@@ -0,0 +1,46 @@
+package client
+
+import (
+	"github.com/boxtown/verto"
+	"net/http"
+	"time"
+)
+
+// RequestEvent is the topic Metrics are published
+// under on the Verto instance's event bus
+const RequestEvent = "verto.client.request"
+
+// Metric describes a completed outbound request
+type Metric struct {
+	Method   string
+	Host     string
+	Path     string
+	Status   int
+	Duration time.Duration
+	Err      error
+}
+
+// Metrics returns Middleware publishing a Metric for
+// every outbound request under RequestEvent on bus
+func Metrics(bus *verto.EventBus) Middleware {
+	return func(next http.RoundTripper) http.RoundTripper {
+		return RoundTripperFunc(func(r *http.Request) (*http.Response, error) {
+			start := time.Now()
+			resp, err := next.RoundTrip(r)
+			if bus != nil {
+				m := &Metric{
+					Method:   r.Method,
+					Host:     r.URL.Host,
+					Path:     r.URL.Path,
+					Duration: time.Since(start),
+					Err:      err,
+				}
+				if resp != nil {
+					m.Status = resp.StatusCode
+				}
+				bus.Publish(RequestEvent, m)
+			}
+			return resp, err
+		})
+	}
+}
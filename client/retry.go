@@ -0,0 +1,94 @@
+package client
+
+import (
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// DefaultBackoff returns exponential backoff starting at
+// 100 milliseconds for retry n (starting at 1), capped at 5 seconds
+func DefaultBackoff(n int) time.Duration {
+	d := 100 * time.Millisecond << uint(n-1)
+	if d > 5*time.Second || d <= 0 {
+		d = 5 * time.Second
+	}
+	return d
+}
+
+// Retry returns Middleware retrying requests up to max times on network
+// errors and 502, 503 and 504 responses. Only idempotent requests (GET,
+// HEAD, OPTIONS, PUT and DELETE) with replayable bodies are retried.
+// Retry-After headers of 503 responses are honored if they are shorter
+// than the backoff cap. If backoff is nil, DefaultBackoff is used
+func Retry(max int, backoff func(n int) time.Duration) Middleware {
+	if backoff == nil {
+		backoff = DefaultBackoff
+	}
+	return func(next http.RoundTripper) http.RoundTripper {
+		return RoundTripperFunc(func(r *http.Request) (*http.Response, error) {
+			if !retryable(r) {
+				return next.RoundTrip(r)
+			}
+			for n := 1; ; n++ {
+				resp, err := next.RoundTrip(r)
+				if n > max || !shouldRetry(resp, err) {
+					return resp, err
+				}
+
+				wait := backoff(n)
+				if resp != nil {
+					if s, e := strconv.Atoi(resp.Header.Get("Retry-After")); e == nil && s >= 0 {
+						if d := time.Duration(s) * time.Second; d <= 5*time.Second {
+							wait = d
+						}
+					}
+					// Drain the body so that the connection is reused
+					io.Copy(ioutil.Discard, io.LimitReader(resp.Body, 4096))
+					resp.Body.Close()
+				}
+
+				timer := time.NewTimer(wait)
+				select {
+				case <-r.Context().Done():
+					timer.Stop()
+					return nil, r.Context().Err()
+				case <-timer.C:
+				}
+
+				if r.Body != nil && r.GetBody != nil {
+					body, err := r.GetBody()
+					if err != nil {
+						return nil, err
+					}
+					r = r.Clone(r.Context())
+					r.Body = body
+				}
+			}
+		})
+	}
+}
+
+// retryable returns whether r can be safely resent
+func retryable(r *http.Request) bool {
+	switch r.Method {
+	case "GET", "HEAD", "OPTIONS", "PUT", "DELETE":
+	default:
+		return false
+	}
+	return r.Body == nil || r.Body == http.NoBody || r.GetBody != nil
+}
+
+// shouldRetry returns whether the result of a round trip warrants a retry
+func shouldRetry(resp *http.Response, err error) bool {
+	if err != nil {
+		return true
+	}
+	switch resp.StatusCode {
+	case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
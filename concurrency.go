@@ -0,0 +1,65 @@
+package verto
+
+import (
+	"github.com/boxtown/verto/mux"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// MaxConcurrent limits the number of requests to the Endpoint that are
+// handled at the same time to n. Requests arriving while n requests are
+// in flight wait up to timeout for a slot and receive a 503 response with
+// a Retry-After header if none frees up. If timeout is zero, overflowing
+// requests are rejected immediately with a 429 response. The limit applies
+// to the Endpoint only and is independent of any global limits.
+//
+// Example usage:
+//
+//	v.Get("/reports/{id}/export", export).MaxConcurrent(4, 5*time.Second)
+func (ep *Endpoint) MaxConcurrent(n int, timeout time.Duration) *Endpoint {
+	if n <= 0 {
+		panic("Endpoint.MaxConcurrent: n must be positive.")
+	}
+	slots := make(chan struct{}, n)
+
+	handler := func(w http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+		select {
+		case slots <- struct{}{}:
+		default:
+			if timeout <= 0 {
+				w.WriteHeader(http.StatusTooManyRequests)
+				w.Write([]byte(http.StatusText(http.StatusTooManyRequests) + "."))
+				return
+			}
+
+			timer := time.NewTimer(timeout)
+			select {
+			case slots <- struct{}{}:
+				timer.Stop()
+			case <-timer.C:
+				w.Header().Set("Retry-After", strconv.Itoa(retryAfter(timeout)))
+				w.WriteHeader(http.StatusServiceUnavailable)
+				w.Write([]byte(http.StatusText(http.StatusServiceUnavailable) + "."))
+				return
+			case <-r.Context().Done():
+				// Client went away while queued
+				timer.Stop()
+				return
+			}
+		}
+		defer func() { <-slots }()
+		next(w, r)
+	}
+	return ep.UsePluginHandler(mux.PluginFunc(handler))
+}
+
+// retryAfter returns the number of whole seconds, at
+// least one, in d
+func retryAfter(d time.Duration) int {
+	s := int((d + time.Second - 1) / time.Second)
+	if s < 1 {
+		s = 1
+	}
+	return s
+}
@@ -0,0 +1,86 @@
+package verto
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestEndpointMaxConcurrent(t *testing.T) {
+	defer func() {
+		err := recover()
+		if err != nil {
+			t.Errorf(err.(error).Error())
+		}
+	}()
+
+	err := "Failed endpoint max concurrent."
+
+	entered := make(chan struct{}, 2)
+	release := make(chan struct{})
+	handler := func(c *Context) (interface{}, error) {
+		entered <- struct{}{}
+		<-release
+		return "done", nil
+	}
+
+	v := New()
+	v.Logger = &NilLogger{}
+	v.Get("/reject", handler).MaxConcurrent(1, 0)
+	v.Get("/queue", handler).MaxConcurrent(1, 20*time.Millisecond)
+	h := &HttpHandler{v}
+
+	serve := func(path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest("GET", "http://test.com"+path, nil)
+		h.ServeHTTP(w, r)
+		return w
+	}
+
+	for _, test := range []struct {
+		path string
+		code int
+	}{
+		{"/reject", http.StatusTooManyRequests},
+		{"/queue", http.StatusServiceUnavailable},
+	} {
+		wg := &sync.WaitGroup{}
+		wg.Add(1)
+		var first *httptest.ResponseRecorder
+		go func() {
+			defer wg.Done()
+			first = serve(test.path)
+		}()
+		<-entered
+
+		w := serve(test.path)
+		if w.Code != test.code {
+			t.Errorf(err)
+		}
+		if test.code == http.StatusServiceUnavailable && w.Header().Get("Retry-After") != "1" {
+			t.Errorf(err)
+		}
+
+		release <- struct{}{}
+		wg.Wait()
+		if first.Code != http.StatusOK {
+			t.Errorf(err)
+		}
+	}
+
+	// Test queued request acquiring a freed slot
+	v.Get("/wait", handler).MaxConcurrent(1, 5*time.Second)
+	results := make(chan int, 2)
+	for i := 0; i < 2; i++ {
+		go func() { results <- serve("/wait").Code }()
+	}
+	<-entered
+	release <- struct{}{}
+	<-entered
+	release <- struct{}{}
+	if <-results != http.StatusOK || <-results != http.StatusOK {
+		t.Errorf(err)
+	}
+}
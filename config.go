@@ -0,0 +1,263 @@
+package verto
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ConfigChangedEvent is the topic []ConfigChange values are
+// published under when a watched config file changes
+const ConfigChangedEvent = "verto.config.changed"
+
+// Config holds the settings that are safe to change at runtime.
+// Config files are JSON documents with the following keys, all optional:
+//
+//	{
+//		"logLevel": "info",
+//		"maintenance": false,
+//		"corsOrigins": ["https://example.com"],
+//		"rateLimits": {"search": 100}
+//	}
+type Config struct {
+	// LogLevel is applied to Verto's Logger if it is a LevelLogger
+	LogLevel string `json:"logLevel,omitempty"`
+
+	// Maintenance enables or disables maintenance mode
+	Maintenance bool `json:"maintenance,omitempty"`
+
+	// CORSOrigins are the allowed CORS origins,
+	// see ConfigWatcher.AllowsOrigin
+	CORSOrigins []string `json:"corsOrigins,omitempty"`
+
+	// RateLimits are named rate limits,
+	// see ConfigWatcher.RateLimit
+	RateLimits map[string]int64 `json:"rateLimits,omitempty"`
+}
+
+// ConfigChange is a changed config setting
+type ConfigChange struct {
+	Key string      `json:"key"`
+	Old interface{} `json:"old"`
+	New interface{} `json:"new"`
+}
+
+func (c ConfigChange) String() string {
+	return fmt.Sprintf("%s: %v -> %v", c.Key, c.Old, c.New)
+}
+
+// ConfigWatcher watches a config file and applies changes to Verto
+type ConfigWatcher struct {
+	path     string
+	interval time.Duration
+	v        *Verto
+	config   Config
+	modTime  time.Time
+	stop     chan struct{}
+	mutex    sync.RWMutex
+}
+
+// WatchConfig loads the JSON config file at path, applies it and polls
+// the file for changes every interval (every 5 seconds if interval is not
+// positive). Changed settings are applied without restart, logged and
+// published under ConfigChangedEvent. Invalid files are logged and ignored,
+// keeping the previous settings. If Verto's Reload function is not set,
+// it is set to reload the file so that the admin API can trigger reloads.
+// Returns an error if the file cannot be loaded initially.
+//
+// Example usage:
+//
+//	cw, err := v.WatchConfig("/etc/app/config.json", 0)
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	defer cw.Stop()
+//	v.Use(cors.New().Configure(&cors.Options{AllowedOriginsFn: cw.AllowsOrigin}))
+func (v *Verto) WatchConfig(path string, interval time.Duration) (*ConfigWatcher, error) {
+	if interval <= 0 {
+		interval = 5 * time.Second
+	}
+	cw := &ConfigWatcher{
+		path:     path,
+		interval: interval,
+		v:        v,
+		stop:     make(chan struct{}),
+	}
+	if err := cw.Reload(); err != nil {
+		return nil, err
+	}
+	if v.Reload == nil {
+		v.Reload = cw.Reload
+	}
+	go cw.watch()
+	return cw, nil
+}
+
+// Config returns the current config
+func (cw *ConfigWatcher) Config() Config {
+	cw.mutex.RLock()
+	defer cw.mutex.RUnlock()
+
+	return cw.config
+}
+
+// AllowsOrigin returns whether origin is one of the configured CORS
+// origins or the origins contain the wildcard *. It can be used as the
+// AllowedOriginsFn of the cors plugin
+func (cw *ConfigWatcher) AllowsOrigin(origin string) bool {
+	cw.mutex.RLock()
+	defer cw.mutex.RUnlock()
+
+	for _, o := range cw.config.CORSOrigins {
+		if o == "*" || strings.EqualFold(o, origin) {
+			return true
+		}
+	}
+	return false
+}
+
+// RateLimit returns the configured rate limit name and
+// whether it is configured
+func (cw *ConfigWatcher) RateLimit(name string) (int64, bool) {
+	cw.mutex.RLock()
+	defer cw.mutex.RUnlock()
+
+	limit, ok := cw.config.RateLimits[name]
+	return limit, ok
+}
+
+// Reload loads the config file and applies the changed settings.
+// The previous settings are kept if the file is invalid
+func (cw *ConfigWatcher) Reload() error {
+	info, err := os.Stat(cw.path)
+	if err != nil {
+		return err
+	}
+	b, err := ioutil.ReadFile(cw.path)
+	if err != nil {
+		return err
+	}
+	config := Config{}
+	if err := json.Unmarshal(b, &config); err != nil {
+		return fmt.Errorf("config: %s: %s", cw.path, err.Error())
+	}
+	var level Level
+	if config.LogLevel != "" {
+		if level, err = ParseLevel(config.LogLevel); err != nil {
+			return fmt.Errorf("config: %s: %s", cw.path, err.Error())
+		}
+	}
+
+	cw.mutex.Lock()
+	old := cw.config
+	cw.config = config
+	cw.modTime = info.ModTime()
+	cw.mutex.Unlock()
+
+	changes := diffConfig(old, config)
+	if len(changes) == 0 {
+		return nil
+	}
+
+	v := cw.v
+	for _, change := range changes {
+		switch change.Key {
+		case "logLevel":
+			if logger, ok := v.Logger.(LevelLogger); ok && config.LogLevel != "" {
+				logger.SetLevel(level)
+			}
+		case "maintenance":
+			v.SetMaintenance(config.Maintenance)
+		}
+		if v.Logger != nil {
+			v.Logger.Infof("config: %s", change.String())
+		}
+	}
+	v.audit(AuditConfigChanged, cw.path, fmt.Sprintf("%d settings changed", len(changes)))
+	if v.Events != nil {
+		v.Events.Publish(ConfigChangedEvent, changes)
+	}
+	return nil
+}
+
+// Stop stops watching the config file
+func (cw *ConfigWatcher) Stop() {
+	cw.mutex.Lock()
+	defer cw.mutex.Unlock()
+
+	select {
+	case <-cw.stop:
+	default:
+		close(cw.stop)
+	}
+}
+
+// watch polls the config file for modifications
+func (cw *ConfigWatcher) watch() {
+	ticker := time.NewTicker(cw.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-cw.stop:
+			return
+		case <-ticker.C:
+			info, err := os.Stat(cw.path)
+			cw.mutex.RLock()
+			changed := err == nil && !info.ModTime().Equal(cw.modTime)
+			cw.mutex.RUnlock()
+			if !changed {
+				continue
+			}
+			if err := cw.Reload(); err != nil && cw.v.Logger != nil {
+				cw.v.Logger.Errorf("config: could not reload: %s", err.Error())
+			}
+		}
+	}
+}
+
+// diffConfig returns the settings that changed from old to new
+func diffConfig(old, new Config) []ConfigChange {
+	changes := make([]ConfigChange, 0)
+	if old.LogLevel != new.LogLevel {
+		changes = append(changes, ConfigChange{"logLevel", old.LogLevel, new.LogLevel})
+	}
+	if old.Maintenance != new.Maintenance {
+		changes = append(changes, ConfigChange{"maintenance", old.Maintenance, new.Maintenance})
+	}
+	if !reflect.DeepEqual(old.CORSOrigins, new.CORSOrigins) {
+		changes = append(changes, ConfigChange{"corsOrigins", old.CORSOrigins, new.CORSOrigins})
+	}
+
+	names := make([]string, 0)
+	for name := range old.RateLimits {
+		names = append(names, name)
+	}
+	for name := range new.RateLimits {
+		if _, ok := old.RateLimits[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		o, oldOk := old.RateLimits[name]
+		n, newOk := new.RateLimits[name]
+		if o != n || oldOk != newOk {
+			var ov, nv interface{}
+			if oldOk {
+				ov = o
+			}
+			if newOk {
+				nv = n
+			}
+			changes = append(changes, ConfigChange{"rateLimits." + name, ov, nv})
+		}
+	}
+	return changes
+}
@@ -0,0 +1,75 @@
+package verto
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestWatchConfig(t *testing.T) {
+	defer func() {
+		err := recover()
+		if err != nil {
+			t.Errorf(err.(error).Error())
+		}
+	}()
+
+	err := "Failed watch config."
+
+	dir, _ := ioutil.TempDir("", "config")
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "config.json")
+	ioutil.WriteFile(path, []byte(`{"logLevel": "info", "corsOrigins": ["https://a.com"], "rateLimits": {"search": 10}}`), 0644)
+
+	v := New()
+	logger := NewLogger()
+	v.Logger = logger
+
+	changes := make(chan []ConfigChange, 4)
+	v.Events.Subscribe(ConfigChangedEvent, func(e Event) {
+		changes <- e.Data.([]ConfigChange)
+	})
+
+	cw, e := v.WatchConfig(path, 10*time.Millisecond)
+	if e != nil {
+		t.Fatalf(e.Error())
+	}
+	defer cw.Stop()
+	<-changes
+
+	if logger.Level() != LevelInfo || !cw.AllowsOrigin("https://a.com") || cw.AllowsOrigin("https://b.com") {
+		t.Errorf(err)
+	}
+	if limit, ok := cw.RateLimit("search"); !ok || limit != 10 {
+		t.Errorf(err)
+	}
+
+	// Test changes are picked up and diffed
+	later := time.Now().Add(time.Second)
+	ioutil.WriteFile(path, []byte(`{"logLevel": "error", "maintenance": true, "corsOrigins": ["https://a.com"], "rateLimits": {"search": 20, "export": 1}}`), 0644)
+	os.Chtimes(path, later, later)
+	select {
+	case c := <-changes:
+		if len(c) != 4 || c[0].String() != "logLevel: info -> error" || c[1].Key != "maintenance" ||
+			c[2].String() != "rateLimits.export: <nil> -> 1" || c[3].String() != "rateLimits.search: 10 -> 20" {
+			t.Errorf(err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatalf(err)
+	}
+	if logger.Level() != LevelError || !v.Maintenance() {
+		t.Errorf(err)
+	}
+
+	// Test invalid files keep the previous settings
+	ioutil.WriteFile(path, []byte(`{"logLevel": "loud"}`), 0644)
+	if v.Reload() == nil || cw.Config().LogLevel != "error" {
+		t.Errorf(err)
+	}
+
+	if _, e := v.WatchConfig(filepath.Join(dir, "missing.json"), 0); e == nil {
+		t.Errorf(err)
+	}
+}
@@ -2,6 +2,7 @@ package verto
 
 import (
 	"errors"
+	"github.com/boxtown/verto/mux"
 	"net/http"
 	"net/url"
 	"strconv"
@@ -36,7 +37,9 @@ type Context struct {
 
 	params   url.Values
 	parseErr error
+	diag     *Diagnostic
 	mut      *sync.Mutex
+	v        *Verto
 }
 
 // NewContext initializes a new Context with the passed in response, request,
@@ -72,6 +75,25 @@ func (c *Context) Get(key string) string {
 	return c.params.Get(key)
 }
 
+// Param returns the value of the path parameter name matched
+// for the request. Unlike Get, Param never returns query or body
+// values and works regardless of Verto's FormParams setting.
+func (c *Context) Param(name string) string {
+	if c.Request == nil {
+		return ""
+	}
+	return mux.Param(c.Request, name)
+}
+
+// Params returns the path parameters matched for the request
+// keyed by name
+func (c *Context) Params() map[string]string {
+	if c.Request == nil {
+		return map[string]string{}
+	}
+	return mux.Params(c.Request)
+}
+
 // GetMulti returns the a slice containing all relevant parameters
 // tied to key. If there was an error retrieving the parameters,
 // the error is stored and retrievable by the ParseError call
@@ -173,6 +195,47 @@ func (c *Context) SetInt64(key string, value int64) {
 	c.Set(key, v)
 }
 
+// Route returns the route matched for the request or nil if
+// the request was not dispatched to a route (e.g. in plugins
+// run for unmatched paths)
+func (c *Context) Route() mux.Route {
+	if c.Request == nil {
+		return nil
+	}
+	return mux.CurrentRoute(c.Request)
+}
+
+// RouteName returns the name of the route matched for the request
+// or an empty string if the route is unnamed or none was matched.
+//
+// Example usage:
+//
+//	v.Get("/admin/users", listUsers).Name("admin.users")
+//	v.Use(verto.PluginFunc(func(c *verto.Context, next http.HandlerFunc) {
+//		if strings.HasPrefix(c.RouteName(), "admin.") && !isAdmin(c) {
+//			c.Response.WriteHeader(403)
+//			return
+//		}
+//		next(c.Response, c.Request)
+//	}))
+func (c *Context) RouteName() string {
+	route := c.Route()
+	if route == nil {
+		return ""
+	}
+	return route.Name()
+}
+
+// RouteMeta returns the metadata value associated with key on the
+// route matched for the request and whether such a value exists
+func (c *Context) RouteMeta(key string) (interface{}, bool) {
+	route := c.Route()
+	if route == nil {
+		return nil, false
+	}
+	return route.Meta(key)
+}
+
 // ParseError returns the error encountered while parsing
 // the HTTP request for parameter values or nil if no
 // error was encountered
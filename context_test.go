@@ -1,7 +1,11 @@
 package verto
 
 import (
+	"fmt"
+	"github.com/boxtown/verto/mux"
 	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
 )
 
@@ -34,6 +38,50 @@ func TestContextGet(t *testing.T) {
 	}
 }
 
+func TestContextParam(t *testing.T) {
+	defer func() {
+		err := recover()
+		if err != nil {
+			t.Errorf(err.(error).Error())
+		}
+	}()
+
+	err := "Failed param."
+
+	// Test improper initialization
+	c := NewContext(nil, nil, nil, nil)
+	if c.Param("id") != "" || len(c.Params()) != 0 {
+		t.Errorf(err)
+	}
+
+	// Test params are separate from form values
+	v := New()
+	v.Logger = &NilLogger{}
+	v.SetFormParams(false)
+	v.Get("/users/{id}", func(c *Context) (interface{}, error) {
+		return c.Param("id") + "," + c.Get("id"), nil
+	})
+	r, _ := http.NewRequest("GET", "http://test.com/users/1?id=q", nil)
+	w := httptest.NewRecorder()
+	(&HttpHandler{v}).ServeHTTP(w, r)
+	if w.Body.String() != "1,q" {
+		t.Errorf(err)
+	}
+
+	// Test param handlers
+	v.SetFormParams(true)
+	v.AddParamHandler("GET", "/posts/{id}", mux.ParamHandlerFunc(
+		func(w http.ResponseWriter, r *http.Request, ps mux.PathParams) {
+			w.Write([]byte(ps[0].Value + "," + r.FormValue("id")))
+		}))
+	r, _ = http.NewRequest("GET", "http://test.com/posts/2?id=q", nil)
+	w = httptest.NewRecorder()
+	(&HttpHandler{v}).ServeHTTP(w, r)
+	if w.Body.String() != "2,q" {
+		t.Errorf(err)
+	}
+}
+
 func TestContextGetMulti(t *testing.T) {
 	defer func() {
 		err := recover()
@@ -240,3 +288,48 @@ func TestContextSetInt64(t *testing.T) {
 		t.Errorf(err)
 	}
 }
+
+func TestContextRoute(t *testing.T) {
+	defer func() {
+		err := recover()
+		if err != nil {
+			t.Errorf(err.(error).Error())
+		}
+	}()
+
+	err := "Failed context route."
+
+	v := New()
+	v.Logger = &NilLogger{}
+	labels := make([]string, 0)
+	v.Use(PluginFunc(func(c *Context, next http.HandlerFunc) {
+		role, _ := c.RouteMeta("role")
+		labels = append(labels, fmt.Sprintf("%s:%v", c.RouteName(), role))
+		next(c.Response, c.Request)
+	}))
+	rf := func(c *Context) (interface{}, error) {
+		return c.Route().Path(), nil
+	}
+	v.Get("/users/{id}", rf).Name("users.show").Meta("role", "admin")
+	v.Get("/health", rf)
+	h := &HttpHandler{v}
+
+	// Test plugins and resources read the matched route's name and metadata
+	for _, path := range []string{"/users/1", "/health"} {
+		r, _ := http.NewRequest("GET", "http://test.com"+path, nil)
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		if w.Code != 200 || !strings.HasPrefix(w.Body.String(), "/") {
+			t.Errorf(err)
+		}
+	}
+	if len(labels) != 2 || labels[0] != "users.show:admin" || labels[1] != ":<nil>" {
+		t.Errorf(err)
+	}
+
+	// Test contexts without a matched route
+	c := NewContext(nil, nil, nil, nil)
+	if c.Route() != nil || c.RouteName() != "" {
+		t.Errorf(err)
+	}
+}
@@ -0,0 +1,77 @@
+package verto
+
+import (
+	"net/http"
+	"strings"
+)
+
+// ContinuePolicy decides whether requests to a route may send their
+// body. Requests carrying an 'Expect: 100-continue' header are rejected
+// before the client is told to continue, so clients do not upload large
+// bodies that would be rejected anyway
+type ContinuePolicy struct {
+	// MaxLength is the maximum Content-Length of request
+	// bodies. Larger requests receive a 413 response. Zero
+	// means no limit
+	MaxLength int64
+
+	// RequireLength rejects requests with a body but without
+	// a Content-Length header with a 411 response
+	RequireLength bool
+
+	// Refuse rejects requests expecting 100-continue with a 417
+	// response, forcing clients to send bodies without waiting
+	Refuse bool
+
+	// Check is an optional check run before the body is read
+	// (e.g. authentication). Errors are passed to the ErrorHandler.
+	// Checks must not read the request body
+	Check func(c *Context) error
+}
+
+// Continue sets the policy deciding whether requests to the route
+// represented by the Endpoint may send their body. Register Continue
+// before plugins that read the request body.
+//
+// Example usage:
+//
+//	v.Put("/files/{name}", upload).Continue(verto.ContinuePolicy{
+//		MaxLength:     100 << 20,
+//		RequireLength: true,
+//		Check:         authorize,
+//	})
+func (ep *Endpoint) Continue(policy ContinuePolicy) *Endpoint {
+	v := ep.v
+	return ep.Use(PluginFunc(func(c *Context, next http.HandlerFunc) {
+		if err := policy.check(c); err != nil {
+			v.errorHandler(c).Handle(err, c)
+			return
+		}
+		next(c.Response, c.Request)
+	}))
+}
+
+// ExpectsContinue returns whether the client of r waits for
+// a 100 Continue response before sending the request body
+func ExpectsContinue(r *http.Request) bool {
+	return strings.EqualFold(r.Header.Get("Expect"), "100-continue")
+}
+
+// check returns an HTTPError if the request of c violates the
+// policy or the error of the policy's Check
+func (policy ContinuePolicy) check(c *Context) error {
+	r := c.Request
+	if policy.Refuse && ExpectsContinue(r) {
+		return &HTTPError{Status: http.StatusExpectationFailed}
+	}
+	if policy.RequireLength && r.ContentLength < 0 {
+		return &HTTPError{Status: http.StatusLengthRequired}
+	}
+	if policy.MaxLength > 0 && r.ContentLength > policy.MaxLength {
+		return &HTTPError{Status: http.StatusRequestEntityTooLarge}
+	}
+	if policy.Check != nil {
+		return policy.Check(c)
+	}
+	return nil
+}
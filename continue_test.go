@@ -0,0 +1,85 @@
+package verto
+
+import (
+	"bufio"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestContinue(t *testing.T) {
+	defer func() {
+		err := recover()
+		if err != nil {
+			t.Errorf(err.(error).Error())
+		}
+	}()
+
+	err := "Failed continue."
+
+	v := New()
+	v.Logger = &NilLogger{}
+	v.Put("/files", func(c *Context) (interface{}, error) {
+		b, _ := ioutil.ReadAll(c.Request.Body)
+		return string(b), nil
+	}).Continue(ContinuePolicy{
+		MaxLength:     10,
+		RequireLength: true,
+		Check: func(c *Context) error {
+			if c.Request.Header.Get("Authorization") == "" {
+				return &HTTPError{Status: http.StatusUnauthorized}
+			}
+			return nil
+		},
+	})
+	v.Put("/strict", func(c *Context) (interface{}, error) {
+		return "ok", nil
+	}).Continue(ContinuePolicy{Refuse: true})
+	h := &HttpHandler{v}
+
+	serve := func(path, body string, header http.Header) *httptest.ResponseRecorder {
+		r, _ := http.NewRequest("PUT", "http://test.com"+path, strings.NewReader(body))
+		for k, values := range header {
+			r.Header[k] = values
+		}
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		return w
+	}
+
+	auth := http.Header{"Authorization": {"x"}}
+	if w := serve("/files", "data", auth); w.Code != 200 || w.Body.String() != "data" {
+		t.Errorf(err)
+	}
+	if serve("/files", "too much data", auth).Code != http.StatusRequestEntityTooLarge {
+		t.Errorf(err)
+	}
+	if serve("/files", "data", nil).Code != http.StatusUnauthorized {
+		t.Errorf(err)
+	}
+	if serve("/strict", "", http.Header{"Expect": {"100-continue"}}).Code != http.StatusExpectationFailed {
+		t.Errorf(err)
+	}
+	if serve("/strict", "", nil).Code != 200 {
+		t.Errorf(err)
+	}
+
+	// Test rejected clients are not told to continue
+	server := httptest.NewServer(h)
+	defer server.Close()
+	conn, e := net.Dial("tcp", strings.TrimPrefix(server.URL, "http://"))
+	if e != nil {
+		t.Fatalf(e.Error())
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(5 * time.Second))
+	conn.Write([]byte("PUT /files HTTP/1.1\r\nHost: test.com\r\nContent-Length: 1000\r\nExpect: 100-continue\r\nAuthorization: x\r\n\r\n"))
+	res, e := http.ReadResponse(bufio.NewReader(conn), nil)
+	if e != nil || res.StatusCode != http.StatusRequestEntityTooLarge {
+		t.Errorf(err)
+	}
+}
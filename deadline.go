@@ -0,0 +1,152 @@
+package verto
+
+import (
+	"context"
+	"errors"
+	"github.com/boxtown/verto/mux"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Headers carrying request deadlines. DeadlineHeader holds an absolute
+// RFC 3339 time, TimeoutHeader a relative gRPC-style timeout made of up
+// to 8 digits followed by a unit (H, M, S, m, u or n, e.g. 250m)
+const (
+	DeadlineHeader = "X-Request-Deadline"
+	TimeoutHeader  = "Grpc-Timeout"
+)
+
+// ErrDeadlineExceeded is passed to the ErrorHandler if the deadline of
+// a request passed before its handler completed. DefaultErrorFunc responds
+// with 504 Gateway Timeout
+var ErrDeadlineExceeded = errors.New("request deadline exceeded")
+
+// PropagateDeadlines registers a global plugin that sets the deadline of
+// each request's context from the DeadlineHeader or TimeoutHeader sent by
+// the client, capped at max if max is positive. Requests without a deadline
+// header get a deadline of max. Requests whose deadline already passed are
+// rejected with a 504 response. Handlers that return after the deadline
+// passed have ErrDeadlineExceeded passed to the ErrorHandler, and outbound
+// requests made through Context.Do carry the remaining deadline.
+//
+// Example usage:
+//
+//	v.PropagateDeadlines(30 * time.Second)
+func (v *Verto) PropagateDeadlines(max time.Duration) *Verto {
+	handler := func(w http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+		now := time.Now()
+		deadline, ok := ParseDeadline(r.Header, now)
+		if max > 0 && (!ok || deadline.After(now.Add(max))) {
+			deadline, ok = now.Add(max), true
+		}
+		if !ok {
+			next(w, r)
+			return
+		}
+		if !deadline.After(now) {
+			w.WriteHeader(http.StatusGatewayTimeout)
+			w.Write([]byte(http.StatusText(http.StatusGatewayTimeout) + "."))
+			return
+		}
+
+		ctx, cancel := context.WithDeadline(r.Context(), deadline)
+		defer cancel()
+		next(w, r.WithContext(ctx))
+	}
+	return v.UsePluginHandler(mux.PluginFunc(handler))
+}
+
+// ParseDeadline returns the deadline sent in h through the DeadlineHeader
+// or, if absent, the TimeoutHeader relative to now. Returns false if h
+// carries no valid deadline
+func ParseDeadline(h http.Header, now time.Time) (time.Time, bool) {
+	if s := h.Get(DeadlineHeader); s != "" {
+		if t, err := time.Parse(time.RFC3339Nano, s); err == nil {
+			return t, true
+		}
+	}
+	if d, ok := parseTimeout(h.Get(TimeoutHeader)); ok {
+		return now.Add(d), true
+	}
+	return time.Time{}, false
+}
+
+// SetDeadline sets the DeadlineHeader and TimeoutHeader in h
+// for deadline relative to now
+func SetDeadline(h http.Header, deadline, now time.Time) {
+	remaining := deadline.Sub(now)
+	if remaining < 0 {
+		remaining = 0
+	}
+	h.Set(DeadlineHeader, deadline.UTC().Format(time.RFC3339Nano))
+	h.Set(TimeoutHeader, strconv.FormatInt(int64(remaining/time.Millisecond), 10)+"m")
+}
+
+// Do sends the outbound request req with Verto's Client, or
+// http.DefaultClient if none is set, within the context of the incoming
+// request so that it is canceled along with it. If the incoming request
+// has a deadline, the remaining deadline is propagated with req. The
+// incoming request is available to outbound middleware through Inbound
+func (c *Context) Do(req *http.Request) (*http.Response, error) {
+	client := http.DefaultClient
+	if c.v != nil && c.v.Client != nil {
+		client = c.v.Client
+	}
+	if c.Request != nil {
+		ctx := c.Request.Context()
+		req = req.WithContext(context.WithValue(ctx, inboundKey{}, c.Request))
+		if deadline, ok := ctx.Deadline(); ok {
+			req.Header = cloneHeader(req.Header)
+			SetDeadline(req.Header, deadline, time.Now())
+		}
+	}
+	return client.Do(req)
+}
+
+// inboundKey is the context key of the incoming request
+// on outbound requests sent through Context.Do
+type inboundKey struct{}
+
+// Inbound returns the incoming request on whose behalf the outbound
+// request with context ctx was sent through Context.Do or nil if the
+// request was not sent through Context.Do. Outbound middleware can use
+// it to propagate headers such as request or trace ids
+func Inbound(ctx context.Context) *http.Request {
+	r, _ := ctx.Value(inboundKey{}).(*http.Request)
+	return r
+}
+
+// DeadlineExceeded returns whether the deadline of the request passed
+func (c *Context) DeadlineExceeded() bool {
+	return c.Request != nil && c.Request.Context().Err() == context.DeadlineExceeded
+}
+
+// parseTimeout parses a gRPC-style timeout
+func parseTimeout(s string) (time.Duration, bool) {
+	if len(s) < 2 || len(s) > 9 {
+		return 0, false
+	}
+	n, err := strconv.ParseInt(s[:len(s)-1], 10, 64)
+	if err != nil || n < 0 {
+		return 0, false
+	}
+	var unit time.Duration
+	switch s[len(s)-1] {
+	case 'H':
+		unit = time.Hour
+	case 'M':
+		unit = time.Minute
+	case 'S':
+		unit = time.Second
+	case 'm':
+		unit = time.Millisecond
+	case 'u':
+		unit = time.Microsecond
+	case 'n':
+		unit = time.Nanosecond
+	default:
+		return 0, false
+	}
+	return time.Duration(n) * unit, true
+}
@@ -0,0 +1,92 @@
+package verto
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestPropagateDeadlines(t *testing.T) {
+	defer func() {
+		err := recover()
+		if err != nil {
+			t.Errorf(err.(error).Error())
+		}
+	}()
+
+	err := "Failed propagate deadlines."
+
+	upstream := make(chan http.Header, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		upstream <- r.Header
+	}))
+	defer server.Close()
+
+	v := New()
+	v.Logger = &NilLogger{}
+	v.PropagateDeadlines(time.Minute)
+	v.Get("/remaining", func(c *Context) (interface{}, error) {
+		deadline, _ := c.Request.Context().Deadline()
+		req, _ := http.NewRequest("GET", server.URL, nil)
+		if resp, err := c.Do(req); err == nil {
+			resp.Body.Close()
+		}
+		return time.Until(deadline).Round(time.Second).String(), nil
+	})
+	v.Get("/slow", func(c *Context) (interface{}, error) {
+		<-c.Request.Context().Done()
+		return "late", nil
+	})
+	h := &HttpHandler{v}
+
+	serve := func(path string, header http.Header) *httptest.ResponseRecorder {
+		r, _ := http.NewRequest("GET", "http://test.com"+path, nil)
+		for k, vs := range header {
+			r.Header[k] = vs
+		}
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		return w
+	}
+
+	// Test max applies without headers and caps longer deadlines
+	if serve("/remaining", nil).Body.String() != "1m0s" {
+		t.Errorf(err)
+	}
+	<-upstream
+	if serve("/remaining", http.Header{TimeoutHeader: {"2H"}}).Body.String() != "1m0s" {
+		t.Errorf(err)
+	}
+	<-upstream
+
+	// Test inbound deadlines are honored and propagated
+	deadline := time.Now().Add(10 * time.Second)
+	w := serve("/remaining", http.Header{DeadlineHeader: {deadline.Format(time.RFC3339Nano)}})
+	if w.Body.String() != "10s" {
+		t.Errorf(err)
+	}
+	out := <-upstream
+	if d, ok := ParseDeadline(out, time.Now()); !ok || d.Sub(deadline) > time.Millisecond || d.Sub(deadline) < -time.Millisecond {
+		t.Errorf(err)
+	}
+	if out.Get(TimeoutHeader) == "" {
+		t.Errorf(err)
+	}
+
+	// Test exceeded deadlines
+	if serve("/slow", http.Header{TimeoutHeader: {"20m"}}).Code != http.StatusGatewayTimeout {
+		t.Errorf(err)
+	}
+	if serve("/slow", http.Header{TimeoutHeader: {"0n"}}).Code != http.StatusGatewayTimeout {
+		t.Errorf(err)
+	}
+
+	// Test timeout parsing
+	if _, ok := parseTimeout("123456789m"); ok {
+		t.Errorf(err)
+	}
+	if d, ok := parseTimeout("5S"); !ok || d != 5*time.Second {
+		t.Errorf(err)
+	}
+}
@@ -0,0 +1,50 @@
+package verto
+
+import (
+	"github.com/boxtown/verto/mux"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Deprecate marks every route under the Group as deprecated. Responses
+// carry a Deprecation header, a Sunset header with the sunset date if
+// sunset is non-zero and a Link header pointing to link (e.g. a migration
+// guide) if link is non-empty. If Verto's ClientKey function is set, the
+// first use of the deprecated group by each client is logged as a warning.
+//
+// Example usage:
+//
+//	sunset := time.Date(2017, time.June, 1, 0, 0, 0, 0, time.UTC)
+//	v.Group("GET", "/v1").Deprecate(sunset, "https://example.com/docs/v2-migration")
+func (g *Group) Deprecate(sunset time.Time, link string) *Group {
+	description := g.describe()
+	seen := make(map[string]bool)
+	mutex := &sync.Mutex{}
+
+	handler := func(w http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+		h := w.Header()
+		h.Set("Deprecation", "true")
+		if !sunset.IsZero() {
+			h.Set("Sunset", sunset.UTC().Format(http.TimeFormat))
+		}
+		if link != "" {
+			h.Add("Link", "<"+link+`>; rel="deprecation"`)
+		}
+
+		if g.v.ClientKey != nil && g.v.Logger != nil {
+			key := g.v.ClientKey(r)
+			mutex.Lock()
+			first := !seen[key]
+			seen[key] = true
+			mutex.Unlock()
+
+			if first {
+				g.v.Logger.Warnf("deprecated API %s used by client %s (%s %s)",
+					description, key, r.Method, r.URL.Path)
+			}
+		}
+		next(w, r)
+	}
+	return g.UsePluginHandler(mux.PluginFunc(handler))
+}
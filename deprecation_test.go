@@ -0,0 +1,67 @@
+package verto
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestGroupDeprecate(t *testing.T) {
+	defer func() {
+		err := recover()
+		if err != nil {
+			t.Errorf(err.(error).Error())
+		}
+	}()
+
+	err := "Failed group deprecate."
+
+	warnings := 0
+	logger := &testWarnLogger{warn: func() { warnings++ }}
+
+	v := New()
+	v.Logger = logger
+	v.ClientKey = func(r *http.Request) string { return r.Header.Get("X-Api-Key") }
+	v.Get("/v1/users", func(c *Context) (interface{}, error) { return "users", nil })
+	v.Get("/v2/users", func(c *Context) (interface{}, error) { return "users", nil })
+
+	sunset := time.Date(2017, time.June, 1, 0, 0, 0, 0, time.UTC)
+	v.Group("GET", "/v1").Deprecate(sunset, "https://test.com/migrate")
+	h := &HttpHandler{v}
+
+	for i := 0; i < 2; i++ {
+		r, _ := http.NewRequest("GET", "http://test.com/v1/users", nil)
+		r.Header.Set("X-Api-Key", "a")
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		if w.Header().Get("Deprecation") != "true" {
+			t.Errorf(err)
+		}
+		if w.Header().Get("Sunset") != "Thu, 01 Jun 2017 00:00:00 GMT" {
+			t.Errorf(err)
+		}
+		if w.Header().Get("Link") != `<https://test.com/migrate>; rel="deprecation"` {
+			t.Errorf(err)
+		}
+	}
+	if warnings != 1 {
+		t.Errorf(err)
+	}
+
+	r, _ := http.NewRequest("GET", "http://test.com/v2/users", nil)
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	if w.Header().Get("Deprecation") != "" {
+		t.Errorf(err)
+	}
+}
+
+type testWarnLogger struct {
+	NilLogger
+	warn func()
+}
+
+func (l *testWarnLogger) Warnf(format string, v ...interface{}) {
+	l.warn()
+}
@@ -0,0 +1,193 @@
+package verto
+
+import (
+	"fmt"
+	"github.com/boxtown/verto/mux"
+	"net/http"
+	"net/url"
+	"runtime/debug"
+	"sort"
+	"strings"
+	"time"
+)
+
+// DiagnosticEvent is the topic Diagnostics are published
+// under on the Verto instance's EventBus
+const DiagnosticEvent = "verto.diagnostic"
+
+// Redacted replaces the values of sensitive
+// parameters and headers in Diagnostics
+const Redacted = "[REDACTED]"
+
+// RedactedNames lists substrings of parameter and header names whose
+// values are redacted in Diagnostics. Matching is case-insensitive
+var RedactedNames = []string{
+	"authorization",
+	"cookie",
+	"password",
+	"passwd",
+	"secret",
+	"token",
+	"api-key",
+	"api_key",
+	"apikey",
+	"session",
+	"csrf",
+}
+
+// Diagnostic is a snapshot of the state of a request captured
+// when the error pipeline or panic recovery runs. Sensitive
+// parameter and header values are redacted
+type Diagnostic struct {
+	// Time is the time the snapshot was captured
+	Time time.Time `json:"time"`
+
+	// Method and URL are the method and URL of the request
+	Method string `json:"method"`
+	URL    string `json:"url"`
+
+	// Route is the path pattern of the matched route and
+	// RouteName its name if any
+	Route     string `json:"route,omitempty"`
+	RouteName string `json:"routeName,omitempty"`
+
+	// Params are the request parameters
+	Params url.Values `json:"params,omitempty"`
+
+	// Header is the request header
+	Header http.Header `json:"header,omitempty"`
+
+	// Injections lists the injection keys retrieved
+	// while handling the request
+	Injections []string `json:"injections,omitempty"`
+
+	// Fields are the promoted LogFields of the request
+	Fields map[string]interface{} `json:"fields,omitempty"`
+
+	// Error is the handled error. Nil for panics
+	Error error `json:"-"`
+
+	// Panic is the recovered panic value. Nil for errors
+	Panic interface{} `json:"-"`
+
+	// Message describes the error or panic
+	Message string `json:"message"`
+
+	// Stack is the stack of the goroutine at the time of capture
+	Stack string `json:"stack"`
+}
+
+// Capture captures a Diagnostic for err or, if err is nil, for the
+// recovered panic value and publishes it under DiagnosticEvent so that
+// error reporters can subscribe to it. Only the first Diagnostic of a
+// Context is captured, subsequent calls return it
+func (c *Context) Capture(err error, recovered interface{}) *Diagnostic {
+	c.mut.Lock()
+	if c.diag != nil {
+		c.mut.Unlock()
+		return c.diag
+	}
+	d := &Diagnostic{
+		Time:  time.Now().UTC(),
+		Error: err,
+		Panic: recovered,
+		Stack: string(debug.Stack()),
+	}
+	if err != nil {
+		d.Message = err.Error()
+	} else {
+		d.Message = fmt.Sprint(recovered)
+	}
+	if r := c.Request; r != nil {
+		d.Method = r.Method
+		d.URL = r.URL.String()
+		d.Header = redactHeader(r.Header)
+
+		// Don't consume the body if parameters
+		// have not been parsed yet
+		params := r.Form
+		if params == nil {
+			params = r.URL.Query()
+		}
+		d.Params = redactParams(params)
+
+		if route := mux.CurrentRoute(r); route != nil {
+			d.Route = route.Path()
+			d.RouteName = route.Name()
+		}
+	}
+	c.diag = d
+	c.mut.Unlock()
+
+	if fields := c.LogFields(); len(fields) > 0 {
+		d.Fields = fields
+	}
+	if c.Injections != nil {
+		if clone, ok := c.Injections().(*IClone); ok && clone != nil {
+			d.Injections = clone.Touched()
+		}
+	}
+	if c.v != nil && c.v.Events != nil {
+		c.v.Events.Publish(DiagnosticEvent, d)
+	}
+	return d
+}
+
+// Diagnostic returns the Diagnostic captured for
+// the request or nil if none was captured
+func (c *Context) Diagnostic() *Diagnostic {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+
+	return c.diag
+}
+
+// redacted returns whether values for name should be redacted
+func redacted(name string) bool {
+	name = strings.ToLower(name)
+	for _, s := range RedactedNames {
+		if strings.Contains(name, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// redactParams returns a redacted copy of params
+func redactParams(params url.Values) url.Values {
+	copied := make(url.Values, len(params))
+	for k, vs := range params {
+		copied[k] = redactValues(k, vs)
+	}
+	return copied
+}
+
+// redactHeader returns a redacted copy of h
+func redactHeader(h http.Header) http.Header {
+	copied := make(http.Header, len(h))
+	for k, vs := range h {
+		copied[k] = redactValues(k, vs)
+	}
+	return copied
+}
+
+func redactValues(name string, values []string) []string {
+	copied := make([]string, len(values))
+	for i, v := range values {
+		if redacted(name) {
+			v = Redacted
+		}
+		copied[i] = v
+	}
+	return copied
+}
+
+// sortedKeys returns the keys of m in sorted order
+func sortedKeys(m map[string]bool) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,76 @@
+package verto
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestDiagnostics(t *testing.T) {
+	defer func() {
+		err := recover()
+		if err != nil {
+			t.Errorf(err.(error).Error())
+		}
+	}()
+
+	err := "Failed diagnostics."
+
+	v := New()
+	v.Logger = &NilLogger{}
+	v.Injections.Set("db", "db")
+	v.Injections.Set("unused", "unused")
+
+	var published *Diagnostic
+	v.Events.Subscribe(DiagnosticEvent, func(e Event) {
+		published = e.Data.(*Diagnostic)
+	})
+	var handled *Diagnostic
+	v.ErrorHandler = ErrorFunc(func(e error, c *Context) {
+		handled = c.Diagnostic()
+		DefaultErrorFunc(e, c)
+	})
+	v.Get("/users/{id}", func(c *Context) (interface{}, error) {
+		c.Injections().Get("db")
+		return nil, errors.New("boom")
+	}).Name("user")
+	h := &HttpHandler{v}
+
+	r, _ := http.NewRequest("GET", "http://test.com/users/1?password=hunter2&q=x", nil)
+	r.Header.Set("Authorization", "Bearer abc")
+	r.Header.Set("Accept", "text/plain")
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+
+	d := published
+	if d == nil || d != handled {
+		t.Fatalf(err)
+	}
+	if d.Message != "boom" || d.Method != "GET" || d.Route != "/users/{id}" || d.RouteName != "user" {
+		t.Errorf(err)
+	}
+	if d.Params.Get("password") != Redacted || d.Params.Get("q") != "x" || d.Params.Get("id") != "1" {
+		t.Errorf(err)
+	}
+	if d.Header.Get("Authorization") != Redacted || d.Header.Get("Accept") != "text/plain" {
+		t.Errorf(err)
+	}
+	if r.Header.Get("Authorization") != "Bearer abc" {
+		t.Errorf(err)
+	}
+	if len(d.Injections) != 1 || d.Injections[0] != "db" {
+		t.Errorf(err)
+	}
+	if !strings.Contains(d.Stack, "goroutine") {
+		t.Errorf(err)
+	}
+
+	// Test only the first diagnostic is captured
+	c := NewContext(w, r, nil, nil)
+	first := c.Capture(nil, "panic")
+	if c.Capture(errors.New("second"), nil) != first || first.Message != "panic" {
+		t.Errorf(err)
+	}
+}
@@ -0,0 +1,72 @@
+package verto
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"net"
+	"net/http"
+)
+
+// ErrClientClosed is passed to the ErrorHandler if the client went away
+// before its response was written, either because the request was
+// canceled or because writing the response failed. Error handlers
+// should not write a response for ErrClientClosed and may use it to
+// record disconnects separately from server errors.
+var ErrClientClosed = errors.New("client closed request")
+
+// ClientClosed returns whether the client of the request has gone away.
+// Expensive handlers can poll ClientClosed to abandon work early
+func (c *Context) ClientClosed() bool {
+	if c.Request != nil && c.Request.Context().Err() == context.Canceled {
+		return true
+	}
+	if cw, ok := c.Response.(*clientWriter); ok {
+		return cw.err != nil
+	}
+	return false
+}
+
+// clientWriter is an http.ResponseWriter that remembers the first
+// write error so that writes to a client that is gone are not
+// repeated and the disconnect can be reported. It also remembers
+// whether the handler wrote a response itself
+type clientWriter struct {
+	http.ResponseWriter
+
+	err   error
+	wrote bool
+}
+
+func (w *clientWriter) WriteHeader(code int) {
+	w.wrote = true
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *clientWriter) Write(b []byte) (int, error) {
+	w.wrote = true
+	if w.err != nil {
+		return 0, w.err
+	}
+	n, err := w.ResponseWriter.Write(b)
+	if err != nil {
+		w.err = err
+	}
+	return n, err
+}
+
+func (w *clientWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok && w.err == nil {
+		f.Flush()
+	}
+}
+
+// Hijack implements http.Hijacker. A hijacked connection
+// counts as written so that no response is sent for it
+func (w *clientWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	conn, rw, err := Hijack(w.ResponseWriter)
+	if err == nil {
+		w.wrote = true
+	}
+	return conn, rw, err
+}
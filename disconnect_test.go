@@ -0,0 +1,70 @@
+package verto
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestClientClosed(t *testing.T) {
+	defer func() {
+		err := recover()
+		if err != nil {
+			t.Errorf(err.(error).Error())
+		}
+	}()
+
+	err := "Failed client closed."
+
+	var handled error
+	serialized := false
+
+	v := New()
+	v.Logger = &NilLogger{}
+	v.ErrorHandler = ErrorFunc(func(e error, c *Context) {
+		handled = e
+		DefaultErrorFunc(e, c)
+	})
+	v.ResponseHandler = ResponseFunc(func(response interface{}, c *Context) {
+		serialized = true
+		DefaultResponseFunc(response, c)
+	})
+	v.Get("/", func(c *Context) (interface{}, error) { return "ok", nil })
+	h := &HttpHandler{v}
+
+	// Test canceled request
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	r, _ := http.NewRequest("GET", "http://test.com/", nil)
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r.WithContext(ctx))
+	if handled != ErrClientClosed || serialized || w.Body.Len() != 0 {
+		t.Errorf(err)
+	}
+
+	// Test failed write
+	handled = nil
+	fw := &failingResponseWriter{httptest.NewRecorder()}
+	h.ServeHTTP(fw, r)
+	if handled != ErrClientClosed || !serialized {
+		t.Errorf(err)
+	}
+
+	// Test live client
+	handled = nil
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	if handled != nil || w.Body.String() != "ok" {
+		t.Errorf(err)
+	}
+}
+
+type failingResponseWriter struct {
+	*httptest.ResponseRecorder
+}
+
+func (w *failingResponseWriter) Write(b []byte) (int, error) {
+	return 0, errors.New("broken pipe")
+}
@@ -0,0 +1,122 @@
+package discovery
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Consul is a Registry backed by the HTTP API of a Consul agent
+type Consul struct {
+	// Address is the address of the Consul agent
+	// (e.g. http://127.0.0.1:8500)
+	Address string
+
+	// Token is an optional ACL token
+	Token string
+
+	// DeregisterAfter optionally has Consul remove instances whose
+	// check stayed critical for the duration (e.g. after a crash)
+	DeregisterAfter time.Duration
+
+	// Client is the http.Client used for requests.
+	// Defaults to a client with a 10 second timeout
+	Client *http.Client
+}
+
+// NewConsul returns a Consul registry using the agent at address
+func NewConsul(address string) *Consul {
+	return &Consul{Address: strings.TrimRight(address, "/")}
+}
+
+// consulService is the Consul service registration payload
+type consulService struct {
+	ID      string            `json:"ID"`
+	Name    string            `json:"Name"`
+	Address string            `json:"Address,omitempty"`
+	Port    int               `json:"Port,omitempty"`
+	Tags    []string          `json:"Tags,omitempty"`
+	Meta    map[string]string `json:"Meta,omitempty"`
+	Check   consulCheck       `json:"Check"`
+}
+
+type consulCheck struct {
+	TTL                            string `json:"TTL"`
+	DeregisterCriticalServiceAfter string `json:"DeregisterCriticalServiceAfter,omitempty"`
+}
+
+// Register registers inst with a TTL check
+func (c *Consul) Register(inst Instance, ttl time.Duration) error {
+	service := consulService{
+		ID:      inst.Id,
+		Name:    inst.Name,
+		Address: inst.Address,
+		Port:    inst.Port,
+		Tags:    inst.Tags,
+		Meta:    inst.Meta,
+		Check:   consulCheck{TTL: ttl.String()},
+	}
+	if c.DeregisterAfter > 0 {
+		service.Check.DeregisterCriticalServiceAfter = c.DeregisterAfter.String()
+	}
+	body, err := json.Marshal(service)
+	if err != nil {
+		return err
+	}
+	return c.put("/v1/agent/service/register", bytes.NewReader(body))
+}
+
+// Heartbeat passes or fails the TTL check of the instance id
+func (c *Consul) Heartbeat(id string, healthy bool, note string) error {
+	status := "fail"
+	if healthy {
+		status = "pass"
+	}
+	path := "/v1/agent/check/" + status + "/service:" + url.PathEscape(id)
+	if note != "" {
+		path += "?note=" + url.QueryEscape(note)
+	}
+	return c.put(path, nil)
+}
+
+// Deregister removes the instance id
+func (c *Consul) Deregister(id string) error {
+	return c.put("/v1/agent/service/deregister/"+url.PathEscape(id), nil)
+}
+
+// put sends a PUT request to the agent
+func (c *Consul) put(path string, body io.Reader) error {
+	req, err := http.NewRequest("PUT", c.Address+path, body)
+	if err != nil {
+		return err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.Token != "" {
+		req.Header.Set("X-Consul-Token", c.Token)
+	}
+
+	client := c.Client
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("discovery: consul %s responded with %d: %s",
+			path, resp.StatusCode, strings.TrimSpace(string(msg)))
+	}
+	return nil
+}
@@ -0,0 +1,164 @@
+// Package discovery registers Verto instances with service registries.
+// An Agent registers the instance once Verto is ready, reports its health
+// to the registry's TTL check while it runs and deregisters it when Verto
+// stops. Registries are pluggable; a Consul implementation is provided.
+package discovery
+
+import (
+	"github.com/boxtown/verto"
+	"sync"
+	"time"
+)
+
+// Instance describes a registered service instance
+type Instance struct {
+	// Id uniquely identifies the instance
+	Id string
+
+	// Name is the service name
+	Name string
+
+	// Address and Port are where the instance is reachable
+	Address string
+	Port    int
+
+	// Tags and Meta are optional registry metadata
+	Tags []string
+	Meta map[string]string
+}
+
+// Registry is a service registry
+type Registry interface {
+	// Register registers inst with a TTL health check. The
+	// registry marks the instance unhealthy if no heartbeat
+	// is received within ttl
+	Register(inst Instance, ttl time.Duration) error
+
+	// Heartbeat reports the health of the instance id
+	Heartbeat(id string, healthy bool, note string) error
+
+	// Deregister removes the instance id
+	Deregister(id string) error
+}
+
+// Agent keeps the registration of a Verto instance up to date
+//
+// Example usage:
+//
+//	consul := discovery.NewConsul("http://127.0.0.1:8500")
+//	agent := discovery.New(consul, discovery.Instance{
+//		Id: "api-1", Name: "api", Address: "10.0.0.5", Port: 8080,
+//	})
+//	agent.Attach(v)
+//	v.Run()
+type Agent struct {
+	// Registry is the service registry
+	Registry Registry
+
+	// Instance is the registered instance
+	Instance Instance
+
+	// TTL is the TTL of the health check. Heartbeats are
+	// sent every third of the TTL. Defaults to 15 seconds
+	TTL time.Duration
+
+	// Healthy reports the health sent with heartbeats.
+	// Defaults to the attached Verto instance's Ready
+	Healthy func() bool
+
+	// OnError is an optional callback for registry errors
+	OnError func(err error)
+
+	stop  chan struct{}
+	mutex sync.Mutex
+}
+
+// New returns an Agent registering inst with registry
+func New(registry Registry, inst Instance) *Agent {
+	return &Agent{Registry: registry, Instance: inst}
+}
+
+// Attach registers the instance when v becomes ready and deregisters
+// it when v stops. Returns a function detaching the agent from v
+func (a *Agent) Attach(v *verto.Verto) func() {
+	if a.Healthy == nil {
+		a.Healthy = v.Ready
+	}
+	ready := v.Events.Subscribe(verto.ReadyEvent, func(e verto.Event) {
+		a.report(a.Start())
+	})
+	stopped := v.Events.Subscribe(verto.StoppedEvent, func(e verto.Event) {
+		a.report(a.Stop())
+	})
+	return func() {
+		ready()
+		stopped()
+	}
+}
+
+// Start registers the instance and starts sending heartbeats
+func (a *Agent) Start() error {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	if a.stop != nil {
+		return nil
+	}
+	if err := a.Registry.Register(a.Instance, a.ttl()); err != nil {
+		return err
+	}
+	a.stop = make(chan struct{})
+	go a.heartbeat(a.stop)
+	return nil
+}
+
+// Stop stops sending heartbeats and deregisters the instance
+func (a *Agent) Stop() error {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	if a.stop == nil {
+		return nil
+	}
+	close(a.stop)
+	a.stop = nil
+	return a.Registry.Deregister(a.Instance.Id)
+}
+
+// Beat sends a single heartbeat
+func (a *Agent) Beat() error {
+	healthy := a.Healthy == nil || a.Healthy()
+	note := "ready"
+	if !healthy {
+		note = "not ready"
+	}
+	return a.Registry.Heartbeat(a.Instance.Id, healthy, note)
+}
+
+func (a *Agent) heartbeat(stop chan struct{}) {
+	a.report(a.Beat())
+
+	ticker := time.NewTicker(a.ttl() / 3)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			a.report(a.Beat())
+		}
+	}
+}
+
+func (a *Agent) report(err error) {
+	if err != nil && a.OnError != nil {
+		a.OnError(err)
+	}
+}
+
+func (a *Agent) ttl() time.Duration {
+	if a.TTL <= 0 {
+		return 15 * time.Second
+	}
+	return a.TTL
+}
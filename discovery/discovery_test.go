@@ -0,0 +1,94 @@
+package discovery
+
+import (
+	"encoding/json"
+	"github.com/boxtown/verto"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestAgent(t *testing.T) {
+	defer func() {
+		err := recover()
+		if err != nil {
+			t.Errorf(err.(error).Error())
+		}
+	}()
+
+	err := "Failed agent."
+
+	mutex := &sync.Mutex{}
+	calls := make([]string, 0)
+	var registered consulService
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mutex.Lock()
+		defer mutex.Unlock()
+
+		if r.Method != "PUT" || r.Header.Get("X-Consul-Token") != "secret" {
+			w.WriteHeader(403)
+			return
+		}
+		if r.URL.Path == "/v1/agent/service/register" {
+			json.NewDecoder(r.Body).Decode(&registered)
+		}
+		calls = append(calls, r.URL.Path+"?"+r.URL.RawQuery)
+	}))
+	defer server.Close()
+
+	consul := NewConsul(server.URL + "/")
+	consul.Token = "secret"
+	consul.DeregisterAfter = time.Minute
+
+	healthy := true
+	agent := New(consul, Instance{Id: "api-1", Name: "api", Address: "10.0.0.5", Port: 8080, Tags: []string{"v1"}})
+	agent.TTL = 30 * time.Millisecond
+	agent.Healthy = func() bool {
+		mutex.Lock()
+		defer mutex.Unlock()
+		return healthy
+	}
+	failures := make([]error, 0)
+	agent.OnError = func(e error) { failures = append(failures, e) }
+
+	v := verto.New()
+	v.Logger = &verto.NilLogger{}
+	agent.Attach(v)
+
+	v.Events.Publish(verto.ReadyEvent, nil)
+	time.Sleep(25 * time.Millisecond)
+	mutex.Lock()
+	healthy = false
+	mutex.Unlock()
+	time.Sleep(25 * time.Millisecond)
+	v.Events.Publish(verto.StoppedEvent, nil)
+
+	mutex.Lock()
+	if registered.ID != "api-1" || registered.Port != 8080 || registered.Check.TTL != "30ms" ||
+		registered.Check.DeregisterCriticalServiceAfter != "1m0s" {
+		t.Errorf(err)
+	}
+	if len(calls) < 4 || calls[1] != "/v1/agent/check/pass/service:api-1?note=ready" {
+		mutex.Unlock()
+		t.Fatalf(err)
+	}
+	seen := make(map[string]bool)
+	for _, call := range calls {
+		seen[call] = true
+	}
+	if !seen["/v1/agent/check/fail/service:api-1?note=not+ready"] || !seen["/v1/agent/service/deregister/api-1?"] {
+		t.Errorf(err)
+	}
+	if len(failures) != 0 {
+		t.Errorf(err)
+	}
+	mutex.Unlock()
+
+	// Test registry errors are returned
+	consul.Token = ""
+	if agent.Start() == nil {
+		t.Errorf(err)
+	}
+}
@@ -0,0 +1,131 @@
+package verto
+
+import (
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// DownloadRateKey is the endpoint metadata key for limiting the
+// rate at which download endpoints stream their content. The value
+// must be an int64 (or int) number of bytes per second.
+//
+// Example usage:
+//
+//	v.Download("/files/{id}", opener).Meta(verto.DownloadRateKey, 512<<10)
+const DownloadRateKey = "verto.download.rate"
+
+// DownloadOpener opens the content served by a download endpoint.
+// The returned ReadSeeker is closed after the response if it
+// implements io.Closer. Errors satisfying os.IsNotExist result in
+// a 404 response, other errors are passed to the ErrorHandler.
+type DownloadOpener func(c *Context) (io.ReadSeeker, os.FileInfo, error)
+
+// Download registers a GET and HEAD endpoint at path serving the content
+// returned by opener as an attachment. Range and conditional requests are
+// honored using the modification time of the content. Complete responses
+// carry a Digest trailer if DownloadDigestKey is set. The GET Endpoint
+// is returned.
+func (v *Verto) Download(path string, opener DownloadOpener) *Endpoint {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		c := v.context(w, r)
+		content, info, err := opener(c)
+		if err != nil {
+			if os.IsNotExist(err) {
+				v.table().NotFound.ServeHTTP(w, r)
+			} else {
+				v.errorHandler(c).Handle(err, c)
+			}
+			return
+		}
+		if closer, ok := content.(io.Closer); ok {
+			defer closer.Close()
+		}
+
+		name := filepath.Base(info.Name())
+		w.Header().Set("Content-Disposition",
+			mime.FormatMediaType("attachment", map[string]string{"filename": name}))
+
+		if rate := downloadRate(c); rate > 0 && r.Method != "HEAD" {
+			w = &throttledWriter{ResponseWriter: w, rate: rate}
+		}
+		if digest, _ := c.RouteMeta(DownloadDigestKey); digest == true && r.Method != "HEAD" {
+			dw := newDigestWriter(w)
+			defer dw.finish()
+			w = dw
+		}
+		http.ServeContent(w, r, name, info.ModTime(), content)
+	})
+
+	v.AddHandler("HEAD", path, handler)
+	return v.AddHandler("GET", path, handler)
+}
+
+// downloadRate returns the download rate set on the
+// route matched by c or zero if no rate is set
+func downloadRate(c *Context) int64 {
+	rate, ok := c.RouteMeta(DownloadRateKey)
+	if !ok {
+		return 0
+	}
+	switch rate := rate.(type) {
+	case int64:
+		return rate
+	case int:
+		return int64(rate)
+	}
+	return 0
+}
+
+// throttledWriter is an http.ResponseWriter that limits
+// the rate at which the response body is written
+type throttledWriter struct {
+	http.ResponseWriter
+
+	rate    int64
+	start   time.Time
+	written int64
+}
+
+// Write writes b in chunks of at most a tenth of the rate,
+// sleeping between chunks to stay at or below the rate
+func (w *throttledWriter) Write(b []byte) (int, error) {
+	if w.start.IsZero() {
+		w.start = time.Now()
+	}
+	chunk := int(w.rate / 10)
+	if chunk < 1 {
+		chunk = 1
+	}
+
+	total := 0
+	for len(b) > 0 {
+		n := chunk
+		if n > len(b) {
+			n = len(b)
+		}
+		n, err := w.ResponseWriter.Write(b[:n])
+		total += n
+		w.written += int64(n)
+		if err != nil {
+			return total, err
+		}
+		b = b[n:]
+
+		expected := time.Duration(w.written * int64(time.Second) / w.rate)
+		if elapsed := time.Since(w.start); elapsed < expected {
+			time.Sleep(expected - elapsed)
+		}
+	}
+	return total, nil
+}
+
+// Flush implements http.Flusher
+func (w *throttledWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
@@ -0,0 +1,113 @@
+package verto
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+)
+
+type testFileInfo struct {
+	name string
+	size int64
+}
+
+func (fi testFileInfo) Name() string       { return fi.name }
+func (fi testFileInfo) Size() int64        { return fi.size }
+func (fi testFileInfo) Mode() os.FileMode  { return 0644 }
+func (fi testFileInfo) ModTime() time.Time { return time.Unix(1000, 0) }
+func (fi testFileInfo) IsDir() bool        { return false }
+func (fi testFileInfo) Sys() interface{}   { return nil }
+
+func TestDownload(t *testing.T) {
+	defer func() {
+		err := recover()
+		if err != nil {
+			t.Errorf(err.(error).Error())
+		}
+	}()
+
+	err := "Failed download."
+
+	v := New()
+	v.Logger = &NilLogger{}
+	v.Download("/files/{name}", func(c *Context) (io.ReadSeeker, os.FileInfo, error) {
+		if c.Get("name") != "report.txt" {
+			return nil, nil, os.ErrNotExist
+		}
+		return bytes.NewReader([]byte("0123456789")), testFileInfo{"report.txt", 10}, nil
+	}).Meta(DownloadRateKey, 1<<20)
+	h := &HttpHandler{v}
+
+	r, _ := http.NewRequest("GET", "http://test.com/files/report.txt", nil)
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	if w.Code != 200 || w.Body.String() != "0123456789" {
+		t.Errorf(err)
+	}
+	if w.Header().Get("Content-Disposition") != `attachment; filename=report.txt` {
+		t.Errorf(err)
+	}
+	if w.Header().Get("Accept-Ranges") != "bytes" {
+		t.Errorf(err)
+	}
+
+	// Test range request
+	r, _ = http.NewRequest("GET", "http://test.com/files/report.txt", nil)
+	r.Header.Set("Range", "bytes=2-4")
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	if w.Code != http.StatusPartialContent || w.Body.String() != "234" {
+		t.Errorf(err)
+	}
+
+	// Test conditional request
+	r, _ = http.NewRequest("GET", "http://test.com/files/report.txt", nil)
+	r.Header.Set("If-Modified-Since", time.Unix(2000, 0).UTC().Format(http.TimeFormat))
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	if w.Code != http.StatusNotModified {
+		t.Errorf(err)
+	}
+
+	// Test HEAD
+	r, _ = http.NewRequest("HEAD", "http://test.com/files/report.txt", nil)
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	if w.Code != 200 || w.Body.Len() != 0 || w.Header().Get("Content-Length") != "10" {
+		t.Errorf(err)
+	}
+
+	// Test not found
+	r, _ = http.NewRequest("GET", "http://test.com/files/missing.txt", nil)
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	if w.Code != 404 {
+		t.Errorf(err)
+	}
+}
+
+func TestThrottledWriter(t *testing.T) {
+	defer func() {
+		err := recover()
+		if err != nil {
+			t.Errorf(err.(error).Error())
+		}
+	}()
+
+	err := "Failed throttled writer."
+
+	rec := httptest.NewRecorder()
+	w := &throttledWriter{ResponseWriter: rec, rate: 1000}
+	start := time.Now()
+	n, e := w.Write(make([]byte, 200))
+	if e != nil || n != 200 || rec.Body.Len() != 200 {
+		t.Errorf(err)
+	}
+	if time.Since(start) < 150*time.Millisecond {
+		t.Errorf(err)
+	}
+}
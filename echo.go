@@ -0,0 +1,113 @@
+package verto
+
+import (
+	"github.com/boxtown/verto/mux"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+)
+
+// DefaultEchoPath is the path of the echo endpoint
+// registered by EnableEcho if no path is given
+const DefaultEchoPath = "/_echo"
+
+// MaxEchoBody is the number of request body bytes
+// included in echo responses
+var MaxEchoBody int64 = 64 << 10
+
+// principalInjection is the injection key of request principals.
+// It mirrors authz.PRINCIPALKEY, which verto cannot import
+const principalInjection = "_VertoPrincipal"
+
+// Echo is the request as seen by the echo endpoint
+type Echo struct {
+	Method     string                 `json:"method"`
+	URL        string                 `json:"url"`
+	Proto      string                 `json:"proto"`
+	Host       string                 `json:"host"`
+	RemoteAddr string                 `json:"remoteAddr"`
+	IP         string                 `json:"ip"`
+	Header     http.Header            `json:"header"`
+	Params     url.Values             `json:"params,omitempty"`
+	Route      *RouteInfo             `json:"route,omitempty"`
+	Principal  *EchoPrincipal         `json:"principal,omitempty"`
+	TLS        string                 `json:"tls,omitempty"`
+	Fields     map[string]interface{} `json:"fields,omitempty"`
+	Body       string                 `json:"body,omitempty"`
+	Truncated  bool                   `json:"truncated,omitempty"`
+}
+
+// EchoPrincipal is the authenticated principal of an echoed request
+type EchoPrincipal struct {
+	ID    string   `json:"id"`
+	Roles []string `json:"roles,omitempty"`
+}
+
+// EnableEcho registers an echo endpoint at path (DefaultEchoPath if empty)
+// for all methods. The endpoint responds with the request as the server
+// saw it after global plugins ran: the possibly rewritten headers, the
+// matched route, the parameters, the authenticated principal, the promoted
+// LogFields and up to MaxEchoBody bytes of the body. This helps debugging
+// proxies and plugins rewriting requests. The endpoint is served behind
+// the passed in access plugins, which should authenticate administrators.
+// If no access plugins are given, only loopback clients are allowed.
+// Sensitive headers are not redacted.
+//
+// Example usage:
+//
+//	v.EnableEcho("", apikeys.New(v.Injections, m), authz.Require("admin"))
+func (v *Verto) EnableEcho(path string, access ...Plugin) Endpoints {
+	if path == "" {
+		path = DefaultEchoPath
+	}
+	if len(access) == 0 {
+		access = []Plugin{AllowIPs("127.0.0.0/8", "::1")}
+	}
+	eps := v.AnyHandler(path, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		writeAdminJSON(w, v.echo(w, r))
+	}))
+	for _, plugin := range access {
+		eps = eps.Use(plugin)
+	}
+	return eps.Meta(AdminKey, true)
+}
+
+// echo returns the Echo of r
+func (v *Verto) echo(w http.ResponseWriter, r *http.Request) *Echo {
+	c := v.context(w, r)
+	e := &Echo{
+		Method:     r.Method,
+		URL:        r.URL.String(),
+		Proto:      r.Proto,
+		Host:       r.Host,
+		RemoteAddr: r.RemoteAddr,
+		IP:         GetIP(r),
+		Header:     r.Header,
+		Params:     r.URL.Query(),
+		Fields:     c.LogFields(),
+	}
+	if info := c.TLS(); info != nil {
+		e.TLS = info.VersionName()
+	}
+	if route := mux.CurrentRoute(r); route != nil {
+		e.Route = &RouteInfo{route.Method(), route.Path(), route.Name(), route.Host()}
+	}
+	if injections := RequestInjections(r); injections != nil {
+		if p, ok := injections.Get(principalInjection).(interface {
+			ID() string
+			Roles() []string
+		}); ok && p != nil {
+			e.Principal = &EchoPrincipal{ID: p.ID(), Roles: p.Roles()}
+		}
+	}
+	if r.Body != nil {
+		b, _ := ioutil.ReadAll(io.LimitReader(r.Body, MaxEchoBody+1))
+		if int64(len(b)) > MaxEchoBody {
+			b = b[:MaxEchoBody]
+			e.Truncated = true
+		}
+		e.Body = string(b)
+	}
+	return e
+}
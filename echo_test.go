@@ -0,0 +1,61 @@
+package verto
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type echoUser struct{}
+
+func (u *echoUser) ID() string      { return "u1" }
+func (u *echoUser) Roles() []string { return []string{"admin"} }
+
+func TestEcho(t *testing.T) {
+	defer func() {
+		err := recover()
+		if err != nil {
+			t.Errorf(err.(error).Error())
+		}
+	}()
+
+	err := "Failed echo."
+
+	v := New()
+	v.Logger = &NilLogger{}
+	v.Use(PluginFunc(func(c *Context, next http.HandlerFunc) {
+		c.Request.Header.Set("X-Rewritten", "yes")
+		c.Injections().Set(principalInjection, &echoUser{})
+		next(c.Response, c.Request)
+	}))
+	v.EnableEcho("")
+	h := &HttpHandler{v}
+
+	serve := func(remote string) *httptest.ResponseRecorder {
+		r, _ := http.NewRequest("POST", "http://test.com/_echo?a=b", strings.NewReader("hello"))
+		r.RemoteAddr = remote
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		return w
+	}
+
+	w := serve("127.0.0.1:1234")
+	e := &Echo{}
+	json.Unmarshal(w.Body.Bytes(), e)
+	if w.Code != 200 || e.Method != "POST" || e.Header.Get("X-Rewritten") != "yes" || e.Params.Get("a") != "b" {
+		t.Errorf(err)
+	}
+	if e.Route == nil || e.Route.Path != "/_echo" || e.Body != "hello" || e.IP != "127.0.0.1" {
+		t.Errorf(err)
+	}
+	if e.Principal == nil || e.Principal.ID != "u1" || e.Principal.Roles[0] != "admin" {
+		t.Errorf(err)
+	}
+
+	// Test non-loopback clients are denied by default
+	if serve("203.0.113.1:1234").Code != http.StatusForbidden {
+		t.Errorf(err)
+	}
+}
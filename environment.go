@@ -0,0 +1,95 @@
+package verto
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Environments recognized by SetEnvironment
+const (
+	Development = "development"
+	Production  = "production"
+	Test        = "test"
+)
+
+// ErrInsecure is the panic value of Run and RunOn in the production
+// environment if no TLSConfig is set and AllowInsecure is false
+var ErrInsecure = errors.New("verto: production environment requires TLS")
+
+// DefaultTimeouts are the server timeouts enforced
+// in the production environment
+var DefaultTimeouts = Timeouts{
+	ReadHeader: 10 * time.Second,
+	Read:       30 * time.Second,
+	Write:      60 * time.Second,
+	Idle:       120 * time.Second,
+}
+
+// Timeouts are the timeouts of the http.Server run by Verto.
+// Zero values mean no timeout
+type Timeouts struct {
+	ReadHeader time.Duration
+	Read       time.Duration
+	Write      time.Duration
+	Idle       time.Duration
+}
+
+// SetEnvironment configures Verto with the bundle of settings for the
+// named environment:
+//
+//	development  verbose logging, indented JSON responses, stack traces
+//	             in default error responses and profiling routes under
+//	             /debug for loopback clients
+//	production   DefaultTimeouts unless Timeouts are set, no stack traces
+//	             in responses and Run and RunOn refuse to serve without a
+//	             TLSConfig unless AllowInsecure is set
+//	test         quiet logging and no timeouts
+//
+// Settings can be adjusted individually after SetEnvironment is called.
+// SetEnvironment panics for unknown environments
+func (v *Verto) SetEnvironment(env string) {
+	switch env {
+	case Development:
+		v.SetVerbose(true)
+		v.debug = true
+		v.prettyJSON = true
+		v.requireTLS = false
+		if !v.debugRoutes {
+			v.debugRoutes = true
+			v.EnableProfiling("/debug")
+		}
+	case Production:
+		v.SetVerbose(false)
+		v.debug = false
+		v.prettyJSON = false
+		v.requireTLS = true
+		if v.Timeouts == (Timeouts{}) {
+			v.Timeouts = DefaultTimeouts
+		}
+	case Test:
+		v.SetVerbose(false)
+		v.Logger = &NilLogger{}
+		v.debug = false
+		v.prettyJSON = false
+		v.requireTLS = false
+		v.Timeouts = Timeouts{}
+	default:
+		panic(fmt.Errorf("verto: unknown environment %q", env))
+	}
+	v.env = env
+	v.audit(AuditConfigChanged, AuditSourceAPI, "environment: "+env)
+}
+
+// Environment returns the name of the environment set
+// through SetEnvironment or an empty string if none was set
+func (v *Verto) Environment() string {
+	return v.env
+}
+
+// Debug returns whether Verto runs in the development environment.
+// Error handlers can include debugging details such as the request's
+// Diagnostic in responses if Debug is true
+func (c *Context) Debug() bool {
+	return c.v != nil && c.v.debug
+}
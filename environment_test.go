@@ -0,0 +1,90 @@
+package verto
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestSetEnvironment(t *testing.T) {
+	defer func() {
+		err := recover()
+		if err != nil {
+			t.Errorf(err.(error).Error())
+		}
+	}()
+
+	err := "Failed set environment."
+
+	v := New()
+	v.Logger = &NilLogger{}
+	v.ResponseHandler = ResponseFunc(JSONResponseFunc)
+	v.Get("/json", func(c *Context) (interface{}, error) {
+		return map[string]int{"a": 1}, nil
+	})
+	v.Get("/error", func(c *Context) (interface{}, error) {
+		return nil, errors.New("boom")
+	})
+	h := &HttpHandler{v}
+
+	serve := func(path, remote string) *httptest.ResponseRecorder {
+		r, _ := http.NewRequest("GET", "http://test.com"+path, nil)
+		r.RemoteAddr = remote
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		return w
+	}
+
+	if serve("/json", "127.0.0.1:1").Body.String() != `{"a":1}` {
+		t.Errorf(err)
+	}
+
+	v.SetEnvironment(Development)
+	v.Logger = &NilLogger{}
+	if v.Environment() != Development || !v.verbose {
+		t.Errorf(err)
+	}
+	if serve("/json", "127.0.0.1:1").Body.String() != "{\n  \"a\": 1\n}" {
+		t.Errorf(err)
+	}
+	if body := serve("/error", "127.0.0.1:1").Body.String(); !strings.HasPrefix(body, "boom\n\ngoroutine") {
+		t.Errorf(err)
+	}
+	if serve("/debug/vars", "127.0.0.1:1").Code != 200 || serve("/debug/vars", "10.0.0.1:1").Code != 403 {
+		t.Errorf(err)
+	}
+
+	// Test switching environments does not register debug routes twice
+	v.SetEnvironment(Development)
+
+	v.SetEnvironment(Production)
+	if v.Timeouts != DefaultTimeouts {
+		t.Errorf(err)
+	}
+	if serve("/error", "127.0.0.1:1").Body.String() != "boom" {
+		t.Errorf(err)
+	}
+	func() {
+		defer func() {
+			if recover() != ErrInsecure {
+				t.Errorf(err)
+			}
+		}()
+		v.RunOn("127.0.0.1:0")
+	}()
+
+	v.SetEnvironment(Test)
+	if v.Timeouts != (Timeouts{}) || v.Environment() != Test {
+		t.Errorf(err)
+	}
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Errorf(err)
+			}
+		}()
+		v.SetEnvironment("staging")
+	}()
+}
@@ -0,0 +1,83 @@
+package verto
+
+import (
+	"errors"
+	"net/http"
+)
+
+// HTTPError is an error carrying the HTTP status, message and
+// optional details of the response it should produce. Resource
+// functions return HTTPErrors to control the status of error
+// responses. DefaultErrorFunc writes the carried status and
+// message, and the details as JSON if present
+type HTTPError struct {
+	// Status is the HTTP status of the response
+	Status int
+
+	// Message is the message sent to the client.
+	// Defaults to the status text
+	Message string
+
+	// Details are optional structured details sent to the
+	// client (e.g. the fields that failed validation)
+	Details interface{}
+
+	// Err is the optional underlying cause. It is
+	// not sent to the client
+	Err error
+}
+
+// NewError returns an HTTPError with status and message.
+//
+// Example usage:
+//
+//	v.Get("/users/{id}", func(c *verto.Context) (interface{}, error) {
+//		user, ok := users[c.Param("id")]
+//		if !ok {
+//			return nil, verto.NewError(404, "user not found")
+//		}
+//		return user, nil
+//	})
+func NewError(status int, message string) *HTTPError {
+	return &HTTPError{Status: status, Message: message}
+}
+
+// Error returns the message of the error or
+// the status text if no message is set
+func (e *HTTPError) Error() string {
+	if e.Message == "" {
+		return http.StatusText(e.Status) + "."
+	}
+	return e.Message
+}
+
+// Unwrap returns the underlying cause of the error
+func (e *HTTPError) Unwrap() error {
+	return e.Err
+}
+
+// WithDetails sets the details of the error and returns the error
+func (e *HTTPError) WithDetails(details interface{}) *HTTPError {
+	e.Details = details
+	return e
+}
+
+// WithCause sets the underlying cause of the error and returns the error
+func (e *HTTPError) WithCause(err error) *HTTPError {
+	e.Err = err
+	return e
+}
+
+// ErrorStatus returns the HTTP status carried by err or one of the
+// errors it wraps: the status of an HTTPError, 504 for
+// ErrDeadlineExceeded and 500 otherwise
+func ErrorStatus(err error) int {
+	var he *HTTPError
+	if errors.As(err, &he) {
+		return he.Status
+	}
+	if errors.Is(err, ErrDeadlineExceeded) {
+		return http.StatusGatewayTimeout
+	}
+	return http.StatusInternalServerError
+}
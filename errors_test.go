@@ -0,0 +1,72 @@
+package verto
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestHTTPError(t *testing.T) {
+	defer func() {
+		err := recover()
+		if err != nil {
+			t.Errorf(err.(error).Error())
+		}
+	}()
+
+	err := "Failed HTTP error."
+
+	cause := errors.New("db down")
+	v := New()
+	v.Logger = &NilLogger{}
+	v.Get("/missing", func(c *Context) (interface{}, error) {
+		return nil, NewError(404, "user not found")
+	})
+	v.Get("/wrapped", func(c *Context) (interface{}, error) {
+		return nil, fmt.Errorf("loading: %w", NewError(503, "").WithCause(cause))
+	})
+	v.Get("/invalid", func(c *Context) (interface{}, error) {
+		return nil, NewError(422, "invalid user").WithDetails(map[string]string{"name": "required"})
+	})
+	v.Get("/plain", func(c *Context) (interface{}, error) {
+		return nil, errors.New("plain")
+	})
+	h := &HttpHandler{v}
+
+	serve := func(path string) *httptest.ResponseRecorder {
+		r, _ := http.NewRequest("GET", "http://test.com"+path, nil)
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		return w
+	}
+
+	if w := serve("/missing"); w.Code != 404 || w.Body.String() != "user not found" {
+		t.Errorf(err)
+	}
+	if w := serve("/wrapped"); w.Code != 503 || w.Body.String() != "Service Unavailable." {
+		t.Errorf(err)
+	}
+	w := serve("/invalid")
+	body := struct {
+		Error   string
+		Details map[string]string
+	}{}
+	json.Unmarshal(w.Body.Bytes(), &body)
+	if w.Code != 422 || body.Error != "invalid user" || body.Details["name"] != "required" {
+		t.Errorf(err)
+	}
+	if w := serve("/plain"); w.Code != 500 || w.Body.String() != "plain" {
+		t.Errorf(err)
+	}
+
+	// Test status helpers
+	if !errors.Is(NewError(500, "").WithCause(cause), cause) {
+		t.Errorf(err)
+	}
+	if ErrorStatus(&HTTPError{Status: 409}) != 409 || ErrorStatus(ErrDeadlineExceeded) != 504 || ErrorStatus(cause) != 500 {
+		t.Errorf(err)
+	}
+}
@@ -0,0 +1,87 @@
+package verto
+
+import (
+	"sync"
+	"time"
+)
+
+// AllEvents is the topic for subscribing to every event
+const AllEvents = "*"
+
+// Event is a message published on an EventBus
+type Event struct {
+	// Topic is the topic the event was published under
+	Topic string
+
+	// Time is the time the event was published
+	Time time.Time
+
+	// Data is the event payload
+	Data interface{}
+}
+
+// EventHandler handles events delivered by an EventBus
+type EventHandler func(e Event)
+
+// EventBus is a simple thread-safe publish/subscribe bus for
+// in-process events. Handlers are called synchronously in the
+// publishing goroutine in the order they subscribed, so handlers
+// performing slow work should hand events off to their own goroutines.
+type EventBus struct {
+	subs  map[string][]*subscription
+	mutex *sync.RWMutex
+}
+
+// subscription is a registered EventHandler
+type subscription struct {
+	handler EventHandler
+}
+
+// NewEventBus returns a newly initialized EventBus
+func NewEventBus() *EventBus {
+	return &EventBus{
+		subs:  make(map[string][]*subscription),
+		mutex: &sync.RWMutex{},
+	}
+}
+
+// Subscribe registers handler for events published under topic.
+// Subscribing to AllEvents receives every event. The returned function
+// removes the subscription
+func (b *EventBus) Subscribe(topic string, handler EventHandler) func() {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	sub := &subscription{handler: handler}
+	b.subs[topic] = append(b.subs[topic], sub)
+
+	return func() {
+		b.mutex.Lock()
+		defer b.mutex.Unlock()
+
+		subs := b.subs[topic]
+		for i, s := range subs {
+			if s == sub {
+				b.subs[topic] = append(subs[:i:i], subs[i+1:]...)
+				return
+			}
+		}
+	}
+}
+
+// Publish publishes data under topic to all subscribed handlers
+func (b *EventBus) Publish(topic string, data interface{}) {
+	e := Event{Topic: topic, Time: time.Now().UTC(), Data: data}
+
+	b.mutex.RLock()
+	handlers := make([]*subscription, 0, len(b.subs[topic])+len(b.subs[AllEvents]))
+	handlers = append(handlers, b.subs[topic]...)
+	if topic != AllEvents {
+		handlers = append(handlers, b.subs[AllEvents]...)
+	}
+	b.mutex.RUnlock()
+
+	for _, sub := range handlers {
+		sub.handler(e)
+	}
+}
@@ -0,0 +1,40 @@
+package verto
+
+import (
+	"testing"
+)
+
+func TestEventBus(t *testing.T) {
+	defer func() {
+		err := recover()
+		if err != nil {
+			t.Errorf(err.(error).Error())
+		}
+	}()
+
+	err := "Failed event bus."
+
+	b := NewEventBus()
+	received := make([]string, 0)
+	unsubscribe := b.Subscribe("user.created", func(e Event) {
+		received = append(received, e.Topic+":"+e.Data.(string))
+	})
+	b.Subscribe(AllEvents, func(e Event) {
+		received = append(received, "*:"+e.Topic)
+	})
+
+	b.Publish("user.created", "a")
+	b.Publish("user.deleted", "b")
+	unsubscribe()
+	b.Publish("user.created", "c")
+
+	expected := []string{"user.created:a", "*:user.created", "*:user.deleted", "*:user.created"}
+	if len(received) != len(expected) {
+		t.Fatalf(err)
+	}
+	for i := range expected {
+		if received[i] != expected[i] {
+			t.Errorf(err)
+		}
+	}
+}
@@ -0,0 +1,204 @@
+package verto
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// FieldsParam is the request parameter recognized by FilterFields
+const FieldsParam = "fields"
+
+// FieldsKey is the route metadata key for the
+// fields clients may select with FieldsParam
+const FieldsKey = "verto.fields"
+
+// fieldTree is a set of selected field paths. A nil
+// subtree selects the field with all its children
+type fieldTree map[string]fieldTree
+
+// Fields restricts the fields clients may select with the fields
+// parameter on the route represented by the Endpoint to allowed.
+// Allowing a field allows all of its children. Requests selecting
+// other fields receive a 400 response
+func (ep *Endpoint) Fields(allowed ...string) *Endpoint {
+	return ep.Meta(FieldsKey, allowed)
+}
+
+// FilterFields wraps handler such that responses are pruned to the
+// fields selected with the fields request parameter before being passed
+// to handler. Fields are comma separated and nested fields are selected
+// with dotted paths (e.g. ?fields=id,author.name). Arrays are filtered
+// element-wise, PagedResponses are filtered per item and Resources
+// are filtered within their data. Field names are JSON field names so
+// handler should render JSON. Requests without the fields parameter
+// are passed through unchanged.
+//
+// Example usage:
+//
+//	v.ResponseHandler = verto.FilterFields(verto.ResponseFunc(verto.JSONResponseFunc))
+//	v.Get("/posts", listPosts).Fields("id", "title", "author")
+func FilterFields(handler ResponseHandler) ResponseHandler {
+	return ResponseFunc(func(response interface{}, c *Context) {
+		if c.Request == nil || response == nil {
+			handler.Handle(response, c)
+			return
+		}
+		selected := c.Request.URL.Query().Get(FieldsParam)
+		if selected == "" {
+			handler.Handle(response, c)
+			return
+		}
+
+		paths := make([]string, 0)
+		for _, p := range strings.Split(selected, ",") {
+			if p = strings.TrimSpace(p); p != "" {
+				paths = append(paths, p)
+			}
+		}
+		if allowed, ok := c.RouteMeta(FieldsKey); ok {
+			if list, ok := allowed.([]string); ok {
+				for _, p := range paths {
+					if !fieldAllowed(p, list) {
+						c.Response.WriteHeader(http.StatusBadRequest)
+						fmt.Fprintf(c.Response, "Unknown field %s.", p)
+						return
+					}
+				}
+			}
+		}
+		tree := parseFields(paths)
+
+		switch r := response.(type) {
+		case *PagedResponse:
+			filtered := *r
+			filtered.Items = filterValue(r.Items, tree)
+			response = &filtered
+		case *Resource:
+			r.resolve(c)
+			filtered := *r
+			filtered.Data = filterValue(r.Data, tree)
+			response = &filtered
+		default:
+			response = filterValue(response, tree)
+		}
+		handler.Handle(response, c)
+	})
+}
+
+// fieldAllowed returns whether path is one of
+// allowed or nested under one of allowed
+func fieldAllowed(path string, allowed []string) bool {
+	for _, a := range allowed {
+		if path == a || strings.HasPrefix(path, a+".") {
+			return true
+		}
+	}
+	return false
+}
+
+// parseFields builds a fieldTree from dotted paths
+func parseFields(paths []string) fieldTree {
+	tree := make(fieldTree)
+	for _, p := range paths {
+		node := tree
+		parts := strings.Split(p, ".")
+		for i, part := range parts {
+			sub, ok := node[part]
+			if ok && sub == nil {
+				// The whole field is already selected
+				break
+			}
+			if i == len(parts)-1 {
+				node[part] = nil
+				break
+			}
+			if !ok {
+				sub = make(fieldTree)
+				node[part] = sub
+			}
+			node = sub
+		}
+	}
+	return tree
+}
+
+// filterValue returns the JSON representation of v pruned to tree.
+// v is returned unchanged if it can't be marshalled
+func filterValue(v interface{}, tree fieldTree) interface{} {
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return v
+	}
+	filtered, err := filterJSON(raw, tree)
+	if err != nil {
+		return v
+	}
+	return filtered
+}
+
+// filterJSON prunes the JSON document raw to tree
+// preserving the order of the remaining fields
+func filterJSON(raw json.RawMessage, tree fieldTree) (json.RawMessage, error) {
+	raw = bytes.TrimSpace(raw)
+	if len(raw) == 0 {
+		return raw, nil
+	}
+
+	switch raw[0] {
+	case '[':
+		elems := make([]json.RawMessage, 0)
+		if err := json.Unmarshal(raw, &elems); err != nil {
+			return nil, err
+		}
+		for i, e := range elems {
+			filtered, err := filterJSON(e, tree)
+			if err != nil {
+				return nil, err
+			}
+			elems[i] = filtered
+		}
+		return json.Marshal(elems)
+	case '{':
+		dec := json.NewDecoder(bytes.NewReader(raw))
+		if _, err := dec.Token(); err != nil {
+			return nil, err
+		}
+		buf := &bytes.Buffer{}
+		buf.WriteByte('{')
+		first := true
+		for dec.More() {
+			tok, err := dec.Token()
+			if err != nil {
+				return nil, err
+			}
+			var value json.RawMessage
+			if err := dec.Decode(&value); err != nil {
+				return nil, err
+			}
+			key := tok.(string)
+			sub, ok := tree[key]
+			if !ok {
+				continue
+			}
+			if sub != nil {
+				if value, err = filterJSON(value, sub); err != nil {
+					return nil, err
+				}
+			}
+			if !first {
+				buf.WriteByte(',')
+			}
+			first = false
+			k, _ := json.Marshal(key)
+			buf.Write(k)
+			buf.WriteByte(':')
+			buf.Write(value)
+		}
+		buf.WriteByte('}')
+		return json.RawMessage(buf.Bytes()), nil
+	}
+	return raw, nil
+}
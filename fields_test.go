@@ -0,0 +1,71 @@
+package verto
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type fieldsAuthor struct {
+	Name  string `json:"name"`
+	Email string `json:"email"`
+}
+
+type fieldsPost struct {
+	Id     int          `json:"id"`
+	Title  string       `json:"title"`
+	Body   string       `json:"body"`
+	Author fieldsAuthor `json:"author"`
+}
+
+func TestFilterFields(t *testing.T) {
+	defer func() {
+		err := recover()
+		if err != nil {
+			t.Errorf(err.(error).Error())
+		}
+	}()
+
+	err := "Failed filter fields."
+
+	post := fieldsPost{1, "Title", "Body", fieldsAuthor{"a", "a@test.com"}}
+
+	v := New()
+	v.Logger = &NilLogger{}
+	v.ResponseHandler = FilterFields(ResponseFunc(JSONResponseFunc))
+	v.Get("/post", func(c *Context) (interface{}, error) {
+		return post, nil
+	})
+	v.Get("/posts", func(c *Context) (interface{}, error) {
+		return NewPagedResponse([]fieldsPost{post, post}, 2, c.Page(10, 10)), nil
+	})
+	v.Get("/restricted", func(c *Context) (interface{}, error) {
+		return post, nil
+	}).Fields("id", "author")
+	h := &HttpHandler{v}
+
+	serve := func(path string) *httptest.ResponseRecorder {
+		r, _ := http.NewRequest("GET", "http://test.com"+path, nil)
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		return w
+	}
+
+	expected := map[string]string{
+		"/post":                              `{"id":1,"title":"Title","body":"Body","author":{"name":"a","email":"a@test.com"}}`,
+		"/post?fields=title,id":              `{"id":1,"title":"Title"}`,
+		"/post?fields=id,author.name":        `{"id":1,"author":{"name":"a"}}`,
+		"/post?fields=author,author.name":    `{"author":{"name":"a","email":"a@test.com"}}`,
+		"/posts?fields=id":                   `{"items":[{"id":1},{"id":1}],"total":2,"page":1,"limit":10}`,
+		"/restricted?fields=id,author.email": `{"id":1,"author":{"email":"a@test.com"}}`,
+	}
+	for path, body := range expected {
+		w := serve(path)
+		if w.Code != 200 || w.Body.String() != body {
+			t.Errorf(err)
+		}
+	}
+	if serve("/restricted?fields=body").Code != 400 {
+		t.Errorf(err)
+	}
+}
@@ -0,0 +1,115 @@
+package verto
+
+import (
+	"github.com/boxtown/verto/static"
+	"io/fs"
+	"net/http"
+	"os"
+	"strings"
+)
+
+// File sends the file at name as the response. The Content-Type is
+// derived from the file extension or content and the Content-Length and
+// Last-Modified headers are set. Conditional and range requests are
+// honored. Errors are HTTPErrors with status 404 for missing files or
+// directories and 403 for unreadable files, whose messages don't reveal
+// the file name. ResourceFuncs can return a nil response after File
+// sent the file.
+//
+// Example usage:
+//
+//	v.Get("/reports/{id}", func(c *verto.Context) (interface{}, error) {
+//		return nil, c.File(filepath.Join(reportDir, c.Param("id")+".pdf"))
+//	})
+func (c *Context) File(name string) error {
+	f, err := os.Open(name)
+	if err != nil {
+		return fileStatusError(err)
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return fileStatusError(err)
+	}
+	if info.IsDir() {
+		return &HTTPError{Status: http.StatusNotFound}
+	}
+	http.ServeContent(c.Response, c.Request, info.Name(), info.ModTime(), f)
+	return nil
+}
+
+// File registers a GET endpoint at path sending the file at name,
+// e.g. for /favicon.ico or /robots.txt. Missing files respond with
+// 404. HEAD requests are answered if AutoHead is enabled. Directories
+// of files are served with Static.
+//
+// Example usage:
+//
+//	v.File("/favicon.ico", "./public/favicon.ico")
+func (v *Verto) File(path, name string) *Endpoint {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		c := v.context(w, r)
+		err := c.File(name)
+		if err != nil && ErrorStatus(err) == http.StatusNotFound {
+			v.table().NotFound.ServeHTTP(w, r)
+		} else if err != nil {
+			v.errorHandler(c).Handle(err, c)
+		}
+	})
+	return v.AddHandler("GET", path, handler)
+}
+
+// Static serves the files in dir under prefix with GET requests using
+// a static.Handler with its defaults. Request paths are confined to dir.
+//
+// Example usage:
+//
+//	v.Static("/assets", "./public")
+func (v *Verto) Static(prefix, dir string) *Endpoint {
+	return v.StaticHandler(prefix, static.New(dir))
+}
+
+// StaticFS serves the files in fsys under prefix with GET requests
+// like Static. Assets can be embedded in the binary with an embed.FS
+//
+// Example usage:
+//
+//	//go:embed public
+//	var public embed.FS
+//
+//	sub, _ := fs.Sub(public, "public")
+//	v.StaticFS("/", sub)
+func (v *Verto) StaticFS(prefix string, fsys fs.FS) *Endpoint {
+	return v.StaticHandler(prefix, static.NewFS(fsys))
+}
+
+// StaticHandler serves the files of h under prefix with GET requests,
+// built on a catch-all route under prefix, and returns the catch-all
+// Endpoint. HEAD requests are answered if AutoHead is enabled.
+//
+// Example usage:
+//
+//	h := static.New("./public")
+//	h.Listing = true
+//	v.StaticHandler("/files", h)
+func (v *Verto) StaticHandler(prefix string, h *static.Handler) *Endpoint {
+	prefix = strings.TrimRight(prefix, "/")
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		h.ServeFile(w, r, strings.TrimPrefix(r.URL.Path, prefix))
+	})
+	if prefix != "" {
+		v.GetHandler(prefix, handler)
+	}
+	return v.GetHandler(prefix+"/^", handler)
+}
+
+// fileStatusError returns the HTTPError for a file system error
+func fileStatusError(err error) error {
+	switch {
+	case os.IsNotExist(err):
+		return &HTTPError{Status: http.StatusNotFound, Err: err}
+	case os.IsPermission(err):
+		return &HTTPError{Status: http.StatusForbidden, Err: err}
+	}
+	return err
+}
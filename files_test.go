@@ -0,0 +1,165 @@
+package verto
+
+import (
+	"github.com/boxtown/verto/static"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestFile(t *testing.T) {
+	defer func() {
+		err := recover()
+		if err != nil {
+			t.Errorf(err.(error).Error())
+		}
+	}()
+
+	err := "Failed file."
+
+	dir, _ := ioutil.TempDir("", "file")
+	defer os.RemoveAll(dir)
+	icon := filepath.Join(dir, "favicon.ico")
+	report := filepath.Join(dir, "report.txt")
+	ioutil.WriteFile(icon, []byte("icon"), 0644)
+	ioutil.WriteFile(report, []byte("report"), 0644)
+
+	v := New()
+	v.Logger = &NilLogger{}
+	v.SetAutoHead(true)
+	v.File("/favicon.ico", icon)
+	v.File("/missing.ico", filepath.Join(dir, "missing.ico"))
+	v.Get("/reports/{name}", func(c *Context) (interface{}, error) {
+		return nil, c.File(filepath.Join(dir, c.Get("name")+".txt"))
+	})
+	h := &HttpHandler{v}
+
+	serve := func(method, path string, header ...string) *httptest.ResponseRecorder {
+		r, _ := http.NewRequest(method, "http://test.com"+path, nil)
+		for i := 0; i+1 < len(header); i += 2 {
+			r.Header.Set(header[i], header[i+1])
+		}
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		return w
+	}
+
+	w := serve("GET", "/favicon.ico")
+	if w.Code != 200 || w.Body.String() != "icon" || w.Header().Get("Content-Length") != "4" ||
+		w.Header().Get("Content-Type") == "" || w.Header().Get("Last-Modified") == "" {
+		t.Errorf(err)
+	}
+	if serve("GET", "/favicon.ico", "If-Modified-Since", w.Header().Get("Last-Modified")).Code != 304 {
+		t.Errorf(err)
+	}
+	if w = serve("HEAD", "/favicon.ico"); w.Code != 200 || w.Body.Len() != 0 {
+		t.Errorf(err)
+	}
+	if serve("GET", "/missing.ico").Code != 404 {
+		t.Errorf(err)
+	}
+
+	// Test ResourceFuncs
+	w = serve("GET", "/reports/report")
+	if w.Code != 200 || w.Body.String() != "report" || w.Header().Get("Content-Type") != "text/plain; charset=utf-8" {
+		t.Errorf(err)
+	}
+	if w = serve("GET", "/reports/other"); w.Code != 404 || strings.Contains(w.Body.String(), dir) {
+		t.Errorf(err)
+	}
+}
+
+func TestStatic(t *testing.T) {
+	defer func() {
+		err := recover()
+		if err != nil {
+			t.Errorf(err.(error).Error())
+		}
+	}()
+
+	err := "Failed static."
+
+	fsys := fstest.MapFS{
+		"app.js":            {Data: []byte("js")},
+		"docs/index.html":   {Data: []byte("docs")},
+		"files/a.txt":       {Data: []byte("a")},
+		"files/.secret":     {Data: []byte("secret")},
+		".git/config":       {Data: []byte("git")},
+		"plain/readme.txt":  {Data: []byte("readme")},
+		"deep/nested/x.txt": {Data: []byte("x")},
+	}
+
+	v := New()
+	v.Logger = &NilLogger{}
+	v.StaticFS("/assets", fsys)
+	browse := static.NewFS(fsys)
+	browse.Listing = true
+	v.StaticHandler("/browse", browse)
+	v.Get("/api", func(c *Context) (interface{}, error) {
+		return "api", nil
+	})
+	h := &HttpHandler{v}
+
+	serve := func(path string) *httptest.ResponseRecorder {
+		r, _ := http.NewRequest("GET", "http://test.com"+path, nil)
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		return w
+	}
+
+	if w := serve("/assets/app.js"); w.Code != 200 || w.Body.String() != "js" || w.Header().Get("ETag") == "" {
+		t.Errorf(err)
+	}
+	if w := serve("/assets/deep/nested/x.txt"); w.Code != 200 || w.Body.String() != "x" {
+		t.Errorf(err)
+	}
+
+	// Test index files and directory redirects
+	if w := serve("/assets/docs/"); w.Code != 200 || w.Body.String() != "docs" {
+		t.Errorf(err)
+	}
+	if w := serve("/assets/docs?v=1"); w.Code != 301 || w.Header().Get("Location") != "docs/?v=1" {
+		t.Errorf(err)
+	}
+
+	// Test traversal and dotfile protection
+	if w := serve("/assets/../api"); w.Code == 200 && w.Body.String() == "api" {
+		t.Errorf(err)
+	}
+	if serve("/assets/%2e%2e/%2e%2e/etc/passwd").Code == 200 {
+		t.Errorf(err)
+	}
+	dir, _ := ioutil.TempDir("", "static")
+	defer os.RemoveAll(dir)
+	os.Mkdir(filepath.Join(dir, "public"), 0755)
+	ioutil.WriteFile(filepath.Join(dir, "outside.txt"), []byte("outside"), 0644)
+	r, _ := http.NewRequest("GET", "http://test.com/outside.txt", nil)
+	w := httptest.NewRecorder()
+	static.New(filepath.Join(dir, "public")).ServeFile(w, r, "/../outside.txt")
+	if w.Code != 404 {
+		t.Errorf(err)
+	}
+	if serve("/assets/.git/config").Code != 404 || serve("/assets/files/.secret").Code != 404 {
+		t.Errorf(err)
+	}
+
+	// Test listings
+	if serve("/assets/plain/").Code != 404 {
+		t.Errorf(err)
+	}
+	w = serve("/browse/files/")
+	if w.Code != 200 || !strings.Contains(w.Body.String(), `<a href="a.txt">a.txt</a>`) || strings.Contains(w.Body.String(), "secret") {
+		t.Errorf(err)
+	}
+	if w = serve("/browse"); w.Code != 301 || w.Header().Get("Location") != "browse/" {
+		t.Errorf(err)
+	}
+	if serve("/assets/missing.js").Code != 404 || serve("/api").Body.String() != "api" {
+		t.Errorf(err)
+	}
+}
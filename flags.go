@@ -0,0 +1,59 @@
+package verto
+
+import (
+	"github.com/boxtown/verto/mux"
+	"net/http"
+)
+
+// FlagKey is the route metadata key under which
+// the feature flag gating a route is stored
+const FlagKey = "verto.flag"
+
+// FlagProvider reports whether feature flags are enabled.
+// Implementations are provided by the flags package
+type FlagProvider interface {
+	// Enabled returns whether the flag name is enabled.
+	// Unknown flags are disabled
+	Enabled(name string) bool
+}
+
+// Flag gates the route represented by the Endpoint behind the feature
+// flag name. Requests receive a 404 response while the flag is disabled
+//
+// Example usage:
+//
+//	v.Flags = flags.Static{"new-checkout": true}
+//	v.Post("/checkout/v2", checkout).Flag("new-checkout")
+func (ep *Endpoint) Flag(name string) *Endpoint {
+	return ep.FlagRedirect(name, "")
+}
+
+// FlagRedirect gates the route represented by the Endpoint behind the
+// feature flag name. Requests are redirected to location while the flag
+// is disabled. If location is empty, requests receive a 404 response
+func (ep *Endpoint) FlagRedirect(name, location string) *Endpoint {
+	v := ep.v
+	handler := func(w http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+		if v.flagEnabled(name) {
+			next(w, r)
+			return
+		}
+		if location != "" {
+			http.Redirect(w, r, location, http.StatusFound)
+			return
+		}
+		v.table().NotFound.ServeHTTP(w, r)
+	}
+	return ep.Meta(FlagKey, name).UsePluginHandler(mux.PluginFunc(handler))
+}
+
+// FlagEnabled returns whether the feature flag name is enabled
+// by Verto's FlagProvider. All flags are disabled if Verto
+// has no FlagProvider
+func (c *Context) FlagEnabled(name string) bool {
+	return c.v != nil && c.v.flagEnabled(name)
+}
+
+func (v *Verto) flagEnabled(name string) bool {
+	return v.Flags != nil && v.Flags.Enabled(name)
+}
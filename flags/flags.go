@@ -0,0 +1,85 @@
+// Package flags provides feature flag providers for Verto. Providers
+// implement verto.FlagProvider and are set as the Verto instance's
+// Flags to gate routes through Endpoint.Flag and to branch inside
+// handlers through Context.FlagEnabled.
+package flags
+
+import (
+	"github.com/boxtown/verto"
+	"os"
+	"strings"
+	"sync"
+)
+
+// Static is a FlagProvider backed by a fixed map of flags
+type Static map[string]bool
+
+// Enabled returns whether name is enabled in the map
+func (s Static) Enabled(name string) bool {
+	return s[name]
+}
+
+// Env is a FlagProvider reading flags from environment variables. The
+// variable for a flag is its name upper-cased with dashes and dots
+// replaced by underscores, prefixed with Prefix (e.g. FLAG_NEW_CHECKOUT
+// for new-checkout). Flags are enabled by the values 1, true, on and yes
+type Env struct {
+	// Prefix is prepended to variable names. Defaults to FLAG_
+	Prefix string
+}
+
+// Enabled returns whether the environment variable for name enables it
+func (e Env) Enabled(name string) bool {
+	prefix := e.Prefix
+	if prefix == "" {
+		prefix = "FLAG_"
+	}
+	key := prefix + strings.ToUpper(strings.NewReplacer("-", "_", ".", "_").Replace(name))
+	switch strings.ToLower(strings.TrimSpace(os.Getenv(key))) {
+	case "1", "true", "on", "yes":
+		return true
+	}
+	return false
+}
+
+// Overrides is a FlagProvider whose flags can be changed at runtime
+// and that falls back to another provider for flags it does not set
+type Overrides struct {
+	// Fallback optionally provides flags without overrides
+	Fallback verto.FlagProvider
+
+	flags map[string]bool
+	mutex sync.RWMutex
+}
+
+// Set overrides the flag name with enabled
+func (o *Overrides) Set(name string, enabled bool) {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+
+	if o.flags == nil {
+		o.flags = make(map[string]bool)
+	}
+	o.flags[name] = enabled
+}
+
+// Unset removes the override of the flag name
+func (o *Overrides) Unset(name string) {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+
+	delete(o.flags, name)
+}
+
+// Enabled returns the override of name if set and
+// otherwise asks the fallback provider
+func (o *Overrides) Enabled(name string) bool {
+	o.mutex.RLock()
+	enabled, ok := o.flags[name]
+	o.mutex.RUnlock()
+
+	if ok {
+		return enabled
+	}
+	return o.Fallback != nil && o.Fallback.Enabled(name)
+}
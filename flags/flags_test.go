@@ -0,0 +1,116 @@
+package flags
+
+import (
+	"fmt"
+	"github.com/boxtown/verto"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+)
+
+func TestProviders(t *testing.T) {
+	defer func() {
+		err := recover()
+		if err != nil {
+			t.Errorf(err.(error).Error())
+		}
+	}()
+
+	err := "Failed providers."
+
+	if !(Static{"a": true}).Enabled("a") || (Static{"a": true}).Enabled("b") {
+		t.Errorf(err)
+	}
+
+	os.Setenv("FLAG_NEW_CHECKOUT", "on")
+	defer os.Unsetenv("FLAG_NEW_CHECKOUT")
+	if !(Env{}).Enabled("new-checkout") || (Env{Prefix: "X_"}).Enabled("new-checkout") {
+		t.Errorf(err)
+	}
+
+	o := &Overrides{Fallback: Static{"a": true}}
+	o.Set("a", false)
+	o.Set("b", true)
+	if o.Enabled("a") || !o.Enabled("b") {
+		t.Errorf(err)
+	}
+	o.Unset("a")
+	if !o.Enabled("a") {
+		t.Errorf(err)
+	}
+
+	fail := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if fail {
+			w.WriteHeader(500)
+			return
+		}
+		fmt.Fprint(w, `{"remote": true, "off": false}`)
+	}))
+	defer server.Close()
+
+	remote := NewRemote(server.URL)
+	if remote.Enabled("remote") {
+		t.Errorf(err)
+	}
+	if e := remote.Refresh(); e != nil || !remote.Enabled("remote") || remote.Enabled("off") {
+		t.Errorf(err)
+	}
+	fail = true
+	if remote.Refresh() == nil || !remote.Enabled("remote") {
+		t.Errorf(err)
+	}
+	remote.Start()
+	remote.Stop()
+}
+
+func TestFlag(t *testing.T) {
+	defer func() {
+		err := recover()
+		if err != nil {
+			t.Errorf(err.(error).Error())
+		}
+	}()
+
+	err := "Failed flag."
+
+	o := &Overrides{}
+	v := verto.New()
+	v.Logger = &verto.NilLogger{}
+	v.Flags = o
+	v.Get("/checkout", func(c *verto.Context) (interface{}, error) {
+		if c.FlagEnabled("fast") {
+			return "fast", nil
+		}
+		return "slow", nil
+	}).Flag("new-checkout")
+	v.Get("/beta", func(c *verto.Context) (interface{}, error) {
+		return "beta", nil
+	}).FlagRedirect("beta", "/stable")
+	h := &verto.HttpHandler{v}
+
+	serve := func(path string) *httptest.ResponseRecorder {
+		r, _ := http.NewRequest("GET", "http://test.com"+path, nil)
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		return w
+	}
+
+	if serve("/checkout").Code != 404 {
+		t.Errorf(err)
+	}
+	if w := serve("/beta"); w.Code != 302 || w.Header().Get("Location") != "/stable" {
+		t.Errorf(err)
+	}
+
+	o.Set("new-checkout", true)
+	o.Set("beta", true)
+	if w := serve("/checkout"); w.Code != 200 || w.Body.String() != "slow" {
+		t.Errorf(err)
+	}
+	o.Set("fast", true)
+	if serve("/checkout").Body.String() != "fast" || serve("/beta").Body.String() != "beta" {
+		t.Errorf(err)
+	}
+}
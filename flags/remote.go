@@ -0,0 +1,128 @@
+package flags
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Remote is a FlagProvider polling flags from a remote HTTP endpoint.
+// The endpoint must respond with a JSON object mapping flag names to
+// booleans. The last successfully fetched flags are kept if a refresh
+// fails so that flag service outages do not flip flags.
+//
+// Example usage:
+//
+//	r := flags.NewRemote("https://flags.internal/api/flags")
+//	if err := r.Refresh(); err != nil {
+//		log.Println(err)
+//	}
+//	r.Start()
+//	defer r.Stop()
+//	v.Flags = r
+type Remote struct {
+	// URL is the endpoint serving the flags
+	URL string
+
+	// Interval is the polling interval. Defaults to 30 seconds
+	Interval time.Duration
+
+	// Client is the http.Client used for polling.
+	// Defaults to a client with a 10 second timeout
+	Client *http.Client
+
+	// OnError is an optional callback for failed refreshes
+	OnError func(err error)
+
+	flags map[string]bool
+	stop  chan struct{}
+	mutex sync.RWMutex
+}
+
+// NewRemote returns a Remote provider polling url
+func NewRemote(url string) *Remote {
+	return &Remote{URL: url, flags: make(map[string]bool)}
+}
+
+// Enabled returns whether name was enabled at the last refresh
+func (r *Remote) Enabled(name string) bool {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	return r.flags[name]
+}
+
+// Refresh fetches the flags from the remote endpoint
+func (r *Remote) Refresh() error {
+	resp, err := r.client().Get(r.URL)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("flags: %s responded with %d", r.URL, resp.StatusCode)
+	}
+	flags := make(map[string]bool)
+	if err := json.NewDecoder(resp.Body).Decode(&flags); err != nil {
+		return err
+	}
+
+	r.mutex.Lock()
+	r.flags = flags
+	r.mutex.Unlock()
+	return nil
+}
+
+// Start starts polling the remote endpoint in the background.
+// Calling Start on a started provider does nothing
+func (r *Remote) Start() {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if r.stop != nil {
+		return
+	}
+	r.stop = make(chan struct{})
+	go r.poll(r.stop)
+}
+
+// Stop stops polling
+func (r *Remote) Stop() {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if r.stop != nil {
+		close(r.stop)
+		r.stop = nil
+	}
+}
+
+func (r *Remote) poll(stop chan struct{}) {
+	interval := r.Interval
+	if interval <= 0 {
+		interval = 30 * time.Second
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			if err := r.Refresh(); err != nil && r.OnError != nil {
+				r.OnError(err)
+			}
+		}
+	}
+}
+
+func (r *Remote) client() *http.Client {
+	if r.Client == nil {
+		return &http.Client{Timeout: 10 * time.Second}
+	}
+	return r.Client
+}
@@ -0,0 +1,360 @@
+// Package form decodes URL-encoded and multipart form values onto Go
+// structs, covering nested structs, slices and maps that encoding/json
+// handles for JSON bodies but HTML forms can't express directly. Field
+// names use brackets or dots for nesting:
+//
+//	user[name]=a&user[address][city]=b    nested structs
+//	tags[]=a&tags[]=b                     slices
+//	items[0][name]=a&items[1][name]=b     slices of structs
+//	attrs[color]=red                      maps
+//
+// Struct fields are matched by their form tag or, case-insensitively,
+// by their name. Fields tagged form:"-" are skipped.
+package form
+
+import (
+	"encoding"
+	"fmt"
+	"mime/multipart"
+	"net/url"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// MaxIndex is the largest slice index accepted in field names
+var MaxIndex = 1000
+
+// Error is returned for values that can't be decoded onto their field
+type Error struct {
+	// Field is the form name of the field
+	Field string
+
+	// Err describes the failure
+	Err error
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("form: %s: %s", e.Field, e.Err.Error())
+}
+
+// node is a level of nested form fields
+type node struct {
+	name     string
+	values   []string
+	files    []*multipart.FileHeader
+	children map[string]*node
+}
+
+func newNode(name string) *node {
+	return &node{name: name, children: make(map[string]*node)}
+}
+
+// child returns the child node for key, creating it if necessary
+func (n *node) child(key string) *node {
+	c, ok := n.children[key]
+	if !ok {
+		name := key
+		if n.name != "" {
+			name = n.name + "[" + key + "]"
+		}
+		c = newNode(name)
+		n.children[key] = c
+	}
+	return c
+}
+
+// Decode decodes values onto dst, which must be a pointer to a struct
+func Decode(values url.Values, dst interface{}) error {
+	return decodeTree(buildTree(values, nil), dst)
+}
+
+// DecodeMultipart decodes the values and files of a multipart form onto
+// dst, which must be a pointer to a struct. Files are decoded onto fields
+// of type *multipart.FileHeader or []*multipart.FileHeader
+func DecodeMultipart(mf *multipart.Form, dst interface{}) error {
+	return decodeTree(buildTree(mf.Value, mf.File), dst)
+}
+
+func decodeTree(root *node, dst interface{}) error {
+	v := reflect.ValueOf(dst)
+	if v.Kind() != reflect.Ptr || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("form: destination must be a non-nil pointer to a struct")
+	}
+	return decode(root, v.Elem())
+}
+
+// buildTree builds the tree of nested fields from form values and files
+func buildTree(values url.Values, files map[string][]*multipart.FileHeader) *node {
+	root := newNode("")
+	for name, vs := range values {
+		n := root
+		for _, key := range splitName(name) {
+			n = n.child(key)
+		}
+		n.values = append(n.values, vs...)
+	}
+	for name, fs := range files {
+		n := root
+		for _, key := range splitName(name) {
+			n = n.child(key)
+		}
+		n.files = append(n.files, fs...)
+	}
+	return root
+}
+
+// splitName splits a field name such as user[address][city]
+// or user.address.city into its keys. Empty brackets yield an
+// empty key
+func splitName(name string) []string {
+	keys := make([]string, 0, 4)
+	i := strings.IndexAny(name, "[.")
+	if i < 0 {
+		return []string{name}
+	}
+	keys = append(keys, name[:i])
+	rest := name[i:]
+	for len(rest) > 0 {
+		switch rest[0] {
+		case '[':
+			end := strings.IndexByte(rest, ']')
+			if end < 0 {
+				return append(keys, rest[1:])
+			}
+			keys = append(keys, rest[1:end])
+			rest = rest[end+1:]
+		case '.':
+			rest = rest[1:]
+			end := strings.IndexAny(rest, "[.")
+			if end < 0 {
+				end = len(rest)
+			}
+			keys = append(keys, rest[:end])
+			rest = rest[end:]
+		default:
+			// Trailing garbage after a bracket is treated as a key
+			return append(keys, rest)
+		}
+	}
+	return keys
+}
+
+var (
+	timeType       = reflect.TypeOf(time.Time{})
+	fileHeaderType = reflect.TypeOf(&multipart.FileHeader{})
+	unmarshaler    = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+// decode decodes n onto v
+func decode(n *node, v reflect.Value) error {
+	if v.Type() == fileHeaderType {
+		if len(n.files) > 0 {
+			v.Set(reflect.ValueOf(n.files[0]))
+		}
+		return nil
+	}
+	if v.Kind() == reflect.Slice && v.Type().Elem() == fileHeaderType {
+		if len(n.files) > 0 {
+			v.Set(reflect.ValueOf(n.files))
+		}
+		return nil
+	}
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		return decode(n, v.Elem())
+	}
+	if v.CanAddr() && v.Type() != timeType && v.Addr().Type().Implements(unmarshaler) {
+		if len(n.values) == 0 {
+			return nil
+		}
+		if err := v.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(n.values[0])); err != nil {
+			return &Error{n.name, err}
+		}
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.Struct:
+		if v.Type() == timeType {
+			return decodeScalar(n, v)
+		}
+		return decodeStruct(n, v)
+	case reflect.Slice:
+		return decodeSlice(n, v)
+	case reflect.Map:
+		return decodeMap(n, v)
+	}
+	return decodeScalar(n, v)
+}
+
+// decodeStruct decodes the children of n onto the fields of v
+func decodeStruct(n *node, v reflect.Value) error {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.PkgPath != "" && !f.Anonymous {
+			continue
+		}
+		tag := strings.Split(f.Tag.Get("form"), ",")[0]
+		if tag == "-" {
+			continue
+		}
+
+		// Embedded structs without a tag are flattened
+		if f.Anonymous && tag == "" && f.Type.Kind() == reflect.Struct {
+			if err := decodeStruct(n, v.Field(i)); err != nil {
+				return err
+			}
+			continue
+		}
+
+		var c *node
+		if tag != "" {
+			c = n.children[tag]
+		} else {
+			for key, child := range n.children {
+				if strings.EqualFold(key, f.Name) {
+					c = child
+					break
+				}
+			}
+		}
+		if c == nil {
+			continue
+		}
+		if err := decode(c, v.Field(i)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// decodeSlice decodes n onto the slice v. Indexed children are
+// decoded in index order, other values are decoded as elements
+func decodeSlice(n *node, v reflect.Value) error {
+	values := n.values
+	if empty, ok := n.children[""]; ok {
+		values = append(values, empty.values...)
+	}
+
+	indexes := make([]int, 0, len(n.children))
+	for key := range n.children {
+		if key == "" {
+			continue
+		}
+		i, err := strconv.Atoi(key)
+		if err != nil || i < 0 || i > MaxIndex {
+			return &Error{n.name, fmt.Errorf("invalid index %q", key)}
+		}
+		indexes = append(indexes, i)
+	}
+	sort.Ints(indexes)
+
+	s := reflect.MakeSlice(v.Type(), 0, len(values)+len(indexes))
+	for _, value := range values {
+		e := reflect.New(v.Type().Elem()).Elem()
+		if err := decode(&node{name: n.name + "[]", values: []string{value}}, e); err != nil {
+			return err
+		}
+		s = reflect.Append(s, e)
+	}
+	for _, i := range indexes {
+		e := reflect.New(v.Type().Elem()).Elem()
+		if err := decode(n.children[strconv.Itoa(i)], e); err != nil {
+			return err
+		}
+		s = reflect.Append(s, e)
+	}
+	v.Set(s)
+	return nil
+}
+
+// decodeMap decodes the children of n onto the map v
+func decodeMap(n *node, v reflect.Value) error {
+	if v.Type().Key().Kind() != reflect.String {
+		return &Error{n.name, fmt.Errorf("unsupported map key type %s", v.Type().Key())}
+	}
+	if v.IsNil() {
+		v.Set(reflect.MakeMap(v.Type()))
+	}
+	for key, c := range n.children {
+		e := reflect.New(v.Type().Elem()).Elem()
+		if err := decode(c, e); err != nil {
+			return err
+		}
+		v.SetMapIndex(reflect.ValueOf(key).Convert(v.Type().Key()), e)
+	}
+	return nil
+}
+
+// decodeScalar decodes the first value of n onto v
+func decodeScalar(n *node, v reflect.Value) error {
+	if len(n.values) == 0 {
+		return nil
+	}
+	s := n.values[0]
+
+	if v.Type() == timeType {
+		if s == "" {
+			return nil
+		}
+		for _, layout := range []string{time.RFC3339, "2006-01-02T15:04", "2006-01-02"} {
+			if t, err := time.Parse(layout, s); err == nil {
+				v.Set(reflect.ValueOf(t))
+				return nil
+			}
+		}
+		return &Error{n.name, fmt.Errorf("invalid time %q", s)}
+	}
+
+	var err error
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(s)
+	case reflect.Bool:
+		// Checked checkboxes without a value attribute send 'on'
+		if s == "on" || s == "" {
+			v.SetBool(s == "on")
+			return nil
+		}
+		var b bool
+		b, err = strconv.ParseBool(s)
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if s == "" {
+			return nil
+		}
+		var i int64
+		i, err = strconv.ParseInt(s, 10, v.Type().Bits())
+		v.SetInt(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if s == "" {
+			return nil
+		}
+		var u uint64
+		u, err = strconv.ParseUint(s, 10, v.Type().Bits())
+		v.SetUint(u)
+	case reflect.Float32, reflect.Float64:
+		if s == "" {
+			return nil
+		}
+		var f float64
+		f, err = strconv.ParseFloat(s, v.Type().Bits())
+		v.SetFloat(f)
+	case reflect.Interface:
+		if v.NumMethod() == 0 {
+			v.Set(reflect.ValueOf(s))
+		}
+	default:
+		err = fmt.Errorf("unsupported type %s", v.Type())
+	}
+	if err != nil {
+		return &Error{n.name, err}
+	}
+	return nil
+}
@@ -0,0 +1,149 @@
+package form
+
+import (
+	"bytes"
+	"mime/multipart"
+	"net/url"
+	"testing"
+	"time"
+)
+
+type testAddress struct {
+	City string `form:"city"`
+	Zip  int    `form:"zip"`
+}
+
+type testItem struct {
+	Name  string  `form:"name"`
+	Price float64 `form:"price"`
+}
+
+type testMeta struct {
+	Source string
+}
+
+type testUser struct {
+	testMeta
+	Name     string            `form:"name"`
+	Admin    bool              `form:"admin"`
+	Born     time.Time         `form:"born"`
+	Address  *testAddress      `form:"address"`
+	Tags     []string          `form:"tags"`
+	Items    []testItem        `form:"items"`
+	Attrs    map[string]string `form:"attrs"`
+	Ignored  string            `form:"-"`
+	Nickname string
+	Avatar   *multipart.FileHeader `form:"avatar"`
+}
+
+func TestDecode(t *testing.T) {
+	defer func() {
+		err := recover()
+		if err != nil {
+			t.Errorf(err.(error).Error())
+		}
+	}()
+
+	err := "Failed decode."
+
+	values, _ := url.ParseQuery("name=a&admin=on&born=2017-01-02" +
+		"&address[city]=b&address.zip=123&tags[]=x&tags[]=y" +
+		"&items[1][name]=second&items[0][name]=first&items[0][price]=1.5" +
+		"&attrs[color]=red&Ignored=z&nickname=n&source=web")
+
+	u := &testUser{}
+	if e := Decode(values, u); e != nil {
+		t.Fatalf(e.Error())
+	}
+	if u.Name != "a" || !u.Admin || u.Born.Day() != 2 || u.Nickname != "n" || u.Source != "web" || u.Ignored != "" {
+		t.Errorf(err)
+	}
+	if u.Address == nil || u.Address.City != "b" || u.Address.Zip != 123 {
+		t.Errorf(err)
+	}
+	if len(u.Tags) != 2 || u.Tags[0] != "x" || u.Tags[1] != "y" {
+		t.Errorf(err)
+	}
+	if len(u.Items) != 2 || u.Items[0].Name != "first" || u.Items[0].Price != 1.5 || u.Items[1].Name != "second" {
+		t.Errorf(err)
+	}
+	if u.Attrs["color"] != "red" {
+		t.Errorf(err)
+	}
+
+	// Test errors name the field
+	values, _ = url.ParseQuery("address[zip]=abc")
+	e := Decode(values, &testUser{})
+	if fe, ok := e.(*Error); !ok || fe.Field != "address[zip]" {
+		t.Errorf(err)
+	}
+	values, _ = url.ParseQuery("items[99999][name]=a")
+	if e = Decode(values, &testUser{}); e == nil {
+		t.Errorf(err)
+	}
+	if e = Decode(url.Values{}, testUser{}); e == nil {
+		t.Errorf(err)
+	}
+}
+
+func TestDecodeMultipart(t *testing.T) {
+	defer func() {
+		err := recover()
+		if err != nil {
+			t.Errorf(err.(error).Error())
+		}
+	}()
+
+	err := "Failed decode multipart."
+
+	buf := &bytes.Buffer{}
+	mw := multipart.NewWriter(buf)
+	mw.WriteField("name", "a")
+	fw, _ := mw.CreateFormFile("avatar", "avatar.png")
+	fw.Write([]byte("png"))
+	mw.Close()
+
+	mf, e := multipart.NewReader(buf, mw.Boundary()).ReadForm(1 << 20)
+	if e != nil {
+		t.Fatalf(e.Error())
+	}
+	u := &testUser{}
+	if e := DecodeMultipart(mf, u); e != nil {
+		t.Fatalf(e.Error())
+	}
+	if u.Name != "a" || u.Avatar == nil || u.Avatar.Filename != "avatar.png" {
+		t.Errorf(err)
+	}
+}
+
+func TestSplitName(t *testing.T) {
+	defer func() {
+		err := recover()
+		if err != nil {
+			t.Errorf(err.(error).Error())
+		}
+	}()
+
+	err := "Failed split name."
+
+	expected := map[string][]string{
+		"name":           {"name"},
+		"a[b][c]":        {"a", "b", "c"},
+		"a.b.c":          {"a", "b", "c"},
+		"a[0].b":         {"a", "0", "b"},
+		"tags[]":         {"tags", ""},
+		"a[unterminated": {"a", "unterminated"},
+	}
+	for name, keys := range expected {
+		actual := splitName(name)
+		if len(actual) != len(keys) {
+			t.Errorf(err)
+			continue
+		}
+		for i := range keys {
+			if actual[i] != keys[i] {
+				t.Errorf(err)
+			}
+		}
+	}
+}
@@ -0,0 +1,61 @@
+package verto
+
+import (
+	"github.com/boxtown/verto/form"
+	"mime"
+	"mime/multipart"
+	"net/http"
+)
+
+// MaxFormMemory is the number of bytes of multipart forms
+// kept in memory by Context.DecodeForm. Larger files are
+// stored in temporary files
+var MaxFormMemory int64 = 32 << 20
+
+// DecodeForm decodes the request's form onto dst, which must be a pointer
+// to a struct. Query, route and URL-encoded or multipart body parameters
+// are decoded, with nested fields and slices named as described in package
+// form. Uploaded files are decoded onto *multipart.FileHeader fields. Errors
+// are HTTPErrors with status 400 for malformed forms and 422 for values
+// that don't fit their fields.
+//
+// Example usage:
+//
+//	type Signup struct {
+//		Name    string   `form:"name"`
+//		Tags    []string `form:"tags"`
+//		Address struct {
+//			City string `form:"city"`
+//		} `form:"address"`
+//	}
+//
+//	var s Signup
+//	if err := c.DecodeForm(&s); err != nil {
+//		return nil, err
+//	}
+func (c *Context) DecodeForm(dst interface{}) error {
+	r := c.Request
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+
+	var err error
+	if mediaType == "multipart/form-data" {
+		err = r.ParseMultipartForm(MaxFormMemory)
+	} else {
+		err = r.ParseForm()
+	}
+	if err != nil {
+		return &HTTPError{Status: http.StatusBadRequest, Message: err.Error(), Err: err}
+	}
+
+	mf := &multipart.Form{Value: r.Form}
+	if r.MultipartForm != nil {
+		mf.File = r.MultipartForm.File
+	}
+	if err := form.DecodeMultipart(mf, dst); err != nil {
+		if _, ok := err.(*form.Error); ok {
+			return &HTTPError{Status: http.StatusUnprocessableEntity, Message: err.Error(), Err: err}
+		}
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,56 @@
+package verto
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestDecodeForm(t *testing.T) {
+	defer func() {
+		err := recover()
+		if err != nil {
+			t.Errorf(err.(error).Error())
+		}
+	}()
+
+	err := "Failed decode form."
+
+	type signup struct {
+		Id      string   `form:"id"`
+		Name    string   `form:"name"`
+		Tags    []string `form:"tags"`
+		Address struct {
+			City string `form:"city"`
+			Zip  int    `form:"zip"`
+		} `form:"address"`
+	}
+
+	v := New()
+	v.Logger = &NilLogger{}
+	v.Post("/signup/{id}", func(c *Context) (interface{}, error) {
+		var s signup
+		if err := c.DecodeForm(&s); err != nil {
+			return nil, err
+		}
+		return s.Id + ":" + s.Name + ":" + strings.Join(s.Tags, ",") + ":" + s.Address.City, nil
+	})
+	h := &HttpHandler{v}
+
+	serve := func(body string) *httptest.ResponseRecorder {
+		r, _ := http.NewRequest("POST", "http://test.com/signup/7?tags[]=q", strings.NewReader(body))
+		r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		return w
+	}
+
+	w := serve("name=a&tags[]=x&address[city]=b")
+	if w.Code != 200 || w.Body.String() != "7:a:x,q:b" {
+		t.Errorf(err)
+	}
+	if serve("address[zip]=abc").Code != 422 {
+		t.Errorf(err)
+	}
+}
@@ -0,0 +1,206 @@
+// Package headers provides hardened HTTP header handling for Verto and its
+// plugins: parsing of list-valued headers and quality values, normalization
+// of duplicate headers and a plugin enforcing header count and size limits.
+package headers
+
+import (
+	"errors"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ErrConflictingHeader is returned by Normalize if a header that
+// may only appear once is present multiple times with differing values
+var ErrConflictingHeader = errors.New("headers: conflicting duplicate header")
+
+// singletons lists headers that may not be combined into a
+// comma-separated list. Differing duplicates of these headers
+// are a common request smuggling vector
+var singletons = map[string]bool{
+	"Authorization":       true,
+	"Content-Length":      true,
+	"Content-Type":        true,
+	"Host":                true,
+	"If-Modified-Since":   true,
+	"If-Unmodified-Since": true,
+	"Proxy-Authorization": true,
+	"Range":               true,
+	"Referer":             true,
+	"User-Agent":          true,
+}
+
+// unmergeable lists headers whose values may contain unquoted
+// commas and therefore must not be merged
+var unmergeable = map[string]bool{
+	"Cookie":     true,
+	"Set-Cookie": true,
+}
+
+// Quality is an element of a list-valued header with a quality
+// value such as Accept or Accept-Encoding
+type Quality struct {
+	// Value is the element without parameters (e.g. text/html or gzip)
+	Value string
+
+	// Q is the quality value of the element. Defaults to 1
+	Q float64
+
+	// Params holds any parameters other than q
+	Params map[string]string
+}
+
+// ParseList returns the elements of the list-valued header name across
+// all of its occurrences in h. Elements are trimmed, empty elements are
+// dropped and commas within quoted strings are respected
+func ParseList(h http.Header, name string) []string {
+	list := make([]string, 0)
+	for _, v := range h[http.CanonicalHeaderKey(name)] {
+		for _, e := range split(v, ',') {
+			if e = strings.TrimSpace(e); e != "" {
+				list = append(list, e)
+			}
+		}
+	}
+	return list
+}
+
+// ParseQuality returns the elements of the list-valued header name in h
+// ordered by descending quality value. Elements of equal quality retain
+// their order. Elements with malformed quality values are dropped
+func ParseQuality(h http.Header, name string) []Quality {
+	qs := make([]Quality, 0)
+	for _, e := range ParseList(h, name) {
+		parts := split(e, ';')
+		q := Quality{Value: strings.TrimSpace(parts[0]), Q: 1}
+		valid := true
+		for _, p := range parts[1:] {
+			kv := strings.SplitN(strings.TrimSpace(p), "=", 2)
+			key := strings.ToLower(strings.TrimSpace(kv[0]))
+			value := ""
+			if len(kv) == 2 {
+				value = strings.Trim(strings.TrimSpace(kv[1]), `"`)
+			}
+			if key == "q" {
+				f, err := strconv.ParseFloat(value, 64)
+				if err != nil || f < 0 || f > 1 {
+					valid = false
+					break
+				}
+				q.Q = f
+				continue
+			}
+			if q.Params == nil {
+				q.Params = make(map[string]string)
+			}
+			q.Params[key] = value
+		}
+		if valid && q.Value != "" {
+			qs = append(qs, q)
+		}
+	}
+	sort.SliceStable(qs, func(i, j int) bool { return qs[i].Q > qs[j].Q })
+	return qs
+}
+
+// Accepts returns whether the header name in h accepts value with a
+// non-zero quality, either explicitly or through the wildcard '*'.
+// Values are compared case-insensitively
+func Accepts(h http.Header, name, value string) bool {
+	return quality(ParseQuality(h, name), value) > 0
+}
+
+// Preferred returns the offer with the highest quality in the header
+// name in h. Ties are resolved in favor of earlier offers. Returns the
+// empty string if no offer is acceptable
+func Preferred(h http.Header, name string, offers ...string) string {
+	qs := ParseQuality(h, name)
+	best, bestQ := "", 0.0
+	for _, offer := range offers {
+		if q := quality(qs, offer); q > bestQ {
+			best, bestQ = offer, q
+		}
+	}
+	return best
+}
+
+// Normalize canonicalizes the keys of h, merges the values of duplicate
+// list-valued headers into a single comma-separated value and collapses
+// identical duplicates of headers that may only appear once. Returns
+// ErrConflictingHeader if such a header has differing values
+func Normalize(h http.Header) error {
+	for key, values := range h {
+		canonical := http.CanonicalHeaderKey(key)
+		if canonical != key {
+			delete(h, key)
+			h[canonical] = append(h[canonical], values...)
+		}
+	}
+	for key, values := range h {
+		if len(values) < 2 || unmergeable[key] {
+			continue
+		}
+		if singletons[key] {
+			for _, v := range values[1:] {
+				if v != values[0] {
+					return ErrConflictingHeader
+				}
+			}
+			h[key] = values[:1]
+			continue
+		}
+		h[key] = []string{strings.Join(values, ", ")}
+	}
+	return nil
+}
+
+// Size returns the approximate wire size of h in bytes
+func Size(h http.Header) int {
+	size := 0
+	for key, values := range h {
+		for _, v := range values {
+			// Account for ": " and "\r\n"
+			size += len(key) + len(v) + 4
+		}
+	}
+	return size
+}
+
+// quality returns the quality of value in qs. Explicit
+// matches take precedence over the wildcard
+func quality(qs []Quality, value string) float64 {
+	wildcard := 0.0
+	for _, q := range qs {
+		if strings.EqualFold(q.Value, value) {
+			return q.Q
+		}
+		if q.Value == "*" && wildcard == 0 {
+			wildcard = q.Q
+		}
+	}
+	return wildcard
+}
+
+// split splits s at sep outside of quoted strings
+func split(s string, sep byte) []string {
+	parts := make([]string, 0, 1)
+	quoted := false
+	start := 0
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			if quoted {
+				i++
+			}
+		case '"':
+			quoted = !quoted
+		case sep:
+			if !quoted {
+				parts = append(parts, s[start:i])
+				start = i + 1
+			}
+		}
+	}
+	return append(parts, s[start:])
+}
@@ -0,0 +1,162 @@
+package headers
+
+import (
+	"github.com/boxtown/verto"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseList(t *testing.T) {
+	defer func() {
+		err := recover()
+		if err != nil {
+			t.Errorf(err.(error).Error())
+		}
+	}()
+
+	err := "Failed parse list."
+
+	h := http.Header{}
+	h.Add("X-List", "a, b,,")
+	h.Add("X-List", ` c ,"d, e"`)
+	if !reflect.DeepEqual(ParseList(h, "x-list"), []string{"a", "b", "c", `"d, e"`}) {
+		t.Errorf(err)
+	}
+	if len(ParseList(h, "X-Missing")) != 0 {
+		t.Errorf(err)
+	}
+}
+
+func TestParseQuality(t *testing.T) {
+	defer func() {
+		err := recover()
+		if err != nil {
+			t.Errorf(err.(error).Error())
+		}
+	}()
+
+	err := "Failed parse quality."
+
+	h := http.Header{}
+	h.Set("Accept", `text/html;level=1;q=0.5, application/json, text/*;q=bad, */*;q=0.1, text/plain;q=0.5`)
+	qs := ParseQuality(h, "Accept")
+	if len(qs) != 4 {
+		t.Fatalf(err)
+	}
+	if qs[0].Value != "application/json" || qs[0].Q != 1 {
+		t.Errorf(err)
+	}
+	if qs[1].Value != "text/html" || qs[1].Params["level"] != "1" || qs[2].Value != "text/plain" {
+		t.Errorf(err)
+	}
+	if qs[3].Value != "*/*" || qs[3].Q != 0.1 {
+		t.Errorf(err)
+	}
+}
+
+func TestPreferred(t *testing.T) {
+	defer func() {
+		err := recover()
+		if err != nil {
+			t.Errorf(err.(error).Error())
+		}
+	}()
+
+	err := "Failed preferred."
+
+	h := http.Header{}
+	h.Set("Accept-Encoding", "deflate;q=0.5, GZIP;q=0.8")
+	if Preferred(h, "Accept-Encoding", "gzip", "deflate") != "gzip" {
+		t.Errorf(err)
+	}
+	h.Set("Accept-Encoding", "*;q=0.2, gzip;q=0")
+	if Preferred(h, "Accept-Encoding", "gzip", "deflate") != "deflate" || Accepts(h, "Accept-Encoding", "gzip") {
+		t.Errorf(err)
+	}
+	h.Set("Accept-Encoding", "identity")
+	if Preferred(h, "Accept-Encoding", "gzip", "deflate") != "" {
+		t.Errorf(err)
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	defer func() {
+		err := recover()
+		if err != nil {
+			t.Errorf(err.(error).Error())
+		}
+	}()
+
+	err := "Failed normalize."
+
+	h := http.Header{
+		"x-forwarded-for": {"1.1.1.1"},
+		"X-Forwarded-For": {"2.2.2.2"},
+		"Content-Type":    {"text/plain", "text/plain"},
+		"Cookie":          {"a=1", "b=2"},
+	}
+	if Normalize(h) != nil {
+		t.Fatalf(err)
+	}
+	if len(h["X-Forwarded-For"]) != 1 || len(h["x-forwarded-for"]) != 0 {
+		t.Errorf(err)
+	}
+	if v := h.Get("X-Forwarded-For"); v != "2.2.2.2, 1.1.1.1" && v != "1.1.1.1, 2.2.2.2" {
+		t.Errorf(err)
+	}
+	if !reflect.DeepEqual(h["Content-Type"], []string{"text/plain"}) || len(h["Cookie"]) != 2 {
+		t.Errorf(err)
+	}
+
+	h = http.Header{"Content-Length": {"5", "10"}}
+	if Normalize(h) != ErrConflictingHeader {
+		t.Errorf(err)
+	}
+}
+
+func TestLimiter(t *testing.T) {
+	defer func() {
+		err := recover()
+		if err != nil {
+			t.Errorf(err.(error).Error())
+		}
+	}()
+
+	err := "Failed limiter."
+
+	v := verto.New()
+	v.Logger = &verto.NilLogger{}
+	v.Use(NewLimiter(3, 100))
+	v.Get("/", func(c *verto.Context) (interface{}, error) {
+		return c.Request.Header.Get("X-A"), nil
+	})
+	h := &verto.HttpHandler{v}
+
+	serve := func(header http.Header) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest("GET", "http://test.com/", nil)
+		r.Header = header
+		h.ServeHTTP(w, r)
+		return w
+	}
+
+	w := serve(http.Header{"X-A": {"1", "2"}})
+	if w.Code != http.StatusOK || w.Body.String() != "1, 2" {
+		t.Errorf(err)
+	}
+	w = serve(http.Header{"X-A": {"1", "2"}, "X-B": {"3", "4"}})
+	if w.Code != http.StatusRequestHeaderFieldsTooLarge {
+		t.Errorf(err)
+	}
+	w = serve(http.Header{"X-A": {strings.Repeat("a", 100)}})
+	if w.Code != http.StatusRequestHeaderFieldsTooLarge {
+		t.Errorf(err)
+	}
+	w = serve(http.Header{"Authorization": {"a", "b"}})
+	if w.Code != http.StatusBadRequest {
+		t.Errorf(err)
+	}
+}
@@ -0,0 +1,68 @@
+package headers
+
+import (
+	"github.com/boxtown/verto"
+	"github.com/boxtown/verto/plugins"
+	"net/http"
+)
+
+// Limiter is a plugin that enforces limits on the number and size of
+// request headers and normalizes duplicate headers. Requests exceeding
+// the limits receive a 431 response and requests with conflicting
+// duplicate headers receive a 400 response.
+//
+// Example usage:
+//
+//	v.Use(headers.NewLimiter(100, 16<<10))
+type Limiter struct {
+	// Core is the core functionality for plugins
+	plugins.Core
+
+	// MaxCount is the maximum number of header values.
+	// Zero means no limit
+	MaxCount int
+
+	// MaxBytes is the maximum total size of the headers.
+	// Zero means no limit
+	MaxBytes int
+}
+
+// NewLimiter returns a Limiter allowing at most maxCount header values
+// with a total size of at most maxBytes
+func NewLimiter(maxCount, maxBytes int) *Limiter {
+	return &Limiter{
+		Core:     plugins.Core{Id: "plugins.HeaderLimiter"},
+		MaxCount: maxCount,
+		MaxBytes: maxBytes,
+	}
+}
+
+// Handle is called per request to enforce the header limits
+func (plugin *Limiter) Handle(c *verto.Context, next http.HandlerFunc) {
+	plugin.Core.Handle(
+		func(c *verto.Context, next http.HandlerFunc) {
+			h := c.Request.Header
+
+			count := 0
+			for _, values := range h {
+				count += len(values)
+			}
+			if (plugin.MaxCount > 0 && count > plugin.MaxCount) ||
+				(plugin.MaxBytes > 0 && Size(h) > plugin.MaxBytes) {
+
+				reject(c, http.StatusRequestHeaderFieldsTooLarge)
+				return
+			}
+			if err := Normalize(h); err != nil {
+				reject(c, http.StatusBadRequest)
+				return
+			}
+			next(c.Response, c.Request)
+		}, c, next)
+}
+
+// reject writes an error response with status
+func reject(c *verto.Context, status int) {
+	c.Response.WriteHeader(status)
+	c.Response.Write([]byte(http.StatusText(status) + "."))
+}
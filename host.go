@@ -0,0 +1,112 @@
+package verto
+
+import (
+	"github.com/boxtown/verto/mux"
+	"net/http"
+)
+
+// Host is a scope of routes served only for requests to hosts
+// matching a pattern. Requests to other hosts are served by
+// Verto's routes. Host routes share Verto's settings, handlers
+// and global plugins.
+//
+// Example usage:
+//
+//	api := v.Host("api.example.com")
+//	api.Get("/users", listUsers)
+//	v.Host("www.example.com").Get("/", home)
+//
+//	tenants := v.Host("{tenant}.example.com")
+//	tenants.Get("/", func(c *verto.Context) (interface{}, error) {
+//		return c.Param("tenant"), nil
+//	})
+type Host struct {
+	pattern string
+	v       *Verto
+}
+
+// Host returns the scope of routes served for hosts matching pattern.
+// Labels of pattern in braces match any single label of a host and are
+// captured as parameters (e.g. "{tenant}.example.com"). Parameters can be
+// refined by regexes like path parameters. Exact hosts take precedence
+// over patterns with parameters. Ports are ignored when matching
+func (v *Verto) Host(pattern string) *Host {
+	v.table().Host(pattern)
+	return &Host{pattern, v}
+}
+
+// Pattern returns the host pattern of the Host
+func (h *Host) Pattern() string {
+	return h.pattern
+}
+
+// Add registers a ResourceFunc for the method+path combination
+// under the Host and returns an Endpoint representing the route
+func (h *Host) Add(method, path string, rf ResourceFunc) *Endpoint {
+	handler := h.v.resource(rf)
+	return h.v.endpoint(h.muxer().Add(method, path, h.v.serve(handler)), handler)
+}
+
+// AddHandler registers an http.Handler for the method+path combination
+// under the Host and returns an Endpoint representing the route
+func (h *Host) AddHandler(method, path string, handler http.Handler) *Endpoint {
+	return h.v.endpoint(h.muxer().Add(method, path, h.v.serve(handler)), handler)
+}
+
+// Get is a wrapper function around Add() that sets the method
+// as GET
+func (h *Host) Get(path string, rf ResourceFunc) *Endpoint {
+	return h.Add("GET", path, rf)
+}
+
+// Put is a wrapper function around Add() that sets the method
+// as PUT
+func (h *Host) Put(path string, rf ResourceFunc) *Endpoint {
+	return h.Add("PUT", path, rf)
+}
+
+// Post is a wrapper function around Add() that sets the method
+// as POST
+func (h *Host) Post(path string, rf ResourceFunc) *Endpoint {
+	return h.Add("POST", path, rf)
+}
+
+// Delete is a wrapper function around Add() that sets the method
+// as DELETE
+func (h *Host) Delete(path string, rf ResourceFunc) *Endpoint {
+	return h.Add("DELETE", path, rf)
+}
+
+// Patch is a wrapper function around Add() that sets the method
+// as PATCH
+func (h *Host) Patch(path string, rf ResourceFunc) *Endpoint {
+	return h.Add("PATCH", path, rf)
+}
+
+// Group returns the Group at the method+path combination under the Host
+func (h *Host) Group(method, path string) *Group {
+	return &Group{h.muxer().Group(method, path), h.v}
+}
+
+// Use adds a Plugin to be executed for all routes under the Host
+// after Verto's global plugins
+func (h *Host) Use(plugin Plugin) *Host {
+	pluginFunc := func(w http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+		c := h.v.context(w, r)
+		plugin.Handle(c, next)
+	}
+	h.v.auditPlugin("host "+h.pattern, plugin)
+	h.muxer().Use(mux.PluginFunc(pluginFunc))
+	return h
+}
+
+// Routes returns every route registered under the Host
+// sorted by path and then method
+func (h *Host) Routes() []mux.Route {
+	return h.muxer().Routes()
+}
+
+// muxer returns the muxer of the Host in Verto's current route table
+func (h *Host) muxer() *mux.PathMuxer {
+	return h.v.table().Host(h.pattern)
+}
@@ -0,0 +1,82 @@
+package verto
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestHost(t *testing.T) {
+	defer func() {
+		err := recover()
+		if err != nil {
+			t.Errorf(err.(error).Error())
+		}
+	}()
+
+	err := "Failed host."
+
+	v := New()
+	v.Logger = &NilLogger{}
+	v.UseHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Global", "1")
+	}))
+	respond := func(s string) ResourceFunc {
+		return func(c *Context) (interface{}, error) {
+			return s, nil
+		}
+	}
+	v.Get("/", respond("default"))
+	api := v.Host("api.example.com")
+	api.Get("/", respond("api"))
+	api.Group("GET", "/users").Add("/{id}", func(c *Context) (interface{}, error) {
+		return "user " + c.Param("id"), nil
+	}).Name("user")
+	v.Host("www.example.com").Get("/", respond("www"))
+	v.Host("{tenant: ^[a-z]+$}.example.com").Get("/", func(c *Context) (interface{}, error) {
+		return "tenant " + c.Param("tenant"), nil
+	})
+	h := &HttpHandler{v}
+
+	serve := func(host, path string) *httptest.ResponseRecorder {
+		r, _ := http.NewRequest("GET", "http://"+host+path, nil)
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		return w
+	}
+
+	// Test hosts are routed to their scopes and
+	// wildcard subdomains are captured
+	expected := [][]string{
+		{"api.example.com", "/", "api"},
+		{"API.example.com:8080", "/", "api"},
+		{"api.example.com", "/users/1", "user 1"},
+		{"www.example.com", "/", "www"},
+		{"acme.example.com", "/", "tenant acme"},
+		{"other.com", "/", "default"},
+		{"a.b.example.com", "/", "default"},
+		{"acme42.example.com", "/", "default"},
+	}
+	for _, e := range expected {
+		w := serve(e[0], e[1])
+		if w.Code != 200 || w.Body.String() != e[2] || w.Header().Get("X-Global") != "1" {
+			t.Errorf(err)
+		}
+	}
+
+	// Test unmatched paths under a host use Verto's handlers
+	if w := serve("api.example.com", "/missing"); w.Code != 404 || w.Body.String() != "Not Found." {
+		t.Errorf(err)
+	}
+	if len(api.Routes()) != 2 || len(v.Routes()) != 5 {
+		t.Errorf(err)
+	}
+
+	// Test host routes are named and listed with their hosts
+	if url, e := v.URL("user", "id", "7"); e != nil || url != "/users/7" {
+		t.Errorf(err)
+	}
+	if routes := v.Routes(); routes[0].Host() != "" || routes[1].Host() != "api.example.com" {
+		t.Errorf(err)
+	}
+}
@@ -0,0 +1,108 @@
+package verto
+
+import (
+	"encoding/xml"
+	"net/http"
+	"strings"
+)
+
+// Link is a hypermedia link to a related resource
+type Link struct {
+	// Rel is the relation type of the link (e.g. self, next, parent)
+	Rel string `json:"rel" xml:"rel,attr"`
+
+	// Href is the fully-qualified URL of the related resource
+	Href string `json:"href" xml:"href,attr"`
+}
+
+// relation is a link relation declared by route name
+// and resolved when the response is rendered
+type relation struct {
+	rel    string
+	route  string
+	params []string
+}
+
+// Resource is a response envelope carrying hypermedia links. Relations
+// are declared by route name and rendered as fully-qualified links
+// using the request's scheme and host by JSONResponseFunc and
+// XMLResponseFunc.
+//
+// Example usage:
+//
+//	v.Get("/users/{id}", func(c *verto.Context) (interface{}, error) {
+//		user := store.Get(c.Param("id"))
+//		return verto.NewResource(user).
+//			Rel("self", "user.show", "id", user.Id).
+//			Rel("parent", "user.list"), nil
+//	}).Name("user.show")
+type Resource struct {
+	XMLName xml.Name `json:"-" xml:"resource"`
+
+	// Data is the resource representation
+	Data interface{} `json:"data" xml:"data"`
+
+	// Links are the resolved links of the resource
+	Links []Link `json:"links" xml:"link"`
+
+	relations []relation
+}
+
+// NewResource returns a Resource wrapping data
+func NewResource(data interface{}) *Resource {
+	return &Resource{Data: data, Links: make([]Link, 0)}
+}
+
+// Rel declares a relation rel to the route named route built with
+// params. If route is empty, the relation links to the request URL.
+// Returns the Resource for chaining
+func (res *Resource) Rel(rel, route string, params ...string) *Resource {
+	res.relations = append(res.relations, relation{rel, route, params})
+	return res
+}
+
+// resolve resolves the declared relations of the resource into
+// links. Relations whose routes cannot be built are skipped and
+// logged
+func (res *Resource) resolve(c *Context) {
+	if len(res.relations) == 0 || c.Request == nil {
+		return
+	}
+	base := RequestBaseURL(c.Request)
+	for _, rel := range res.relations {
+		path := c.Request.URL.RequestURI()
+		if rel.route != "" {
+			if c.v == nil {
+				continue
+			}
+			var err error
+			path, err = c.v.URL(rel.route, rel.params...)
+			if err != nil {
+				if c.Logger != nil {
+					c.Logger.Warnf("hypermedia: could not resolve %s link: %s", rel.rel, err.Error())
+				}
+				continue
+			}
+		}
+		res.Links = append(res.Links, Link{Rel: rel.rel, Href: base + path})
+	}
+	res.relations = nil
+}
+
+// RequestBaseURL returns the scheme and host of the request as a
+// URL without a trailing slash (e.g. https://example.com). The
+// X-Forwarded-Proto header is recognized.
+func RequestBaseURL(r *http.Request) string {
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+	if proto := r.Header.Get("X-Forwarded-Proto"); proto != "" {
+		scheme = strings.ToLower(strings.TrimSpace(strings.Split(proto, ",")[0]))
+	}
+	host := r.Host
+	if host == "" {
+		host = r.URL.Host
+	}
+	return scheme + "://" + host
+}
@@ -0,0 +1,83 @@
+package verto
+
+import (
+	"crypto/tls"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestResource(t *testing.T) {
+	defer func() {
+		err := recover()
+		if err != nil {
+			t.Errorf(err.(error).Error())
+		}
+	}()
+
+	err := "Failed resource."
+
+	v := New()
+	v.Logger = &NilLogger{}
+	v.ResponseHandler = ResponseFunc(JSONResponseFunc)
+	v.Get("/users", func(c *Context) (interface{}, error) {
+		return nil, nil
+	}).Name("user.list")
+	v.Get("/users/{id}", func(c *Context) (interface{}, error) {
+		return NewResource(c.Get("id")).
+			Rel("self", "").
+			Rel("parent", "user.list").
+			Rel("next", "user.show", "id", "43").
+			Rel("broken", "missing"), nil
+	}).Name("user.show")
+	h := &HttpHandler{v}
+
+	r, _ := http.NewRequest("GET", "http://test.com/users/42", nil)
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	expected := `{"data":"42","links":[` +
+		`{"rel":"self","href":"http://test.com/users/42"},` +
+		`{"rel":"parent","href":"http://test.com/users"},` +
+		`{"rel":"next","href":"http://test.com/users/43"}]}`
+	if w.Body.String() != expected {
+		t.Errorf(err)
+	}
+
+	// Test XML
+	v.ResponseHandler = ResponseFunc(XMLResponseFunc)
+	r, _ = http.NewRequest("GET", "http://test.com/users/42", nil)
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	expected = `<resource><data>42</data>` +
+		`<link rel="self" href="http://test.com/users/42"></link>` +
+		`<link rel="parent" href="http://test.com/users"></link>` +
+		`<link rel="next" href="http://test.com/users/43"></link></resource>`
+	if w.Body.String() != expected {
+		t.Errorf(err)
+	}
+}
+
+func TestRequestBaseURL(t *testing.T) {
+	defer func() {
+		err := recover()
+		if err != nil {
+			t.Errorf(err.(error).Error())
+		}
+	}()
+
+	err := "Failed request base URL."
+
+	r, _ := http.NewRequest("GET", "http://test.com/a", nil)
+	if RequestBaseURL(r) != "http://test.com" {
+		t.Errorf(err)
+	}
+	r.TLS = &tls.ConnectionState{}
+	if RequestBaseURL(r) != "https://test.com" {
+		t.Errorf(err)
+	}
+	r.TLS = nil
+	r.Header.Set("X-Forwarded-Proto", "HTTPS, http")
+	if RequestBaseURL(r) != "https://test.com" {
+		t.Errorf(err)
+	}
+}
@@ -0,0 +1,157 @@
+package verto
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// ErrPluginMissing is returned by InitPlugins if a plugin requires
+// a plugin that is not registered
+var ErrPluginMissing = errors.New("verto: required plugin not registered")
+
+// ErrPluginCycle is returned by InitPlugins if plugins
+// require each other
+var ErrPluginCycle = errors.New("verto: plugin requirements form a cycle")
+
+// PluginInitializer is implemented by plugins that need to be
+// initialized with the Verto instance before requests are served,
+// e.g. to register injections or routes of their own
+type PluginInitializer interface {
+	Init(v *Verto) error
+}
+
+// PluginIdentifier is implemented by plugins that
+// other plugins can require
+type PluginIdentifier interface {
+	// PluginId returns the Id plugins are required by
+	PluginId() string
+}
+
+// PluginDependent is implemented by plugins that require other plugins.
+// Required plugins must be registered with the same Verto instance and
+// are initialized first
+type PluginDependent interface {
+	// Requires returns the Ids of the plugins required
+	Requires() []string
+}
+
+// pluginRegistry tracks the plugins registered with
+// Verto that take part in initialization
+type pluginRegistry struct {
+	plugins     []interface{}
+	initialized map[int]bool
+	mutex       sync.Mutex
+}
+
+// add tracks plugin if it takes part in initialization.
+// Plugins registered multiple times are tracked once
+func (reg *pluginRegistry) add(plugin interface{}) {
+	switch plugin.(type) {
+	case PluginInitializer, PluginIdentifier, PluginDependent:
+	default:
+		return
+	}
+
+	reg.mutex.Lock()
+	defer reg.mutex.Unlock()
+
+	if reflect.TypeOf(plugin).Comparable() {
+		for _, p := range reg.plugins {
+			if p == plugin {
+				return
+			}
+		}
+	}
+	reg.plugins = append(reg.plugins, plugin)
+}
+
+// InitPlugins checks the requirements of the plugins registered with
+// Verto and initializes them in dependency order. Each plugin is
+// initialized once; plugins registered later, e.g. with staged route
+// tables, are initialized by calling InitPlugins again. InitPlugins is
+// called by RunOn, which panics with the returned error so that missing
+// requirements fail at startup rather than on the first request.
+//
+// Example usage:
+//
+//	v.Use(metrics.New())
+//	v.Use(exporter) // exporter.Requires() returns []string{"plugins.Metrics"}
+//	if err := v.InitPlugins(); err != nil {
+//		log.Fatal(err)
+//	}
+func (v *Verto) InitPlugins() error {
+	reg := v.plugins
+	reg.mutex.Lock()
+	plugins := append([]interface{}(nil), reg.plugins...)
+	reg.mutex.Unlock()
+
+	ids := make(map[string][]int)
+	for i, p := range plugins {
+		if p, ok := p.(PluginIdentifier); ok {
+			ids[p.PluginId()] = append(ids[p.PluginId()], i)
+		}
+	}
+	for _, p := range plugins {
+		if p, ok := p.(PluginDependent); ok {
+			for _, id := range p.Requires() {
+				if len(ids[id]) == 0 {
+					return fmt.Errorf("%w: %s requires %s", ErrPluginMissing, pluginName(p), id)
+				}
+			}
+		}
+	}
+
+	// Initialize plugins depth first so that required
+	// plugins are initialized before their dependents
+	visiting := make(map[int]bool)
+	var visit func(i int) error
+	visit = func(i int) error {
+		reg.mutex.Lock()
+		done := reg.initialized[i]
+		reg.mutex.Unlock()
+		if done {
+			return nil
+		}
+		if visiting[i] {
+			return fmt.Errorf("%w: %s", ErrPluginCycle, pluginName(plugins[i]))
+		}
+		visiting[i] = true
+		if p, ok := plugins[i].(PluginDependent); ok {
+			for _, id := range p.Requires() {
+				for _, j := range ids[id] {
+					if err := visit(j); err != nil {
+						return err
+					}
+				}
+			}
+		}
+		if p, ok := plugins[i].(PluginInitializer); ok {
+			if err := p.Init(v); err != nil {
+				return fmt.Errorf("verto: initializing %s: %w", pluginName(p), err)
+			}
+		}
+		visiting[i] = false
+
+		reg.mutex.Lock()
+		reg.initialized[i] = true
+		reg.mutex.Unlock()
+		return nil
+	}
+	for i := range plugins {
+		if err := visit(i); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// pluginName returns the Id of plugin or its type
+// if it has none
+func pluginName(plugin interface{}) string {
+	if p, ok := plugin.(PluginIdentifier); ok {
+		return p.PluginId()
+	}
+	return fmt.Sprintf("%T", plugin)
+}
@@ -0,0 +1,82 @@
+package verto
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+)
+
+// initPlugin is a plugin recording its initialization
+type initPlugin struct {
+	id       string
+	requires []string
+	inits    *[]string
+	err      error
+}
+
+func (p *initPlugin) Handle(c *Context, next http.HandlerFunc) {
+	next(c.Response, c.Request)
+}
+
+func (p *initPlugin) Init(v *Verto) error {
+	*p.inits = append(*p.inits, p.id)
+	return p.err
+}
+
+func (p *initPlugin) PluginId() string {
+	return p.id
+}
+
+func (p *initPlugin) Requires() []string {
+	return p.requires
+}
+
+func TestInitPlugins(t *testing.T) {
+	defer func() {
+		err := recover()
+		if err != nil {
+			t.Errorf(err.(error).Error())
+		}
+	}()
+
+	err := "Failed init plugins."
+
+	inits := make([]string, 0)
+	v := New()
+	v.Logger = &NilLogger{}
+	exporter := &initPlugin{id: "exporter", requires: []string{"metrics"}, inits: &inits}
+	metrics := &initPlugin{id: "metrics", requires: []string{"recorder"}, inits: &inits}
+	recorder := &initPlugin{id: "recorder", inits: &inits}
+	v.Use(exporter).Use(metrics)
+	v.Group("GET", "/api").Use(recorder).Use(metrics)
+
+	// Test required plugins are initialized first and once
+	if e := v.InitPlugins(); e != nil {
+		t.Fatalf(err)
+	}
+	if len(inits) != 3 || inits[0] != "recorder" || inits[1] != "metrics" || inits[2] != "exporter" {
+		t.Errorf(err)
+	}
+	if v.InitPlugins() != nil || len(inits) != 3 {
+		t.Errorf(err)
+	}
+
+	// Test missing requirements and cycles fail
+	v = New()
+	v.Use(&initPlugin{id: "a", requires: []string{"b"}, inits: &inits})
+	if e := v.InitPlugins(); !errors.Is(e, ErrPluginMissing) {
+		t.Errorf(err)
+	}
+	v.Use(&initPlugin{id: "b", requires: []string{"a"}, inits: &inits})
+	if e := v.InitPlugins(); !errors.Is(e, ErrPluginCycle) {
+		t.Errorf(err)
+	}
+
+	// Test initialization errors are returned
+	v = New()
+	failure := errors.New("failure")
+	v.Use(&initPlugin{id: "a", inits: &inits, err: failure})
+	if e := v.InitPlugins(); !errors.Is(e, failure) {
+		t.Errorf(err)
+	}
+}
@@ -75,13 +75,20 @@ type ReadOnlyInjections interface {
 type IContainer struct {
 	mutex *sync.RWMutex
 	data  map[string]*injectionDef
+
+	// used records the keys ever retrieved from
+	// the container or any of its clones
+	usedMutex *sync.Mutex
+	used      map[string]bool
 }
 
 // NewContainer returns a pointer to a newly initiated Injections Container.
 func NewContainer() *IContainer {
 	return &IContainer{
-		mutex: &sync.RWMutex{},
-		data:  make(map[string]*injectionDef),
+		mutex:     &sync.RWMutex{},
+		data:      make(map[string]*injectionDef),
+		usedMutex: &sync.Mutex{},
+		used:      make(map[string]bool),
 	}
 }
 
@@ -93,6 +100,7 @@ func (i *IContainer) Clone(w http.ResponseWriter, r *http.Request) *IClone {
 		r:          r,
 		mutex:      &sync.RWMutex{},
 		threadData: make(map[string]interface{}),
+		touched:    make(map[string]bool),
 	}
 }
 
@@ -112,6 +120,7 @@ func (i *IContainer) Get(key string) interface{} {
 // Otherwise, the associated value and true is returned. This
 // function will evaluate lazy functions with a singleton LifeTime
 func (i *IContainer) TryGet(key string) (interface{}, bool) {
+	i.markUsed(key)
 	i.mutex.RLock()
 
 	v, ok := i.data[key]
@@ -212,6 +221,34 @@ func (i *IContainer) Clear() {
 	i.data = make(map[string]*injectionDef)
 }
 
+// Unused returns the sorted keys registered with the container
+// that have never been retrieved from it or any of its clones
+func (i *IContainer) Unused() []string {
+	i.mutex.RLock()
+	defer i.mutex.RUnlock()
+	i.usedMutex.Lock()
+	defer i.usedMutex.Unlock()
+
+	unused := make(map[string]bool)
+	for key := range i.data {
+		if !i.used[key] {
+			unused[key] = true
+		}
+	}
+	return sortedKeys(unused)
+}
+
+// markUsed records the retrieval of key
+func (i *IContainer) markUsed(key string) {
+	if i.usedMutex == nil {
+		return
+	}
+	i.usedMutex.Lock()
+	defer i.usedMutex.Unlock()
+
+	i.used[key] = true
+}
+
 // IClone is a cloned version of the IContainer
 // and should have a 1-1 relation with an http.Request.
 // IClone maintains a request-specific map for evaluating
@@ -223,6 +260,7 @@ type IClone struct {
 	r          *http.Request
 	mutex      *sync.RWMutex
 	threadData map[string]interface{}
+	touched    map[string]bool
 }
 
 // Get calls TryGet on the IClone and disregards the
@@ -240,6 +278,8 @@ func (i *IClone) Get(key string) interface{} {
 // function only once in its lifetime. The per-request scoping comes from
 // the IContainer spawning an IClone per incoming http.Request
 func (i *IClone) TryGet(key string) (interface{}, bool) {
+	i.touch(key)
+	i.markUsed(key)
 	i.IContainer.mutex.RLock()
 
 	v, ok := i.IContainer.data[key]
@@ -346,6 +386,47 @@ func (i *IClone) Clear() {
 	i.threadData = make(map[string]interface{})
 }
 
+// Touched returns the sorted keys retrieved through
+// Get or TryGet on this IClone
+func (i *IClone) Touched() []string {
+	if i.mutex == nil {
+		return nil
+	}
+	i.mutex.RLock()
+	defer i.mutex.RUnlock()
+
+	return sortedKeys(i.touched)
+}
+
+// touch records the retrieval of key
+func (i *IClone) touch(key string) {
+	if i.mutex == nil || i.touched == nil {
+		return
+	}
+	i.mutex.Lock()
+	defer i.mutex.Unlock()
+
+	i.touched[key] = true
+}
+
+// injectionsKey is the context key of the
+// IClone of a request
+type injectionsKey struct{}
+
+// RequestInjections returns the per-request Injections clone
+// carried by the context of r or nil if r was not served by
+// Verto. It allows plain http.Handlers and plugins to access
+// the same injections as the request's Context
+func RequestInjections(r *http.Request) Injections {
+	if r == nil {
+		return nil
+	}
+	if clone, ok := r.Context().Value(injectionsKey{}).(*IClone); ok {
+		return clone
+	}
+	return nil
+}
+
 // readOnlyInjections is an implementation of the ReadOnlyInjections
 // interface in order to provide factory functions with read access
 // to the outer container.
@@ -1,7 +1,11 @@
 package verto
 
 import (
+	"context"
+	"errors"
+	"github.com/boxtown/verto/mux"
 	"net/http"
+	"net/http/httptest"
 	"sync"
 	"testing"
 )
@@ -245,3 +249,69 @@ func TestIContainerClear(t *testing.T) {
 		t.Errorf(err)
 	}
 }
+
+func TestRequestInjections(t *testing.T) {
+	defer func() {
+		err := recover()
+		if err != nil {
+			t.Errorf(err.(error).Error())
+		}
+	}()
+
+	err := "Failed request injections."
+
+	v := New()
+	v.Logger = &NilLogger{}
+	v.Injections.Lazy("path", func(w http.ResponseWriter, r *http.Request, i ReadOnlyInjections) interface{} {
+		return r.URL.Path
+	}, REQUEST)
+
+	type swapKey struct{}
+	v.Get("/swapped", func(c *Context) (interface{}, error) {
+		if c.Request.Context().Value(swapKey{}) == nil {
+			return nil, errors.New("request not swapped")
+		}
+		return c.Injections().Get("path"), nil
+	}).UsePluginHandler(mux.PluginFunc(func(w http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+		// Plugins may swap the request for a copy
+		next(w, r.WithContext(context.WithValue(r.Context(), swapKey{}, true)))
+	}))
+	v.GetHandler("/plain", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(RequestInjections(r).Get("path").(string)))
+	}))
+	h := &HttpHandler{v}
+
+	for _, path := range []string{"/swapped", "/plain"} {
+		r, _ := http.NewRequest("GET", "http://test.com"+path, nil)
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		if w.Code != 200 || w.Body.String() != path {
+			t.Errorf(err)
+		}
+	}
+
+	r, _ := http.NewRequest("GET", "http://test.com/", nil)
+	if RequestInjections(r) != nil || RequestInjections(nil) != nil {
+		t.Errorf(err)
+	}
+}
+
+func BenchmarkRequestInjections(b *testing.B) {
+	v := New()
+	v.Logger = &NilLogger{}
+	v.Injections.Lazy("id", func(w http.ResponseWriter, r *http.Request, i ReadOnlyInjections) interface{} {
+		return r.URL.Path
+	}, REQUEST)
+	v.Get("/bench", func(c *Context) (interface{}, error) {
+		return c.Injections().Get("id"), nil
+	})
+	h := &HttpHandler{v}
+
+	b.ReportAllocs()
+	b.RunParallel(func(pb *testing.PB) {
+		r, _ := http.NewRequest("GET", "http://test.com/bench", nil)
+		for pb.Next() {
+			h.ServeHTTP(httptest.NewRecorder(), r)
+		}
+	})
+}
@@ -0,0 +1,131 @@
+package verto
+
+import (
+	"context"
+	"net/http"
+	"sync"
+)
+
+// LogField promotes a request-scoped value (e.g. a user id, tenant
+// or trace id) into the access log records and Diagnostics of every
+// request. Values are looked up in the request's context under
+// ContextKey or, if Injection is set, in the request's Injections
+type LogField struct {
+	// Name is the name of the field in records
+	Name string
+
+	// ContextKey is the request context key of the value
+	ContextKey interface{}
+
+	// Injection is the injection key of the value. Factories
+	// associated with the key are evaluated
+	Injection string
+}
+
+// ContextField returns a LogField promoting the
+// request context value of key as name
+func ContextField(name string, key interface{}) LogField {
+	return LogField{Name: name, ContextKey: key}
+}
+
+// InjectionField returns a LogField promoting the
+// injection of key as name
+func InjectionField(name, key string) LogField {
+	return LogField{Name: name, Injection: key}
+}
+
+// logStateKey is the context key of the logState of a request
+type logStateKey struct{}
+
+// logState is the state shared between logging plugins and
+// the handling of a request
+type logState struct {
+	suppressed int32
+
+	// fields are the promoted context values captured
+	// when the request reached its route, where context
+	// values attached by all plugins are visible
+	fields map[string]interface{}
+	mutex  sync.Mutex
+}
+
+// requestLogState returns the logState attached to r or nil
+func requestLogState(r *http.Request) *logState {
+	if r == nil {
+		return nil
+	}
+	state, _ := r.Context().Value(logStateKey{}).(*logState)
+	return state
+}
+
+// LogFields returns the values of Verto's LogFields for the request
+// of c by field name. Context values attached by plugins running
+// after the caller are included if a logging plugin attached its
+// state to the request with WithLogFlag.
+//
+// Example usage:
+//
+//	v.LogFields = []verto.LogField{
+//		verto.ContextField("trace_id", traceKey),
+//		verto.InjectionField("user_id", "userId"),
+//	}
+func (c *Context) LogFields() map[string]interface{} {
+	fields := make(map[string]interface{})
+	if c.v == nil {
+		return fields
+	}
+	state := requestLogState(c.Request)
+	for _, f := range c.v.root().LogFields {
+		var value interface{}
+		if f.Injection != "" {
+			if c.Injections != nil {
+				if injections := c.Injections(); injections != nil {
+					value, _ = injections.TryGet(f.Injection)
+				}
+			}
+		} else if f.ContextKey != nil {
+			if c.Request != nil {
+				value = c.Request.Context().Value(f.ContextKey)
+			}
+			if value == nil && state != nil {
+				state.mutex.Lock()
+				value = state.fields[f.Name]
+				state.mutex.Unlock()
+			}
+		}
+		if value != nil {
+			fields[f.Name] = value
+		}
+	}
+	return fields
+}
+
+// captureLogFields records the promoted context values of r
+// in the logState attached to r if there is one
+func (v *Verto) captureLogFields(r *http.Request) {
+	state := requestLogState(r)
+	fields := v.root().LogFields
+	if state == nil || len(fields) == 0 {
+		return
+	}
+
+	state.mutex.Lock()
+	defer state.mutex.Unlock()
+
+	for _, f := range fields {
+		if f.ContextKey == nil {
+			continue
+		}
+		if value := r.Context().Value(f.ContextKey); value != nil {
+			if state.fields == nil {
+				state.fields = make(map[string]interface{})
+			}
+			state.fields[f.Name] = value
+		}
+	}
+}
+
+// withLogState returns a copy of r carrying a new logState
+func withLogState(r *http.Request) *http.Request {
+	return r.WithContext(context.WithValue(r.Context(), logStateKey{}, &logState{}))
+}
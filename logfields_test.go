@@ -0,0 +1,62 @@
+package verto
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type testTraceKey struct{}
+
+func TestLogFields(t *testing.T) {
+	defer func() {
+		err := recover()
+		if err != nil {
+			t.Errorf(err.(error).Error())
+		}
+	}()
+
+	err := "Failed log fields."
+
+	v := New()
+	v.Logger = &NilLogger{}
+	v.LogFields = []LogField{
+		ContextField("trace_id", testTraceKey{}),
+		InjectionField("user_id", "userId"),
+		ContextField("missing", "missing"),
+	}
+
+	var logged map[string]interface{}
+	var diag *Diagnostic
+	v.Use(PluginFunc(func(c *Context, next http.HandlerFunc) {
+		r := WithLogFlag(c.Request)
+		next(c.Response, r)
+		c.Request = r
+		logged = c.LogFields()
+	}))
+	v.Use(PluginFunc(func(c *Context, next http.HandlerFunc) {
+		c.Injections().Set("userId", "u1")
+		next(c.Response, c.Request.WithContext(context.WithValue(c.Request.Context(), testTraceKey{}, "t1")))
+	}))
+	v.Get("/fail", func(c *Context) (interface{}, error) {
+		return nil, errors.New("fail")
+	})
+	v.ErrorHandler = ErrorFunc(func(e error, c *Context) {
+		diag = c.Diagnostic()
+		c.Response.WriteHeader(500)
+	})
+	h := &HttpHandler{v}
+
+	r, _ := http.NewRequest("GET", "http://test.com/fail", nil)
+	h.ServeHTTP(httptest.NewRecorder(), r)
+
+	// Test outer plugins see values attached by inner plugins
+	if len(logged) != 2 || logged["trace_id"] != "t1" || logged["user_id"] != "u1" {
+		t.Errorf(err)
+	}
+	if diag == nil || diag.Fields["trace_id"] != "t1" || diag.Fields["user_id"] != "u1" {
+		t.Errorf(err)
+	}
+}
@@ -32,6 +32,61 @@ type Logger interface {
 	Close()
 }
 
+// Level is a logging level. Loggers with a level
+// discard messages below their level
+type Level int
+
+// Logging levels in increasing order of severity
+const (
+	LevelDebug Level = iota
+	LevelInfo
+	LevelWarn
+	LevelError
+)
+
+// levelNames maps levels to their names
+var levelNames = map[Level]string{
+	LevelDebug: "debug",
+	LevelInfo:  "info",
+	LevelWarn:  "warn",
+	LevelError: "error",
+}
+
+// prefixLevels maps message prefixes to their levels
+var prefixLevels = map[string]Level{
+	"[DEBUG]": LevelDebug,
+	"[INFO]":  LevelInfo,
+	"[WARN]":  LevelWarn,
+	"[ERROR]": LevelError,
+}
+
+// String returns the name of the level
+func (l Level) String() string {
+	if name, ok := levelNames[l]; ok {
+		return name
+	}
+	return fmt.Sprintf("level(%d)", int(l))
+}
+
+// ParseLevel returns the level named name
+func ParseLevel(name string) (Level, error) {
+	for l, n := range levelNames {
+		if n == name {
+			return l, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown log level %q", name)
+}
+
+// LevelLogger is implemented by Loggers whose level
+// can be changed at runtime
+type LevelLogger interface {
+	Logger
+
+	Level() Level
+	SetLevel(level Level)
+}
+
 // NilLogger is a logger that implements the logging
 // interface such that all its functions are no-ops
 type NilLogger struct{}
@@ -50,7 +105,7 @@ func (nl *NilLogger) Fatalf(format string, v ...interface{}) {}
 func (nl *NilLogger) Panicf(format string, v ...interface{}) {}
 func (nl *NilLogger) Print(v ...interface{})                 {}
 func (nl *NilLogger) Printf(format string, v ...interface{}) {}
-func (nl *NilLogger) Close() error                           { return nil }
+func (nl *NilLogger) Close()                                 {}
 
 // DefaultLogger is the Verto default implementation of the Logger interface.
 // This logger is thread-safe.
@@ -60,6 +115,7 @@ type DefaultLogger struct {
 	errors      map[string][]error
 	files       []*os.File
 	closed      bool
+	level       Level
 	mut         *sync.RWMutex
 
 	// DropTimeout is the duration before a message is dropped
@@ -164,6 +220,23 @@ func (dl *DefaultLogger) Close() {
 	}
 }
 
+// Level returns the level of the logger. Debug, info, warn and error
+// messages below the level are discarded. The default level is LevelDebug
+func (dl *DefaultLogger) Level() Level {
+	dl.mut.RLock()
+	defer dl.mut.RUnlock()
+
+	return dl.level
+}
+
+// SetLevel sets the level of the logger
+func (dl *DefaultLogger) SetLevel(level Level) {
+	dl.mut.Lock()
+	defer dl.mut.Unlock()
+
+	dl.level = level
+}
+
 // Info prints an info level message to all subscribers and open
 // log files.
 func (dl *DefaultLogger) Info(v ...interface{}) {
@@ -267,10 +340,14 @@ func (dl *DefaultLogger) Printf(format string, v ...interface{}) {
 // Prints a message to all subscribers and open log files. Keeps
 // track of errors writing to files
 func (dl *DefaultLogger) lprint(prefix string, v ...interface{}) {
+	if !dl.enabled(prefix) {
+		return
+	}
+
 	var buf bytes.Buffer
 	dl.appendPrefix(prefix, &buf)
 
-	buf.WriteString(fmt.Sprint(v))
+	buf.WriteString(fmt.Sprint(v...))
 	buf.WriteString("\n")
 
 	msg := buf.String()
@@ -284,11 +361,15 @@ func (dl *DefaultLogger) lprint(prefix string, v ...interface{}) {
 
 // Prints a formatted message. Keeps track of errors writing to files
 func (dl *DefaultLogger) lprintf(prefix, format string, v ...interface{}) {
+	if !dl.enabled(prefix) {
+		return
+	}
+
 	var buf bytes.Buffer
 	dl.appendPrefix(prefix, &buf)
 
 	if len(v) > 0 {
-		buf.WriteString(fmt.Sprintf(format, v))
+		buf.WriteString(fmt.Sprintf(format, v...))
 	} else {
 		buf.WriteString(fmt.Sprint(format))
 	}
@@ -303,6 +384,13 @@ func (dl *DefaultLogger) lprintf(prefix, format string, v ...interface{}) {
 	dl.writeToFiles(msg)
 }
 
+// Returns whether messages with the passed in
+// prefix are at or above the logger's level
+func (dl *DefaultLogger) enabled(prefix string) bool {
+	level, ok := prefixLevels[prefix]
+	return !ok || level >= dl.Level()
+}
+
 // Appends a prefix consisting of the current time and the passed in prefix
 // to a byte Buffer. Assumes the buffer is valid (not nil).
 func (dl *DefaultLogger) appendPrefix(prefix string, buf *bytes.Buffer) {
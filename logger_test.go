@@ -180,3 +180,28 @@ func getPrefix(logMsg string) string {
 	sp := strings.Split(msg, " ")
 	return sp[0]
 }
+
+func TestLoggerLevel(t *testing.T) {
+	defer func() {
+		err := recover()
+		if err != nil {
+			t.Errorf(err.(error).Error())
+		}
+	}()
+
+	err := "Failed logger level."
+
+	logger := NewLogger()
+	sub := logger.AddSubscriber("test")
+	logger.SetLevel(LevelWarn)
+	go func() {
+		logger.Info("dropped")
+		logger.Warn("kept")
+	}()
+	if msg := <-sub; msg[len(msg)-5:] != "kept\n" {
+		t.Errorf(err)
+	}
+	if l, e := ParseLevel("error"); e != nil || l != LevelError || l.String() != "error" {
+		t.Errorf(err)
+	}
+}
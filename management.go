@@ -0,0 +1,93 @@
+package verto
+
+import (
+	"sort"
+)
+
+// Management returns a Verto instance with its own route table that is
+// served on a second listener at addr (e.g. ":9090") while Verto runs.
+// Health, metrics, profiling and admin endpoints registered on the
+// management instance are isolated from the public API listener. The
+// management instance shares injections, logger, event bus and audit
+// trail with v and is started and stopped along with v. Stopping the
+// management instance stops v. Maintenance mode does not apply to
+// management routes. Calling Management again with the same address
+// returns the same instance.
+//
+// Example usage:
+//
+//	m := v.Management(":9090")
+//	m.EnableAdmin("/admin")
+//	m.EnableProfiling("/debug")
+//	m.GetHandler("/ready", v.ReadinessHandler())
+//	v.Run()
+func (v *Verto) Management(addr string) *Verto {
+	v.mutex.Lock()
+	if m, ok := v.management[addr]; ok {
+		v.mutex.Unlock()
+		return m
+	}
+	v.mutex.Unlock()
+
+	m := v.Stage()
+	m.parent = v
+	m.admin = nil
+	m.management = nil
+	m.ops = &operations{}
+	m.Timeouts = Timeouts{}
+
+	v.mutex.Lock()
+	defer v.mutex.Unlock()
+	if v.management == nil {
+		v.management = make(map[string]*Verto)
+	}
+	v.management[addr] = m
+	return m
+}
+
+// root returns the instance owning v's lifecycle
+func (v *Verto) root() *Verto {
+	if v.parent != nil {
+		return v.parent
+	}
+	return v
+}
+
+// serveManagement starts the management listeners in the background
+func (v *Verto) serveManagement() {
+	v.mutex.RLock()
+	addrs := make([]string, 0, len(v.management))
+	for addr := range v.management {
+		addrs = append(addrs, addr)
+	}
+	v.mutex.RUnlock()
+	sort.Strings(addrs)
+
+	for _, addr := range addrs {
+		v.mutex.Lock()
+		m := v.management[addr]
+		v.mutex.Unlock()
+
+		l := m.listen(addr)
+		v.mutex.Lock()
+		m.l = l
+		v.mutex.Unlock()
+
+		if v.verbose {
+			v.Logger.Infof("Management listener on %s initializing...", addr)
+		}
+		go m.server().Serve(l)
+	}
+}
+
+// stopManagement closes the management listeners
+func (v *Verto) stopManagement() {
+	v.mutex.RLock()
+	defer v.mutex.RUnlock()
+
+	for _, m := range v.management {
+		if m.l != nil {
+			m.l.Close()
+		}
+	}
+}
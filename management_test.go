@@ -0,0 +1,95 @@
+package verto
+
+import (
+	"io/ioutil"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestManagement(t *testing.T) {
+	defer func() {
+		err := recover()
+		if err != nil {
+			t.Errorf(err.(error).Error())
+		}
+	}()
+
+	err := "Failed management."
+
+	v := New()
+	v.Logger = &NilLogger{}
+	v.Get("/public", func(c *Context) (interface{}, error) {
+		return "public", nil
+	})
+	m := v.Management("127.0.0.1:0")
+	if v.Management("127.0.0.1:0") != m {
+		t.Errorf(err)
+	}
+	m.GetHandler("/ready", v.ReadinessHandler())
+	m.EnableAdmin("/admin")
+
+	ready := make(chan struct{}, 1)
+	v.Events.Subscribe(ReadyEvent, func(e Event) { ready <- struct{}{} })
+	stopped := make(chan struct{})
+	go func() {
+		v.RunOn("127.0.0.1:0")
+		close(stopped)
+	}()
+	select {
+	case <-ready:
+	case <-time.After(5 * time.Second):
+		t.Fatalf(err)
+	}
+
+	client := &http.Client{Timeout: 5 * time.Second, Transport: &http.Transport{DisableKeepAlives: true}}
+	fetch := func(addr, path string) (int, string) {
+		resp, e := client.Get("http://" + addr + path)
+		if e != nil {
+			return 0, ""
+		}
+		defer resp.Body.Close()
+		b, _ := ioutil.ReadAll(resp.Body)
+		return resp.StatusCode, string(b)
+	}
+	public := v.l.Addr().String()
+	management := m.l.Addr().String()
+
+	// Test routes are isolated per listener
+	if code, body := fetch(public, "/public"); code != 200 || body != "public" {
+		t.Errorf(err)
+	}
+	if code, _ := fetch(public, "/ready"); code != 404 {
+		t.Errorf(err)
+	}
+	if code, _ := fetch(management, "/public"); code != 404 {
+		t.Errorf(err)
+	}
+	if code, body := fetch(management, "/ready"); code != 200 || body != "Ready." {
+		t.Errorf(err)
+	}
+
+	// Test maintenance mode spares management routes
+	v.SetMaintenance(true)
+	if code, _ := fetch(public, "/public"); code != 503 {
+		t.Errorf(err)
+	}
+	if code, _ := fetch(management, "/ready"); code != 200 {
+		t.Errorf(err)
+	}
+
+	// Test the admin API on the management listener stops both listeners
+	resp, e := client.Post("http://"+management+"/admin/shutdown", "text/plain", nil)
+	if e != nil || resp.StatusCode != 202 {
+		t.Fatalf(err)
+	}
+	resp.Body.Close()
+	select {
+	case <-stopped:
+	case <-time.After(5 * time.Second):
+		t.Fatalf(err)
+	}
+	if code, _ := fetch(management, "/ready"); code != 0 {
+		t.Errorf(err)
+	}
+}
@@ -0,0 +1,54 @@
+package verto
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// Mount delegates every request for prefix and the paths under it to
+// handler, e.g. the router of another framework or an http.ServeMux.
+// The prefix is stripped from the request path before handler is called,
+// so that handler sees the paths it would see if it were served on its
+// own. Verto's global plugins run before handler. Requests are delegated
+// for each of mux.Methods. The returned Endpoints can be given plugins
+// and metadata at once.
+//
+// Example usage:
+//
+//	admin := http.NewServeMux()
+//	admin.HandleFunc("/stats", stats) // served at /admin/stats
+//	v.Mount("/admin", admin)
+func (v *Verto) Mount(prefix string, handler http.Handler) Endpoints {
+	prefix = strings.TrimRight(prefix, "/")
+	stripped := stripPrefix(prefix, handler)
+	eps := v.AnyHandler(prefix+"/^", stripped)
+	eps = append(eps, v.AnyHandler(prefix+"/", stripped)...)
+	if prefix != "" {
+		eps = append(eps, v.AnyHandler(prefix, stripped)...)
+	}
+	return eps
+}
+
+// stripPrefix returns a handler serving requests through handler
+// with prefix removed from their paths. The root path is passed
+// for requests of the prefix itself
+func stripPrefix(prefix string, handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r2 := new(http.Request)
+		*r2 = *r
+		r2.URL = new(url.URL)
+		*r2.URL = *r.URL
+		r2.URL.Path = strings.TrimPrefix(r.URL.Path, prefix)
+		if r2.URL.Path == "" {
+			r2.URL.Path = "/"
+		}
+		if r.URL.RawPath != "" {
+			r2.URL.RawPath = strings.TrimPrefix(r.URL.RawPath, prefix)
+			if r2.URL.RawPath == "" {
+				r2.URL.RawPath = "/"
+			}
+		}
+		handler.ServeHTTP(w, r2)
+	})
+}
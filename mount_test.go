@@ -0,0 +1,73 @@
+package verto
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestMount(t *testing.T) {
+	defer func() {
+		err := recover()
+		if err != nil {
+			t.Errorf(err.(error).Error())
+		}
+	}()
+
+	err := "Failed mount."
+
+	v := New()
+	v.Logger = &NilLogger{}
+	v.UseHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Global", "1")
+	}))
+	admin := http.NewServeMux()
+	admin.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, r.Method+" "+r.URL.Path)
+	})
+	v.Mount("/admin/", admin)
+	v.Get("/other", func(c *Context) (interface{}, error) {
+		return "other", nil
+	})
+	h := &HttpHandler{v}
+
+	serve := func(method, path string) *httptest.ResponseRecorder {
+		r, _ := http.NewRequest(method, "http://test.com"+path, nil)
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		return w
+	}
+
+	// Test requests under the prefix are delegated with the
+	// prefix stripped after global plugins ran
+	expected := map[string]string{
+		"/admin":           "GET /",
+		"/admin/":          "GET /",
+		"/admin/stats":     "GET /stats",
+		"/admin/users/1/x": "GET /users/1/x",
+	}
+	for path, body := range expected {
+		w := serve("GET", path)
+		if w.Code != 200 || w.Body.String() != body || w.Header().Get("X-Global") != "1" {
+			t.Errorf(err)
+		}
+	}
+	if w := serve("DELETE", "/admin/users/1"); w.Body.String() != "DELETE /users/1" {
+		t.Errorf(err)
+	}
+
+	// Test routes outside of the prefix are unaffected
+	if w := serve("GET", "/other"); w.Body.String() != "other" {
+		t.Errorf(err)
+	}
+	if w := serve("GET", "/administrator"); w.Code != 404 {
+		t.Errorf(err)
+	}
+
+	// Test the prefix is delegated in non-strict mode
+	v.SetStrict(false)
+	if w := serve("GET", "/admin"); w.Body.String() != "GET /" {
+		t.Errorf(err)
+	}
+}
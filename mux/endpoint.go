@@ -1,6 +1,7 @@
 package mux
 
 import (
+	"context"
 	"net/http"
 )
 
@@ -18,6 +19,53 @@ type Endpoint interface {
 	// UseHandler wraps the handler as a PluginHandler and adds it onto the end
 	// of the plugin chain.
 	UseHandler(hander http.Handler) Endpoint
+
+	// Meta associates a metadata value with key for the Endpoint.
+	// Metadata is readable during request handling through CurrentRoute.
+	Meta(key string, value interface{}) Endpoint
+
+	// Name registers name as the name of the Endpoint's route so that
+	// URLs for the route can be built with PathMuxer.URL
+	Name(name string) Endpoint
+
+	// Route returns a read-only view of the Endpoint's route
+	Route() Route
+
+	// Remove detaches the Endpoint so that its route is no longer
+	// served. Routes under the Endpoint's path are unaffected.
+	// Removing a detached Endpoint does nothing
+	Remove()
+}
+
+// Route is a read-only view of the route matched for a request.
+type Route interface {
+	// Name returns the name of the route or an
+	// empty string if the route is unnamed
+	Name() string
+
+	// Method returns the method the route was registered under
+	Method() string
+
+	// Path returns the full path pattern of the route
+	Path() string
+
+	// Host returns the host pattern the route is served for
+	// or an empty string if it is served for any host
+	Host() string
+
+	// Meta returns the metadata value associated with key
+	// and whether such a value exists
+	Meta(key string) (interface{}, bool)
+}
+
+// CurrentRoute returns the Route matched for r or nil if r
+// was not dispatched by a PathMuxer.
+func CurrentRoute(r *http.Request) Route {
+	route, _ := r.Context().Value(routeKey).(*route)
+	if route == nil {
+		return nil
+	}
+	return route
 }
 
 // endpoint is a private struct used to keep track of handlers
@@ -31,6 +79,15 @@ type endpoint struct {
 
 	chain    *plugins
 	compiled *plugins
+
+	name  string
+	meta  map[string]interface{}
+	route *route
+
+	// rawParams is set for endpoints registered with
+	// AddParamHandler whose path parameters are never
+	// added to the request's form
+	rawParams bool
 }
 
 // returns a fully initialized endpoint with handler
@@ -41,7 +98,9 @@ func newEndpoint(method, path string, handler http.Handler) *endpoint {
 		path:    path,
 		handler: handler,
 		chain:   newPlugins(),
+		meta:    make(map[string]interface{}),
 	}
+	ep.route = &route{ep}
 	ep.compile()
 	return ep
 }
@@ -68,8 +127,22 @@ func (ep *endpoint) cType() cType {
 }
 
 // exec runs the compiled chain of handlers for this endpoint.
+// The endpoint is attached to the request context so that it is
+// retrievable through CurrentRoute.
 func (ep *endpoint) exec(w http.ResponseWriter, r *http.Request) {
-	ep.compiled.run(w, r)
+	if ep.formParams() {
+		formParams(r)
+	}
+	ep.compiled.run(w, r.WithContext(context.WithValue(r.Context(), routeKey, ep.route)))
+}
+
+// formParams returns whether the path parameters of requests
+// for the endpoint are added to the request's form
+func (ep *endpoint) formParams() bool {
+	if ep.rawParams {
+		return false
+	}
+	return ep.parent == nil || ep.parent.mux == nil || ep.parent.mux.base().FormParams
 }
 
 // Join sets a new group as parent and adjusts
@@ -103,3 +176,106 @@ func (ep *endpoint) UseHandler(handler http.Handler) Endpoint {
 
 	return ep.Use(pluginHandler)
 }
+
+// Meta associates a metadata value with key for the endpoint.
+// If an old association exists, it is overwritten.
+func (ep *endpoint) Meta(key string, value interface{}) Endpoint {
+	ep.meta[key] = value
+	return ep
+}
+
+// Name registers name as the name of the endpoint's route with the
+// endpoint's muxer. A previous route registered under name is replaced.
+func (ep *endpoint) Name(name string) Endpoint {
+	if ep.parent == nil || ep.parent.mux == nil {
+		panic("Endpoint.Name: endpoint is not attached to a PathMuxer.")
+	}
+	ep.name = name
+	ep.parent.mux.names[name] = ep
+	return ep
+}
+
+// Route returns a read-only view of the endpoint's route
+func (ep *endpoint) Route() Route {
+	return ep.route
+}
+
+// Remove detaches the endpoint from its group and unregisters
+// its name. Remove is safe to call while the muxer is serving
+func (ep *endpoint) Remove() {
+	if ep.parent != nil && ep.parent.mux != nil {
+		ep.parent.mux.mutex.Lock()
+		defer ep.parent.mux.mutex.Unlock()
+	}
+	ep.remove()
+}
+
+// remove implements Remove. The caller must hold the muxer's lock.
+// The parent is kept as requests in flight may still use it
+func (ep *endpoint) remove() {
+	if ep.parent != nil && ep.parent.matcher.detach(ep.path, ep) {
+		ep.unname()
+	}
+}
+
+// unname unregisters the endpoint's name with its muxer
+func (ep *endpoint) unname() {
+	if ep.name == "" || ep.parent == nil || ep.parent.mux == nil {
+		return
+	}
+	if names := ep.parent.mux.names; names[ep.name] == ep {
+		delete(names, ep.name)
+	}
+}
+
+// fullPath returns the full path pattern of the endpoint
+func (ep *endpoint) fullPath() string {
+	if ep.parent == nil {
+		return ep.path
+	}
+	return ep.parent.fullPath + ep.path
+}
+
+// ---------- Route ----------
+// ---------------------------
+
+// contextKey is the type for keys of values stored by
+// the mux package in request contexts
+type contextKey int
+
+// routeKey is the request context key for the matched route
+const routeKey contextKey = 0
+
+// groupKey is the request context key for the group
+// that failed to match a request
+const groupKey contextKey = 1
+
+// route implements the Route interface as a read-only
+// view of an endpoint
+type route struct {
+	ep *endpoint
+}
+
+func (rt *route) Name() string {
+	return rt.ep.name
+}
+
+func (rt *route) Method() string {
+	return rt.ep.method
+}
+
+func (rt *route) Path() string {
+	return rt.ep.fullPath()
+}
+
+func (rt *route) Host() string {
+	if rt.ep.parent == nil || rt.ep.parent.mux == nil {
+		return ""
+	}
+	return rt.ep.parent.mux.host
+}
+
+func (rt *route) Meta(key string) (interface{}, bool) {
+	v, ok := rt.ep.meta[key]
+	return v, ok
+}
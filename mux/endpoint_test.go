@@ -77,3 +77,43 @@ func TestEndpointServeHTTP(t *testing.T) {
 		t.Errorf(err)
 	}
 }
+
+func TestEndpointMeta(t *testing.T) {
+	defer func() {
+		err := recover()
+		if err != nil {
+			t.Errorf(err.(error).Error())
+		}
+	}()
+
+	err := "Failed endpoint meta."
+	var route Route
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		route = CurrentRoute(r)
+	})
+
+	mux := New()
+	mux.Group("GET", "/a").Add("/{b}", handler).Meta("key", "value")
+
+	r, _ := http.NewRequest("GET", "http://test.com/a/c", nil)
+	if CurrentRoute(r) != nil {
+		t.Errorf(err)
+	}
+	mux.ServeHTTP(nil, r)
+	if route == nil {
+		t.Fatalf(err)
+	}
+	if route.Method() != "GET" {
+		t.Errorf(err)
+	}
+	if route.Path() != "/a/{b}" {
+		t.Errorf(err)
+	}
+	if v, ok := route.Meta("key"); !ok || v != "value" {
+		t.Errorf(err)
+	}
+	if _, ok := route.Meta("missing"); ok {
+		t.Errorf(err)
+	}
+}
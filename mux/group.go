@@ -1,7 +1,9 @@
 package mux
 
 import (
+	"context"
 	"net/http"
+	"sort"
 	"strings"
 )
 
@@ -30,6 +32,27 @@ type Group interface {
 	// UseHandler wraps handler as a PluginHandler and calls Use. Handler registered
 	// using UseHandler automatically call the next-in-line Plugin.
 	UseHandler(handler http.Handler) Group
+
+	// Meta associates a metadata value with key for the Group. Metadata
+	// is readable through GroupMeta while the Group's paths are answered
+	// with Not Found, Method Not Allowed or automatic OPTIONS responses
+	Meta(key string, value interface{}) Group
+
+	// Method returns the method the group was created under
+	Method() string
+
+	// Path returns the full path prefix of the group
+	Path() string
+
+	// Routes returns every route registered under the group
+	// and its subgroups sorted by path
+	Routes() []Route
+
+	// Remove detaches the group along with its subgroups and
+	// endpoints, including endpoints it subsumed when it was
+	// created. Removing the root group of a method removes every
+	// route of the method. Removing a detached group does nothing
+	Remove()
 }
 
 // group implements the Group interface and the Compilable
@@ -46,6 +69,8 @@ type group struct {
 
 	chain    *plugins
 	compiled *plugins
+
+	meta map[string]interface{}
 }
 
 // newGroup returns a group with
@@ -60,6 +85,7 @@ func newGroup(method, path string, mux *PathMuxer) *group {
 		matcher:  &matcher{},
 		chain:    newPlugins(),
 		compiled: newPlugins(),
+		meta:     make(map[string]interface{}),
 	}
 	g.compile()
 	return g
@@ -91,6 +117,7 @@ func (g *group) Add(path string, handler http.Handler) Endpoint {
 	} else {
 		ep = results.data().(*endpoint)
 		ep.handler = handler
+		ep.rawParams = false
 	}
 	return ep
 }
@@ -139,11 +166,15 @@ func (g *group) Group(path string) Group {
 		}
 	}
 
-	// Create new group
+	// Create new group. Its path is already relative
+	// to the current group so it is attached directly
 	ng := newGroup(g.method, path, g.mux)
+	ng.parent = g
+	ng.fullPath = g.fullPath + path
 
-	// Gather subgroups, drop them from current mux/group,
-	// add them to new group
+	// Gather subgroups and endpoints, drop them from current
+	// mux/group, add them to new group. Wildcard segments
+	// are subsumed regardless of their names
 	sub := make([]compilable, 0)
 	g.matcher.applyAt(path, func(c compilable) {
 		sub = append(sub, c)
@@ -153,7 +184,7 @@ func (g *group) Group(path string) Group {
 	}
 
 	// Add group to current mux/group
-	ng.join(g)
+	g.matcher.add(path, ng)
 	ng.compile()
 	return ng
 }
@@ -180,6 +211,93 @@ func (g *group) UseHandler(handler http.Handler) Group {
 	return g
 }
 
+// Method returns the method the group was created under
+func (g *group) Method() string {
+	return g.method
+}
+
+// Meta associates value with key in the group's metadata
+func (g *group) Meta(key string, value interface{}) Group {
+	g.meta[key] = value
+	return g
+}
+
+// Path returns the full path prefix of the group
+func (g *group) Path() string {
+	return g.fullPath
+}
+
+// Routes returns every route in the subtree of the group
+// sorted by path
+func (g *group) Routes() []Route {
+	routes := make([]Route, 0)
+	g.endpoints(func(ep *endpoint) {
+		routes = append(routes, ep.route)
+	})
+	sort.Slice(routes, func(i, j int) bool {
+		return routes[i].Path() < routes[j].Path()
+	})
+	return routes
+}
+
+// Remove detaches the group from its parent and unregisters the
+// names of its endpoints. Remove is safe to call while the muxer
+// is serving
+func (g *group) Remove() {
+	if g.mux != nil {
+		g.mux.mutex.Lock()
+		defer g.mux.mutex.Unlock()
+	}
+	g.endpoints(func(ep *endpoint) {
+		ep.unname()
+	})
+	if g.parent == nil {
+		// The root group of a method stays registered
+		// with the muxer but loses all routes
+		g.matcher.root = nil
+		return
+	}
+	g.parent.matcher.detach(g.path, g)
+}
+
+// lookup returns the endpoint registered at path
+// in the subtree of the group or nil
+func (g *group) lookup(path string) *endpoint {
+	results, err := g.matcher.matchExplicit(path)
+	if err != nil {
+		return nil
+	}
+	switch c := results.data().(type) {
+	case *group:
+		return c.lookup(trimPathPrefix(path, c.path, false))
+	case *endpoint:
+		return c
+	}
+	return nil
+}
+
+// groups applies f to every subgroup in the subtree of group
+func (g *group) groups(f func(g *group)) {
+	g.matcher.apply(func(c compilable) {
+		if c, ok := c.(*group); ok {
+			f(c)
+			c.groups(f)
+		}
+	})
+}
+
+// endpoints applies f to every endpoint in the subtree of group
+func (g *group) endpoints(f func(ep *endpoint)) {
+	g.matcher.apply(func(c compilable) {
+		switch c := c.(type) {
+		case *endpoint:
+			f(c)
+		case *group:
+			c.endpoints(f)
+		}
+	})
+}
+
 // Compile compiles the parent chain with
 // the groups chain in order to avoid expensive
 // chain manipulation during serving of requests.
@@ -195,7 +313,7 @@ func (g *group) compile() {
 	} else if g.mux != nil {
 		// no parent so must be top level group, request
 		// copy from muxer
-		g.compiled.link(g.mux.chain.deepCopy())
+		g.compiled.link(g.mux.globalChain())
 	}
 	g.compiled.link(g.chain.deepCopy())
 	g.matcher.apply(func(c compilable) {
@@ -203,6 +321,57 @@ func (g *group) compile() {
 	})
 }
 
+// matches returns whether the full path p matches an
+// endpoint of the group or one of its subgroups
+func (g *group) matches(p string) bool {
+	return g.find(p) != nil
+}
+
+// find returns the endpoint matching the full path p
+// in the subtree of the group or nil if there is none
+func (g *group) find(p string) *endpoint {
+	result, err := g.match(p)
+	if err != nil {
+		return nil
+	}
+	data := result.data()
+	g.matcher.release(result)
+	switch c := data.(type) {
+	case *group:
+		return c.find(p)
+	case *endpoint:
+		return c
+	}
+	return nil
+}
+
+// match matches the full path p against the group's matcher. The
+// group's own path is served by an endpoint subsumed at exactly
+// that path if one exists and by an endpoint added at '/' otherwise.
+// The results must be released to the group's matcher
+func (g *group) match(p string) (results, error) {
+	if g.mux != nil {
+		g.mux.mutex.RLock()
+		defer g.mux.mutex.RUnlock()
+	}
+	path := trimPathPrefix(p, g.fullPath, true)
+	if len(path) == 0 {
+		if result, err := g.matcher.match(path); err == nil {
+			return result, nil
+		}
+		path = "/"
+	} else if path[0] != '/' {
+		// The trimmed path is a suffix of p so the slash
+		// can usually be recovered without concatenating
+		if i := len(p) - len(path); i > 0 && p[i-1] == '/' {
+			path = p[i-1:]
+		} else {
+			path = "/" + path
+		}
+	}
+	return g.matcher.match(path)
+}
+
 // cType returns the type of Compilable
 // group is
 func (g *group) cType() cType {
@@ -214,30 +383,48 @@ func (g *group) cType() cType {
 // the associated handler is run. Otherwise, the proper error response
 // is returned.
 func (g *group) exec(w http.ResponseWriter, r *http.Request) {
-	path := trimPathPrefix(r.URL.Path, g.fullPath, true)
-	if path[0] != '/' {
-		path = "/" + path
-	}
-
-	result, err := g.matcher.match(path)
+	result, err := g.match(r.URL.Path)
 	if err == ErrNotFound {
-		g.mux.NotFound.ServeHTTP(w, r)
+		g.mux.notFound(w, g.attach(r))
 		return
 	} else if err == ErrRedirectSlash {
-		if !g.mux.Strict {
+		if !g.mux.base().Strict {
 			r.URL.Path = handleTrailingSlash(r.URL.Path)
-			g.mux.Redirect.ServeHTTP(w, r)
+			g.mux.base().Redirect.ServeHTTP(w, r)
 			return
 		}
-		g.mux.NotFound.ServeHTTP(w, r)
+		g.mux.notFound(w, g.attach(r))
 		return
 	}
 
+	data := result.data()
 	if len(result.params()) > 0 {
-		r.ParseForm()
-		insertParams(result.params(), r.Form)
+		r = withParams(r, result.params())
 	}
-	result.data().exec(w, r)
+	g.matcher.release(result)
+	data.exec(w, r)
+}
+
+// attach returns a copy of r with the group attached to its
+// context so that the group's metadata is retrievable through GroupMeta
+func (g *group) attach(r *http.Request) *http.Request {
+	return r.WithContext(context.WithValue(r.Context(), groupKey, g))
+}
+
+// GroupMeta returns the metadata value stored under key by the innermost
+// group that failed to match r, or that holds the routes of r's path for
+// automatic OPTIONS requests, or by the nearest of its parents that has
+// one. Returns false if r was not answered with a Not Found, Method Not
+// Allowed or automatic OPTIONS response by a PathMuxer or if no such group
+// has a value for key
+func GroupMeta(r *http.Request, key string) (interface{}, bool) {
+	g, _ := r.Context().Value(groupKey).(*group)
+	for ; g != nil; g = g.parent {
+		if value, ok := g.meta[key]; ok {
+			return value, true
+		}
+	}
+	return nil, false
 }
 
 // Join sets a new group as parent and adjusts
@@ -249,5 +436,8 @@ func (g *group) join(parent *group) {
 	g.parent = parent
 	g.path = trimPathPrefix(g.path, parent.path, false)
 	g.fullPath = parent.fullPath + g.path
+	g.groups(func(sub *group) {
+		sub.fullPath = sub.parent.fullPath + sub.path
+	})
 	parent.matcher.add(g.path, g)
 }
@@ -280,3 +280,30 @@ func TestGroupPlugins(t *testing.T) {
 		t.Errorf(err)
 	}
 }
+
+func TestGroupMatchAllocs(t *testing.T) {
+	defer func() {
+		err := recover()
+		if err != nil {
+			t.Errorf(err.(error).Error())
+		}
+	}()
+
+	mux := New()
+	mux.Add("GET", "/api/users/{id}", http.NotFoundHandler())
+	g := mux.Group("GET", "/api").(*group)
+
+	// Test matching within a group neither allocates
+	// nor concatenates the trimmed path
+	err := "Failed group match allocs."
+	allocs := testing.AllocsPerRun(100, func() {
+		results, e := g.match("/api/users/42")
+		if e != nil {
+			t.Fatalf(err)
+		}
+		g.matcher.release(results)
+	})
+	if allocs != 0 {
+		t.Errorf(err)
+	}
+}
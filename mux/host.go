@@ -0,0 +1,181 @@
+package mux
+
+import (
+	"net"
+	"net/http"
+	"regexp"
+	"strings"
+)
+
+// host is a host pattern served by a PathMuxer of its own
+type host struct {
+	pattern string
+	labels  []hostLabel
+	params  bool
+	mux     *PathMuxer
+}
+
+// hostLabel is a literal or parameterized label of a host pattern
+type hostLabel struct {
+	literal string
+	param   string
+	regex   *regexp.Regexp
+}
+
+// newHost parses pattern into a host served by mux. Labels in
+// braces are parameters optionally refined by a regex
+// (e.g. "{tenant: ^[a-z]+$}.example.com")
+func newHost(pattern string, mux *PathMuxer) *host {
+	h := &host{pattern: pattern, mux: mux}
+	for _, l := range strings.Split(strings.ToLower(strings.TrimSuffix(pattern, ".")), ".") {
+		if !strings.HasPrefix(l, "{") || !strings.HasSuffix(l, "}") {
+			h.labels = append(h.labels, hostLabel{literal: l})
+			continue
+		}
+		parts := strings.SplitN(l[1:len(l)-1], ":", 2)
+		label := hostLabel{param: strings.TrimSpace(parts[0])}
+		if len(parts) == 2 {
+			label.regex = regexp.MustCompile(strings.TrimSpace(parts[1]))
+		}
+		h.labels = append(h.labels, label)
+		h.params = true
+	}
+	return h
+}
+
+// match returns whether the host name matches the pattern
+// of h and the parameters captured by the match
+func (h *host) match(name string) (bool, PathParams) {
+	labels := strings.Split(name, ".")
+	if len(labels) != len(h.labels) {
+		return false, nil
+	}
+	var ps PathParams
+	for i, l := range h.labels {
+		switch {
+		case l.param == "":
+			if l.literal != labels[i] {
+				return false, nil
+			}
+		case labels[i] == "" || (l.regex != nil && !l.regex.MatchString(labels[i])):
+			return false, nil
+		default:
+			ps = append(ps, PathParam{l.param, labels[i]})
+		}
+	}
+	return true, ps
+}
+
+// Host returns the PathMuxer serving requests for hosts matching pattern
+// in place of mux, creating it if needed. Labels of pattern in braces
+// match any single label of a host, optionally refined by a regex, and
+// are captured as parameters (e.g. "{tenant}.example.com"). Patterns
+// without parameters take precedence over those with parameters, which
+// are tried in order of creation. Requests for other hosts are served by
+// mux. Host muxers share the settings, fallback handlers and global
+// plugins of mux but have routes, groups and names of their own.
+//
+// Example usage:
+//
+//	api := mux.Host("api.example.com")
+//	api.AddFunc("GET", "/users", listUsers)
+//	tenants := mux.Host("{tenant}.example.com")
+//	tenants.AddFunc("GET", "/", func(w http.ResponseWriter, r *http.Request) {
+//		fmt.Fprint(w, mux.Param(r, "tenant"))
+//	})
+func (mux *PathMuxer) Host(pattern string) *PathMuxer {
+	mux.mutex.Lock()
+	defer mux.mutex.Unlock()
+
+	for _, h := range mux.hosts {
+		if h.pattern == pattern {
+			return h.mux
+		}
+	}
+	hm := New()
+	hm.parent = mux
+	hm.host = pattern
+	mux.hosts = append(mux.hosts, newHost(pattern, hm))
+	hm.compile()
+	return hm
+}
+
+// matchHost returns the host muxer serving r and the parameters
+// captured from the host of r or nil if mux serves r itself
+func (mux *PathMuxer) matchHost(r *http.Request) (*PathMuxer, PathParams) {
+	mux.mutex.RLock()
+	defer mux.mutex.RUnlock()
+
+	if len(mux.hosts) == 0 {
+		return nil, nil
+	}
+	name := r.Host
+	if h, _, err := net.SplitHostPort(name); err == nil {
+		name = h
+	}
+	name = strings.ToLower(strings.TrimSuffix(name, "."))
+	for _, params := range []bool{false, true} {
+		for _, h := range mux.hosts {
+			if h.params != params {
+				continue
+			}
+			if ok, ps := h.match(name); ok {
+				return h.mux, ps
+			}
+		}
+	}
+	return nil, nil
+}
+
+// base returns the muxer whose settings and fallback
+// handlers mux uses, i.e. the muxer mux is a host of
+func (mux *PathMuxer) base() *PathMuxer {
+	for mux.parent != nil {
+		mux = mux.parent
+	}
+	return mux
+}
+
+// globalChain returns a copy of the global plugins run for the
+// routes of mux, including those of the muxer mux is a host of
+func (mux *PathMuxer) globalChain() *plugins {
+	if mux.parent == nil {
+		return mux.chain.deepCopy()
+	}
+	chain := mux.parent.globalChain()
+	chain.link(mux.chain.deepCopy())
+	return chain
+}
+
+// compile recompiles the plugin chains of
+// the routes of mux and its host muxers
+func (mux *PathMuxer) compile() {
+	for _, g := range mux.methods {
+		g.compile()
+	}
+	for _, h := range mux.hostList() {
+		h.mux.compile()
+	}
+}
+
+// hostList returns a copy of the hosts of mux
+func (mux *PathMuxer) hostList() []*host {
+	mux.mutex.RLock()
+	defer mux.mutex.RUnlock()
+
+	return append([]*host(nil), mux.hosts...)
+}
+
+// lookupName returns the endpoint registered under name
+// with mux or, failing that, with one of its host muxers
+func (mux *PathMuxer) lookupName(name string) (*endpoint, bool) {
+	if ep, ok := mux.names[name]; ok {
+		return ep, true
+	}
+	for _, h := range mux.hostList() {
+		if ep, ok := h.mux.lookupName(name); ok {
+			return ep, true
+		}
+	}
+	return nil, false
+}
@@ -4,6 +4,7 @@ import (
 	"errors"
 	"regexp"
 	"strings"
+	"sync"
 )
 
 // ---------- Mux Errors ----------
@@ -26,15 +27,6 @@ var ErrRedirectSlash = errors.New("mux: redirect trailing slash")
 const catchAll string = "^"
 const empty string = ""
 
-// ---------- Param ----------
-// ---------------------------
-
-// param represents a Key-Value HTTP parameter pair
-type param struct {
-	key   string
-	value string
-}
-
 // ---------- Results ----------
 // -----------------------------
 
@@ -44,27 +36,28 @@ type results interface {
 	data() compilable
 
 	// Returns all parameter key-value pairs as a slice
-	params() []param
+	params() PathParams
 }
 
 // ---------- matcherResults -----------
 // -------------------------------------
 
-// matcherResults is a simple and efficient
-// implementation of the Results interface
+// matcherResults is a simple and efficient implementation of
+// the Results interface. matcherResults are pooled per matcher
+// so that matching does not allocate
 type matcherResults struct {
 	c compilable
-	p []param
+	p PathParams
 }
 
 func newResults(maxParams int) *matcherResults {
 	return &matcherResults{
-		p: make([]param, 0, maxParams),
+		p: make(PathParams, 0, maxParams),
 	}
 }
 
 func (mr *matcherResults) addPair(key, value string) {
-	pair := param{key, value}
+	pair := PathParam{key, value}
 	mr.p = append(mr.p, pair)
 }
 
@@ -72,10 +65,17 @@ func (mr *matcherResults) data() compilable {
 	return mr.c
 }
 
-func (mr *matcherResults) params() []param {
+func (mr *matcherResults) params() PathParams {
 	return mr.p
 }
 
+// reset clears mr for reuse keeping
+// the capacity of its parameters
+func (mr *matcherResults) reset() {
+	mr.c = nil
+	mr.p = mr.p[:0]
+}
+
 // ---------- pathIterator ----------
 // ---------------------------------
 
@@ -165,7 +165,7 @@ func (n *matcherNode) add(path string, c compilable) int {
 	for pi.hasNext() {
 		// Get next path segment
 		s := pi.next()
-		if strings.HasPrefix(s, "{") && strings.HasSuffix(s, "}") {
+		if isWild(s) {
 			// Path segment is wildcard
 			child := n.wildChild
 			if child == nil {
@@ -230,6 +230,12 @@ func (n *matcherNode) apply(f func(c compilable)) {
 		for _, child := range n.children {
 			queue = append(queue, child)
 		}
+		if n.wildChild != nil {
+			queue = append(queue, n.wildChild)
+		}
+		if n.catchAll != nil {
+			queue = append(queue, n.catchAll)
+		}
 
 		if n.data != nil {
 			f(n.data)
@@ -237,61 +243,109 @@ func (n *matcherNode) apply(f func(c compilable)) {
 	}
 }
 
+// find returns the node at path or nil if none exists. Static
+// segments match children, wildcard segments match the wild child
+// regardless of their name and regex and a catch-all segment matches
+// the catch-all child, ending the traversal.
+func (n *matcherNode) find(path string) *matcherNode {
+	pi := pathIterator{path: path}
+	for pi.hasNext() && n != nil {
+		s := pi.next()
+		if child, ok := n.children[s]; ok {
+			n = child
+		} else if s == catchAll {
+			return n.catchAll
+		} else if isWild(s) {
+			n = n.wildChild
+		} else {
+			return nil
+		}
+	}
+	return n
+}
+
 // Applys f to the subtree rooted at path. Automatically stops
 // traversal at catch-all. Wildcards must be explicitly matched.
 // If the path is not found, the function returns without applying
 // f.
 func (n *matcherNode) applyAt(path string, f func(c compilable)) {
-	pi := pathIterator{path: path}
-	for pi.hasNext() {
-		s := pi.next()
-		child, ok := n.children[s]
-		if !ok {
-			if s == catchAll {
-				n = n.catchAll
-				break
-			} else if s[0] == '{' && s[len(s)-1] == '}' {
-				child = n.wildChild
-				continue
-			}
-			return
-		}
-		n = child
+	if n = n.find(path); n != nil {
+		n.apply(f)
 	}
-	n.apply(f)
 }
 
 // Private drop function that drops the subtree
 // rooted at path. Automatically stops parsing on a
 // catch-all. Wildcards must be matched explicitly with
 // starting { and ending }. Dropped subtrees are completely
-// deleted.
+// deleted along with ancestors left without data or children.
 func (n *matcherNode) drop(path string) {
-	pi := pathIterator{path: path}
-	var s string
+	n = n.find(path)
+	if n == nil {
+		return
+	}
+	if n.parent == nil {
+		*n = *newMatcherNode()
+		return
+	}
+	for n.parent != nil {
+		parent := n.parent
+		parent.remove(n)
+		if parent.data != nil || !parent.empty() {
+			return
+		}
+		n = parent
+	}
+}
 
-	for pi.hasNext() {
-		s = pi.next()
-		child, ok := n.children[s]
-		if !ok {
-			if s == catchAll {
-				n = n.catchAll
-				break
-			} else if s[0] == '{' && s[len(s)-1] == '}' {
-				child = n.wildChild
-				continue
+// detach removes the data c from the node at path leaving subpaths
+// of path intact. Nodes left without data or children are pruned.
+// Returns whether c was found at path
+func (n *matcherNode) detach(path string, c compilable) bool {
+	n = n.find(path)
+	if n == nil || n.data != c {
+		return false
+	}
+	n.data = nil
+	for n.parent != nil && n.data == nil && n.empty() {
+		parent := n.parent
+		parent.remove(n)
+		n = parent
+	}
+	return true
+}
+
+// remove detaches child from n
+func (n *matcherNode) remove(child *matcherNode) {
+	switch child {
+	case n.wildChild:
+		n.wildChild = nil
+	case n.catchAll:
+		n.catchAll = nil
+	default:
+		for s, c := range n.children {
+			if c == child {
+				delete(n.children, s)
 			}
-			return
 		}
-		n = child
 	}
-	delete(n.parent.children, s)
+	child.parent = nil
 }
 
-// Private matching function that contains all the matching logic
-func (n *matcherNode) match(path string, explicit bool, maxParams int) (results, error) {
+// empty returns whether n has no child nodes
+func (n *matcherNode) empty() bool {
+	return len(n.children) == 0 && n.wildChild == nil && n.catchAll == nil
+}
+
+// isWild returns whether the path segment s is a wildcard
+func isWild(s string) bool {
+	return len(s) > 1 && s[0] == '{' && s[len(s)-1] == '}'
+}
+
+// Private matching function that contains all the matching logic.
+// Matched data and parameters are stored in results
+func (n *matcherNode) match(path string, explicit bool, results *matcherResults) error {
 	pi := pathIterator{path: path}
-	results := newResults(maxParams)
 	var mrg compilable
 
 	for pi.hasNext() {
@@ -302,35 +356,47 @@ func (n *matcherNode) match(path string, explicit bool, maxParams int) (results,
 			// at trailing slash and a redirect might be in order
 			if pi.seenTrailingSlash() {
 				if n.data != nil {
-					return nil, ErrRedirectSlash
+					return ErrRedirectSlash
 				}
 				if n.parent.wildChild != nil && n.parent.wildChild.data != nil {
-					return nil, ErrRedirectSlash
+					return ErrRedirectSlash
 				}
-				return nil, ErrNotFound
+				return ErrNotFound
 			}
 
-			var notWild = len(s) == 0 || s[0] != '{' || s[len(s)-1] != '}'
 			child = n.wildChild
 
-			// If segment is not wild and we want explicit match
-			// or wild child doesn't exist, check catch all and
-			// most recent group as last ditch effort
-			if (explicit && notWild) || child == nil {
-				if n.catchAll != nil {
+			// Explicit matches only match wildcard segments
+			// to the wild child and catch-all segments to the
+			// catch-all so that registering a pattern never
+			// resolves to a different pattern
+			if explicit {
+				if s == catchAll && n.catchAll != nil {
+					n = n.catchAll
+					break
+				}
+				if !isWild(s) {
+					child = nil
+				}
+			}
+
+			// If wild child doesn't exist, check catch all
+			// and most recent group as last ditch effort
+			if child == nil {
+				if !explicit && n.catchAll != nil {
 					n = n.catchAll
 					break
 				}
 				if mrg != nil {
 					results.c = mrg
-					return results, nil
+					return nil
 				}
-				return nil, ErrNotFound
+				return ErrNotFound
 			}
 
 			// Found wildcard, check the regex constraint if necessary
 			if !explicit && child.regex != nil && !child.regex.MatchString(s) {
-				return nil, ErrNotFound
+				return ErrNotFound
 			}
 			results.addPair(child.wildcard, s)
 		}
@@ -343,13 +409,13 @@ func (n *matcherNode) match(path string, explicit bool, maxParams int) (results,
 		// If we are at a node whose data is nil, it is most likely the
 		// case that the data actually lies on a trailing slash node
 		if child, ok := n.children[empty]; ok && child.data != nil {
-			return nil, ErrRedirectSlash
+			return ErrRedirectSlash
 		}
-		return nil, ErrNotFound
+		return ErrNotFound
 	}
 
 	results.c = n.data
-	return results, nil
+	return nil
 }
 
 // ---------- DefaultMatcher ----------
@@ -360,6 +426,7 @@ func (n *matcherNode) match(path string, explicit bool, maxParams int) (results,
 type matcher struct {
 	root *matcherNode
 	mp   int
+	pool sync.Pool
 }
 
 // Add registers an object with a specific path. Wildcard path
@@ -406,24 +473,61 @@ func (m *matcher) drop(path string) {
 	m.root.drop(path)
 }
 
+// Detach removes c from path without dropping the subtree rooted at path
+func (m *matcher) detach(path string, c compilable) bool {
+	if m.root == nil {
+		return false
+	}
+	return m.root.detach(path, c)
+}
+
 // match returns the object registered at path or an error if none exist.
 // Wildcard segments are observed. ErrNotFound is returned if no matching path
 // exists and a trailing slash redirect (tsr) isn't possible. ErrRedirect is returned
-// if no matching path exists but a tsr is possible.
+// if no matching path exists but a tsr is possible. The returned results are
+// pooled and should be passed to release once they are no longer used.
 func (m *matcher) match(path string) (results, error) {
 	if m.root == nil {
 		return nil, ErrNotFound
 	}
-	return m.root.match(path, false, m.mp)
+	mr := m.get()
+	if err := m.root.match(path, false, mr); err != nil {
+		m.release(mr)
+		return nil, err
+	}
+	return mr, nil
 }
 
 // matchExplicit performs in the same manner as match except that it doesn't
-// check regex restrictions on wildcard parameters.
+// check regex restrictions on wildcard parameters. It is used when registering
+// paths so its results are not pooled.
 func (m *matcher) matchExplicit(path string) (results, error) {
 	if m.root == nil {
 		return nil, ErrNotFound
 	}
-	return m.root.match(path, true, m.mp)
+	mr := newResults(m.mp)
+	if err := m.root.match(path, true, mr); err != nil {
+		return nil, err
+	}
+	return mr, nil
+}
+
+// get returns pooled results or new results with
+// room for the maximum number of parameters
+func (m *matcher) get() *matcherResults {
+	if mr, ok := m.pool.Get().(*matcherResults); ok {
+		return mr
+	}
+	return newResults(m.mp)
+}
+
+// release returns results obtained from match to the pool.
+// Neither r nor its parameters may be used afterwards
+func (m *matcher) release(r results) {
+	if mr, ok := r.(*matcherResults); ok {
+		mr.reset()
+		m.pool.Put(mr)
+	}
 }
 
 // maxParams returns the maximum possible number of
@@ -175,7 +175,7 @@ func TestMatcherMatch(t *testing.T) {
 	}
 	found := false
 	for _, v := range results.params() {
-		if v.key == "wc" && v.value == "test" {
+		if v.Key == "wc" && v.Value == "test" {
 			found = true
 		}
 	}
@@ -199,7 +199,7 @@ func TestMatcherMatch(t *testing.T) {
 	}
 	found = false
 	for _, v := range results.params() {
-		if v.key == "wc" && v.value == "42" {
+		if v.Key == "wc" && v.Value == "42" {
 			found = true
 		}
 	}
@@ -221,7 +221,7 @@ func TestMatcherMatch(t *testing.T) {
 	}
 	found = false
 	for _, v := range results.params() {
-		if v.key == "wc" && v.value == "{test}" {
+		if v.Key == "wc" && v.Value == "{test}" {
 			found = true
 		}
 	}
@@ -232,3 +232,105 @@ func TestMatcherMatch(t *testing.T) {
 		t.Errorf(err)
 	}
 }
+
+func TestMatcherAllocs(t *testing.T) {
+	defer func() {
+		err := recover()
+		if err != nil {
+			t.Errorf(err.(error).Error())
+		}
+	}()
+
+	m := &matcher{}
+	m.add("/users", &endpoint{})
+	m.add("/users/{id}", &endpoint{})
+
+	// Test static and single parameter matches do not allocate
+	err := "Failed matcher allocs."
+	for _, path := range []string{"/users", "/users/42"} {
+		allocs := testing.AllocsPerRun(100, func() {
+			results, e := m.match(path)
+			if e != nil {
+				t.Fatalf(err)
+			}
+			m.release(results)
+		})
+		if allocs != 0 {
+			t.Errorf(err)
+		}
+	}
+
+	// Test released results are reset
+	err = "Failed matcher release."
+	results, _ := m.match("/users/42")
+	m.release(results)
+	results, _ = m.match("/users")
+	if len(results.params()) != 0 {
+		t.Errorf(err)
+	}
+	m.release(results)
+}
+
+func BenchmarkMatcherStatic(b *testing.B) {
+	m := &matcher{}
+	m.add("/users", &endpoint{})
+	m.add("/users/{id}", &endpoint{})
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		results, _ := m.match("/users")
+		m.release(results)
+	}
+}
+
+func BenchmarkMatcherParam(b *testing.B) {
+	m := &matcher{}
+	m.add("/users", &endpoint{})
+	m.add("/users/{id}", &endpoint{})
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		results, _ := m.match("/users/42")
+		m.release(results)
+	}
+}
+
+func TestMatcherDetach(t *testing.T) {
+	defer func() {
+		err := recover()
+		if err != nil {
+			t.Errorf(err.(error).Error())
+		}
+	}()
+
+	err := "Failed matcher detach."
+
+	m := &matcher{}
+	a := &endpoint{}
+	b := &endpoint{}
+	c := &endpoint{}
+	m.add("/a", a)
+	m.add("/a/{id}", b)
+	m.add("/x/y/z", c)
+
+	// Test detaching keeps subpaths
+	if m.detach("/a", b) || !m.detach("/a", a) {
+		t.Errorf(err)
+	}
+	if _, e := m.match("/a"); e != ErrNotFound {
+		t.Errorf(err)
+	}
+	if results, e := m.match("/a/1"); e != nil || results.data() != b {
+		t.Errorf(err)
+	}
+
+	// Test empty ancestors are pruned
+	if !m.detach("/x/y/z", c) {
+		t.Errorf(err)
+	}
+	if _, ok := m.root.children["x"]; ok {
+		t.Errorf(err)
+	}
+}
@@ -0,0 +1,115 @@
+package mux
+
+import (
+	"context"
+	"net/http"
+)
+
+// PathParam is a path parameter matched by the muxer
+type PathParam struct {
+	Key   string
+	Value string
+}
+
+// PathParams are the path parameters matched for a request in the
+// order of their path segments. Unlike url.Values they are accessible
+// by position and require no map allocation.
+type PathParams []PathParam
+
+// ByName returns the value of the parameter name or an empty string
+// if no such parameter exists. If groups and endpoints share a
+// parameter name the innermost value is returned
+func (ps PathParams) ByName(name string) string {
+	for i := len(ps) - 1; i >= 0; i-- {
+		if ps[i].Key == name {
+			return ps[i].Value
+		}
+	}
+	return ""
+}
+
+// ParamHandler is a handler receiving the matched path parameters
+// directly (see PathMuxer.AddParamHandler)
+type ParamHandler interface {
+	ServeHTTPParams(w http.ResponseWriter, r *http.Request, ps PathParams)
+}
+
+// ParamHandlerFunc wraps functions so that they implement the
+// ParamHandler interface. ParamHandlerFuncs are also http.Handlers
+// receiving the parameters of the request
+type ParamHandlerFunc func(w http.ResponseWriter, r *http.Request, ps PathParams)
+
+// ServeHTTPParams calls the function wrapped as a ParamHandlerFunc
+func (f ParamHandlerFunc) ServeHTTPParams(w http.ResponseWriter, r *http.Request, ps PathParams) {
+	f(w, r, ps)
+}
+
+// ServeHTTP calls the function wrapped as a ParamHandlerFunc
+// with the path parameters of r
+func (f ParamHandlerFunc) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f(w, r, RequestParams(r))
+}
+
+// paramsKey is the request context key under
+// which matched path parameters are stored
+type paramsKey struct{}
+
+// RequestParams returns the path parameters matched for r. The
+// returned PathParams must not be modified
+func RequestParams(r *http.Request) PathParams {
+	ps, _ := r.Context().Value(paramsKey{}).(PathParams)
+	return ps
+}
+
+// Param returns the value of the path parameter name matched
+// for r or an empty string if no such parameter was matched.
+// Path parameters are kept separate from query and body values
+// so that they never collide.
+func Param(r *http.Request, name string) string {
+	return RequestParams(r).ByName(name)
+}
+
+// Params returns the path parameters matched for r keyed by
+// name. The returned map is a copy and may be modified
+func Params(r *http.Request) map[string]string {
+	ps := RequestParams(r)
+	m := make(map[string]string, len(ps))
+	for _, p := range ps {
+		m[p.Key] = p.Value
+	}
+	return m
+}
+
+// WithParams returns a shallow copy of r carrying the path parameters
+// given as name value pairs, e.g. to test handlers outside of a muxer
+func WithParams(r *http.Request, params ...string) *http.Request {
+	ps := make(PathParams, 0, len(params)/2)
+	for i := 0; i+1 < len(params); i += 2 {
+		ps = append(ps, PathParam{params[i], params[i+1]})
+	}
+	return withParams(r, ps)
+}
+
+// withParams returns a shallow copy of r carrying a copy of ps
+// in addition to the parameters matched by enclosing groups
+func withParams(r *http.Request, ps PathParams) *http.Request {
+	outer := RequestParams(r)
+	ps = append(append(make(PathParams, 0, len(outer)+len(ps)), outer...), ps...)
+	return r.WithContext(context.WithValue(r.Context(), paramsKey{}, ps))
+}
+
+// formParams adds the path parameters of r to its form
+// values, parsing the form first
+func formParams(r *http.Request) {
+	ps := RequestParams(r)
+	if len(ps) == 0 {
+		return
+	}
+	if r.Form == nil && r.Header.Get("Content-Encoding") != "" {
+		// Leave encoded bodies unread for decoding plugins
+		r.Form = r.URL.Query()
+	} else {
+		r.ParseForm()
+	}
+	insertParams(ps, r.Form)
+}
@@ -0,0 +1,68 @@
+package mux
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestParams(t *testing.T) {
+	defer func() {
+		err := recover()
+		if err != nil {
+			t.Errorf(err.(error).Error())
+		}
+	}()
+
+	err := "Failed params."
+
+	var r *http.Request
+	pm := New()
+	pm.AddFunc("GET", "/users/{id}/posts/{post}", func(w http.ResponseWriter, req *http.Request) {
+		r = req
+	})
+	pm.Group("GET", "/users/{id}")
+
+	// Test params of nested groups and form values
+	req, _ := http.NewRequest("GET", "http://test.com/users/1/posts/2?id=q", nil)
+	pm.ServeHTTP(httptest.NewRecorder(), req)
+	if Param(r, "id") != "1" || Param(r, "post") != "2" || Param(r, "none") != "" {
+		t.Errorf(err)
+	}
+	if ps := Params(r); len(ps) != 2 || ps["id"] != "1" || ps["post"] != "2" {
+		t.Errorf(err)
+	}
+	if len(r.Form["id"]) != 2 {
+		t.Errorf(err)
+	}
+
+	// Test params are kept out of the form
+	pm.FormParams = false
+	req, _ = http.NewRequest("GET", "http://test.com/users/1/posts/2?id=q", nil)
+	pm.ServeHTTP(httptest.NewRecorder(), req)
+	if Param(r, "id") != "1" || r.Form != nil || r.URL.Query().Get("id") != "q" {
+		t.Errorf(err)
+	}
+
+	// Test positional params never touch the form
+	pm.FormParams = true
+	var ps PathParams
+	pm.AddParamHandler("GET", "/users/{id}/posts/{post}", ParamHandlerFunc(
+		func(w http.ResponseWriter, req *http.Request, params PathParams) {
+			r, ps = req, params
+		}))
+	req, _ = http.NewRequest("GET", "http://test.com/users/1/posts/2", nil)
+	pm.ServeHTTP(httptest.NewRecorder(), req)
+	if len(ps) != 2 || ps[0] != (PathParam{"id", "1"}) || ps[1].Value != "2" || ps.ByName("post") != "2" {
+		t.Errorf(err)
+	}
+	if r.Form != nil {
+		t.Errorf(err)
+	}
+
+	// Test params set outside of a muxer
+	req = WithParams(req, "id", "3")
+	if Param(req, "id") != "3" || Param(req, "post") != "" {
+		t.Errorf(err)
+	}
+}
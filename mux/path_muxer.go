@@ -7,12 +7,15 @@ package mux
 // wildcards and regex routes.
 
 import (
-	"bytes"
 	"fmt"
 	"net/http"
 	"net/url"
 	"path"
+	"regexp"
+	"sort"
+	"strconv"
 	"strings"
+	"sync"
 )
 
 // ---------------------------------
@@ -25,15 +28,52 @@ type PathMuxer struct {
 	chain    *plugins
 	compiled *plugins
 	methods  map[string]*group
+	names    map[string]*endpoint
+	hosts    []*host
+	parent   *PathMuxer
+	host     string
+
+	// mutex guards the route table against removals
+	// and the host list against additions while
+	// requests are matched
+	mutex sync.RWMutex
 
 	NotFound       http.Handler
 	NotImplemented http.Handler
 	Redirect       http.Handler
 
+	// MethodNotAllowed handles requests for paths registered only
+	// under other methods. The Allow header listing those methods
+	// is set before the handler is called.
+	MethodNotAllowed http.Handler
+
 	// If strict, Paths with trailing slashes are considered
 	// a different path than those without trailing slashes.
 	// E.g. '/a/b/' != '/a/b'.
 	Strict bool
+
+	// If AutoOptions, OPTIONS requests for paths without an
+	// OPTIONS handler are answered with a 204 status and an
+	// Allow header listing the methods registered for the path.
+	// Global plugins are run before the response is written.
+	AutoOptions bool
+
+	// Options, if set, writes the responses to OPTIONS requests answered
+	// because of AutoOptions in place of empty 204 responses. The Allow
+	// header is set before the handler is called and the innermost group
+	// of the path's routes is retrievable through GroupMeta.
+	Options http.Handler
+
+	// If AutoHead, HEAD requests for paths without a HEAD handler
+	// are served by the path's GET handler. The response body is
+	// discarded while headers and Content-Length are preserved.
+	AutoHead bool
+
+	// If FormParams, matched path parameters are also added to
+	// the request's Form, parsing it first, as in earlier versions.
+	// Path parameters are always available through Param and Params.
+	// Defaults to true
+	FormParams bool
 }
 
 // New returns a pointer to a newly initialized PathMuxer.
@@ -41,12 +81,15 @@ func New() *PathMuxer {
 	muxer := PathMuxer{
 		chain:   newPlugins(),
 		methods: make(map[string]*group),
+		names:   make(map[string]*endpoint),
 
-		NotFound:       NotFoundHandler{},
-		NotImplemented: NotImplementedHandler{},
-		Redirect:       RedirectHandler{},
+		NotFound:         NotFoundHandler{},
+		NotImplemented:   NotImplementedHandler{},
+		Redirect:         RedirectHandler{},
+		MethodNotAllowed: MethodNotAllowedHandler{},
 
-		Strict: true,
+		Strict:     true,
+		FormParams: true,
 	}
 
 	return &muxer
@@ -69,6 +112,35 @@ func (mux *PathMuxer) Add(method, path string, handler http.Handler) Endpoint {
 	return g.Add(path, handler)
 }
 
+// AddParamHandler sets handler as the handler for a specific
+// method+path combination like Add. The matched path parameters are
+// passed to the handler directly and never added to the request's
+// form, so no form parsing or url.Values allocation takes place
+// regardless of FormParams.
+func (mux *PathMuxer) AddParamHandler(method, path string, handler ParamHandler) Endpoint {
+	ep := mux.Add(method, path, ParamHandlerFunc(handler.ServeHTTPParams))
+	ep.(*endpoint).rawParams = true
+	return ep
+}
+
+// Methods are the methods Any registers
+// handlers for if no methods are given
+var Methods = []string{"GET", "HEAD", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"}
+
+// Any sets handler as the handler of path for each of methods or,
+// if no methods are given, for each of Methods. The endpoints share
+// the handler and are returned in the order of the methods.
+func (mux *PathMuxer) Any(path string, handler http.Handler, methods ...string) []Endpoint {
+	if len(methods) == 0 {
+		methods = Methods
+	}
+	endpoints := make([]Endpoint, len(methods))
+	for i, method := range methods {
+		endpoints[i] = mux.Add(method, path, handler)
+	}
+	return endpoints
+}
+
 // AddFunc wraps f as an http.Handler and set is as handler for a specific method+path
 // combination. AddFunc returns the endpoint node.
 func (mux *PathMuxer) AddFunc(method, path string, f func(w http.ResponseWriter, r *http.Request)) Endpoint {
@@ -103,14 +175,150 @@ func (mux *PathMuxer) Group(method, path string) Group {
 	return g.Group(path)
 }
 
+// Remove removes the endpoint registered for the method+path
+// combination and returns whether one was registered. Path must
+// be the route's pattern, e.g. "/users/{id}". Routes under path
+// are unaffected. Remove is safe to call while the muxer is serving
+func (mux *PathMuxer) Remove(method, path string) bool {
+	mux.mutex.Lock()
+	defer mux.mutex.Unlock()
+
+	g, ok := mux.methods[method]
+	if !ok {
+		return false
+	}
+	ep := g.lookup(cleanPath(path))
+	if ep == nil {
+		return false
+	}
+	ep.remove()
+	return true
+}
+
+// Match returns the route serving requests for path under method or
+// nil if there is none. Path is a request path rather than a pattern
+func (mux *PathMuxer) Match(method, path string) Route {
+	g, ok := mux.methods[method]
+	if !ok {
+		return nil
+	}
+	if ep := g.find(cleanPath(path)); ep != nil {
+		return ep.route
+	}
+	return nil
+}
+
+// Routes returns every route registered with the muxer and its
+// host muxers sorted by host, with the muxer's own routes first,
+// and then by path and method
+func (mux *PathMuxer) Routes() []Route {
+	seen := make(map[*endpoint]bool)
+	endpoints := make([]*endpoint, 0)
+	for _, g := range mux.methods {
+		g.endpoints(func(ep *endpoint) {
+			if !seen[ep] {
+				seen[ep] = true
+				endpoints = append(endpoints, ep)
+			}
+		})
+	}
+	sort.Slice(endpoints, func(i, j int) bool {
+		pi, pj := endpoints[i].fullPath(), endpoints[j].fullPath()
+		if pi != pj {
+			return pi < pj
+		}
+		return endpoints[i].method < endpoints[j].method
+	})
+
+	routes := make([]Route, len(endpoints))
+	for i, ep := range endpoints {
+		routes[i] = ep.route
+	}
+
+	hosts := mux.hostList()
+	sort.SliceStable(hosts, func(i, j int) bool {
+		return hosts[i].pattern < hosts[j].pattern
+	})
+	for _, h := range hosts {
+		routes = append(routes, h.mux.Routes()...)
+	}
+	return routes
+}
+
+// Groups returns every group created on the muxer
+// sorted by path and then method
+func (mux *PathMuxer) Groups() []Group {
+	groups := make([]*group, 0)
+	for _, g := range mux.methods {
+		g.groups(func(g *group) {
+			groups = append(groups, g)
+		})
+	}
+	sort.Slice(groups, func(i, j int) bool {
+		if groups[i].fullPath != groups[j].fullPath {
+			return groups[i].fullPath < groups[j].fullPath
+		}
+		return groups[i].method < groups[j].method
+	})
+
+	result := make([]Group, len(groups))
+	for i, g := range groups {
+		result[i] = g
+	}
+	return result
+}
+
+// URL builds the path of the route registered under name. Params are
+// key-value pairs substituted for the route's named parameters. The
+// remainder of a catch-all route is supplied with the key '^'.
+// An error is returned if no route is registered under name, if a
+// parameter is missing or if a parameter does not match the pattern
+// of a regular expression parameter. Names of the routes of host
+// muxers are resolved if no route of the muxer itself has the name.
+func (mux *PathMuxer) URL(name string, params ...string) (string, error) {
+	ep, ok := mux.lookupName(name)
+	if !ok {
+		return "", fmt.Errorf("mux: no route named %q", name)
+	}
+	if len(params)%2 != 0 {
+		return "", fmt.Errorf("mux: odd number of parameters for route %q", name)
+	}
+	values := make(map[string]string, len(params)/2)
+	for i := 0; i < len(params); i += 2 {
+		values[params[i]] = params[i+1]
+	}
+
+	segments := strings.Split(ep.fullPath(), "/")
+	for i, s := range segments {
+		if s == catchAll {
+			segments = segments[:i+1]
+			segments[i] = strings.TrimPrefix(values[catchAll], "/")
+			break
+		}
+		if !strings.HasPrefix(s, "{") || !strings.HasSuffix(s, "}") {
+			continue
+		}
+		parts := strings.SplitN(s[1:len(s)-1], ":", 2)
+		key := strings.TrimSpace(parts[0])
+		value, ok := values[key]
+		if !ok {
+			return "", fmt.Errorf("mux: missing parameter %q for route %q", key, name)
+		}
+		if len(parts) == 2 {
+			if match, err := regexp.MatchString(strings.TrimSpace(parts[1]), value); err != nil || !match {
+				return "", fmt.Errorf("mux: parameter %q for route %q does not match %s", key, name, strings.TrimSpace(parts[1]))
+			}
+		}
+		segments[i] = url.PathEscape(value)
+	}
+	return strings.Join(segments, "/"), nil
+}
+
 // Use adds a plugin handler onto the end of the chain of global
 // plugins for the muxer.
 func (mux *PathMuxer) Use(handler PluginHandler) *PathMuxer {
 	mux.chain.use(handler)
-	for _, g := range mux.methods {
-		g.compile()
-	}
-
+	mux.compile()
 	return mux
 }
 
@@ -129,20 +337,132 @@ func (mux *PathMuxer) UseHandler(handler http.Handler) *PathMuxer {
 
 // ServeHTTP dispatches the correct handler for the route.
 func (mux *PathMuxer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if hm, ps := mux.matchHost(r); hm != nil {
+		if len(ps) > 0 {
+			r = withParams(r, ps)
+		}
+		hm.ServeHTTP(w, r)
+		return
+	}
+
+	base := mux.base()
 	if p := cleanPath(r.URL.Path); p != r.URL.Path {
 		r.URL.Path = p
-		mux.Redirect.ServeHTTP(w, r)
+		base.Redirect.ServeHTTP(w, r)
+		return
+	}
+
+	if r.Method == "OPTIONS" && base.AutoOptions && mux.serveOptions(w, r) {
+		return
+	}
+	if r.Method == "HEAD" && base.AutoHead && mux.serveHead(w, r) {
 		return
 	}
 
 	g, ok := mux.methods[r.Method]
 	if !ok {
-		mux.NotImplemented.ServeHTTP(w, r)
+		if !mux.methodNotAllowed(w, r) {
+			base.NotImplemented.ServeHTTP(w, r)
+		}
 		return
 	}
 	g.exec(w, r)
 }
 
+// Allowed returns the sorted methods under which a route matching
+// path is registered, including HEAD for GET routes if AutoHead is set
+func (mux *PathMuxer) Allowed(path string) []string {
+	path = cleanPath(path)
+	methods := make([]string, 0)
+	head, get := false, false
+	for method, g := range mux.methods {
+		if g.matches(path) {
+			methods = append(methods, method)
+			head = head || method == "HEAD"
+			get = get || method == "GET"
+		}
+	}
+	if mux.base().AutoHead && get && !head {
+		methods = append(methods, "HEAD")
+	}
+	sort.Strings(methods)
+	return methods
+}
+
+// methodNotAllowed serves a 405 response through the MethodNotAllowed
+// handler if the request path is registered under other methods and
+// returns whether it did
+func (mux *PathMuxer) methodNotAllowed(w http.ResponseWriter, r *http.Request) bool {
+	allowed := mux.Allowed(r.URL.Path)
+	handler := mux.base().MethodNotAllowed
+	if len(allowed) == 0 || handler == nil {
+		return false
+	}
+	w.Header().Set("Allow", strings.Join(allowed, ", "))
+	handler.ServeHTTP(w, r)
+	return true
+}
+
+// serveOptions answers an OPTIONS request for a path without an OPTIONS
+// handler with the methods registered for the path and returns whether
+// it did
+func (mux *PathMuxer) serveOptions(w http.ResponseWriter, r *http.Request) bool {
+	if g, ok := mux.methods["OPTIONS"]; ok && g.matches(r.URL.Path) {
+		return false
+	}
+	allowed := mux.Allowed(r.URL.Path)
+	if len(allowed) == 0 {
+		return false
+	}
+	allowed = append(allowed, "OPTIONS")
+	sort.Strings(allowed)
+
+	options := mux.base().Options
+	chain := mux.globalChain()
+	chain.use(PluginFunc(func(w http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+		w.Header().Set("Allow", strings.Join(allowed, ", "))
+		if options != nil {
+			options.ServeHTTP(w, r)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	for _, method := range allowed {
+		if g, ok := mux.methods[method]; ok {
+			if ep := g.find(r.URL.Path); ep != nil && ep.parent != nil {
+				r = ep.parent.attach(r)
+				break
+			}
+		}
+	}
+	chain.run(w, r)
+	return true
+}
+
+// serveHead serves a HEAD request for a path without a HEAD handler
+// through the path's GET handler and returns whether it did
+func (mux *PathMuxer) serveHead(w http.ResponseWriter, r *http.Request) bool {
+	if g, ok := mux.methods["HEAD"]; ok && g.matches(r.URL.Path) {
+		return false
+	}
+	g, ok := mux.methods["GET"]
+	if !ok || !g.matches(r.URL.Path) {
+		return false
+	}
+	hw := &headWriter{ResponseWriter: w, status: http.StatusOK}
+	g.exec(hw, r)
+	hw.finish()
+	return true
+}
+
+// notFound serves a 405 response if the request path is registered
+// under other methods and a 404 response otherwise
+func (mux *PathMuxer) notFound(w http.ResponseWriter, r *http.Request) {
+	if !mux.methodNotAllowed(w, r) {
+		mux.base().NotFound.ServeHTTP(w, r)
+	}
+}
+
 // -----------------------------
 // ---------- Helpers ----------
 
@@ -164,6 +484,51 @@ func (handler NotImplementedHandler) ServeHTTP(w http.ResponseWriter, r *http.Re
 	fmt.Fprintf(w, "Not Implemented.")
 }
 
+// MethodNotAllowedHandler is the default http.Handler for Method Not Allowed responses.
+// Returns a 405 status with message "Method Not Allowed."
+type MethodNotAllowedHandler struct{}
+
+func (handler MethodNotAllowedHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusMethodNotAllowed)
+	fmt.Fprintf(w, "Method Not Allowed.")
+}
+
+// headWriter is an http.ResponseWriter that discards the response
+// body of HEAD requests served by GET handlers. The status is held
+// back until the handler completes so that the Content-Length of
+// the discarded body can be set
+type headWriter struct {
+	http.ResponseWriter
+
+	status  int
+	n       int64
+	flushed bool
+}
+
+func (w *headWriter) WriteHeader(code int) {
+	if !w.flushed {
+		w.status = code
+	}
+}
+
+func (w *headWriter) Write(b []byte) (int, error) {
+	w.n += int64(len(b))
+	return len(b), nil
+}
+
+// finish writes the held back status with
+// the Content-Length of the discarded body
+func (w *headWriter) finish() {
+	if w.flushed {
+		return
+	}
+	w.flushed = true
+	if w.Header().Get("Content-Length") == "" && w.n > 0 {
+		w.Header().Set("Content-Length", strconv.FormatInt(w.n, 10))
+	}
+	w.ResponseWriter.WriteHeader(w.status)
+}
+
 // RedirectHandler is the default http.Handler for Redirect responses. Returns a 301 status and redirects
 // to the URL stored in r. This handler assumes the necessary adjustments to r.URL
 // have been made prior to calling the handler.
@@ -175,12 +540,12 @@ func (handler RedirectHandler) ServeHTTP(w http.ResponseWriter, r *http.Request)
 }
 
 // Inserts parameters into a parameter map
-func insertParams(params []param, values url.Values) {
+func insertParams(params PathParams, values url.Values) {
 	if len(params) == 0 {
 		return
 	}
 	for _, v := range params {
-		values.Add(v.key, v.value)
+		values.Add(v.Key, v.Value)
 	}
 }
 
@@ -382,10 +747,8 @@ func trimPathPrefix(path, prefix string, skipWild bool) string {
 	if i < len(prefix) {
 		return path
 	}
-
-	var buf bytes.Buffer
-	for ; j < len(path); j++ {
-		buf.WriteByte(byte(path[j]))
+	if j > len(path) {
+		return ""
 	}
-	return buf.String()
+	return path[j:]
 }
@@ -4,6 +4,7 @@ package mux
 import (
 	"net/http"
 	"net/http/httptest"
+	"strconv"
 	"testing"
 )
 
@@ -415,6 +416,81 @@ func TestPathMuxerUseHandler(t *testing.T) {
 	pm.chain.run(nil, r)
 }
 
+func TestPathMuxerURL(t *testing.T) {
+	defer func() {
+		err := recover()
+		if err != nil {
+			t.Errorf(err.(error).Error())
+		}
+	}()
+
+	err := "Failed path muxer URL."
+
+	pm := New()
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+	pm.Add("GET", "/users/{id: ^[0-9]+$}/posts/{post}", handler).Name("post.show")
+	pm.Group("GET", "/files").Add("/^", handler).Name("files")
+
+	url, e := pm.URL("post.show", "id", "42", "post", "a b")
+	if e != nil || url != "/users/42/posts/a%20b" {
+		t.Errorf(err)
+	}
+	url, e = pm.URL("files", "^", "/a/b.txt")
+	if e != nil || url != "/files/a/b.txt" {
+		t.Errorf(err)
+	}
+	if _, e = pm.URL("post.show", "id", "42"); e == nil {
+		t.Errorf(err)
+	}
+	if _, e = pm.URL("post.show", "id", "abc", "post", "a"); e == nil {
+		t.Errorf(err)
+	}
+	if _, e = pm.URL("missing"); e == nil {
+		t.Errorf(err)
+	}
+
+	// Test name is visible on the matched route
+	name := ""
+	pm.Add("GET", "/named", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		name = CurrentRoute(r).Name()
+	})).Name("named")
+	r, _ := http.NewRequest("GET", "http://test.com/named", nil)
+	pm.ServeHTTP(httptest.NewRecorder(), r)
+	if name != "named" {
+		t.Errorf(err)
+	}
+}
+
+func TestPathMuxerRoutes(t *testing.T) {
+	defer func() {
+		err := recover()
+		if err != nil {
+			t.Errorf(err.(error).Error())
+		}
+	}()
+
+	err := "Failed path muxer routes."
+
+	pm := New()
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+	pm.Add("POST", "/a", handler)
+	pm.Add("GET", "/a", handler)
+	pm.Add("GET", "/a/{id}", handler)
+	pm.Add("GET", "/b/^", handler)
+	pm.Group("GET", "/a")
+
+	routes := pm.Routes()
+	expected := []string{"GET /a", "POST /a", "GET /a/{id}", "GET /b/^"}
+	if len(routes) != len(expected) {
+		t.Fatalf(err)
+	}
+	for i, r := range routes {
+		if r.Method()+" "+r.Path() != expected[i] {
+			t.Errorf(err)
+		}
+	}
+}
+
 func TestNotFoundHandler(t *testing.T) {
 	err := "Failed not found handler."
 
@@ -447,6 +523,183 @@ func TestNotImplementedHandler(t *testing.T) {
 	}
 }
 
+func TestPathMuxerAny(t *testing.T) {
+	defer func() {
+		err := recover()
+		if err != nil {
+			t.Errorf(err.(error).Error())
+		}
+	}()
+
+	err := "Failed path muxer any."
+
+	pm := New()
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(r.Method))
+	})
+	if len(pm.Any("/all", handler)) != len(Methods) || len(pm.Any("/some", handler, "GET", "PUT")) != 2 {
+		t.Errorf(err)
+	}
+	for _, method := range Methods {
+		r, _ := http.NewRequest(method, "http://test.com/all", nil)
+		w := httptest.NewRecorder()
+		pm.ServeHTTP(w, r)
+		if w.Code != 200 || w.Body.String() != method {
+			t.Errorf(err)
+		}
+	}
+	if allowed := pm.Allowed("/some"); len(allowed) != 2 || allowed[0] != "GET" || allowed[1] != "PUT" {
+		t.Errorf(err)
+	}
+}
+
+func TestAutoOptions(t *testing.T) {
+	defer func() {
+		err := recover()
+		if err != nil {
+			t.Errorf(err.(error).Error())
+		}
+	}()
+
+	err := "Failed auto options."
+
+	pm := New()
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(r.Method))
+	})
+	pm.Add("GET", "/users/{id}", handler)
+	pm.Add("PUT", "/users/{id}", handler)
+	pm.Add("OPTIONS", "/explicit", handler)
+	pm.Add("GET", "/explicit", handler)
+	pm.Use(PluginFunc(func(w http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+		w.Header().Set("X-Global", "1")
+		next(w, r)
+	}))
+
+	serve := func(path string) *httptest.ResponseRecorder {
+		r, _ := http.NewRequest("OPTIONS", "http://test.com"+path, nil)
+		w := httptest.NewRecorder()
+		pm.ServeHTTP(w, r)
+		return w
+	}
+
+	// Test disabled by default
+	if serve("/users/1").Code != 405 {
+		t.Errorf(err)
+	}
+
+	pm.AutoOptions = true
+	w := serve("/users/1")
+	if w.Code != 204 || w.Header().Get("Allow") != "GET, OPTIONS, PUT" || w.Header().Get("X-Global") != "1" {
+		t.Errorf(err)
+	}
+	if w = serve("/explicit"); w.Code != 200 || w.Body.String() != "OPTIONS" {
+		t.Errorf(err)
+	}
+	if serve("/missing").Code != 404 {
+		t.Errorf(err)
+	}
+}
+
+func TestAutoHead(t *testing.T) {
+	defer func() {
+		err := recover()
+		if err != nil {
+			t.Errorf(err.(error).Error())
+		}
+	}()
+
+	err := "Failed auto head."
+
+	pm := New()
+	pm.Add("GET", "/users/{id}", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-User", r.FormValue("id"))
+		w.WriteHeader(203)
+		w.Write([]byte("user"))
+	}))
+	pm.Add("HEAD", "/explicit", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Explicit", "1")
+	}))
+	pm.Add("GET", "/explicit", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	serve := func(path string) *httptest.ResponseRecorder {
+		r, _ := http.NewRequest("HEAD", "http://test.com"+path, nil)
+		w := httptest.NewRecorder()
+		pm.ServeHTTP(w, r)
+		return w
+	}
+
+	// Test disabled by default
+	if serve("/users/1").Code != 405 {
+		t.Errorf(err)
+	}
+
+	pm.AutoHead = true
+	w := serve("/users/1")
+	if w.Code != 203 || w.Body.Len() != 0 || w.Header().Get("Content-Length") != "4" || w.Header().Get("X-User") != "1" {
+		t.Errorf(err)
+	}
+	if serve("/explicit").Header().Get("X-Explicit") != "1" {
+		t.Errorf(err)
+	}
+	if serve("/missing").Code != 404 {
+		t.Errorf(err)
+	}
+	if allowed := pm.Allowed("/users/1"); len(allowed) != 2 || allowed[0] != "GET" || allowed[1] != "HEAD" {
+		t.Errorf(err)
+	}
+}
+
+func TestMethodNotAllowed(t *testing.T) {
+	defer func() {
+		err := recover()
+		if err != nil {
+			t.Errorf(err.(error).Error())
+		}
+	}()
+
+	err := "Failed method not allowed."
+
+	pm := New()
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+	pm.Add("GET", "/users/{id}", handler)
+	pm.Add("DELETE", "/users/{id}", handler)
+	pm.Group("PUT", "/users").Add("/{id}", handler)
+	pm.Add("POST", "/posts", handler)
+
+	serve := func(method, path string) *httptest.ResponseRecorder {
+		r, _ := http.NewRequest(method, "http://test.com"+path, nil)
+		w := httptest.NewRecorder()
+		pm.ServeHTTP(w, r)
+		return w
+	}
+
+	w := serve("POST", "/users/1")
+	if w.Code != 405 || w.Header().Get("Allow") != "DELETE, GET, PUT" || w.Body.String() != "Method Not Allowed." {
+		t.Errorf(err)
+	}
+	if w = serve("PATCH", "/users/1"); w.Code != 405 || w.Header().Get("Allow") != "DELETE, GET, PUT" {
+		t.Errorf(err)
+	}
+	if w = serve("GET", "/posts"); w.Code != 405 || w.Header().Get("Allow") != "POST" {
+		t.Errorf(err)
+	}
+	if w = serve("GET", "/comments"); w.Code != 404 || w.Header().Get("Allow") != "" {
+		t.Errorf(err)
+	}
+	if serve("PATCH", "/comments").Code != 501 {
+		t.Errorf(err)
+	}
+
+	// Test custom handler
+	pm.MethodNotAllowed = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(418)
+	})
+	if w = serve("POST", "/users/1"); w.Code != 418 || w.Header().Get("Allow") == "" {
+		t.Errorf(err)
+	}
+}
+
 func TestRedirectHandler(t *testing.T) {
 	err := "Failed not redirect handler."
 
@@ -537,10 +790,18 @@ func TestPathMuxerServeHTTP(t *testing.T) {
 		t.Errorf(err)
 	}
 
-	// Test not implemented
+	// Test method not allowed
 	w = httptest.NewRecorder()
 	r, _ = http.NewRequest("POST", "http://test.com/path/to/handler", nil)
 	pm.ServeHTTP(w, r)
+	if w.Code != 405 || w.Header().Get("Allow") != "GET" {
+		t.Error(err)
+	}
+
+	// Test not implemented
+	w = httptest.NewRecorder()
+	r, _ = http.NewRequest("POST", "http://test.com/nonexistent", nil)
+	pm.ServeHTTP(w, r)
 	if w.Code != 501 {
 		t.Error(err)
 	}
@@ -557,3 +818,297 @@ func TestPathMuxerServeHTTP(t *testing.T) {
 		t.Errorf(err)
 	}
 }
+
+func BenchmarkPathMuxerStatic(b *testing.B) {
+	mux := New()
+	mux.Add("GET", "/users", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	r, _ := http.NewRequest("GET", "http://test.com/users", nil)
+	w := httptest.NewRecorder()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		mux.ServeHTTP(w, r)
+	}
+}
+
+func BenchmarkPathMuxerParam(b *testing.B) {
+	mux := New()
+	mux.FormParams = false
+	mux.Add("GET", "/users/{id}", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	r, _ := http.NewRequest("GET", "http://test.com/users/42", nil)
+	w := httptest.NewRecorder()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		mux.ServeHTTP(w, r)
+	}
+}
+
+func TestPathMuxerRemove(t *testing.T) {
+	defer func() {
+		err := recover()
+		if err != nil {
+			t.Errorf(err.(error).Error())
+		}
+	}()
+
+	err := "Failed path muxer remove."
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+	})
+	status := func(mux *PathMuxer, method, path string) int {
+		r, _ := http.NewRequest(method, "http://test.com"+path, nil)
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, r)
+		return w.Code
+	}
+
+	mux := New()
+	mux.Add("GET", "/users", handler)
+	mux.Add("GET", "/users/{id}", handler).Name("user")
+	mux.Add("POST", "/users/{id}", handler)
+	api := mux.Group("GET", "/api")
+	api.Add("/a", handler)
+	api.Add("/b", handler)
+
+	// Test removing by pattern keeps subpaths and other methods
+	if mux.Remove("GET", "/users/42") || mux.Remove("PUT", "/users/{id}") {
+		t.Errorf(err)
+	}
+	if !mux.Remove("GET", "/users/{id}") {
+		t.Fatalf(err)
+	}
+	if status(mux, "GET", "/users/42") != http.StatusMethodNotAllowed ||
+		status(mux, "POST", "/users/42") != http.StatusTeapot ||
+		status(mux, "GET", "/users") != http.StatusTeapot {
+		t.Errorf(err)
+	}
+	if _, e := mux.URL("user", "id", "42"); e == nil {
+		t.Errorf(err)
+	}
+
+	// Test removing endpoints within groups and groups
+	if !mux.Remove("GET", "/api/a") || status(mux, "GET", "/api/a") != http.StatusNotFound {
+		t.Errorf(err)
+	}
+	api.Remove()
+	if status(mux, "GET", "/api/b") != http.StatusNotFound || len(mux.Groups()) != 0 {
+		t.Errorf(err)
+	}
+
+	// Test endpoints can be removed twice and re-added
+	ep := mux.Add("GET", "/c", handler)
+	ep.Remove()
+	ep.Remove()
+	if status(mux, "GET", "/c") != http.StatusNotFound {
+		t.Errorf(err)
+	}
+	mux.Add("GET", "/c", handler)
+	if status(mux, "GET", "/c") != http.StatusTeapot {
+		t.Errorf(err)
+	}
+
+	// Test removing the root group removes every route of the method
+	mux.Group("GET", "/").Remove()
+	if status(mux, "GET", "/users") != http.StatusNotFound || status(mux, "POST", "/users/1") != http.StatusTeapot {
+		t.Errorf(err)
+	}
+}
+
+func TestPathMuxerRemoveWhileServing(t *testing.T) {
+	defer func() {
+		err := recover()
+		if err != nil {
+			t.Errorf(err.(error).Error())
+		}
+	}()
+
+	mux := New()
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+	for _, p := range []string{"/a", "/b", "/c", "/d"} {
+		mux.Add("GET", p+"/{id}", handler)
+	}
+
+	// Run with -race to check removals are synchronized
+	done := make(chan bool)
+	go func() {
+		for _, p := range []string{"/a", "/b", "/c", "/d"} {
+			mux.Remove("GET", p+"/{id}")
+		}
+		done <- true
+	}()
+	for i := 0; i < 100; i++ {
+		r, _ := http.NewRequest("GET", "http://test.com/c/1", nil)
+		mux.ServeHTTP(httptest.NewRecorder(), r)
+	}
+	<-done
+	if len(mux.Routes()) != 0 {
+		t.Errorf("Failed path muxer remove while serving.")
+	}
+}
+
+func TestPathMuxerGroupMeta(t *testing.T) {
+	defer func() {
+		err := recover()
+		if err != nil {
+			t.Errorf(err.(error).Error())
+		}
+	}()
+
+	err := "Failed path muxer group meta."
+
+	mux := New()
+	meta := ""
+	mux.NotFound = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		value, _ := GroupMeta(r, "format")
+		meta, _ = value.(string)
+		w.WriteHeader(http.StatusNotFound)
+	})
+	mux.AddFunc("GET", "/", func(w http.ResponseWriter, r *http.Request) {})
+	api := mux.Group("GET", "/api").Meta("format", "json")
+	api.AddFunc("/users", func(w http.ResponseWriter, r *http.Request) {})
+	api.Group("/admin").AddFunc("/stats", func(w http.ResponseWriter, r *http.Request) {})
+
+	// Test unmatched requests carry the metadata of the
+	// innermost group, inherited from its parents
+	for path, expected := range map[string]string{
+		"/missing":           "",
+		"/api/missing":       "json",
+		"/api/admin/missing": "json",
+	} {
+		meta = "unset"
+		r, _ := http.NewRequest("GET", "http://test.com"+path, nil)
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, r)
+		if w.Code != 404 || meta != expected {
+			t.Errorf(err)
+		}
+	}
+}
+
+func TestPathMuxerMatch(t *testing.T) {
+	defer func() {
+		err := recover()
+		if err != nil {
+			t.Errorf(err.(error).Error())
+		}
+	}()
+
+	err := "Failed path muxer match."
+
+	mux := New()
+	mux.AddFunc("GET", "/users/{id}", func(w http.ResponseWriter, r *http.Request) {}).Name("user")
+	mux.Group("GET", "/api").AddFunc("/files/^", func(w http.ResponseWriter, r *http.Request) {})
+
+	if route := mux.Match("GET", "/users/1"); route == nil || route.Name() != "user" {
+		t.Errorf(err)
+	}
+	if route := mux.Match("GET", "/api/files/a/b"); route == nil || route.Path() != "/api/files/^" {
+		t.Errorf(err)
+	}
+	if mux.Match("POST", "/users/1") != nil || mux.Match("GET", "/missing") != nil {
+		t.Errorf(err)
+	}
+}
+
+func TestPathMuxerHost(t *testing.T) {
+	defer func() {
+		err := recover()
+		if err != nil {
+			t.Errorf(err.(error).Error())
+		}
+	}()
+
+	err := "Failed path muxer host."
+
+	mux := New()
+	api := mux.Host("api.example.com")
+	if mux.Host("api.example.com") != api {
+		t.Errorf(err)
+	}
+	api.AddFunc("GET", "/", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("api"))
+	})
+	mux.Host("{sub}.example.com").AddFunc("GET", "/", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(Param(r, "sub")))
+	})
+	// Global plugins added later apply to hosts
+	mux.UseHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Global", "1")
+	}))
+	mux.AutoOptions = true
+
+	serve := func(method, host string) *httptest.ResponseRecorder {
+		r, _ := http.NewRequest(method, "http://"+host+"/", nil)
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, r)
+		return w
+	}
+	if w := serve("GET", "api.example.com"); w.Body.String() != "api" || w.Header().Get("X-Global") != "1" {
+		t.Errorf(err)
+	}
+	if w := serve("GET", "docs.example.com:443"); w.Body.String() != "docs" {
+		t.Errorf(err)
+	}
+	// Other hosts are served by the muxer itself
+	if w := serve("GET", "example.com"); w.Code != 501 {
+		t.Errorf(err)
+	}
+	if w := serve("OPTIONS", "api.example.com"); w.Code != 204 || w.Header().Get("Allow") != "GET, OPTIONS" {
+		t.Errorf(err)
+	}
+}
+
+func TestPathMuxerHostRoutes(t *testing.T) {
+	defer func() {
+		err := recover()
+		if err != nil {
+			t.Errorf(err.(error).Error())
+		}
+	}()
+
+	err := "Failed path muxer host routes."
+
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+	mux := New()
+	mux.Add("GET", "/b", h)
+	mux.Host("{sub}.example.com").Add("GET", "/a", h)
+	mux.Host("api.example.com").Add("GET", "/users/{id}", h).Name("user")
+
+	// Test host routes are listed after the muxer's own routes
+	routes := mux.Routes()
+	if len(routes) != 3 {
+		t.Fatalf(err)
+	}
+	if routes[0].Path() != "/b" || routes[0].Host() != "" {
+		t.Errorf(err)
+	}
+	if routes[1].Path() != "/users/{id}" || routes[1].Host() != "api.example.com" {
+		t.Errorf(err)
+	}
+	if routes[2].Path() != "/a" || routes[2].Host() != "{sub}.example.com" {
+		t.Errorf(err)
+	}
+
+	// Test names of host routes are resolved
+	if url, e := mux.URL("user", "id", "7"); e != nil || url != "/users/7" {
+		t.Errorf(err)
+	}
+
+	// Test hosts can be added while requests are served
+	done := make(chan struct{})
+	go func() {
+		for i := 0; i < 100; i++ {
+			mux.Host(strconv.Itoa(i) + ".example.org")
+		}
+		close(done)
+	}()
+	for i := 0; i < 100; i++ {
+		r, _ := http.NewRequest("GET", "http://api.example.com/users/1", nil)
+		mux.ServeHTTP(httptest.NewRecorder(), r)
+	}
+	<-done
+}
@@ -28,6 +28,10 @@ type plugin struct {
 	handler PluginHandler
 	next    *plugin
 	prev    *plugin
+
+	// serve is the plugin's run method bound once so that
+	// running a chain does not allocate per plugin
+	serve http.HandlerFunc
 }
 
 // emptyPlugin represents an empty plugin with a no-op handler
@@ -35,11 +39,24 @@ var emptyPlugin = &plugin{
 	handler: PluginFunc(func(w http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
 
 	}),
+	serve: func(w http.ResponseWriter, r *http.Request) {},
+}
+
+// newPlugin returns a plugin running handler
+// that is not yet linked to other plugins
+func newPlugin(handler PluginHandler) *plugin {
+	p := &plugin{
+		handler: handler,
+		next:    emptyPlugin,
+		prev:    emptyPlugin,
+	}
+	p.serve = p.run
+	return p
 }
 
 // Run calls the plugin's handler passing it the next plugin in line
 func (p *plugin) run(w http.ResponseWriter, r *http.Request) {
-	p.handler.Handle(w, r, p.next.run)
+	p.handler.Handle(w, r, p.next.serve)
 }
 
 // plugins is a doubly-linked list of plugins
@@ -90,11 +107,7 @@ func (p *plugins) link(p2 *plugins) {
 func (p *plugins) use(handler PluginHandler) {
 	p.length = p.length + 1
 
-	plugin := &plugin{
-		handler: handler,
-		next:    emptyPlugin,
-		prev:    emptyPlugin,
-	}
+	plugin := newPlugin(handler)
 
 	if p.head == emptyPlugin {
 		p.head = plugin
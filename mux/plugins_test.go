@@ -26,11 +26,8 @@ func TestPluginRun(t *testing.T) {
 		tVal2 = "B"
 	})
 
-	p := &plugin{}
-	p2 := &plugin{}
-
-	p.handler = h
-	p2.handler = h2
+	p := newPlugin(h)
+	p2 := newPlugin(h2)
 	p.next = p2
 
 	p.run(nil, nil)
@@ -0,0 +1,94 @@
+package mux
+
+import (
+	"bytes"
+	"sort"
+	"strings"
+)
+
+// Kinds of Nodes in the routing tree
+const (
+	NodeGroup    = "group"
+	NodeEndpoint = "endpoint"
+)
+
+// Node describes a group or endpoint in the routing tree
+// of a PathMuxer as returned by Tree
+type Node struct {
+	// Kind is NodeGroup or NodeEndpoint
+	Kind string
+
+	// Method is the method the node was registered under
+	Method string
+
+	// Path is the path of the node relative to its group
+	Path string
+
+	// FullPath is the full path pattern of the node
+	FullPath string
+
+	// Children are the groups and endpoints of a group
+	// sorted by path
+	Children []Node
+}
+
+// String returns an indented rendering of
+// the node and its children
+func (n Node) String() string {
+	var buf bytes.Buffer
+	n.write(&buf, 0)
+	return buf.String()
+}
+
+func (n Node) write(buf *bytes.Buffer, depth int) {
+	buf.WriteString(strings.Repeat("  ", depth))
+	buf.WriteString(n.Path)
+	if n.Kind == NodeGroup {
+		buf.WriteString(" (group)")
+	}
+	buf.WriteString("\n")
+	for _, c := range n.Children {
+		c.write(buf, depth+1)
+	}
+}
+
+// Tree returns the routing tree for method. The root Node is the
+// method's root group with its path being an empty string. Tree
+// reflects how groups created after endpoints subsume them and
+// is intended for debugging and tests.
+func (mux *PathMuxer) Tree(method string) Node {
+	g, ok := mux.methods[method]
+	if !ok {
+		return Node{Kind: NodeGroup, Method: method}
+	}
+	return g.node()
+}
+
+// node returns the Node describing the group
+// and its subtree
+func (g *group) node() Node {
+	n := Node{
+		Kind:     NodeGroup,
+		Method:   g.method,
+		Path:     g.path,
+		FullPath: g.fullPath,
+		Children: make([]Node, 0),
+	}
+	g.matcher.apply(func(c compilable) {
+		switch c := c.(type) {
+		case *endpoint:
+			n.Children = append(n.Children, Node{
+				Kind:     NodeEndpoint,
+				Method:   c.method,
+				Path:     c.path,
+				FullPath: c.fullPath(),
+			})
+		case *group:
+			n.Children = append(n.Children, c.node())
+		}
+	})
+	sort.Slice(n.Children, func(i, j int) bool {
+		return n.Children[i].Path < n.Children[j].Path
+	})
+	return n
+}
@@ -0,0 +1,174 @@
+package mux
+
+import (
+	"fmt"
+	"math/rand"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestPathMuxerTree(t *testing.T) {
+	defer func() {
+		err := recover()
+		if err != nil {
+			t.Errorf(err.(error).Error())
+		}
+	}()
+
+	err := "Failed path muxer tree."
+
+	pm := New()
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+	pm.Add("GET", "/users/list", h)
+	pm.Add("GET", "/users/{id}/posts", h)
+	pm.Add("GET", "/users/{id}/posts/{post}", h)
+	pm.Add("GET", "/users/{id}/friends", h)
+	pm.Group("GET", "/users/{uid}")
+
+	tree := pm.Tree("GET")
+	expected := " (group)\n" +
+		"  /users/list\n" +
+		"  /users/{uid} (group)\n" +
+		"    /friends\n" +
+		"    /posts\n" +
+		"    /posts/{post}\n"
+	if tree.String() != expected {
+		t.Errorf(err)
+	}
+	if len(tree.Children) != 2 || tree.Children[1].Kind != NodeGroup ||
+		tree.Children[1].Children[1].FullPath != "/users/{uid}/posts" {
+		t.Errorf(err)
+	}
+	if pm.Tree("POST").Kind != NodeGroup || len(pm.Tree("POST").Children) != 0 {
+		t.Errorf(err)
+	}
+}
+
+func TestPathMuxerCatchAllAdd(t *testing.T) {
+	defer func() {
+		err := recover()
+		if err != nil {
+			t.Errorf(err.(error).Error())
+		}
+	}()
+
+	err := "Failed path muxer catch-all add."
+
+	pm := New()
+	served := ""
+	handler := func(name string) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			served = name
+		})
+	}
+	pm.Add("GET", "/files/^", handler("catchall"))
+	pm.Add("GET", "/files/{name}/meta", handler("meta"))
+	pm.Add("GET", "/files/index", handler("index"))
+
+	// Test patterns never overwrite the catch-all
+	if len(pm.Routes()) != 3 {
+		t.Errorf(err)
+	}
+	for path, name := range map[string]string{
+		"/files/a/meta": "meta",
+		"/files/index":  "index",
+	} {
+		r, _ := http.NewRequest("GET", "http://test.com"+path, nil)
+		pm.ServeHTTP(httptest.NewRecorder(), r)
+		if served != name {
+			t.Errorf(err)
+		}
+	}
+}
+
+// TestGroupRelocation registers random sets of static and wildcard
+// routes interleaved with groups created at random prefixes and checks
+// that every route remains registered and reachable afterwards
+func TestGroupRelocation(t *testing.T) {
+	defer func() {
+		err := recover()
+		if err != nil {
+			t.Errorf(err.(error).Error())
+		}
+	}()
+
+	err := "Failed group relocation."
+
+	segment := func(rnd *rand.Rand, depth int, wild string) string {
+		if rnd.Intn(3) == 0 {
+			return fmt.Sprintf("{%s%d}", wild, depth)
+		}
+		return string(rune('a' + rnd.Intn(3)))
+	}
+	path := func(rnd *rand.Rand, n int, wild string) string {
+		segments := make([]string, n)
+		for i := range segments {
+			segments[i] = segment(rnd, i, wild)
+		}
+		return "/" + strings.Join(segments, "/")
+	}
+
+	wildcards := regexp.MustCompile(`\{[^}]*\}`)
+	for seed := int64(0); seed < 500; seed++ {
+		rnd := rand.New(rand.NewSource(seed))
+		pm := New()
+		groups := make([]Group, 0)
+		served := -1
+		expected := make(map[string]int)
+
+		for op := 0; op < 30; op++ {
+			// Groups and routes are created on the muxer or,
+			// relative to their path, on a previously created group
+			var g Group
+			if len(groups) > 0 && rnd.Intn(4) == 0 {
+				g = groups[rnd.Intn(len(groups))]
+			}
+			if rnd.Intn(10) < 3 {
+				p := path(rnd, 1+rnd.Intn(3), "g")
+				if g != nil {
+					groups = append(groups, g.Group(p))
+				} else {
+					groups = append(groups, pm.Group("GET", p))
+				}
+				continue
+			}
+
+			p := path(rnd, 1+rnd.Intn(4), "w")
+			id := op
+			handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				served = id
+			})
+			if g != nil {
+				g.Add(p, handler)
+				p = g.Path() + p
+			} else {
+				pm.Add("GET", p, handler)
+			}
+			expected[wildcards.ReplaceAllString(p, "v")] = id
+		}
+
+		// Routes may have been renamed by groups
+		// so compare wildcard-free request paths
+		if len(pm.Routes()) != len(expected) {
+			t.Errorf("%s (seed %d)\n%s", err, seed, pm.Tree("GET"))
+			continue
+		}
+		for p, id := range expected {
+			r, _ := http.NewRequest("GET", "http://test.com"+p, nil)
+			served = -1
+			pm.ServeHTTP(httptest.NewRecorder(), r)
+			if served != id {
+				t.Errorf("%s (seed %d, %s)\n%s", err, seed, p, pm.Tree("GET"))
+				break
+			}
+		}
+		for _, rt := range pm.Routes() {
+			if _, ok := expected[wildcards.ReplaceAllString(rt.Path(), "v")]; !ok {
+				t.Errorf("%s (seed %d, %s)\n%s", err, seed, rt.Path(), pm.Tree("GET"))
+			}
+		}
+	}
+}
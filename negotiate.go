@@ -0,0 +1,107 @@
+package verto
+
+import (
+	"fmt"
+	"html"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// fallback returns the handler answering requests that match no route
+// (or a route without a handler) with status. The response is rendered
+// by the ErrorHandler resolved for the request so that fallback responses
+// are formatted like any other error response of the route or Group
+func (v *Verto) fallback(status int) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		c := v.context(w, r)
+		v.errorHandler(c).Handle(NewError(status, ""), c)
+	})
+}
+
+// NegotiatedErrorFunc renders errors in the format preferred by the
+// Accept header of the request: problem documents (see ProblemHandler)
+// for clients preferring JSON, HTML pages (see HTMLErrorFunc) for
+// browsers and plain text through DefaultErrorFunc otherwise.
+//
+// Example usage:
+//
+//	v.ErrorHandler = verto.ErrorFunc(verto.NegotiatedErrorFunc)
+func NegotiatedErrorFunc(err error, c *Context) {
+	c.Response.Header().Add("Vary", "Accept")
+	offer := preferredType(c.Request.Header.Get("Accept"),
+		"text/plain", "application/json", ProblemContentType, "text/html")
+	switch offer {
+	case "application/json", ProblemContentType:
+		ProblemJSONErrorFunc(err, c)
+	case "text/html":
+		HTMLErrorFunc(err, c)
+	default:
+		DefaultErrorFunc(err, c)
+	}
+}
+
+// HTMLErrorFunc renders errors as minimal HTML pages. The status, title
+// and detail of a page are those of the problem document of the error
+// (see NewProblem) so that messages are disclosed alike
+func HTMLErrorFunc(err error, c *Context) {
+	if err == ErrClientClosed {
+		return
+	}
+	p := NewProblem(err, c, DefaultProblems)
+
+	c.Response.Header().Set("Content-Type", "text/html; charset=utf-8")
+	c.Response.WriteHeader(p.Status)
+	title := html.EscapeString(p.Title)
+	fmt.Fprintf(c.Response, "<!DOCTYPE html>\n<html>\n<head><title>%d %s</title></head>\n<body>\n<h1>%s</h1>\n",
+		p.Status, title, title)
+	if p.Detail != "" {
+		fmt.Fprintf(c.Response, "<p>%s</p>\n", html.EscapeString(p.Detail))
+	}
+	fmt.Fprint(c.Response, "</body>\n</html>\n")
+}
+
+// preferredType returns the offered media type with the highest quality
+// in the Accept header value accept, honoring wildcards. Ties are resolved
+// in favor of earlier offers. Returns the empty string if no offer is
+// acceptable
+func preferredType(accept string, offers ...string) string {
+	best, bestQ := "", 0.0
+	for _, offer := range offers {
+		if q := typeQuality(accept, offer); q > bestQ {
+			best, bestQ = offer, q
+		}
+	}
+	return best
+}
+
+// typeQuality returns the quality of the media type offer in the Accept
+// header value accept. The most specific matching range determines
+// the quality
+func typeQuality(accept, offer string) float64 {
+	q, specificity := 0.0, -1
+	for _, e := range strings.Split(accept, ",") {
+		parts := strings.Split(e, ";")
+		mr := strings.ToLower(strings.TrimSpace(parts[0]))
+		s := 0
+		switch {
+		case mr == offer:
+			s = 2
+		case mr == offer[:strings.Index(offer, "/")+1]+"*":
+			s = 1
+		case mr != "*/*":
+			continue
+		}
+		if s <= specificity {
+			continue
+		}
+		specificity, q = s, 1
+		for _, p := range parts[1:] {
+			kv := strings.SplitN(strings.TrimSpace(p), "=", 2)
+			if len(kv) == 2 && strings.TrimSpace(kv[0]) == "q" {
+				q, _ = strconv.ParseFloat(strings.TrimSpace(kv[1]), 64)
+			}
+		}
+	}
+	return q
+}
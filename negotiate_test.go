@@ -0,0 +1,79 @@
+package verto
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestNegotiatedFallbacks(t *testing.T) {
+	defer func() {
+		err := recover()
+		if err != nil {
+			t.Errorf(err.(error).Error())
+		}
+	}()
+
+	err := "Failed negotiated fallbacks."
+
+	v := New()
+	v.Logger = &NilLogger{}
+	ok := func(c *Context) (interface{}, error) {
+		return "ok", nil
+	}
+	v.Get("/ok", ok)
+	api := v.Group("GET", "/api")
+	api.OnError(ErrorFunc(ProblemJSONErrorFunc))
+	api.Add("/users", ok)
+	api.Group("/admin").Add("/stats", ok)
+	h := &HttpHandler{v}
+
+	serve := func(method, path, accept string) *httptest.ResponseRecorder {
+		r, _ := http.NewRequest(method, "http://test.com"+path, nil)
+		if accept != "" {
+			r.Header.Set("Accept", accept)
+		}
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		return w
+	}
+
+	// Test fallbacks default to plain text
+	w := serve("GET", "/missing", "")
+	if w.Code != 404 || w.Body.String() != "Not Found." {
+		t.Errorf(err)
+	}
+	w = serve("POST", "/ok", "")
+	if w.Code != 405 || w.Header().Get("Allow") != "GET" || w.Body.String() != "Method Not Allowed." {
+		t.Errorf(err)
+	}
+
+	// Test fallbacks under a Group use the Group's ErrorHandler
+	// including those of sub-Groups
+	for _, path := range []string{"/api/missing", "/api/admin/missing"} {
+		w = serve("GET", path, "")
+		p := make(map[string]interface{})
+		json.Unmarshal(w.Body.Bytes(), &p)
+		if w.Code != 404 || w.Header().Get("Content-Type") != ProblemContentType || p["status"] != 404.0 {
+			t.Errorf(err)
+		}
+	}
+
+	// Test fallbacks are negotiated by the Accept header
+	v.ErrorHandler = ErrorFunc(NegotiatedErrorFunc)
+	w = serve("GET", "/missing", "application/json")
+	if w.Code != 404 || w.Header().Get("Content-Type") != ProblemContentType {
+		t.Errorf(err)
+	}
+	w = serve("GET", "/missing", "text/html,application/xhtml+xml,*/*;q=0.8")
+	if w.Code != 404 || !strings.HasPrefix(w.Header().Get("Content-Type"), "text/html") ||
+		!strings.Contains(w.Body.String(), "<h1>Not Found</h1>") {
+		t.Errorf(err)
+	}
+	w = serve("GET", "/missing", "*/*")
+	if w.Code != 404 || w.Body.String() != "Not Found." || w.Header().Get("Vary") != "Accept" {
+		t.Errorf(err)
+	}
+}
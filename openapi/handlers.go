@@ -0,0 +1,69 @@
+package openapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/boxtown/verto"
+	"html"
+	"net/http"
+	"strings"
+)
+
+// SwaggerUIAssets is the base URL the Swagger UI page
+// served by Register loads the swagger-ui-dist assets from
+var SwaggerUIAssets = "https://unpkg.com/swagger-ui-dist@5"
+
+// Register registers documentation endpoints under prefix on v:
+//
+//	GET prefix/openapi.json  returns the OpenAPI document of v's routes
+//	GET prefix/docs          serves a Swagger UI page for the document
+//
+// The document is generated for every request so that it reflects
+// routes added or removed later on. The endpoints are left out of
+// the document
+func Register(v *verto.Verto, prefix string, info Info) {
+	prefix = strings.TrimRight(prefix, "/")
+	spec := prefix + "/openapi.json"
+	v.AddHandler("GET", spec, Handler(v, info)).Meta(HiddenKey, true)
+	v.AddHandler("GET", prefix+"/docs", SwaggerUI(spec)).Meta(HiddenKey, true)
+}
+
+// Handler returns an http.Handler serving the OpenAPI document of v's routes
+func Handler(v *verto.Verto, info Info) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(Generate(v.Routes(), info))
+	})
+}
+
+// SwaggerUI returns an http.Handler serving a Swagger UI page
+// for the OpenAPI document at url. The page is embedded in the
+// handler and loads its assets from SwaggerUIAssets
+func SwaggerUI(url string) http.Handler {
+	assets := html.EscapeString(strings.TrimRight(SwaggerUIAssets, "/"))
+	// encoding/json escapes '<' so the URL cannot end the script
+	spec, _ := json.Marshal(url)
+	page := fmt.Sprintf(swaggerUIPage, assets, assets, spec)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		fmt.Fprint(w, page)
+	})
+}
+
+// swaggerUIPage is the Swagger UI page template
+const swaggerUIPage = `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>API Documentation</title>
+<link rel="stylesheet" href="%s/swagger-ui.css">
+</head>
+<body>
+<div id="swagger-ui"></div>
+<script src="%s/swagger-ui-bundle.js"></script>
+<script>
+window.ui = SwaggerUIBundle({url: %s, dom_id: "#swagger-ui"});
+</script>
+</body>
+</html>
+`
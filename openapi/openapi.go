@@ -0,0 +1,213 @@
+// Package openapi generates OpenAPI 3 documents from a Verto route table.
+// Every route becomes an operation of the document. Path parameters are
+// documented from route patterns, including their regex constraints,
+// and request and response bodies from prototype values attached to
+// routes as metadata with the keys shared with package sdkgen.
+//
+// Example usage:
+//
+//	v.Get("/users/{id: ^[0-9]+$}", getUser).Name("user.show").
+//		Meta(openapi.ResponseKey, User{})
+//	v.Post("/users", createUser).Name("user.create").
+//		Meta(openapi.RequestKey, User{}).
+//		Meta(openapi.ResponseKey, User{})
+//
+//	openapi.Register(v, "", openapi.Info{Title: "Users", Version: "1.0.0"})
+package openapi
+
+import (
+	"github.com/boxtown/verto/mux"
+	"github.com/boxtown/verto/sdkgen"
+	"reflect"
+	"strings"
+)
+
+// Version is the OpenAPI version of generated documents
+const Version = "3.0.3"
+
+// RequestKey is the route metadata key for a prototype
+// value of the route's request body type
+const RequestKey = sdkgen.RequestKey
+
+// ResponseKey is the route metadata key for a prototype
+// value of the route's response body type
+const ResponseKey = sdkgen.ResponseKey
+
+// HiddenKey is the route metadata key marking routes
+// left out of generated documents if set to true
+const HiddenKey = "openapi.hidden"
+
+// Document is an OpenAPI document
+type Document struct {
+	OpenAPI    string              `json:"openapi"`
+	Info       Info                `json:"info"`
+	Paths      map[string]PathItem `json:"paths"`
+	Components *Components         `json:"components,omitempty"`
+}
+
+// Info is the metadata of the API described by a Document
+type Info struct {
+	Title       string `json:"title"`
+	Version     string `json:"version"`
+	Description string `json:"description,omitempty"`
+}
+
+// PathItem maps the lower-case methods of a path to their Operations
+type PathItem map[string]*Operation
+
+// Operation describes a route
+type Operation struct {
+	OperationID string               `json:"operationId,omitempty"`
+	Parameters  []*Parameter         `json:"parameters,omitempty"`
+	RequestBody *RequestBody         `json:"requestBody,omitempty"`
+	Responses   map[string]*Response `json:"responses"`
+	Servers     []*Server            `json:"servers,omitempty"`
+}
+
+// Server describes the host serving an operation of a host route.
+// Parameters of the host pattern become server variables
+type Server struct {
+	URL       string                     `json:"url"`
+	Variables map[string]*ServerVariable `json:"variables,omitempty"`
+}
+
+// ServerVariable describes a parameter of a host pattern
+type ServerVariable struct {
+	Default string `json:"default"`
+}
+
+// Parameter describes a path parameter of a route
+type Parameter struct {
+	Name     string  `json:"name"`
+	In       string  `json:"in"`
+	Required bool    `json:"required"`
+	Schema   *Schema `json:"schema"`
+}
+
+// RequestBody describes the request body of a route
+type RequestBody struct {
+	Required bool                  `json:"required"`
+	Content  map[string]*MediaType `json:"content"`
+}
+
+// Response describes a response of a route
+type Response struct {
+	Description string                `json:"description"`
+	Content     map[string]*MediaType `json:"content,omitempty"`
+}
+
+// MediaType describes a body of a media type
+type MediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+// Components holds the schemas of the named struct types
+// referenced by the operations of a Document
+type Components struct {
+	Schemas map[string]*Schema `json:"schemas"`
+}
+
+// Generate returns the OpenAPI document describing routes. Operations
+// of host routes carry the host as their server. Of routes of different
+// hosts sharing a method and path, only the first is described
+func Generate(routes []mux.Route, info Info) *Document {
+	doc := &Document{OpenAPI: Version, Info: info, Paths: make(map[string]PathItem)}
+	schemas := newSchemas()
+	for _, r := range routes {
+		if hidden, _ := r.Meta(HiddenKey); hidden == true {
+			continue
+		}
+		path, params := parsePath(r.Path())
+		op := &Operation{
+			OperationID: r.Name(),
+			Parameters:  params,
+			Responses:   map[string]*Response{"200": {Description: "OK"}},
+		}
+		if v, ok := r.Meta(RequestKey); ok && v != nil {
+			op.RequestBody = &RequestBody{
+				Required: true,
+				Content:  jsonContent(schemas.of(reflect.TypeOf(v))),
+			}
+		}
+		if v, ok := r.Meta(ResponseKey); ok && v != nil {
+			op.Responses["200"].Content = jsonContent(schemas.of(reflect.TypeOf(v)))
+		}
+		if host := r.Host(); host != "" {
+			op.Servers = []*Server{hostServer(host)}
+		}
+
+		item, ok := doc.Paths[path]
+		if !ok {
+			item = make(PathItem)
+			doc.Paths[path] = item
+		}
+		method := strings.ToLower(r.Method())
+		if _, ok := item[method]; !ok {
+			item[method] = op
+		}
+	}
+	if len(schemas.named) > 0 {
+		doc.Components = &Components{Schemas: schemas.named}
+	}
+	return doc
+}
+
+// hostServer returns the Server of the host pattern. Parameter
+// regexes are dropped and parameters default to their names
+func hostServer(pattern string) *Server {
+	s := &Server{}
+	labels := strings.Split(pattern, ".")
+	for i, l := range labels {
+		if !strings.HasPrefix(l, "{") || !strings.HasSuffix(l, "}") {
+			continue
+		}
+		name := strings.TrimSpace(strings.SplitN(l[1:len(l)-1], ":", 2)[0])
+		if s.Variables == nil {
+			s.Variables = make(map[string]*ServerVariable)
+		}
+		s.Variables[name] = &ServerVariable{Default: name}
+		labels[i] = "{" + name + "}"
+	}
+	s.URL = "//" + strings.Join(labels, ".")
+	return s
+}
+
+// parsePath returns the OpenAPI path template of the route path
+// pattern and its path parameters. Parameter regexes become the
+// patterns of the parameters and the catch-all is named 'rest'
+func parsePath(pattern string) (string, []*Parameter) {
+	params := make([]*Parameter, 0)
+	segments := strings.Split(pattern, "/")
+	for i, s := range segments {
+		if s == "^" {
+			params = append(params, pathParam("rest", ""))
+			segments = append(segments[:i], "{rest}")
+			break
+		}
+		if strings.HasPrefix(s, "{") && strings.HasSuffix(s, "}") {
+			parts := strings.SplitN(s[1:len(s)-1], ":", 2)
+			name, regex := strings.TrimSpace(parts[0]), ""
+			if len(parts) == 2 {
+				regex = strings.TrimSpace(parts[1])
+			}
+			params = append(params, pathParam(name, regex))
+			segments[i] = "{" + name + "}"
+		}
+	}
+	return strings.Join(segments, "/"), params
+}
+
+// pathParam returns a required string path parameter
+func pathParam(name, pattern string) *Parameter {
+	return &Parameter{
+		Name:     name,
+		In:       "path",
+		Required: true,
+		Schema:   &Schema{Type: "string", Pattern: pattern},
+	}
+}
+
+// jsonContent returns the JSON content map for schema
+func jsonContent(schema *Schema) map[string]*MediaType {
+	return map[string]*MediaType{"application/json": {Schema: schema}}
+}
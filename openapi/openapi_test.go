@@ -0,0 +1,135 @@
+package openapi
+
+import (
+	"encoding/json"
+	"github.com/boxtown/verto"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+type testUser struct {
+	Id      int               `json:"id"`
+	Name    string            `json:"name,omitempty"`
+	Tags    []string          `json:"tags"`
+	Labels  map[string]string `json:"labels"`
+	Created time.Time         `json:"created"`
+	Manager *testUser         `json:"manager"`
+	secret  string
+}
+
+func TestGenerate(t *testing.T) {
+	defer func() {
+		err := recover()
+		if err != nil {
+			t.Errorf(err.(error).Error())
+		}
+	}()
+
+	err := "Failed generate."
+
+	v := verto.New()
+	v.Logger = &verto.NilLogger{}
+	rf := func(c *verto.Context) (interface{}, error) { return nil, nil }
+	v.Get("/users/{id: ^[0-9]+$}", rf).Name("user.show").Meta(ResponseKey, &testUser{})
+	v.Post("/users", rf).Name("user.create").Meta(RequestKey, testUser{}).Meta(ResponseKey, []testUser{})
+	v.Get("/files/^", rf)
+	v.Get("/hidden", rf).Meta(HiddenKey, true)
+	v.Host("{tenant: ^[a-z]+$}.example.com").Get("/settings", rf)
+
+	doc := Generate(v.Routes(), Info{Title: "Users", Version: "1.0.0"})
+	if doc.OpenAPI != Version || len(doc.Paths) != 4 || doc.Paths["/hidden"] != nil {
+		t.Fatalf(err)
+	}
+
+	// Test host routes are served by their hosts
+	settings := doc.Paths["/settings"]["get"]
+	if settings == nil || len(settings.Servers) != 1 || settings.Servers[0].URL != "//{tenant}.example.com" ||
+		settings.Servers[0].Variables["tenant"].Default != "tenant" {
+		t.Errorf(err)
+	}
+	if show := doc.Paths["/users/{id}"]["get"]; show == nil || show.Servers != nil {
+		t.Errorf(err)
+	}
+
+	// Test path parameters keep their regex constraints
+	show := doc.Paths["/users/{id}"]["get"]
+	if show == nil || show.OperationID != "user.show" || len(show.Parameters) != 1 {
+		t.Fatalf(err)
+	}
+	if p := show.Parameters[0]; p.Name != "id" || p.In != "path" || !p.Required || p.Schema.Pattern != "^[0-9]+$" {
+		t.Errorf(err)
+	}
+	files := doc.Paths["/files/{rest}"]["get"]
+	if files == nil || len(files.Parameters) != 1 || files.Parameters[0].Name != "rest" {
+		t.Errorf(err)
+	}
+
+	// Test bodies reference component schemas
+	ref := "#/components/schemas/testUser"
+	if show.Responses["200"].Content["application/json"].Schema.Ref != ref {
+		t.Errorf(err)
+	}
+	create := doc.Paths["/users"]["post"]
+	if create.RequestBody == nil || create.RequestBody.Content["application/json"].Schema.Ref != ref {
+		t.Errorf(err)
+	}
+	if list := create.Responses["200"].Content["application/json"].Schema; list.Type != "array" || list.Items.Ref != ref {
+		t.Errorf(err)
+	}
+	user := doc.Components.Schemas["testUser"]
+	if user == nil || len(user.Properties) != 6 || user.Properties["secret"] != nil {
+		t.Fatalf(err)
+	}
+	if user.Properties["id"].Type != "integer" || user.Properties["created"].Format != "date-time" ||
+		user.Properties["tags"].Items.Type != "string" || user.Properties["labels"].AdditionalProperties.Type != "string" ||
+		user.Properties["manager"].Ref != ref {
+		t.Errorf(err)
+	}
+	if strings.Join(user.Required, ",") != "id,tags,labels,created" {
+		t.Errorf(err)
+	}
+}
+
+func TestRegister(t *testing.T) {
+	defer func() {
+		err := recover()
+		if err != nil {
+			t.Errorf(err.(error).Error())
+		}
+	}()
+
+	err := "Failed register."
+
+	v := verto.New()
+	v.Logger = &verto.NilLogger{}
+	v.Get("/users", func(c *verto.Context) (interface{}, error) { return nil, nil })
+	Register(v, "/api/", Info{Title: "Users", Version: "1.0.0"})
+	h := &verto.HttpHandler{v}
+
+	serve := func(path string) *httptest.ResponseRecorder {
+		r, _ := http.NewRequest("GET", "http://test.com"+path, nil)
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		return w
+	}
+
+	// Test the document describes routes other than its own
+	w := serve("/api/openapi.json")
+	doc := make(map[string]interface{})
+	if e := json.Unmarshal(w.Body.Bytes(), &doc); e != nil || w.Code != 200 {
+		t.Fatalf(err)
+	}
+	paths, _ := doc["paths"].(map[string]interface{})
+	if doc["openapi"] != Version || len(paths) != 1 || paths["/users"] == nil {
+		t.Errorf(err)
+	}
+
+	// Test the Swagger UI page loads the document
+	w = serve("/api/docs")
+	if w.Code != 200 || !strings.Contains(w.Body.String(), `url: "/api/openapi.json"`) {
+		t.Errorf(err)
+	}
+}
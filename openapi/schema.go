@@ -0,0 +1,157 @@
+package openapi
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Schema is an OpenAPI schema object
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Pattern              string             `json:"pattern,omitempty"`
+	Nullable             bool               `json:"nullable,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+}
+
+// timeType is the reflect.Type of time.Time
+var timeType = reflect.TypeOf(time.Time{})
+
+// schemas builds the schemas of Go types. Named struct types
+// are collected as components and referenced by name
+type schemas struct {
+	named map[string]*Schema
+	types map[reflect.Type]string
+}
+
+// newSchemas returns an empty schemas
+func newSchemas() *schemas {
+	return &schemas{
+		named: make(map[string]*Schema),
+		types: make(map[reflect.Type]string),
+	}
+}
+
+// of returns the schema of values of type t as
+// serialized by encoding/json
+func (s *schemas) of(t reflect.Type) *Schema {
+	nullable := false
+	for t.Kind() == reflect.Ptr {
+		t, nullable = t.Elem(), true
+	}
+
+	var schema *Schema
+	switch {
+	case t == timeType:
+		schema = &Schema{Type: "string", Format: "date-time"}
+	case t.Kind() == reflect.Struct && t.Name() != "":
+		schema = &Schema{Ref: "#/components/schemas/" + s.component(t)}
+	case t.Kind() == reflect.Struct:
+		schema = s.object(t)
+	default:
+		schema = s.basic(t)
+	}
+	if nullable && schema.Ref == "" {
+		schema.Nullable = true
+	}
+	return schema
+}
+
+// component registers the schema of the named struct type
+// t as a component and returns the component's name
+func (s *schemas) component(t reflect.Type) string {
+	if name, ok := s.types[t]; ok {
+		return name
+	}
+	name := t.Name()
+	for i := 2; s.named[name] != nil; i++ {
+		name = t.Name() + strconv.Itoa(i)
+	}
+	// Register the name before building the schema
+	// so that recursive types terminate
+	s.types[t] = name
+	s.named[name] = &Schema{}
+	*s.named[name] = *s.object(t)
+	return name
+}
+
+// object returns the object schema of struct type t
+func (s *schemas) object(t reflect.Type) *Schema {
+	schema := &Schema{Type: "object", Properties: make(map[string]*Schema)}
+	s.fields(t, schema)
+	return schema
+}
+
+// fields adds the JSON-serialized fields of struct type t to schema.
+// Fields of embedded structs without a JSON name are promoted
+func (s *schemas) fields(t reflect.Type, schema *Schema) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.PkgPath != "" && !f.Anonymous {
+			continue
+		}
+		tag := f.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		parts := strings.Split(tag, ",")
+		name := parts[0]
+
+		ft := f.Type
+		if f.Anonymous && name == "" {
+			if ft.Kind() == reflect.Ptr {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				s.fields(ft, schema)
+			}
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+
+		optional := ft.Kind() == reflect.Ptr
+		for _, p := range parts[1:] {
+			if p == "omitempty" {
+				optional = true
+			}
+		}
+		schema.Properties[name] = s.of(ft)
+		if !optional {
+			schema.Required = append(schema.Required, name)
+		}
+	}
+}
+
+// basic returns the schema of non-struct type t
+func (s *schemas) basic(t reflect.Type) *Schema {
+	switch t.Kind() {
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint8, reflect.Uint16:
+		return &Schema{Type: "integer", Format: "int32"}
+	case reflect.Int, reflect.Int64, reflect.Uint, reflect.Uint32, reflect.Uint64:
+		return &Schema{Type: "integer", Format: "int64"}
+	case reflect.Float32:
+		return &Schema{Type: "number", Format: "float"}
+	case reflect.Float64:
+		return &Schema{Type: "number", Format: "double"}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string", Format: "byte"}
+		}
+		return &Schema{Type: "array", Items: s.of(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: s.of(t.Elem())}
+	}
+	return &Schema{}
+}
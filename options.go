@@ -0,0 +1,140 @@
+package verto
+
+import (
+	"encoding/json"
+	"github.com/boxtown/verto/mux"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// DiscoveryKey is the Group metadata key under
+// which Group-specific Discoveries are stored
+const DiscoveryKey = "verto.discovery"
+
+// Discovery configures the machine-readable descriptions of resources
+// returned for OPTIONS requests answered automatically. Without a
+// Discovery such requests are answered with empty 204 responses.
+//
+// Example usage:
+//
+//	v.SetAutoOptions(true)
+//	v.Discovery = &verto.Discovery{Schema: "/openapi.json"}
+//	v.Group("GET", "/internal").Discover(nil)
+type Discovery struct {
+	// Schema is the URL of the OpenAPI document of the API, e.g. as
+	// served by openapi.Register. If set, descriptions link every
+	// operation to its entry in the document and responses carry
+	// a describedby Link to the document
+	Schema string
+}
+
+// Description is the description of a resource
+// returned for OPTIONS requests
+type Description struct {
+	// Path is the requested path of the resource
+	Path string `json:"path"`
+
+	// Methods describes the methods allowed on the resource
+	Methods []MethodDescription `json:"methods"`
+}
+
+// MethodDescription describes a method allowed on a resource. Methods
+// answered automatically (e.g. OPTIONS) are described by Method only
+type MethodDescription struct {
+	// Method is the HTTP method
+	Method string `json:"method"`
+
+	// Route is the path pattern of the route serving the method
+	Route string `json:"route,omitempty"`
+
+	// Name is the name of the route if it is named
+	Name string `json:"name,omitempty"`
+
+	// Parameters are the names of the route's path parameters
+	Parameters []string `json:"parameters,omitempty"`
+
+	// Schema links to the operation in the Discovery's OpenAPI document
+	Schema string `json:"schema,omitempty"`
+}
+
+// Discover sets the Discovery for the paths under the Group,
+// overriding the Discovery of Verto. A nil Discovery turns
+// descriptions off for the Group
+func (g *Group) Discover(d *Discovery) *Group {
+	g.g.Meta(DiscoveryKey, d)
+	return g
+}
+
+// description returns the description of the resource
+// at path listing the methods in allowed
+func (d *Discovery) description(v *Verto, path string, allowed []string) *Description {
+	desc := &Description{Path: path, Methods: make([]MethodDescription, 0, len(allowed))}
+	for _, method := range allowed {
+		md := MethodDescription{Method: method}
+		if route := v.table().Match(method, path); route != nil {
+			md.Route = route.Path()
+			md.Name = route.Name()
+			md.Parameters = routeParams(route.Path())
+			if d.Schema != "" {
+				md.Schema = d.Schema + "#/paths/" + url.PathEscape(pointerEscape(openAPIPath(route.Path()))) +
+					"/" + strings.ToLower(method)
+			}
+		}
+		desc.Methods = append(desc.Methods, md)
+	}
+	return desc
+}
+
+// describe answers automatic OPTIONS requests with the description
+// of the requested resource if a Discovery applies to the request
+func (v *Verto) describe(w http.ResponseWriter, r *http.Request) {
+	d := v.Discovery
+	if gd, ok := mux.GroupMeta(r, DiscoveryKey); ok {
+		d, _ = gd.(*Discovery)
+	}
+	if d == nil {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	allowed := strings.Split(w.Header().Get("Allow"), ", ")
+	if d.Schema != "" {
+		w.Header().Add("Link", "<"+d.Schema+`>; rel="describedby"`)
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(d.description(v, r.URL.Path, allowed))
+}
+
+// routeParams returns the names of the parameters in the route
+// path pattern. The catch-all is named 'rest'
+func routeParams(pattern string) []string {
+	params := make([]string, 0)
+	for _, s := range strings.Split(openAPIPath(pattern), "/") {
+		if strings.HasPrefix(s, "{") {
+			params = append(params, s[1:len(s)-1])
+		}
+	}
+	return params
+}
+
+// openAPIPath returns the OpenAPI path template of the route path
+// pattern. Parameter regexes are dropped and the catch-all is
+// named 'rest'
+func openAPIPath(pattern string) string {
+	segments := strings.Split(pattern, "/")
+	for i, s := range segments {
+		if s == "^" {
+			segments = append(segments[:i], "{rest}")
+			break
+		}
+		if strings.HasPrefix(s, "{") && strings.HasSuffix(s, "}") {
+			segments[i] = "{" + strings.TrimSpace(strings.SplitN(s[1:len(s)-1], ":", 2)[0]) + "}"
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
+// pointerEscape escapes s as a JSON pointer reference token
+func pointerEscape(s string) string {
+	return strings.NewReplacer("~", "~0", "/", "~1").Replace(s)
+}
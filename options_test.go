@@ -0,0 +1,71 @@
+package verto
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestOptionsDiscovery(t *testing.T) {
+	defer func() {
+		err := recover()
+		if err != nil {
+			t.Errorf(err.(error).Error())
+		}
+	}()
+
+	err := "Failed options discovery."
+
+	v := New()
+	v.Logger = &NilLogger{}
+	v.SetAutoOptions(true)
+	rf := func(c *Context) (interface{}, error) {
+		return nil, nil
+	}
+	v.Get("/users/{id: ^[0-9]+$}", rf).Name("user.show")
+	v.Delete("/users/{id}", rf)
+	internal := v.Group("GET", "/internal")
+	internal.Add("/stats", rf)
+	h := &HttpHandler{v}
+
+	options := func(path string) *httptest.ResponseRecorder {
+		r, _ := http.NewRequest("OPTIONS", "http://test.com"+path, nil)
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		return w
+	}
+
+	// Test OPTIONS responses are empty without a Discovery
+	w := options("/users/1")
+	if w.Code != 204 || w.Header().Get("Allow") != "DELETE, GET, OPTIONS" || w.Body.Len() != 0 {
+		t.Errorf(err)
+	}
+
+	// Test OPTIONS responses describe the resource
+	v.Discovery = &Discovery{Schema: "/openapi.json"}
+	w = options("/users/1")
+	desc := Description{}
+	if e := json.Unmarshal(w.Body.Bytes(), &desc); e != nil || w.Code != 200 {
+		t.Fatalf(err)
+	}
+	if desc.Path != "/users/1" || len(desc.Methods) != 3 || w.Header().Get("Link") != `</openapi.json>; rel="describedby"` {
+		t.Fatalf(err)
+	}
+	get := desc.Methods[1]
+	if get.Method != "GET" || get.Name != "user.show" || get.Route != "/users/{id: ^[0-9]+$}" ||
+		len(get.Parameters) != 1 || get.Parameters[0] != "id" ||
+		get.Schema != "/openapi.json#/paths/~1users~1%7Bid%7D/get" {
+		t.Errorf(err)
+	}
+	if desc.Methods[2].Method != "OPTIONS" || desc.Methods[2].Route != "" {
+		t.Errorf(err)
+	}
+
+	// Test Groups can turn descriptions off
+	internal.Discover(nil)
+	w = options("/internal/stats")
+	if w.Code != 204 || w.Header().Get("Allow") != "GET, OPTIONS" {
+		t.Errorf(err)
+	}
+}
@@ -0,0 +1,97 @@
+package verto
+
+import (
+	"context"
+	"github.com/boxtown/verto/mux"
+	"net/http"
+)
+
+// ErrorHandlerKey is the endpoint metadata key under
+// which route-specific ErrorHandlers are stored
+const ErrorHandlerKey = "verto.errorHandler"
+
+// ResponseHandlerKey is the endpoint metadata key under
+// which route-specific ResponseHandlers are stored
+const ResponseHandlerKey = "verto.responseHandler"
+
+// handlerKey is the context key of Group-specific handlers
+type handlerKey string
+
+// OnError sets the ErrorHandler for the route represented by the
+// Endpoint, overriding the ErrorHandlers of its Groups and Verto
+func (ep *Endpoint) OnError(handler ErrorHandler) *Endpoint {
+	return ep.Meta(ErrorHandlerKey, handler)
+}
+
+// OnResponse sets the ResponseHandler for the route represented by the
+// Endpoint, overriding the ResponseHandlers of its Groups and Verto
+func (ep *Endpoint) OnResponse(handler ResponseHandler) *Endpoint {
+	return ep.Meta(ResponseHandlerKey, handler)
+}
+
+// OnError sets the ErrorHandler for all routes under the Group,
+// overriding the ErrorHandler of Verto. Routes and sub-Groups can
+// set their own ErrorHandlers. The ErrorHandler also renders the
+// Not Found and Method Not Allowed responses for paths under the
+// Group, so that an API Group can answer them with JSON.
+//
+// Example usage:
+//
+//	api := v.Group("GET", "/api")
+//	api.OnError(verto.ErrorFunc(jsonErrors))
+//	api.OnResponse(verto.ResponseFunc(verto.JSONResponseFunc))
+func (g *Group) OnError(handler ErrorHandler) *Group {
+	g.g.Meta(ErrorHandlerKey, handler)
+	return g.UsePluginHandler(handlerPlugin(ErrorHandlerKey, handler))
+}
+
+// OnResponse sets the ResponseHandler for all routes under the Group,
+// overriding the ResponseHandler of Verto. Routes and sub-Groups can
+// set their own ResponseHandlers
+func (g *Group) OnResponse(handler ResponseHandler) *Group {
+	g.g.Meta(ResponseHandlerKey, handler)
+	return g.UsePluginHandler(handlerPlugin(ResponseHandlerKey, handler))
+}
+
+// handlerPlugin returns a plugin attaching handler
+// to the context of requests under key
+func handlerPlugin(key string, handler interface{}) mux.PluginFunc {
+	return func(w http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+		next(w, r.WithContext(context.WithValue(r.Context(), handlerKey(key), handler)))
+	}
+}
+
+// errorHandler returns the ErrorHandler for the request of c: the
+// handler of its route, of its innermost Group or else Verto's
+func (v *Verto) errorHandler(c *Context) ErrorHandler {
+	if h, ok := v.routeHandler(c, ErrorHandlerKey).(ErrorHandler); ok {
+		return h
+	}
+	return v.ErrorHandler
+}
+
+// responseHandler returns the ResponseHandler for the request of c: the
+// handler of its route, of its innermost Group or else Verto's
+func (v *Verto) responseHandler(c *Context) ResponseHandler {
+	if h, ok := v.routeHandler(c, ResponseHandlerKey).(ResponseHandler); ok {
+		return h
+	}
+	return v.ResponseHandler
+}
+
+// routeHandler returns the handler stored under key in the route
+// metadata or the request context of c, or for unmatched requests
+// in the metadata of their Group, or nil if there is none
+func (v *Verto) routeHandler(c *Context, key string) interface{} {
+	if h, ok := c.RouteMeta(key); ok {
+		return h
+	}
+	if c.Request == nil {
+		return nil
+	}
+	if h := c.Request.Context().Value(handlerKey(key)); h != nil {
+		return h
+	}
+	h, _ := mux.GroupMeta(c.Request, key)
+	return h
+}
@@ -0,0 +1,73 @@
+package verto
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestHandlerOverrides(t *testing.T) {
+	defer func() {
+		err := recover()
+		if err != nil {
+			t.Errorf(err.(error).Error())
+		}
+	}()
+
+	err := "Failed handler overrides."
+
+	respond := func(prefix string) ResponseHandler {
+		return ResponseFunc(func(response interface{}, c *Context) {
+			c.Response.Write([]byte(prefix + ":" + response.(string)))
+		})
+	}
+	fail := func(prefix string) ErrorHandler {
+		return ErrorFunc(func(e error, c *Context) {
+			c.Response.WriteHeader(500)
+			c.Response.Write([]byte(prefix + ":" + e.Error()))
+		})
+	}
+
+	v := New()
+	v.Logger = &NilLogger{}
+	v.ResponseHandler = respond("global")
+	v.ErrorHandler = fail("global")
+	ok := func(c *Context) (interface{}, error) {
+		return "ok", nil
+	}
+	bad := func(c *Context) (interface{}, error) {
+		return nil, errors.New("bad")
+	}
+
+	api := v.Group("GET", "/api")
+	api.OnResponse(respond("api")).OnError(fail("api"))
+	api.Add("/ok", ok)
+	api.Add("/bad", bad)
+	api.Add("/own", ok).OnResponse(respond("own"))
+	api.Group("/html").OnResponse(respond("html")).Add("/ok", ok)
+	v.Get("/ok", ok)
+	v.Get("/bad", bad)
+	h := &HttpHandler{v}
+
+	serve := func(path string) string {
+		r, _ := http.NewRequest("GET", "http://test.com"+path, nil)
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		return w.Body.String()
+	}
+
+	expected := map[string]string{
+		"/ok":          "global:ok",
+		"/bad":         "global:bad",
+		"/api/ok":      "api:ok",
+		"/api/bad":     "api:bad",
+		"/api/own":     "own:ok",
+		"/api/html/ok": "html:ok",
+	}
+	for path, body := range expected {
+		if serve(path) != body {
+			t.Errorf(err)
+		}
+	}
+}
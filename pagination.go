@@ -0,0 +1,162 @@
+package verto
+
+import (
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// Request parameters recognized by Context.Page
+const (
+	PageParam   = "page"
+	LimitParam  = "limit"
+	CursorParam = "cursor"
+)
+
+// Page holds the pagination parameters of a request
+type Page struct {
+	// Number is the 1-based page number
+	Number int
+
+	// Limit is the maximum number of items per page
+	Limit int
+
+	// Offset is the number of items preceding the page
+	Offset int
+
+	// Cursor is the opaque cursor supplied by the client
+	// for cursor-based pagination
+	Cursor string
+}
+
+// Page parses the page, limit and cursor request parameters. Missing or
+// invalid page numbers default to 1 and missing or invalid limits default
+// to defaultLimit. Limits are capped at maxLimit if maxLimit is positive.
+func (c *Context) Page(defaultLimit, maxLimit int) Page {
+	p := Page{Number: 1, Limit: defaultLimit}
+	if n, err := strconv.Atoi(c.Get(PageParam)); err == nil && n > 0 {
+		p.Number = n
+	}
+	if l, err := strconv.Atoi(c.Get(LimitParam)); err == nil && l > 0 {
+		p.Limit = l
+	}
+	if maxLimit > 0 && p.Limit > maxLimit {
+		p.Limit = maxLimit
+	}
+	if p.Limit < 1 {
+		p.Limit = 1
+	}
+	p.Offset = (p.Number - 1) * p.Limit
+	p.Cursor = c.Get(CursorParam)
+	return p
+}
+
+// PagedResponse is a response envelope for a single page of items.
+// ResponseHandlers wrapped with Paginated emit Link and X-Total-Count
+// headers for PagedResponses.
+type PagedResponse struct {
+	// Items is the page of items
+	Items interface{} `json:"items" xml:"items"`
+
+	// Total is the total number of items across all pages
+	// or a negative number if the total is unknown
+	Total int64 `json:"total" xml:"total"`
+
+	// Page is the 1-based page number
+	Page int `json:"page" xml:"page"`
+
+	// Limit is the maximum number of items per page
+	Limit int `json:"limit" xml:"limit"`
+
+	// NextCursor is the cursor for the next page in cursor-based
+	// pagination or empty if there are no further pages
+	NextCursor string `json:"next_cursor,omitempty" xml:"next_cursor,omitempty"`
+}
+
+// NewPagedResponse returns a PagedResponse for items on page p out of total
+func NewPagedResponse(items interface{}, total int64, p Page) *PagedResponse {
+	return &PagedResponse{
+		Items: items,
+		Total: total,
+		Page:  p.Number,
+		Limit: p.Limit,
+	}
+}
+
+// Paginated wraps handler such that PagedResponses have RFC 5988 Link
+// headers (first, prev, next, last) and an X-Total-Count header set before
+// being passed to handler. Other responses are passed through unchanged.
+//
+// Example usage:
+//
+//	v.ResponseHandler = verto.Paginated(verto.ResponseFunc(verto.JSONResponseFunc))
+//
+//	v.Get("/users", func(c *verto.Context) (interface{}, error) {
+//		p := c.Page(20, 100)
+//		users, total := store.List(p.Offset, p.Limit)
+//		return verto.NewPagedResponse(users, total, p), nil
+//	})
+func Paginated(handler ResponseHandler) ResponseHandler {
+	return ResponseFunc(func(response interface{}, c *Context) {
+		if p, ok := response.(*PagedResponse); ok && c.Request != nil {
+			if p.Total >= 0 {
+				c.Response.Header().Set("X-Total-Count", strconv.FormatInt(p.Total, 10))
+			}
+			if links := p.links(c.Request.URL); links != "" {
+				c.Response.Header().Set("Link", links)
+			}
+		}
+		handler.Handle(response, c)
+	})
+}
+
+// links returns the Link header value for the page
+// relative to the request URL u
+func (p *PagedResponse) links(u *url.URL) string {
+	if p.Limit < 1 {
+		return ""
+	}
+
+	links := make([]string, 0, 4)
+	link := func(rel string, set func(q url.Values)) {
+		q := u.Query()
+		set(q)
+		l := url.URL{Path: u.Path, RawQuery: q.Encode()}
+		links = append(links, "<"+l.String()+`>; rel="`+rel+`"`)
+	}
+	page := func(n int) func(q url.Values) {
+		return func(q url.Values) {
+			q.Del(CursorParam)
+			q.Set(PageParam, strconv.Itoa(n))
+			q.Set(LimitParam, strconv.Itoa(p.Limit))
+		}
+	}
+
+	if p.NextCursor != "" {
+		link("next", func(q url.Values) {
+			q.Del(PageParam)
+			q.Set(CursorParam, p.NextCursor)
+			q.Set(LimitParam, strconv.Itoa(p.Limit))
+		})
+		return strings.Join(links, ", ")
+	}
+
+	last := 0
+	if p.Total >= 0 {
+		last = int((p.Total + int64(p.Limit) - 1) / int64(p.Limit))
+		if last < 1 {
+			last = 1
+		}
+	}
+	link("first", page(1))
+	if p.Page > 1 {
+		link("prev", page(p.Page-1))
+	}
+	if last == 0 || p.Page < last {
+		link("next", page(p.Page+1))
+	}
+	if last > 0 {
+		link("last", page(last))
+	}
+	return strings.Join(links, ", ")
+}
@@ -0,0 +1,95 @@
+package verto
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestContextPage(t *testing.T) {
+	defer func() {
+		err := recover()
+		if err != nil {
+			t.Errorf(err.(error).Error())
+		}
+	}()
+
+	err := "Failed page."
+
+	r, _ := http.NewRequest("GET", "http://test.com/users?page=3&limit=10", nil)
+	c := NewContext(nil, r, nil, nil)
+	p := c.Page(20, 100)
+	if p.Number != 3 || p.Limit != 10 || p.Offset != 20 {
+		t.Errorf(err)
+	}
+
+	// Test bounds
+	r, _ = http.NewRequest("GET", "http://test.com/users?page=-1&limit=1000&cursor=abc", nil)
+	c = NewContext(nil, r, nil, nil)
+	p = c.Page(20, 100)
+	if p.Number != 1 || p.Limit != 100 || p.Offset != 0 || p.Cursor != "abc" {
+		t.Errorf(err)
+	}
+
+	// Test defaults
+	r, _ = http.NewRequest("GET", "http://test.com/users?limit=abc", nil)
+	c = NewContext(nil, r, nil, nil)
+	p = c.Page(20, 100)
+	if p.Number != 1 || p.Limit != 20 {
+		t.Errorf(err)
+	}
+}
+
+func TestPaginated(t *testing.T) {
+	defer func() {
+		err := recover()
+		if err != nil {
+			t.Errorf(err.(error).Error())
+		}
+	}()
+
+	err := "Failed paginated."
+
+	handler := Paginated(ResponseFunc(JSONResponseFunc))
+
+	r, _ := http.NewRequest("GET", "http://test.com/users?page=2&limit=10&q=a", nil)
+	w := httptest.NewRecorder()
+	c := NewContext(w, r, nil, nil)
+	handler.Handle(NewPagedResponse([]int{1, 2}, 35, c.Page(20, 100)), c)
+
+	if w.Header().Get("X-Total-Count") != "35" {
+		t.Errorf(err)
+	}
+	expected := `</users?limit=10&page=1&q=a>; rel="first", ` +
+		`</users?limit=10&page=1&q=a>; rel="prev", ` +
+		`</users?limit=10&page=3&q=a>; rel="next", ` +
+		`</users?limit=10&page=4&q=a>; rel="last"`
+	if w.Header().Get("Link") != expected {
+		t.Errorf(err)
+	}
+	if w.Body.String() != `{"items":[1,2],"total":35,"page":2,"limit":10}` {
+		t.Errorf(err)
+	}
+
+	// Test cursor pagination
+	r, _ = http.NewRequest("GET", "http://test.com/users?cursor=a", nil)
+	w = httptest.NewRecorder()
+	c = NewContext(w, r, nil, nil)
+	resp := NewPagedResponse([]int{}, -1, c.Page(20, 100))
+	resp.NextCursor = "b"
+	handler.Handle(resp, c)
+	if w.Header().Get("X-Total-Count") != "" {
+		t.Errorf(err)
+	}
+	if w.Header().Get("Link") != `</users?cursor=b&limit=20>; rel="next"` {
+		t.Errorf(err)
+	}
+
+	// Test passthrough
+	w = httptest.NewRecorder()
+	c = NewContext(w, r, nil, nil)
+	handler.Handle("a", c)
+	if w.Header().Get("Link") != "" || w.Body.String() != `"a"` {
+		t.Errorf(err)
+	}
+}
@@ -0,0 +1,92 @@
+package verto
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"github.com/boxtown/verto/patch"
+	"io"
+	"io/ioutil"
+	"mime"
+	"net/http"
+	"reflect"
+)
+
+// JSONPatchType is the media type of RFC 6902 JSON Patch documents
+const JSONPatchType = "application/json-patch+json"
+
+// MergePatchType is the media type of RFC 7386 JSON Merge Patch documents
+const MergePatchType = "application/merge-patch+json"
+
+// MaxPatchSize is the maximum size in bytes of patch documents
+var MaxPatchSize int64 = 1 << 20
+
+// Patch applies the JSON Patch or JSON Merge Patch document in the request
+// body, as selected by the request's Content-Type, to target, which must be
+// a pointer to a struct or map. Patched documents with unknown struct fields
+// are rejected and targets implementing Validate() error are validated after
+// patching. target is only modified if the whole patch applies cleanly.
+// Errors are HTTPErrors with status 415 for unsupported content types,
+// 409 for failed test operations and 422 for patches that can't be applied.
+//
+// Example usage:
+//
+//	v.Patch("/users/{id}", func(c *verto.Context) (interface{}, error) {
+//		user := load(c.Param("id"))
+//		if err := c.Patch(user); err != nil {
+//			return nil, err
+//		}
+//		return user, save(user)
+//	})
+func (c *Context) Patch(target interface{}) error {
+	rv := reflect.ValueOf(target)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return errors.New("verto: patch target must be a non-nil pointer")
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(c.Request.Header.Get("Content-Type"))
+	if mediaType != JSONPatchType && mediaType != MergePatchType {
+		return &HTTPError{Status: http.StatusUnsupportedMediaType}
+	}
+
+	body, err := ioutil.ReadAll(io.LimitReader(c.Request.Body, MaxPatchSize+1))
+	if err != nil {
+		return &HTTPError{Status: http.StatusBadRequest, Message: err.Error(), Err: err}
+	}
+	if int64(len(body)) > MaxPatchSize {
+		return &HTTPError{Status: http.StatusRequestEntityTooLarge}
+	}
+
+	doc, err := json.Marshal(target)
+	if err != nil {
+		return err
+	}
+	if mediaType == JSONPatchType {
+		doc, err = patch.Apply(doc, body)
+	} else {
+		doc, err = patch.Merge(doc, body)
+	}
+	if err == patch.ErrTestFailed {
+		return &HTTPError{Status: http.StatusConflict, Message: err.Error(), Err: err}
+	}
+	if err != nil {
+		return &HTTPError{Status: http.StatusUnprocessableEntity, Message: err.Error(), Err: err}
+	}
+
+	// Decode into a fresh value so that removed fields are zeroed
+	patched := reflect.New(rv.Elem().Type())
+	dec := json.NewDecoder(bytes.NewReader(doc))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(patched.Interface()); err != nil {
+		return &HTTPError{Status: http.StatusUnprocessableEntity, Message: err.Error(), Err: err}
+	}
+	if validator, ok := patched.Interface().(interface {
+		Validate() error
+	}); ok {
+		if err := validator.Validate(); err != nil {
+			return &HTTPError{Status: http.StatusUnprocessableEntity, Message: err.Error(), Err: err}
+		}
+	}
+	rv.Elem().Set(patched.Elem())
+	return nil
+}
@@ -0,0 +1,389 @@
+// Package patch applies RFC 6902 JSON Patch and RFC 7386 JSON Merge
+// Patch documents to JSON documents. Documents are applied atomically:
+// if any operation of a JSON Patch fails, the original document is kept.
+package patch
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// ErrTestFailed is returned when a test operation of a JSON Patch fails
+var ErrTestFailed = errors.New("patch: test operation failed")
+
+// Operation is a JSON Patch operation
+type Operation struct {
+	Op    string          `json:"op"`
+	Path  string          `json:"path"`
+	From  string          `json:"from,omitempty"`
+	Value json.RawMessage `json:"value,omitempty"`
+}
+
+// Error is returned for invalid JSON Patch operations
+type Error struct {
+	// Index is the index of the failed operation
+	Index int
+
+	// Op is the failed operation
+	Op Operation
+
+	// Err describes the failure
+	Err error
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("patch: operation %d (%s %s): %s", e.Index, e.Op.Op, e.Op.Path, e.Err.Error())
+}
+
+// Decode decodes a JSON Patch document
+func Decode(patch []byte) ([]Operation, error) {
+	ops := make([]Operation, 0)
+	if err := json.Unmarshal(patch, &ops); err != nil {
+		return nil, err
+	}
+	return ops, nil
+}
+
+// Apply applies the JSON Patch document patch to the JSON document doc
+// and returns the patched document
+func Apply(doc, patch []byte) ([]byte, error) {
+	ops, err := Decode(patch)
+	if err != nil {
+		return nil, err
+	}
+	root, err := decode(doc)
+	if err != nil {
+		return nil, err
+	}
+	for i, op := range ops {
+		root, err = apply(root, op)
+		if err != nil {
+			if err == ErrTestFailed {
+				return nil, err
+			}
+			return nil, &Error{Index: i, Op: op, Err: err}
+		}
+	}
+	return json.Marshal(root)
+}
+
+// Merge applies the JSON Merge Patch document patch to the JSON
+// document doc and returns the patched document
+func Merge(doc, patch []byte) ([]byte, error) {
+	target, err := decode(doc)
+	if err != nil {
+		return nil, err
+	}
+	p, err := decode(patch)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(merge(target, p))
+}
+
+// merge implements the MergePatch algorithm of RFC 7386
+func merge(target, patch interface{}) interface{} {
+	p, ok := patch.(map[string]interface{})
+	if !ok {
+		return patch
+	}
+	t, ok := target.(map[string]interface{})
+	if !ok {
+		t = make(map[string]interface{})
+	}
+	for k, v := range p {
+		if v == nil {
+			delete(t, k)
+		} else {
+			t[k] = merge(t[k], v)
+		}
+	}
+	return t
+}
+
+// apply applies a single operation to root and returns the new root
+func apply(root interface{}, op Operation) (interface{}, error) {
+	switch op.Op {
+	case "add":
+		value, err := op.value()
+		if err != nil {
+			return nil, err
+		}
+		return add(root, op.Path, value)
+	case "remove":
+		root, _, err := remove(root, op.Path)
+		return root, err
+	case "replace":
+		value, err := op.value()
+		if err != nil {
+			return nil, err
+		}
+		root, _, err = remove(root, op.Path)
+		if err != nil {
+			return nil, err
+		}
+		return add(root, op.Path, value)
+	case "move":
+		if op.Path != op.From && strings.HasPrefix(op.Path, op.From+"/") {
+			return nil, errors.New("cannot move a value into one of its children")
+		}
+		root, value, err := remove(root, op.From)
+		if err != nil {
+			return nil, err
+		}
+		return add(root, op.Path, value)
+	case "copy":
+		value, err := get(root, op.From)
+		if err != nil {
+			return nil, err
+		}
+		return add(root, op.Path, deepCopy(value))
+	case "test":
+		value, err := op.value()
+		if err != nil {
+			return nil, err
+		}
+		actual, err := get(root, op.Path)
+		if err != nil || !equal(actual, value) {
+			return nil, ErrTestFailed
+		}
+		return root, nil
+	}
+	return nil, fmt.Errorf("unknown operation %q", op.Op)
+}
+
+// value decodes the value of the operation
+func (op Operation) value() (interface{}, error) {
+	if len(op.Value) == 0 {
+		return nil, errors.New("missing value")
+	}
+	return decode(op.Value)
+}
+
+// parsePointer splits an RFC 6901 JSON Pointer into unescaped tokens
+func parsePointer(pointer string) ([]string, error) {
+	if pointer == "" {
+		return nil, nil
+	}
+	if pointer[0] != '/' {
+		return nil, fmt.Errorf("invalid pointer %q", pointer)
+	}
+	tokens := strings.Split(pointer[1:], "/")
+	for i, t := range tokens {
+		tokens[i] = strings.Replace(strings.Replace(t, "~1", "/", -1), "~0", "~", -1)
+	}
+	return tokens, nil
+}
+
+// get returns the value at pointer in root
+func get(root interface{}, pointer string) (interface{}, error) {
+	tokens, err := parsePointer(pointer)
+	if err != nil {
+		return nil, err
+	}
+	node := root
+	for _, t := range tokens {
+		switch n := node.(type) {
+		case map[string]interface{}:
+			v, ok := n[t]
+			if !ok {
+				return nil, fmt.Errorf("path %q does not exist", pointer)
+			}
+			node = v
+		case []interface{}:
+			i, err := index(t, len(n))
+			if err != nil {
+				return nil, err
+			}
+			node = n[i]
+		default:
+			return nil, fmt.Errorf("path %q does not exist", pointer)
+		}
+	}
+	return node, nil
+}
+
+// add adds value at pointer in root and returns the new root
+func add(root interface{}, pointer string, value interface{}) (interface{}, error) {
+	tokens, err := parsePointer(pointer)
+	if err != nil {
+		return nil, err
+	}
+	if len(tokens) == 0 {
+		return value, nil
+	}
+	parentPointer := pointer[:strings.LastIndex(pointer, "/")]
+	parent, err := get(root, parentPointer)
+	if err != nil {
+		return nil, err
+	}
+	last := tokens[len(tokens)-1]
+	switch p := parent.(type) {
+	case map[string]interface{}:
+		p[last] = value
+		return root, nil
+	case []interface{}:
+		i := len(p)
+		if last != "-" {
+			if i, err = index(last, len(p)+1); err != nil {
+				return nil, err
+			}
+		}
+		arr := append(p, nil)
+		copy(arr[i+1:], arr[i:])
+		arr[i] = value
+		return set(root, parentPointer, arr)
+	}
+	return nil, fmt.Errorf("path %q does not exist", pointer)
+}
+
+// remove removes the value at pointer in root
+// and returns the new root and the removed value
+func remove(root interface{}, pointer string) (interface{}, interface{}, error) {
+	tokens, err := parsePointer(pointer)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(tokens) == 0 {
+		return nil, root, nil
+	}
+	parentPointer := pointer[:strings.LastIndex(pointer, "/")]
+	parent, err := get(root, parentPointer)
+	if err != nil {
+		return nil, nil, err
+	}
+	last := tokens[len(tokens)-1]
+	switch p := parent.(type) {
+	case map[string]interface{}:
+		v, ok := p[last]
+		if !ok {
+			return nil, nil, fmt.Errorf("path %q does not exist", pointer)
+		}
+		delete(p, last)
+		return root, v, nil
+	case []interface{}:
+		i, err := index(last, len(p))
+		if err != nil {
+			return nil, nil, err
+		}
+		v := p[i]
+		arr := append(p[:i:i], p[i+1:]...)
+		root, err = set(root, parentPointer, arr)
+		return root, v, err
+	}
+	return nil, nil, fmt.Errorf("path %q does not exist", pointer)
+}
+
+// set replaces the value at pointer in root and returns the new root
+func set(root interface{}, pointer string, value interface{}) (interface{}, error) {
+	tokens, err := parsePointer(pointer)
+	if err != nil {
+		return nil, err
+	}
+	if len(tokens) == 0 {
+		return value, nil
+	}
+	parent, err := get(root, pointer[:strings.LastIndex(pointer, "/")])
+	if err != nil {
+		return nil, err
+	}
+	last := tokens[len(tokens)-1]
+	switch p := parent.(type) {
+	case map[string]interface{}:
+		p[last] = value
+	case []interface{}:
+		i, err := index(last, len(p))
+		if err != nil {
+			return nil, err
+		}
+		p[i] = value
+	}
+	return root, nil
+}
+
+// index parses an array index token that must be less than n
+func index(token string, n int) (int, error) {
+	if token == "" || (len(token) > 1 && token[0] == '0') {
+		return 0, fmt.Errorf("invalid array index %q", token)
+	}
+	i, err := strconv.Atoi(token)
+	if err != nil || i < 0 || i >= n {
+		return 0, fmt.Errorf("array index %q out of bounds", token)
+	}
+	return i, nil
+}
+
+// decode decodes a JSON document preserving number precision
+func decode(doc []byte) (interface{}, error) {
+	var v interface{}
+	dec := json.NewDecoder(bytes.NewReader(doc))
+	dec.UseNumber()
+	if err := dec.Decode(&v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// deepCopy returns a copy of a decoded JSON value
+func deepCopy(v interface{}) interface{} {
+	switch t := v.(type) {
+	case map[string]interface{}:
+		m := make(map[string]interface{}, len(t))
+		for k, e := range t {
+			m[k] = deepCopy(e)
+		}
+		return m
+	case []interface{}:
+		arr := make([]interface{}, len(t))
+		for i, e := range t {
+			arr[i] = deepCopy(e)
+		}
+		return arr
+	}
+	return v
+}
+
+// equal compares decoded JSON values. Numbers
+// are compared by their numeric value
+func equal(a, b interface{}) bool {
+	na, aok := a.(json.Number)
+	nb, bok := b.(json.Number)
+	if aok && bok {
+		fa, err1 := na.Float64()
+		fb, err2 := nb.Float64()
+		if err1 == nil && err2 == nil {
+			return fa == fb
+		}
+		return na == nb
+	}
+	switch ta := a.(type) {
+	case map[string]interface{}:
+		tb, ok := b.(map[string]interface{})
+		if !ok || len(ta) != len(tb) {
+			return false
+		}
+		for k, v := range ta {
+			if w, ok := tb[k]; !ok || !equal(v, w) {
+				return false
+			}
+		}
+		return true
+	case []interface{}:
+		tb, ok := b.([]interface{})
+		if !ok || len(ta) != len(tb) {
+			return false
+		}
+		for i := range ta {
+			if !equal(ta[i], tb[i]) {
+				return false
+			}
+		}
+		return true
+	}
+	return reflect.DeepEqual(a, b)
+}
@@ -0,0 +1,77 @@
+package patch
+
+import (
+	"testing"
+)
+
+func TestApply(t *testing.T) {
+	defer func() {
+		err := recover()
+		if err != nil {
+			t.Errorf(err.(error).Error())
+		}
+	}()
+
+	err := "Failed apply."
+
+	doc := []byte(`{"name": "a", "tags": ["x", "y"], "meta": {"a/b": 1}}`)
+	tests := []struct {
+		patch    string
+		expected string
+	}{
+		{`[{"op": "add", "path": "/age", "value": 3}]`, `{"age":3,"meta":{"a/b":1},"name":"a","tags":["x","y"]}`},
+		{`[{"op": "add", "path": "/tags/1", "value": "z"}]`, `{"meta":{"a/b":1},"name":"a","tags":["x","z","y"]}`},
+		{`[{"op": "add", "path": "/tags/-", "value": "z"}]`, `{"meta":{"a/b":1},"name":"a","tags":["x","y","z"]}`},
+		{`[{"op": "remove", "path": "/tags/0"}]`, `{"meta":{"a/b":1},"name":"a","tags":["y"]}`},
+		{`[{"op": "replace", "path": "/meta/a~1b", "value": 2}]`, `{"meta":{"a/b":2},"name":"a","tags":["x","y"]}`},
+		{`[{"op": "move", "from": "/name", "path": "/title"}]`, `{"meta":{"a/b":1},"tags":["x","y"],"title":"a"}`},
+		{`[{"op": "copy", "from": "/tags", "path": "/labels"}]`, `{"labels":["x","y"],"meta":{"a/b":1},"name":"a","tags":["x","y"]}`},
+		{`[{"op": "test", "path": "/meta/a~1b", "value": 1.0}, {"op": "remove", "path": "/meta"}]`, `{"name":"a","tags":["x","y"]}`},
+	}
+	for _, test := range tests {
+		patched, e := Apply(doc, []byte(test.patch))
+		if e != nil || string(patched) != test.expected {
+			t.Errorf(err)
+		}
+	}
+
+	// Test failures
+	if _, e := Apply(doc, []byte(`[{"op": "test", "path": "/name", "value": "b"}]`)); e != ErrTestFailed {
+		t.Errorf(err)
+	}
+	failures := []string{
+		`[{"op": "remove", "path": "/missing"}]`,
+		`[{"op": "add", "path": "/tags/5", "value": 1}]`,
+		`[{"op": "replace", "path": "/name"}]`,
+		`[{"op": "move", "from": "/meta", "path": "/meta/child"}]`,
+		`[{"op": "frobnicate", "path": "/name"}]`,
+	}
+	for _, f := range failures {
+		if _, e := Apply(doc, []byte(f)); e == nil {
+			t.Errorf(err)
+		} else if _, ok := e.(*Error); !ok {
+			t.Errorf(err)
+		}
+	}
+}
+
+func TestMerge(t *testing.T) {
+	defer func() {
+		err := recover()
+		if err != nil {
+			t.Errorf(err.(error).Error())
+		}
+	}()
+
+	err := "Failed merge."
+
+	doc := []byte(`{"a": "b", "c": {"d": "e", "f": "g"}}`)
+	patched, e := Merge(doc, []byte(`{"a": "z", "c": {"f": null}, "h": [1]}`))
+	if e != nil || string(patched) != `{"a":"z","c":{"d":"e"},"h":[1]}` {
+		t.Errorf(err)
+	}
+	patched, e = Merge(doc, []byte(`["replaced"]`))
+	if e != nil || string(patched) != `["replaced"]` {
+		t.Errorf(err)
+	}
+}
@@ -0,0 +1,79 @@
+package verto
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type patchUser struct {
+	Name  string   `json:"name"`
+	Email string   `json:"email,omitempty"`
+	Tags  []string `json:"tags"`
+}
+
+func (u *patchUser) Validate() error {
+	if u.Name == "" {
+		return errors.New("Name is required.")
+	}
+	return nil
+}
+
+func TestPatch(t *testing.T) {
+	defer func() {
+		err := recover()
+		if err != nil {
+			t.Errorf(err.(error).Error())
+		}
+	}()
+
+	err := "Failed patch."
+
+	v := New()
+	v.Logger = &NilLogger{}
+	v.ResponseHandler = ResponseFunc(JSONResponseFunc)
+	v.Patch("/user", func(c *Context) (interface{}, error) {
+		user := &patchUser{Name: "a", Email: "a@test.com", Tags: []string{"x"}}
+		if err := c.Patch(user); err != nil {
+			return nil, err
+		}
+		return user, nil
+	})
+	h := &HttpHandler{v}
+
+	serve := func(contentType, body string) *httptest.ResponseRecorder {
+		r, _ := http.NewRequest("PATCH", "http://test.com/user", strings.NewReader(body))
+		r.Header.Set("Content-Type", contentType)
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		return w
+	}
+
+	w := serve(JSONPatchType, `[{"op": "replace", "path": "/name", "value": "b"}, {"op": "add", "path": "/tags/-", "value": "y"}]`)
+	if w.Code != 200 || w.Body.String() != `{"name":"b","email":"a@test.com","tags":["x","y"]}` {
+		t.Errorf(err)
+	}
+	w = serve(MergePatchType, `{"email": null, "tags": ["z"]}`)
+	if w.Code != 200 || w.Body.String() != `{"name":"a","tags":["z"]}` {
+		t.Errorf(err)
+	}
+
+	expected := []struct {
+		contentType string
+		body        string
+		code        int
+	}{
+		{"application/json", `{}`, http.StatusUnsupportedMediaType},
+		{JSONPatchType, `[{"op": "test", "path": "/name", "value": "b"}]`, http.StatusConflict},
+		{JSONPatchType, `[{"op": "remove", "path": "/missing"}]`, http.StatusUnprocessableEntity},
+		{MergePatchType, `{"unknown": 1}`, http.StatusUnprocessableEntity},
+		{MergePatchType, `{"name": ""}`, http.StatusUnprocessableEntity},
+	}
+	for _, e := range expected {
+		if serve(e.contentType, e.body).Code != e.code {
+			t.Errorf(err)
+		}
+	}
+}
@@ -0,0 +1,64 @@
+package verto
+
+import (
+	"context"
+	"net/http"
+	"sync/atomic"
+)
+
+// PayloadSize records the size of a response body before and after
+// content encoding. Metrics plugins attach a PayloadSize to requests
+// with WithPayloadSize and encoding writers such as the compression
+// plugin's report the bytes they encode to it
+type PayloadSize struct {
+	body    int64
+	wire    int64
+	encoded int32
+}
+
+// Body returns the number of response body bytes written
+// by the handler before content encoding
+func (ps *PayloadSize) Body() int64 {
+	return atomic.LoadInt64(&ps.body)
+}
+
+// Wire returns the number of response body bytes
+// sent to the client after content encoding
+func (ps *PayloadSize) Wire() int64 {
+	return atomic.LoadInt64(&ps.wire)
+}
+
+// Encoded returns whether an encoding writer reported
+// the sizes of the response
+func (ps *PayloadSize) Encoded() bool {
+	return atomic.LoadInt32(&ps.encoded) == 1
+}
+
+// AddBody records n body bytes written before encoding
+func (ps *PayloadSize) AddBody(n int) {
+	atomic.StoreInt32(&ps.encoded, 1)
+	atomic.AddInt64(&ps.body, int64(n))
+}
+
+// AddWire records n body bytes sent after encoding
+func (ps *PayloadSize) AddWire(n int) {
+	atomic.StoreInt32(&ps.encoded, 1)
+	atomic.AddInt64(&ps.wire, int64(n))
+}
+
+// payloadSizeKey is the context key of the PayloadSize of a request
+type payloadSizeKey struct{}
+
+// WithPayloadSize returns a copy of r carrying a new PayloadSize
+// and the PayloadSize
+func WithPayloadSize(r *http.Request) (*http.Request, *PayloadSize) {
+	ps := &PayloadSize{}
+	return r.WithContext(context.WithValue(r.Context(), payloadSizeKey{}, ps)), ps
+}
+
+// RequestPayloadSize returns the PayloadSize attached to r
+// with WithPayloadSize or nil if none is attached
+func RequestPayloadSize(r *http.Request) *PayloadSize {
+	ps, _ := r.Context().Value(payloadSizeKey{}).(*PayloadSize)
+	return ps
+}
@@ -0,0 +1,31 @@
+package verto
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestPayloadSize(t *testing.T) {
+	defer func() {
+		err := recover()
+		if err != nil {
+			t.Errorf(err.(error).Error())
+		}
+	}()
+
+	err := "Failed payload size."
+
+	r, _ := http.NewRequest("GET", "http://test.com", nil)
+	if RequestPayloadSize(r) != nil {
+		t.Errorf(err)
+	}
+	r, ps := WithPayloadSize(r)
+	if RequestPayloadSize(r) != ps || ps.Encoded() {
+		t.Errorf(err)
+	}
+	ps.AddBody(10)
+	ps.AddWire(4)
+	if !ps.Encoded() || ps.Body() != 10 || ps.Wire() != 4 {
+		t.Errorf(err)
+	}
+}
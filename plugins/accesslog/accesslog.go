@@ -0,0 +1,206 @@
+// Package accesslog provides a plugin writing one JSON record per
+// request to an access log. Records can be anonymized for data
+// protection compliance: client IPs can be truncated or hashed,
+// configured headers and fields dropped, and handlers of sensitive
+// routes can opt their requests out of the log with verto.DoNotLog.
+package accesslog
+
+import (
+	"bufio"
+	"encoding/json"
+	"github.com/boxtown/verto"
+	"github.com/boxtown/verto/plugins"
+	"io"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Record fields written by the access log
+const (
+	FieldTime      = "time"
+	FieldMethod    = "method"
+	FieldPath      = "path"
+	FieldQuery     = "query"
+	FieldStatus    = "status"
+	FieldBytes     = "bytes"
+	FieldDuration  = "duration_ms"
+	FieldIP        = "ip"
+	FieldUserAgent = "user_agent"
+	FieldReferer   = "referer"
+	FieldHeader    = "header"
+)
+
+// AccessLog is a plugin that writes a JSON record of every request
+// to Out once the request is handled. If Out is nil, records are
+// written to the request's Logger at the info level. The promoted
+// LogFields of Verto (e.g. user or trace ids) are included in records.
+//
+// Example usage:
+//
+//	log := accesslog.New(os.Stdout)
+//	log.Headers = []string{"X-Request-Id"}
+//	log.Anonymizer = &verto.Anonymizer{TruncateIPs: true, DropFields: []string{"user_agent"}}
+//	v.Use(log)
+//
+//	v.Post("/login", func(c *verto.Context) (interface{}, error) {
+//		verto.DoNotLog(c)
+//		...
+//	})
+type AccessLog struct {
+	// Core is the core functionality for plugins
+	plugins.Core
+
+	// Out is the destination of records
+	Out io.Writer
+
+	// Headers lists the request headers included in records
+	Headers []string
+
+	// Anonymizer optionally scrubs personal data from records
+	Anonymizer *verto.Anonymizer
+
+	// Now returns the current time. Defaults to time.Now
+	Now func() time.Time
+
+	mutex sync.Mutex
+}
+
+// New returns a newly initialized AccessLog plugin writing to out
+func New(out io.Writer) *AccessLog {
+	return &AccessLog{
+		Core: plugins.Core{Id: "plugins.AccessLog"},
+		Out:  out,
+	}
+}
+
+// Handle is called per web request to log the request
+// once it is handled
+func (plugin *AccessLog) Handle(c *verto.Context, next http.HandlerFunc) {
+	plugin.Core.Handle(
+		func(c *verto.Context, next http.HandlerFunc) {
+			start := plugin.now()
+			r := verto.WithLogFlag(c.Request)
+			lw := &logWriter{ResponseWriter: c.Response}
+			next(lw, r)
+			if verto.LogSuppressed(r) {
+				return
+			}
+			c.Request = r
+			plugin.write(c, plugin.record(c, lw, start))
+		}, c, next)
+}
+
+// record returns the fields of the record of the request of c,
+// including the promoted LogFields, with the dropped fields removed
+func (plugin *AccessLog) record(c *verto.Context, lw *logWriter, start time.Time) map[string]interface{} {
+	r := c.Request
+	a := plugin.Anonymizer
+	status := lw.status
+	if status == 0 {
+		status = http.StatusOK
+	}
+
+	record := map[string]interface{}{
+		FieldTime:      start.UTC().Format(time.RFC3339Nano),
+		FieldMethod:    r.Method,
+		FieldPath:      r.URL.Path,
+		FieldQuery:     r.URL.RawQuery,
+		FieldStatus:    status,
+		FieldBytes:     lw.n,
+		FieldDuration:  float64(plugin.now().Sub(start)) / float64(time.Millisecond),
+		FieldIP:        a.IP(verto.GetIP(r)),
+		FieldUserAgent: r.UserAgent(),
+		FieldReferer:   r.Referer(),
+	}
+	for name, value := range c.LogFields() {
+		if _, ok := record[name]; !ok {
+			record[name] = value
+		}
+	}
+	if len(plugin.Headers) > 0 {
+		h := make(http.Header)
+		for _, name := range plugin.Headers {
+			if values := r.Header.Values(name); len(values) > 0 {
+				h[http.CanonicalHeaderKey(name)] = values
+			}
+		}
+		if h = a.Header(h); len(h) > 0 {
+			record[FieldHeader] = h
+		}
+	}
+	for field := range record {
+		if a.Drops(field) {
+			delete(record, field)
+		}
+	}
+	return record
+}
+
+// write writes record to Out or the Logger of c
+func (plugin *AccessLog) write(c *verto.Context, record map[string]interface{}) {
+	b, err := json.Marshal(record)
+	if err != nil {
+		return
+	}
+	if plugin.Out == nil {
+		if c.Logger != nil {
+			c.Logger.Infof("%s", b)
+		}
+		return
+	}
+
+	plugin.mutex.Lock()
+	defer plugin.mutex.Unlock()
+	plugin.Out.Write(append(b, '\n'))
+}
+
+func (plugin *AccessLog) now() time.Time {
+	if plugin.Now == nil {
+		return time.Now()
+	}
+	return plugin.Now()
+}
+
+// logWriter is an http.ResponseWriter that records the
+// status and number of body bytes written
+type logWriter struct {
+	http.ResponseWriter
+
+	status int
+	n      int64
+}
+
+func (w *logWriter) WriteHeader(code int) {
+	if w.status == 0 {
+		w.status = code
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *logWriter) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	n, err := w.ResponseWriter.Write(b)
+	w.n += int64(n)
+	return n, err
+}
+
+// Flush implements http.Flusher
+func (w *logWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Hijack implements http.Hijacker. Hijacked
+// connections are logged as protocol switches
+func (w *logWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	conn, rw, err := verto.Hijack(w.ResponseWriter)
+	if err == nil && w.status == 0 {
+		w.status = http.StatusSwitchingProtocols
+	}
+	return conn, rw, err
+}
@@ -0,0 +1,82 @@
+package accesslog
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/boxtown/verto"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestAccessLog(t *testing.T) {
+	defer func() {
+		err := recover()
+		if err != nil {
+			t.Errorf(err.(error).Error())
+		}
+	}()
+
+	err := "Failed access log."
+
+	out := &bytes.Buffer{}
+	log := New(out)
+	log.Headers = []string{"X-Request-Id", "Cookie"}
+	log.Anonymizer = &verto.Anonymizer{
+		TruncateIPs: true,
+		DropHeaders: []string{"Cookie"},
+		DropFields:  []string{FieldUserAgent},
+	}
+
+	v := verto.New()
+	v.Logger = &verto.NilLogger{}
+	v.LogFields = []verto.LogField{verto.InjectionField("tenant", "tenant")}
+	v.Injections.Set("tenant", "acme")
+	v.Get("/users", func(c *verto.Context) (interface{}, error) {
+		return "users", nil
+	})
+	v.Post("/login", func(c *verto.Context) (interface{}, error) {
+		verto.DoNotLog(c)
+		return "ok", nil
+	})
+	v.Use(log)
+	h := &verto.HttpHandler{v}
+
+	r, _ := http.NewRequest("GET", "http://test.com/users?page=2", nil)
+	r.RemoteAddr = "203.0.113.57:1234"
+	r.Header.Set("X-Request-Id", "abc")
+	r.Header.Set("Cookie", "session=secret")
+	r.Header.Set("User-Agent", "test")
+	h.ServeHTTP(httptest.NewRecorder(), r)
+	r, _ = http.NewRequest("POST", "http://test.com/login", nil)
+	h.ServeHTTP(httptest.NewRecorder(), r)
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 1 {
+		t.Fatalf(err)
+	}
+	record := struct {
+		Method    string
+		Path      string
+		Query     string
+		Status    int
+		Bytes     int64
+		IP        string
+		UserAgent *string `json:"user_agent"`
+		Tenant    string
+		Header    http.Header
+	}{}
+	if e := json.Unmarshal([]byte(lines[0]), &record); e != nil {
+		t.Fatalf(err)
+	}
+	if record.Method != "GET" || record.Path != "/users" || record.Query != "page=2" || record.Status != 200 || record.Bytes != 5 {
+		t.Errorf(err)
+	}
+	if record.IP != "203.0.113.0" || record.UserAgent != nil || record.Tenant != "acme" {
+		t.Errorf(err)
+	}
+	if record.Header.Get("X-Request-Id") != "abc" || record.Header.Get("Cookie") != "" {
+		t.Errorf(err)
+	}
+}
@@ -0,0 +1,258 @@
+// Package authz provides role- and permission-based access control for
+// Verto routes. Access rules are attached per group or per endpoint as
+// plugins and are evaluated against the request's principal using a
+// pluggable Policy.
+package authz
+
+import (
+	"fmt"
+	"github.com/boxtown/verto"
+	"github.com/boxtown/verto/plugins"
+	"net/http"
+)
+
+// PRINCIPALKEY is the injection key authentication plugins should use
+// to expose the authenticated principal of a request. Principals are
+// generally registered as a lazy injection with a REQUEST LifeTime.
+const PRINCIPALKEY = "_VertoPrincipal"
+
+// Principal is the interface for authenticated request principals
+type Principal interface {
+	// ID returns a unique identifier for the principal
+	ID() string
+
+	// Roles returns the roles held by the principal
+	Roles() []string
+}
+
+// User is a simple implementation of the Principal interface
+type User struct {
+	Id        string
+	RoleNames []string
+}
+
+// ID returns the id of the user
+func (u *User) ID() string {
+	return u.Id
+}
+
+// Roles returns the roles held by the user
+func (u *User) Roles() []string {
+	return u.RoleNames
+}
+
+// Policy is the interface for access control policy engines
+type Policy interface {
+	// HasRole returns whether the principal holds role
+	HasRole(p Principal, role string) (bool, error)
+
+	// HasPermission returns whether the principal holds permission
+	HasPermission(p Principal, permission string) (bool, error)
+}
+
+// StaticPolicy is a Policy backed by a static map of role
+// names to the permissions they grant. The permission '*'
+// grants all permissions.
+type StaticPolicy map[string][]string
+
+// HasRole returns whether role is one of the principal's roles
+func (sp StaticPolicy) HasRole(p Principal, role string) (bool, error) {
+	for _, r := range p.Roles() {
+		if r == role {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// HasPermission returns whether any of the principal's roles
+// grants permission
+func (sp StaticPolicy) HasPermission(p Principal, permission string) (bool, error) {
+	for _, r := range p.Roles() {
+		for _, perm := range sp[r] {
+			if perm == permission || perm == wc {
+				return true, nil
+			}
+		}
+	}
+	return false, nil
+}
+
+// Enforcer is the interface implemented by Casbin-style enforcers
+// that decide on subject, object, action triples
+type Enforcer interface {
+	Enforce(rvals ...interface{}) (bool, error)
+}
+
+// EnforcerPolicy adapts an Enforcer to the Policy interface. Permissions
+// are expected in the form "object:action" and are enforced as
+// (principal ID, object, action). Roles are checked against the
+// principal's own roles.
+type EnforcerPolicy struct {
+	Enforcer Enforcer
+}
+
+// HasRole returns whether role is one of the principal's roles
+func (ep EnforcerPolicy) HasRole(p Principal, role string) (bool, error) {
+	return StaticPolicy(nil).HasRole(p, role)
+}
+
+// HasPermission enforces permission through the wrapped Enforcer
+func (ep EnforcerPolicy) HasPermission(p Principal, permission string) (bool, error) {
+	obj, act := permission, ""
+	for i := len(permission) - 1; i >= 0; i-- {
+		if permission[i] == ':' {
+			obj, act = permission[:i], permission[i+1:]
+			break
+		}
+	}
+	return ep.Enforcer.Enforce(p.ID(), obj, act)
+}
+
+// DefaultPolicy is the Policy used by plugins created with Require
+// and RequirePermission. The default policy grants no permissions.
+var DefaultPolicy Policy = StaticPolicy{}
+
+// Authz is a plugin that restricts access to routes to principals
+// satisfying a set of roles and permissions. Requests without a
+// principal receive a 401 response and requests whose principal
+// does not satisfy the requirements receive a 403 response.
+//
+// Example usage:
+//
+//	policy := authz.StaticPolicy{"editor": {"articles:write"}}
+//
+//	admin := v.Group("GET", "/admin").Use(authz.Require("admin"))
+//	v.Post("/articles", handler).Use(authz.RequirePermission("articles:write").WithPolicy(policy))
+type Authz struct {
+	// Core is the core functionality for plugins
+	plugins.Core
+
+	// Policy is the policy engine used to evaluate requirements
+	Policy Policy
+
+	// Roles lists roles of which the principal must hold at least one
+	Roles []string
+
+	// Permissions lists permissions the principal must hold all of
+	Permissions []string
+
+	// PrincipalFn retrieves the principal for a request. If nil,
+	// the principal is retrieved with From
+	PrincipalFn func(c *verto.Context) Principal
+
+	// OnDenied is an optional function for writing denied responses.
+	// status is either 401 or 403
+	OnDenied func(status int, c *verto.Context)
+}
+
+// Require returns an Authz plugin requiring one of roles
+func Require(roles ...string) *Authz {
+	return &Authz{
+		Core:   plugins.Core{Id: "plugins.Authz"},
+		Policy: DefaultPolicy,
+		Roles:  roles,
+	}
+}
+
+// RequirePermission returns an Authz plugin requiring all of permissions
+func RequirePermission(permissions ...string) *Authz {
+	return &Authz{
+		Core:        plugins.Core{Id: "plugins.Authz"},
+		Policy:      DefaultPolicy,
+		Permissions: permissions,
+	}
+}
+
+// WithPolicy sets the policy engine for the plugin and returns the plugin
+func (plugin *Authz) WithPolicy(policy Policy) *Authz {
+	plugin.Policy = policy
+	return plugin
+}
+
+// Handle is called per web request to check the request's principal
+// against the plugin's requirements
+func (plugin *Authz) Handle(c *verto.Context, next http.HandlerFunc) {
+	plugin.Core.Handle(
+		func(c *verto.Context, next http.HandlerFunc) {
+			var p Principal
+			if plugin.PrincipalFn != nil {
+				p = plugin.PrincipalFn(c)
+			} else {
+				p = From(c)
+			}
+			if p == nil {
+				plugin.deny(http.StatusUnauthorized, c)
+				return
+			}
+
+			allowed, err := plugin.allowed(p)
+			if err != nil && c.Logger != nil {
+				c.Logger.Errorf("authz: policy error: %s", err.Error())
+			}
+			if !allowed {
+				plugin.deny(http.StatusForbidden, c)
+				return
+			}
+			next(c.Response, c.Request)
+		}, c, next)
+}
+
+// allowed evaluates the plugin's requirements for p
+func (plugin *Authz) allowed(p Principal) (bool, error) {
+	policy := plugin.Policy
+	if policy == nil {
+		policy = DefaultPolicy
+	}
+
+	if len(plugin.Roles) > 0 {
+		hasRole := false
+		for _, role := range plugin.Roles {
+			ok, err := policy.HasRole(p, role)
+			if err != nil {
+				return false, err
+			}
+			if ok {
+				hasRole = true
+				break
+			}
+		}
+		if !hasRole {
+			return false, nil
+		}
+	}
+
+	for _, perm := range plugin.Permissions {
+		ok, err := policy.HasPermission(p, perm)
+		if err != nil || !ok {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+// deny writes a denied response with status
+func (plugin *Authz) deny(status int, c *verto.Context) {
+	if plugin.OnDenied != nil {
+		plugin.OnDenied(status, c)
+		return
+	}
+	c.Response.WriteHeader(status)
+	fmt.Fprint(c.Response, http.StatusText(status)+".")
+}
+
+// From retrieves the principal injected at PRINCIPALKEY for the
+// request or nil if no principal exists
+func From(c *verto.Context) Principal {
+	if c.Injections == nil {
+		return nil
+	}
+	i := c.Injections()
+	if i == nil {
+		return nil
+	}
+	p, _ := i.Get(PRINCIPALKEY).(Principal)
+	return p
+}
+
+const wc string = "*"
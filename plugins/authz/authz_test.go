@@ -0,0 +1,58 @@
+package authz
+
+import (
+	"github.com/boxtown/verto"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestAuthzPlugin(t *testing.T) {
+	defer func() {
+		err := recover()
+		if err != nil {
+			t.Errorf(err.(error).Error())
+		}
+	}()
+
+	err := "Failed authz."
+
+	v := verto.New()
+	v.Injections.Lazy(PRINCIPALKEY, func(w http.ResponseWriter, r *http.Request, i verto.ReadOnlyInjections) interface{} {
+		switch r.Header.Get("X-User") {
+		case "admin":
+			return &User{Id: "1", RoleNames: []string{"admin"}}
+		case "editor":
+			return &User{Id: "2", RoleNames: []string{"editor"}}
+		}
+		return nil
+	}, verto.REQUEST)
+
+	policy := StaticPolicy{"editor": {"articles:write"}, "admin": {"*"}}
+	handler := func(c *verto.Context) (interface{}, error) {
+		return "ok", nil
+	}
+	v.Get("/admin", handler).Use(Require("admin"))
+	v.Post("/articles", handler).Use(RequirePermission("articles:write").WithPolicy(policy))
+
+	h := &verto.HttpHandler{Verto: v}
+	cases := []struct {
+		method, path, user string
+		code               int
+	}{
+		{"GET", "/admin", "", 401},
+		{"GET", "/admin", "editor", 403},
+		{"GET", "/admin", "admin", 200},
+		{"POST", "/articles", "editor", 200},
+		{"POST", "/articles", "admin", 200},
+	}
+	for _, tc := range cases {
+		r, _ := http.NewRequest(tc.method, "http://test.com"+tc.path, nil)
+		r.Header.Set("X-User", tc.user)
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		if w.Code != tc.code {
+			t.Errorf(err)
+		}
+	}
+}
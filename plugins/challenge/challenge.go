@@ -0,0 +1,290 @@
+// Package challenge provides an anti-automation plugin for Verto. Clients
+// that are required to pass a challenge receive a 403 response describing
+// the challenge and must repeat the request with a solution. Challenges
+// are answered through a captcha Provider or, as a fallback usable by
+// non-browser clients, a proof-of-work puzzle. Clients that passed a
+// challenge receive a signed pass cookie exempting them for a while.
+package challenge
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"github.com/boxtown/verto"
+	"github.com/boxtown/verto/plugins"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ResponseHeader is the request header carrying challenge solutions
+// in the form "<provider> <response>" (e.g. "pow <token>:<counter>")
+const ResponseHeader = "X-Challenge-Response"
+
+// ResponseField is the form field carrying challenge solutions
+// in the same form as ResponseHeader
+const ResponseField = "challenge_response"
+
+// PASSCOOKIE is the name of the cookie exempting clients that
+// passed a challenge
+const PASSCOOKIE = "_VertoChallengePass"
+
+// Provider is the interface for challenge providers such as captchas
+type Provider interface {
+	// Name identifies the provider in challenge responses
+	// and solutions
+	Name() string
+
+	// Verify returns whether response solves the challenge
+	Verify(r *http.Request, response string) (bool, error)
+}
+
+// Details is the JSON body of challenge responses
+type Details struct {
+	// Providers lists the names of the providers accepted
+	// as solutions
+	Providers []string `json:"providers"`
+
+	// Token is the proof-of-work token to solve
+	Token string `json:"token,omitempty"`
+
+	// Difficulty is the proof-of-work difficulty
+	Difficulty int `json:"difficulty,omitempty"`
+}
+
+// Challenge is a plugin that requires clients to pass a challenge. It can
+// be used on routes that always require a challenge (e.g. signups) or be
+// triggered for individual clients, e.g. by a rate limiter or quota plugin
+// detecting abuse.
+//
+// Example usage:
+//
+//	ch := challenge.New(secret, nil)
+//	v.Post("/signup", signup).Use(ch.Require())
+//
+//	api.Use(ch)
+//	q.OnExceeded = func(key string, c *verto.Context) {
+//		ch.Flag(verto.GetIP(c.Request), time.Hour)
+//	}
+type Challenge struct {
+	// Core is the core functionality for plugins
+	plugins.Core
+
+	// Provider is an optional captcha provider
+	Provider Provider
+
+	// Fallback is the proof-of-work provider offered in addition
+	// to Provider. Set to nil to only accept Provider solutions
+	Fallback *ProofOfWork
+
+	// Always requires a challenge from all clients
+	Always bool
+
+	// KeyFn identifies the client of a request for flagging and
+	// pass cookies. Defaults to the client IP
+	KeyFn func(c *verto.Context) string
+
+	// PassTTL is how long clients that passed a challenge are
+	// exempt. Defaults to 30 minutes
+	PassTTL time.Duration
+
+	// Now returns the current time. Defaults to time.Now
+	Now func() time.Time
+
+	secret  []byte
+	flagged map[string]time.Time
+	mutex   *sync.Mutex
+}
+
+// New returns a Challenge plugin that only challenges flagged clients.
+// secret signs pass cookies and proof-of-work tokens. provider is an
+// optional captcha provider. A proof-of-work fallback with a difficulty
+// of 20 bits is always offered unless Fallback is set to nil
+func New(secret []byte, provider Provider) *Challenge {
+	return &Challenge{
+		Core:     plugins.Core{Id: "plugins.Challenge"},
+		Provider: provider,
+		Fallback: NewProofOfWork(secret, 20),
+		PassTTL:  30 * time.Minute,
+		secret:   secret,
+		flagged:  make(map[string]time.Time),
+		mutex:    &sync.Mutex{},
+	}
+}
+
+// Require returns a plugin that challenges every client, sharing
+// the providers, flags and pass cookies of ch
+func (ch *Challenge) Require() verto.Plugin {
+	return verto.PluginFunc(func(c *verto.Context, next http.HandlerFunc) {
+		ch.Core.Handle(func(c *verto.Context, next http.HandlerFunc) {
+			ch.challenge(c, next, true)
+		}, c, next)
+	})
+}
+
+// Flag requires a challenge from the client identified by key for d
+func (ch *Challenge) Flag(key string, d time.Duration) {
+	ch.mutex.Lock()
+	defer ch.mutex.Unlock()
+
+	now := ch.now()
+	for k, until := range ch.flagged {
+		if now.After(until) {
+			delete(ch.flagged, k)
+		}
+	}
+	ch.flagged[key] = now.Add(d)
+}
+
+// Unflag removes the flag of the client identified by key
+func (ch *Challenge) Unflag(key string) {
+	ch.mutex.Lock()
+	defer ch.mutex.Unlock()
+
+	delete(ch.flagged, key)
+}
+
+// Flagged returns whether the client identified by key is flagged
+func (ch *Challenge) Flagged(key string) bool {
+	ch.mutex.Lock()
+	defer ch.mutex.Unlock()
+
+	until, ok := ch.flagged[key]
+	return ok && ch.now().Before(until)
+}
+
+// Handle is called per web request to challenge flagged clients
+// or all clients if Always is set
+func (ch *Challenge) Handle(c *verto.Context, next http.HandlerFunc) {
+	ch.Core.Handle(func(c *verto.Context, next http.HandlerFunc) {
+		ch.challenge(c, next, ch.Always)
+	}, c, next)
+}
+
+// challenge challenges the client of the request if required
+func (ch *Challenge) challenge(c *verto.Context, next http.HandlerFunc, always bool) {
+	key := ch.key(c)
+	if (!always && !ch.Flagged(key)) || ch.passed(c.Request, key) {
+		next(c.Response, c.Request)
+		return
+	}
+
+	if solution := solutionOf(c.Request); solution != "" {
+		ok, err := ch.verify(c.Request, solution)
+		if err != nil && c.Logger != nil {
+			c.Logger.Errorf("challenge: could not verify solution: %s", err.Error())
+		}
+		if ok {
+			ch.pass(c.Response, key)
+			next(c.Response, c.Request)
+			return
+		}
+	}
+	ch.deny(c)
+}
+
+// verify verifies a solution of the form "<provider> <response>"
+func (ch *Challenge) verify(r *http.Request, solution string) (bool, error) {
+	parts := strings.SplitN(solution, " ", 2)
+	if len(parts) != 2 {
+		return false, nil
+	}
+	if ch.Provider != nil && parts[0] == ch.Provider.Name() {
+		return ch.Provider.Verify(r, parts[1])
+	}
+	if ch.Fallback != nil && parts[0] == ch.Fallback.Name() {
+		return ch.Fallback.Verify(r, parts[1])
+	}
+	return false, nil
+}
+
+// deny writes a challenge response
+func (ch *Challenge) deny(c *verto.Context) {
+	details := Details{Providers: make([]string, 0, 2)}
+	if ch.Provider != nil {
+		details.Providers = append(details.Providers, ch.Provider.Name())
+	}
+	if ch.Fallback != nil {
+		token, err := ch.Fallback.Issue()
+		if err == nil {
+			details.Providers = append(details.Providers, ch.Fallback.Name())
+			details.Token = token
+			details.Difficulty = ch.Fallback.Difficulty
+		} else if c.Logger != nil {
+			c.Logger.Errorf("challenge: could not issue token: %s", err.Error())
+		}
+	}
+
+	h := c.Response.Header()
+	h.Set("Content-Type", "application/json")
+	h.Set("Cache-Control", "no-store")
+	c.Response.WriteHeader(http.StatusForbidden)
+	json.NewEncoder(c.Response).Encode(details)
+}
+
+// passed returns whether the request carries a valid
+// pass cookie for key
+func (ch *Challenge) passed(r *http.Request, key string) bool {
+	cookie, err := r.Cookie(PASSCOOKIE)
+	if err != nil {
+		return false
+	}
+	i := strings.Index(cookie.Value, ".")
+	if i < 0 {
+		return false
+	}
+	expiry, err := strconv.ParseInt(cookie.Value[:i], 10, 64)
+	if err != nil || ch.now().Unix() > expiry {
+		return false
+	}
+	return hmac.Equal([]byte(cookie.Value[i+1:]), []byte(ch.sign(key, cookie.Value[:i])))
+}
+
+// pass writes a pass cookie bound to key
+func (ch *Challenge) pass(w http.ResponseWriter, key string) {
+	ttl := ch.PassTTL
+	if ttl <= 0 {
+		ttl = 30 * time.Minute
+	}
+	expiry := strconv.FormatInt(ch.now().Add(ttl).Unix(), 10)
+	http.SetCookie(w, &http.Cookie{
+		Name:     PASSCOOKIE,
+		Value:    expiry + "." + ch.sign(key, expiry),
+		Path:     "/",
+		MaxAge:   int(ttl / time.Second),
+		HttpOnly: true,
+	})
+}
+
+// sign returns the hex encoded HMAC binding expiry to key
+func (ch *Challenge) sign(key, expiry string) string {
+	mac := hmac.New(sha256.New, ch.secret)
+	mac.Write([]byte("pass:" + key + ":" + expiry))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// key returns the client key of the request
+func (ch *Challenge) key(c *verto.Context) string {
+	if ch.KeyFn != nil {
+		return ch.KeyFn(c)
+	}
+	return verto.GetIP(c.Request)
+}
+
+func (ch *Challenge) now() time.Time {
+	if ch.Now == nil {
+		return time.Now()
+	}
+	return ch.Now()
+}
+
+// solutionOf returns the challenge solution of the request
+func solutionOf(r *http.Request) string {
+	if s := r.Header.Get(ResponseHeader); s != "" {
+		return s
+	}
+	return r.FormValue(ResponseField)
+}
@@ -0,0 +1,143 @@
+package challenge
+
+import (
+	"encoding/json"
+	"github.com/boxtown/verto"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+type testProvider struct{}
+
+func (p *testProvider) Name() string {
+	return "captcha"
+}
+
+func (p *testProvider) Verify(r *http.Request, response string) (bool, error) {
+	return response == "human", nil
+}
+
+func TestChallenge(t *testing.T) {
+	defer func() {
+		err := recover()
+		if err != nil {
+			t.Errorf(err.(error).Error())
+		}
+	}()
+
+	err := "Failed challenge."
+
+	ch := New([]byte("secret"), &testProvider{})
+	ch.Fallback.Difficulty = 8
+
+	v := verto.New()
+	v.Logger = &verto.NilLogger{}
+	v.Get("/signup", func(c *verto.Context) (interface{}, error) { return "ok", nil }).Use(ch.Require())
+	v.Get("/api", func(c *verto.Context) (interface{}, error) { return "ok", nil }).Use(ch)
+	h := &verto.HttpHandler{v}
+
+	serve := func(path, solution string, cookie *http.Cookie) *httptest.ResponseRecorder {
+		r, _ := http.NewRequest("GET", "http://test.com"+path, nil)
+		r.RemoteAddr = "1.2.3.4:5000"
+		if solution != "" {
+			r.Header.Set(ResponseHeader, solution)
+		}
+		if cookie != nil {
+			r.AddCookie(cookie)
+		}
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		return w
+	}
+
+	// Test unflagged clients pass
+	if serve("/api", "", nil).Code != http.StatusOK {
+		t.Errorf(err)
+	}
+
+	// Test required challenges
+	w := serve("/signup", "", nil)
+	details := Details{}
+	json.Unmarshal(w.Body.Bytes(), &details)
+	if w.Code != http.StatusForbidden || len(details.Providers) != 2 || details.Token == "" || details.Difficulty != 8 {
+		t.Fatalf(err)
+	}
+	if serve("/signup", "captcha robot", nil).Code != http.StatusForbidden {
+		t.Errorf(err)
+	}
+	w = serve("/signup", "captcha human", nil)
+	if w.Code != http.StatusOK || len(w.Result().Cookies()) != 1 {
+		t.Fatalf(err)
+	}
+
+	// Test pass cookies exempt their client only
+	pass := w.Result().Cookies()[0]
+	if serve("/signup", "", pass).Code != http.StatusOK {
+		t.Errorf(err)
+	}
+	ch.KeyFn = func(c *verto.Context) string { return "other" }
+	if serve("/signup", "", pass).Code != http.StatusForbidden {
+		t.Errorf(err)
+	}
+	ch.KeyFn = nil
+
+	// Test flagged clients and proof-of-work solutions
+	ch.Flag("1.2.3.4", time.Hour)
+	w = serve("/api", "", nil)
+	json.Unmarshal(w.Body.Bytes(), &details)
+	if w.Code != http.StatusForbidden {
+		t.Fatalf(err)
+	}
+	solution := "pow " + Solve(details.Token, details.Difficulty)
+	if serve("/api", solution, nil).Code != http.StatusOK {
+		t.Errorf(err)
+	}
+	if serve("/api", solution, nil).Code != http.StatusForbidden {
+		t.Errorf(err)
+	}
+	ch.Unflag("1.2.3.4")
+	if serve("/api", "", nil).Code != http.StatusOK {
+		t.Errorf(err)
+	}
+}
+
+func TestProofOfWork(t *testing.T) {
+	defer func() {
+		err := recover()
+		if err != nil {
+			t.Errorf(err.(error).Error())
+		}
+	}()
+
+	err := "Failed proof of work."
+
+	now := time.Unix(1000, 0)
+	pow := NewProofOfWork([]byte("secret"), 10)
+	pow.Now = func() time.Time { return now }
+
+	token, _ := pow.Issue()
+	solution := Solve(token, 10)
+	if ok, _ := pow.Verify(nil, "1.2.3:0"); ok {
+		t.Errorf(err)
+	}
+
+	// Test expiry
+	now = now.Add(time.Hour)
+	if ok, _ := pow.Verify(nil, solution); ok {
+		t.Errorf(err)
+	}
+	token, _ = pow.Issue()
+	if ok, _ := pow.Verify(nil, Solve(token, 10)); !ok {
+		t.Errorf(err)
+	}
+
+	// Test forged tokens
+	other := NewProofOfWork([]byte("other"), 10)
+	other.Now = pow.Now
+	token, _ = other.Issue()
+	if ok, _ := pow.Verify(nil, Solve(token, 10)); ok {
+		t.Errorf(err)
+	}
+}
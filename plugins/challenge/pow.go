@@ -0,0 +1,149 @@
+package challenge
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ProofOfWork is a Provider requiring clients to solve a hashcash-style
+// puzzle: for an issued token, find a counter such that the SHA-256 hash
+// of "<token>:<counter>" has at least Difficulty leading zero bits. Tokens
+// are signed and expire, so no per-client state is kept until a token has
+// been solved. Solved tokens cannot be reused.
+type ProofOfWork struct {
+	// Difficulty is the number of leading zero bits required.
+	// Each additional bit doubles the expected work
+	Difficulty int
+
+	// TTL is the lifetime of issued tokens. Defaults to 5 minutes
+	TTL time.Duration
+
+	// Now returns the current time. Defaults to time.Now
+	Now func() time.Time
+
+	secret []byte
+	used   map[string]time.Time
+	mutex  *sync.Mutex
+}
+
+// NewProofOfWork returns a ProofOfWork provider signing tokens
+// with secret and requiring difficulty leading zero bits
+func NewProofOfWork(secret []byte, difficulty int) *ProofOfWork {
+	return &ProofOfWork{
+		Difficulty: difficulty,
+		TTL:        5 * time.Minute,
+		secret:     secret,
+		used:       make(map[string]time.Time),
+		mutex:      &sync.Mutex{},
+	}
+}
+
+// Name returns "pow"
+func (p *ProofOfWork) Name() string {
+	return "pow"
+}
+
+// Issue returns a new signed token of the form <expiry>.<nonce>.<signature>
+func (p *ProofOfWork) Issue() (string, error) {
+	b := make([]byte, 16)
+	if _, err := io.ReadFull(rand.Reader, b); err != nil {
+		return "", err
+	}
+	payload := strconv.FormatInt(p.now().Add(p.ttl()).Unix(), 10) + "." + hex.EncodeToString(b)
+	return payload + "." + p.sign(payload), nil
+}
+
+// Verify returns whether response is a valid solution of the form
+// <token>:<counter> for an unexpired token issued by p
+func (p *ProofOfWork) Verify(r *http.Request, response string) (bool, error) {
+	i := strings.LastIndex(response, ":")
+	if i < 0 {
+		return false, nil
+	}
+	token := response[:i]
+
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 || !hmac.Equal([]byte(p.sign(parts[0]+"."+parts[1])), []byte(parts[2])) {
+		return false, nil
+	}
+	expiry, err := strconv.ParseInt(parts[0], 10, 64)
+	now := p.now()
+	if err != nil || now.Unix() > expiry {
+		return false, nil
+	}
+	if leadingZeros(sha256.Sum256([]byte(response))) < p.Difficulty {
+		return false, nil
+	}
+
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	// Reject replays and prune expired tokens
+	if _, ok := p.used[token]; ok {
+		return false, nil
+	}
+	for t, exp := range p.used {
+		if now.After(exp) {
+			delete(p.used, t)
+		}
+	}
+	p.used[token] = time.Unix(expiry, 0)
+	return true, nil
+}
+
+// Solve returns a solution for token with difficulty. Solve
+// is intended for Go clients and tests
+func Solve(token string, difficulty int) string {
+	for counter := 0; ; counter++ {
+		response := token + ":" + strconv.Itoa(counter)
+		if leadingZeros(sha256.Sum256([]byte(response))) >= difficulty {
+			return response
+		}
+	}
+}
+
+// sign returns the hex encoded HMAC of payload
+func (p *ProofOfWork) sign(payload string) string {
+	mac := hmac.New(sha256.New, p.secret)
+	mac.Write([]byte(payload))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func (p *ProofOfWork) ttl() time.Duration {
+	if p.TTL <= 0 {
+		return 5 * time.Minute
+	}
+	return p.TTL
+}
+
+func (p *ProofOfWork) now() time.Time {
+	if p.Now == nil {
+		return time.Now()
+	}
+	return p.Now()
+}
+
+// leadingZeros returns the number of leading zero bits of sum
+func leadingZeros(sum [sha256.Size]byte) int {
+	n := 0
+	for _, b := range sum {
+		if b == 0 {
+			n += 8
+			continue
+		}
+		for b&0x80 == 0 {
+			n++
+			b <<= 1
+		}
+		break
+	}
+	return n
+}
@@ -0,0 +1,58 @@
+package challenge
+
+import (
+	"encoding/json"
+	"github.com/boxtown/verto"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// SiteVerify is a Provider for captcha services with a siteverify API
+// such as reCAPTCHA, hCaptcha and Turnstile. The client-side widget
+// produces a response token that is verified by POSTing it together
+// with the secret to the service's verification URL.
+type SiteVerify struct {
+	// ProviderName is the name reported to clients (e.g. "recaptcha")
+	ProviderName string
+
+	// URL is the verification endpoint of the service
+	URL string
+
+	// Secret is the server-side secret of the site
+	Secret string
+
+	// Client is the HTTP client used for verification.
+	// Defaults to a client with a 5 second timeout
+	Client *http.Client
+}
+
+// Name returns the provider name
+func (sv *SiteVerify) Name() string {
+	return sv.ProviderName
+}
+
+// Verify verifies response with the captcha service
+func (sv *SiteVerify) Verify(r *http.Request, response string) (bool, error) {
+	client := sv.Client
+	if client == nil {
+		client = &http.Client{Timeout: 5 * time.Second}
+	}
+	res, err := client.PostForm(sv.URL, url.Values{
+		"secret":   {sv.Secret},
+		"response": {response},
+		"remoteip": {verto.GetIP(r)},
+	})
+	if err != nil {
+		return false, err
+	}
+	defer res.Body.Close()
+
+	result := struct {
+		Success bool `json:"success"`
+	}{}
+	if err := json.NewDecoder(res.Body).Decode(&result); err != nil {
+		return false, err
+	}
+	return result.Success, nil
+}
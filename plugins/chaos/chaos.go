@@ -0,0 +1,191 @@
+// Package chaos provides a fault injection plugin for Verto. The plugin
+// injects latency, error responses or dropped connections into a share
+// of the requests matching its rules so that the resilience of clients
+// and dependent services can be tested in staging environments. Faults
+// are only injected once the plugin is explicitly enabled.
+package chaos
+
+import (
+	"errors"
+	"fmt"
+	"github.com/boxtown/verto"
+	"github.com/boxtown/verto/plugins"
+	"math/rand"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ErrProduction is returned by Init if the plugin is enabled
+// in the production environment without AllowProduction
+var ErrProduction = errors.New("chaos: fault injection enabled in production")
+
+// Rule describes the faults injected into matching requests. A rule
+// injects its latency before any error response or dropped connection
+type Rule struct {
+	// Match selects the requests the rule applies to.
+	// All requests match if Match is nil
+	Match func(c *verto.Context) bool
+
+	// Percent is the percentage of matching
+	// requests faults are injected into
+	Percent float64
+
+	// Latency delays faulted requests. A random
+	// duration of up to Jitter is added
+	Latency time.Duration
+	Jitter  time.Duration
+
+	// Status responds to faulted requests with the
+	// status instead of serving them if non-zero
+	Status int
+
+	// Drop closes the connection of faulted
+	// requests without responding if true
+	Drop bool
+}
+
+// Chaos is a plugin injecting faults into requests. Rules are evaluated
+// in order and the first matching rule applies. Chaos is disabled when
+// created and injects no faults until Enable is called.
+//
+// Example usage:
+//
+//	c := chaos.New(
+//		chaos.Rule{Percent: 10, Latency: 2 * time.Second},
+//		chaos.Rule{
+//			Match:   func(c *verto.Context) bool { return c.RouteName() == "checkout" },
+//			Percent: 5,
+//			Status:  503,
+//		},
+//	)
+//	v.Use(c)
+//	if os.Getenv("CHAOS") == "1" {
+//		c.Enable()
+//	}
+type Chaos struct {
+	// Core is the core functionality for plugins
+	plugins.Core
+
+	// Rules are the fault injection rules
+	Rules []Rule
+
+	// AllowProduction allows enabling fault injection
+	// in the production environment
+	AllowProduction bool
+
+	// OnFault is an optional callback invoked
+	// for requests faults are injected into
+	OnFault func(c *verto.Context, rule Rule)
+
+	enabled int32
+	random  *rand.Rand
+	sleep   func(d time.Duration)
+	mutex   sync.Mutex
+}
+
+// New returns a disabled Chaos plugin injecting faults
+// according to rules
+func New(rules ...Rule) *Chaos {
+	return &Chaos{
+		Core:   plugins.Core{Id: "plugins.Chaos"},
+		Rules:  rules,
+		random: rand.New(rand.NewSource(time.Now().UnixNano())),
+		sleep:  time.Sleep,
+	}
+}
+
+// Enable turns fault injection on
+func (plugin *Chaos) Enable() {
+	atomic.StoreInt32(&plugin.enabled, 1)
+}
+
+// Disable turns fault injection off
+func (plugin *Chaos) Disable() {
+	atomic.StoreInt32(&plugin.enabled, 0)
+}
+
+// Enabled returns whether fault injection is on
+func (plugin *Chaos) Enabled() bool {
+	return atomic.LoadInt32(&plugin.enabled) == 1
+}
+
+// Init fails startup with ErrProduction if the plugin is enabled in the
+// production environment of v and AllowProduction is not set
+func (plugin *Chaos) Init(v *verto.Verto) error {
+	if plugin.Enabled() && !plugin.AllowProduction && v.Environment() == verto.Production {
+		return ErrProduction
+	}
+	return nil
+}
+
+// Handle is called per web request to inject faults
+// into the request if it is selected by a rule
+func (plugin *Chaos) Handle(c *verto.Context, next http.HandlerFunc) {
+	plugin.Core.Handle(
+		func(c *verto.Context, next http.HandlerFunc) {
+			rule, ok := plugin.selected(c)
+			if !ok {
+				next(c.Response, c.Request)
+				return
+			}
+			if plugin.OnFault != nil {
+				plugin.OnFault(c, rule)
+			}
+			if d := rule.Latency + plugin.jitter(rule.Jitter); d > 0 {
+				plugin.sleep(d)
+			}
+			switch {
+			case rule.Drop:
+				drop(c.Response)
+			case rule.Status != 0:
+				c.Response.WriteHeader(rule.Status)
+				fmt.Fprint(c.Response, http.StatusText(rule.Status)+".")
+			default:
+				next(c.Response, c.Request)
+			}
+		}, c, next)
+}
+
+// selected returns the rule selecting the request of c
+// and whether a rule selected it
+func (plugin *Chaos) selected(c *verto.Context) (Rule, bool) {
+	if !plugin.Enabled() {
+		return Rule{}, false
+	}
+	for _, rule := range plugin.Rules {
+		if rule.Match != nil && !rule.Match(c) {
+			continue
+		}
+		return rule, plugin.float()*100 < rule.Percent
+	}
+	return Rule{}, false
+}
+
+// jitter returns a random duration of up to max
+func (plugin *Chaos) jitter(max time.Duration) time.Duration {
+	if max <= 0 {
+		return 0
+	}
+	return time.Duration(plugin.float() * float64(max))
+}
+
+// float returns a random number in [0, 1)
+func (plugin *Chaos) float() float64 {
+	plugin.mutex.Lock()
+	defer plugin.mutex.Unlock()
+
+	return plugin.random.Float64()
+}
+
+// drop closes the connection of w without responding. The handler is
+// aborted if the connection cannot be hijacked so that net/http closes
+// the connection instead
+func drop(w http.ResponseWriter) {
+	conn, _, err := verto.Hijack(w)
+	if err != nil {
+		panic(http.ErrAbortHandler)
+	}
+	conn.Close()
+}
@@ -0,0 +1,113 @@
+package chaos
+
+import (
+	"github.com/boxtown/verto"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestChaos(t *testing.T) {
+	defer func() {
+		err := recover()
+		if err != nil {
+			t.Errorf(err.(error).Error())
+		}
+	}()
+
+	err := "Failed chaos."
+
+	faults := 0
+	slept := time.Duration(0)
+	c := New(
+		Rule{
+			Match:   func(c *verto.Context) bool { return strings.HasPrefix(c.Request.URL.Path, "/slow") },
+			Percent: 100,
+			Latency: time.Second,
+		},
+		Rule{
+			Match:   func(c *verto.Context) bool { return strings.HasPrefix(c.Request.URL.Path, "/fail") },
+			Percent: 100,
+			Status:  503,
+		},
+		Rule{Percent: 0, Status: 500},
+	)
+	c.OnFault = func(c *verto.Context, rule Rule) { faults++ }
+	c.sleep = func(d time.Duration) { slept += d }
+
+	v := verto.New()
+	v.Logger = &verto.NilLogger{}
+	v.Use(c)
+	ok := func(c *verto.Context) (interface{}, error) { return "ok", nil }
+	v.Get("/slow", ok)
+	v.Get("/fail", ok)
+	v.Get("/ok", ok)
+	h := &verto.HttpHandler{v}
+
+	serve := func(path string) *httptest.ResponseRecorder {
+		r, _ := http.NewRequest("GET", "http://test.com"+path, nil)
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		return w
+	}
+
+	// Test no faults are injected unless enabled
+	for _, path := range []string{"/slow", "/fail", "/ok"} {
+		if w := serve(path); w.Code != 200 {
+			t.Errorf(err)
+		}
+	}
+	if faults != 0 || slept != 0 {
+		t.Errorf(err)
+	}
+
+	// Test faults are injected into matching requests
+	c.Enable()
+	if w := serve("/slow"); w.Code != 200 || slept != time.Second {
+		t.Errorf(err)
+	}
+	if w := serve("/fail"); w.Code != 503 {
+		t.Errorf(err)
+	}
+	if w := serve("/ok"); w.Code != 200 || faults != 2 {
+		t.Errorf(err)
+	}
+
+	// Test enabling in production fails startup
+	v.SetEnvironment(verto.Production)
+	if c.Init(v) != ErrProduction {
+		t.Errorf(err)
+	}
+	c.AllowProduction = true
+	if c.Init(v) != nil {
+		t.Errorf(err)
+	}
+}
+
+func TestChaosDrop(t *testing.T) {
+	defer func() {
+		err := recover()
+		if err != nil {
+			t.Errorf(err.(error).Error())
+		}
+	}()
+
+	err := "Failed chaos drop."
+
+	c := New(Rule{Percent: 100, Drop: true})
+	c.Enable()
+	v := verto.New()
+	v.Logger = &verto.NilLogger{}
+	v.Use(c)
+	v.Get("/", func(c *verto.Context) (interface{}, error) { return "ok", nil })
+	server := httptest.NewServer(&verto.HttpHandler{v})
+	defer server.Close()
+
+	// Test connections are dropped without a response
+	if resp, e := http.Get(server.URL); e == nil {
+		resp.Body.Close()
+		t.Errorf(err)
+	}
+}
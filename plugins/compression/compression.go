@@ -1,17 +1,20 @@
 package compression
 
 import (
+	"bufio"
 	"github.com/boxtown/verto"
+	"github.com/boxtown/verto/headers"
 	"github.com/boxtown/verto/plugins"
-	"io"
+	"net"
 	"net/http"
-	"strings"
 )
 
 // Compression is a plugin that replaces the default
 // ResponseWriter with a compression writer that compresses
 // everything written to the response. Currently supports
-// gzip and deflate
+// gzip and deflate. Responses that already carry a Content-Encoding
+// (e.g. precompressed static files) or are partial content
+// are passed through untouched.
 type Compression struct {
 	// Core is the core functionality for plugins
 	plugins.Core
@@ -24,8 +27,8 @@ func New() *Compression {
 
 // Handle is called on per web request to supply a compression writer to the
 // other plugins and request handler. Currently only gzip and deflate are supported.
-// The compression type used is the first supported compression type encountered
-// in the 'Accept-Encoding' header of incoming requests
+// The compression type used is the supported compression type with the highest
+// quality in the 'Accept-Encoding' header of incoming requests, preferring gzip
 func (plugin *Compression) Handle(c *verto.Context, next http.HandlerFunc) {
 	plugin.Core.Handle(
 		func(c *verto.Context, next http.HandlerFunc) {
@@ -33,60 +36,146 @@ func (plugin *Compression) Handle(c *verto.Context, next http.HandlerFunc) {
 			w := c.Response
 
 			w.Header().Add("Vary", "Accept-Encoding")
+			size := verto.RequestPayloadSize(r)
 
-			enc := strings.Split(r.Header.Get("Accept-Encoding"), ",")
-			for _, v := range enc {
-				v = strings.ToLower(strings.TrimSpace(v))
-				if v == "gzip" {
-					w.Header().Add("Content-Encoding", "gzip")
-
-					ref := pool.get(w, ctGzip)
-					defer ref.dispose()
-
-					w = &writer{
-						Writer:         ref.w,
-						ResponseWriter: w,
-					}
-					next(w, r)
-					return
-				}
-				if v == "deflate" {
-					w.Header().Add("Content-Encoding", "deflate")
-
-					ref := pool.get(w, ctFlate)
-					defer ref.dispose()
-
-					w = &writer{
-						Writer:         ref.w,
-						ResponseWriter: w,
-					}
-					next(w, r)
-					return
-				}
+			switch headers.Preferred(r.Header, "Accept-Encoding", "gzip", "deflate") {
+			case "gzip":
+				cw := &writer{ResponseWriter: w, encoding: "gzip", ct: ctGzip, size: size}
+				defer cw.dispose()
+
+				next(cw, r)
+			case "deflate":
+				cw := &writer{ResponseWriter: w, encoding: "deflate", ct: ctFlate, size: size}
+				defer cw.dispose()
+
+				next(cw, r)
+			default:
+				next(w, r)
 			}
-			next(w, r)
 		}, c, next)
 }
 
-// writer implements io.Writer as well as http.ResponseWriter.
-// It is assumed that the io.Writer is a compression writer that wraps
-// the http.ResponseWriter
+// writer implements http.ResponseWriter. The decision to compress is
+// deferred until the response header is written so that handlers can
+// opt out by setting their own Content-Encoding. Compression writers
+// are only retrieved from the pool once compression is certain.
+// Body and wire sizes are reported to the request's PayloadSize
+// if a metrics plugin attached one.
 type writer struct {
-	io.Writer
 	http.ResponseWriter
+
+	encoding    string
+	ct          compressType
+	ref         *writerRef
+	size        *verto.PayloadSize
+	decided     bool
+	passthrough bool
+	err         error
 }
 
-func (w writer) Header() http.Header {
+func (w *writer) Header() http.Header {
 	return w.ResponseWriter.Header()
 }
 
-func (w writer) Write(b []byte) (int, error) {
+func (w *writer) Write(b []byte) (int, error) {
+	// Don't compress more data for a client that is gone
+	if w.err != nil {
+		return 0, w.err
+	}
 	if len(w.Header().Get("Content-Type")) == 0 {
 		w.Header().Set("Content-Type", http.DetectContentType(b))
 	}
-	return w.Writer.Write(b)
+	if !w.decided {
+		w.WriteHeader(http.StatusOK)
+	}
+	if w.passthrough {
+		return (&errWriter{w}).Write(b)
+	}
+	if w.ref == nil {
+		w.ref = pool.get(&errWriter{w}, w.ct)
+	}
+	n, err := w.ref.w.Write(b)
+	if w.size != nil {
+		w.size.AddBody(n)
+	}
+	if w.err != nil {
+		return n, w.err
+	}
+	return n, err
+}
+
+// Flush flushes compressed data to the client
+func (w *writer) Flush() {
+	if w.ref != nil && w.err == nil {
+		w.ref.flush()
+	}
+	if f, ok := w.ResponseWriter.(http.Flusher); ok && w.err == nil {
+		f.Flush()
+	}
+}
+
+// Hijack implements http.Hijacker. Hijacked connections
+// (e.g. WebSocket upgrades) are never compressed
+func (w *writer) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	conn, rw, err := verto.Hijack(w.ResponseWriter)
+	if err == nil {
+		w.decided = true
+		w.passthrough = true
+	}
+	return conn, rw, err
 }
 
-func (w writer) WriteHeader(code int) {
+func (w *writer) WriteHeader(code int) {
+	if !w.decided {
+		w.decide(code)
+	}
 	w.ResponseWriter.WriteHeader(code)
 }
+
+// decide determines whether the response should be compressed
+// based on the status code and headers set by the handler
+func (w *writer) decide(code int) {
+	w.decided = true
+
+	h := w.Header()
+	if h.Get("Content-Encoding") != "" ||
+		h.Get("Content-Range") != "" ||
+		code == http.StatusNoContent ||
+		code == http.StatusNotModified ||
+		code == http.StatusPartialContent {
+
+		w.passthrough = true
+		return
+	}
+	h.Set("Content-Encoding", w.encoding)
+	h.Del("Content-Length")
+}
+
+// dispose completes the compressed stream and returns the
+// compression writer to the pool if one was retrieved. The
+// stream is not completed if the client is gone
+func (w *writer) dispose() {
+	if w.ref != nil {
+		w.ref.dispose(w.err == nil)
+	}
+}
+
+// errWriter records write errors of the underlying
+// ResponseWriter, which signal that the client is gone
+type errWriter struct {
+	w *writer
+}
+
+func (ew *errWriter) Write(b []byte) (int, error) {
+	if ew.w.err != nil {
+		return 0, ew.w.err
+	}
+	n, err := ew.w.ResponseWriter.Write(b)
+	if err != nil {
+		ew.w.err = err
+	}
+	if ew.w.size != nil && !ew.w.passthrough {
+		ew.w.size.AddWire(n)
+	}
+	return n, err
+}
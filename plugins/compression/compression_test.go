@@ -1,12 +1,17 @@
 package compression
 
 import (
+	"bufio"
 	"bytes"
 	"compress/flate"
 	"compress/gzip"
+	"errors"
 	"github.com/boxtown/verto"
+	"io/ioutil"
+	"net"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 )
 
@@ -81,3 +86,167 @@ func TestCompressionPlugin(t *testing.T) {
 		t.Errorf(err)
 	}
 }
+
+func TestCompressionPassthrough(t *testing.T) {
+	defer func() {
+		err := recover()
+		if err != nil {
+			t.Errorf(err.(error).Error())
+		}
+	}()
+
+	err := "Failed compression passthrough."
+
+	plugin := New()
+
+	endpoint := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Encoding", "br")
+		w.Write([]byte("test"))
+	})
+
+	r, _ := http.NewRequest("GET", "http://test.com", nil)
+	r.Header.Add("Accept-Encoding", "gzip")
+	w := httptest.NewRecorder()
+	c := &verto.Context{Request: r, Response: w}
+	plugin.Handle(c, endpoint)
+
+	if w.Header().Get("Content-Encoding") != "br" {
+		t.Errorf(err)
+	}
+	if w.Body.String() != "test" {
+		t.Errorf(err)
+	}
+}
+
+func TestCompressionClientClosed(t *testing.T) {
+	defer func() {
+		err := recover()
+		if err != nil {
+			t.Errorf(err.(error).Error())
+		}
+	}()
+
+	err := "Failed compression client closed."
+
+	plugin := New()
+
+	// Test the gzip stream is terminated
+	endpoint := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("test"))
+	})
+	r, _ := http.NewRequest("GET", "http://test.com", nil)
+	r.Header.Add("Accept-Encoding", "gzip")
+	w := httptest.NewRecorder()
+	plugin.Handle(&verto.Context{Request: r, Response: w}, endpoint)
+
+	gr, e := gzip.NewReader(bytes.NewReader(w.Body.Bytes()))
+	if e != nil {
+		t.Fatalf(err)
+	}
+	if b, e := ioutil.ReadAll(gr); e != nil || string(b) != "test" {
+		t.Errorf(err)
+	}
+
+	// Test writes stop once the client is gone
+	writes := 0
+	endpoint = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		for i := 0; i < 100; i++ {
+			if _, e := w.Write(bytes.Repeat([]byte("a"), 64<<10)); e != nil {
+				return
+			}
+			w.(http.Flusher).Flush()
+			writes++
+		}
+	})
+	fw := &failingWriter{ResponseRecorder: httptest.NewRecorder()}
+	plugin.Handle(&verto.Context{Request: r, Response: fw}, endpoint)
+	if writes > 1 || fw.writes != 1 {
+		t.Errorf(err)
+	}
+}
+
+type failingWriter struct {
+	*httptest.ResponseRecorder
+	writes int
+}
+
+func (w *failingWriter) Write(b []byte) (int, error) {
+	w.writes++
+	return 0, errors.New("broken pipe")
+}
+
+func TestCompressionTrailers(t *testing.T) {
+	defer func() {
+		err := recover()
+		if err != nil {
+			t.Errorf(err.(error).Error())
+		}
+	}()
+
+	err := "Failed compression trailers."
+
+	v := verto.New()
+	v.Logger = &verto.NilLogger{}
+	v.Use(New())
+	v.Get("/stream", func(c *verto.Context) (interface{}, error) {
+		c.AddTrailer("Checksum")
+		c.Response.Write([]byte("data"))
+		c.SetTrailer("Checksum", "abc")
+		return "", nil
+	})
+	s := httptest.NewServer(&verto.HttpHandler{v})
+	defer s.Close()
+
+	r, _ := http.NewRequest("GET", s.URL+"/stream", nil)
+	r.Header.Set("Accept-Encoding", "gzip")
+	res, e := http.DefaultClient.Do(r)
+	if e != nil {
+		t.Fatalf(err)
+	}
+	defer res.Body.Close()
+	gr, e := gzip.NewReader(res.Body)
+	if e != nil {
+		t.Fatalf(err)
+	}
+	b, _ := ioutil.ReadAll(gr)
+	if string(b) != "data" || res.Header.Get("Content-Encoding") != "gzip" || res.Trailer.Get("Checksum") != "abc" {
+		t.Errorf(err)
+	}
+}
+
+func TestCompressionHijack(t *testing.T) {
+	defer func() {
+		err := recover()
+		if err != nil {
+			t.Errorf(err.(error).Error())
+		}
+	}()
+
+	err := "Failed compression hijack."
+
+	v := verto.New()
+	v.Logger = &verto.NilLogger{}
+	v.Use(New())
+	v.WebSocket("/ws", func(c *verto.Context, conn *verto.WebSocketConn) {
+		conn.Write([]byte("hello\n"))
+	})
+	s := httptest.NewServer(&verto.HttpHandler{v})
+	defer s.Close()
+
+	conn, _ := net.Dial("tcp", strings.TrimPrefix(s.URL, "http://"))
+	defer conn.Close()
+	conn.Write([]byte("GET /ws HTTP/1.1\r\nHost: test.com\r\nAccept-Encoding: gzip\r\n" +
+		"Upgrade: websocket\r\nConnection: Upgrade\r\n" +
+		"Sec-WebSocket-Key: dGhlIHNhbXBsZSBub25jZQ==\r\nSec-WebSocket-Version: 13\r\n\r\n"))
+	br := bufio.NewReader(conn)
+	resp, _ := http.ReadResponse(br, nil)
+	if resp == nil || resp.StatusCode != http.StatusSwitchingProtocols {
+		t.Fatalf(err)
+	}
+	if resp.Header.Get("Content-Encoding") != "" {
+		t.Errorf(err)
+	}
+	if line, _ := br.ReadString('\n'); line != "hello\n" {
+		t.Errorf(err)
+	}
+}
@@ -0,0 +1,135 @@
+package compression
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"fmt"
+	"github.com/boxtown/verto"
+	"github.com/boxtown/verto/plugins"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// Decompression is a plugin that transparently decompresses gzip and
+// deflate encoded request bodies. Bodies are decompressed up front so
+// that handlers see plain bodies with an accurate Content-Length.
+// Bodies that decompress to more than MaxSize bytes are rejected with
+// a 413 response to guard against decompression bombs, bodies with
+// other encodings receive a 415 response and corrupt bodies a 400
+// response.
+//
+// Example usage:
+//
+//	v.Use(compression.NewDecompression(10 << 20))
+type Decompression struct {
+	// Core is the core functionality for plugins
+	plugins.Core
+
+	// MaxSize is the maximum decompressed size
+	// of request bodies in bytes
+	MaxSize int64
+}
+
+// NewDecompression returns a Decompression plugin allowing
+// request bodies to decompress to at most maxSize bytes
+func NewDecompression(maxSize int64) *Decompression {
+	return &Decompression{
+		Core:    plugins.Core{Id: "plugins.Decompression"},
+		MaxSize: maxSize,
+	}
+}
+
+// Handle is called per web request to decompress encoded request bodies
+func (plugin *Decompression) Handle(c *verto.Context, next http.HandlerFunc) {
+	plugin.Core.Handle(
+		func(c *verto.Context, next http.HandlerFunc) {
+			r := c.Request
+			encoding := strings.ToLower(strings.TrimSpace(r.Header.Get("Content-Encoding")))
+			if encoding == "" || encoding == "identity" || r.Body == nil {
+				next(c.Response, r)
+				return
+			}
+
+			var reader io.ReadCloser
+			var err error
+			switch encoding {
+			case "gzip", "x-gzip":
+				reader, err = gzip.NewReader(r.Body)
+			case "deflate":
+				reader, err = newDeflateReader(r.Body)
+			default:
+				decompressionError(c.Response, http.StatusUnsupportedMediaType)
+				return
+			}
+			if err != nil {
+				decompressionError(c.Response, http.StatusBadRequest)
+				return
+			}
+			defer reader.Close()
+
+			body, err := ioutil.ReadAll(io.LimitReader(reader, plugin.MaxSize+1))
+			if err != nil {
+				decompressionError(c.Response, http.StatusBadRequest)
+				return
+			}
+			if int64(len(body)) > plugin.MaxSize {
+				decompressionError(c.Response, http.StatusRequestEntityTooLarge)
+				return
+			}
+
+			r.Body = ioutil.NopCloser(bytes.NewReader(body))
+			r.ContentLength = int64(len(body))
+			r.Header.Del("Content-Encoding")
+			r.Header.Set("Content-Length", strconv.Itoa(len(body)))
+			reparseForm(r)
+
+			next(c.Response, r)
+		}, c, next)
+}
+
+// newDeflateReader returns a reader for deflate bodies. Clients
+// disagree on whether deflate means zlib-wrapped (as specified) or
+// raw deflate data, so both are accepted
+func newDeflateReader(body io.Reader) (io.ReadCloser, error) {
+	b, err := ioutil.ReadAll(io.LimitReader(body, 2))
+	if err != nil {
+		return nil, err
+	}
+	rest := io.MultiReader(bytes.NewReader(b), body)
+	if len(b) == 2 && b[0]&0x0f == 8 && (uint16(b[0])<<8|uint16(b[1]))%31 == 0 {
+		return zlib.NewReader(rest)
+	}
+	return flate.NewReader(rest), nil
+}
+
+// reparseForm parses the decompressed body into the request's form
+// if the form was populated from the URL and route parameters only
+func reparseForm(r *http.Request) {
+	if r.Form == nil || r.PostForm != nil {
+		return
+	}
+	existing := r.Form
+	query := r.URL.Query()
+	r.Form = nil
+	r.ParseForm()
+	if r.Form == nil {
+		r.Form = query
+	}
+	for k, values := range existing {
+		// Values beyond the query values are route parameters
+		if len(values) > len(query[k]) {
+			r.Form[k] = append(r.Form[k], values[len(query[k]):]...)
+		}
+	}
+}
+
+// decompressionError writes an error response
+func decompressionError(w http.ResponseWriter, status int) {
+	w.WriteHeader(status)
+	fmt.Fprint(w, http.StatusText(status)+".")
+}
@@ -0,0 +1,95 @@
+package compression
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"github.com/boxtown/verto"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestDecompressionPlugin(t *testing.T) {
+	defer func() {
+		err := recover()
+		if err != nil {
+			t.Errorf(err.(error).Error())
+		}
+	}()
+
+	err := "Failed decompression."
+
+	gzipped := &bytes.Buffer{}
+	gw := gzip.NewWriter(gzipped)
+	gw.Write([]byte("name=a&age=3"))
+	gw.Close()
+
+	zlibbed := &bytes.Buffer{}
+	zw := zlib.NewWriter(zlibbed)
+	zw.Write([]byte("zlib"))
+	zw.Close()
+
+	raw := &bytes.Buffer{}
+	fw, _ := flate.NewWriter(raw, flate.DefaultCompression)
+	fw.Write([]byte("raw"))
+	fw.Close()
+
+	bomb := &bytes.Buffer{}
+	gw = gzip.NewWriter(bomb)
+	gw.Write(bytes.Repeat([]byte{0}, 1<<16))
+	gw.Close()
+
+	v := verto.New()
+	v.Logger = &verto.NilLogger{}
+	v.Use(NewDecompression(1 << 10))
+	v.Post("/users/{id}", func(c *verto.Context) (interface{}, error) {
+		return c.Get("id") + ":" + c.Get("name") + ":" + c.Get("age"), nil
+	})
+	v.Post("/echo", func(c *verto.Context) (interface{}, error) {
+		b, _ := ioutil.ReadAll(c.Request.Body)
+		return string(b), nil
+	})
+	h := &verto.HttpHandler{v}
+
+	serve := func(path, encoding string, body []byte) *httptest.ResponseRecorder {
+		r, _ := http.NewRequest("POST", "http://test.com"+path, bytes.NewReader(body))
+		r.Header.Set("Content-Encoding", encoding)
+		r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		return w
+	}
+
+	// Test form bodies on routes with parameters
+	if w := serve("/users/1", "gzip", gzipped.Bytes()); w.Body.String() != "1:a:3" {
+		t.Errorf(err)
+	}
+
+	// Test deflate with and without zlib wrapping
+	if w := serve("/echo", "deflate", zlibbed.Bytes()); w.Body.String() != "zlib" {
+		t.Errorf(err)
+	}
+	if w := serve("/echo", "deflate", raw.Bytes()); w.Body.String() != "raw" {
+		t.Errorf(err)
+	}
+
+	// Test unencoded bodies pass through
+	if w := serve("/echo", "", []byte("plain")); w.Body.String() != "plain" {
+		t.Errorf(err)
+	}
+
+	// Test failures
+	if w := serve("/echo", "gzip", bomb.Bytes()); w.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf(err)
+	}
+	if w := serve("/echo", "br", []byte("x")); w.Code != http.StatusUnsupportedMediaType {
+		t.Errorf(err)
+	}
+	if w := serve("/echo", "gzip", []byte(strings.Repeat("x", 20))); w.Code != http.StatusBadRequest {
+		t.Errorf(err)
+	}
+}
@@ -12,21 +12,29 @@ type writerRef struct {
 	disposal chan<- io.WriteCloser
 }
 
-// dispose disposes of the reference by either returning the
-// writer to the pool or disposing of the writer if
-// the pool is full
-func (ref *writerRef) dispose() {
+// flush flushes pending compressed data to the underlying writer
+func (ref *writerRef) flush() {
 	switch w := ref.w.(type) {
 	case *flate.Writer:
 		w.Flush()
 	case *gzip.Writer:
 		w.Flush()
 	}
+}
+
+// dispose disposes of the reference by returning the writer to
+// the pool if there is room. If complete is true, the compressed
+// stream is closed first so that it is terminated properly (e.g.
+// the gzip footer is written). Closed writers remain reusable
+// since they are reset when retrieved from the pool
+func (ref *writerRef) dispose(complete bool) {
+	if complete {
+		ref.w.Close()
+	}
 
 	select {
 	case ref.disposal <- ref.w:
 	default:
-		ref.w.Close()
 	}
 }
 
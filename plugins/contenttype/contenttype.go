@@ -0,0 +1,150 @@
+// Package contenttype provides a plugin that enforces the media types a
+// route consumes and produces. Requests with a body whose Content-Type is
+// not allowed are rejected with 415 Unsupported Media Type and requests
+// whose Accept header matches none of the produced types are rejected
+// with 406 Not Acceptable.
+package contenttype
+
+import (
+	"github.com/boxtown/verto"
+	"github.com/boxtown/verto/headers"
+	"github.com/boxtown/verto/plugins"
+	"mime"
+	"net/http"
+	"strings"
+)
+
+// ConsumesKey is the route metadata key for the media types a route
+// accepts in request bodies. The value is a string or []string and
+// overrides the plugin's Consumes list
+const ConsumesKey = "contenttype.consumes"
+
+// ProducesKey is the route metadata key for the media types a route
+// can respond with. The value is a string or []string and overrides
+// the plugin's Produces list
+const ProducesKey = "contenttype.produces"
+
+// ContentType is a plugin that enforces allowed request and
+// response media types. Media types may contain wildcards
+// (e.g. text/* or */*). An empty list disables the respective check.
+//
+// Example usage:
+//
+//	v.Group("POST", "/api").Use(contenttype.New([]string{"application/json"}, nil))
+//	v.Post("/api/uploads", upload).
+//		Meta(contenttype.ConsumesKey, []string{"multipart/form-data"})
+type ContentType struct {
+	// Core is the core functionality for plugins
+	plugins.Core
+
+	// Consumes lists the allowed request body media types
+	Consumes []string
+
+	// Produces lists the media types responses can be produced in
+	Produces []string
+}
+
+// New returns a ContentType plugin allowing request bodies of the
+// consumes media types and responses of the produces media types
+func New(consumes, produces []string) *ContentType {
+	return &ContentType{
+		Core:     plugins.Core{Id: "plugins.ContentType"},
+		Consumes: consumes,
+		Produces: produces,
+	}
+}
+
+// Handle is called per request to enforce the allowed media types
+func (plugin *ContentType) Handle(c *verto.Context, next http.HandlerFunc) {
+	plugin.Core.Handle(
+		func(c *verto.Context, next http.HandlerFunc) {
+			r := c.Request
+
+			consumes := plugin.types(c, ConsumesKey, plugin.Consumes)
+			if len(consumes) > 0 && hasBody(r) {
+				mt, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+				if err != nil || !matchesAny(consumes, mt) {
+					reject(c, http.StatusUnsupportedMediaType)
+					return
+				}
+			}
+
+			produces := plugin.types(c, ProducesKey, plugin.Produces)
+			if len(produces) > 0 && r.Header.Get("Accept") != "" {
+				if !acceptable(r.Header, produces) {
+					reject(c, http.StatusNotAcceptable)
+					return
+				}
+			}
+
+			next(c.Response, r)
+		}, c, next)
+}
+
+// types returns the media types stored at key in the route
+// metadata or def if none are set
+func (plugin *ContentType) types(c *verto.Context, key string, def []string) []string {
+	v, ok := c.RouteMeta(key)
+	if !ok {
+		return def
+	}
+	switch t := v.(type) {
+	case string:
+		return []string{t}
+	case []string:
+		return t
+	}
+	return def
+}
+
+// hasBody returns whether the request carries a body
+func hasBody(r *http.Request) bool {
+	return r.ContentLength != 0 || len(r.TransferEncoding) > 0
+}
+
+// acceptable returns whether any of the produced media types is
+// matched by a media range with non-zero quality in h
+func acceptable(h http.Header, produces []string) bool {
+	for _, mr := range headers.ParseQuality(h, "Accept") {
+		if mr.Q <= 0 {
+			continue
+		}
+		for _, p := range produces {
+			if match(mr.Value, p) || match(p, mr.Value) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// matchesAny returns whether media type mt is matched
+// by any of the patterns
+func matchesAny(patterns []string, mt string) bool {
+	for _, p := range patterns {
+		if match(p, mt) {
+			return true
+		}
+	}
+	return false
+}
+
+// match returns whether media type mt is matched by pattern. Patterns
+// may wildcard the subtype (text/*) or the whole type (*/*)
+func match(pattern, mt string) bool {
+	pattern = strings.ToLower(pattern)
+	mt = strings.ToLower(mt)
+	if pattern == "*/*" || pattern == mt {
+		return true
+	}
+	if strings.HasSuffix(pattern, "/*") {
+		return strings.HasPrefix(mt, pattern[:len(pattern)-1])
+	}
+	return false
+}
+
+// reject writes an error response with status
+func reject(c *verto.Context, status int) {
+	c.Response.WriteHeader(status)
+	c.Response.Write([]byte(http.StatusText(status) + "."))
+}
@@ -0,0 +1,61 @@
+package contenttype
+
+import (
+	"bytes"
+	"github.com/boxtown/verto"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestContentType(t *testing.T) {
+	defer func() {
+		err := recover()
+		if err != nil {
+			t.Errorf(err.(error).Error())
+		}
+	}()
+
+	err := "Failed content type."
+
+	v := verto.New()
+	v.Logger = &verto.NilLogger{}
+	handler := func(c *verto.Context) (interface{}, error) { return "ok", nil }
+	v.Post("/api/users", handler)
+	v.Post("/api/uploads", handler).Meta(ConsumesKey, "multipart/*")
+	v.Group("POST", "/api").Use(New([]string{"application/json"}, []string{"application/json", "text/*"}))
+	h := &verto.HttpHandler{v}
+
+	cases := []struct {
+		path        string
+		contentType string
+		accept      string
+		body        string
+		code        int
+	}{
+		{"/api/users", "application/json; charset=utf-8", "", "{}", http.StatusOK},
+		{"/api/users", "", "", "", http.StatusOK},
+		{"/api/users", "text/xml", "", "<a/>", http.StatusUnsupportedMediaType},
+		{"/api/users", "", "", "{}", http.StatusUnsupportedMediaType},
+		{"/api/users", "application/json", "application/xml", "{}", http.StatusNotAcceptable},
+		{"/api/users", "application/json", "application/xml, */*;q=0.1", "{}", http.StatusOK},
+		{"/api/users", "application/json", "application/*;q=0", "{}", http.StatusNotAcceptable},
+		{"/api/users", "application/json", "text/html", "{}", http.StatusOK},
+		{"/api/uploads", "multipart/form-data; boundary=x", "", "--x--", http.StatusOK},
+		{"/api/uploads", "application/json", "", "{}", http.StatusUnsupportedMediaType},
+	}
+	for _, test := range cases {
+		r, _ := http.NewRequest("POST", "http://test.com"+test.path, bytes.NewBufferString(test.body))
+		if test.contentType != "" {
+			r.Header.Set("Content-Type", test.contentType)
+		}
+		if test.accept != "" {
+			r.Header.Set("Accept", test.accept)
+		}
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		if w.Code != test.code {
+			t.Errorf(err)
+		}
+	}
+}
@@ -23,8 +23,23 @@ type Core struct {
 	// time the plugin exits execution
 	OnExit func(c *verto.Context)
 
-	// Id is an id for the plugin
+	// Id is an id for the plugin. Other plugins
+	// require the plugin by its Id
 	Id string
+
+	// Requirements are the optional Ids of plugins the plugin
+	// requires (see verto.PluginDependent)
+	Requirements []string
+}
+
+// PluginId returns the Id of the plugin
+func (core Core) PluginId() string {
+	return core.Id
+}
+
+// Requires returns the Requirements of the plugin
+func (core Core) Requires() []string {
+	return core.Requirements
 }
 
 // Handle wraps a plugin function within Core plugin
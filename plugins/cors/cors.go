@@ -2,6 +2,7 @@ package cors
 
 import (
 	"github.com/boxtown/verto"
+	"github.com/boxtown/verto/headers"
 	"github.com/boxtown/verto/plugins"
 	"net/http"
 	"strconv"
@@ -213,8 +214,8 @@ func (plugin *Cors) writeHeaders(w http.ResponseWriter, r *http.Request, preflig
 	}
 
 	// Check requested headers if preflight
-	headers := r.Header.Get("Access-Control-Request-Headers")
-	if preflight && !plugin.areHeadersAllowed(strings.Split(headers, ",")) {
+	requested := headers.ParseList(r.Header, "Access-Control-Request-Headers")
+	if preflight && !plugin.areHeadersAllowed(requested) {
 		return
 	}
 
@@ -228,7 +229,9 @@ func (plugin *Cors) writeHeaders(w http.ResponseWriter, r *http.Request, preflig
 	}
 	if preflight {
 		w.Header().Set("Access-Control-Allow-Methods", method)
-		w.Header().Set("Access-Control-Allow-Headers", headers)
+		if len(requested) > 0 {
+			w.Header().Set("Access-Control-Allow-Headers", strings.Join(requested, ", "))
+		}
 		if plugin.maxAge > 0 {
 			w.Header().Set("Access-Control-Max-Age", strconv.FormatInt(plugin.maxAge, 10))
 		}
@@ -0,0 +1,229 @@
+// Package dbtx provides a plugin that manages a database transaction
+// per request. The transaction is exposed through the injections container,
+// committed before the response is written by ResponseHandler or when the
+// request completes and rolled back if the ErrorHandler is invoked or a
+// panic occurs.
+package dbtx
+
+import (
+	"database/sql"
+	"errors"
+	"github.com/boxtown/verto"
+	"github.com/boxtown/verto/plugins"
+	"net/http"
+	"sync"
+)
+
+// TXKEY is the injection key under which the per-request
+// transaction is exposed
+const TXKEY = "_VertoTx"
+
+// ErrNoDB is returned by Tx.Begin if no *sql.DB could be
+// found in the injections container
+var ErrNoDB = errors.New("dbtx: no *sql.DB injected")
+
+// ErrCommit is passed to the ErrorHandler by ResponseHandler
+// if the request's transaction could not be committed
+var ErrCommit = errors.New("dbtx: could not commit transaction")
+
+// Tx is a request-scoped database transaction. The underlying
+// sql.Tx is lazily started on the first call to Begin so requests
+// that never touch the database do not pay for a transaction.
+// Tx is thread-safe
+type Tx struct {
+	db     *sql.DB
+	tx     *sql.Tx
+	failed bool
+	mutex  *sync.Mutex
+}
+
+// Begin returns the transaction for the current request,
+// starting it if it has not been started yet
+func (t *Tx) Begin() (*sql.Tx, error) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	if t.tx != nil {
+		return t.tx, nil
+	}
+	if t.db == nil {
+		return nil, ErrNoDB
+	}
+	tx, err := t.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	t.tx = tx
+	return tx, nil
+}
+
+// Fail marks the transaction to be rolled back once
+// the request completes instead of committed
+func (t *Tx) Fail() {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	t.failed = true
+}
+
+// Failed returns whether the transaction has been
+// marked for rollback
+func (t *Tx) Failed() bool {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	return t.failed
+}
+
+// finish commits or rolls back the transaction if it was
+// started. finish is a no-op for unstarted transactions
+func (t *Tx) finish() error {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	if t.tx == nil {
+		return nil
+	}
+	tx := t.tx
+	t.tx = nil
+	if t.failed {
+		return tx.Rollback()
+	}
+	return tx.Commit()
+}
+
+// DBTx is a plugin that ties a database transaction to the
+// lifetime of a request. Transactions still open once the rest of the
+// chain has run are finished after the response has been written, so
+// commit failures can only be reported through OnFinishError. Wrap
+// Verto's ResponseHandler with ResponseHandler to commit before the
+// response is written.
+//
+// Example usage:
+//
+//	v.Injections.Set("db", db)
+//	v.ErrorHandler = dbtx.ErrorHandler(v.ErrorHandler)
+//	v.ResponseHandler = dbtx.ResponseHandler(v.ResponseHandler, v.ErrorHandler)
+//	v.Use(dbtx.New(v.Injections, "db"))
+//
+//	v.Post("/users", func(c *verto.Context) (interface{}, error) {
+//		tx, err := dbtx.From(c).Begin()
+//		...
+//	})
+type DBTx struct {
+	// Core is the core functionality for plugins
+	plugins.Core
+
+	// OnFinishError is an optional callback invoked if
+	// committing or rolling back the transaction fails.
+	// If nil, the error is logged through the Context logger
+	OnFinishError func(err error, c *verto.Context)
+}
+
+// New registers a per-request lazy transaction under TXKEY in i using
+// the *sql.DB injected at dbKey and returns a new DBTx plugin instance
+func New(i verto.Injections, dbKey string) *DBTx {
+	i.Lazy(
+		TXKEY,
+		func(w http.ResponseWriter, r *http.Request, ri verto.ReadOnlyInjections) interface{} {
+			db, _ := ri.Get(dbKey).(*sql.DB)
+			return &Tx{db: db, mutex: &sync.Mutex{}}
+		},
+		verto.REQUEST)
+
+	return &DBTx{Core: plugins.Core{Id: "plugins.DBTx"}}
+}
+
+// Handle is called per web request to commit or roll back the request's
+// transaction once the rest of the chain has run. Panics roll back the
+// transaction and are then bubbled up.
+func (plugin *DBTx) Handle(c *verto.Context, next http.HandlerFunc) {
+	plugin.Core.Handle(
+		func(c *verto.Context, next http.HandlerFunc) {
+			tx := From(c)
+			if tx == nil {
+				next(c.Response, c.Request)
+				return
+			}
+
+			defer func() {
+				if rMsg := recover(); rMsg != nil {
+					tx.Fail()
+					plugin.finish(tx, c)
+					panic(rMsg)
+				}
+			}()
+
+			next(c.Response, c.Request)
+			plugin.finish(tx, c)
+		}, c, next)
+}
+
+// finishes tx and reports any errors encountered
+func (plugin *DBTx) finish(tx *Tx, c *verto.Context) {
+	err := tx.finish()
+	if err == nil {
+		return
+	}
+	if plugin.OnFinishError != nil {
+		plugin.OnFinishError(err, c)
+	} else if c.Logger != nil {
+		c.Logger.Errorf("dbtx: could not finish transaction: %s", err.Error())
+	}
+}
+
+// From retrieves the request-scoped transaction from the Context's
+// injections or nil if no transaction has been registered
+func From(c *verto.Context) *Tx {
+	if c.Injections == nil {
+		return nil
+	}
+	i := c.Injections()
+	if i == nil {
+		return nil
+	}
+	tx, _ := i.Get(TXKEY).(*Tx)
+	return tx
+}
+
+// ErrorHandler wraps handler such that invoking it marks the request's
+// transaction for rollback before delegating to handler. A nil handler
+// delegates to verto.DefaultErrorFunc
+func ErrorHandler(handler verto.ErrorHandler) verto.ErrorHandler {
+	if handler == nil {
+		handler = verto.ErrorFunc(verto.DefaultErrorFunc)
+	}
+	return verto.ErrorFunc(func(err error, c *verto.Context) {
+		if tx := From(c); tx != nil {
+			tx.Fail()
+		}
+		handler.Handle(err, c)
+	})
+}
+
+// ResponseHandler wraps handler such that the request's transaction is
+// committed, or rolled back if it failed, before delegating to handler.
+// If the transaction cannot be committed, ErrCommit is passed to
+// errHandler instead so that clients never see a success response for
+// a lost write. Nil handlers delegate to verto.DefaultResponseFunc and
+// verto.DefaultErrorFunc respectively
+func ResponseHandler(handler verto.ResponseHandler, errHandler verto.ErrorHandler) verto.ResponseHandler {
+	if handler == nil {
+		handler = verto.ResponseFunc(verto.DefaultResponseFunc)
+	}
+	if errHandler == nil {
+		errHandler = verto.ErrorFunc(verto.DefaultErrorFunc)
+	}
+	return verto.ResponseFunc(func(response interface{}, c *verto.Context) {
+		if tx := From(c); tx != nil {
+			if err := tx.finish(); err != nil {
+				if c.Logger != nil {
+					c.Logger.Errorf("dbtx: could not finish transaction: %s", err.Error())
+				}
+				errHandler.Handle(ErrCommit, c)
+				return
+			}
+		}
+		handler.Handle(response, c)
+	})
+}
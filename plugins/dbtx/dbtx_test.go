@@ -0,0 +1,181 @@
+package dbtx
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"github.com/boxtown/verto"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+)
+
+func TestDBTx(t *testing.T) {
+	defer func() {
+		err := recover()
+		if err != nil {
+			t.Errorf(err.(error).Error())
+		}
+	}()
+
+	err := "Failed dbtx."
+
+	d := &fakeDriver{mutex: &sync.Mutex{}}
+	v := newVerto(d)
+	v.Get("/commit", func(c *verto.Context) (interface{}, error) {
+		if _, e := From(c).Begin(); e != nil {
+			return nil, e
+		}
+		return "ok", nil
+	})
+	v.Get("/rollback", func(c *verto.Context) (interface{}, error) {
+		if _, e := From(c).Begin(); e != nil {
+			return nil, e
+		}
+		return nil, errors.New("failed")
+	})
+	v.Get("/unstarted", func(c *verto.Context) (interface{}, error) {
+		return "ok", nil
+	})
+	h := &verto.HttpHandler{v}
+
+	serve := func(path string) *httptest.ResponseRecorder {
+		r, _ := http.NewRequest("GET", "http://test.com"+path, nil)
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		return w
+	}
+
+	// Test successful requests commit
+	if w := serve("/commit"); w.Code != 200 || w.Body.String() != "ok" {
+		t.Errorf(err)
+	}
+	if d.counts() != [2]int{1, 0} {
+		t.Errorf(err)
+	}
+
+	// Test failed requests roll back
+	if serve("/rollback").Code != 500 {
+		t.Errorf(err)
+	}
+	if d.counts() != [2]int{1, 1} {
+		t.Errorf(err)
+	}
+
+	// Test unstarted transactions are neither committed nor rolled back
+	if serve("/unstarted").Code != 200 {
+		t.Errorf(err)
+	}
+	if d.counts() != [2]int{1, 1} {
+		t.Errorf(err)
+	}
+
+	// Test commit failures become error responses
+	d.failCommit = true
+	if w := serve("/commit"); w.Code != 500 || w.Body.String() == "ok" {
+		t.Errorf(err)
+	}
+}
+
+func TestDBTxNilHandlers(t *testing.T) {
+	defer func() {
+		err := recover()
+		if err != nil {
+			t.Errorf(err.(error).Error())
+		}
+	}()
+
+	err := "Failed dbtx nil handlers."
+
+	d := &fakeDriver{mutex: &sync.Mutex{}}
+	v := newVerto(d)
+	v.ErrorHandler = ErrorHandler(nil)
+	v.ResponseHandler = ResponseHandler(nil, nil)
+	v.Get("/rollback", func(c *verto.Context) (interface{}, error) {
+		From(c).Begin()
+		return nil, errors.New("failed")
+	})
+	h := &verto.HttpHandler{v}
+
+	r, _ := http.NewRequest("GET", "http://test.com/rollback", nil)
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	if w.Code != 500 || d.counts() != [2]int{0, 1} {
+		t.Errorf(err)
+	}
+}
+
+func newVerto(d *fakeDriver) *verto.Verto {
+	v := verto.New()
+	v.Logger = &verto.NilLogger{}
+	v.Injections.Set("db", sql.OpenDB(d))
+	v.ErrorHandler = ErrorHandler(v.ErrorHandler)
+	v.ResponseHandler = ResponseHandler(v.ResponseHandler, v.ErrorHandler)
+	v.Use(New(v.Injections, "db"))
+	return v
+}
+
+// fakeDriver is a database driver counting the commits
+// and rollbacks of its transactions
+type fakeDriver struct {
+	failCommit bool
+	commits    int
+	rollbacks  int
+	mutex      *sync.Mutex
+}
+
+func (d *fakeDriver) Connect(ctx context.Context) (driver.Conn, error) {
+	return &fakeConn{d}, nil
+}
+
+func (d *fakeDriver) Driver() driver.Driver {
+	return nil
+}
+
+func (d *fakeDriver) counts() [2]int {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	return [2]int{d.commits, d.rollbacks}
+}
+
+type fakeConn struct {
+	d *fakeDriver
+}
+
+func (conn *fakeConn) Prepare(query string) (driver.Stmt, error) {
+	return nil, errors.New("not supported")
+}
+
+func (conn *fakeConn) Close() error {
+	return nil
+}
+
+func (conn *fakeConn) Begin() (driver.Tx, error) {
+	return &fakeTx{conn.d}, nil
+}
+
+type fakeTx struct {
+	d *fakeDriver
+}
+
+func (tx *fakeTx) Commit() error {
+	tx.d.mutex.Lock()
+	defer tx.d.mutex.Unlock()
+
+	if tx.d.failCommit {
+		return errors.New("commit failed")
+	}
+	tx.d.commits++
+	return nil
+}
+
+func (tx *fakeTx) Rollback() error {
+	tx.d.mutex.Lock()
+	defer tx.d.mutex.Unlock()
+
+	tx.d.rollbacks++
+	return nil
+}
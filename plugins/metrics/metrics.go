@@ -0,0 +1,213 @@
+// Package metrics provides a plugin recording per-route request
+// metrics. Alongside counts, statuses and latencies, the plugin records
+// the size of response payloads both before and after content encoding
+// so that bandwidth savings from compression and payload bloat can be
+// tracked per route.
+package metrics
+
+import (
+	"bufio"
+	"encoding/json"
+	"github.com/boxtown/verto"
+	"github.com/boxtown/verto/mux"
+	"github.com/boxtown/verto/plugins"
+	"net"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+)
+
+// RouteStats are the metrics recorded for a route
+type RouteStats struct {
+	// Method and Path identify the route. Path is the
+	// route's pattern rather than the requested path
+	Method string `json:"method"`
+	Path   string `json:"path"`
+
+	// Name is the name of the route if it is named
+	Name string `json:"name,omitempty"`
+
+	// Requests is the number of requests served
+	Requests int64 `json:"requests"`
+
+	// Errors is the number of 5xx responses
+	Errors int64 `json:"errors"`
+
+	// Duration is the total time spent serving requests
+	Duration time.Duration `json:"duration"`
+
+	// BodyBytes is the total size of response bodies
+	// written by handlers before content encoding
+	BodyBytes int64 `json:"body_bytes"`
+
+	// WireBytes is the total size of response bodies
+	// sent to clients after content encoding
+	WireBytes int64 `json:"wire_bytes"`
+}
+
+// Savings returns the fraction of body bytes saved by
+// content encoding. Negative savings indicate bloat
+func (rs RouteStats) Savings() float64 {
+	if rs.BodyBytes == 0 {
+		return 0
+	}
+	return 1 - float64(rs.WireBytes)/float64(rs.BodyBytes)
+}
+
+// Metrics is a plugin recording per-route metrics. Metrics must
+// be registered before the compression plugin to observe the
+// size of responses after compression.
+//
+// Example usage:
+//
+//	m := metrics.New()
+//	v.Use(m).Use(compression.New())
+//	v.GetHandler("/admin/metrics", m.Report())
+type Metrics struct {
+	// Core is the core functionality for plugins
+	plugins.Core
+
+	// Now returns the current time. Defaults to time.Now
+	Now func() time.Time
+
+	routes map[string]*RouteStats
+	mutex  sync.RWMutex
+}
+
+// New returns a newly initialized Metrics plugin
+func New() *Metrics {
+	return &Metrics{
+		Core:   plugins.Core{Id: "plugins.Metrics"},
+		routes: make(map[string]*RouteStats),
+	}
+}
+
+// Handle is called per web request to record metrics
+// for the request's route
+func (plugin *Metrics) Handle(c *verto.Context, next http.HandlerFunc) {
+	plugin.Core.Handle(
+		func(c *verto.Context, next http.HandlerFunc) {
+			start := plugin.now()
+			r, size := verto.WithPayloadSize(c.Request)
+			cw := &countingWriter{ResponseWriter: c.Response, status: http.StatusOK}
+
+			next(cw, r)
+
+			body, wire := cw.n, cw.n
+			if size.Encoded() {
+				body, wire = size.Body(), size.Wire()
+			}
+			plugin.record(r, cw.status, plugin.now().Sub(start), body, wire)
+		}, c, next)
+}
+
+// Stats returns the metrics of all routes sorted by path and method
+func (plugin *Metrics) Stats() []RouteStats {
+	plugin.mutex.RLock()
+	defer plugin.mutex.RUnlock()
+
+	stats := make([]RouteStats, 0, len(plugin.routes))
+	for _, rs := range plugin.routes {
+		stats = append(stats, *rs)
+	}
+	sort.Slice(stats, func(i, j int) bool {
+		if stats[i].Path != stats[j].Path {
+			return stats[i].Path < stats[j].Path
+		}
+		return stats[i].Method < stats[j].Method
+	})
+	return stats
+}
+
+// Reset clears all recorded metrics
+func (plugin *Metrics) Reset() {
+	plugin.mutex.Lock()
+	defer plugin.mutex.Unlock()
+
+	plugin.routes = make(map[string]*RouteStats)
+}
+
+// Report returns an http.Handler serving the metrics
+// of all routes as JSON
+func (plugin *Metrics) Report() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(plugin.Stats())
+	})
+}
+
+// record adds a served request to the metrics of its route
+func (plugin *Metrics) record(r *http.Request, status int, d time.Duration, body, wire int64) {
+	method, path, name := r.Method, r.URL.Path, ""
+	if route := mux.CurrentRoute(r); route != nil {
+		method, path, name = route.Method(), route.Path(), route.Name()
+	}
+
+	plugin.mutex.Lock()
+	defer plugin.mutex.Unlock()
+
+	key := method + " " + path
+	rs, ok := plugin.routes[key]
+	if !ok {
+		rs = &RouteStats{Method: method, Path: path, Name: name}
+		plugin.routes[key] = rs
+	}
+	rs.Requests++
+	if status >= 500 {
+		rs.Errors++
+	}
+	rs.Duration += d
+	rs.BodyBytes += body
+	rs.WireBytes += wire
+}
+
+func (plugin *Metrics) now() time.Time {
+	if plugin.Now == nil {
+		return time.Now()
+	}
+	return plugin.Now()
+}
+
+// countingWriter is an http.ResponseWriter that records
+// the status and counts the body bytes of a response
+type countingWriter struct {
+	http.ResponseWriter
+
+	status  int
+	written bool
+	n       int64
+}
+
+func (w *countingWriter) WriteHeader(code int) {
+	if !w.written {
+		w.status = code
+		w.written = true
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *countingWriter) Write(b []byte) (int, error) {
+	w.written = true
+	n, err := w.ResponseWriter.Write(b)
+	w.n += int64(n)
+	return n, err
+}
+
+// Flush implements http.Flusher
+func (w *countingWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Hijack implements http.Hijacker. Hijacked
+// connections are recorded as protocol switches
+func (w *countingWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	conn, rw, err := verto.Hijack(w.ResponseWriter)
+	if err == nil && !w.written {
+		w.status = http.StatusSwitchingProtocols
+		w.written = true
+	}
+	return conn, rw, err
+}
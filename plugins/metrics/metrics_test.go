@@ -0,0 +1,84 @@
+package metrics
+
+import (
+	"encoding/json"
+	"github.com/boxtown/verto"
+	"github.com/boxtown/verto/plugins/compression"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestMetrics(t *testing.T) {
+	defer func() {
+		err := recover()
+		if err != nil {
+			t.Errorf(err.(error).Error())
+		}
+	}()
+
+	err := "Failed metrics."
+
+	m := New()
+	v := verto.New()
+	v.Logger = &verto.NilLogger{}
+	v.Use(m).Use(compression.New())
+
+	text := strings.Repeat("compressible ", 100)
+	v.GetHandler("/users/{id}", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(text))
+	})).Name("users.show")
+	v.GetHandler("/fail", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(500)
+	}))
+	h := &verto.HttpHandler{v}
+
+	serve := func(path, encoding string) {
+		r, _ := http.NewRequest("GET", "http://test.com"+path, nil)
+		r.Header.Set("Accept-Encoding", encoding)
+		h.ServeHTTP(httptest.NewRecorder(), r)
+	}
+	serve("/users/1", "gzip")
+	serve("/users/2", "identity")
+	serve("/fail", "")
+
+	stats := m.Stats()
+	if len(stats) != 2 || stats[0].Path != "/fail" || stats[0].Errors != 1 || stats[0].Name != "" {
+		t.Fatalf(err)
+	}
+	users := stats[1]
+	if users.Method != "GET" || users.Path != "/users/{id}" || users.Name != "users.show" || users.Requests != 2 {
+		t.Errorf(err)
+	}
+	if users.BodyBytes != int64(2*len(text)) {
+		t.Errorf(err)
+	}
+	if users.WireBytes <= int64(len(text)) || users.WireBytes >= users.BodyBytes || users.Savings() <= 0.4 {
+		t.Errorf(err)
+	}
+
+	// Test report
+	w := httptest.NewRecorder()
+	m.Report().ServeHTTP(w, nil)
+	report := make([]RouteStats, 0)
+	json.Unmarshal(w.Body.Bytes(), &report)
+	if len(report) != 2 || report[1].WireBytes != users.WireBytes {
+		t.Errorf(err)
+	}
+
+	m.Reset()
+	if len(m.Stats()) != 0 {
+		t.Errorf(err)
+	}
+
+	// Test requirements on other core plugins are checked
+	m.Requirements = []string{"plugins.Compression"}
+	if v.InitPlugins() != nil {
+		t.Errorf(err)
+	}
+	m.Requirements = []string{"plugins.Recorder"}
+	if v.InitPlugins() == nil {
+		t.Errorf(err)
+	}
+}
@@ -0,0 +1,242 @@
+// Package quota provides a plugin for long-window quota accounting
+// per client. Requests and bytes served are tracked per window (a day
+// by default) in a pluggable Store, reported to clients through X-Quota
+// headers and exposed to administrators through a reporting endpoint.
+package quota
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"github.com/boxtown/verto"
+	"github.com/boxtown/verto/apikeys"
+	"github.com/boxtown/verto/plugins"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Usage is the usage of a client within a window
+type Usage struct {
+	// Key identifies the client
+	Key string `json:"key"`
+
+	// Window is the start of the window
+	Window time.Time `json:"window"`
+
+	// Requests is the number of requests made in the window
+	Requests int64 `json:"requests"`
+
+	// Bytes is the number of response bytes served in the window
+	Bytes int64 `json:"bytes"`
+}
+
+// Limits are the quota limits of a client per window.
+// Zero values mean no limit
+type Limits struct {
+	Requests int64 `json:"requests"`
+	Bytes    int64 `json:"bytes"`
+}
+
+// Quota is a plugin that enforces per-client quotas. Clients that
+// have exhausted their request or byte quota for the current window
+// receive a 429 response until the window resets. Responses carry
+// X-Quota-Limit, X-Quota-Remaining and X-Quota-Reset headers (and
+// X-Quota-Bytes-Limit and X-Quota-Bytes-Remaining for byte quotas).
+//
+// Example usage:
+//
+//	q := quota.New(quota.NewMemoryStore(), quota.Limits{Requests: 10000})
+//	api.Use(apikeys.New(v.Injections, m)).Use(q)
+//	v.GetHandler("/admin/quota", q.Report())
+type Quota struct {
+	// Core is the core functionality for plugins
+	plugins.Core
+
+	// Store persists usage
+	Store Store
+
+	// Window is the accounting window. Defaults to 24 hours
+	Window time.Duration
+
+	// Limits are the default limits for clients
+	Limits Limits
+
+	// LimitsFn optionally returns the limits for a client key
+	// overriding the default limits (see TenantLimits)
+	LimitsFn func(key string) Limits
+
+	// KeyFn identifies the client of a request (see InjectionKey
+	// and PrincipalKey for tenant keys). If nil, the id of the
+	// request's API key is used if present and the client IP otherwise
+	KeyFn func(c *verto.Context) string
+
+	// OnExceeded is an optional callback invoked when a client
+	// exceeds its quota (e.g. to flag the client for a challenge)
+	OnExceeded func(key string, c *verto.Context)
+
+	// Now returns the current time. Defaults to time.Now
+	Now func() time.Time
+}
+
+// New returns a Quota plugin persisting usage to store
+// with limits as the default limits
+func New(store Store, limits Limits) *Quota {
+	return &Quota{
+		Core:   plugins.Core{Id: "plugins.Quota"},
+		Store:  store,
+		Limits: limits,
+	}
+}
+
+// Handle is called per web request to account for and enforce
+// the client's quota
+func (plugin *Quota) Handle(c *verto.Context, next http.HandlerFunc) {
+	plugin.Core.Handle(
+		func(c *verto.Context, next http.HandlerFunc) {
+			key := plugin.key(c)
+			window := plugin.now().Truncate(plugin.window())
+			reset := window.Add(plugin.window())
+			limits := plugin.limits(key)
+
+			usage, err := plugin.Store.Add(key, window, 1, 0)
+			if err != nil {
+				// Quota accounting failures should not take the API down
+				if c.Logger != nil {
+					c.Logger.Errorf("quota: could not record usage for %s: %s", key, err.Error())
+				}
+				next(c.Response, c.Request)
+				return
+			}
+
+			h := c.Response.Header()
+			h.Set("X-Quota-Reset", strconv.FormatInt(reset.Unix(), 10))
+			if limits.Requests > 0 {
+				h.Set("X-Quota-Limit", strconv.FormatInt(limits.Requests, 10))
+				h.Set("X-Quota-Remaining", strconv.FormatInt(remaining(limits.Requests, usage.Requests), 10))
+			}
+			if limits.Bytes > 0 {
+				h.Set("X-Quota-Bytes-Limit", strconv.FormatInt(limits.Bytes, 10))
+				h.Set("X-Quota-Bytes-Remaining", strconv.FormatInt(remaining(limits.Bytes, usage.Bytes), 10))
+			}
+
+			if (limits.Requests > 0 && usage.Requests > limits.Requests) ||
+				(limits.Bytes > 0 && usage.Bytes >= limits.Bytes) {
+				retry := int64(reset.Sub(plugin.now()) / time.Second)
+				if retry < 1 {
+					retry = 1
+				}
+				h.Set("Retry-After", strconv.FormatInt(retry, 10))
+				if plugin.OnExceeded != nil {
+					plugin.OnExceeded(key, c)
+				}
+				c.Response.WriteHeader(http.StatusTooManyRequests)
+				fmt.Fprint(c.Response, "Quota Exceeded.")
+				return
+			}
+
+			cw := &countingWriter{ResponseWriter: c.Response}
+			next(cw, c.Request)
+			if cw.n > 0 {
+				if _, err := plugin.Store.Add(key, window, 0, cw.n); err != nil && c.Logger != nil {
+					c.Logger.Errorf("quota: could not record usage for %s: %s", key, err.Error())
+				}
+			}
+		}, c, next)
+}
+
+// Report returns an http.Handler serving the usage of all clients
+// in the current window as JSON. An earlier window can be selected
+// with a unix timestamp in the window query parameter
+func (plugin *Quota) Report() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t := plugin.now()
+		if ts := r.URL.Query().Get("window"); ts != "" {
+			unix, err := strconv.ParseInt(ts, 10, 64)
+			if err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				fmt.Fprint(w, "Bad Request.")
+				return
+			}
+			t = time.Unix(unix, 0)
+		}
+
+		usages, err := plugin.Store.List(t.Truncate(plugin.window()))
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			fmt.Fprint(w, "Internal Server Error.")
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(usages)
+	})
+}
+
+// key returns the client key of the request
+func (plugin *Quota) key(c *verto.Context) string {
+	if plugin.KeyFn != nil {
+		return plugin.KeyFn(c)
+	}
+	if k := apikeys.From(c); k != nil {
+		return k.Id
+	}
+	return verto.GetIP(c.Request)
+}
+
+// limits returns the limits for key
+func (plugin *Quota) limits(key string) Limits {
+	if plugin.LimitsFn != nil {
+		return plugin.LimitsFn(key)
+	}
+	return plugin.Limits
+}
+
+func (plugin *Quota) window() time.Duration {
+	if plugin.Window <= 0 {
+		return 24 * time.Hour
+	}
+	return plugin.Window
+}
+
+func (plugin *Quota) now() time.Time {
+	if plugin.Now == nil {
+		return time.Now()
+	}
+	return plugin.Now()
+}
+
+// remaining returns the remaining quota for limit
+// after used, never less than zero
+func remaining(limit, used int64) int64 {
+	if used >= limit {
+		return 0
+	}
+	return limit - used
+}
+
+// countingWriter is an http.ResponseWriter that
+// counts the number of body bytes written
+type countingWriter struct {
+	http.ResponseWriter
+
+	n int64
+}
+
+func (w *countingWriter) Write(b []byte) (int, error) {
+	n, err := w.ResponseWriter.Write(b)
+	w.n += int64(n)
+	return n, err
+}
+
+// Flush implements http.Flusher
+func (w *countingWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Hijack implements http.Hijacker
+func (w *countingWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	return verto.Hijack(w.ResponseWriter)
+}
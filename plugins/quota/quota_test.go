@@ -0,0 +1,156 @@
+package quota
+
+import (
+	"encoding/json"
+	"github.com/boxtown/verto"
+	"github.com/boxtown/verto/plugins/authz"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestQuota(t *testing.T) {
+	defer func() {
+		err := recover()
+		if err != nil {
+			t.Errorf(err.(error).Error())
+		}
+	}()
+
+	err := "Failed quota."
+
+	now := time.Date(2017, time.January, 1, 12, 0, 0, 0, time.UTC)
+	q := New(NewMemoryStore(), Limits{Requests: 2, Bytes: 100})
+	q.KeyFn = func(c *verto.Context) string { return c.Request.Header.Get("X-Client") }
+	q.Now = func() time.Time { return now }
+	exceeded := ""
+	q.OnExceeded = func(key string, c *verto.Context) { exceeded = key }
+
+	v := verto.New()
+	v.Logger = &verto.NilLogger{}
+	v.Get("/data", func(c *verto.Context) (interface{}, error) {
+		return "0123456789", nil
+	}).Use(q)
+	v.GetHandler("/admin/quota", q.Report())
+	h := &verto.HttpHandler{v}
+
+	serve := func(client string) *httptest.ResponseRecorder {
+		r, _ := http.NewRequest("GET", "http://test.com/data", nil)
+		r.Header.Set("X-Client", client)
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		return w
+	}
+
+	w := serve("a")
+	if w.Code != 200 || w.Header().Get("X-Quota-Limit") != "2" || w.Header().Get("X-Quota-Remaining") != "1" {
+		t.Errorf(err)
+	}
+	if w.Header().Get("X-Quota-Reset") != "1483315200" {
+		t.Errorf(err)
+	}
+	w = serve("a")
+	if w.Code != 200 || w.Header().Get("X-Quota-Remaining") != "0" || w.Header().Get("X-Quota-Bytes-Remaining") != "90" {
+		t.Errorf(err)
+	}
+	w = serve("a")
+	if w.Code != http.StatusTooManyRequests || w.Header().Get("Retry-After") != "43200" || exceeded != "a" {
+		t.Errorf(err)
+	}
+
+	// Test other clients are unaffected
+	if serve("b").Code != 200 {
+		t.Errorf(err)
+	}
+
+	// Test report
+	r, _ := http.NewRequest("GET", "http://test.com/admin/quota", nil)
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	usages := make([]Usage, 0)
+	json.Unmarshal(w.Body.Bytes(), &usages)
+	if len(usages) != 2 || usages[0].Key != "a" || usages[0].Requests != 3 || usages[0].Bytes != 20 {
+		t.Errorf(err)
+	}
+
+	// Test window reset
+	now = now.Add(24 * time.Hour)
+	if serve("a").Code != 200 {
+		t.Errorf(err)
+	}
+}
+
+func TestTenantLimits(t *testing.T) {
+	defer func() {
+		err := recover()
+		if err != nil {
+			t.Errorf(err.(error).Error())
+		}
+	}()
+
+	err := "Failed tenant limits."
+
+	dir, _ := ioutil.TempDir("", "quota")
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "limits.json")
+	ioutil.WriteFile(path, []byte(`{"acme": {"requests": 1}}`), 0644)
+
+	tl := NewTenantLimits(FileLimits(path), Limits{Requests: 3})
+	q := New(NewMemoryStore(), tl.Default)
+	q.KeyFn = InjectionKey("tenant")
+	q.LimitsFn = tl.Limits
+
+	v := verto.New()
+	v.Logger = &verto.NilLogger{}
+	v.Use(verto.PluginFunc(func(c *verto.Context, next http.HandlerFunc) {
+		c.Injections().Set("tenant", c.Request.Header.Get("X-Tenant"))
+		next(c.Response, c.Request)
+	}))
+	v.Get("/data", func(c *verto.Context) (interface{}, error) {
+		return "data", nil
+	}).Use(q)
+	h := &verto.HttpHandler{v}
+
+	serve := func(tenant string) *httptest.ResponseRecorder {
+		r, _ := http.NewRequest("GET", "http://test.com/data", nil)
+		r.Header.Set("X-Tenant", tenant)
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		return w
+	}
+
+	if w := serve("acme"); w.Code != 200 || w.Header().Get("X-Quota-Limit") != "1" {
+		t.Errorf(err)
+	}
+	if serve("acme").Code != http.StatusTooManyRequests {
+		t.Errorf(err)
+	}
+	if w := serve("initech"); w.Code != 200 || w.Header().Get("X-Quota-Limit") != "3" {
+		t.Errorf(err)
+	}
+
+	// Test reloads pick up changed limits and failures keep them
+	ioutil.WriteFile(path, []byte(`{"acme": {"requests": 10}}`), 0644)
+	if tl.Reload() != nil || serve("acme").Code != 200 {
+		t.Errorf(err)
+	}
+	ioutil.WriteFile(path, []byte(`{`), 0644)
+	if tl.Reload() == nil || tl.Limits("acme").Requests != 10 {
+		t.Errorf(err)
+	}
+
+	// Test principals identify clients by id
+	r, _ := http.NewRequest("GET", "http://test.com/data", nil)
+	c := verto.NewContext(nil, r, func() verto.Injections {
+		i := verto.NewContainer()
+		i.Set(authz.PRINCIPALKEY, &authz.User{Id: "u1"})
+		return i
+	}, nil)
+	if PrincipalKey()(c) != "u1" {
+		t.Errorf(err)
+	}
+}
@@ -0,0 +1,96 @@
+package quota
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// Store is the interface for quota usage storage backends.
+// Implementations backed by shared storage (e.g. SQL or Redis)
+// allow quotas to be enforced across multiple instances.
+type Store interface {
+	// Add adds requests and bytes to the usage of key in the
+	// window starting at window and returns the updated usage
+	Add(key string, window time.Time, requests, bytes int64) (Usage, error)
+
+	// List returns the usage of all keys in the window
+	// starting at window
+	List(window time.Time) ([]Usage, error)
+}
+
+// MemoryStore is an in-memory Store that retains a bounded
+// number of windows. MemoryStore is thread-safe
+type MemoryStore struct {
+	// Retain is the number of windows retained.
+	// Defaults to 31
+	Retain int
+
+	windows map[int64]map[string]*Usage
+	mutex   *sync.Mutex
+}
+
+// NewMemoryStore returns an empty MemoryStore
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		Retain:  31,
+		windows: make(map[int64]map[string]*Usage),
+		mutex:   &sync.Mutex{},
+	}
+}
+
+// Add adds requests and bytes to the usage of key in window
+func (ms *MemoryStore) Add(key string, window time.Time, requests, bytes int64) (Usage, error) {
+	ms.mutex.Lock()
+	defer ms.mutex.Unlock()
+
+	w := window.UnixNano()
+	usages, ok := ms.windows[w]
+	if !ok {
+		usages = make(map[string]*Usage)
+		ms.windows[w] = usages
+		ms.prune()
+	}
+	u, ok := usages[key]
+	if !ok {
+		u = &Usage{Key: key, Window: window.UTC()}
+		usages[key] = u
+	}
+	u.Requests += requests
+	u.Bytes += bytes
+	return *u, nil
+}
+
+// List returns the usage of all keys in window ordered by key
+func (ms *MemoryStore) List(window time.Time) ([]Usage, error) {
+	ms.mutex.Lock()
+	defer ms.mutex.Unlock()
+
+	list := make([]Usage, 0)
+	for _, u := range ms.windows[window.UnixNano()] {
+		list = append(list, *u)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Key < list[j].Key
+	})
+	return list, nil
+}
+
+// prune drops the oldest windows exceeding Retain
+func (ms *MemoryStore) prune() {
+	retain := ms.Retain
+	if retain <= 0 {
+		retain = 31
+	}
+	if len(ms.windows) <= retain {
+		return
+	}
+	windows := make([]int64, 0, len(ms.windows))
+	for w := range ms.windows {
+		windows = append(windows, w)
+	}
+	sort.Slice(windows, func(i, j int) bool { return windows[i] < windows[j] })
+	for _, w := range windows[:len(windows)-retain] {
+		delete(ms.windows, w)
+	}
+}
@@ -0,0 +1,181 @@
+package quota
+
+import (
+	"encoding/json"
+	"github.com/boxtown/verto"
+	"github.com/boxtown/verto/plugins/authz"
+	"io/ioutil"
+	"sync"
+	"time"
+)
+
+// InjectionKey returns a KeyFn identifying clients by the request
+// injection under key, e.g. a tenant id set by an authentication plugin.
+// String injections are used as is and injections with an ID() string
+// method (such as authz.Principals) by their id. Requests without the
+// injection are identified by their client IP
+func InjectionKey(key string) func(c *verto.Context) string {
+	return func(c *verto.Context) string {
+		if c.Injections != nil {
+			if injections := c.Injections(); injections != nil {
+				switch v := injections.Get(key).(type) {
+				case string:
+					if v != "" {
+						return v
+					}
+				case interface{ ID() string }:
+					if id := v.ID(); id != "" {
+						return id
+					}
+				}
+			}
+		}
+		return verto.GetIP(c.Request)
+	}
+}
+
+// PrincipalKey returns a KeyFn identifying clients by the
+// id of the authz.Principal of the request
+func PrincipalKey() func(c *verto.Context) string {
+	return InjectionKey(authz.PRINCIPALKEY)
+}
+
+// LimitSource is the interface for sources of per-client limits
+// such as a database table or a configuration file
+type LimitSource interface {
+	// Load returns the limits of all clients with custom limits
+	Load() (map[string]Limits, error)
+}
+
+// StaticLimits is a LimitSource backed by a fixed map of limits
+type StaticLimits map[string]Limits
+
+// Load returns the map
+func (sl StaticLimits) Load() (map[string]Limits, error) {
+	return sl, nil
+}
+
+// FileLimits is a LimitSource reading limits from a JSON file
+// mapping client keys to limits, e.g.
+//
+//	{"acme": {"requests": 100000}, "initech": {"requests": 500, "bytes": 1048576}}
+type FileLimits string
+
+// Load reads and parses the file
+func (fl FileLimits) Load() (map[string]Limits, error) {
+	b, err := ioutil.ReadFile(string(fl))
+	if err != nil {
+		return nil, err
+	}
+	limits := make(map[string]Limits)
+	if err := json.Unmarshal(b, &limits); err != nil {
+		return nil, err
+	}
+	return limits, nil
+}
+
+// TenantLimits caches the per-client limits of a LimitSource and
+// reloads them periodically so that limits can be changed without
+// a restart. Clients without custom limits receive Default. A failed
+// reload keeps the previous limits. TenantLimits is thread-safe.
+//
+// Example usage:
+//
+//	tl := quota.NewTenantLimits(quota.FileLimits("limits.json"), quota.Limits{Requests: 1000})
+//	tl.Watch(time.Minute)
+//	q := quota.New(quota.NewMemoryStore(), tl.Default)
+//	q.KeyFn = quota.InjectionKey("tenant")
+//	q.LimitsFn = tl.Limits
+type TenantLimits struct {
+	// Source provides the per-client limits
+	Source LimitSource
+
+	// Default are the limits of clients without custom limits
+	Default Limits
+
+	// OnError is an optional callback invoked when reloading fails
+	OnError func(err error)
+
+	limits map[string]Limits
+	stop   chan struct{}
+	mutex  sync.RWMutex
+}
+
+// NewTenantLimits returns a TenantLimits loaded from source with
+// defaults as the limits of clients without custom limits. If the
+// initial load fails, defaults apply until a reload succeeds
+func NewTenantLimits(source LimitSource, defaults Limits) *TenantLimits {
+	tl := &TenantLimits{Source: source, Default: defaults}
+	tl.Reload()
+	return tl
+}
+
+// Limits returns the limits of key. It is suitable as Quota.LimitsFn
+func (tl *TenantLimits) Limits(key string) Limits {
+	tl.mutex.RLock()
+	defer tl.mutex.RUnlock()
+
+	if limits, ok := tl.limits[key]; ok {
+		return limits
+	}
+	return tl.Default
+}
+
+// Reload loads the limits from Source, keeping
+// the previous limits if loading fails
+func (tl *TenantLimits) Reload() error {
+	limits, err := tl.Source.Load()
+	if err != nil {
+		return err
+	}
+	copied := make(map[string]Limits, len(limits))
+	for k, v := range limits {
+		copied[k] = v
+	}
+
+	tl.mutex.Lock()
+	tl.limits = copied
+	tl.mutex.Unlock()
+	return nil
+}
+
+// Watch reloads the limits every interval until Stop is called.
+// Watch does nothing if the limits are already watched
+func (tl *TenantLimits) Watch(interval time.Duration) {
+	tl.mutex.Lock()
+	defer tl.mutex.Unlock()
+
+	if tl.stop != nil {
+		return
+	}
+	tl.stop = make(chan struct{})
+	go tl.watch(interval, tl.stop)
+}
+
+// Stop stops reloading the limits
+func (tl *TenantLimits) Stop() {
+	tl.mutex.Lock()
+	defer tl.mutex.Unlock()
+
+	if tl.stop != nil {
+		close(tl.stop)
+		tl.stop = nil
+	}
+}
+
+// watch reloads the limits every interval until stop is closed
+func (tl *TenantLimits) watch(interval time.Duration, stop chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			if err := tl.Reload(); err != nil && tl.OnError != nil {
+				tl.OnError(err)
+			}
+		}
+	}
+}
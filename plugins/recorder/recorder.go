@@ -0,0 +1,253 @@
+// Package recorder provides a plugin that records incoming requests to
+// files for debugging along with a replayer that re-issues recorded
+// requests against an in-process http.Handler. Recording is opt-in and
+// sensitive headers are redacted before requests are written.
+package recorder
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"github.com/boxtown/verto"
+	"github.com/boxtown/verto/plugins"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Redacted replaces the values of redacted headers
+const Redacted = "[REDACTED]"
+
+// DefaultRedactHeaders lists the headers redacted by default
+var DefaultRedactHeaders = []string{
+	"Authorization",
+	"Proxy-Authorization",
+	"Cookie",
+	"X-Api-Key",
+}
+
+// Recording is a recorded request
+type Recording struct {
+	Time       time.Time   `json:"time"`
+	Method     string      `json:"method"`
+	URL        string      `json:"url"`
+	Host       string      `json:"host"`
+	RemoteAddr string      `json:"remote_addr"`
+	Header     http.Header `json:"header"`
+	Body       []byte      `json:"body"`
+
+	// Truncated is true if the body exceeded the
+	// recorder's maximum body size
+	Truncated bool `json:"truncated"`
+}
+
+// Request returns a new request equivalent to the recorded request
+func (rec *Recording) Request() (*http.Request, error) {
+	r, err := http.NewRequest(rec.Method, rec.URL, bytes.NewReader(rec.Body))
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range rec.Header {
+		r.Header[k] = append([]string(nil), v...)
+	}
+	r.Host = rec.Host
+	r.RemoteAddr = rec.RemoteAddr
+	return r, nil
+}
+
+// Recorder is a plugin that records requests matching Filter as JSON
+// files in Dir. Request bodies are captured up to MaxBodySize bytes
+// and remain readable by later handlers.
+//
+// Example usage:
+//
+//	rec := recorder.New("/tmp/recordings")
+//	rec.Filter = func(r *http.Request) bool {
+//		return strings.HasPrefix(r.URL.Path, "/orders")
+//	}
+//	v.Use(rec)
+type Recorder struct {
+	// Core is the core functionality for plugins
+	plugins.Core
+
+	// Dir is the directory recordings are written to
+	Dir string
+
+	// Filter decides which requests are recorded.
+	// If nil, all requests are recorded
+	Filter func(r *http.Request) bool
+
+	// RedactHeaders lists headers whose values are redacted
+	RedactHeaders []string
+
+	// Sanitize is an optional function for further sanitizing
+	// recordings (e.g. scrubbing body fields) before they are written
+	Sanitize func(rec *Recording)
+
+	// MaxBodySize is the maximum number of body bytes recorded.
+	// Defaults to 1MB
+	MaxBodySize int64
+}
+
+// New returns a Recorder writing recordings to dir that
+// redacts DefaultRedactHeaders
+func New(dir string) *Recorder {
+	return &Recorder{
+		Core:          plugins.Core{Id: "plugins.Recorder"},
+		Dir:           dir,
+		RedactHeaders: DefaultRedactHeaders,
+	}
+}
+
+// Handle is called per web request to record matching requests
+func (plugin *Recorder) Handle(c *verto.Context, next http.HandlerFunc) {
+	plugin.Core.Handle(
+		func(c *verto.Context, next http.HandlerFunc) {
+			r := c.Request
+			if plugin.Filter == nil || plugin.Filter(r) {
+				if err := plugin.record(r); err != nil && c.Logger != nil {
+					c.Logger.Errorf("recorder: could not record request: %s", err.Error())
+				}
+			}
+			next(c.Response, r)
+		}, c, next)
+}
+
+// record captures r and writes it to a new file. The body of r
+// is replaced so that it can still be read in full
+func (plugin *Recorder) record(r *http.Request) error {
+	max := plugin.MaxBodySize
+	if max <= 0 {
+		max = 1 << 20
+	}
+
+	rec := &Recording{
+		Time:       time.Now().UTC(),
+		Method:     r.Method,
+		URL:        requestURL(r),
+		Host:       r.Host,
+		RemoteAddr: r.RemoteAddr,
+		Header:     make(http.Header),
+	}
+	for k, v := range r.Header {
+		rec.Header[k] = append([]string(nil), v...)
+	}
+	for _, h := range plugin.RedactHeaders {
+		if _, ok := rec.Header[http.CanonicalHeaderKey(h)]; ok {
+			rec.Header.Set(h, Redacted)
+		}
+	}
+
+	if r.Body != nil {
+		body, err := ioutil.ReadAll(io.LimitReader(r.Body, max+1))
+		if err != nil {
+			return err
+		}
+		r.Body = readCloser{io.MultiReader(bytes.NewReader(body), r.Body), r.Body}
+		if int64(len(body)) > max {
+			body = body[:max]
+			rec.Truncated = true
+		}
+		rec.Body = body
+	}
+
+	if plugin.Sanitize != nil {
+		plugin.Sanitize(rec)
+	}
+
+	b, err := json.MarshalIndent(rec, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(plugin.Dir, 0700); err != nil {
+		return err
+	}
+	name := rec.Time.Format("20060102T150405.000000000") + "-" + randomSuffix() + ".json"
+	return ioutil.WriteFile(filepath.Join(plugin.Dir, name), b, 0600)
+}
+
+// Load reads a recording from the file at path
+func Load(path string) (*Recording, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	rec := &Recording{}
+	if err := json.Unmarshal(b, rec); err != nil {
+		return nil, err
+	}
+	return rec, nil
+}
+
+// LoadDir reads all recordings in dir in the order
+// they were recorded
+func LoadDir(dir string) ([]*Recording, error) {
+	names, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(names)
+
+	recs := make([]*Recording, 0, len(names))
+	for _, name := range names {
+		rec, err := Load(name)
+		if err != nil {
+			return nil, err
+		}
+		recs = append(recs, rec)
+	}
+	return recs, nil
+}
+
+// Replay re-issues the recorded request against handler in-process
+// and returns the recorded response
+//
+// Example usage:
+//
+//	recs, _ := recorder.LoadDir("/tmp/recordings")
+//	for _, rec := range recs {
+//		w, _ := recorder.Replay(&verto.HttpHandler{v}, rec)
+//		fmt.Println(rec.Method, rec.URL, w.Code)
+//	}
+func Replay(handler http.Handler, rec *Recording) (*httptest.ResponseRecorder, error) {
+	r, err := rec.Request()
+	if err != nil {
+		return nil, err
+	}
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+	return w, nil
+}
+
+// readCloser combines a reader with the closer of the original body
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
+
+// requestURL returns the absolute URL of r
+func requestURL(r *http.Request) string {
+	if r.URL.IsAbs() {
+		return r.URL.String()
+	}
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+	return scheme + "://" + strings.TrimSuffix(r.Host, "/") + r.URL.RequestURI()
+}
+
+// randomSuffix returns a short random hex string
+// to keep recording file names unique
+func randomSuffix() string {
+	b := make([]byte, 4)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,76 @@
+package recorder
+
+import (
+	"github.com/boxtown/verto"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestRecorder(t *testing.T) {
+	defer func() {
+		err := recover()
+		if err != nil {
+			t.Errorf(err.(error).Error())
+		}
+	}()
+
+	err := "Failed recorder."
+
+	dir, _ := ioutil.TempDir("", "recorder")
+	defer os.RemoveAll(dir)
+
+	bodies := make([]string, 0)
+	v := verto.New()
+	v.Logger = &verto.NilLogger{}
+	v.PostHandler("/orders", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := ioutil.ReadAll(r.Body)
+		bodies = append(bodies, string(b)+r.Header.Get("Authorization"))
+		w.WriteHeader(http.StatusCreated)
+	}))
+	v.PostHandler("/other", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	rec := New(dir)
+	rec.Filter = func(r *http.Request) bool { return r.URL.Path == "/orders" }
+	rec.MaxBodySize = 5
+	v.Use(rec)
+	h := &verto.HttpHandler{v}
+
+	r, _ := http.NewRequest("POST", "http://test.com/orders?a=b", strings.NewReader("0123456789"))
+	r.Header.Set("Authorization", "secret")
+	h.ServeHTTP(httptest.NewRecorder(), r)
+	r, _ = http.NewRequest("POST", "http://test.com/other", strings.NewReader("x"))
+	h.ServeHTTP(httptest.NewRecorder(), r)
+
+	// Test body remained readable
+	if len(bodies) != 1 || bodies[0] != "0123456789secret" {
+		t.Errorf(err)
+	}
+
+	recs, e := LoadDir(dir)
+	if e != nil || len(recs) != 1 {
+		t.Fatalf(err)
+	}
+	recording := recs[0]
+	if recording.Method != "POST" || recording.URL != "http://test.com/orders?a=b" {
+		t.Errorf(err)
+	}
+	if string(recording.Body) != "01234" || !recording.Truncated {
+		t.Errorf(err)
+	}
+	if recording.Header.Get("Authorization") != Redacted {
+		t.Errorf(err)
+	}
+
+	// Test replay
+	w, e := Replay(h, recording)
+	if e != nil || w.Code != http.StatusCreated {
+		t.Errorf(err)
+	}
+	if len(bodies) != 2 || bodies[1] != "01234"+Redacted {
+		t.Errorf(err)
+	}
+}
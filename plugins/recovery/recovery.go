@@ -24,20 +24,21 @@ func New() *Recovery {
 }
 
 // Handle is called per web request to protect from program panics. If the OnRecover
-// function is supplied on the plugin, OnRecover will be called to handle program
-// panics. Otherwise, Handle will just bubble the panic up
+// function is supplied on the plugin, a Diagnostic is captured for the panic and
+// OnRecover will be called to handle it. The Diagnostic is available to OnRecover
+// through Context.Diagnostic. Otherwise, Handle will just bubble the panic up
 func (plugin *Recovery) Handle(c *verto.Context, next http.HandlerFunc) {
 	plugin.Core.Handle(
 		func(c *verto.Context, next http.HandlerFunc) {
-			r := c.Request
-			w := c.Response
-			next(w, r)
-			if rMsg := recover(); rMsg != nil {
-				if plugin.OnRecover != nil {
+			defer func() {
+				if rMsg := recover(); rMsg != nil {
+					if plugin.OnRecover == nil {
+						panic(rMsg)
+					}
+					c.Capture(nil, rMsg)
 					plugin.OnRecover(rMsg, c)
-				} else {
-					panic(rMsg)
 				}
-			}
+			}()
+			next(c.Response, c.Request)
 		}, c, next)
 }
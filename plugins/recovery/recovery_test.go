@@ -0,0 +1,45 @@
+package recovery
+
+import (
+	"fmt"
+	"github.com/boxtown/verto"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRecovery(t *testing.T) {
+	defer func() {
+		err := recover()
+		if err != nil {
+			t.Errorf(err.(error).Error())
+		}
+	}()
+
+	err := "Failed recovery."
+
+	var d *verto.Diagnostic
+	plugin := New()
+	plugin.OnRecover = func(rMsg interface{}, c *verto.Context) {
+		d = c.Diagnostic()
+		c.Response.WriteHeader(500)
+		fmt.Fprint(c.Response, "Internal Server Error.")
+	}
+
+	v := verto.New()
+	v.Logger = &verto.NilLogger{}
+	v.Get("/panic", func(c *verto.Context) (interface{}, error) {
+		panic("boom")
+	}).Use(plugin)
+	h := &verto.HttpHandler{v}
+
+	r, _ := http.NewRequest("GET", "http://test.com/panic", nil)
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	if w.Code != 500 || w.Body.String() != "Internal Server Error." {
+		t.Errorf(err)
+	}
+	if d == nil || d.Panic != "boom" || d.Message != "boom" || d.Route != "/panic" {
+		t.Fatalf(err)
+	}
+}
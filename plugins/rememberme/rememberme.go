@@ -0,0 +1,376 @@
+// Package rememberme provides persistent logins for Verto using the
+// selector:validator token pattern. The remember-me cookie holds a
+// selector identifying a stored token and a random validator of which
+// only a hash is stored. Validators are rotated on every use and reuse
+// of a rotated validator is treated as token theft, revoking all of
+// the user's tokens.
+package rememberme
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"github.com/boxtown/verto"
+	"github.com/boxtown/verto/plugins"
+	"github.com/boxtown/verto/plugins/authz"
+	"github.com/boxtown/verto/session"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// COOKIENAME is the name of the remember-me cookie
+const COOKIENAME = "_VertoRememberMe"
+
+// STATEKEY is the injection key of the per-request login state
+const STATEKEY = "_VertoRememberMeState"
+
+// USERKEY is the session key the logged in user id is stored under
+const USERKEY = session.USERKEY
+
+// ErrInvalidToken is returned if a remember-me cookie is
+// malformed or refers to a missing or expired token
+var ErrInvalidToken = errors.New("rememberme: invalid token")
+
+// ErrTheft is returned if a remember-me cookie carries a validator that
+// does not match its token. This happens when a stolen cookie was used
+// and rotated before the legitimate user presented their copy
+var ErrTheft = errors.New("rememberme: token theft detected")
+
+// LookupFunc returns the principal of the user with userId
+type LookupFunc func(userId string) (authz.Principal, error)
+
+// state is the per-request login state
+type state struct {
+	userId string
+}
+
+// RememberMe is a plugin that logs in users presenting a valid
+// remember-me cookie. Logged in users are stored in the request's
+// session under USERKEY if a session is registered at
+// session.SESSIONKEY, and exposed as the request's principal at
+// authz.PRINCIPALKEY if a LookupFunc is given. RememberMe should run
+// before any plugins accessing the principal.
+//
+// Example usage:
+//
+//	rm := rememberme.New(v.Injections, rememberme.NewMemoryStore(), findUser)
+//	v.Use(rm)
+//
+//	// in the login handler
+//	rm.Remember(c, user.Id)
+type RememberMe struct {
+	// Core is the core functionality for plugins
+	plugins.Core
+
+	// Store holds the tokens
+	Store Store
+
+	// TTL is the lifetime of tokens since their last use.
+	// Defaults to 30 days
+	TTL time.Duration
+
+	// Grace is the period after a rotation during which the previous
+	// validator is still accepted. Defaults to 30 seconds
+	Grace time.Duration
+
+	// OnTheft is an optional callback invoked after theft was detected
+	// and all tokens of the user were revoked (e.g. to notify the user)
+	OnTheft func(userId string, c *verto.Context)
+
+	// Now returns the current time. Defaults to time.Now
+	Now func() time.Time
+
+	// The below fields correspond to the fields within http.Cookie
+	Path   string
+	Domain string
+	Secure bool
+}
+
+// New registers the per-request login state in i and, if lookup is not
+// nil, a per-request lazy principal at authz.PRINCIPALKEY. Returns a new
+// RememberMe plugin backed by store
+func New(i verto.Injections, store Store, lookup LookupFunc) *RememberMe {
+	i.Lazy(
+		STATEKEY,
+		func(w http.ResponseWriter, r *http.Request, ri verto.ReadOnlyInjections) interface{} {
+			return &state{}
+		},
+		verto.REQUEST)
+	if lookup != nil {
+		i.Lazy(
+			authz.PRINCIPALKEY,
+			func(w http.ResponseWriter, r *http.Request, ri verto.ReadOnlyInjections) interface{} {
+				userId := ""
+				if st, ok := ri.Get(STATEKEY).(*state); ok {
+					userId = st.userId
+				}
+				if s, ok := ri.Get(session.SESSIONKEY).(session.Session); ok && userId == "" {
+					userId, _ = s.Get(USERKEY).(string)
+				}
+				if userId == "" {
+					return nil
+				}
+				if p, err := lookup(userId); err == nil {
+					return p
+				}
+				return nil
+			},
+			verto.REQUEST)
+	}
+
+	return &RememberMe{
+		Core:  plugins.Core{Id: "plugins.RememberMe"},
+		Store: store,
+		TTL:   30 * 24 * time.Hour,
+		Grace: 30 * time.Second,
+		Path:  "/",
+	}
+}
+
+// Handle is called per web request to log in users presenting
+// a remember-me cookie that are not logged in yet
+func (plugin *RememberMe) Handle(c *verto.Context, next http.HandlerFunc) {
+	plugin.Core.Handle(
+		func(c *verto.Context, next http.HandlerFunc) {
+			if UserId(c) != "" {
+				next(c.Response, c.Request)
+				return
+			}
+			cookie, err := c.Request.Cookie(COOKIENAME)
+			if err != nil {
+				next(c.Response, c.Request)
+				return
+			}
+
+			userId, err := plugin.authenticate(c, cookie.Value)
+			switch err {
+			case nil:
+				login(c, userId)
+			case ErrTheft:
+				plugin.expire(c)
+				if c.Logger != nil {
+					c.Logger.Warnf("rememberme: token theft detected for user %s", userId)
+				}
+				if plugin.OnTheft != nil {
+					plugin.OnTheft(userId, c)
+				}
+			case ErrInvalidToken:
+				plugin.expire(c)
+			default:
+				if c.Logger != nil {
+					c.Logger.Errorf("rememberme: could not authenticate: %s", err.Error())
+				}
+			}
+			next(c.Response, c.Request)
+		}, c, next)
+}
+
+// Remember issues a new remember-me token for the user with userId,
+// writes the remember-me cookie and logs the user in for the request.
+// Remember is generally called by login handlers
+func (plugin *RememberMe) Remember(c *verto.Context, userId string) error {
+	selector, err := random(12)
+	if err != nil {
+		return err
+	}
+	validator, err := random(32)
+	if err != nil {
+		return err
+	}
+	now := plugin.now()
+	t := &Token{
+		Selector: selector,
+		Hash:     hash(validator),
+		Rotated:  now,
+		UserId:   userId,
+		Expires:  now.Add(plugin.TTL),
+	}
+	if err := plugin.Store.Create(t); err != nil {
+		return err
+	}
+	plugin.write(c, selector, validator, t.Expires)
+	login(c, userId)
+	return nil
+}
+
+// Forget revokes the remember-me token of the request, expires the
+// remember-me cookie and logs the user out of the session. Forget is
+// generally called by logout handlers
+func (plugin *RememberMe) Forget(c *verto.Context) error {
+	if cookie, err := c.Request.Cookie(COOKIENAME); err == nil {
+		if selector, _, ok := split(cookie.Value); ok {
+			if err := plugin.Store.Delete(selector); err != nil {
+				return err
+			}
+		}
+	}
+	plugin.expire(c)
+	if st := stateOf(c); st != nil {
+		st.userId = ""
+	}
+	if s := sessionOf(c); s != nil {
+		s.Del(USERKEY)
+	}
+	return nil
+}
+
+// UserId returns the id of the user logged in through the session
+// or a remember-me cookie or the empty string
+func UserId(c *verto.Context) string {
+	if st := stateOf(c); st != nil && st.userId != "" {
+		return st.userId
+	}
+	if s := sessionOf(c); s != nil {
+		userId, _ := s.Get(USERKEY).(string)
+		return userId
+	}
+	return ""
+}
+
+// authenticate validates a remember-me cookie value and rotates
+// its validator. Returns the id of the token's user
+func (plugin *RememberMe) authenticate(c *verto.Context, value string) (string, error) {
+	selector, validator, ok := split(value)
+	if !ok {
+		return "", ErrInvalidToken
+	}
+	t, err := plugin.Store.Get(selector)
+	if err == ErrNotFound {
+		return "", ErrInvalidToken
+	}
+	if err != nil {
+		return "", err
+	}
+
+	now := plugin.now()
+	if now.After(t.Expires) {
+		plugin.Store.Delete(selector)
+		return "", ErrInvalidToken
+	}
+
+	h := hash(validator)
+	if equal(h, t.Hash) {
+		next, err := random(32)
+		if err != nil {
+			return "", err
+		}
+		t.PreviousHash = t.Hash
+		t.Hash = hash(next)
+		t.Rotated = now
+		t.Expires = now.Add(plugin.TTL)
+		err = plugin.Store.Rotate(t, t.PreviousHash)
+		if err == ErrConflict {
+			// A concurrent request rotated the token first. Its previous
+			// hash is now ours, so re-validating falls into the grace period
+			return plugin.authenticate(c, value)
+		}
+		if err != nil {
+			return "", err
+		}
+		plugin.write(c, selector, next, t.Expires)
+		return t.UserId, nil
+	}
+
+	// Concurrent requests may still carry the previous validator
+	if t.PreviousHash != "" && equal(h, t.PreviousHash) && now.Sub(t.Rotated) <= plugin.Grace {
+		return t.UserId, nil
+	}
+
+	if err := plugin.Store.DeleteUser(t.UserId); err != nil {
+		return "", err
+	}
+	return t.UserId, ErrTheft
+}
+
+// write writes the remember-me cookie
+func (plugin *RememberMe) write(c *verto.Context, selector, validator string, expires time.Time) {
+	http.SetCookie(c.Response, &http.Cookie{
+		Name:     COOKIENAME,
+		Value:    selector + ":" + validator,
+		Path:     plugin.Path,
+		Domain:   plugin.Domain,
+		Expires:  expires.UTC(),
+		Secure:   plugin.Secure,
+		HttpOnly: true,
+	})
+}
+
+// expire expires the remember-me cookie
+func (plugin *RememberMe) expire(c *verto.Context) {
+	http.SetCookie(c.Response, &http.Cookie{
+		Name:    COOKIENAME,
+		Path:    plugin.Path,
+		Domain:  plugin.Domain,
+		Expires: time.Now().UTC(),
+		MaxAge:  -1,
+	})
+}
+
+// now returns the current time
+func (plugin *RememberMe) now() time.Time {
+	if plugin.Now != nil {
+		return plugin.Now()
+	}
+	return time.Now()
+}
+
+// login records userId as the logged in user of the request
+func login(c *verto.Context, userId string) {
+	if st := stateOf(c); st != nil {
+		st.userId = userId
+	}
+	if s := sessionOf(c); s != nil {
+		s.Set(USERKEY, userId)
+	}
+}
+
+// stateOf retrieves the login state of the request
+func stateOf(c *verto.Context) *state {
+	if c.Injections == nil || c.Injections() == nil {
+		return nil
+	}
+	st, _ := c.Injections().Get(STATEKEY).(*state)
+	return st
+}
+
+// sessionOf retrieves the session of the request if registered
+func sessionOf(c *verto.Context) session.Session {
+	if c.Injections == nil || c.Injections() == nil {
+		return nil
+	}
+	s, _ := c.Injections().Get(session.SESSIONKEY).(session.Session)
+	return s
+}
+
+// split splits a cookie value into selector and validator
+func split(value string) (string, string, bool) {
+	parts := strings.SplitN(value, ":", 2)
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return "", "", false
+	}
+	return parts[0], parts[1], true
+}
+
+// hash returns the hex encoded SHA-256 hash of validator
+func hash(validator string) string {
+	sum := sha256.Sum256([]byte(validator))
+	return hex.EncodeToString(sum[:])
+}
+
+// equal compares hashes in constant time
+func equal(a, b string) bool {
+	return subtle.ConstantTimeCompare([]byte(a), []byte(b)) == 1
+}
+
+// random returns n random hex encoded bytes
+func random(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := io.ReadFull(rand.Reader, b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
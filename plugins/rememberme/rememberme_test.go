@@ -0,0 +1,186 @@
+package rememberme
+
+import (
+	"github.com/boxtown/verto"
+	"github.com/boxtown/verto/plugins/authz"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestRememberMe(t *testing.T) {
+	defer func() {
+		err := recover()
+		if err != nil {
+			t.Errorf(err.(error).Error())
+		}
+	}()
+
+	err := "Failed remember me."
+
+	now := time.Unix(1000, 0)
+	thefts := 0
+	store := NewMemoryStore()
+
+	v := verto.New()
+	v.Logger = &verto.NilLogger{}
+	rm := New(v.Injections, store, func(userId string) (authz.Principal, error) {
+		return &authz.User{Id: userId}, nil
+	})
+	rm.Now = func() time.Time { return now }
+	rm.OnTheft = func(userId string, c *verto.Context) { thefts++ }
+	v.Use(rm)
+
+	v.Post("/login", func(c *verto.Context) (interface{}, error) {
+		return "", rm.Remember(c, "u1")
+	})
+	v.Post("/logout", func(c *verto.Context) (interface{}, error) {
+		return "", rm.Forget(c)
+	})
+	v.Get("/me", func(c *verto.Context) (interface{}, error) {
+		if p, ok := c.Injections().Get(authz.PRINCIPALKEY).(authz.Principal); ok {
+			return UserId(c) + "/" + p.ID(), nil
+		}
+		return UserId(c), nil
+	})
+	h := &verto.HttpHandler{v}
+
+	serve := func(method, path string, cookie *http.Cookie) (string, *http.Cookie) {
+		r, _ := http.NewRequest(method, "http://test.com"+path, nil)
+		if cookie != nil {
+			r.AddCookie(cookie)
+		}
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		for _, c := range w.Result().Cookies() {
+			if c.Name == COOKIENAME {
+				return w.Body.String(), c
+			}
+		}
+		return w.Body.String(), nil
+	}
+
+	_, first := serve("POST", "/login", nil)
+	if first == nil || !first.HttpOnly {
+		t.Fatalf(err)
+	}
+
+	// Test login with rotation
+	body, second := serve("GET", "/me", first)
+	if body != "u1/u1" || second == nil || second.Value == first.Value {
+		t.Fatalf(err)
+	}
+
+	// Test the previous validator is accepted within the grace period
+	body, _ = serve("GET", "/me", first)
+	if body != "u1/u1" || thefts != 0 {
+		t.Errorf(err)
+	}
+
+	// Test reuse of a rotated validator after the grace period
+	body, third := serve("GET", "/me", second)
+	if body != "u1/u1" {
+		t.Fatalf(err)
+	}
+	now = now.Add(time.Minute)
+	body, expired := serve("GET", "/me", second)
+	if body != "" || thefts != 1 || expired == nil || expired.MaxAge >= 0 {
+		t.Errorf(err)
+	}
+	if body, _ = serve("GET", "/me", third); body != "" {
+		t.Errorf(err)
+	}
+
+	// Test expiry and logout
+	_, cookie := serve("POST", "/login", nil)
+	now = now.Add(31 * 24 * time.Hour)
+	if body, _ = serve("GET", "/me", cookie); body != "" {
+		t.Errorf(err)
+	}
+	_, cookie = serve("POST", "/login", nil)
+	serve("POST", "/logout", cookie)
+	if body, _ = serve("GET", "/me", cookie); body != "" {
+		t.Errorf(err)
+	}
+}
+
+func TestRememberMeConcurrentRotation(t *testing.T) {
+	defer func() {
+		err := recover()
+		if err != nil {
+			t.Errorf(err.(error).Error())
+		}
+	}()
+
+	err := "Failed remember me concurrent rotation."
+
+	thefts := 0
+	store := &racingStore{MemoryStore: NewMemoryStore()}
+
+	v := verto.New()
+	v.Logger = &verto.NilLogger{}
+	rm := New(v.Injections, store, nil)
+	rm.OnTheft = func(userId string, c *verto.Context) { thefts++ }
+	v.Use(rm)
+
+	v.Post("/login", func(c *verto.Context) (interface{}, error) {
+		return "", rm.Remember(c, "u1")
+	})
+	v.Get("/me", func(c *verto.Context) (interface{}, error) {
+		return UserId(c), nil
+	})
+	h := &verto.HttpHandler{v}
+
+	serve := func(method, path string, cookie *http.Cookie) (string, *http.Cookie) {
+		r, _ := http.NewRequest(method, "http://test.com"+path, nil)
+		if cookie != nil {
+			r.AddCookie(cookie)
+		}
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		for _, c := range w.Result().Cookies() {
+			if c.Name == COOKIENAME {
+				return w.Body.String(), c
+			}
+		}
+		return w.Body.String(), nil
+	}
+
+	_, first := serve("POST", "/login", nil)
+	if first == nil {
+		t.Fatalf(err)
+	}
+
+	// Serve a second request with the same cookie between the
+	// first request loading and rotating the token
+	var raced string
+	var rotated *http.Cookie
+	store.race = func() { raced, rotated = serve("GET", "/me", first) }
+
+	body, _ := serve("GET", "/me", first)
+	if body != "u1" || raced != "u1" || rotated == nil || thefts != 0 {
+		t.Fatalf(err)
+	}
+
+	// Test the cookie issued by the winning rotation remains valid
+	if body, _ = serve("GET", "/me", rotated); body != "u1" || thefts != 0 {
+		t.Errorf(err)
+	}
+}
+
+// racingStore is a Store calling race once after
+// the next token is loaded
+type racingStore struct {
+	*MemoryStore
+	race func()
+}
+
+func (rs *racingStore) Get(selector string) (*Token, error) {
+	t, err := rs.MemoryStore.Get(selector)
+	if race := rs.race; race != nil {
+		rs.race = nil
+		race()
+	}
+	return t, err
+}
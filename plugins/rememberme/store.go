@@ -0,0 +1,134 @@
+package rememberme
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrNotFound is returned by Stores if a token does not exist
+var ErrNotFound = errors.New("rememberme: token not found")
+
+// ErrConflict is returned by Store.Rotate if the stored token
+// was rotated since it was loaded
+var ErrConflict = errors.New("rememberme: token rotated concurrently")
+
+// Token is a persistent login token. Only a hash of the
+// validator is stored so that a leaked store cannot be
+// used to log in
+type Token struct {
+	// Selector identifies the token and is stored in plain text
+	Selector string
+
+	// Hash is the hex encoded SHA-256 hash of the validator
+	Hash string
+
+	// PreviousHash is the hash of the validator before the last
+	// rotation. It remains valid for a short grace period so that
+	// concurrent requests carrying the old cookie are not treated
+	// as theft
+	PreviousHash string
+
+	// Rotated is the time of the last rotation
+	Rotated time.Time
+
+	// UserId is the id of the user the token logs in
+	UserId string
+
+	// Expires is the time the token expires
+	Expires time.Time
+}
+
+// Store is the interface for persistent token storage backends.
+// Store implementations must be thread-safe
+type Store interface {
+	// Create stores a new token
+	Create(t *Token) error
+
+	// Get returns the token with selector or ErrNotFound
+	Get(selector string) (*Token, error)
+
+	// Rotate replaces the stored token with the same selector if its
+	// stored hash equals hash. Returns ErrConflict otherwise
+	Rotate(t *Token, hash string) error
+
+	// Delete deletes the token with selector
+	Delete(selector string) error
+
+	// DeleteUser deletes all tokens of the user with userId
+	DeleteUser(userId string) error
+}
+
+// MemoryStore is an in-memory Store. MemoryStore is thread-safe
+type MemoryStore struct {
+	tokens map[string]Token
+	mutex  *sync.Mutex
+}
+
+// NewMemoryStore returns an empty MemoryStore
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		tokens: make(map[string]Token),
+		mutex:  &sync.Mutex{},
+	}
+}
+
+// Create stores a copy of t
+func (ms *MemoryStore) Create(t *Token) error {
+	ms.mutex.Lock()
+	defer ms.mutex.Unlock()
+
+	ms.tokens[t.Selector] = *t
+	return nil
+}
+
+// Get returns a copy of the token with selector
+func (ms *MemoryStore) Get(selector string) (*Token, error) {
+	ms.mutex.Lock()
+	defer ms.mutex.Unlock()
+
+	t, ok := ms.tokens[selector]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &t, nil
+}
+
+// Rotate replaces the stored token with a copy of t if
+// the stored hash equals hash
+func (ms *MemoryStore) Rotate(t *Token, hash string) error {
+	ms.mutex.Lock()
+	defer ms.mutex.Unlock()
+
+	stored, ok := ms.tokens[t.Selector]
+	if !ok {
+		return ErrNotFound
+	}
+	if stored.Hash != hash {
+		return ErrConflict
+	}
+	ms.tokens[t.Selector] = *t
+	return nil
+}
+
+// Delete deletes the token with selector
+func (ms *MemoryStore) Delete(selector string) error {
+	ms.mutex.Lock()
+	defer ms.mutex.Unlock()
+
+	delete(ms.tokens, selector)
+	return nil
+}
+
+// DeleteUser deletes all tokens of the user with userId
+func (ms *MemoryStore) DeleteUser(userId string) error {
+	ms.mutex.Lock()
+	defer ms.mutex.Unlock()
+
+	for selector, t := range ms.tokens {
+		if t.UserId == userId {
+			delete(ms.tokens, selector)
+		}
+	}
+	return nil
+}
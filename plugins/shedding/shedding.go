@@ -0,0 +1,191 @@
+// Package shedding provides a load-shedding plugin for Verto. The plugin
+// tracks in-flight requests and response latency and, when the server is
+// overloaded, rejects requests to low-priority routes so that high-priority
+// routes keep being serviced.
+package shedding
+
+import (
+	"fmt"
+	"github.com/boxtown/verto"
+	"github.com/boxtown/verto/plugins"
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// PriorityKey is the route metadata key holding the Priority of a route
+const PriorityKey = "shedding.priority"
+
+// Priority is the priority of a route. Routes without
+// a priority have Normal priority
+type Priority int
+
+// Route priorities
+const (
+	// Low priority routes are shed first
+	Low Priority = iota
+
+	// Normal priority routes are shed when the server is critically overloaded
+	Normal
+
+	// High priority routes are never shed
+	High
+)
+
+// Shedder is a plugin that sheds requests while the server is overloaded.
+// The load is the highest of the ratio of in-flight requests to MaxInFlight
+// and the ratio of the average latency to MaxLatency. Requests to Low
+// priority routes are rejected with a 503 response and a Retry-After header
+// while the load is at least 1 and requests to Normal priority routes are
+// rejected while the load is at least Critical.
+//
+// Example usage:
+//
+//	s := shedding.New(200, 500*time.Millisecond)
+//	v.Use(s)
+//	v.Get("/reports", reports).Meta(shedding.PriorityKey, shedding.Low)
+//	v.Post("/checkout", checkout).Meta(shedding.PriorityKey, shedding.High)
+type Shedder struct {
+	// Core is the core functionality for plugins
+	plugins.Core
+
+	// MaxInFlight is the number of in-flight requests at which
+	// the server is overloaded. Zero disables the check
+	MaxInFlight int
+
+	// MaxLatency is the average latency at which the server
+	// is overloaded. Zero disables the check
+	MaxLatency time.Duration
+
+	// Critical is the load at which Normal priority
+	// routes are shed. Defaults to 1.5
+	Critical float64
+
+	// RetryAfter is sent with shed responses. Defaults to 5 seconds
+	RetryAfter time.Duration
+
+	// Decay is the weight of each new latency sample in the
+	// exponentially weighted average latency. Defaults to 0.1
+	Decay float64
+
+	// OnShed is an optional callback invoked for shed requests
+	OnShed func(c *verto.Context, load float64)
+
+	inFlight int
+	latency  float64
+	mutex    sync.Mutex
+}
+
+// New returns a Shedder considering the server overloaded at
+// maxInFlight in-flight requests or an average latency of maxLatency
+func New(maxInFlight int, maxLatency time.Duration) *Shedder {
+	return &Shedder{
+		Core:        plugins.Core{Id: "plugins.Shedding"},
+		MaxInFlight: maxInFlight,
+		MaxLatency:  maxLatency,
+	}
+}
+
+// Handle is called per web request to shed the request if the
+// server is overloaded and to track load otherwise
+func (plugin *Shedder) Handle(c *verto.Context, next http.HandlerFunc) {
+	plugin.Core.Handle(
+		func(c *verto.Context, next http.HandlerFunc) {
+			if load := plugin.Load(); plugin.shed(priority(c), load) {
+				if plugin.OnShed != nil {
+					plugin.OnShed(c, load)
+				}
+				c.Response.Header().Set("Retry-After", strconv.Itoa(plugin.retryAfter()))
+				c.Response.WriteHeader(http.StatusServiceUnavailable)
+				fmt.Fprint(c.Response, http.StatusText(http.StatusServiceUnavailable)+".")
+				return
+			}
+
+			plugin.mutex.Lock()
+			plugin.inFlight++
+			plugin.mutex.Unlock()
+
+			start := time.Now()
+			defer func() {
+				plugin.observe(time.Since(start))
+			}()
+			next(c.Response, c.Request)
+		}, c, next)
+}
+
+// Load returns the current load of the server. A load of
+// 1 or more means the server is overloaded
+func (plugin *Shedder) Load() float64 {
+	plugin.mutex.Lock()
+	defer plugin.mutex.Unlock()
+
+	load := 0.0
+	if plugin.MaxInFlight > 0 {
+		load = float64(plugin.inFlight) / float64(plugin.MaxInFlight)
+	}
+	if plugin.MaxLatency > 0 {
+		load = math.Max(load, plugin.latency/float64(plugin.MaxLatency))
+	}
+	return load
+}
+
+// InFlight returns the number of requests in flight
+func (plugin *Shedder) InFlight() int {
+	plugin.mutex.Lock()
+	defer plugin.mutex.Unlock()
+
+	return plugin.inFlight
+}
+
+// shed returns whether requests with priority p are shed at load
+func (plugin *Shedder) shed(p Priority, load float64) bool {
+	switch {
+	case p >= High:
+		return false
+	case p == Normal:
+		critical := plugin.Critical
+		if critical <= 0 {
+			critical = 1.5
+		}
+		return load >= critical
+	default:
+		return load >= 1
+	}
+}
+
+// observe records the completion of a request that took d
+func (plugin *Shedder) observe(d time.Duration) {
+	plugin.mutex.Lock()
+	defer plugin.mutex.Unlock()
+
+	decay := plugin.Decay
+	if decay <= 0 || decay > 1 {
+		decay = 0.1
+	}
+	plugin.inFlight--
+	plugin.latency += decay * (float64(d) - plugin.latency)
+}
+
+func (plugin *Shedder) retryAfter() int {
+	d := plugin.RetryAfter
+	if d <= 0 {
+		d = 5 * time.Second
+	}
+	s := int((d + time.Second - 1) / time.Second)
+	if s < 1 {
+		s = 1
+	}
+	return s
+}
+
+// priority returns the priority of the route matched for c
+func priority(c *verto.Context) Priority {
+	if p, ok := c.RouteMeta(PriorityKey); ok {
+		if p, ok := p.(Priority); ok {
+			return p
+		}
+	}
+	return Normal
+}
@@ -0,0 +1,89 @@
+package shedding
+
+import (
+	"github.com/boxtown/verto"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestShedder(t *testing.T) {
+	defer func() {
+		err := recover()
+		if err != nil {
+			t.Errorf(err.(error).Error())
+		}
+	}()
+
+	err := "Failed shedder."
+
+	s := New(2, 0)
+	shed := 0
+	s.OnShed = func(c *verto.Context, load float64) { shed++ }
+
+	v := verto.New()
+	v.Logger = &verto.NilLogger{}
+	v.Use(s)
+
+	release := make(chan struct{})
+	started := make(chan struct{}, 2)
+	v.Get("/block", func(c *verto.Context) (interface{}, error) {
+		started <- struct{}{}
+		<-release
+		return "ok", nil
+	})
+	ok := func(c *verto.Context) (interface{}, error) { return "ok", nil }
+	v.Get("/low", ok).Meta(PriorityKey, Low)
+	v.Get("/normal", ok)
+	v.Get("/high", ok).Meta(PriorityKey, High)
+	h := &verto.HttpHandler{v}
+
+	serve := func(path string) *httptest.ResponseRecorder {
+		r, _ := http.NewRequest("GET", "http://test.com"+path, nil)
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		return w
+	}
+
+	if serve("/low").Code != 200 {
+		t.Errorf(err)
+	}
+
+	// Saturate the server with two blocked requests
+	done := make(chan struct{})
+	for i := 0; i < 2; i++ {
+		go func() {
+			serve("/block")
+			done <- struct{}{}
+		}()
+	}
+	<-started
+	<-started
+	if s.InFlight() != 2 || s.Load() != 1 {
+		t.Errorf(err)
+	}
+
+	w := serve("/low")
+	if w.Code != 503 || w.Header().Get("Retry-After") != "5" || shed != 1 {
+		t.Errorf(err)
+	}
+	if serve("/normal").Code != 200 || serve("/high").Code != 200 {
+		t.Errorf(err)
+	}
+
+	close(release)
+	<-done
+	<-done
+	if s.InFlight() != 0 || serve("/low").Code != 200 {
+		t.Errorf(err)
+	}
+
+	// Test latency based shedding
+	s = New(0, time.Millisecond)
+	s.Decay = 1
+	s.observe(2 * time.Millisecond)
+	if s.Load() != 2 || !s.shed(Normal, s.Load()) || s.shed(High, s.Load()) {
+		t.Errorf(err)
+	}
+}
@@ -0,0 +1,145 @@
+// Package strictparse provides a plugin that hardens request parsing
+// against request smuggling and header injection. Requests with
+// conflicting framing headers, malformed header names or values, or
+// overlong URIs are rejected and logged for security monitoring.
+package strictparse
+
+import (
+	"github.com/boxtown/verto"
+	"github.com/boxtown/verto/plugins"
+	"net/http"
+	"strings"
+)
+
+// DefaultMaxURILength is the default maximum length of request URIs
+const DefaultMaxURILength = 8192
+
+// StrictParse is a plugin that rejects requests that are ambiguous
+// or malformed on the wire:
+//
+//   - requests carrying both Content-Length and Transfer-Encoding,
+//     multiple differing Content-Length values or a Transfer-Encoding
+//     other than chunked
+//   - header names that are not valid tokens and header values that
+//     contain control characters
+//   - URIs longer than MaxURILength, which receive a 414 response
+//
+// Other rejected requests receive a 400 response. StrictParse should be
+// registered as a guard so that it runs before routing.
+//
+// Example usage:
+//
+//	v.Guard(strictparse.New())
+type StrictParse struct {
+	// Core is the core functionality for plugins
+	plugins.Core
+
+	// MaxURILength is the maximum length of request URIs.
+	// Defaults to DefaultMaxURILength
+	MaxURILength int
+
+	// OnReject is an optional callback invoked with the reason
+	// a request was rejected (e.g. to feed security monitoring)
+	OnReject func(reason string, c *verto.Context)
+}
+
+// New returns a newly initialized StrictParse plugin
+func New() *StrictParse {
+	return &StrictParse{Core: plugins.Core{Id: "plugins.StrictParse"}}
+}
+
+// Handle is called per web request to reject malformed requests
+func (plugin *StrictParse) Handle(c *verto.Context, next http.HandlerFunc) {
+	plugin.Core.Handle(
+		func(c *verto.Context, next http.HandlerFunc) {
+			r := c.Request
+
+			status, reason := http.StatusOK, ""
+			if max := plugin.maxURILength(); len(r.RequestURI) > max || len(r.URL.String()) > max {
+				status, reason = http.StatusRequestURITooLong, "URI too long"
+			} else if reason = framing(r); reason != "" {
+				status = http.StatusBadRequest
+			} else if reason = malformedHeader(r.Header); reason != "" {
+				status = http.StatusBadRequest
+			}
+			if reason == "" {
+				next(c.Response, c.Request)
+				return
+			}
+
+			if c.Logger != nil {
+				c.Logger.Warnf("strictparse: rejected %s %.64q from %s: %s",
+					r.Method, r.URL.Path, verto.GetIP(r), reason)
+			}
+			if plugin.OnReject != nil {
+				plugin.OnReject(reason, c)
+			}
+			c.Response.Header().Set("Connection", "close")
+			c.Response.WriteHeader(status)
+			c.Response.Write([]byte(http.StatusText(status) + "."))
+		}, c, next)
+}
+
+func (plugin *StrictParse) maxURILength() int {
+	if plugin.MaxURILength <= 0 {
+		return DefaultMaxURILength
+	}
+	return plugin.MaxURILength
+}
+
+// framing returns why the body framing of r is ambiguous
+// or an empty string if it is not
+func framing(r *http.Request) string {
+	lengths := r.Header["Content-Length"]
+	for _, l := range lengths {
+		if strings.TrimSpace(l) != strings.TrimSpace(lengths[0]) {
+			return "conflicting Content-Length headers"
+		}
+	}
+
+	encodings := r.TransferEncoding
+	if te, ok := r.Header["Transfer-Encoding"]; ok {
+		encodings = te
+	}
+	if len(encodings) == 0 {
+		return ""
+	}
+	if len(lengths) > 0 {
+		return "both Content-Length and Transfer-Encoding"
+	}
+	if len(encodings) != 1 || !strings.EqualFold(strings.TrimSpace(encodings[0]), "chunked") {
+		return "unsupported Transfer-Encoding"
+	}
+	return ""
+}
+
+// malformedHeader returns why a header of h is malformed
+// or an empty string if all headers are well-formed
+func malformedHeader(h http.Header) string {
+	for name, values := range h {
+		if name == "" {
+			return "empty header name"
+		}
+		for i := 0; i < len(name); i++ {
+			if !isTokenChar(name[i]) {
+				return "invalid header name"
+			}
+		}
+		for _, value := range values {
+			for i := 0; i < len(value); i++ {
+				if b := value[i]; (b < ' ' && b != '\t') || b == 0x7f {
+					return "control character in header " + name
+				}
+			}
+		}
+	}
+	return ""
+}
+
+// isTokenChar returns whether b may appear in an HTTP token (RFC 7230)
+func isTokenChar(b byte) bool {
+	if b >= 'a' && b <= 'z' || b >= 'A' && b <= 'Z' || b >= '0' && b <= '9' {
+		return true
+	}
+	return strings.IndexByte("!#$%&'*+-.^_`|~", b) != -1
+}
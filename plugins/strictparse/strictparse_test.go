@@ -0,0 +1,77 @@
+package strictparse
+
+import (
+	"github.com/boxtown/verto"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestStrictParse(t *testing.T) {
+	defer func() {
+		err := recover()
+		if err != nil {
+			t.Errorf(err.(error).Error())
+		}
+	}()
+
+	err := "Failed strict parse."
+
+	reasons := make([]string, 0)
+	sp := New()
+	sp.MaxURILength = 64
+	sp.OnReject = func(reason string, c *verto.Context) {
+		reasons = append(reasons, reason)
+	}
+
+	v := verto.New()
+	v.Logger = &verto.NilLogger{}
+	v.Guard(sp)
+	v.Post("/data", func(c *verto.Context) (interface{}, error) {
+		return "ok", nil
+	})
+	h := &verto.HttpHandler{v}
+
+	serve := func(path string, header http.Header, te []string) *httptest.ResponseRecorder {
+		r, _ := http.NewRequest("POST", "http://test.com"+path, strings.NewReader("body"))
+		r.RequestURI = path
+		for k, values := range header {
+			r.Header[k] = values
+		}
+		r.TransferEncoding = te
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		return w
+	}
+
+	if serve("/data", nil, nil).Code != 200 {
+		t.Errorf(err)
+	}
+	if serve("/data", nil, []string{"chunked"}).Code != 200 {
+		t.Errorf(err)
+	}
+	if serve("/data", http.Header{"Content-Length": {"4", "5"}}, nil).Code != 400 {
+		t.Errorf(err)
+	}
+	if serve("/data", http.Header{"Content-Length": {"4"}}, []string{"chunked"}).Code != 400 {
+		t.Errorf(err)
+	}
+	if serve("/data", http.Header{"Transfer-Encoding": {"gzip, chunked"}}, nil).Code != 400 {
+		t.Errorf(err)
+	}
+	if serve("/data", http.Header{"X-Bad Name": {"a"}}, nil).Code != 400 {
+		t.Errorf(err)
+	}
+	if serve("/data", http.Header{"X-Injected": {"a\r\nSet-Cookie: x"}}, nil).Code != 400 {
+		t.Errorf(err)
+	}
+
+	// Test rejection happens before routing
+	if w := serve("/"+strings.Repeat("a", 100), nil, nil); w.Code != http.StatusRequestURITooLong {
+		t.Errorf(err)
+	}
+	if len(reasons) != 6 || reasons[5] != "URI too long" {
+		t.Errorf(err)
+	}
+}
@@ -0,0 +1,530 @@
+package validation
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"net/mail"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// jsonSchema is a compiled JSON Schema. The supported keywords are
+// type, enum, const, properties, required, additionalProperties, items,
+// minItems, maxItems, uniqueItems, minLength, maxLength, pattern, format
+// (email, date-time, date, uri), minimum, maximum, exclusiveMinimum,
+// exclusiveMaximum, multipleOf, allOf, anyOf, oneOf, not and local $refs
+// into definitions or $defs
+type jsonSchema struct {
+	// boolean schemas accept or reject everything
+	always *bool
+
+	ref                  string
+	types                []string
+	enum                 []interface{}
+	constant             interface{}
+	hasConst             bool
+	properties           map[string]*jsonSchema
+	required             []string
+	additionalProperties *jsonSchema
+	items                *jsonSchema
+	minItems, maxItems   *int
+	uniqueItems          bool
+	minLength, maxLength *int
+	pattern              *regexp.Regexp
+	format               string
+	minimum, maximum     *float64
+	exclusiveMinimum     *float64
+	exclusiveMaximum     *float64
+	multipleOf           *float64
+	allOf, anyOf, oneOf  []*jsonSchema
+	not                  *jsonSchema
+
+	// root is the root schema for resolving $refs
+	root *jsonSchemaRoot
+}
+
+// jsonSchemaRoot holds the definitions of a schema document
+type jsonSchemaRoot struct {
+	schema      *jsonSchema
+	definitions map[string]*jsonSchema
+}
+
+// JSONSchema compiles the JSON Schema document doc into a BodyValidator
+// enforcing it on JSON request bodies. Violations are reported per field
+// using dotted paths (e.g. author.name or tags[1]).
+//
+// Example usage:
+//
+//	body, err := validation.JSONSchemaFile("schemas/user.json")
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	validation.Attach(v.Post("/users", createUser), &validation.Schema{Body: body})
+func JSONSchema(doc []byte) (BodyValidator, error) {
+	var raw interface{}
+	if err := json.Unmarshal(doc, &raw); err != nil {
+		return nil, err
+	}
+	root := &jsonSchemaRoot{definitions: make(map[string]*jsonSchema)}
+	schema, err := compileSchema(raw, root)
+	if err != nil {
+		return nil, err
+	}
+	if m, ok := raw.(map[string]interface{}); ok {
+		for _, key := range []string{"definitions", "$defs"} {
+			defs, _ := m[key].(map[string]interface{})
+			for name, d := range defs {
+				compiled, err := compileSchema(d, root)
+				if err != nil {
+					return nil, err
+				}
+				root.definitions["#/"+key+"/"+name] = compiled
+			}
+		}
+	}
+	root.schema = schema
+	if err := schema.resolve(make(map[*jsonSchema]bool)); err != nil {
+		return nil, err
+	}
+
+	return BodyFunc(func(body []byte) []Violation {
+		var v interface{}
+		dec := json.NewDecoder(bytes.NewReader(body))
+		dec.UseNumber()
+		if err := dec.Decode(&v); err != nil {
+			return []Violation{{"body", err.Error()}}
+		}
+		return schema.validate(v, "")
+	}), nil
+}
+
+// JSONSchemaFile compiles the JSON Schema document
+// in the file at path into a BodyValidator
+func JSONSchemaFile(path string) (BodyValidator, error) {
+	doc, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return JSONSchema(doc)
+}
+
+// compileSchema compiles a decoded schema document
+func compileSchema(raw interface{}, root *jsonSchemaRoot) (*jsonSchema, error) {
+	s := &jsonSchema{root: root}
+	if b, ok := raw.(bool); ok {
+		s.always = &b
+		return s, nil
+	}
+	m, ok := raw.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("validation: schema must be an object or boolean")
+	}
+
+	var err error
+	sub := func(key string) *jsonSchema {
+		v, ok := m[key]
+		if !ok || err != nil {
+			return nil
+		}
+		var compiled *jsonSchema
+		compiled, err = compileSchema(v, root)
+		return compiled
+	}
+	subs := func(key string) []*jsonSchema {
+		list, _ := m[key].([]interface{})
+		compiled := make([]*jsonSchema, 0, len(list))
+		for _, v := range list {
+			if err != nil {
+				return nil
+			}
+			var c *jsonSchema
+			c, err = compileSchema(v, root)
+			compiled = append(compiled, c)
+		}
+		return compiled
+	}
+	number := func(key string) *float64 {
+		if f, ok := m[key].(float64); ok {
+			return &f
+		}
+		return nil
+	}
+	integer := func(key string) *int {
+		if f, ok := m[key].(float64); ok {
+			i := int(f)
+			return &i
+		}
+		return nil
+	}
+
+	s.ref, _ = m["$ref"].(string)
+	switch t := m["type"].(type) {
+	case string:
+		s.types = []string{t}
+	case []interface{}:
+		for _, e := range t {
+			if name, ok := e.(string); ok {
+				s.types = append(s.types, name)
+			}
+		}
+	}
+	s.enum, _ = m["enum"].([]interface{})
+	s.constant, s.hasConst = m["const"]
+	if props, ok := m["properties"].(map[string]interface{}); ok {
+		s.properties = make(map[string]*jsonSchema)
+		for name, p := range props {
+			if s.properties[name], err = compileSchema(p, root); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if req, ok := m["required"].([]interface{}); ok {
+		for _, r := range req {
+			if name, ok := r.(string); ok {
+				s.required = append(s.required, name)
+			}
+		}
+	}
+	s.additionalProperties = sub("additionalProperties")
+	s.items = sub("items")
+	s.minItems = integer("minItems")
+	s.maxItems = integer("maxItems")
+	s.uniqueItems, _ = m["uniqueItems"].(bool)
+	s.minLength = integer("minLength")
+	s.maxLength = integer("maxLength")
+	if p, ok := m["pattern"].(string); ok {
+		if s.pattern, err = regexp.Compile(p); err != nil {
+			return nil, err
+		}
+	}
+	s.format, _ = m["format"].(string)
+	s.minimum = number("minimum")
+	s.maximum = number("maximum")
+	s.exclusiveMinimum = number("exclusiveMinimum")
+	s.exclusiveMaximum = number("exclusiveMaximum")
+	s.multipleOf = number("multipleOf")
+	s.allOf = subs("allOf")
+	s.anyOf = subs("anyOf")
+	s.oneOf = subs("oneOf")
+	s.not = sub("not")
+	if err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// resolve checks that all $refs reachable from s resolve
+func (s *jsonSchema) resolve(seen map[*jsonSchema]bool) error {
+	if s == nil || seen[s] {
+		return nil
+	}
+	seen[s] = true
+	if s.ref != "" {
+		target := s.target()
+		if target == nil {
+			return fmt.Errorf("validation: unresolvable $ref %q", s.ref)
+		}
+		if err := target.resolve(seen); err != nil {
+			return err
+		}
+	}
+	children := []*jsonSchema{s.additionalProperties, s.items, s.not}
+	for _, p := range s.properties {
+		children = append(children, p)
+	}
+	children = append(children, s.allOf...)
+	children = append(children, s.anyOf...)
+	children = append(children, s.oneOf...)
+	for _, c := range children {
+		if err := c.resolve(seen); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// target returns the schema referenced by s.ref
+func (s *jsonSchema) target() *jsonSchema {
+	if s.ref == "#" {
+		return s.root.schema
+	}
+	return s.root.definitions[s.ref]
+}
+
+// validate validates the decoded JSON value v at path
+func (s *jsonSchema) validate(v interface{}, path string) []Violation {
+	field := path
+	if field == "" {
+		field = "body"
+	}
+	if s.always != nil {
+		if *s.always {
+			return nil
+		}
+		return []Violation{{field, "is not allowed"}}
+	}
+	if s.ref != "" {
+		return s.target().validate(v, path)
+	}
+
+	if len(s.types) > 0 && !typeMatches(v, s.types) {
+		return []Violation{{field, "must be of type " + strings.Join(s.types, " or ")}}
+	}
+
+	var violations []Violation
+	fail := func(format string, args ...interface{}) {
+		violations = append(violations, Violation{field, fmt.Sprintf(format, args...)})
+	}
+
+	if s.enum != nil {
+		found := false
+		for _, e := range s.enum {
+			if jsonEqual(v, e) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			fail("must be one of %s", jsonList(s.enum))
+		}
+	}
+	if s.hasConst && !jsonEqual(v, s.constant) {
+		fail("must be %s", jsonList([]interface{}{s.constant}))
+	}
+
+	switch t := v.(type) {
+	case map[string]interface{}:
+		for _, name := range s.required {
+			if _, ok := t[name]; !ok {
+				violations = append(violations, Violation{join(path, name), "is required"})
+			}
+		}
+		for _, name := range sortedNames(t) {
+			if p, ok := s.properties[name]; ok {
+				violations = append(violations, p.validate(t[name], join(path, name))...)
+			} else if s.additionalProperties != nil {
+				if s.additionalProperties.always != nil && !*s.additionalProperties.always {
+					violations = append(violations, Violation{join(path, name), "is not allowed"})
+				} else {
+					violations = append(violations, s.additionalProperties.validate(t[name], join(path, name))...)
+				}
+			}
+		}
+	case []interface{}:
+		if s.minItems != nil && len(t) < *s.minItems {
+			fail("must have at least %d items", *s.minItems)
+		}
+		if s.maxItems != nil && len(t) > *s.maxItems {
+			fail("must have at most %d items", *s.maxItems)
+		}
+		if s.uniqueItems {
+		unique:
+			for i := range t {
+				for j := i + 1; j < len(t); j++ {
+					if jsonEqual(t[i], t[j]) {
+						fail("must have unique items")
+						break unique
+					}
+				}
+			}
+		}
+		if s.items != nil {
+			for i, e := range t {
+				violations = append(violations, s.items.validate(e, path+"["+strconv.Itoa(i)+"]")...)
+			}
+		}
+	case string:
+		n := utf8.RuneCountInString(t)
+		if s.minLength != nil && n < *s.minLength {
+			fail("must be at least %d characters long", *s.minLength)
+		}
+		if s.maxLength != nil && n > *s.maxLength {
+			fail("must be at most %d characters long", *s.maxLength)
+		}
+		if s.pattern != nil && !s.pattern.MatchString(t) {
+			fail("must match %s", s.pattern.String())
+		}
+		if s.format != "" && !formatMatches(s.format, t) {
+			fail("must be a valid %s", s.format)
+		}
+	case json.Number:
+		f, _ := t.Float64()
+		if s.minimum != nil && f < *s.minimum {
+			fail("must be at least %v", *s.minimum)
+		}
+		if s.maximum != nil && f > *s.maximum {
+			fail("must be at most %v", *s.maximum)
+		}
+		if s.exclusiveMinimum != nil && f <= *s.exclusiveMinimum {
+			fail("must be greater than %v", *s.exclusiveMinimum)
+		}
+		if s.exclusiveMaximum != nil && f >= *s.exclusiveMaximum {
+			fail("must be less than %v", *s.exclusiveMaximum)
+		}
+		if s.multipleOf != nil && *s.multipleOf > 0 {
+			q := f / *s.multipleOf
+			if math.Abs(q-math.Round(q)) > 1e-9 {
+				fail("must be a multiple of %v", *s.multipleOf)
+			}
+		}
+	}
+
+	for _, sub := range s.allOf {
+		violations = append(violations, sub.validate(v, path)...)
+	}
+	if len(s.anyOf) > 0 {
+		matched := false
+		for _, sub := range s.anyOf {
+			if len(sub.validate(v, path)) == 0 {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			fail("must match at least one allowed schema")
+		}
+	}
+	if len(s.oneOf) > 0 {
+		matched := 0
+		for _, sub := range s.oneOf {
+			if len(sub.validate(v, path)) == 0 {
+				matched++
+			}
+		}
+		if matched != 1 {
+			fail("must match exactly one allowed schema")
+		}
+	}
+	if s.not != nil && len(s.not.validate(v, path)) == 0 {
+		fail("must not match the disallowed schema")
+	}
+	return violations
+}
+
+// typeMatches returns whether the decoded JSON value v is of one of types
+func typeMatches(v interface{}, types []string) bool {
+	for _, t := range types {
+		switch val := v.(type) {
+		case nil:
+			if t == "null" {
+				return true
+			}
+		case bool:
+			if t == "boolean" {
+				return true
+			}
+		case string:
+			if t == "string" {
+				return true
+			}
+		case map[string]interface{}:
+			if t == "object" {
+				return true
+			}
+		case []interface{}:
+			if t == "array" {
+				return true
+			}
+		case json.Number:
+			if t == "number" {
+				return true
+			}
+			if t == "integer" {
+				if _, err := val.Int64(); err == nil {
+					return true
+				}
+				if f, err := val.Float64(); err == nil && f == math.Trunc(f) {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// formatMatches returns whether s is valid for format.
+// Unknown formats are not checked
+func formatMatches(format, s string) bool {
+	switch format {
+	case "email":
+		a, err := mail.ParseAddress(s)
+		return err == nil && a.Address == s
+	case "date-time":
+		_, err := time.Parse(time.RFC3339, s)
+		return err == nil
+	case "date":
+		_, err := time.Parse("2006-01-02", s)
+		return err == nil
+	case "uri":
+		u, err := url.Parse(s)
+		return err == nil && u.Scheme != ""
+	}
+	return true
+}
+
+// jsonEqual compares decoded JSON values. Numbers
+// are compared by their numeric value
+func jsonEqual(a, b interface{}) bool {
+	ja, _ := json.Marshal(normalize(a))
+	jb, _ := json.Marshal(normalize(b))
+	return bytes.Equal(ja, jb)
+}
+
+// normalize converts numbers in a decoded JSON value to float64
+func normalize(v interface{}) interface{} {
+	switch t := v.(type) {
+	case json.Number:
+		f, _ := t.Float64()
+		return f
+	case map[string]interface{}:
+		m := make(map[string]interface{}, len(t))
+		for k, e := range t {
+			m[k] = normalize(e)
+		}
+		return m
+	case []interface{}:
+		arr := make([]interface{}, len(t))
+		for i, e := range t {
+			arr[i] = normalize(e)
+		}
+		return arr
+	}
+	return v
+}
+
+// jsonList renders values as a comma separated list of JSON values
+func jsonList(values []interface{}) string {
+	parts := make([]string, len(values))
+	for i, v := range values {
+		b, _ := json.Marshal(v)
+		parts[i] = string(b)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// join joins a field name onto a dotted path
+func join(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
+
+// sortedNames returns the keys of m in sorted order so
+// that violations are reported deterministically
+func sortedNames(m map[string]interface{}) []string {
+	names := make([]string, 0, len(m))
+	for k := range m {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	return names
+}
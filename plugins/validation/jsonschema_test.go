@@ -0,0 +1,117 @@
+package validation
+
+import (
+	"encoding/json"
+	"github.com/boxtown/verto"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const userSchema = `{
+	"type": "object",
+	"required": ["name", "email"],
+	"additionalProperties": false,
+	"properties": {
+		"name": {"type": "string", "minLength": 2},
+		"email": {"type": "string", "format": "email"},
+		"age": {"type": "integer", "minimum": 0, "exclusiveMaximum": 150},
+		"role": {"enum": ["admin", "user"]},
+		"tags": {"type": "array", "items": {"type": "string"}, "uniqueItems": true},
+		"address": {"$ref": "#/definitions/address"}
+	},
+	"definitions": {
+		"address": {
+			"type": "object",
+			"required": ["city"],
+			"properties": {"city": {"type": "string"}}
+		}
+	}
+}`
+
+func TestJSONSchema(t *testing.T) {
+	defer func() {
+		err := recover()
+		if err != nil {
+			t.Errorf(err.(error).Error())
+		}
+	}()
+
+	err := "Failed json schema."
+
+	dir, _ := ioutil.TempDir("", "schema")
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "user.json")
+	ioutil.WriteFile(path, []byte(userSchema), 0644)
+
+	body, e := JSONSchemaFile(path)
+	if e != nil {
+		t.Fatalf(e.Error())
+	}
+
+	v := verto.New()
+	v.Logger = &verto.NilLogger{}
+	v.Use(New())
+	Attach(v.Post("/users", func(c *verto.Context) (interface{}, error) {
+		return "created", nil
+	}), &Schema{Body: body})
+	h := &verto.HttpHandler{v}
+
+	serve := func(body string) *httptest.ResponseRecorder {
+		r, _ := http.NewRequest("POST", "http://test.com/users", strings.NewReader(body))
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		return w
+	}
+
+	w := serve(`{"name": "ab", "email": "a@test.com", "age": 3, "tags": ["x"], "address": {"city": "c"}}`)
+	if w.Code != 200 || w.Body.String() != "created" {
+		t.Errorf(err)
+	}
+
+	w = serve(`{"name": "a", "email": "nope", "age": 1.5, "role": "root", "tags": ["x", "x", 1], "address": {}, "extra": 1}`)
+	if w.Code != 422 {
+		t.Errorf(err)
+	}
+	result := struct {
+		Errors []Violation `json:"errors"`
+	}{}
+	json.Unmarshal(w.Body.Bytes(), &result)
+	expected := map[string]bool{
+		"name": true, "email": true, "age": true, "role": true, "tags": true,
+		"tags[2]": true, "address.city": true, "extra": true,
+	}
+	if len(result.Errors) != len(expected) {
+		t.Errorf(err)
+	}
+	for _, violation := range result.Errors {
+		if !expected[violation.Field] {
+			t.Errorf(err)
+		}
+	}
+
+	// Test missing required fields and malformed bodies
+	w = serve(`{}`)
+	json.Unmarshal(w.Body.Bytes(), &result)
+	if w.Code != 422 || len(result.Errors) != 2 || result.Errors[0].Field != "name" {
+		t.Errorf(err)
+	}
+	if serve(`{`).Code != 422 {
+		t.Errorf(err)
+	}
+
+	// Test compile errors
+	if _, e := JSONSchema([]byte(`{"$ref": "#/definitions/missing"}`)); e == nil {
+		t.Errorf(err)
+	}
+	if _, e := JSONSchema([]byte(`{"pattern": "("}`)); e == nil {
+		t.Errorf(err)
+	}
+	if _, e := JSONSchema([]byte(`{"oneOf": [{"type": "string"}, {"type": "integer", "multipleOf": 2}]}`)); e != nil {
+		t.Errorf(err)
+	}
+}
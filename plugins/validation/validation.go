@@ -0,0 +1,338 @@
+// Package validation provides a plugin that validates incoming requests
+// against per-route schemas before the route handler is run. Schemas are
+// registered as route metadata so that validation can be centralized in
+// a single global plugin instead of being scattered across handlers.
+package validation
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/boxtown/verto"
+	"github.com/boxtown/verto/plugins"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// METAKEY is the route metadata key under which
+// a *Schema is registered
+const METAKEY = "validation.schema"
+
+// Type is the expected type of a request parameter
+type Type int
+
+const (
+	// String parameters are accepted as is
+	String Type = iota
+
+	// Int parameters must parse as a base 10 integer
+	Int
+
+	// Float parameters must parse as a float64
+	Float
+
+	// Bool parameters must parse as a boolean
+	Bool
+)
+
+// Violation describes a single failed validation
+type Violation struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// Rule describes the constraints on a single request parameter.
+// Zero values for constraints mean the constraint is not checked.
+type Rule struct {
+	// Required designates the parameter as mandatory
+	Required bool
+
+	// Type is the type the parameter must parse as
+	Type Type
+
+	// Pattern is a regular expression the parameter must match
+	Pattern *regexp.Regexp
+
+	// MinLength and MaxLength constrain the length of the parameter
+	MinLength int
+	MaxLength int
+
+	// OneOf restricts the parameter to a set of allowed values
+	OneOf []string
+
+	// Fn is an optional custom check. A non-nil error is
+	// reported as a violation using the error's message
+	Fn func(value string) error
+}
+
+// validate checks values against the rule and returns any violations
+func (rule Rule) validate(name string, values []string) []Violation {
+	if len(values) == 0 || (len(values) == 1 && values[0] == "") {
+		if rule.Required {
+			return []Violation{{name, "is required"}}
+		}
+		return nil
+	}
+
+	var violations []Violation
+	for _, v := range values {
+		if msg := rule.check(v); msg != "" {
+			violations = append(violations, Violation{name, msg})
+		}
+	}
+	return violations
+}
+
+// check validates a single value and returns a violation
+// message or an empty string if the value is valid
+func (rule Rule) check(v string) string {
+	switch rule.Type {
+	case Int:
+		if _, err := strconv.ParseInt(v, 10, 64); err != nil {
+			return "must be an integer"
+		}
+	case Float:
+		if _, err := strconv.ParseFloat(v, 64); err != nil {
+			return "must be a number"
+		}
+	case Bool:
+		if _, err := strconv.ParseBool(v); err != nil {
+			return "must be a boolean"
+		}
+	}
+	if rule.MinLength > 0 && len(v) < rule.MinLength {
+		return fmt.Sprintf("must be at least %d characters", rule.MinLength)
+	}
+	if rule.MaxLength > 0 && len(v) > rule.MaxLength {
+		return fmt.Sprintf("must be at most %d characters", rule.MaxLength)
+	}
+	if rule.Pattern != nil && !rule.Pattern.MatchString(v) {
+		return "must match " + rule.Pattern.String()
+	}
+	if len(rule.OneOf) > 0 {
+		found := false
+		for _, o := range rule.OneOf {
+			if o == v {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return "must be one of " + strings.Join(rule.OneOf, ", ")
+		}
+	}
+	if rule.Fn != nil {
+		if err := rule.Fn(v); err != nil {
+			return err.Error()
+		}
+	}
+	return ""
+}
+
+// BodyValidator is an interface for validating raw request bodies
+type BodyValidator interface {
+	Validate(body []byte) []Violation
+}
+
+// BodyFunc wraps functions so that they implement BodyValidator
+type BodyFunc func(body []byte) []Violation
+
+// Validate calls the function wrapped by BodyFunc
+func (bf BodyFunc) Validate(body []byte) []Violation {
+	return bf(body)
+}
+
+// JSONStruct returns a BodyValidator that checks that the body decodes
+// as JSON into a fresh instance of proto's type. Struct fields tagged
+// with `validate:"required"` must be present and non-zero.
+func JSONStruct(proto interface{}) BodyValidator {
+	t := reflect.TypeOf(proto)
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return BodyFunc(func(body []byte) []Violation {
+		v := reflect.New(t)
+		if err := json.Unmarshal(body, v.Interface()); err != nil {
+			return []Violation{{"body", err.Error()}}
+		}
+		return checkRequired(v.Elem(), "")
+	})
+}
+
+// checkRequired recursively checks required struct fields
+func checkRequired(v reflect.Value, prefix string) []Violation {
+	if v.Kind() != reflect.Struct {
+		return nil
+	}
+
+	var violations []Violation
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.PkgPath != "" {
+			continue
+		}
+		name := f.Name
+		if tag := strings.Split(f.Tag.Get("json"), ",")[0]; tag != "" && tag != "-" {
+			name = tag
+		}
+		name = prefix + name
+
+		fv := v.Field(i)
+		if f.Tag.Get("validate") == "required" && isZero(fv) {
+			violations = append(violations, Violation{name, "is required"})
+			continue
+		}
+		if fv.Kind() == reflect.Ptr && !fv.IsNil() {
+			fv = fv.Elem()
+		}
+		violations = append(violations, checkRequired(fv, name+".")...)
+	}
+	return violations
+}
+
+// isZero returns whether v holds the zero value for its type
+func isZero(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface, reflect.Map, reflect.Slice:
+		return v.IsNil() || (v.Kind() != reflect.Ptr && v.Kind() != reflect.Interface && v.Len() == 0)
+	}
+	return reflect.DeepEqual(v.Interface(), reflect.Zero(v.Type()).Interface())
+}
+
+// Schema describes the parameters and body accepted by a route
+type Schema struct {
+	// Params maps parameter names (path, query, or form) to rules.
+	// Path parameters take precedence over query and form values
+	Params map[string]Rule
+
+	// Body is an optional validator for the request body
+	Body BodyValidator
+
+	// MaxBodySize caps the number of body bytes read for validation.
+	// Bodies larger than MaxBodySize are rejected. Defaults to 1MB
+	MaxBodySize int64
+}
+
+// Attach registers schema as metadata on the route represented by ep
+func Attach(ep *verto.Endpoint, schema *Schema) *verto.Endpoint {
+	return ep.Meta(METAKEY, schema)
+}
+
+// Validation is a plugin that rejects requests failing their route's
+// schema with a 422 Unprocessable Entity response before the route
+// handler is run. Routes without a registered schema are passed through.
+//
+// Example usage:
+//
+//	v.Use(validation.New())
+//
+//	ep := v.Get("/users/{id}", handler)
+//	validation.Attach(ep, &validation.Schema{
+//		Params: map[string]validation.Rule{
+//			"id": {Required: true, Type: validation.Int},
+//		},
+//	})
+type Validation struct {
+	// Core is the core functionality for plugins
+	plugins.Core
+
+	// OnInvalid is an optional function for writing the response
+	// to invalid requests. If nil, a 422 response containing
+	// a JSON list of violations is written
+	OnInvalid func(violations []Violation, c *verto.Context)
+}
+
+// New returns a newly initialized Validation plugin
+func New() *Validation {
+	return &Validation{Core: plugins.Core{Id: "plugins.Validation"}}
+}
+
+// Handle is called per web request to validate the request against
+// the matched route's schema.
+func (plugin *Validation) Handle(c *verto.Context, next http.HandlerFunc) {
+	plugin.Core.Handle(
+		func(c *verto.Context, next http.HandlerFunc) {
+			meta, _ := c.RouteMeta(METAKEY)
+			schema, ok := meta.(*Schema)
+			if !ok || schema == nil {
+				next(c.Response, c.Request)
+				return
+			}
+
+			if violations := schema.validate(c); len(violations) > 0 {
+				plugin.invalid(violations, c)
+				return
+			}
+			next(c.Response, c.Request)
+		}, c, next)
+}
+
+// validate validates the request in c against the schema
+func (schema *Schema) validate(c *verto.Context) []Violation {
+	var violations []Violation
+
+	// The body is validated first and restored so that
+	// form parsing for parameters can still read it
+	if schema.Body != nil {
+		body, err := readBody(c.Request, schema.MaxBodySize)
+		if err != nil {
+			return []Violation{{"body", err.Error()}}
+		}
+		violations = append(violations, schema.Body.Validate(body)...)
+	}
+
+	// Path parameters are looked up first so that they validate
+	// regardless of Verto's FormParams setting
+	params := c.Params()
+	for name, rule := range schema.Params {
+		var values []string
+		if value, ok := params[name]; ok {
+			values = []string{value}
+		} else {
+			values = c.GetMulti(name)
+		}
+		violations = append(violations, rule.validate(name, values)...)
+	}
+	return violations
+}
+
+// invalid writes the response for a request with violations
+func (plugin *Validation) invalid(violations []Violation, c *verto.Context) {
+	if plugin.OnInvalid != nil {
+		plugin.OnInvalid(violations, c)
+		return
+	}
+
+	c.Response.Header().Set("Content-Type", "application/json")
+	c.Response.WriteHeader(422)
+	json.NewEncoder(c.Response).Encode(struct {
+		Errors []Violation `json:"errors"`
+	}{violations})
+}
+
+// readBody reads up to max bytes from the request body and
+// replaces the body so that it can be read again
+func readBody(r *http.Request, max int64) ([]byte, error) {
+	if r.Body == nil {
+		return nil, nil
+	}
+	if max <= 0 {
+		max = 1 << 20
+	}
+	body, err := ioutil.ReadAll(io.LimitReader(r.Body, max+1))
+	r.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(body)) > max {
+		return nil, fmt.Errorf("exceeds maximum size of %d bytes", max)
+	}
+	r.Body = ioutil.NopCloser(bytes.NewReader(body))
+	return body, nil
+}
@@ -0,0 +1,140 @@
+package validation
+
+import (
+	"github.com/boxtown/verto"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestValidationPlugin(t *testing.T) {
+	defer func() {
+		err := recover()
+		if err != nil {
+			t.Errorf(err.(error).Error())
+		}
+	}()
+
+	err := "Failed validation."
+
+	v := verto.New()
+	v.Use(New())
+
+	called := false
+	handler := func(c *verto.Context) (interface{}, error) {
+		called = true
+		return "ok", nil
+	}
+
+	Attach(v.Get("/users/{id}", handler), &Schema{
+		Params: map[string]Rule{
+			"id":   {Required: true, Type: Int},
+			"sort": {OneOf: []string{"asc", "desc"}},
+		},
+	})
+	Attach(v.Post("/users", handler), &Schema{
+		Body: JSONStruct(struct {
+			Name string `json:"name" validate:"required"`
+		}{}),
+	})
+	v.Get("/free", handler)
+
+	h := &verto.HttpHandler{Verto: v}
+
+	// Test valid params
+	r, _ := http.NewRequest("GET", "http://test.com/users/1?sort=asc", nil)
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	if !called || w.Code != 200 {
+		t.Errorf(err)
+	}
+
+	// Test invalid params
+	called = false
+	r, _ = http.NewRequest("GET", "http://test.com/users/a?sort=up", nil)
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	if called || w.Code != 422 {
+		t.Errorf(err)
+	}
+	if !strings.Contains(w.Body.String(), "must be an integer") {
+		t.Errorf(err)
+	}
+	if !strings.Contains(w.Body.String(), "must be one of asc, desc") {
+		t.Errorf(err)
+	}
+
+	// Test invalid body
+	r, _ = http.NewRequest("POST", "http://test.com/users", strings.NewReader(`{"name":""}`))
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	if called || w.Code != 422 {
+		t.Errorf(err)
+	}
+
+	// Test valid body
+	r, _ = http.NewRequest("POST", "http://test.com/users", strings.NewReader(`{"name":"a"}`))
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	if !called || w.Code != 200 {
+		t.Errorf(err)
+	}
+
+	// Test route without schema
+	called = false
+	r, _ = http.NewRequest("GET", "http://test.com/free", nil)
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	if !called {
+		t.Errorf(err)
+	}
+}
+
+func TestValidationPathParams(t *testing.T) {
+	defer func() {
+		err := recover()
+		if err != nil {
+			t.Errorf(err.(error).Error())
+		}
+	}()
+
+	err := "Failed validation path params."
+
+	v := verto.New()
+	v.SetFormParams(false)
+	v.Use(New())
+
+	Attach(v.Get("/users/{id}", func(c *verto.Context) (interface{}, error) {
+		return "ok", nil
+	}), &Schema{
+		Params: map[string]Rule{
+			"id":   {Required: true, Type: Int},
+			"sort": {OneOf: []string{"asc", "desc"}},
+		},
+	})
+	h := &verto.HttpHandler{Verto: v}
+
+	// Test path parameters validate without form params
+	r, _ := http.NewRequest("GET", "http://test.com/users/42?sort=asc", nil)
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	if w.Code != 200 {
+		t.Errorf(err)
+	}
+
+	r, _ = http.NewRequest("GET", "http://test.com/users/a", nil)
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	if w.Code != 422 || !strings.Contains(w.Body.String(), "must be an integer") {
+		t.Errorf(err)
+	}
+
+	// Test query values do not shadow path parameters
+	r, _ = http.NewRequest("GET", "http://test.com/users/42?id=a", nil)
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	if w.Code != 200 {
+		t.Errorf(err)
+	}
+}
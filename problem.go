@@ -0,0 +1,198 @@
+package verto
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sync"
+)
+
+// ProblemContentType is the content type of RFC 7807 problem documents
+const ProblemContentType = "application/problem+json"
+
+// Problem is an RFC 7807 problem details document. Extensions
+// are written as additional top-level members
+type Problem struct {
+	Type       string
+	Title      string
+	Status     int
+	Detail     string
+	Instance   string
+	Extensions map[string]interface{}
+}
+
+// MarshalJSON implements json.Marshaler
+func (p *Problem) MarshalJSON() ([]byte, error) {
+	m := make(map[string]interface{}, len(p.Extensions)+5)
+	for k, v := range p.Extensions {
+		m[k] = v
+	}
+	m["type"] = p.Type
+	m["title"] = p.Title
+	m["status"] = p.Status
+	if p.Detail != "" {
+		m["detail"] = p.Detail
+	}
+	if p.Instance != "" {
+		m["instance"] = p.Instance
+	}
+	return json.Marshal(m)
+}
+
+// ProblemType describes the problem documents produced for a class of errors.
+// Empty fields are filled in from the error: Type defaults to about:blank,
+// Status to the status carried by the error and Title to the status text
+type ProblemType struct {
+	Type   string
+	Title  string
+	Status int
+}
+
+// ProblemRegistry maps Go errors to ProblemTypes. Errors are matched
+// in registration order against the sentinel errors, error types and
+// functions registered. ProblemRegistry is safe for concurrent use.
+//
+// Example usage:
+//
+//	reg := verto.NewProblemRegistry()
+//	reg.Register(sql.ErrNoRows, verto.ProblemType{
+//		Type:   "https://example.com/problems/not-found",
+//		Status: 404,
+//	})
+//	reg.RegisterType(&ValidationError{}, verto.ProblemType{
+//		Type:   "https://example.com/problems/invalid",
+//		Title:  "Your request is not valid.",
+//		Status: 422,
+//	})
+//	v.ErrorHandler = &verto.ProblemHandler{Registry: reg}
+type ProblemRegistry struct {
+	entries []problemEntry
+	mutex   *sync.RWMutex
+}
+
+type problemEntry struct {
+	match func(err error) bool
+	pt    ProblemType
+}
+
+// NewProblemRegistry returns a newly initialized ProblemRegistry
+func NewProblemRegistry() *ProblemRegistry {
+	return &ProblemRegistry{
+		entries: make([]problemEntry, 0),
+		mutex:   &sync.RWMutex{},
+	}
+}
+
+// Register maps errors matching target with errors.Is to pt
+func (reg *ProblemRegistry) Register(target error, pt ProblemType) {
+	reg.RegisterFunc(func(err error) bool { return errors.Is(err, target) }, pt)
+}
+
+// RegisterType maps errors wrapping an error of the
+// same type as example to pt
+func (reg *ProblemRegistry) RegisterType(example error, pt ProblemType) {
+	reg.RegisterFunc(matchType(example), pt)
+}
+
+// RegisterFunc maps errors for which match returns true to pt
+func (reg *ProblemRegistry) RegisterFunc(match func(err error) bool, pt ProblemType) {
+	reg.mutex.Lock()
+	defer reg.mutex.Unlock()
+
+	reg.entries = append(reg.entries, problemEntry{match: match, pt: pt})
+}
+
+// Lookup returns the ProblemType of the first registration
+// matching err and whether one matched
+func (reg *ProblemRegistry) Lookup(err error) (ProblemType, bool) {
+	if reg == nil {
+		return ProblemType{}, false
+	}
+	reg.mutex.RLock()
+	defer reg.mutex.RUnlock()
+
+	for _, entry := range reg.entries {
+		if entry.match(err) {
+			return entry.pt, true
+		}
+	}
+	return ProblemType{}, false
+}
+
+// DefaultProblems is the ProblemRegistry used by ProblemJSONErrorFunc
+var DefaultProblems = NewProblemRegistry()
+
+// ProblemHandler is an ErrorHandler that renders errors as RFC 7807
+// application/problem+json documents. The status of a document is the
+// status of the matching ProblemType, or the status carried by the error
+// (see ErrorStatus). The message and details of HTTPErrors become the
+// detail and a details extension of the document. Messages of other
+// errors are only disclosed in the development environment
+type ProblemHandler struct {
+	// Registry maps errors to problem types. Defaults
+	// to DefaultProblems if nil
+	Registry *ProblemRegistry
+}
+
+// Handle renders err as a problem document
+func (ph *ProblemHandler) Handle(err error, c *Context) {
+	if err == ErrClientClosed {
+		return
+	}
+	reg := ph.Registry
+	if reg == nil {
+		reg = DefaultProblems
+	}
+	p := NewProblem(err, c, reg)
+
+	c.Response.Header().Set("Content-Type", ProblemContentType)
+	c.Response.WriteHeader(p.Status)
+	json.NewEncoder(c.Response).Encode(p)
+}
+
+// ProblemJSONErrorFunc renders errors as RFC 7807 problem
+// documents using the DefaultProblems registry.
+//
+// Example usage:
+//
+//	v.ErrorHandler = verto.ErrorFunc(verto.ProblemJSONErrorFunc)
+func ProblemJSONErrorFunc(err error, c *Context) {
+	(&ProblemHandler{}).Handle(err, c)
+}
+
+// NewProblem returns the problem document for err in the request
+// of c resolved through DefaultTranslator and reg
+func NewProblem(err error, c *Context, reg *ProblemRegistry) *Problem {
+	err = DefaultTranslator.Translate(err)
+	p := &Problem{Type: "about:blank", Status: ErrorStatus(err)}
+	if c != nil && c.Request != nil {
+		p.Instance = c.Request.URL.RequestURI()
+	}
+
+	var he *HTTPError
+	switch {
+	case errors.As(err, &he):
+		if he.Message != "" {
+			p.Detail = he.Message
+		}
+		if he.Details != nil {
+			p.Extensions = map[string]interface{}{"details": he.Details}
+		}
+	case c != nil && c.Debug():
+		p.Detail = err.Error()
+	}
+
+	if pt, ok := reg.Lookup(err); ok {
+		if pt.Type != "" {
+			p.Type = pt.Type
+		}
+		if pt.Status != 0 {
+			p.Status = pt.Status
+		}
+		p.Title = pt.Title
+	}
+	if p.Title == "" {
+		p.Title = http.StatusText(p.Status)
+	}
+	return p
+}
@@ -0,0 +1,95 @@
+package verto
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type testValidationError struct {
+	field string
+}
+
+func (e *testValidationError) Error() string {
+	return e.field + " is invalid"
+}
+
+func TestProblemJSON(t *testing.T) {
+	defer func() {
+		err := recover()
+		if err != nil {
+			t.Errorf(err.(error).Error())
+		}
+	}()
+
+	err := "Failed problem json."
+
+	errGone := errors.New("gone")
+	reg := NewProblemRegistry()
+	reg.Register(errGone, ProblemType{Type: "https://example.com/gone", Status: 410})
+	reg.RegisterType(&testValidationError{}, ProblemType{
+		Type:   "https://example.com/invalid",
+		Title:  "Invalid request.",
+		Status: 422,
+	})
+
+	v := New()
+	v.Logger = &NilLogger{}
+	v.ErrorHandler = &ProblemHandler{Registry: reg}
+	v.Get("/missing", func(c *Context) (interface{}, error) {
+		return nil, NewError(404, "user not found").WithDetails([]string{"id"})
+	})
+	v.Get("/gone", func(c *Context) (interface{}, error) {
+		return nil, fmt.Errorf("loading: %w", errGone)
+	})
+	v.Get("/invalid", func(c *Context) (interface{}, error) {
+		return nil, fmt.Errorf("decoding: %w", &testValidationError{"name"})
+	})
+	v.Get("/internal", func(c *Context) (interface{}, error) {
+		return nil, errors.New("password=secret")
+	})
+	h := &HttpHandler{v}
+
+	serve := func(path string) (*httptest.ResponseRecorder, map[string]interface{}) {
+		r, _ := http.NewRequest("GET", "http://test.com"+path, nil)
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		doc := make(map[string]interface{})
+		json.Unmarshal(w.Body.Bytes(), &doc)
+		return w, doc
+	}
+
+	w, doc := serve("/missing?x=1")
+	if w.Code != 404 || w.Header().Get("Content-Type") != ProblemContentType {
+		t.Errorf(err)
+	}
+	if doc["type"] != "about:blank" || doc["title"] != "Not Found" || doc["status"] != 404.0 ||
+		doc["detail"] != "user not found" || doc["instance"] != "/missing?x=1" || doc["details"] == nil {
+		t.Errorf(err)
+	}
+
+	w, doc = serve("/gone")
+	if w.Code != 410 || doc["type"] != "https://example.com/gone" || doc["title"] != "Gone" {
+		t.Errorf(err)
+	}
+
+	w, doc = serve("/invalid")
+	if w.Code != 422 || doc["type"] != "https://example.com/invalid" || doc["title"] != "Invalid request." {
+		t.Errorf(err)
+	}
+
+	// Test internal error messages are not disclosed
+	w, doc = serve("/internal")
+	if w.Code != 500 || doc["title"] != "Internal Server Error" || doc["detail"] != nil {
+		t.Errorf(err)
+	}
+
+	// Test default registry
+	v.ErrorHandler = ErrorFunc(ProblemJSONErrorFunc)
+	if w, _ = serve("/gone"); w.Code != 500 || w.Header().Get("Content-Type") != ProblemContentType {
+		t.Errorf(err)
+	}
+}
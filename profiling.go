@@ -0,0 +1,96 @@
+package verto
+
+import (
+	"expvar"
+	"net"
+	"net/http"
+	"net/http/pprof"
+	"strings"
+)
+
+// EnableProfiling mounts the net/http/pprof and expvar handlers under prefix
+// (e.g. /debug/pprof/ and /debug/vars for the prefix /debug). The handlers are
+// registered as regular GET and POST groups so they run behind the passed in
+// access plugins instead of being wired into http.DefaultServeMux. If no access
+// plugins are given, only loopback clients are allowed.
+//
+// Example usage:
+//
+//	v.EnableProfiling("/debug", verto.AllowIPs("10.0.0.0/8"), authz.New(...))
+func (v *Verto) EnableProfiling(prefix string, access ...Plugin) {
+	prefix = strings.TrimRight(prefix, "/")
+	if len(access) == 0 {
+		access = []Plugin{AllowIPs("127.0.0.0/8", "::1")}
+	}
+
+	profiles := func(w http.ResponseWriter, r *http.Request) {
+		switch name := strings.TrimPrefix(r.URL.Path, prefix+"/pprof/"); name {
+		case "":
+			// pprof.Index renders relative links so the
+			// index must be served with a trailing slash
+			pprof.Index(w, r)
+		case "cmdline":
+			pprof.Cmdline(w, r)
+		case "profile":
+			pprof.Profile(w, r)
+		case "symbol":
+			pprof.Symbol(w, r)
+		case "trace":
+			pprof.Trace(w, r)
+		default:
+			pprof.Handler(name).ServeHTTP(w, r)
+		}
+	}
+
+	get := v.Group("GET", prefix)
+	post := v.Group("POST", prefix)
+	for _, plugin := range access {
+		get.Use(plugin)
+		post.Use(plugin)
+	}
+	get.AddHandler("/pprof/", http.HandlerFunc(profiles))
+	get.AddHandler("/pprof/^", http.HandlerFunc(profiles))
+	get.AddHandler("/vars", expvar.Handler())
+	post.AddHandler("/pprof/symbol", http.HandlerFunc(pprof.Symbol))
+}
+
+// AllowIPs returns a Plugin that only lets through requests whose remote
+// address lies within one of the passed in networks. Networks may be CIDR
+// ranges or single IP addresses. Other requests receive a 403 response.
+// The remote address is taken from the connection rather than the
+// X-Forwarded-For header, which can be set by clients. AllowIPs panics
+// if a network cannot be parsed
+func AllowIPs(networks ...string) Plugin {
+	nets := make([]*net.IPNet, len(networks))
+	for i, n := range networks {
+		if !strings.Contains(n, "/") {
+			if ip := net.ParseIP(n); ip != nil && ip.To4() != nil {
+				n += "/32"
+			} else {
+				n += "/128"
+			}
+		}
+		_, ipnet, err := net.ParseCIDR(n)
+		if err != nil {
+			panic("AllowIPs: " + err.Error())
+		}
+		nets[i] = ipnet
+	}
+
+	return PluginFunc(func(c *Context, next http.HandlerFunc) {
+		host, _, err := net.SplitHostPort(c.Request.RemoteAddr)
+		if err != nil {
+			host = c.Request.RemoteAddr
+		}
+		if ip := net.ParseIP(host); ip != nil {
+			for _, ipnet := range nets {
+				if ipnet.Contains(ip) {
+					next(c.Response, c.Request)
+					return
+				}
+			}
+		}
+		c.Response.WriteHeader(http.StatusForbidden)
+		c.Response.Write([]byte("Forbidden."))
+	})
+}
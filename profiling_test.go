@@ -0,0 +1,94 @@
+package verto
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestEnableProfiling(t *testing.T) {
+	defer func() {
+		err := recover()
+		if err != nil {
+			t.Errorf(err.(error).Error())
+		}
+	}()
+
+	err := "Failed enable profiling."
+
+	v := New()
+	v.Logger = &NilLogger{}
+	v.EnableProfiling("/debug")
+	h := &HttpHandler{v}
+
+	serve := func(method, path, addr string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest(method, "http://test.com"+path, strings.NewReader(""))
+		r.RemoteAddr = addr
+		h.ServeHTTP(w, r)
+		return w
+	}
+
+	w := serve("GET", "/debug/pprof/", "127.0.0.1:5000")
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), "goroutine") {
+		t.Errorf(err)
+	}
+	w = serve("GET", "/debug/pprof/goroutine?debug=1", "127.0.0.1:5000")
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), "goroutine profile") {
+		t.Errorf(err)
+	}
+	w = serve("GET", "/debug/vars", "[::1]:5000")
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), "memstats") {
+		t.Errorf(err)
+	}
+	w = serve("POST", "/debug/pprof/symbol", "127.0.0.1:5000")
+	if w.Code != http.StatusOK {
+		t.Errorf(err)
+	}
+
+	// Test access control
+	w = serve("GET", "/debug/pprof/", "10.1.2.3:5000")
+	if w.Code != http.StatusForbidden {
+		t.Errorf(err)
+	}
+	w = serve("GET", "/debug/vars", "10.1.2.3:5000")
+	if w.Code != http.StatusForbidden {
+		t.Errorf(err)
+	}
+}
+
+func TestAllowIPs(t *testing.T) {
+	defer func() {
+		err := recover()
+		if err != nil {
+			t.Errorf(err.(error).Error())
+		}
+	}()
+
+	err := "Failed allow ips."
+
+	v := New()
+	v.Logger = &NilLogger{}
+	v.Get("/", func(c *Context) (interface{}, error) { return "ok", nil }).
+		Use(AllowIPs("10.0.0.0/8", "192.168.1.7"))
+	h := &HttpHandler{v}
+
+	cases := map[string]int{
+		"10.20.30.40:1":  http.StatusOK,
+		"192.168.1.7:1":  http.StatusOK,
+		"192.168.1.8:1":  http.StatusForbidden,
+		"127.0.0.1:1":    http.StatusForbidden,
+		"not an address": http.StatusForbidden,
+	}
+	for addr, code := range cases {
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest("GET", "http://test.com/", nil)
+		r.RemoteAddr = addr
+		r.Header.Set("X-Forwarded-For", "10.0.0.1")
+		h.ServeHTTP(w, r)
+		if w.Code != code {
+			t.Errorf(err)
+		}
+	}
+}
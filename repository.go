@@ -0,0 +1,297 @@
+package verto
+
+import (
+	"encoding/json"
+	"github.com/boxtown/verto/mux"
+	"mime"
+	"net/http"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// Request parameters recognized by RepositoryController. Filters
+// are given as filter[field]=value and sorting as a comma separated
+// list of fields, descending if prefixed with '-' (sort=-created_at,name)
+const (
+	SortParam   = "sort"
+	FilterParam = "filter"
+)
+
+// ErrRecordNotFound is returned by Repositories for missing records
+var ErrRecordNotFound = &HTTPError{Status: http.StatusNotFound}
+
+// Query selects the records listed by a Repository
+type Query struct {
+	// Parents are the ids of the parent resources of nested
+	// resources keyed by parameter name (e.g. userID)
+	Parents map[string]string
+
+	// Filters are the field values records must have
+	Filters map[string]string
+
+	// Sort are the fields to sort by in order. Fields
+	// prefixed with '-' are sorted in descending order
+	Sort []string
+
+	// Offset and Limit select the page of records
+	Offset int
+	Limit  int
+
+	// IncludeDeleted selects soft-deleted records as well
+	IncludeDeleted bool
+}
+
+// Repository is a storage adapter for the records of a resource.
+// Repositories back the generic Controller returned by
+// RepositoryController
+type Repository interface {
+	// New returns a pointer to a new, empty record
+	New() interface{}
+
+	// List returns the records selected by q as a slice
+	// and the total number of records matching q's filters
+	List(q Query) (interface{}, int64, error)
+
+	// Get returns the record with id or ErrRecordNotFound
+	Get(id string, q Query) (interface{}, error)
+
+	// Create stores a new record and returns it
+	Create(record interface{}, q Query) (interface{}, error)
+
+	// Update stores a modified record and returns it
+	Update(id string, record interface{}, q Query) (interface{}, error)
+
+	// Delete removes the record with id
+	Delete(id string, q Query) error
+}
+
+// Timestamps are audit fields maintained by RepositoryController
+// for records that embed them
+type Timestamps struct {
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// Created sets the creation and update time of the record to now
+func (t *Timestamps) Created(now time.Time) {
+	t.CreatedAt = now
+	t.UpdatedAt = now
+}
+
+// Updated sets the update time of the record to now
+func (t *Timestamps) Updated(now time.Time) {
+	t.UpdatedAt = now
+}
+
+// SoftDelete marks records that embed it as deleted instead of
+// removing them. Soft-deleted records are hidden by RepositoryController
+type SoftDelete struct {
+	DeletedAt *time.Time `json:"deleted_at,omitempty" db:"deleted_at"`
+}
+
+// Deleted returns whether the record is soft-deleted
+func (s *SoftDelete) Deleted() bool {
+	return s.DeletedAt != nil
+}
+
+// MarkDeleted soft-deletes the record at now
+func (s *SoftDelete) MarkDeleted(now time.Time) {
+	s.DeletedAt = &now
+}
+
+// timestamped is implemented by records embedding Timestamps
+type timestamped interface {
+	Created(now time.Time)
+	Updated(now time.Time)
+}
+
+// softDeletable is implemented by records embedding SoftDelete
+type softDeletable interface {
+	Deleted() bool
+	MarkDeleted(now time.Time)
+}
+
+// repositoryController is a Controller backed by a Repository
+type repositoryController struct {
+	repo         Repository
+	defaultLimit int
+	maxLimit     int
+	now          func() time.Time
+}
+
+// RepositoryController returns a Controller serving the records of
+// repo. Records are decoded from and rendered as JSON. Index responses
+// are PagedResponses of at most maxLimit records (defaultLimit if the
+// request gives no limit) filtered and sorted by the filter and sort
+// parameters. Records embedding Timestamps have their audit fields
+// maintained and records embedding SoftDelete are soft-deleted and
+// hidden from all actions. Updates with a JSON Patch or JSON Merge
+// Patch content type are applied as patches.
+//
+// Example usage:
+//
+//	type User struct {
+//		Id   string `json:"id"`
+//		Name string `json:"name"`
+//		verto.Timestamps
+//		verto.SoftDelete
+//	}
+//
+//	v.Resource("/users", verto.RepositoryController(users, 20, 100))
+func RepositoryController(repo Repository, defaultLimit, maxLimit int) Controller {
+	return &repositoryController{
+		repo:         repo,
+		defaultLimit: defaultLimit,
+		maxLimit:     maxLimit,
+		now:          time.Now,
+	}
+}
+
+func (rc *repositoryController) Index(c *Context) (interface{}, error) {
+	p := c.Page(rc.defaultLimit, rc.maxLimit)
+	q := RequestQuery(c)
+	q.Offset = p.Offset
+	q.Limit = p.Limit
+
+	records, total, err := rc.repo.List(q)
+	if err != nil {
+		return nil, err
+	}
+	return NewPagedResponse(records, total, p), nil
+}
+
+func (rc *repositoryController) Show(c *Context) (interface{}, error) {
+	return rc.get(c)
+}
+
+func (rc *repositoryController) Create(c *Context) (interface{}, error) {
+	record := rc.repo.New()
+	if err := decodeRecord(c, record); err != nil {
+		return nil, err
+	}
+	if t, ok := record.(timestamped); ok {
+		t.Created(rc.now())
+	}
+	return rc.repo.Create(record, RequestQuery(c))
+}
+
+func (rc *repositoryController) Update(c *Context) (interface{}, error) {
+	record, err := rc.get(c)
+	if err != nil {
+		return nil, err
+	}
+
+	// Creation times can't be changed by clients
+	var created time.Time
+	if field := timestampsOf(record); field != nil {
+		created = field.CreatedAt
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(c.Request.Header.Get("Content-Type"))
+	if mediaType == JSONPatchType || mediaType == MergePatchType {
+		err = c.Patch(record)
+	} else {
+		err = decodeRecord(c, record)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if field := timestampsOf(record); field != nil {
+		field.CreatedAt = created
+		field.Updated(rc.now())
+	}
+	return rc.repo.Update(c.Param(IDParam), record, RequestQuery(c))
+}
+
+func (rc *repositoryController) Delete(c *Context) (interface{}, error) {
+	record, err := rc.get(c)
+	if err != nil {
+		return nil, err
+	}
+	q := RequestQuery(c)
+	if s, ok := record.(softDeletable); ok {
+		s.MarkDeleted(rc.now())
+		_, err = rc.repo.Update(c.Param(IDParam), record, q)
+		return nil, err
+	}
+	return nil, rc.repo.Delete(c.Param(IDParam), q)
+}
+
+// get returns the record with the id of the request
+// hiding soft-deleted records
+func (rc *repositoryController) get(c *Context) (interface{}, error) {
+	record, err := rc.repo.Get(c.Param(IDParam), RequestQuery(c))
+	if err != nil {
+		return nil, err
+	}
+	if s, ok := record.(softDeletable); ok && s.Deleted() {
+		return nil, ErrRecordNotFound
+	}
+	return record, nil
+}
+
+// RequestQuery returns the Query of the request with the parent ids,
+// filters and sorting of the request. Pagination is left to the caller
+func RequestQuery(c *Context) Query {
+	q := Query{
+		Parents: make(map[string]string),
+		Filters: make(map[string]string),
+	}
+	if c.Request == nil {
+		return q
+	}
+
+	query := c.Request.URL.Query()
+	for name, values := range query {
+		if strings.HasPrefix(name, FilterParam+"[") && strings.HasSuffix(name, "]") && len(values) > 0 {
+			q.Filters[name[len(FilterParam)+1:len(name)-1]] = values[0]
+		}
+	}
+	for _, s := range strings.Split(query.Get(SortParam), ",") {
+		if s = strings.TrimSpace(s); s != "" && s != "-" {
+			q.Sort = append(q.Sort, s)
+		}
+	}
+	if route := mux.CurrentRoute(c.Request); route != nil {
+		for _, s := range strings.Split(route.Path(), "/") {
+			if strings.HasPrefix(s, "{") && strings.HasSuffix(s, "}") {
+				name := strings.TrimSpace(strings.SplitN(s[1:len(s)-1], ":", 2)[0])
+				if name != IDParam {
+					q.Parents[name] = c.Get(name)
+				}
+			}
+		}
+	}
+	return q
+}
+
+// decodeRecord decodes the JSON request body onto record
+func decodeRecord(c *Context, record interface{}) error {
+	dec := json.NewDecoder(c.Request.Body)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(record); err != nil {
+		return &HTTPError{Status: http.StatusUnprocessableEntity, Message: err.Error(), Err: err}
+	}
+	return nil
+}
+
+// timestampsOf returns the embedded Timestamps of record or nil
+func timestampsOf(record interface{}) *Timestamps {
+	v := reflect.ValueOf(record)
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return nil
+	}
+	f := v.FieldByName("Timestamps")
+	if !f.IsValid() || f.Type() != reflect.TypeOf(Timestamps{}) || !f.CanAddr() {
+		return nil
+	}
+	return f.Addr().Interface().(*Timestamps)
+}
@@ -0,0 +1,159 @@
+package verto
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+)
+
+type repoUser struct {
+	Id    string `json:"id"`
+	Name  string `json:"name"`
+	Group string `json:"group"`
+	Timestamps
+	SoftDelete
+}
+
+// testRepository is an in-memory Repository
+type testRepository struct {
+	users   map[string]*repoUser
+	queries []Query
+}
+
+func (repo *testRepository) New() interface{} {
+	return &repoUser{}
+}
+
+func (repo *testRepository) List(q Query) (interface{}, int64, error) {
+	repo.queries = append(repo.queries, q)
+	users := make([]*repoUser, 0)
+	for _, u := range repo.users {
+		if (q.IncludeDeleted || !u.Deleted()) && (q.Filters["group"] == "" || q.Filters["group"] == u.Group) {
+			users = append(users, u)
+		}
+	}
+	sort.Slice(users, func(i, j int) bool { return users[i].Id < users[j].Id })
+	total := int64(len(users))
+	if q.Offset+q.Limit < len(users) {
+		users = users[q.Offset : q.Offset+q.Limit]
+	}
+	return users, total, nil
+}
+
+func (repo *testRepository) Get(id string, q Query) (interface{}, error) {
+	u, ok := repo.users[id]
+	if !ok {
+		return nil, ErrRecordNotFound
+	}
+	copied := *u
+	return &copied, nil
+}
+
+func (repo *testRepository) Create(record interface{}, q Query) (interface{}, error) {
+	u := record.(*repoUser)
+	repo.users[u.Id] = u
+	return u, nil
+}
+
+func (repo *testRepository) Update(id string, record interface{}, q Query) (interface{}, error) {
+	repo.users[id] = record.(*repoUser)
+	return record, nil
+}
+
+func (repo *testRepository) Delete(id string, q Query) error {
+	delete(repo.users, id)
+	return nil
+}
+
+func TestRepositoryController(t *testing.T) {
+	defer func() {
+		err := recover()
+		if err != nil {
+			t.Errorf(err.(error).Error())
+		}
+	}()
+
+	err := "Failed repository controller."
+
+	now := time.Date(2017, time.January, 1, 0, 0, 0, 0, time.UTC)
+	repo := &testRepository{users: make(map[string]*repoUser)}
+	controller := RepositoryController(repo, 10, 100).(*repositoryController)
+	controller.now = func() time.Time { return now }
+
+	v := New()
+	v.Logger = &NilLogger{}
+	v.ResponseHandler = ResponseFunc(JSONResponseFunc)
+	v.Resource("/groups", controller).Nest("/users", controller)
+	h := &HttpHandler{v}
+
+	serve := func(method, path, contentType, body string) *httptest.ResponseRecorder {
+		r, _ := http.NewRequest(method, "http://test.com"+path, strings.NewReader(body))
+		r.Header.Set("Content-Type", contentType)
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		return w
+	}
+	decode := func(w *httptest.ResponseRecorder) *repoUser {
+		res := struct{ Data *repoUser }{}
+		json.Unmarshal(w.Body.Bytes(), &res)
+		return res.Data
+	}
+
+	// Test create maintains timestamps
+	w := serve("POST", "/groups/g/users", "application/json", `{"id": "1", "name": "a", "group": "x"}`)
+	if u := decode(w); w.Code != 201 || u == nil || !u.CreatedAt.Equal(now) || !u.UpdatedAt.Equal(now) {
+		t.Errorf(err)
+	}
+	serve("POST", "/groups/g/users", "application/json", `{"id": "2", "name": "b", "group": "y"}`)
+	if serve("POST", "/groups/g/users", "application/json", `{"id": "3", "bogus": 1}`).Code != 422 {
+		t.Errorf(err)
+	}
+
+	// Test update keeps the creation time
+	now = now.Add(time.Hour)
+	w = serve("PUT", "/groups/g/users/1", "application/json", `{"name": "c", "created_at": "2020-01-01T00:00:00Z"}`)
+	if u := decode(w); w.Code != 200 || u.Name != "c" || !u.CreatedAt.Equal(now.Add(-time.Hour)) || !u.UpdatedAt.Equal(now) {
+		t.Errorf(err)
+	}
+	w = serve("PATCH", "/groups/g/users/1", MergePatchType, `{"name": "d"}`)
+	if u := decode(w); w.Code != 200 || u.Name != "d" || u.Group != "x" {
+		t.Errorf(err)
+	}
+
+	// Test listing with filters, sorting and parents
+	w = serve("GET", "/groups/g/users?filter[group]=y&sort=-name,id&limit=5", "", "")
+	page := struct {
+		Items []*repoUser
+		Total int64
+	}{}
+	json.Unmarshal(w.Body.Bytes(), &page)
+	if w.Code != 200 || page.Total != 1 || page.Items[0].Id != "2" {
+		t.Errorf(err)
+	}
+	q := repo.queries[len(repo.queries)-1]
+	if q.Parents["groupID"] != "g" || len(q.Sort) != 2 || q.Sort[0] != "-name" || q.Limit != 5 || q.IncludeDeleted {
+		t.Errorf(err)
+	}
+
+	// Test soft delete hides records
+	if serve("DELETE", "/groups/g/users/1", "", "").Code != 204 {
+		t.Errorf(err)
+	}
+	if repo.users["1"] == nil || !repo.users["1"].Deleted() {
+		t.Errorf(err)
+	}
+	if serve("GET", "/groups/g/users/1", "", "").Code != 404 {
+		t.Errorf(err)
+	}
+	if serve("DELETE", "/groups/g/users/1", "", "").Code != 404 {
+		t.Errorf(err)
+	}
+	json.Unmarshal(serve("GET", "/groups/g/users", "", "").Body.Bytes(), &page)
+	if page.Total != 1 {
+		t.Errorf(err)
+	}
+}
@@ -0,0 +1,220 @@
+package verto
+
+import (
+	"net/http"
+	"strings"
+)
+
+// IDParam is the path parameter holding the id
+// of the resource on scaffolded member routes
+const IDParam = "id"
+
+// NoContent is a response that resource functions can return
+// to send an empty 204 No Content response
+var NoContent = &noContent{}
+
+// noContent is the type of NoContent
+type noContent struct{}
+
+// Controller handles the conventional REST actions of a resource
+// registered with Verto.Resource. The id of the resource acted upon
+// by Show, Update and Delete is available as c.Param(verto.IDParam)
+type Controller interface {
+	// Index lists the resources of the collection. Returning a
+	// PagedResponse built with Context.Page paginates the collection
+	Index(c *Context) (interface{}, error)
+
+	// Show returns a single resource
+	Show(c *Context) (interface{}, error)
+
+	// Create creates a resource from the request
+	Create(c *Context) (interface{}, error)
+
+	// Update updates a resource from the request
+	Update(c *Context) (interface{}, error)
+
+	// Delete deletes a resource. Returning a nil
+	// response sends a 204 No Content response
+	Delete(c *Context) (interface{}, error)
+}
+
+// Identifier is implemented by resource representations
+// that expose their id. Created Identifiers are answered
+// with a Location header pointing to the new resource
+type Identifier interface {
+	ResourceID() string
+}
+
+// ResourceRoutes are the routes registered for a resource
+type ResourceRoutes struct {
+	// Name is the route name prefix of the resource (e.g. users)
+	Name string
+
+	// Path is the path of the resource collection
+	Path string
+
+	// Index, Show, Create, Update, Patch and Delete are the
+	// Endpoints of the conventional REST actions
+	Index, Show, Create, Update, Patch, Delete *Endpoint
+
+	v *Verto
+}
+
+// Resource registers the conventional REST routes for the resource
+// collection at path handled by controller:
+//
+//	GET    /users       Index   users.index
+//	POST   /users       Create  users.create
+//	GET    /users/{id}  Show    users.show
+//	PUT    /users/{id}  Update  users.update
+//	PATCH  /users/{id}  Update  users.patch
+//	DELETE /users/{id}  Delete  users.delete
+//
+// Responses of Show, Create and Update are wrapped in a Resource envelope
+// linking to the resource itself and its collection unless they already
+// are Resources. Created resources are answered with a 201 response and
+// nil Delete responses with a 204 response.
+//
+// Example usage:
+//
+//	v.ResponseHandler = verto.Paginated(verto.ResponseFunc(verto.JSONResponseFunc))
+//	v.Resource("/users", &UserController{db}).Use(auth)
+func (v *Verto) Resource(path string, controller Controller) *ResourceRoutes {
+	path = "/" + strings.Trim(path, "/")
+	return v.resourceRoutes(resourceName(path), path, controller)
+}
+
+// resourceRoutes registers the routes of a resource with
+// route names prefixed by name and collection path path
+func (v *Verto) resourceRoutes(name, path string, controller Controller) *ResourceRoutes {
+	rr := &ResourceRoutes{Name: name, Path: path, v: v}
+	member := path + "/{" + IDParam + "}"
+
+	rr.Index = v.Get(path, controller.Index).Name(name + ".index")
+	rr.Create = v.Post(path, rr.envelope(controller.Create, true)).Name(name + ".create")
+	rr.Show = v.Get(member, rr.envelope(controller.Show, false)).Name(name + ".show")
+	rr.Update = v.Put(member, rr.envelope(controller.Update, false)).Name(name + ".update")
+	rr.Patch = v.Patch(member, rr.envelope(controller.Update, false)).Name(name + ".patch")
+	rr.Delete = v.Delete(member, func(c *Context) (interface{}, error) {
+		response, err := controller.Delete(c)
+		if err == nil && response == nil {
+			return NoContent, nil
+		}
+		return response, err
+	}).Name(name + ".delete")
+	return rr
+}
+
+// Nest registers the conventional REST routes for the child resource
+// collection at path under each resource of rr. The id of the parent
+// resource is bound to a path parameter named after the singular parent
+// (userID for users) that the child controller reads with c.Get. Child
+// route names are prefixed with the parent's name.
+//
+// Example usage:
+//
+//	v.Resource("/users", users).Nest("/posts", posts)
+//
+//	GET /users/{userID}/posts/{id}  users.posts.show
+func (rr *ResourceRoutes) Nest(path string, controller Controller) *ResourceRoutes {
+	path = "/" + strings.Trim(path, "/")
+	param := ParentParam(rr.Name)
+	return rr.v.resourceRoutes(
+		rr.Name+"."+resourceName(path),
+		rr.Path+"/{"+param+"}"+path,
+		controller,
+	)
+}
+
+// ParentParam returns the name of the path parameter binding
+// the id of the parent resource for nested resources of the
+// resource named name (users -> userID, categories -> categoryID)
+func ParentParam(name string) string {
+	if i := strings.LastIndex(name, "."); i >= 0 {
+		name = name[i+1:]
+	}
+	switch {
+	case strings.HasSuffix(name, "ies") && len(name) > 3:
+		name = name[:len(name)-3] + "y"
+	case strings.HasSuffix(name, "ses") || strings.HasSuffix(name, "xes"):
+		name = name[:len(name)-2]
+	case strings.HasSuffix(name, "s") && !strings.HasSuffix(name, "ss"):
+		name = name[:len(name)-1]
+	}
+	return name + "ID"
+}
+
+// Endpoints returns the Endpoints of all actions of the resource
+func (rr *ResourceRoutes) Endpoints() []*Endpoint {
+	return []*Endpoint{rr.Index, rr.Create, rr.Show, rr.Update, rr.Patch, rr.Delete}
+}
+
+// Use adds plugin to the Endpoints of all actions of the resource
+func (rr *ResourceRoutes) Use(plugin Plugin) *ResourceRoutes {
+	for _, ep := range rr.Endpoints() {
+		ep.Use(plugin)
+	}
+	return rr
+}
+
+// envelope wraps the response of rf in a Resource envelope linking to
+// the resource and its collection. Created resources are answered with
+// a 201 status and a Location header if their id is known
+func (rr *ResourceRoutes) envelope(rf ResourceFunc, created bool) ResourceFunc {
+	return func(c *Context) (interface{}, error) {
+		response, err := rf(c)
+		if err != nil || response == nil {
+			return response, err
+		}
+		if _, ok := response.(*Resource); ok {
+			return response, nil
+		}
+
+		params := rr.params(c)
+		id := c.Param(IDParam)
+		if identifier, ok := response.(Identifier); ok {
+			id = identifier.ResourceID()
+		}
+
+		res := NewResource(response)
+		if id != "" {
+			self := append(params, IDParam, id)
+			res.Rel("self", rr.Name+".show", self...)
+			if created {
+				if location, err := rr.v.URL(rr.Name+".show", self...); err == nil {
+					c.Response.Header().Set("Location", location)
+				}
+			}
+		}
+		res.Rel("collection", rr.Name+".index", params...)
+		if created {
+			c.Response.WriteHeader(http.StatusCreated)
+		}
+		return res, nil
+	}
+}
+
+// params returns the parent resource parameters of the request
+// as name value pairs for building URLs
+func (rr *ResourceRoutes) params(c *Context) []string {
+	params := make([]string, 0)
+	for _, s := range strings.Split(rr.Path, "/") {
+		if strings.HasPrefix(s, "{") && strings.HasSuffix(s, "}") {
+			name := s[1 : len(s)-1]
+			params = append(params, name, c.Param(name))
+		}
+	}
+	return params
+}
+
+// resourceName returns the route name prefix for a collection
+// path, which is its last literal segment (/api/users -> users)
+func resourceName(path string) string {
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	for i := len(segments) - 1; i >= 0; i-- {
+		if s := segments[i]; s != "" && !strings.HasPrefix(s, "{") {
+			return s
+		}
+	}
+	return "resource"
+}
@@ -0,0 +1,170 @@
+package verto
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type testItem struct {
+	Id   string `json:"id"`
+	Name string `json:"name"`
+}
+
+func (item *testItem) ResourceID() string {
+	return item.Id
+}
+
+type testController struct {
+	items map[string]*testItem
+}
+
+func (tc *testController) Index(c *Context) (interface{}, error) {
+	p := c.Page(10, 10)
+	items := make([]*testItem, 0)
+	for _, id := range []string{"1", "2"} {
+		if item, ok := tc.items[id]; ok {
+			items = append(items, item)
+		}
+	}
+	total := int64(len(items))
+	if p.Offset+p.Limit < len(items) {
+		items = items[p.Offset : p.Offset+p.Limit]
+	}
+	return NewPagedResponse(items, total, p), nil
+}
+
+func (tc *testController) Show(c *Context) (interface{}, error) {
+	item, ok := tc.items[c.Get(IDParam)]
+	if !ok {
+		return nil, &HTTPError{Status: http.StatusNotFound}
+	}
+	return item, nil
+}
+
+func (tc *testController) Create(c *Context) (interface{}, error) {
+	item := &testItem{Id: "2", Name: c.Get("name")}
+	tc.items[item.Id] = item
+	return item, nil
+}
+
+func (tc *testController) Update(c *Context) (interface{}, error) {
+	item, ok := tc.items[c.Get(IDParam)]
+	if !ok {
+		return nil, errors.New("missing")
+	}
+	item.Name = c.Get("name")
+	return item, nil
+}
+
+func (tc *testController) Delete(c *Context) (interface{}, error) {
+	delete(tc.items, c.Get(IDParam))
+	return nil, nil
+}
+
+func TestResourceRoutes(t *testing.T) {
+	defer func() {
+		err := recover()
+		if err != nil {
+			t.Errorf(err.(error).Error())
+		}
+	}()
+
+	err := "Failed resource routes."
+
+	v := New()
+	v.Logger = &NilLogger{}
+	v.ResponseHandler = Paginated(ResponseFunc(JSONResponseFunc))
+	rr := v.Resource("/users", &testController{map[string]*testItem{"1": {"1", "a"}}})
+	if rr.Name != "users" || len(rr.Endpoints()) != 6 {
+		t.Errorf(err)
+	}
+	if u, _ := v.URL("users.show", "id", "1"); u != "/users/1" {
+		t.Errorf(err)
+	}
+	h := &HttpHandler{v}
+
+	serve := func(method, path, body string) *httptest.ResponseRecorder {
+		r, _ := http.NewRequest(method, "http://test.com"+path, strings.NewReader(body))
+		r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		return w
+	}
+
+	w := serve("GET", "/users/1", "")
+	if w.Code != 200 || w.Body.String() != `{"data":{"id":"1","name":"a"},"links":[{"rel":"self","href":"http://test.com/users/1"},{"rel":"collection","href":"http://test.com/users"}]}` {
+		t.Errorf(err)
+	}
+	if serve("GET", "/users/5", "").Code != 404 {
+		t.Errorf(err)
+	}
+
+	w = serve("POST", "/users", "name=b")
+	if w.Code != 201 || w.Header().Get("Location") != "/users/2" || !strings.Contains(w.Body.String(), `"name":"b"`) {
+		t.Errorf(err)
+	}
+
+	w = serve("GET", "/users?limit=1", "")
+	if w.Code != 200 || w.Header().Get("X-Total-Count") != "2" || !strings.Contains(w.Body.String(), `"items":[{"id":"1","name":"a"}]`) {
+		t.Errorf(err)
+	}
+
+	w = serve("PATCH", "/users/2", "name=c")
+	if w.Code != 200 || !strings.Contains(w.Body.String(), `"name":"c"`) {
+		t.Errorf(err)
+	}
+
+	w = serve("DELETE", "/users/2", "")
+	if w.Code != 204 || w.Body.Len() != 0 {
+		t.Errorf(err)
+	}
+}
+
+type testPostController struct {
+	testController
+}
+
+func (tc *testPostController) Show(c *Context) (interface{}, error) {
+	return &testItem{Id: c.Get(IDParam), Name: "post of " + c.Get("userID")}, nil
+}
+
+func TestResourceNest(t *testing.T) {
+	defer func() {
+		err := recover()
+		if err != nil {
+			t.Errorf(err.(error).Error())
+		}
+	}()
+
+	err := "Failed resource nest."
+
+	v := New()
+	v.Logger = &NilLogger{}
+	v.ResponseHandler = ResponseFunc(JSONResponseFunc)
+	posts := v.Resource("/users", &testController{map[string]*testItem{}}).
+		Nest("/posts", &testPostController{testController{map[string]*testItem{}}})
+	if posts.Name != "users.posts" || posts.Path != "/users/{userID}/posts" {
+		t.Errorf(err)
+	}
+	h := &HttpHandler{v}
+
+	r, _ := http.NewRequest("GET", "http://test.com/users/7/posts/3", nil)
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	if w.Body.String() != `{"data":{"id":"3","name":"post of 7"},"links":[{"rel":"self","href":"http://test.com/users/7/posts/3"},{"rel":"collection","href":"http://test.com/users/7/posts"}]}` {
+		t.Errorf(err)
+	}
+
+	expected := map[string]string{
+		"users": "userID", "categories": "categoryID", "addresses": "addressID",
+		"boxes": "boxID", "users.posts": "postID", "staff": "staffID",
+	}
+	for name, param := range expected {
+		if ParentParam(name) != param {
+			t.Errorf(err)
+		}
+	}
+}
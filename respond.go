@@ -0,0 +1,58 @@
+package verto
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+// JSON writes v as a JSON response with status code and Content-Type
+// application/json. The JSON is indented if pretty JSON is enabled.
+// Nothing is written if v cannot be marshalled and the marshalling
+// error is returned. JSON is meant for plugins and handlers writing
+// responses directly; ResourceFuncs return their responses instead.
+//
+// Example usage:
+//
+//	v.Use(verto.PluginFunc(func(c *verto.Context, next http.HandlerFunc) {
+//		if !allowed(c) {
+//			c.JSON(403, map[string]string{"error": "forbidden"})
+//			return
+//		}
+//		next(c.Response, c.Request)
+//	}))
+func (c *Context) JSON(code int, v interface{}) error {
+	var b []byte
+	var err error
+	if c.v != nil && c.v.prettyJSON {
+		b, err = json.MarshalIndent(v, "", "  ")
+	} else {
+		b, err = json.Marshal(v)
+	}
+	if err != nil {
+		return err
+	}
+	c.Response.Header().Set("Content-Type", "application/json; charset=utf-8")
+	c.Response.WriteHeader(code)
+	_, err = c.Response.Write(b)
+	return err
+}
+
+// String writes a plain text response with status code formatted
+// according to format and args
+func (c *Context) String(code int, format string, args ...interface{}) error {
+	c.Response.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	c.Response.WriteHeader(code)
+	_, err := fmt.Fprintf(c.Response, format, args...)
+	return err
+}
+
+// Status writes a response with status code and no body
+func (c *Context) Status(code int) {
+	c.Response.WriteHeader(code)
+}
+
+// NoContent writes a 204 No Content response
+func (c *Context) NoContent() {
+	c.Response.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,64 @@
+package verto
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestResponseHelpers(t *testing.T) {
+	defer func() {
+		err := recover()
+		if err != nil {
+			t.Errorf(err.(error).Error())
+		}
+	}()
+
+	err := "Failed response helpers."
+
+	r, _ := http.NewRequest("GET", "http://test.com", nil)
+
+	w := httptest.NewRecorder()
+	c := NewContext(w, r, nil, nil)
+	if c.JSON(201, map[string]int{"id": 1}) != nil || w.Code != 201 || w.Body.String() != `{"id":1}` ||
+		w.Header().Get("Content-Type") != "application/json; charset=utf-8" {
+		t.Errorf(err)
+	}
+
+	// Test unmarshallable values write nothing
+	w = httptest.NewRecorder()
+	c = NewContext(w, r, nil, nil)
+	if c.JSON(200, make(chan int)) == nil || w.Body.Len() != 0 || w.Header().Get("Content-Type") != "" {
+		t.Errorf(err)
+	}
+
+	w = httptest.NewRecorder()
+	c = NewContext(w, r, nil, nil)
+	if c.String(404, "no user %d", 7) != nil || w.Code != 404 || w.Body.String() != "no user 7" ||
+		w.Header().Get("Content-Type") != "text/plain; charset=utf-8" {
+		t.Errorf(err)
+	}
+
+	w = httptest.NewRecorder()
+	NewContext(w, r, nil, nil).Status(202)
+	if w.Code != 202 || w.Body.Len() != 0 {
+		t.Errorf(err)
+	}
+
+	w = httptest.NewRecorder()
+	NewContext(w, r, nil, nil).NoContent()
+	if w.Code != 204 {
+		t.Errorf(err)
+	}
+
+	// Test pretty JSON
+	v := New()
+	v.Logger = &NilLogger{}
+	v.SetEnvironment(Development)
+	w = httptest.NewRecorder()
+	c = v.context(w, r)
+	c.JSON(200, map[string]int{"id": 1})
+	if w.Body.String() != "{\n  \"id\": 1\n}" {
+		t.Errorf(err)
+	}
+}
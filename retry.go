@@ -0,0 +1,111 @@
+package verto
+
+import (
+	"github.com/boxtown/verto/mux"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// RetrySafeKey is the route metadata key declaring whether
+// requests to the route are safe for clients to retry
+const RetrySafeKey = "verto.retrySafe"
+
+// RetryAfterKey is the route metadata key for the delay
+// advertised to clients in Retry-After headers
+const RetryAfterKey = "verto.retryAfter"
+
+// DefaultRetryAfter is the delay advertised in Retry-After headers
+// for retry-safe routes that do not declare their own
+var DefaultRetryAfter = time.Second
+
+// RetrySafe declares whether requests to the route represented by the
+// Endpoint are safe to retry. Routes with idempotent methods (GET, HEAD,
+// OPTIONS, PUT and DELETE) are retry-safe unless declared otherwise.
+// 429 and 503 responses from retry-safe routes carry a Retry-After header
+// and generated clients document retry-safe operations as such.
+//
+// Example usage:
+//
+//	v.Post("/payments/{id}/capture", capture).RetrySafe(true).RetryAfter(5 * time.Second)
+//	v.Get("/counter/next", next).RetrySafe(false)
+func (ep *Endpoint) RetrySafe(safe bool) *Endpoint {
+	return ep.Meta(RetrySafeKey, safe)
+}
+
+// RetryAfter sets the delay advertised in Retry-After headers
+// of 429 and 503 responses from the route represented by the Endpoint
+func (ep *Endpoint) RetryAfter(d time.Duration) *Endpoint {
+	return ep.Meta(RetryAfterKey, d)
+}
+
+// RetrySafe returns whether requests to route are safe to retry,
+// either as declared with Endpoint.RetrySafe or by the route's method
+func RetrySafe(route mux.Route) bool {
+	if route == nil {
+		return false
+	}
+	if safe, ok := route.Meta(RetrySafeKey); ok {
+		if b, ok := safe.(bool); ok {
+			return b
+		}
+	}
+	switch route.Method() {
+	case "GET", "HEAD", "OPTIONS", "PUT", "DELETE":
+		return true
+	}
+	return false
+}
+
+// RetrySafe returns whether the request is safe for the client to retry
+func (c *Context) RetrySafe() bool {
+	if c.Request == nil {
+		return false
+	}
+	return RetrySafe(mux.CurrentRoute(c.Request))
+}
+
+// retryAfter returns the Retry-After header value in
+// seconds for the route of the request handled by c
+func (c *Context) retryAfter() string {
+	d := DefaultRetryAfter
+	if after, ok := c.RouteMeta(RetryAfterKey); ok {
+		if a, ok := after.(time.Duration); ok {
+			d = a
+		}
+	}
+	s := int((d + time.Second - 1) / time.Second)
+	if s < 1 {
+		s = 1
+	}
+	return strconv.Itoa(s)
+}
+
+// retryWriter adds a Retry-After header to 429 and 503
+// responses that do not already carry one
+type retryWriter struct {
+	http.ResponseWriter
+
+	after string
+}
+
+func (w *retryWriter) WriteHeader(code int) {
+	if code == http.StatusTooManyRequests || code == http.StatusServiceUnavailable {
+		if w.Header().Get("Retry-After") == "" {
+			w.Header().Set("Retry-After", w.after)
+		}
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+// Flush implements http.Flusher
+func (w *retryWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Unwrap returns the wrapped ResponseWriter
+func (w *retryWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
@@ -0,0 +1,68 @@
+package verto
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestRetrySafe(t *testing.T) {
+	defer func() {
+		err := recover()
+		if err != nil {
+			t.Errorf(err.(error).Error())
+		}
+	}()
+
+	err := "Failed retry safe."
+
+	unavailable := func(c *Context) (interface{}, error) {
+		return nil, &HTTPError{Status: http.StatusServiceUnavailable}
+	}
+
+	v := New()
+	v.Logger = &NilLogger{}
+	v.Get("/get", unavailable)
+	v.Get("/slow", unavailable).RetryAfter(90 * time.Second)
+	v.Post("/post", unavailable)
+	v.Post("/safe", func(c *Context) (interface{}, error) {
+		return nil, NewError(http.StatusTooManyRequests, "Slow down.")
+	}).RetrySafe(true)
+	v.Put("/unsafe", unavailable).RetrySafe(false)
+	v.Get("/fail", func(c *Context) (interface{}, error) {
+		return nil, errors.New("fail")
+	})
+	h := &HttpHandler{v}
+
+	serve := func(method, path string) *httptest.ResponseRecorder {
+		r, _ := http.NewRequest(method, "http://test.com"+path, nil)
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		return w
+	}
+
+	w := serve("GET", "/get")
+	if w.Code != 503 || w.Header().Get("Retry-After") != "1" || w.Body.String() != "Service Unavailable." {
+		t.Errorf(err)
+	}
+	if serve("GET", "/slow").Header().Get("Retry-After") != "90" {
+		t.Errorf(err)
+	}
+	w = serve("POST", "/post")
+	if w.Code != 503 || w.Header().Get("Retry-After") != "" {
+		t.Errorf(err)
+	}
+	w = serve("POST", "/safe")
+	if w.Code != 429 || w.Header().Get("Retry-After") != "1" || w.Body.String() != "Slow down." {
+		t.Errorf(err)
+	}
+	if serve("PUT", "/unsafe").Header().Get("Retry-After") != "" {
+		t.Errorf(err)
+	}
+	w = serve("GET", "/fail")
+	if w.Code != 500 || w.Header().Get("Retry-After") != "" {
+		t.Errorf(err)
+	}
+}
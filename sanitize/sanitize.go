@@ -0,0 +1,219 @@
+// Package sanitize provides policy-based HTML sanitization for embedding
+// user-generated content in server-rendered pages. Input is tokenized and
+// the output is rebuilt from allowed elements and attributes only, with all
+// text and attribute values escaped, so markup outside the policy can never
+// pass through.
+package sanitize
+
+import (
+	"html"
+	"html/template"
+	"strings"
+	"unicode"
+)
+
+// Policy describes the elements and attributes allowed in sanitized HTML
+type Policy struct {
+	// Elements maps allowed element names to their allowed attributes
+	Elements map[string][]string
+
+	// URLAttributes lists attributes holding URLs whose
+	// scheme is checked against Schemes
+	URLAttributes []string
+
+	// Schemes lists the allowed URL schemes. Relative
+	// URLs are always allowed
+	Schemes []string
+
+	// NoFollow adds rel="nofollow noopener" to links
+	NoFollow bool
+}
+
+// StrictPolicy returns a Policy stripping all markup and keeping text
+func StrictPolicy() *Policy {
+	return &Policy{Elements: map[string][]string{}}
+}
+
+// UGCPolicy returns a Policy allowing basic text formatting and links,
+// suitable for comments and other user-generated content
+func UGCPolicy() *Policy {
+	return &Policy{
+		Elements: map[string][]string{
+			"a":          {"href", "title"},
+			"b":          nil,
+			"blockquote": nil,
+			"br":         nil,
+			"code":       nil,
+			"em":         nil,
+			"i":          nil,
+			"li":         nil,
+			"ol":         nil,
+			"p":          nil,
+			"pre":        nil,
+			"strong":     nil,
+			"u":          nil,
+			"ul":         nil,
+		},
+		URLAttributes: []string{"href", "src"},
+		Schemes:       []string{"http", "https", "mailto"},
+		NoFollow:      true,
+	}
+}
+
+// Sanitize returns s with all markup not allowed by the policy removed.
+// The contents of script, style and similar elements are dropped entirely.
+// Unclosed elements are closed and stray end tags are dropped
+func (p *Policy) Sanitize(s string) string {
+	out := &strings.Builder{}
+	open := make([]string, 0)
+
+	for len(s) > 0 {
+		i := strings.IndexByte(s, '<')
+		if i < 0 {
+			out.WriteString(escapeText(s))
+			break
+		}
+		out.WriteString(escapeText(s[:i]))
+		s = s[i:]
+
+		t, rest, ok := nextTag(s)
+		if !ok {
+			// Not a tag, the '<' is text
+			out.WriteString("&lt;")
+			s = s[1:]
+			continue
+		}
+		s = rest
+
+		switch {
+		case t.skip:
+		case rawText[t.name] && !t.end:
+			s = skipRawText(s, t.name)
+		case t.end:
+			for j := len(open) - 1; j >= 0; j-- {
+				if open[j] == t.name {
+					for k := len(open) - 1; k >= j; k-- {
+						out.WriteString("</" + open[k] + ">")
+					}
+					open = open[:j]
+					break
+				}
+			}
+		default:
+			allowed, ok := p.Elements[t.name]
+			if !ok {
+				continue
+			}
+			out.WriteString("<" + t.name)
+			for _, a := range t.attrs {
+				if !contains(allowed, a.name) || (a.name == "rel" && p.NoFollow && t.name == "a") {
+					continue
+				}
+				if contains(p.URLAttributes, a.name) && !p.safeURL(a.value) {
+					continue
+				}
+				out.WriteString(" " + a.name + `="` + html.EscapeString(a.value) + `"`)
+			}
+			if t.name == "a" && p.NoFollow {
+				out.WriteString(` rel="nofollow noopener"`)
+			}
+			out.WriteString(">")
+			if !void[t.name] {
+				open = append(open, t.name)
+			}
+		}
+	}
+	for i := len(open) - 1; i >= 0; i-- {
+		out.WriteString("</" + open[i] + ">")
+	}
+	return out.String()
+}
+
+// HTML returns the sanitized s as template.HTML so that
+// it is embedded in templates without being escaped again
+func (p *Policy) HTML(s string) template.HTML {
+	return template.HTML(p.Sanitize(s))
+}
+
+// StripTags returns the text of s with all markup removed and
+// entities decoded. The result is plain text and must be
+// escaped when embedded in HTML
+func StripTags(s string) string {
+	return html.UnescapeString(StrictPolicy().Sanitize(s))
+}
+
+// FuncMap returns template helper functions using p:
+//
+//	sanitize   sanitizes HTML with p and marks it safe
+//	stripTags  removes all markup, leaving plain text
+//	nl2br      escapes plain text and converts newlines to <br>
+func FuncMap(p *Policy) template.FuncMap {
+	return template.FuncMap{
+		"sanitize":  p.HTML,
+		"stripTags": StripTags,
+		"nl2br": func(s string) template.HTML {
+			s = strings.Replace(html.EscapeString(s), "\r\n", "\n", -1)
+			return template.HTML(strings.Replace(s, "\n", "<br>", -1))
+		},
+	}
+}
+
+// safeURL returns whether u is relative or has an allowed scheme
+func (p *Policy) safeURL(u string) bool {
+	// Browsers ignore whitespace and control characters in schemes
+	u = strings.Map(func(r rune) rune {
+		if unicode.IsSpace(r) || unicode.IsControl(r) {
+			return -1
+		}
+		return r
+	}, u)
+
+	i := strings.IndexAny(u, ":/?#")
+	if i < 0 || u[i] != ':' {
+		return true
+	}
+	scheme := strings.ToLower(u[:i])
+	for _, s := range p.Schemes {
+		if s == scheme {
+			return true
+		}
+	}
+	return false
+}
+
+// escapeText normalizes the entities in text and escapes it
+func escapeText(s string) string {
+	return html.EscapeString(html.UnescapeString(s))
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// rawText lists elements whose contents are not markup
+// and are dropped along with the element
+var rawText = map[string]bool{
+	"iframe":   true,
+	"noembed":  true,
+	"noframes": true,
+	"noscript": true,
+	"object":   true,
+	"script":   true,
+	"style":    true,
+	"template": true,
+	"textarea": true,
+	"title":    true,
+	"xmp":      true,
+}
+
+// void lists elements without contents or end tags
+var void = map[string]bool{
+	"area": true, "br": true, "col": true, "embed": true, "hr": true,
+	"img": true, "input": true, "link": true, "meta": true, "source": true,
+	"track": true, "wbr": true,
+}
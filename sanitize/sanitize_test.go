@@ -0,0 +1,71 @@
+package sanitize
+
+import (
+	"bytes"
+	"html/template"
+	"testing"
+)
+
+func TestSanitize(t *testing.T) {
+	defer func() {
+		err := recover()
+		if err != nil {
+			t.Errorf(err.(error).Error())
+		}
+	}()
+
+	err := "Failed sanitize."
+	p := UGCPolicy()
+
+	cases := map[string]string{
+		"plain & simple":                             `plain &amp; simple`,
+		"<b>bold</b> <blink>text</blink>":            `<b>bold</b> text`,
+		"<p onclick='x()'>hi":                        `<p>hi</p>`,
+		"<script>alert(1)</script>ok":                `ok`,
+		"<STYLE>p{}</style >ok":                      `ok`,
+		"<!-- comment -->ok":                         `ok`,
+		`<a href="http://a.com/?x=1&amp;y=2">a</a>`:  `<a href="http://a.com/?x=1&amp;y=2" rel="nofollow noopener">a</a>`,
+		`<a href="java&#x09;script:alert(1)">a</a>`:  `<a rel="nofollow noopener">a</a>`,
+		`<a href=" JavaScript:alert(1)" rel=x>a</a>`: `<a rel="nofollow noopener">a</a>`,
+		`<a href="/relative" title='"q"'>a</a>`:      `<a href="/relative" title="&#34;q&#34;" rel="nofollow noopener">a</a>`,
+		"<b><i>x</b>":                                `<b><i>x</i></b>`,
+		"</i>x":                                      `x`,
+		"1 < 2 and <3":                               `1 &lt; 2 and &lt;3`,
+		"<br/>x<img src=x onerror=alert(1)>":         `<br>x`,
+		`<p title="unterminated`:                     ``,
+		"<scr<script>ipt>alert(1)</script>":          `ipt&gt;alert(1)`,
+	}
+	for in, expected := range cases {
+		if out := p.Sanitize(in); out != expected {
+			t.Errorf("%s %q: got %q", err, in, out)
+		}
+	}
+
+	if StrictPolicy().Sanitize("<b>a &amp; b</b>") != "a &amp; b" {
+		t.Errorf(err)
+	}
+	if StripTags("<p>a &amp; b</p>") != "a & b" {
+		t.Errorf(err)
+	}
+}
+
+func TestFuncMap(t *testing.T) {
+	defer func() {
+		err := recover()
+		if err != nil {
+			t.Errorf(err.(error).Error())
+		}
+	}()
+
+	err := "Failed func map."
+
+	tmpl := template.Must(template.New("t").Funcs(FuncMap(UGCPolicy())).Parse(
+		`{{sanitize .}}|{{stripTags .}}|{{nl2br "a\n<b>"}}`))
+	buf := &bytes.Buffer{}
+	if e := tmpl.Execute(buf, "<em>hi</em><script>x</script>"); e != nil {
+		t.Fatalf(e.Error())
+	}
+	if buf.String() != "<em>hi</em>|hi|a<br>&lt;b&gt;" {
+		t.Errorf(err)
+	}
+}
@@ -0,0 +1,135 @@
+package sanitize
+
+import (
+	"html"
+	"strings"
+)
+
+// tag is a parsed start or end tag
+type tag struct {
+	name  string
+	end   bool
+	skip  bool
+	attrs []attr
+}
+
+// attr is a parsed attribute with a decoded value
+type attr struct {
+	name  string
+	value string
+}
+
+// nextTag parses the tag at the start of s, which begins with '<'.
+// Comments, doctypes and processing instructions are returned as
+// skipped tags. Returns false if s does not start with a tag
+func nextTag(s string) (tag, string, bool) {
+	if strings.HasPrefix(s, "<!--") {
+		end := strings.Index(s[4:], "-->")
+		if end < 0 {
+			return tag{skip: true}, "", true
+		}
+		return tag{skip: true}, s[4+end+3:], true
+	}
+	if len(s) > 1 && (s[1] == '!' || s[1] == '?') {
+		end := strings.IndexByte(s, '>')
+		if end < 0 {
+			return tag{skip: true}, "", true
+		}
+		return tag{skip: true}, s[end+1:], true
+	}
+
+	t := tag{}
+	i := 1
+	if i < len(s) && s[i] == '/' {
+		t.end = true
+		i++
+	}
+	start := i
+	for i < len(s) && isNameChar(s[i]) {
+		i++
+	}
+	if i == start || !isLetter(s[start]) {
+		return tag{}, s, false
+	}
+	t.name = strings.ToLower(s[start:i])
+
+	// Parse attributes up to the closing '>'
+	for {
+		for i < len(s) && (isSpace(s[i]) || s[i] == '/') {
+			i++
+		}
+		if i >= len(s) {
+			// Unterminated tags are dropped
+			return tag{skip: true}, "", true
+		}
+		if s[i] == '>' {
+			return t, s[i+1:], true
+		}
+
+		start = i
+		for i < len(s) && !isSpace(s[i]) && s[i] != '=' && s[i] != '>' && s[i] != '/' {
+			i++
+		}
+		a := attr{name: strings.ToLower(s[start:i])}
+		for i < len(s) && isSpace(s[i]) {
+			i++
+		}
+		if i < len(s) && s[i] == '=' {
+			i++
+			for i < len(s) && isSpace(s[i]) {
+				i++
+			}
+			if i < len(s) && (s[i] == '"' || s[i] == '\'') {
+				q := s[i]
+				end := strings.IndexByte(s[i+1:], q)
+				if end < 0 {
+					return tag{skip: true}, "", true
+				}
+				a.value = s[i+1 : i+1+end]
+				i += end + 2
+			} else {
+				start = i
+				for i < len(s) && !isSpace(s[i]) && s[i] != '>' {
+					i++
+				}
+				a.value = s[start:i]
+			}
+		}
+		if a.name != "" && !t.end {
+			a.value = html.UnescapeString(a.value)
+			t.attrs = append(t.attrs, a)
+		}
+	}
+}
+
+// skipRawText returns s after the end tag of the raw
+// text element name or the empty string if there is none
+func skipRawText(s, name string) string {
+	lower := strings.ToLower(s)
+	for offset := 0; ; {
+		i := strings.Index(lower[offset:], "</"+name)
+		if i < 0 {
+			return ""
+		}
+		i += offset + 2 + len(name)
+		if i >= len(s) || !isNameChar(s[i]) {
+			if end := strings.IndexByte(s[i:], '>'); end >= 0 {
+				return s[i+end+1:]
+			}
+			return ""
+		}
+		offset = i
+	}
+}
+
+func isLetter(c byte) bool {
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func isNameChar(c byte) bool {
+	return isLetter(c) || (c >= '0' && c <= '9') || c == '-' || c == ':'
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\f'
+}
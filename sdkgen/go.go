@@ -0,0 +1,256 @@
+package sdkgen
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/boxtown/verto/mux"
+	"go/format"
+	"go/token"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// goHeader is the fixed part of generated Go clients
+const goHeader = `// Code generated by sdkgen. DO NOT EDIT.
+
+package %s
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+%s)
+
+var _ = url.PathEscape
+
+// Client is a client for the API
+type Client struct {
+	// BaseURL is the scheme and host of the API (e.g. https://api.example.com)
+	BaseURL string
+
+	// HTTPClient is the client used to issue requests
+	HTTPClient *http.Client
+}
+
+// New returns a Client for the API at baseURL
+func New(baseURL string) *Client {
+	return &Client{BaseURL: baseURL, HTTPClient: http.DefaultClient}
+}
+
+// Error is returned for responses with a non-2xx status
+type Error struct {
+	StatusCode int
+	Body       string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("api: status %%d: %%s", e.StatusCode, e.Body)
+}
+
+// do issues a request and decodes the JSON response into out
+func (c *Client) do(method, path string, body, out interface{}) error {
+	var r io.Reader
+	if body != nil {
+		b, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		r = bytes.NewReader(b)
+	}
+	req, err := http.NewRequest(method, c.BaseURL+path, r)
+	if err != nil {
+		return err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	req.Header.Set("Accept", "application/json")
+
+	res, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		b, _ := ioutil.ReadAll(res.Body)
+		return &Error{StatusCode: res.StatusCode, Body: string(b)}
+	}
+	if out == nil || res.StatusCode == http.StatusNoContent {
+		return nil
+	}
+	return json.NewDecoder(res.Body).Decode(out)
+}
+`
+
+// Go writes a Go client package named pkg for the named routes
+// in routes to w. The generated source is gofmt-formatted.
+func Go(w io.Writer, pkg string, routes []mux.Route) error {
+	ops := operations(routes)
+	types := structTypes(ops)
+
+	imports := ""
+	if usesTime(types, ops) {
+		imports = "\t\"time\"\n"
+	}
+
+	buf := &bytes.Buffer{}
+	fmt.Fprintf(buf, goHeader, pkg, imports)
+
+	for _, t := range types {
+		fmt.Fprintf(buf, "\n// %s is a request or response type of the API\n", t.Name())
+		fmt.Fprintf(buf, "type %s struct {\n", t.Name())
+		for _, f := range fields(t) {
+			tag := f.jsonName
+			if f.optional {
+				tag += ",omitempty"
+			}
+			fmt.Fprintf(buf, "\t%s %s `json:%s`\n", f.name, goType(f.typ), strconv.Quote(tag))
+		}
+		buf.WriteString("}\n")
+	}
+
+	for _, op := range ops {
+		writeGoOperation(buf, op)
+	}
+
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(src)
+	return err
+}
+
+// writeGoOperation writes the client method for op
+func writeGoOperation(buf *bytes.Buffer, op operation) {
+	args := make([]string, 0)
+	for _, p := range op.params() {
+		args = append(args, goParam(p)+" string")
+	}
+	if op.request != nil {
+		args = append(args, "body "+goType(reflect.PtrTo(op.request)))
+	}
+
+	result := "error"
+	if op.response != nil {
+		result = "(" + goType(reflect.PtrTo(op.response)) + ", error)"
+	}
+
+	name := exportedName(op.name)
+	fmt.Fprintf(buf, "\n// %s calls %s %s\n", name, op.method, pathPattern(op))
+	if op.retrySafe {
+		fmt.Fprintf(buf, "// %s is safe to retry\n", name)
+	}
+	fmt.Fprintf(buf, "func (c *Client) %s(%s) %s {\n", name, strings.Join(args, ", "), result)
+
+	parts := make([]string, 0, len(op.segments))
+	for _, s := range op.segments {
+		if s.param == "" {
+			parts = append(parts, strconv.Quote(s.literal))
+		} else if s.catchAll {
+			parts = append(parts, goParam(s.param))
+		} else {
+			parts = append(parts, "url.PathEscape("+goParam(s.param)+")")
+		}
+	}
+	if len(parts) == 0 {
+		parts = append(parts, `"/"`)
+	}
+	fmt.Fprintf(buf, "\tpath := %s\n", strings.Join(parts, " + "))
+
+	body := "nil"
+	if op.request != nil {
+		body = "body"
+	}
+	if op.response == nil {
+		fmt.Fprintf(buf, "\treturn c.do(%q, path, %s, nil)\n}\n", op.method, body)
+		return
+	}
+	fmt.Fprintf(buf, "\tout := new(%s)\n", goType(op.response))
+	fmt.Fprintf(buf, "\tif err := c.do(%q, path, %s, out); err != nil {\n\t\treturn nil, err\n\t}\n", op.method, body)
+	buf.WriteString("\treturn out, nil\n}\n")
+}
+
+// goType returns the Go source representation of t
+func goType(t reflect.Type) string {
+	if t == timeType {
+		return "time.Time"
+	}
+	switch t.Kind() {
+	case reflect.Ptr:
+		return "*" + goType(t.Elem())
+	case reflect.Slice:
+		return "[]" + goType(t.Elem())
+	case reflect.Array:
+		return "[" + strconv.Itoa(t.Len()) + "]" + goType(t.Elem())
+	case reflect.Map:
+		return "map[" + goType(t.Key()) + "]" + goType(t.Elem())
+	case reflect.Struct:
+		if t.Name() == "" {
+			return "map[string]interface{}"
+		}
+		return t.Name()
+	case reflect.Interface:
+		return "interface{}"
+	}
+	return t.Kind().String()
+}
+
+// goParam returns a Go identifier for a path parameter that
+// does not collide with keywords or generated identifiers
+func goParam(name string) string {
+	id := unexportedName(name)
+	switch id {
+	case "c", "path", "out", "body", "err", "url":
+		return id + "Param"
+	}
+	if token.Lookup(id).IsKeyword() {
+		return id + "Param"
+	}
+	return id
+}
+
+// usesTime returns whether any generated type references time.Time
+func usesTime(types []reflect.Type, ops []operation) bool {
+	var uses func(t reflect.Type) bool
+	uses = func(t reflect.Type) bool {
+		for t.Kind() == reflect.Ptr || t.Kind() == reflect.Slice ||
+			t.Kind() == reflect.Array || t.Kind() == reflect.Map {
+			t = t.Elem()
+		}
+		return t == timeType
+	}
+	for _, t := range types {
+		for _, f := range fields(t) {
+			if uses(f.typ) {
+				return true
+			}
+		}
+	}
+	for _, op := range ops {
+		if (op.request != nil && uses(op.request)) || (op.response != nil && uses(op.response)) {
+			return true
+		}
+	}
+	return false
+}
+
+// pathPattern returns the route path of op with
+// parameters rendered as {name}
+func pathPattern(op operation) string {
+	p := ""
+	for _, s := range op.segments {
+		if s.param == "" {
+			p += s.literal
+		} else {
+			p += "{" + s.param + "}"
+		}
+	}
+	return p
+}
@@ -0,0 +1,245 @@
+// Package sdkgen generates client packages from a Verto route table.
+// One client function is generated per named route. Request and response
+// types are taken from prototype values attached to routes as metadata.
+// Operations on retry-safe routes (see verto.Endpoint.RetrySafe) are
+// documented as safe to retry.
+//
+// Example usage:
+//
+//	v.Get("/users/{id}", getUser).Name("user.show").
+//		Meta(sdkgen.ResponseKey, User{})
+//	v.Post("/users", createUser).Name("user.create").
+//		Meta(sdkgen.RequestKey, User{}).
+//		Meta(sdkgen.ResponseKey, User{})
+//
+//	f, _ := os.Create("client/client.go")
+//	sdkgen.Go(f, "client", v.Routes())
+package sdkgen
+
+import (
+	"github.com/boxtown/verto"
+	"github.com/boxtown/verto/mux"
+	"reflect"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// RequestKey is the route metadata key for a prototype
+// value of the route's request body type
+const RequestKey = "sdkgen.request"
+
+// ResponseKey is the route metadata key for a prototype
+// value of the route's response body type
+const ResponseKey = "sdkgen.response"
+
+// operation is a client function generated for a named route
+type operation struct {
+	name      string
+	method    string
+	segments  []segment
+	request   reflect.Type
+	response  reflect.Type
+	retrySafe bool
+}
+
+// segment is a literal or parameterized part of a route path
+type segment struct {
+	literal  string
+	param    string
+	catchAll bool
+}
+
+// operations returns an operation for every named route
+func operations(routes []mux.Route) []operation {
+	ops := make([]operation, 0)
+	for _, r := range routes {
+		if r.Name() == "" {
+			continue
+		}
+		op := operation{
+			name:      r.Name(),
+			method:    r.Method(),
+			segments:  parsePath(r.Path()),
+			retrySafe: verto.RetrySafe(r),
+		}
+		if v, ok := r.Meta(RequestKey); ok && v != nil {
+			op.request = indirect(reflect.TypeOf(v))
+		}
+		if v, ok := r.Meta(ResponseKey); ok && v != nil {
+			op.response = indirect(reflect.TypeOf(v))
+		}
+		ops = append(ops, op)
+	}
+	return ops
+}
+
+// params returns the names of the operation's path parameters
+func (op operation) params() []string {
+	params := make([]string, 0)
+	for _, s := range op.segments {
+		if s.param != "" {
+			params = append(params, s.param)
+		}
+	}
+	return params
+}
+
+// parsePath splits a route path pattern into segments. Named
+// parameters and catch-alls become parameter segments. The
+// catch-all is named 'rest'
+func parsePath(path string) []segment {
+	segments := make([]segment, 0)
+	literal := ""
+	for i, s := range strings.Split(path, "/") {
+		if i > 0 {
+			literal += "/"
+		}
+		param := ""
+		if s == "^" {
+			param = "rest"
+		} else if strings.HasPrefix(s, "{") && strings.HasSuffix(s, "}") {
+			param = strings.TrimSpace(strings.SplitN(s[1:len(s)-1], ":", 2)[0])
+		}
+		if param == "" {
+			literal += s
+			continue
+		}
+		if literal != "" {
+			segments = append(segments, segment{literal: literal})
+			literal = ""
+		}
+		segments = append(segments, segment{param: param, catchAll: s == "^"})
+		if s == "^" {
+			break
+		}
+	}
+	if literal != "" {
+		segments = append(segments, segment{literal: literal})
+	}
+	return segments
+}
+
+// structTypes returns every named struct type reachable
+// from the request and response types of ops in order of
+// first appearance
+func structTypes(ops []operation) []reflect.Type {
+	seen := make(map[reflect.Type]bool)
+	types := make([]reflect.Type, 0)
+
+	var visit func(t reflect.Type)
+	visit = func(t reflect.Type) {
+		for t.Kind() == reflect.Ptr || t.Kind() == reflect.Slice ||
+			t.Kind() == reflect.Array || t.Kind() == reflect.Map {
+			t = t.Elem()
+		}
+		if t.Kind() != reflect.Struct || t == timeType || seen[t] {
+			return
+		}
+		seen[t] = true
+		if t.Name() != "" {
+			types = append(types, t)
+		}
+		for _, f := range fields(t) {
+			visit(f.typ)
+		}
+	}
+	for _, op := range ops {
+		if op.request != nil {
+			visit(op.request)
+		}
+		if op.response != nil {
+			visit(op.response)
+		}
+	}
+	return types
+}
+
+// field is a JSON-serialized struct field
+type field struct {
+	name     string
+	jsonName string
+	typ      reflect.Type
+	optional bool
+}
+
+// fields returns the JSON-serialized fields of struct type t.
+// Fields of embedded structs without a JSON name are promoted
+func fields(t reflect.Type) []field {
+	fs := make([]field, 0)
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.PkgPath != "" && !f.Anonymous {
+			continue
+		}
+		tag := f.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		parts := strings.Split(tag, ",")
+		name := parts[0]
+
+		ft := f.Type
+		if f.Anonymous && name == "" {
+			if ft.Kind() == reflect.Ptr {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				fs = append(fs, fields(ft)...)
+			}
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+
+		optional := ft.Kind() == reflect.Ptr
+		for _, p := range parts[1:] {
+			if p == "omitempty" {
+				optional = true
+			}
+		}
+		fs = append(fs, field{f.Name, name, ft, optional})
+	}
+	return fs
+}
+
+// indirect returns the element type of pointer types
+func indirect(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t
+}
+
+// exportedName converts a route or parameter name such as
+// user.show or user_id into an exported identifier (UserShow, UserId)
+func exportedName(name string) string {
+	out := make([]rune, 0, len(name))
+	upper := true
+	for _, r := range name {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			upper = true
+			continue
+		}
+		if upper {
+			r = unicode.ToUpper(r)
+			upper = false
+		}
+		out = append(out, r)
+	}
+	if len(out) == 0 || unicode.IsDigit(out[0]) {
+		out = append([]rune("Op"), out...)
+	}
+	return string(out)
+}
+
+// unexportedName converts a route or parameter name into
+// an unexported identifier (userShow, userId)
+func unexportedName(name string) string {
+	r := []rune(exportedName(name))
+	r[0] = unicode.ToLower(r[0])
+	return string(r)
+}
+
+var timeType = reflect.TypeOf(time.Time{})
@@ -0,0 +1,120 @@
+package sdkgen
+
+import (
+	"bytes"
+	"github.com/boxtown/verto"
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"strings"
+	"testing"
+	"time"
+)
+
+type testUser struct {
+	Id      string    `json:"id"`
+	Name    string    `json:"name,omitempty"`
+	Tags    []string  `json:"tags"`
+	Created time.Time `json:"created"`
+	Manager *testUser `json:"manager"`
+	secret  string
+}
+
+func testVerto() *verto.Verto {
+	v := verto.New()
+	v.Logger = &verto.NilLogger{}
+	rf := func(c *verto.Context) (interface{}, error) { return nil, nil }
+
+	v.Get("/users/{id: ^[0-9]+$}", rf).Name("user.show").Meta(ResponseKey, &testUser{})
+	v.Post("/users", rf).Name("user.create").Meta(RequestKey, testUser{}).Meta(ResponseKey, testUser{})
+	v.Delete("/users/{type}", rf).Name("user.delete")
+	v.Get("/files/^", rf).Name("files")
+	v.Get("/unnamed", rf)
+	return v
+}
+
+func TestGo(t *testing.T) {
+	defer func() {
+		err := recover()
+		if err != nil {
+			t.Errorf(err.(error).Error())
+		}
+	}()
+
+	err := "Failed go."
+
+	buf := &bytes.Buffer{}
+	if e := Go(buf, "client", testVerto().Routes()); e != nil {
+		t.Fatalf(e.Error())
+	}
+	src := buf.String()
+
+	// Generated source must type check
+	fset := token.NewFileSet()
+	f, e := parser.ParseFile(fset, "client.go", src, 0)
+	if e != nil {
+		t.Fatalf(e.Error())
+	}
+	conf := types.Config{Importer: importer.Default()}
+	if _, e := conf.Check("client", fset, []*ast.File{f}, nil); e != nil {
+		t.Fatalf(e.Error())
+	}
+
+	expected := []string{
+		"func (c *Client) UserShow(id string) (*testUser, error)",
+		"func (c *Client) UserCreate(body *testUser) (*testUser, error)",
+		"func (c *Client) UserDelete(typeParam string) error",
+		"func (c *Client) Files(rest string) error",
+		`path := "/users/" + url.PathEscape(id)`,
+		`path := "/files/" + rest`,
+		"Name    string    `json:\"name,omitempty\"`",
+		"Manager *testUser `json:\"manager,omitempty\"`",
+		"// UserShow is safe to retry",
+	}
+	for _, s := range expected {
+		if !strings.Contains(src, s) {
+			t.Errorf(err)
+		}
+	}
+	if strings.Contains(src, "Unnamed") || strings.Contains(src, "secret") ||
+		strings.Contains(src, "UserCreate is safe to retry") {
+		t.Errorf(err)
+	}
+}
+
+func TestTypeScript(t *testing.T) {
+	defer func() {
+		err := recover()
+		if err != nil {
+			t.Errorf(err.(error).Error())
+		}
+	}()
+
+	err := "Failed typescript."
+
+	buf := &bytes.Buffer{}
+	if e := TypeScript(buf, testVerto().Routes()); e != nil {
+		t.Fatalf(e.Error())
+	}
+	src := buf.String()
+
+	expected := []string{
+		"userShow(id: string): Promise<testUser> {",
+		"return this.request<testUser>(\"GET\", `/users/${encodeURIComponent(id)}`);",
+		"userCreate(body: testUser): Promise<testUser> {",
+		"return this.request<testUser>(\"POST\", `/users`, body);",
+		"files(rest: string): Promise<void> {",
+		"export interface testUser {",
+		`"name"?: string;`,
+		`"tags": string[];`,
+		`"created": string;`,
+		`"manager"?: testUser | null;`,
+	}
+	for _, s := range expected {
+		if !strings.Contains(src, s) {
+			t.Errorf(err)
+		}
+	}
+}
@@ -0,0 +1,145 @@
+package sdkgen
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/boxtown/verto/mux"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// tsHeader is the fixed part of generated TypeScript clients
+const tsHeader = `// Code generated by sdkgen. DO NOT EDIT.
+
+export class ApiError extends Error {
+  constructor(public status: number, public body: string) {
+    super("api: status " + status + ": " + body);
+  }
+}
+
+export class Client {
+  constructor(public baseURL: string, private fetchFn: typeof fetch = fetch) {}
+
+  private async request<T>(method: string, path: string, body?: unknown): Promise<T> {
+    const headers: Record<string, string> = { Accept: "application/json" };
+    if (body !== undefined) {
+      headers["Content-Type"] = "application/json";
+    }
+    const res = await this.fetchFn(this.baseURL + path, {
+      method,
+      headers,
+      body: body === undefined ? undefined : JSON.stringify(body),
+    });
+    if (!res.ok) {
+      throw new ApiError(res.status, await res.text());
+    }
+    if (res.status === 204) {
+      return undefined as unknown as T;
+    }
+    return (await res.json()) as T;
+  }
+`
+
+// TypeScript writes a TypeScript client module for the named
+// routes in routes to w. The generated client uses fetch
+func TypeScript(w io.Writer, routes []mux.Route) error {
+	ops := operations(routes)
+	buf := &bytes.Buffer{}
+	buf.WriteString(tsHeader)
+
+	for _, op := range ops {
+		args := make([]string, 0)
+		for _, p := range op.params() {
+			args = append(args, unexportedName(p)+": string")
+		}
+		if op.request != nil {
+			args = append(args, "body: "+tsType(op.request))
+		}
+		result := "void"
+		if op.response != nil {
+			result = tsType(op.response)
+		}
+
+		parts := make([]string, 0, len(op.segments))
+		for _, s := range op.segments {
+			if s.param == "" {
+				parts = append(parts, strings.Replace(s.literal, "`", "\\`", -1))
+			} else if s.catchAll {
+				parts = append(parts, "${"+unexportedName(s.param)+"}")
+			} else {
+				parts = append(parts, "${encodeURIComponent("+unexportedName(s.param)+")}")
+			}
+		}
+		path := strings.Join(parts, "")
+		if path == "" {
+			path = "/"
+		}
+
+		body := ""
+		if op.request != nil {
+			body = ", body"
+		}
+		fmt.Fprintf(buf, "\n  // %s %s\n", op.method, pathPattern(op))
+		if op.retrySafe {
+			buf.WriteString("  // Safe to retry\n")
+		}
+		fmt.Fprintf(buf, "  %s(%s): Promise<%s> {\n", unexportedName(op.name), strings.Join(args, ", "), result)
+		fmt.Fprintf(buf, "    return this.request<%s>(%s, `%s`%s);\n  }\n", result, strconv.Quote(op.method), path, body)
+	}
+	buf.WriteString("}\n")
+
+	for _, t := range structTypes(ops) {
+		fmt.Fprintf(buf, "\nexport interface %s {\n", t.Name())
+		for _, f := range fields(t) {
+			opt := ""
+			if f.optional {
+				opt = "?"
+			}
+			fmt.Fprintf(buf, "  %s%s: %s;\n", strconv.Quote(f.jsonName), opt, tsType(f.typ))
+		}
+		buf.WriteString("}\n")
+	}
+
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+// tsType returns the TypeScript representation of t
+// as serialized by encoding/json
+func tsType(t reflect.Type) string {
+	if t == timeType {
+		return "string"
+	}
+	switch t.Kind() {
+	case reflect.Ptr:
+		return tsType(t.Elem()) + " | null"
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			// []byte is serialized as a base64 string
+			return "string"
+		}
+		elem := tsType(t.Elem())
+		if strings.Contains(elem, " ") {
+			elem = "(" + elem + ")"
+		}
+		return elem + "[]"
+	case reflect.Map:
+		return "Record<string, " + tsType(t.Elem()) + ">"
+	case reflect.Struct:
+		if t.Name() == "" {
+			return "Record<string, unknown>"
+		}
+		return t.Name()
+	case reflect.Bool:
+		return "boolean"
+	case reflect.String:
+		return "string"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return "number"
+	}
+	return "unknown"
+}
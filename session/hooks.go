@@ -0,0 +1,68 @@
+package session
+
+import (
+	"errors"
+)
+
+// ErrSweepUnsupported is returned by Sweep if the
+// Store does not implement the Sweeper interface
+var ErrSweepUnsupported = errors.New("Store does not support sweeping")
+
+// HookFunc is called with the id and a copy of
+// the data of a session whose lifecycle changed
+type HookFunc func(id string, data map[interface{}]interface{})
+
+// Hooks are callbacks for the lifecycle of server-side sessions
+// created by StoreSessionFactory and TokenSessionFactory with a
+// Store. They allow applications to track active sessions, enforce
+// policies such as a single session per user and release resources
+// kept for a session, e.g. keyed by session id. Hooks are called
+// after the session's lock is released and may use the Store.
+//
+// Example usage:
+//
+//	hooks := &session.Hooks{
+//		OnCreate: func(id string, data map[interface{}]interface{}) {
+//			if old, ok := active.Swap(data["user"], id); ok {
+//				store.Delete(old.(string))
+//			}
+//		},
+//	}
+//	factory := &session.StoreSessionFactory{Store: store, Hooks: hooks, ...}
+type Hooks struct {
+	// OnCreate is called after a new session is first saved.
+	// Data set on an existing session does not create it anew
+	OnCreate HookFunc
+
+	// OnDestroy is called after a session is deleted because it
+	// was cleared and flushed. data is the data last stored
+	OnDestroy HookFunc
+
+	// OnExpire is called by Sweep for each expired session
+	// removed from the Store
+	OnExpire HookFunc
+}
+
+// Sweep removes expired sessions from store and calls OnExpire for
+// each of them. Stores that do not implement Sweeper cannot report
+// expired sessions and ErrSweepUnsupported is returned. Sweep should
+// be called periodically, e.g.:
+//
+//	go func() {
+//		for range time.Tick(time.Minute) {
+//			hooks.Sweep(store)
+//		}
+//	}()
+func (hooks *Hooks) Sweep(store Store) error {
+	sweeper, ok := store.(Sweeper)
+	if !ok {
+		return ErrSweepUnsupported
+	}
+	swept, err := sweeper.Sweep()
+	if hooks.OnExpire != nil {
+		for _, rec := range swept {
+			hooks.OnExpire(rec.Id, rec.Data)
+		}
+	}
+	return err
+}
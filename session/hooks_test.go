@@ -0,0 +1,83 @@
+package session
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestHooks(t *testing.T) {
+	defer func() {
+		err := recover()
+		if err != nil {
+			t.Errorf(err.(error).Error())
+		}
+	}()
+
+	err := "Failed session hooks."
+
+	events := make([]string, 0)
+	hooks := &Hooks{
+		OnCreate: func(id string, data map[interface{}]interface{}) {
+			events = append(events, "create:"+data["user"].(string))
+		},
+		OnDestroy: func(id string, data map[interface{}]interface{}) {
+			events = append(events, "destroy:"+data["user"].(string))
+		},
+		OnExpire: func(id string, data map[interface{}]interface{}) {
+			events = append(events, "expire:"+data["user"].(string))
+		},
+	}
+	store := NewMemoryStore()
+	factory := &StoreSessionFactory{Store: store, HashKey: []byte("hash"), Hooks: hooks}
+
+	// flush runs fn on the session of a request
+	// carrying cookies and returns the new cookies
+	flush := func(cookies []*http.Cookie, fn func(s Session)) []*http.Cookie {
+		r, _ := http.NewRequest("GET", "http://test.com", nil)
+		for _, c := range cookies {
+			r.AddCookie(c)
+		}
+		w := httptest.NewRecorder()
+		s := factory.Create(w, r)
+		fn(s)
+		if e := s.Flush(); e != nil {
+			t.Fatalf(err)
+		}
+		return w.Result().Cookies()
+	}
+
+	// Test create fires once and destroy fires on clear
+	cookies := flush(nil, func(s Session) { s.Set("user", "bob") })
+	cookies = flush(cookies, func(s Session) { s.Set("seen", true) })
+	flush(cookies, func(s Session) { s.Clear() })
+	if len(events) != 2 || events[0] != "create:bob" || events[1] != "destroy:bob" {
+		t.Errorf(err)
+	}
+
+	// Test expired sessions are reported when swept
+	events = events[:0]
+	factory.TTL = time.Millisecond
+	flush(nil, func(s Session) { s.Set("user", "alice") })
+	time.Sleep(5 * time.Millisecond)
+	if hooks.Sweep(store) != nil {
+		t.Errorf(err)
+	}
+	if len(events) != 2 || events[1] != "expire:alice" {
+		t.Errorf(err)
+	}
+	if swept, _ := store.Sweep(); len(swept) != 0 {
+		t.Errorf(err)
+	}
+
+	// Test hooks may use the session's store
+	err = "Failed session hooks reentrancy."
+	hooks.OnCreate = func(id string, data map[interface{}]interface{}) {
+		if _, e := store.Load(id); e != nil {
+			t.Errorf(err)
+		}
+	}
+	factory.TTL = 0
+	flush(nil, func(s Session) { s.Set("user", "carol") })
+}
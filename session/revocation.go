@@ -0,0 +1,90 @@
+package session
+
+import (
+	"fmt"
+	"time"
+)
+
+// USERKEY is the session key the logged in user id is stored under
+const USERKEY = "_VertoUserId"
+
+// RevocationList is the interface for shared revocation storage
+// backends. Implementations backed by shared storage (e.g. the abuse
+// package's Store) revoke sessions across multiple instances
+type RevocationList interface {
+	// Revoke records the revocation of key as of now. The
+	// revocation is kept for ttl or indefinitely if ttl is zero
+	Revoke(key string, ttl time.Duration) error
+
+	// RevokedAt returns the time key was revoked and
+	// whether a revocation of key exists
+	RevokedAt(key string) (time.Time, bool, error)
+}
+
+// Revocations revokes sessions by session id or by user id so that
+// users can be logged out everywhere at once, e.g. from a back-channel
+// logout endpoint. Factories consult their Revocations before trusting
+// a presented session and treat revoked sessions as new ones. Revoking
+// a user revokes the sessions of the user issued before the revocation.
+// Sessions are not trusted if the list cannot be consulted.
+//
+// Example usage:
+//
+//	revocations := &session.Revocations{
+//		List: abuse.NewRevocationList(bans),
+//		TTL:  24 * time.Hour,
+//	}
+//	factory := &session.StoreSessionFactory{Revocations: revocations, ...}
+//	v.Post("/logout/everywhere", func(c *verto.Context) (interface{}, error) {
+//		user, _ := sessionOf(c).Get(session.USERKEY).(string)
+//		return nil, revocations.RevokeUser(user)
+//	})
+type Revocations struct {
+	// List stores the revocations. This field is required
+	List RevocationList
+
+	// UserKey is the session key the user id is stored
+	// under. Defaults to USERKEY
+	UserKey interface{}
+
+	// TTL is how long revocations are kept. It should be at least
+	// the lifetime of sessions. Zero keeps revocations indefinitely
+	TTL time.Duration
+}
+
+// RevokeSession revokes the session with id
+func (rv *Revocations) RevokeSession(id string) error {
+	return rv.List.Revoke("session:"+id, rv.TTL)
+}
+
+// RevokeUser revokes all sessions of user issued until now
+func (rv *Revocations) RevokeUser(user string) error {
+	return rv.List.Revoke("user:"+user, rv.TTL)
+}
+
+// Revoked returns whether the session with id and data issued at
+// issued is revoked. Sessions without an id are only checked by user
+func (rv *Revocations) Revoked(id string, data map[interface{}]interface{}, issued time.Time) bool {
+	if rv == nil {
+		return false
+	}
+	if id != "" {
+		if _, ok, err := rv.List.RevokedAt("session:" + id); ok || err != nil {
+			return true
+		}
+	}
+	key := rv.UserKey
+	if key == nil {
+		key = USERKEY
+	}
+	user, ok := data[key]
+	if !ok {
+		// Data restored from JSON has string keys
+		user, ok = data[fmt.Sprint(key)]
+	}
+	if !ok {
+		return false
+	}
+	at, ok, err := rv.List.RevokedAt("user:" + fmt.Sprint(user))
+	return err != nil || (ok && !issued.After(at))
+}
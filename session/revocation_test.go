@@ -0,0 +1,113 @@
+package session
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// mapRevocations is a RevocationList for tests
+type mapRevocations map[string]time.Time
+
+func (m mapRevocations) Revoke(key string, ttl time.Duration) error {
+	m[key] = time.Now()
+	return nil
+}
+
+func (m mapRevocations) RevokedAt(key string) (time.Time, bool, error) {
+	at, ok := m[key]
+	return at, ok, nil
+}
+
+func TestRevocations(t *testing.T) {
+	defer func() {
+		err := recover()
+		if err != nil {
+			t.Errorf(err.(error).Error())
+		}
+	}()
+
+	err := "Failed store session revocation."
+
+	revocations := &Revocations{List: make(mapRevocations)}
+	store := NewMemoryStore()
+	factory := &StoreSessionFactory{Store: store, HashKey: []byte("hash"), Revocations: revocations}
+
+	// login stores a session for user and returns its cookies
+	login := func(user string) ([]*http.Cookie, string) {
+		r, _ := http.NewRequest("GET", "http://test.com", nil)
+		w := httptest.NewRecorder()
+		s := factory.Create(w, r)
+		s.Set(USERKEY, user)
+		if e := s.Flush(); e != nil {
+			t.Fatalf(err)
+		}
+		return w.Result().Cookies(), s.(*StoreSession).Id()
+	}
+	user := func(cookies []*http.Cookie) interface{} {
+		r, _ := http.NewRequest("GET", "http://test.com", nil)
+		for _, c := range cookies {
+			r.AddCookie(c)
+		}
+		return factory.Create(httptest.NewRecorder(), r).Get(USERKEY)
+	}
+
+	// Test revoking a session by id deletes it
+	a, id := login("bob")
+	b, _ := login("bob")
+	c, _ := login("alice")
+	revocations.RevokeSession(id)
+	if user(a) != nil || user(b) != "bob" {
+		t.Errorf(err)
+	}
+	if _, e := store.Load(id); e != ErrNotFound {
+		t.Errorf(err)
+	}
+
+	// Test revoking a user logs them out everywhere
+	// but allows logging in again
+	time.Sleep(time.Millisecond)
+	revocations.RevokeUser("bob")
+	if user(b) != nil || user(c) != "alice" {
+		t.Errorf(err)
+	}
+	time.Sleep(time.Millisecond)
+	d, _ := login("bob")
+	if user(d) != "bob" {
+		t.Errorf(err)
+	}
+
+	// Test stateless tokens are revoked by id and user
+	err = "Failed token session revocation."
+	tokens := &TokenSessionFactory{HashKey: []byte("hash"), Revocations: revocations}
+	issue := func(user string) (string, string) {
+		r, _ := http.NewRequest("GET", "http://test.com", nil)
+		w := httptest.NewRecorder()
+		s := tokens.Create(w, r)
+		s.Set(USERKEY, user)
+		if e := s.Flush(); e != nil {
+			t.Fatalf(err)
+		}
+		return w.Header().Get(DefaultTokenResponseHeader), s.(*TokenSession).Id()
+	}
+	present := func(token string) interface{} {
+		r, _ := http.NewRequest("GET", "http://test.com", nil)
+		r.Header.Set("Authorization", "Bearer "+token)
+		return tokens.Create(httptest.NewRecorder(), r).Get(USERKEY)
+	}
+	token, id := issue("carol")
+	if present(token) != "carol" {
+		t.Fatalf(err)
+	}
+	revocations.RevokeSession(id)
+	if present(token) != nil {
+		t.Errorf(err)
+	}
+	token, _ = issue("carol")
+	time.Sleep(time.Millisecond)
+	revocations.RevokeUser("carol")
+	if present(token) != nil {
+		t.Errorf(err)
+	}
+}
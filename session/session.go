@@ -10,8 +10,10 @@ import (
 	"encoding/base64"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"io"
 	"net/http"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -30,6 +32,10 @@ var ErrBadHMAC = errors.New("Mis-matched HMAC")
 // if the hashKey parameter is missing
 var ErrMissingKey = errors.New("Missing required hashKey argument")
 
+// ErrBadChunks is returned when reading a chunked session cookie
+// whose chunk count is invalid or whose chunks are missing
+var ErrBadChunks = errors.New("Missing or invalid session cookie chunks")
+
 // SESSIONKEY is the constant name used to denote both the verto
 // session cookie and the session injection
 const SESSIONKEY = "_VertoSession"
@@ -61,7 +67,8 @@ type Session interface {
 
 // CookieSession is an implementation of the Session
 // interface using secure cookies as the backing store.
-// CookieSession is thread safe
+// Session data is serialized as JSON so keys are restored
+// as strings. CookieSession is thread safe
 type CookieSession struct {
 	data       map[interface{}]interface{}
 	hashKey    []byte
@@ -69,6 +76,8 @@ type CookieSession struct {
 	mutex      *sync.RWMutex
 	w          http.ResponseWriter
 	model      *http.Cookie
+	maxChunks  int
+	chunks     int
 }
 
 // Get retrieves the data associated with the key
@@ -113,36 +122,147 @@ func (s *CookieSession) Clear() {
 // Flush will delete any associated cookies. Otherwise,
 // the data will be marshalled and encoded into a secure cookie
 // with the parameters set by the CookieSessionFactory that
-// spawned the session instance
+// spawned the session instance. Secure cookies exceeding the
+// browser cookie size limit are split across multiple chunk
+// cookies. If the chunk limit is exceeded, a *CookieTooLargeError
+// is returned and no cookies are written
 func (s *CookieSession) Flush() error {
 	s.mutex.Lock()
 	defer s.mutex.Unlock()
 
-	// If no data, clear session cookie
+	// If no data, clear session cookies
 	if len(s.data) == 0 {
-		http.SetCookie(s.w, &http.Cookie{
-			Name:    SESSIONKEY,
-			Expires: time.Now().UTC(),
-			MaxAge:  -1,
-		})
+		s.expire(SESSIONKEY)
+		s.expireChunks(0)
 		return nil
 	}
 
-	// attempt to marshal data map to json
-	m, e := json.Marshal(s.data)
+	// attempt to marshal data map to json. Keys are
+	// marshalled as strings
+	data := make(map[string]interface{}, len(s.data))
+	for k, v := range s.data {
+		data[fmt.Sprint(k)] = v
+	}
+	m, e := json.Marshal(data)
 	if e != nil {
 		return e
 	}
 
 	// attempt to secure cookie with HMAC and encryption,
-	// then flush cookie to ResponseWriter and return
+	// then flush cookie to ResponseWriter and return.
+	// The HMAC covers the whole value so chunks cannot be
+	// dropped, reordered or mixed between sessions
 	s.model.Value = string(m)
-	if secure, e := NewSecureCookie(s.model, s.hashKey, s.encryptKey); e != nil {
+	secure, e := NewSecureCookie(s.model, s.hashKey, s.encryptKey)
+	if e != nil {
 		return e
-	} else {
+	}
+	if len(secure.Value) <= chunkSize {
 		http.SetCookie(s.w, secure)
+		s.expireChunks(0)
 		return nil
 	}
+
+	n := (len(secure.Value) + chunkSize - 1) / chunkSize
+	if n > s.maxChunks {
+		return &CookieTooLargeError{Size: len(secure.Value), Max: s.maxChunks * chunkSize}
+	}
+	for i := 0; i < n; i++ {
+		chunk := clone(secure)
+		chunk.Name = chunkName(i)
+		end := (i + 1) * chunkSize
+		if end > len(secure.Value) {
+			end = len(secure.Value)
+		}
+		chunk.Value = secure.Value[i*chunkSize : end]
+		http.SetCookie(s.w, chunk)
+	}
+	header := clone(secure)
+	header.Value = chunkPrefix + strconv.Itoa(n)
+	http.SetCookie(s.w, header)
+	s.expireChunks(n)
+	return nil
+}
+
+// expire expires the cookie with name
+func (s *CookieSession) expire(name string) {
+	http.SetCookie(s.w, &http.Cookie{
+		Name:    name,
+		Path:    s.model.Path,
+		Domain:  s.model.Domain,
+		Expires: time.Now().UTC(),
+		MaxAge:  -1,
+	})
+}
+
+// expireChunks expires chunk cookies received with the
+// request that are not overwritten by the n chunks written
+func (s *CookieSession) expireChunks(n int) {
+	for i := n; i < s.chunks; i++ {
+		s.expire(chunkName(i))
+	}
+}
+
+// CookieTooLargeError is returned by CookieSession.Flush if the
+// session data does not fit into the maximum number of chunk cookies
+type CookieTooLargeError struct {
+	// Size is the size of the encoded session in bytes
+	Size int
+
+	// Max is the maximum encoded session size in bytes
+	Max int
+}
+
+func (e *CookieTooLargeError) Error() string {
+	return fmt.Sprintf(
+		"Session cookie of %d bytes exceeds the maximum of %d bytes. "+
+			"Store less data in the session, raise MaxChunks or use a StoreSessionFactory",
+		e.Size, e.Max)
+}
+
+// chunkSize is the maximum value size of a single session
+// cookie. Browsers limit cookies to 4096 bytes including the
+// name and attributes
+const chunkSize = 3800
+
+// chunkPrefix prefixes the value of the session cookie of chunked
+// sessions. The value is followed by the number of chunks. '*' is
+// not part of the base64 alphabet of unchunked values
+const chunkPrefix = "*"
+
+// defaultMaxChunks is the default maximum number of chunk cookies
+const defaultMaxChunks = 4
+
+// returns the name of the i-th chunk cookie
+func chunkName(i int) string {
+	return SESSIONKEY + "_" + strconv.Itoa(i)
+}
+
+// readCookie returns the session cookie of the request, reassembling
+// chunked sessions, and the number of chunk cookies. Returns an error
+// if the cookie is missing or a chunk is missing
+func readCookie(r *http.Request, maxChunks int) (*http.Cookie, int, error) {
+	cookie, err := r.Cookie(SESSIONKEY)
+	if err != nil {
+		return nil, 0, err
+	}
+	if !strings.HasPrefix(cookie.Value, chunkPrefix) {
+		return cookie, 0, nil
+	}
+
+	n, err := strconv.Atoi(cookie.Value[len(chunkPrefix):])
+	if err != nil || n < 1 || n > maxChunks {
+		return nil, 0, ErrBadChunks
+	}
+	var value bytes.Buffer
+	for i := 0; i < n; i++ {
+		chunk, err := r.Cookie(chunkName(i))
+		if err != nil {
+			return nil, n, ErrBadChunks
+		}
+		value.WriteString(chunk.Value)
+	}
+	return &http.Cookie{Name: SESSIONKEY, Value: value.String()}, n, nil
 }
 
 // Factory is an interface for creating Session instances
@@ -171,6 +291,10 @@ type CookieSessionFactory struct {
 	MaxAge   int
 	Secure   bool
 	HttpOnly bool
+
+	// MaxChunks is the maximum number of cookies a session may
+	// be split across. Defaults to 4
+	MaxChunks int
 }
 
 // Create instantiates a CookieSession from the passed in http.Request
@@ -179,6 +303,10 @@ type CookieSessionFactory struct {
 // the contents stored in the generated session. If cookie decryption fails,
 // the session data will be empty
 func (factory *CookieSessionFactory) Create(w http.ResponseWriter, r *http.Request) Session {
+	maxChunks := factory.MaxChunks
+	if maxChunks <= 0 {
+		maxChunks = defaultMaxChunks
+	}
 	session := &CookieSession{
 		data:       make(map[interface{}]interface{}),
 		hashKey:    factory.HashKey,
@@ -195,13 +323,21 @@ func (factory *CookieSessionFactory) Create(w http.ResponseWriter, r *http.Reque
 			Secure:   factory.Secure,
 			HttpOnly: factory.HttpOnly,
 		},
+		maxChunks: maxChunks,
 	}
 
 	// If a previous session exists and is valid,
 	// unmarshal values into created session data
-	if cookie, err := r.Cookie(SESSIONKEY); err == nil {
+	cookie, chunks, err := readCookie(r, maxChunks)
+	session.chunks = chunks
+	if err == nil {
 		if cookie, err := DecryptCookie(cookie, factory.HashKey, factory.EncryptKey); err == nil {
-			json.Unmarshal([]byte(cookie.Value), session.data)
+			data := make(map[string]interface{})
+			if json.Unmarshal([]byte(cookie.Value), &data) == nil {
+				for k, v := range data {
+					session.data[k] = v
+				}
+			}
 		}
 	}
 
@@ -299,22 +435,25 @@ var sep = ":"
 // of cookie
 func clone(cookie *http.Cookie) *http.Cookie {
 	return &http.Cookie{
-		Name:    cookie.Name,
-		Path:    cookie.Path,
-		Domain:  cookie.Domain,
-		Expires: cookie.Expires,
-		MaxAge:  cookie.MaxAge,
-		Secure:  cookie.Secure,
+		Name:     cookie.Name,
+		Path:     cookie.Path,
+		Domain:   cookie.Domain,
+		Expires:  cookie.Expires,
+		MaxAge:   cookie.MaxAge,
+		Secure:   cookie.Secure,
+		HttpOnly: cookie.HttpOnly,
 	}
 }
 
 // attempts to retrieve the mac from the value and compare
 // against a freshly calculated mac using the passed in name
 // and stripped value. Returns the stripped value and true
-// if the mac matches or an empty string and false otherwise
+// if the mac matches or an empty string and false otherwise.
+// The mac is raw bytes that may contain the separator so it
+// is located by its fixed size
 func checkHMAC(key []byte, name, value string) (string, bool) {
-	i := strings.LastIndex(value, sep)
-	if i < 0 || i == len(value)-1 {
+	i := len(value) - sha256.Size - len(sep)
+	if i < 0 || value[i:i+len(sep)] != sep {
 		return "", false
 	}
 	actual := value[:i]
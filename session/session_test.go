@@ -0,0 +1,88 @@
+package session
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestCookieSessionChunking(t *testing.T) {
+	defer func() {
+		err := recover()
+		if err != nil {
+			t.Errorf(err.(error).Error())
+		}
+	}()
+
+	err := "Failed cookie session chunking."
+
+	factory := &CookieSessionFactory{HashKey: []byte("hash"), EncryptKey: []byte("0123456789abcdef")}
+
+	// flush runs s through a request carrying cookies and
+	// returns the cookies of the response
+	flush := func(cookies []*http.Cookie, fn func(s Session)) ([]*http.Cookie, Session, error) {
+		r, _ := http.NewRequest("GET", "http://test.com", nil)
+		for _, c := range cookies {
+			r.AddCookie(c)
+		}
+		w := httptest.NewRecorder()
+		s := factory.Create(w, r)
+		fn(s)
+		e := s.Flush()
+		return w.Result().Cookies(), s, e
+	}
+	live := func(cookies []*http.Cookie) []*http.Cookie {
+		l := make([]*http.Cookie, 0)
+		for _, c := range cookies {
+			if c.MaxAge >= 0 {
+				l = append(l, c)
+			}
+		}
+		return l
+	}
+
+	// Test small sessions use a single cookie
+	cookies, _, e := flush(nil, func(s Session) { s.Set("user", "bob") })
+	if e != nil || len(cookies) != 1 || cookies[0].Name != SESSIONKEY {
+		t.Fatalf(err)
+	}
+	_, s, _ := flush(cookies, func(s Session) {})
+	if s.Get("user") != "bob" {
+		t.Errorf(err)
+	}
+
+	// Test large sessions are chunked and reassembled
+	big := strings.Repeat("x", 8000)
+	cookies, _, e = flush(cookies, func(s Session) { s.Set("big", big) })
+	if e != nil || len(cookies) != 4 {
+		t.Fatalf(err)
+	}
+	for _, c := range cookies {
+		if len(c.String()) > 4096 {
+			t.Errorf(err)
+		}
+	}
+	chunked := cookies
+	shrunk, s, _ := flush(chunked, func(s Session) { s.Del("big") })
+	if s.Get("big") != nil || s.Get("user") != "bob" {
+		t.Errorf(err)
+	}
+
+	// Test shrinking expires stale chunks
+	if len(live(shrunk)) != 1 || len(shrunk) != 4 {
+		t.Errorf(err)
+	}
+
+	// Test missing chunks invalidate the session
+	_, s, _ = flush(chunked[1:], func(s Session) {})
+	if s.Get("user") != nil {
+		t.Errorf(err)
+	}
+
+	// Test the size cap
+	_, _, e = flush(nil, func(s Session) { s.Set("huge", strings.Repeat("x", 20000)) })
+	if _, ok := e.(*CookieTooLargeError); !ok || !strings.Contains(e.Error(), "exceeds") {
+		t.Errorf(err)
+	}
+}
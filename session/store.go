@@ -0,0 +1,150 @@
+package session
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrNotFound is returned by Stores if a session record
+// does not exist or has expired
+var ErrNotFound = errors.New("Session not found")
+
+// ErrConflict is returned by Store.Save if the stored record was
+// modified since it was loaded and by StoreSession.Flush if the
+// conflict could not be resolved
+var ErrConflict = errors.New("Session modified concurrently")
+
+// Record is a server-side session record
+type Record struct {
+	// Id is the session id
+	Id string
+
+	// Data is the session data
+	Data map[interface{}]interface{}
+
+	// Created is the time the session was first saved
+	Created time.Time
+
+	// Version is incremented by the Store on every save and is used
+	// to detect concurrent modification. New records have version 0
+	Version int64
+
+	// Expires is the time the record expires. The zero
+	// value means the record does not expire
+	Expires time.Time
+}
+
+// Store is the interface for server-side session storage backends.
+// Stores implement optimistic concurrency control through record
+// versions. Store implementations must be thread-safe
+type Store interface {
+	// Load returns the record with id or ErrNotFound
+	Load(id string) (*Record, error)
+
+	// Save stores rec if the stored version equals rec.Version (or
+	// no record is stored for a version of 0) and increments
+	// rec.Version. Returns ErrConflict otherwise
+	Save(rec *Record) error
+
+	// Delete deletes the record with id
+	Delete(id string) error
+}
+
+// Sweeper is implemented by Stores that can remove expired records
+// on demand. Sweeping is required for Hooks.OnExpire to be called
+type Sweeper interface {
+	// Sweep deletes all expired records and returns them
+	Sweep() ([]*Record, error)
+}
+
+// MemoryStore is an in-memory Store. MemoryStore is thread-safe
+type MemoryStore struct {
+	records map[string]*Record
+	mutex   *sync.Mutex
+}
+
+// NewMemoryStore returns an empty MemoryStore
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		records: make(map[string]*Record),
+		mutex:   &sync.Mutex{},
+	}
+}
+
+// Load returns a copy of the record with id. Expired records
+// are not returned but are kept until they are swept
+func (ms *MemoryStore) Load(id string) (*Record, error) {
+	ms.mutex.Lock()
+	defer ms.mutex.Unlock()
+
+	rec, ok := ms.records[id]
+	if !ok || expired(rec, time.Now()) {
+		return nil, ErrNotFound
+	}
+	return copyRecord(rec), nil
+}
+
+// Save stores a copy of rec if its version matches the stored
+// version. Expired records are overwritten as if they did not exist
+func (ms *MemoryStore) Save(rec *Record) error {
+	ms.mutex.Lock()
+	defer ms.mutex.Unlock()
+
+	var version int64
+	if stored, ok := ms.records[rec.Id]; ok && !expired(stored, time.Now()) {
+		version = stored.Version
+	}
+	if rec.Version != version {
+		return ErrConflict
+	}
+	rec.Version++
+	ms.records[rec.Id] = copyRecord(rec)
+	return nil
+}
+
+// Delete deletes the record with id
+func (ms *MemoryStore) Delete(id string) error {
+	ms.mutex.Lock()
+	defer ms.mutex.Unlock()
+
+	delete(ms.records, id)
+	return nil
+}
+
+// Sweep deletes all expired records and returns them
+func (ms *MemoryStore) Sweep() ([]*Record, error) {
+	ms.mutex.Lock()
+	defer ms.mutex.Unlock()
+
+	now := time.Now()
+	swept := make([]*Record, 0)
+	for id, rec := range ms.records {
+		if expired(rec, now) {
+			delete(ms.records, id)
+			swept = append(swept, rec)
+		}
+	}
+	return swept, nil
+}
+
+// returns whether rec has expired at now
+func expired(rec *Record, now time.Time) bool {
+	return !rec.Expires.IsZero() && now.After(rec.Expires)
+}
+
+// returns a copy of rec with a copied data map
+func copyRecord(rec *Record) *Record {
+	c := *rec
+	c.Data = copyData(rec.Data)
+	return &c
+}
+
+// returns a shallow copy of data
+func copyData(data map[interface{}]interface{}) map[interface{}]interface{} {
+	c := make(map[interface{}]interface{}, len(data))
+	for k, v := range data {
+		c[k] = v
+	}
+	return c
+}
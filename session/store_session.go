@@ -0,0 +1,340 @@
+package session
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"reflect"
+	"sync"
+	"time"
+)
+
+// ConflictFunc resolves a concurrent modification of a session. base
+// is the data the session was loaded with, local is the data of the
+// session being flushed and remote is the data stored by the concurrent
+// request. ConflictFunc returns the data to store or an error to abort
+// the flush
+type ConflictFunc func(base, local, remote map[interface{}]interface{}) (map[interface{}]interface{}, error)
+
+// Merge is the default ConflictFunc. Keys set or deleted by the
+// flushing session are applied on top of the remote data so that
+// concurrent requests modifying different keys do not lose each
+// other's changes. For keys modified by both, the flushing session wins
+func Merge(base, local, remote map[interface{}]interface{}) (map[interface{}]interface{}, error) {
+	merged := copyData(remote)
+	for k, v := range local {
+		if bv, ok := base[k]; !ok || !reflect.DeepEqual(bv, v) {
+			merged[k] = v
+		}
+	}
+	for k := range base {
+		if _, ok := local[k]; !ok {
+			delete(merged, k)
+		}
+	}
+	return merged, nil
+}
+
+// maxFlushAttempts is the number of times StoreSession.Flush
+// attempts to resolve conflicts before giving up
+const maxFlushAttempts = 3
+
+// StoreSession is an implementation of the Session interface backed
+// by a server-side Store. Only a signed session id is stored in the
+// session cookie (or the session token, see TokenSessionFactory).
+// Concurrent requests sharing a session are detected on Flush through
+// record versions and resolved with a ConflictFunc. StoreSession is
+// thread safe
+type StoreSession struct {
+	id          string
+	data        map[interface{}]interface{}
+	base        map[interface{}]interface{}
+	version     int64
+	created     time.Time
+	store       Store
+	ttl         time.Duration
+	onConflict  ConflictFunc
+	hooks       *Hooks
+	revocations *Revocations
+	mutex       *sync.RWMutex
+
+	// write hands the session id to the client
+	// and expire tells the client to discard it
+	write  func(id string) error
+	expire func()
+}
+
+// Id returns the session id or the empty string
+// if the session has not been stored yet
+func (s *StoreSession) Id() string {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	return s.id
+}
+
+// Get retrieves the data associated with the key
+// or nil if no such association exists
+func (s *StoreSession) Get(key interface{}) interface{} {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	return s.data[key]
+}
+
+// Set sets a key-value association for the session instance.
+// If a previous association exists, it is overwritten
+func (s *StoreSession) Set(key, value interface{}) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.data[key] = value
+}
+
+// Del deletes a key-value association from the session instance
+func (s *StoreSession) Del(key interface{}) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	delete(s.data, key)
+}
+
+// Clear clears all data from the session instance.
+// Calling clear and then flush deletes the stored
+// session and expires the session cookie
+func (s *StoreSession) Clear() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.data = make(map[interface{}]interface{})
+}
+
+// Flush saves the session data to the Store and writes the session
+// cookie. If the stored session was modified by a concurrent request
+// since it was loaded, the factory's OnConflict function is used to
+// resolve the conflict. ErrConflict is returned if the conflict could
+// not be resolved. Hooks for the creation or destruction of the
+// session are called once the session is unlocked
+func (s *StoreSession) Flush() error {
+	s.mutex.Lock()
+	notify, err := s.flush()
+	s.mutex.Unlock()
+
+	if notify != nil {
+		notify()
+	}
+	return err
+}
+
+// flush implements Flush and returns a function calling
+// the lifecycle hook for the flush, if any
+func (s *StoreSession) flush() (func(), error) {
+	store := s.store
+
+	// If no data, delete the session
+	if len(s.data) == 0 {
+		var notify func()
+		if s.id != "" {
+			if err := store.Delete(s.id); err != nil {
+				return nil, err
+			}
+			if s.hooks != nil && s.hooks.OnDestroy != nil {
+				id, data, hook := s.id, s.base, s.hooks.OnDestroy
+				notify = func() { hook(id, data) }
+			}
+			s.id = ""
+			s.base = make(map[interface{}]interface{})
+			s.version = 0
+			s.created = time.Time{}
+		}
+		s.expire()
+		return notify, nil
+	}
+
+	created := s.id == ""
+	if created {
+		id, err := newSessionId()
+		if err != nil {
+			return nil, err
+		}
+		s.id = id
+		s.created = time.Now()
+	}
+
+	resolve := s.onConflict
+	if resolve == nil {
+		resolve = Merge
+	}
+	for attempt := 0; attempt < maxFlushAttempts; attempt++ {
+		rec := &Record{
+			Id:      s.id,
+			Data:    copyData(s.data),
+			Version: s.version,
+			Created: s.created,
+		}
+		if s.ttl > 0 {
+			rec.Expires = time.Now().Add(s.ttl)
+		}
+
+		err := store.Save(rec)
+		if err == nil {
+			s.version = rec.Version
+			s.base = copyData(s.data)
+			var notify func()
+			if created && s.hooks != nil && s.hooks.OnCreate != nil {
+				id, data, hook := s.id, copyData(s.data), s.hooks.OnCreate
+				notify = func() { hook(id, data) }
+			}
+			return notify, s.write(s.id)
+		}
+		if err != ErrConflict {
+			return nil, err
+		}
+
+		// Reload the concurrently stored record and resolve
+		remote, err := store.Load(s.id)
+		if err == ErrNotFound {
+			remote = &Record{Id: s.id, Data: make(map[interface{}]interface{})}
+		} else if err != nil {
+			return nil, err
+		}
+		merged, err := resolve(s.base, s.data, remote.Data)
+		if err != nil {
+			return nil, err
+		}
+		s.data = merged
+		s.base = remote.Data
+		s.version = remote.Version
+	}
+	return nil, ErrConflict
+}
+
+// StoreSessionFactory is an implementation of Factory that creates
+// Session instances backed by a server-side Store
+type StoreSessionFactory struct {
+	// Store is the backing store for session data.
+	// This field is required
+	Store Store
+
+	// HashKey used to sign the session id cookie.
+	// This field is required
+	HashKey []byte
+
+	// EncryptKey is an optional key used to encrypt
+	// the session id cookie
+	EncryptKey []byte
+
+	// TTL is the lifetime of stored sessions since their last
+	// flush. Zero means sessions do not expire
+	TTL time.Duration
+
+	// OnConflict resolves concurrent modifications of a session.
+	// Defaults to Merge
+	OnConflict ConflictFunc
+
+	// Hooks are optional callbacks for the
+	// lifecycle of the factory's sessions
+	Hooks *Hooks
+
+	// Revocations are optionally consulted
+	// before trusting a presented session
+	Revocations *Revocations
+
+	// The below fields correspond to the fields within http.Cookie
+	Path     string
+	Domain   string
+	MaxAge   int
+	Secure   bool
+	HttpOnly bool
+}
+
+// Create instantiates a StoreSession for the passed in http.Request and
+// writes out to the passed in http.ResponseWriter. If the request carries
+// a valid session cookie for a stored session, the stored data is loaded.
+// Otherwise the session is empty and receives a new id when flushed
+func (factory *StoreSessionFactory) Create(w http.ResponseWriter, r *http.Request) Session {
+	session := newStoreSession(factory.Store, factory.TTL, factory.OnConflict, factory.Hooks)
+	session.revocations = factory.Revocations
+	session.write = func(id string) error {
+		return factory.writeCookie(w, id)
+	}
+	session.expire = func() {
+		http.SetCookie(w, &http.Cookie{
+			Name:    SESSIONKEY,
+			Path:    factory.Path,
+			Domain:  factory.Domain,
+			Expires: time.Now().UTC(),
+			MaxAge:  -1,
+		})
+	}
+
+	if cookie, err := r.Cookie(SESSIONKEY); err == nil {
+		if cookie, err := DecryptCookie(cookie, factory.HashKey, factory.EncryptKey); err == nil {
+			session.load(cookie.Value)
+		}
+	}
+	return session
+}
+
+// writeCookie writes the signed session id cookie
+func (factory *StoreSessionFactory) writeCookie(w http.ResponseWriter, id string) error {
+	cookie, err := NewSecureCookie(&http.Cookie{
+		Name:   SESSIONKEY,
+		Value:  id,
+		Path:   factory.Path,
+		Domain: factory.Domain,
+		MaxAge: factory.MaxAge,
+		Secure: factory.Secure,
+	}, factory.HashKey, factory.EncryptKey)
+	if err != nil {
+		return err
+	}
+	cookie.HttpOnly = factory.HttpOnly
+	http.SetCookie(w, cookie)
+	return nil
+}
+
+// newStoreSession returns an empty StoreSession backed by store.
+// The caller sets how the session id reaches the client
+func newStoreSession(store Store, ttl time.Duration, onConflict ConflictFunc, hooks *Hooks) *StoreSession {
+	return &StoreSession{
+		data:       make(map[interface{}]interface{}),
+		base:       make(map[interface{}]interface{}),
+		store:      store,
+		ttl:        ttl,
+		onConflict: onConflict,
+		hooks:      hooks,
+		mutex:      &sync.RWMutex{},
+	}
+}
+
+// load loads the stored session with id into the session. The
+// session stays empty if none is stored. Revoked sessions are
+// deleted from the store
+func (s *StoreSession) load(id string) {
+	rec, err := s.store.Load(id)
+	if err != nil {
+		return
+	}
+	if s.revocations.Revoked(rec.Id, rec.Data, rec.Created) {
+		if s.store.Delete(rec.Id) == nil && s.hooks != nil && s.hooks.OnDestroy != nil {
+			s.hooks.OnDestroy(rec.Id, rec.Data)
+		}
+		return
+	}
+	s.id = rec.Id
+	s.data = copyData(rec.Data)
+	s.base = rec.Data
+	s.version = rec.Version
+	s.created = rec.Created
+}
+
+// newSessionId returns a random 256-bit hex encoded session id
+func newSessionId() (string, error) {
+	b := make([]byte, 32)
+	if _, err := io.ReadFull(rand.Reader, b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,137 @@
+package session
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestMemoryStore(t *testing.T) {
+	defer func() {
+		err := recover()
+		if err != nil {
+			t.Errorf(err.(error).Error())
+		}
+	}()
+
+	err := "Failed memory store."
+
+	store := NewMemoryStore()
+	rec := &Record{Id: "a", Data: map[interface{}]interface{}{"k": 1}}
+	if store.Save(rec) != nil || rec.Version != 1 {
+		t.Fatalf(err)
+	}
+
+	stale := &Record{Id: "a", Data: map[interface{}]interface{}{"k": 2}}
+	if store.Save(stale) != ErrConflict {
+		t.Errorf(err)
+	}
+	if store.Save(rec) != nil || rec.Version != 2 {
+		t.Errorf(err)
+	}
+
+	loaded, e := store.Load("a")
+	if e != nil || loaded.Version != 2 || loaded.Data["k"] != 1 {
+		t.Errorf(err)
+	}
+	store.Delete("a")
+	if _, e = store.Load("a"); e != ErrNotFound {
+		t.Errorf(err)
+	}
+}
+
+func TestStoreSessionConcurrentFlush(t *testing.T) {
+	defer func() {
+		err := recover()
+		if err != nil {
+			t.Errorf(err.(error).Error())
+		}
+	}()
+
+	err := "Failed store session concurrent flush."
+
+	factory := &StoreSessionFactory{
+		Store:   NewMemoryStore(),
+		HashKey: []byte("hash"),
+	}
+
+	// Create the session
+	r, _ := http.NewRequest("GET", "http://test.com", nil)
+	w := httptest.NewRecorder()
+	s := factory.Create(w, r)
+	s.Set("user", "bob")
+	s.Set("cart", 1)
+	if s.Flush() != nil {
+		t.Fatalf(err)
+	}
+	cookie := w.Result().Cookies()[0]
+
+	// Two requests sharing the session modify different keys
+	load := func() Session {
+		r, _ := http.NewRequest("GET", "http://test.com", nil)
+		r.AddCookie(cookie)
+		return factory.Create(httptest.NewRecorder(), r)
+	}
+	s1 := load()
+	s2 := load()
+	if s1.Get("user") != "bob" || s2.Get("cart") != 1 {
+		t.Fatalf(err)
+	}
+	s1.Set("theme", "dark")
+	s2.Set("cart", 2)
+	s2.Del("user")
+	if s1.Flush() != nil || s2.Flush() != nil {
+		t.Fatalf(err)
+	}
+
+	s3 := load()
+	if s3.Get("theme") != "dark" || s3.Get("cart") != 2 || s3.Get("user") != nil {
+		t.Errorf(err)
+	}
+
+	// Test conflict hook rejecting the flush
+	factory.OnConflict = func(base, local, remote map[interface{}]interface{}) (map[interface{}]interface{}, error) {
+		return nil, ErrConflict
+	}
+	s4 := load()
+	s3.Set("cart", 3)
+	s4.Set("cart", 4)
+	if s3.Flush() != nil || s4.Flush() != ErrConflict {
+		t.Errorf(err)
+	}
+	if load().Get("cart") != 3 {
+		t.Errorf(err)
+	}
+
+	// Test tampered cookies start a new session
+	r, _ = http.NewRequest("GET", "http://test.com", nil)
+	r.AddCookie(&http.Cookie{Name: SESSIONKEY, Value: "forged"})
+	if factory.Create(httptest.NewRecorder(), r).Get("cart") != nil {
+		t.Errorf(err)
+	}
+}
+
+func TestSecureCookieSeparatorInHMAC(t *testing.T) {
+	defer func() {
+		err := recover()
+		if err != nil {
+			t.Errorf(err.(error).Error())
+		}
+	}()
+
+	err := "Failed secure cookie with separator in HMAC."
+
+	// Raw HMACs contain the separator for about
+	// one in eight values
+	for i := 0; i < 200; i++ {
+		value := strings.Repeat("v", i)
+		secure, e := NewSecureCookie(&http.Cookie{Name: SESSIONKEY, Value: value}, []byte("hash"), nil)
+		if e != nil {
+			t.Fatalf(err)
+		}
+		if plain, e := DecryptCookie(secure, []byte("hash"), nil); e != nil || plain.Value != value {
+			t.Fatalf(err)
+		}
+	}
+}
@@ -0,0 +1,301 @@
+package session
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// TOKENKEY is the name under which session tokens are signed.
+// It differs from SESSIONKEY so that session cookies cannot be
+// replayed as tokens and vice versa
+const TOKENKEY = "_VertoToken"
+
+// Default headers used by TokenSessionFactory
+const (
+	DefaultTokenHeader         = "Authorization"
+	DefaultTokenResponseHeader = "X-Session-Token"
+)
+
+// bearerPrefix is the authorization scheme expected
+// for tokens carried in the Authorization header
+const bearerPrefix = "Bearer "
+
+// tokenPayload is the signed content of a stateless session token
+type tokenPayload struct {
+	Id      string                 `json:"j"`
+	Issued  int64                  `json:"i"`
+	Data    map[string]interface{} `json:"d"`
+	Expires int64                  `json:"e,omitempty"`
+}
+
+// TokenSession is an implementation of the Session interface carrying
+// all session data in a signed token instead of a cookie. Session data
+// is serialized as JSON so keys are restored as strings. TokenSession
+// is thread safe
+type TokenSession struct {
+	id      string
+	issued  time.Time
+	data    map[interface{}]interface{}
+	factory *TokenSessionFactory
+	mutex   *sync.RWMutex
+	w       http.ResponseWriter
+}
+
+// Id returns the session id or the empty string
+// if the session has not been flushed yet
+func (s *TokenSession) Id() string {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	return s.id
+}
+
+// Get retrieves the data associated with the key
+// or nil if no such association exists
+func (s *TokenSession) Get(key interface{}) interface{} {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	return s.data[key]
+}
+
+// Set sets a key-value association for the session instance.
+// If a previous association exists, it is overwritten
+func (s *TokenSession) Set(key, value interface{}) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.data[key] = value
+}
+
+// Del deletes a key-value association from the session instance
+func (s *TokenSession) Del(key interface{}) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	delete(s.data, key)
+}
+
+// Clear clears all data from the session instance
+func (s *TokenSession) Clear() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.data = make(map[interface{}]interface{})
+}
+
+// Flush encodes the session data into a fresh token and writes it to
+// the response header of the TokenSessionFactory that spawned the
+// session. If there is no data in the session instance, the response
+// header is set to an empty value telling the client to discard its
+// token. Each flush restarts the TTL of the token
+func (s *TokenSession) Flush() error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if len(s.data) == 0 {
+		s.id = ""
+		s.factory.expire(s.w)
+		return nil
+	}
+
+	// Ids identify stateless sessions for revocation
+	if s.id == "" {
+		id, e := newSessionId()
+		if e != nil {
+			return e
+		}
+		s.id = id
+		s.issued = s.factory.now()
+	}
+
+	payload := tokenPayload{
+		Id:     s.id,
+		Issued: s.issued.UnixNano(),
+		Data:   make(map[string]interface{}, len(s.data)),
+	}
+	for k, v := range s.data {
+		payload.Data[fmt.Sprint(k)] = v
+	}
+	if s.factory.TTL > 0 {
+		payload.Expires = s.factory.now().Add(s.factory.TTL).Unix()
+	}
+	m, e := json.Marshal(payload)
+	if e != nil {
+		return e
+	}
+	return s.factory.writeToken(s.w, string(m))
+}
+
+// TokenSessionFactory is an implementation of Factory for clients that
+// do not handle cookies, such as mobile apps and API consumers. The
+// session is carried in a token read from a request header and written
+// to a response header, using the same signing and encryption as secure
+// cookies.
+//
+// Without a Store, all session data is kept in the token. Such tokens
+// are stateless and cannot be revoked before they expire, so TTL should
+// be kept short. With a Store, the token only carries the session id and
+// sessions behave like those of a StoreSessionFactory.
+//
+// Example usage:
+//
+//	factory := &session.TokenSessionFactory{
+//		HashKey: hashKey,
+//		TTL:     time.Hour,
+//	}
+//
+// Clients send the token back as "Authorization: Bearer <token>".
+type TokenSessionFactory struct {
+	// HashKey used to create an HMAC for the token.
+	// This field is required.
+	HashKey []byte
+
+	// EncryptKey is an optional key used to encrypt the token.
+	// If no EncryptKey is provided, the token is signed but its
+	// content is readable by the client
+	EncryptKey []byte
+
+	// Header is the request header carrying the token. Defaults
+	// to Authorization, in which case the token is expected with
+	// the Bearer scheme. Other headers carry the bare token
+	Header string
+
+	// ResponseHeader is the response header the token is written
+	// to on Flush. Defaults to X-Session-Token
+	ResponseHeader string
+
+	// TTL is the lifetime of a token, or of the stored session if
+	// Store is set, after its last flush. Tokens do not expire
+	// if TTL is zero
+	TTL time.Duration
+
+	// Store is an optional server-side store for session data.
+	// If set, tokens only carry the session id
+	Store Store
+
+	// OnConflict resolves concurrent modifications of stored
+	// sessions. Defaults to Merge. It is unused without a Store
+	OnConflict ConflictFunc
+
+	// Hooks are optional callbacks for the lifecycle of
+	// stored sessions. They are unused without a Store
+	Hooks *Hooks
+
+	// Revocations are optionally consulted before trusting
+	// a presented token. As stateless tokens cannot be deleted,
+	// revocation is the only way to end them before they expire
+	Revocations *Revocations
+
+	// Now returns the current time. Defaults to time.Now
+	Now func() time.Time
+}
+
+// Create instantiates a Session from the token carried by the passed
+// in http.Request. If the token is missing, invalid or expired the
+// session data will be empty. Token validation failures are not
+// reported so that a bad token behaves like a new session
+func (factory *TokenSessionFactory) Create(w http.ResponseWriter, r *http.Request) Session {
+	value := factory.readToken(r)
+
+	if factory.Store != nil {
+		session := newStoreSession(factory.Store, factory.TTL, factory.OnConflict, factory.Hooks)
+		session.revocations = factory.Revocations
+		session.write = func(id string) error {
+			return factory.writeToken(w, id)
+		}
+		session.expire = func() {
+			factory.expire(w)
+		}
+		if value != "" {
+			session.load(value)
+		}
+		return session
+	}
+
+	session := &TokenSession{
+		data:    make(map[interface{}]interface{}),
+		factory: factory,
+		mutex:   &sync.RWMutex{},
+		w:       w,
+	}
+	if value != "" {
+		var payload tokenPayload
+		if json.Unmarshal([]byte(value), &payload) == nil &&
+			(payload.Expires == 0 || factory.now().Unix() < payload.Expires) {
+			data := make(map[interface{}]interface{}, len(payload.Data))
+			for k, v := range payload.Data {
+				data[k] = v
+			}
+			issued := time.Unix(0, payload.Issued)
+			if !factory.Revocations.Revoked(payload.Id, data, issued) {
+				session.id = payload.Id
+				session.issued = issued
+				session.data = data
+			}
+		}
+	}
+	return session
+}
+
+// readToken returns the verified value of the token carried
+// by r or an empty string if there is no valid token
+func (factory *TokenSessionFactory) readToken(r *http.Request) string {
+	token := r.Header.Get(factory.header())
+	if factory.header() == DefaultTokenHeader {
+		if len(token) < len(bearerPrefix) || !strings.EqualFold(token[:len(bearerPrefix)], bearerPrefix) {
+			return ""
+		}
+		token = token[len(bearerPrefix):]
+	}
+	token = strings.TrimSpace(token)
+	if token == "" {
+		return ""
+	}
+	cookie, err := DecryptCookie(&http.Cookie{Name: TOKENKEY, Value: token}, factory.HashKey, factory.EncryptKey)
+	if err != nil {
+		return ""
+	}
+	return cookie.Value
+}
+
+// writeToken signs and optionally encrypts value and
+// writes the resulting token to the response header
+func (factory *TokenSessionFactory) writeToken(w http.ResponseWriter, value string) error {
+	token, err := NewSecureCookie(&http.Cookie{Name: TOKENKEY, Value: value}, factory.HashKey, factory.EncryptKey)
+	if err != nil {
+		return err
+	}
+	w.Header().Set(factory.responseHeader(), token.Value)
+	return nil
+}
+
+// expire tells the client to discard its token
+func (factory *TokenSessionFactory) expire(w http.ResponseWriter) {
+	w.Header().Set(factory.responseHeader(), "")
+}
+
+func (factory *TokenSessionFactory) header() string {
+	if factory.Header == "" {
+		return DefaultTokenHeader
+	}
+	return factory.Header
+}
+
+func (factory *TokenSessionFactory) responseHeader() string {
+	if factory.ResponseHeader == "" {
+		return DefaultTokenResponseHeader
+	}
+	return factory.ResponseHeader
+}
+
+func (factory *TokenSessionFactory) now() time.Time {
+	if factory.Now == nil {
+		return time.Now()
+	}
+	return factory.Now()
+}
@@ -0,0 +1,140 @@
+package session
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestTokenSession(t *testing.T) {
+	defer func() {
+		err := recover()
+		if err != nil {
+			t.Errorf(err.(error).Error())
+		}
+	}()
+
+	err := "Failed token session."
+
+	now := time.Unix(1000, 0)
+	factory := &TokenSessionFactory{
+		HashKey:    []byte("hash"),
+		EncryptKey: []byte("0123456789abcdef"),
+		TTL:        time.Minute,
+		Now:        func() time.Time { return now },
+	}
+
+	// flush runs fn on the session of a request carrying
+	// the authorization header auth and returns the token
+	// written to the response
+	flush := func(auth string, fn func(s Session)) (string, bool, Session) {
+		r, _ := http.NewRequest("GET", "http://test.com", nil)
+		if auth != "" {
+			r.Header.Set("Authorization", auth)
+		}
+		w := httptest.NewRecorder()
+		s := factory.Create(w, r)
+		fn(s)
+		if e := s.Flush(); e != nil {
+			t.Fatalf(err)
+		}
+		token, ok := w.HeaderMap[DefaultTokenResponseHeader]
+		if !ok {
+			return "", false, s
+		}
+		return token[0], true, s
+	}
+
+	// Test data round trips through the token
+	token, ok, _ := flush("", func(s Session) { s.Set("user", "bob") })
+	if !ok || token == "" {
+		t.Fatalf(err)
+	}
+	_, _, s := flush("Bearer "+token, func(s Session) {})
+	if s.Get("user") != "bob" {
+		t.Errorf(err)
+	}
+
+	// Test missing scheme, tampered and cookie signed values are rejected
+	_, _, s = flush(token, func(s Session) {})
+	if s.Get("user") != nil {
+		t.Errorf(err)
+	}
+	_, _, s = flush("Bearer x"+token, func(s Session) {})
+	if s.Get("user") != nil {
+		t.Errorf(err)
+	}
+	cookie, _ := NewSecureCookie(&http.Cookie{Name: SESSIONKEY, Value: `{"d":{"user":"eve"}}`}, factory.HashKey, nil)
+	_, _, s = flush("Bearer "+cookie.Value, func(s Session) {})
+	if s.Get("user") != nil {
+		t.Errorf(err)
+	}
+
+	// Test tokens expire after TTL
+	now = now.Add(2 * time.Minute)
+	_, _, s = flush("Bearer "+token, func(s Session) {})
+	if s.Get("user") != nil {
+		t.Errorf(err)
+	}
+
+	// Test clearing the session tells the client to discard the token
+	token, _, _ = flush("", func(s Session) { s.Set("user", "bob") })
+	token, ok, _ = flush("Bearer "+token, func(s Session) { s.Clear() })
+	if !ok || token != "" {
+		t.Errorf(err)
+	}
+}
+
+func TestTokenSessionStore(t *testing.T) {
+	defer func() {
+		err := recover()
+		if err != nil {
+			t.Errorf(err.(error).Error())
+		}
+	}()
+
+	err := "Failed token session store."
+
+	store := NewMemoryStore()
+	factory := &TokenSessionFactory{
+		HashKey:        []byte("hash"),
+		Header:         "X-Session",
+		ResponseHeader: "X-Session",
+		Store:          store,
+	}
+
+	r, _ := http.NewRequest("GET", "http://test.com", nil)
+	w := httptest.NewRecorder()
+	s := factory.Create(w, r)
+	s.Set("user", "bob")
+	if e := s.Flush(); e != nil {
+		t.Fatalf(err)
+	}
+	token := w.Header().Get("X-Session")
+	if token == "" {
+		t.Fatalf(err)
+	}
+
+	// Test the token carries only the session id
+	r, _ = http.NewRequest("GET", "http://test.com", nil)
+	r.Header.Set("X-Session", token)
+	w = httptest.NewRecorder()
+	s = factory.Create(w, r)
+	if s.Get("user") != "bob" {
+		t.Errorf(err)
+	}
+	id := s.(*StoreSession).id
+	if v, e := DecryptCookie(&http.Cookie{Name: TOKENKEY, Value: token}, factory.HashKey, nil); e != nil || v.Value != id {
+		t.Errorf(err)
+	}
+
+	// Test clearing deletes the stored session
+	s.Clear()
+	if e := s.Flush(); e != nil {
+		t.Fatalf(err)
+	}
+	if _, e := store.Load(id); e != ErrNotFound {
+		t.Errorf(err)
+	}
+}
@@ -0,0 +1,193 @@
+package verto
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"github.com/boxtown/verto/mux"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+)
+
+// MaxShadowBody is the maximum size of request and response bodies
+// buffered for shadow comparison. Requests with larger bodies are not
+// mirrored and larger responses are only compared by status
+const MaxShadowBody = 1 << 20
+
+// ShadowHeader is set on mirrored requests served to shadow handlers
+const ShadowHeader = "X-Verto-Shadow"
+
+// ShadowDiffEvent is the topic ShadowDiffs are published under
+// on the Verto instance's EventBus
+const ShadowDiffEvent = "verto.shadow.diff"
+
+// ShadowDiff describes a difference between the response of a
+// route and the response of its shadow handler
+type ShadowDiff struct {
+	// Method and Path identify the mirrored request
+	Method string
+	Path   string
+
+	// Status and ShadowStatus are the response statuses
+	Status       int
+	ShadowStatus int
+
+	// Offset is the offset of the first differing body byte
+	// or -1 if the bodies are equal or were not compared
+	Offset int
+}
+
+func (d *ShadowDiff) String() string {
+	return fmt.Sprintf("%s %s: status %d, shadow status %d, body differs at %d",
+		d.Method, d.Path, d.Status, d.ShadowStatus, d.Offset)
+}
+
+// Shadow mirrors requests for the route represented by the Endpoint to
+// rf after the route has responded. The shadow runs fire-and-forget in its
+// own goroutine, its response is discarded and differences from the route's
+// response are logged as warnings and published under ShadowDiffEvent.
+// Shadows validate rewrites against production traffic without affecting
+// clients. Shadows must not have side effects the route does not expect
+// (e.g. writing to the same database twice); mirrored requests carry
+// the ShadowHeader so that shared code can tell them apart.
+//
+// Example usage:
+//
+//	v.Get("/search", search).Shadow(searchV2)
+func (ep *Endpoint) Shadow(rf ResourceFunc) *Endpoint {
+	return ep.ShadowHandler(ep.v.resource(rf))
+}
+
+// ShadowHandler is like Shadow but mirrors requests to an http.Handler
+func (ep *Endpoint) ShadowHandler(shadow http.Handler) *Endpoint {
+	v := ep.v
+	handler := func(w http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+		body, ok := bufferBody(r)
+		if !ok {
+			next(w, r)
+			return
+		}
+
+		tw := &teeWriter{ResponseWriter: w}
+		next(tw, r)
+
+		// Detach the mirrored request from the client's request
+		// so that it is not canceled once the response completes.
+		// The route, its metadata and path params are kept
+		mirror := r.WithContext(context.WithoutCancel(r.Context()))
+		mirror.Header = cloneHeader(r.Header)
+		mirror.Header.Set(ShadowHeader, "1")
+		mirror.Body = ioutil.NopCloser(bytes.NewReader(body))
+		go v.serveShadow(shadow, mirror, tw)
+	}
+	return ep.UsePluginHandler(mux.PluginFunc(handler))
+}
+
+// serveShadow serves the mirrored request r to shadow and
+// compares its response to the recorded primary response
+func (v *Verto) serveShadow(shadow http.Handler, r *http.Request, primary *teeWriter) {
+	defer func() {
+		if rMsg := recover(); rMsg != nil && v.Logger != nil {
+			v.Logger.Errorf("shadow: %s %s panicked: %v", r.Method, r.URL.Path, rMsg)
+		}
+	}()
+
+	w := httptest.NewRecorder()
+	r = v.withInjections(w, r)
+
+	shadow.ServeHTTP(w, r)
+
+	diff := &ShadowDiff{
+		Method:       r.Method,
+		Path:         r.URL.Path,
+		Status:       primary.status(),
+		ShadowStatus: w.Code,
+		Offset:       -1,
+	}
+	if !primary.truncated && w.Body.Len() <= MaxShadowBody {
+		a, b := primary.body.Bytes(), w.Body.Bytes()
+		for i := 0; i < len(a) || i < len(b); i++ {
+			if i >= len(a) || i >= len(b) || a[i] != b[i] {
+				diff.Offset = i
+				break
+			}
+		}
+	}
+	if diff.Status == diff.ShadowStatus && diff.Offset < 0 {
+		return
+	}
+	if v.Logger != nil {
+		v.Logger.Warnf("shadow: %s", diff.String())
+	}
+	if v.Events != nil {
+		v.Events.Publish(ShadowDiffEvent, diff)
+	}
+}
+
+// bufferBody reads the body of r into memory and replaces it with
+// a reader over the buffered bytes. Returns false if the body is
+// larger than MaxShadowBody
+func bufferBody(r *http.Request) ([]byte, bool) {
+	if r.Body == nil || r.Body == http.NoBody {
+		return nil, true
+	}
+	body, err := ioutil.ReadAll(io.LimitReader(r.Body, MaxShadowBody+1))
+	r.Body = ioutil.NopCloser(io.MultiReader(bytes.NewReader(body), r.Body))
+	if err != nil || len(body) > MaxShadowBody {
+		return nil, false
+	}
+	return body, true
+}
+
+func cloneHeader(h http.Header) http.Header {
+	copied := make(http.Header, len(h))
+	for k, vs := range h {
+		copied[k] = append([]string(nil), vs...)
+	}
+	return copied
+}
+
+// teeWriter is an http.ResponseWriter that records the
+// status and up to MaxShadowBody bytes of the response
+type teeWriter struct {
+	http.ResponseWriter
+
+	code      int
+	body      bytes.Buffer
+	truncated bool
+}
+
+func (w *teeWriter) WriteHeader(code int) {
+	if w.code == 0 {
+		w.code = code
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *teeWriter) Write(b []byte) (int, error) {
+	if w.code == 0 {
+		w.code = http.StatusOK
+	}
+	if w.body.Len()+len(b) > MaxShadowBody {
+		w.truncated = true
+	} else if !w.truncated {
+		w.body.Write(b)
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *teeWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// status returns the recorded status
+func (w *teeWriter) status() int {
+	if w.code == 0 {
+		return http.StatusOK
+	}
+	return w.code
+}
@@ -0,0 +1,130 @@
+package verto
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestShadow(t *testing.T) {
+	defer func() {
+		err := recover()
+		if err != nil {
+			t.Errorf(err.(error).Error())
+		}
+	}()
+
+	err := "Failed shadow."
+
+	v := New()
+	v.Logger = &NilLogger{}
+	v.Injections.Set("db", "db")
+
+	diffs := make(chan *ShadowDiff, 2)
+	v.Events.Subscribe(ShadowDiffEvent, func(e Event) {
+		diffs <- e.Data.(*ShadowDiff)
+	})
+	shadowed := make(chan string, 2)
+	v.Post("/echo", func(c *Context) (interface{}, error) {
+		b, _ := ioutil.ReadAll(c.Request.Body)
+		return string(b), nil
+	}).Shadow(func(c *Context) (interface{}, error) {
+		b, _ := ioutil.ReadAll(c.Request.Body)
+		body := strings.ToUpper(string(b))
+		if c.Injections().Get("db") != "db" || c.Request.Header.Get(ShadowHeader) != "1" {
+			body = "bad shadow"
+		}
+		shadowed <- body
+		return body, nil
+	})
+	h := &HttpHandler{v}
+
+	serve := func(body string) *httptest.ResponseRecorder {
+		r, _ := http.NewRequest("POST", "http://test.com/echo", strings.NewReader(body))
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		return w
+	}
+
+	wait := func() string {
+		select {
+		case body := <-shadowed:
+			return body
+		case <-time.After(time.Second):
+			return ""
+		}
+	}
+
+	// Test matching responses produce no diff
+	if serve("ABC").Body.String() != "ABC" || wait() != "ABC" {
+		t.Errorf(err)
+	}
+
+	// Test client responses are unaffected and diffs are published
+	if serve("abc").Body.String() != "abc" || wait() != "ABC" {
+		t.Errorf(err)
+	}
+	select {
+	case d := <-diffs:
+		if d.Offset != 0 || d.Status != 200 || d.ShadowStatus != 200 || d.Path != "/echo" {
+			t.Errorf(err)
+		}
+	case <-time.After(time.Second):
+		t.Errorf(err)
+	}
+	if len(diffs) != 0 {
+		t.Errorf(err)
+	}
+}
+
+func TestShadowParams(t *testing.T) {
+	defer func() {
+		err := recover()
+		if err != nil {
+			t.Errorf(err.(error).Error())
+		}
+	}()
+
+	err := "Failed shadow with params."
+
+	v := New()
+	v.Logger = &NilLogger{}
+
+	diffs := make(chan *ShadowDiff, 1)
+	v.Events.Subscribe(ShadowDiffEvent, func(e Event) {
+		diffs <- e.Data.(*ShadowDiff)
+	})
+	shadowed := make(chan string, 1)
+	resource := func(c *Context) (interface{}, error) {
+		return c.Param("id") + ":" + c.RouteName(), nil
+	}
+	v.Get("/users/{id}", resource).Name("user").Shadow(func(c *Context) (interface{}, error) {
+		body, e := resource(c)
+		shadowed <- body.(string)
+		return body, e
+	})
+	h := &HttpHandler{v}
+
+	r, _ := http.NewRequest("GET", "http://test.com/users/7", nil)
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	if w.Body.String() != "7:user" {
+		t.Errorf(err)
+	}
+	select {
+	case body := <-shadowed:
+		if body != "7:user" {
+			t.Errorf(err)
+		}
+	case <-time.After(time.Second):
+		t.Errorf(err)
+	}
+	select {
+	case <-diffs:
+		t.Errorf(err)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
@@ -0,0 +1,164 @@
+package verto
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+)
+
+// Events published by Verto when it becomes ready to serve,
+// when its startup smoke tests fail and when it stopped serving
+const (
+	ReadyEvent           = "verto.ready"
+	SmokeTestFailedEvent = "verto.smoketest.failed"
+	StoppedEvent         = "verto.stopped"
+)
+
+// SmokeTestHeader is set on in-process smoke test requests
+const SmokeTestHeader = "X-Verto-Smoke-Test"
+
+// exit is os.Exit, replaced in tests
+var exit = os.Exit
+
+// SmokeTestFailure describes a failed smoke test request
+type SmokeTestFailure struct {
+	// Route is the smoke tested route
+	Route string
+
+	// Status is the response status
+	Status int
+
+	// Body is the response body
+	Body string
+}
+
+// SmokeTestError is returned by RunSmokeTests if any smoke test failed
+type SmokeTestError struct {
+	Failures []SmokeTestFailure
+}
+
+func (err *SmokeTestError) Error() string {
+	msgs := make([]string, len(err.Failures))
+	for i, f := range err.Failures {
+		msgs[i] = fmt.Sprintf("%s: %d %s", f.Route, f.Status, strings.TrimSpace(f.Body))
+	}
+	return "smoke tests failed: " + strings.Join(msgs, "; ")
+}
+
+// SmokeTest registers routes to be requested in-process after the
+// listener binds in Run and RunOn. Routes are given as "METHOD /path"
+// or as "/path" for GET requests. Verto only reports ready once every
+// route responded with a status below 400, catching missing injections
+// or bad configuration before traffic arrives. If ExitOnSmokeTestFailure
+// is set, the process exits with status 1 when a smoke test fails.
+//
+// Example usage:
+//
+//	v.SmokeTest("/health", "GET /users/1")
+//	v.GetHandler("/ready", v.ReadinessHandler())
+//	v.Run()
+func (v *Verto) SmokeTest(routes ...string) {
+	v.mutex.Lock()
+	defer v.mutex.Unlock()
+
+	v.smokeTests = append(v.smokeTests, routes...)
+}
+
+// RunSmokeTests issues in-process requests against the routes registered
+// through SmokeTest and returns a *SmokeTestError if any of them failed.
+// Requests carry the SmokeTestHeader and originate from the loopback address
+func (v *Verto) RunSmokeTests() error {
+	v.mutex.RLock()
+	routes := append([]string(nil), v.smokeTests...)
+	v.mutex.RUnlock()
+
+	failures := make([]SmokeTestFailure, 0)
+	for _, route := range routes {
+		method, path := "GET", route
+		if i := strings.IndexByte(route, ' '); i >= 0 {
+			method, path = route[:i], strings.TrimSpace(route[i+1:])
+		}
+		r, err := http.NewRequest(method, path, nil)
+		if err != nil {
+			failures = append(failures, SmokeTestFailure{Route: route, Body: err.Error()})
+			continue
+		}
+		r.RemoteAddr = "127.0.0.1:0"
+		r.Header.Set(SmokeTestHeader, "1")
+
+		w := httptest.NewRecorder()
+		v.serveSmokeTest(w, r)
+		if w.Code >= 400 {
+			failures = append(failures, SmokeTestFailure{Route: route, Status: w.Code, Body: w.Body.String()})
+		}
+	}
+	if len(failures) > 0 {
+		return &SmokeTestError{failures}
+	}
+	return nil
+}
+
+// Ready returns whether Verto is serving, passed
+// its startup smoke tests and is not draining
+func (v *Verto) Ready() bool {
+	v.mutex.RLock()
+	defer v.mutex.RUnlock()
+
+	return v.ready && !v.ops.draining
+}
+
+// ReadinessHandler returns an http.Handler responding with 200 if
+// Verto is ready and with 503 otherwise, suitable as a readiness probe
+func (v *Verto) ReadinessHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !v.Ready() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			fmt.Fprint(w, "Service Unavailable.")
+			return
+		}
+		fmt.Fprint(w, "Ready.")
+	})
+}
+
+// serveSmokeTest serves r like a top-level request recovering
+// from panics so that a broken route fails its smoke test
+// instead of taking the server down
+func (v *Verto) serveSmokeTest(w *httptest.ResponseRecorder, r *http.Request) {
+	defer func() {
+		if rMsg := recover(); rMsg != nil {
+			w.Code = http.StatusInternalServerError
+			fmt.Fprint(w.Body, rMsg)
+		}
+	}()
+	v.guard(0, w, r)
+}
+
+// startup runs the smoke tests once the listener is bound
+// and marks Verto ready if they pass
+func (v *Verto) startup() {
+	if err := v.RunSmokeTests(); err != nil {
+		if v.Logger != nil {
+			v.Logger.Errorf("verto: %s", err.Error())
+		}
+		if v.Events != nil {
+			v.Events.Publish(SmokeTestFailedEvent, err)
+		}
+		if v.ExitOnSmokeTestFailure {
+			exit(1)
+		}
+		return
+	}
+	v.setReady(true)
+	if v.Events != nil {
+		v.Events.Publish(ReadyEvent, nil)
+	}
+}
+
+func (v *Verto) setReady(ready bool) {
+	v.mutex.Lock()
+	defer v.mutex.Unlock()
+
+	v.ready = ready
+}
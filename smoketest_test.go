@@ -0,0 +1,103 @@
+package verto
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+)
+
+func TestSmokeTest(t *testing.T) {
+	defer func() {
+		err := recover()
+		if err != nil {
+			t.Errorf(err.(error).Error())
+		}
+	}()
+
+	err := "Failed smoke test."
+
+	v := New()
+	v.Logger = &NilLogger{}
+	healthy := false
+	smoked := false
+	v.Get("/health", func(c *Context) (interface{}, error) {
+		smoked = c.Request.Header.Get(SmokeTestHeader) == "1"
+		if !healthy {
+			return nil, errors.New("no database")
+		}
+		return "ok", nil
+	})
+	v.Post("/warm", func(c *Context) (interface{}, error) {
+		return "warm", nil
+	})
+	v.Get("/panic", func(c *Context) (interface{}, error) {
+		panic("boom")
+	})
+	v.GetHandler("/ready", v.ReadinessHandler())
+	h := &HttpHandler{v}
+
+	ready := func() int {
+		r, _ := http.NewRequest("GET", "http://test.com/ready", nil)
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		return w.Code
+	}
+
+	v.SmokeTest("/health", "POST /warm")
+	var published interface{}
+	v.Events.Subscribe(SmokeTestFailedEvent, func(e Event) { published = e.Data })
+
+	exited := 0
+	exit = func(code int) { exited = code }
+	defer func() { exit = os.Exit }()
+	v.ExitOnSmokeTestFailure = true
+
+	v.startup()
+	if !smoked || v.Ready() || ready() != 503 || exited != 1 {
+		t.Errorf(err)
+	}
+	if e, ok := published.(*SmokeTestError); !ok || len(e.Failures) != 1 || e.Failures[0].Route != "/health" || e.Failures[0].Status != 500 {
+		t.Errorf(err)
+	}
+
+	healthy = true
+	v.startup()
+	if !v.Ready() || ready() != 200 {
+		t.Errorf(err)
+	}
+
+	// Test panicking routes fail instead of crashing
+	v.SmokeTest("/panic")
+	if e, ok := v.RunSmokeTests().(*SmokeTestError); !ok || len(e.Failures) != 1 || e.Failures[0].Body != "boom" {
+		t.Errorf(err)
+	}
+}
+
+func TestSmokeTestGuards(t *testing.T) {
+	defer func() {
+		err := recover()
+		if err != nil {
+			t.Errorf(err.(error).Error())
+		}
+	}()
+
+	err := "Failed smoke test guards."
+
+	v := New()
+	v.Logger = &NilLogger{}
+	guarded := false
+	v.Guard(PluginFunc(func(c *Context, next http.HandlerFunc) {
+		guarded = c.Request.Header.Get(SmokeTestHeader) == "1"
+		next(c.Response, c.Request)
+	}))
+	v.Get("/health", func(c *Context) (interface{}, error) {
+		return "ok", nil
+	})
+	v.SmokeTest("/health")
+
+	if v.RunSmokeTests() != nil || !guarded {
+		t.Errorf(err)
+	}
+}
@@ -0,0 +1,129 @@
+package verto
+
+import (
+	"context"
+	"github.com/boxtown/verto/mux"
+	"hash/fnv"
+	"net/http"
+	"sort"
+)
+
+// VariantHeader is the response header tagging responses
+// of split routes with the variant that served them
+const VariantHeader = "X-Variant"
+
+// variantKey is the request context key of the selected variant
+type variantKey struct{}
+
+// variantHandlerKey is the request context key of the
+// handler replacing the handler of a split route
+type variantHandlerKey struct{}
+
+// variantHandler is the handler of the variant selected
+// for a request to route
+type variantHandler struct {
+	route   mux.Route
+	handler http.Handler
+}
+
+// Variant is an alternate implementation of a split route
+type Variant struct {
+	// Weight is the relative share of traffic routed to the variant
+	Weight int
+
+	// Resource handles requests routed to the variant. If nil,
+	// requests are handled by the route's own handler
+	Resource ResourceFunc
+}
+
+// Split routes traffic for the route represented by the Endpoint to
+// one of the named variants in proportion to their weights. Clients are
+// assigned to variants deterministically by the key returned by Verto's
+// ClientKey function, falling back to the client IP, so that a client
+// keeps seeing the same variant. Responses are tagged with the variant
+// in the VariantHeader and handlers can read it through Context.Variant.
+//
+// Example usage:
+//
+//	v.Get("/checkout", checkout).Split(map[string]verto.Variant{
+//		"control":  {Weight: 90},
+//		"one-page": {Weight: 10, Resource: onePageCheckout},
+//	})
+func (ep *Endpoint) Split(variants map[string]Variant) *Endpoint {
+	v := ep.v
+	path := ep.Route().Path()
+
+	// Order variants by name so that assignments are stable
+	names := make([]string, 0, len(variants))
+	total := 0
+	for name, variant := range variants {
+		if variant.Weight > 0 {
+			names = append(names, name)
+			total += variant.Weight
+		}
+	}
+	sort.Strings(names)
+
+	handlers := make(map[string]http.Handler, len(names))
+	for _, name := range names {
+		if rf := variants[name].Resource; rf != nil {
+			handlers[name] = v.resource(rf)
+		}
+	}
+
+	handler := func(w http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+		if total == 0 {
+			next(w, r)
+			return
+		}
+		key := GetIP(r)
+		if v.ClientKey != nil {
+			key = v.ClientKey(r)
+		}
+		h := fnv.New32a()
+		h.Write([]byte(path + "\x00" + key))
+		bucket := int(h.Sum32() % uint32(total))
+
+		name := names[len(names)-1]
+		for _, n := range names {
+			if bucket < variants[n].Weight {
+				name = n
+				break
+			}
+			bucket -= variants[n].Weight
+		}
+
+		w.Header().Set(VariantHeader, name)
+		ctx := context.WithValue(r.Context(), variantKey{}, name)
+		if handler, ok := handlers[name]; ok {
+			// The variant replaces the route's handler once the rest
+			// of the plugin chain has run
+			ctx = context.WithValue(ctx, variantHandlerKey{}, &variantHandler{
+				route:   mux.CurrentRoute(r),
+				handler: handler,
+			})
+		}
+		next(w, r.WithContext(ctx))
+	}
+	return ep.UsePluginHandler(mux.PluginFunc(handler))
+}
+
+// variantOf returns the handler of the variant selected for
+// r or handler if the route of r is not split
+func variantOf(r *http.Request, handler http.Handler) http.Handler {
+	variant, ok := r.Context().Value(variantHandlerKey{}).(*variantHandler)
+	if !ok || variant.route != mux.CurrentRoute(r) {
+		return handler
+	}
+	return variant.handler
+}
+
+// Variant returns the variant of a split route selected for
+// the request or an empty string if the route is not split
+func (c *Context) Variant() string {
+	if c.Request == nil {
+		return ""
+	}
+	name, _ := c.Request.Context().Value(variantKey{}).(string)
+	return name
+}
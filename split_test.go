@@ -0,0 +1,112 @@
+package verto
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+)
+
+func TestSplit(t *testing.T) {
+	defer func() {
+		err := recover()
+		if err != nil {
+			t.Errorf(err.(error).Error())
+		}
+	}()
+
+	err := "Failed split."
+
+	v := New()
+	v.Logger = &NilLogger{}
+	v.Injections.Set("db", "db")
+	v.ClientKey = func(r *http.Request) string { return r.Header.Get("X-Client") }
+	v.Get("/checkout", func(c *Context) (interface{}, error) {
+		return "control:" + c.Variant(), nil
+	}).Split(map[string]Variant{
+		"control": {Weight: 3},
+		"new": {Weight: 1, Resource: func(c *Context) (interface{}, error) {
+			return "new:" + c.Variant() + ":" + c.Injections().Get("db").(string), nil
+		}},
+		"off": {Weight: 0, Resource: func(c *Context) (interface{}, error) {
+			return "off", nil
+		}},
+	})
+	h := &HttpHandler{v}
+
+	serve := func(client string) *httptest.ResponseRecorder {
+		r, _ := http.NewRequest("GET", "http://test.com/checkout", nil)
+		r.Header.Set("X-Client", client)
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		return w
+	}
+
+	counts := make(map[string]int)
+	for i := 0; i < 400; i++ {
+		w := serve(strconv.Itoa(i))
+		variant := w.Header().Get(VariantHeader)
+		counts[variant]++
+
+		switch variant {
+		case "control":
+			if w.Body.String() != "control:control" {
+				t.Fatalf(err)
+			}
+		case "new":
+			if w.Body.String() != "new:new:db" {
+				t.Fatalf(err)
+			}
+		default:
+			t.Fatalf(err)
+		}
+
+		// Test assignment is deterministic
+		if serve(strconv.Itoa(i)).Header().Get(VariantHeader) != variant {
+			t.Fatalf(err)
+		}
+	}
+	if counts["control"] < 250 || counts["new"] < 60 {
+		t.Errorf(err)
+	}
+}
+
+func TestSplitPluginsAfterSplit(t *testing.T) {
+	defer func() {
+		err := recover()
+		if err != nil {
+			t.Errorf(err.(error).Error())
+		}
+	}()
+
+	err := "Failed split with plugins after split."
+
+	v := New()
+	v.Logger = &NilLogger{}
+	v.Get("/checkout", func(c *Context) (interface{}, error) {
+		return "control", nil
+	}).Split(map[string]Variant{
+		"new": {Weight: 1, Resource: func(c *Context) (interface{}, error) {
+			return "new", nil
+		}},
+	}).Use(PluginFunc(func(c *Context, next http.HandlerFunc) {
+		c.Response.Header().Set("X-After", c.Variant())
+		next(c.Response, c.Request)
+	}))
+	h := &HttpHandler{v}
+
+	r, _ := http.NewRequest("GET", "http://test.com/checkout", nil)
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	if w.Body.String() != "new" || w.Header().Get("X-After") != "new" {
+		t.Errorf(err)
+	}
+
+	// Test variants honour maintenance mode
+	v.SetMaintenance(true)
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	if w.Code != http.StatusServiceUnavailable {
+		t.Errorf(err)
+	}
+}
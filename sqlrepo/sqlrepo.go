@@ -0,0 +1,417 @@
+// Package sqlrepo provides a verto.Repository backed by a database/sql
+// database so that simple resources need no hand-written SQL. Records are
+// structs whose fields map to columns through db tags as with sqlx. Filters,
+// sorting and pagination parsed from requests by verto.RepositoryController
+// are translated into WHERE, ORDER BY, LIMIT and OFFSET clauses, restricted
+// to the record's columns.
+//
+// Example usage:
+//
+//	type Post struct {
+//		Id     int64  `json:"id" db:"id"`
+//		UserId int64  `json:"user_id" db:"user_id"`
+//		Title  string `json:"title" db:"title"`
+//		verto.Timestamps
+//		verto.SoftDelete
+//	}
+//
+//	posts := sqlrepo.New(db, "posts", Post{})
+//	v.Resource("/users", users).Nest("/posts", verto.RepositoryController(posts, 20, 100))
+package sqlrepo
+
+import (
+	"database/sql"
+	"github.com/boxtown/verto"
+	"net/http"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// DB is the subset of *sql.DB used by Repository. It is
+// also satisfied by *sql.Tx, *sqlx.DB and *sqlx.Tx
+type DB interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+	Query(query string, args ...interface{}) (*sql.Rows, error)
+	QueryRow(query string, args ...interface{}) *sql.Row
+}
+
+// column is a struct field mapped to a table column
+type column struct {
+	name     string
+	jsonName string
+	index    []int
+}
+
+// Repository is a verto.Repository storing records in a SQL table
+type Repository struct {
+	// DB is the database records are stored in
+	DB DB
+
+	// Table is the name of the table records are stored in
+	Table string
+
+	// Key is the primary key column. Defaults to id
+	Key string
+
+	// Parents maps the parameters of parent resources to the
+	// columns referencing them (e.g. userID to user_id). Parameters
+	// without a mapping use their snake cased name if such a
+	// column exists (userID to user_id)
+	Parents map[string]string
+
+	// Filterable and Sortable restrict the columns clients may
+	// filter and sort by. If nil, all columns are allowed
+	Filterable []string
+	Sortable   []string
+
+	// Dollar selects $n placeholders (e.g. PostgreSQL)
+	// instead of ? placeholders
+	Dollar bool
+
+	typ     reflect.Type
+	columns []column
+}
+
+// ErrInvalidQuery is returned for filters and
+// sorting by columns that are not allowed
+var ErrInvalidQuery = verto.NewError(http.StatusBadRequest, "Invalid filter or sort field.")
+
+// New returns a Repository storing records of prototype's
+// struct type in table
+func New(db DB, table string, prototype interface{}) *Repository {
+	t := reflect.TypeOf(prototype)
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return &Repository{
+		DB:      db,
+		Table:   table,
+		Key:     "id",
+		typ:     t,
+		columns: columns(t, nil),
+	}
+}
+
+// New returns a pointer to a new record
+func (repo *Repository) New() interface{} {
+	return reflect.New(repo.typ).Interface()
+}
+
+// List returns the records selected by q as a slice of
+// record pointers and the number of records matching q
+func (repo *Repository) List(q verto.Query) (interface{}, int64, error) {
+	where, args, err := repo.where(q)
+	if err != nil {
+		return nil, 0, err
+	}
+	order, err := repo.order(q.Sort)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var total int64
+	if err := repo.DB.QueryRow(repo.query("SELECT COUNT(*) FROM "+repo.Table+where), args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	stmt := "SELECT " + repo.columnList() + " FROM " + repo.Table + where + order
+	if q.Limit > 0 {
+		stmt += " LIMIT " + strconv.Itoa(q.Limit)
+	}
+	if q.Offset > 0 {
+		stmt += " OFFSET " + strconv.Itoa(q.Offset)
+	}
+	rows, err := repo.DB.Query(repo.query(stmt), args...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	records := reflect.MakeSlice(reflect.SliceOf(reflect.PtrTo(repo.typ)), 0, q.Limit)
+	for rows.Next() {
+		record := reflect.New(repo.typ)
+		if err := rows.Scan(repo.fields(record)...); err != nil {
+			return nil, 0, err
+		}
+		records = reflect.Append(records, record)
+	}
+	return records.Interface(), total, rows.Err()
+}
+
+// Get returns the record with id or verto.ErrRecordNotFound
+func (repo *Repository) Get(id string, q verto.Query) (interface{}, error) {
+	where, args := repo.scope(id, q)
+	record := reflect.New(repo.typ)
+	err := repo.DB.QueryRow(
+		repo.query("SELECT "+repo.columnList()+" FROM "+repo.Table+where), args...,
+	).Scan(repo.fields(record)...)
+	if err == sql.ErrNoRows {
+		return nil, verto.ErrRecordNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return record.Interface(), nil
+}
+
+// Create inserts record
+func (repo *Repository) Create(record interface{}, q verto.Query) (interface{}, error) {
+	names := make([]string, len(repo.columns))
+	marks := make([]string, len(repo.columns))
+	for i, c := range repo.columns {
+		names[i] = c.name
+		marks[i] = "?"
+	}
+	_, err := repo.DB.Exec(
+		repo.query("INSERT INTO "+repo.Table+" ("+strings.Join(names, ", ")+") VALUES ("+strings.Join(marks, ", ")+")"),
+		repo.values(record)...,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return record, nil
+}
+
+// Update stores all columns of record except the key
+func (repo *Repository) Update(id string, record interface{}, q verto.Query) (interface{}, error) {
+	sets := make([]string, 0, len(repo.columns))
+	args := make([]interface{}, 0, len(repo.columns)+1)
+	values := repo.values(record)
+	for i, c := range repo.columns {
+		if c.name == repo.Key {
+			continue
+		}
+		sets = append(sets, c.name+" = ?")
+		args = append(args, values[i])
+	}
+	where, scopeArgs := repo.scope(id, verto.Query{Parents: q.Parents, IncludeDeleted: true})
+	res, err := repo.DB.Exec(
+		repo.query("UPDATE "+repo.Table+" SET "+strings.Join(sets, ", ")+where),
+		append(args, scopeArgs...)...,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return nil, verto.ErrRecordNotFound
+	}
+	return record, nil
+}
+
+// Delete deletes the record with id
+func (repo *Repository) Delete(id string, q verto.Query) error {
+	where, args := repo.scope(id, verto.Query{Parents: q.Parents, IncludeDeleted: true})
+	res, err := repo.DB.Exec(repo.query("DELETE FROM "+repo.Table+where), args...)
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return verto.ErrRecordNotFound
+	}
+	return nil
+}
+
+// where returns the WHERE clause selecting the records matching q
+func (repo *Repository) where(q verto.Query) (string, []interface{}, error) {
+	conds, args := repo.conditions(q)
+	for _, name := range sortedKeys(q.Filters) {
+		c, ok := repo.column(name, repo.Filterable)
+		if !ok {
+			return "", nil, ErrInvalidQuery
+		}
+		conds = append(conds, c.name+" = ?")
+		args = append(args, q.Filters[name])
+	}
+	return clause(conds), args, nil
+}
+
+// scope returns the WHERE clause selecting the record with id within q
+func (repo *Repository) scope(id string, q verto.Query) (string, []interface{}) {
+	conds, args := repo.conditions(q)
+	conds = append([]string{repo.Key + " = ?"}, conds...)
+	args = append([]interface{}{id}, args...)
+	return clause(conds), args
+}
+
+// conditions returns the conditions selecting records of the
+// parent resources of q hiding soft-deleted records
+func (repo *Repository) conditions(q verto.Query) ([]string, []interface{}) {
+	conds := make([]string, 0)
+	args := make([]interface{}, 0)
+	for _, param := range sortedKeys(q.Parents) {
+		name, ok := repo.Parents[param]
+		if !ok {
+			name = snakeCase(param)
+			if _, exists := repo.column(name, nil); !exists {
+				continue
+			}
+		}
+		conds = append(conds, name+" = ?")
+		args = append(args, q.Parents[param])
+	}
+	if _, ok := repo.column("deleted_at", nil); ok && !q.IncludeDeleted {
+		conds = append(conds, "deleted_at IS NULL")
+	}
+	return conds, args
+}
+
+// order returns the ORDER BY clause for sort
+func (repo *Repository) order(sort []string) (string, error) {
+	if len(sort) == 0 {
+		return " ORDER BY " + repo.Key, nil
+	}
+	terms := make([]string, 0, len(sort))
+	for _, s := range sort {
+		direction := " ASC"
+		if strings.HasPrefix(s, "-") {
+			direction = " DESC"
+			s = s[1:]
+		}
+		c, ok := repo.column(s, repo.Sortable)
+		if !ok {
+			return "", ErrInvalidQuery
+		}
+		terms = append(terms, c.name+direction)
+	}
+	return " ORDER BY " + strings.Join(terms, ", "), nil
+}
+
+// column returns the column with the column or JSON name
+// name if it exists and is contained in allowed (if non-nil)
+func (repo *Repository) column(name string, allowed []string) (column, bool) {
+	for _, c := range repo.columns {
+		if c.name != name && c.jsonName != name {
+			continue
+		}
+		if allowed == nil {
+			return c, true
+		}
+		for _, a := range allowed {
+			if a == c.name {
+				return c, true
+			}
+		}
+	}
+	return column{}, false
+}
+
+// columnList returns the comma separated column names
+func (repo *Repository) columnList() string {
+	names := make([]string, len(repo.columns))
+	for i, c := range repo.columns {
+		names[i] = c.name
+	}
+	return strings.Join(names, ", ")
+}
+
+// fields returns pointers to the fields of record for scanning
+func (repo *Repository) fields(record reflect.Value) []interface{} {
+	v := record.Elem()
+	fields := make([]interface{}, len(repo.columns))
+	for i, c := range repo.columns {
+		fields[i] = v.FieldByIndex(c.index).Addr().Interface()
+	}
+	return fields
+}
+
+// values returns the column values of record
+func (repo *Repository) values(record interface{}) []interface{} {
+	v := reflect.ValueOf(record)
+	for v.Kind() == reflect.Ptr {
+		v = v.Elem()
+	}
+	values := make([]interface{}, len(repo.columns))
+	for i, c := range repo.columns {
+		values[i] = v.FieldByIndex(c.index).Interface()
+	}
+	return values
+}
+
+// query rewrites placeholders for the configured dialect
+func (repo *Repository) query(q string) string {
+	if !repo.Dollar {
+		return q
+	}
+	out := make([]byte, 0, len(q)+8)
+	n := 0
+	for i := 0; i < len(q); i++ {
+		if q[i] == '?' {
+			n++
+			out = append(out, '$')
+			out = append(out, strconv.Itoa(n)...)
+			continue
+		}
+		out = append(out, q[i])
+	}
+	return string(out)
+}
+
+// columns returns the columns of struct type t. Fields are named by
+// their db tag or their snake cased name. Embedded structs without
+// a db tag are flattened and fields tagged db:"-" are skipped
+func columns(t reflect.Type, index []int) []column {
+	cols := make([]column, 0)
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("db")
+		if tag == "-" || (f.PkgPath != "" && !f.Anonymous) {
+			continue
+		}
+		idx := append(append([]int{}, index...), i)
+		if f.Anonymous && tag == "" && f.Type.Kind() == reflect.Struct {
+			cols = append(cols, columns(f.Type, idx)...)
+			continue
+		}
+		name := strings.Split(tag, ",")[0]
+		if name == "" {
+			name = snakeCase(f.Name)
+		}
+		jsonName := strings.Split(f.Tag.Get("json"), ",")[0]
+		if jsonName == "" || jsonName == "-" {
+			jsonName = name
+		}
+		cols = append(cols, column{name: name, jsonName: jsonName, index: idx})
+	}
+	return cols
+}
+
+// clause joins conditions into a WHERE clause
+func clause(conds []string) string {
+	if len(conds) == 0 {
+		return ""
+	}
+	return " WHERE " + strings.Join(conds, " AND ")
+}
+
+// snakeCase converts a Go or parameter name into
+// a snake cased column name (UserID -> user_id)
+func snakeCase(name string) string {
+	runes := []rune(name)
+	out := make([]rune, 0, len(runes)+4)
+	for i, r := range runes {
+		if unicode.IsUpper(r) {
+			prevLower := i > 0 && unicode.IsLower(runes[i-1])
+			nextLower := i > 0 && i+1 < len(runes) && unicode.IsLower(runes[i+1]) && unicode.IsUpper(runes[i-1])
+			if prevLower || nextLower {
+				out = append(out, '_')
+			}
+			r = unicode.ToLower(r)
+		}
+		out = append(out, r)
+	}
+	return string(out)
+}
+
+// sortedKeys returns the keys of m in sorted order
+// so that generated queries are deterministic
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
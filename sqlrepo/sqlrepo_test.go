@@ -0,0 +1,176 @@
+package sqlrepo
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"github.com/boxtown/verto"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+type testPost struct {
+	Id     int64  `json:"id" db:"id"`
+	UserId string `json:"user_id" db:"user_id"`
+	Title  string `json:"title"`
+	Secret string `json:"-" db:"-"`
+	verto.Timestamps
+	verto.SoftDelete
+}
+
+// recorder is a database/sql driver recording statements
+// and answering queries with canned rows
+type recorder struct {
+	statements []string
+	args       [][]driver.Value
+	rows       [][]driver.Value
+	columns    []string
+	affected   int64
+}
+
+func (rec *recorder) Open(name string) (driver.Conn, error) { return rec, nil }
+func (rec *recorder) Prepare(query string) (driver.Stmt, error) {
+	return &recorderStmt{rec, query}, nil
+}
+func (rec *recorder) Close() error              { return nil }
+func (rec *recorder) Begin() (driver.Tx, error) { return nil, errors.New("unsupported") }
+
+type recorderStmt struct {
+	rec   *recorder
+	query string
+}
+
+func (s *recorderStmt) Close() error  { return nil }
+func (s *recorderStmt) NumInput() int { return -1 }
+func (s *recorderStmt) Exec(args []driver.Value) (driver.Result, error) {
+	s.rec.statements = append(s.rec.statements, s.query)
+	s.rec.args = append(s.rec.args, args)
+	return driver.RowsAffected(s.rec.affected), nil
+}
+func (s *recorderStmt) Query(args []driver.Value) (driver.Rows, error) {
+	s.rec.statements = append(s.rec.statements, s.query)
+	s.rec.args = append(s.rec.args, args)
+	if strings.HasPrefix(s.query, "SELECT COUNT(*)") {
+		return &recorderRows{columns: []string{"count"}, rows: [][]driver.Value{{int64(len(s.rec.rows))}}}, nil
+	}
+	return &recorderRows{columns: s.rec.columns, rows: s.rec.rows}, nil
+}
+
+type recorderRows struct {
+	columns []string
+	rows    [][]driver.Value
+}
+
+func (r *recorderRows) Columns() []string { return r.columns }
+func (r *recorderRows) Close() error      { return nil }
+func (r *recorderRows) Next(dest []driver.Value) error {
+	if len(r.rows) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.rows[0])
+	r.rows = r.rows[1:]
+	return nil
+}
+
+func TestRepository(t *testing.T) {
+	defer func() {
+		err := recover()
+		if err != nil {
+			t.Errorf(err.(error).Error())
+		}
+	}()
+
+	err := "Failed repository."
+
+	now := time.Now()
+	rec := &recorder{
+		columns:  []string{"id", "user_id", "title", "created_at", "updated_at", "deleted_at"},
+		rows:     [][]driver.Value{{int64(1), "7", "a", now, now, nil}},
+		affected: 1,
+	}
+	sql.Register("sqlrepo-recorder", rec)
+	db, _ := sql.Open("sqlrepo-recorder", "")
+	defer db.Close()
+
+	repo := New(db, "posts", testPost{})
+	repo.Dollar = true
+	repo.Sortable = []string{"title", "created_at"}
+	if repo.columnList() != "id, user_id, title, created_at, updated_at, deleted_at" {
+		t.Errorf(err)
+	}
+
+	// Test listing with parents, filters, sorting and pagination
+	q := verto.Query{
+		Parents: map[string]string{"userID": "7"},
+		Filters: map[string]string{"title": "a"},
+		Sort:    []string{"-created_at"},
+		Offset:  10,
+		Limit:   5,
+	}
+	records, total, e := repo.List(q)
+	if e != nil || total != 1 {
+		t.Fatalf(err)
+	}
+	posts := records.([]*testPost)
+	if len(posts) != 1 || posts[0].Id != 1 || posts[0].UserId != "7" || posts[0].Title != "a" {
+		t.Errorf(err)
+	}
+	expected := []string{
+		"SELECT COUNT(*) FROM posts WHERE user_id = $1 AND deleted_at IS NULL AND title = $2",
+		"SELECT id, user_id, title, created_at, updated_at, deleted_at FROM posts WHERE user_id = $1 AND deleted_at IS NULL AND title = $2 ORDER BY created_at DESC LIMIT 5 OFFSET 10",
+	}
+	for i, s := range expected {
+		if rec.statements[i] != s {
+			t.Errorf(err)
+		}
+	}
+
+	// Test disallowed sorting
+	if _, _, e = repo.List(verto.Query{Sort: []string{"user_id"}}); e != ErrInvalidQuery {
+		t.Errorf(err)
+	}
+	if _, _, e = repo.List(verto.Query{Filters: map[string]string{"secret": "x"}}); e != ErrInvalidQuery {
+		t.Errorf(err)
+	}
+
+	// Test get, create, update and delete
+	rec.statements = nil
+	rec.rows = [][]driver.Value{{int64(1), "7", "a", now, now, nil}}
+	if _, e = repo.Get("1", verto.Query{}); e != nil {
+		t.Errorf(err)
+	}
+	post := &testPost{Id: 2, UserId: "7", Title: "b"}
+	repo.Create(post, verto.Query{})
+	repo.Update("2", post, verto.Query{Parents: map[string]string{"userID": "7"}})
+	repo.Delete("2", verto.Query{})
+	expected = []string{
+		"SELECT id, user_id, title, created_at, updated_at, deleted_at FROM posts WHERE id = $1 AND deleted_at IS NULL",
+		"INSERT INTO posts (id, user_id, title, created_at, updated_at, deleted_at) VALUES ($1, $2, $3, $4, $5, $6)",
+		"UPDATE posts SET user_id = $1, title = $2, created_at = $3, updated_at = $4, deleted_at = $5 WHERE id = $6 AND user_id = $7",
+		"DELETE FROM posts WHERE id = $1",
+	}
+	if len(rec.statements) != len(expected) {
+		t.Fatalf(err)
+	}
+	for i, s := range expected {
+		if rec.statements[i] != s {
+			t.Errorf(err)
+		}
+	}
+
+	// Test missing records
+	rec.rows = nil
+	if _, e = repo.Get("3", verto.Query{}); e != verto.ErrRecordNotFound {
+		t.Errorf(err)
+	}
+	rec.affected = 0
+	if e = repo.Delete("3", verto.Query{}); e != verto.ErrRecordNotFound {
+		t.Errorf(err)
+	}
+
+	if snakeCase("UserID") != "user_id" || snakeCase("HTTPServer") != "http_server" || snakeCase("Title") != "title" {
+		t.Errorf(err)
+	}
+}
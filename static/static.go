@@ -0,0 +1,341 @@
+// Package static provides file serving for Verto with conditional GET
+// support. Files are served with ETag and Last-Modified validators,
+// precompressed sibling files (.br and .gz) are served in place of the
+// original when the client accepts them, and fingerprinted assets are
+// served with immutable cache headers. Directories are served with their
+// index file or, if enabled, a listing. Files can be served from disk or
+// from an fs.FS such as an embed.FS.
+package static
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"html"
+	"io"
+	"io/fs"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultIndex is the index file New and NewFS
+// configure Handlers to serve for directories
+const DefaultIndex = "index.html"
+
+// DefaultFingerprint matches file names containing a content hash
+// of at least 8 hex characters before the extension (e.g. app.3f9a1c2e.js)
+var DefaultFingerprint = regexp.MustCompile(`[.-][0-9a-fA-F]{8,}\.[^./]+$`)
+
+// encodings lists the supported precompressed sibling
+// extensions in order of preference
+var encodings = []struct {
+	name string
+	ext  string
+}{
+	{"br", ".br"},
+	{"gzip", ".gz"},
+}
+
+// Handler is an http.Handler that serves files from a file system.
+// The request path is used as the file name relative to Root and is
+// confined to Root. Requests for directories without a trailing slash
+// are redirected to the path with a slash.
+//
+// Example usage:
+//
+//	h := static.New("./public")
+//	h.Precompressed = true
+//	v.StaticHandler("/assets", h)
+type Handler struct {
+	// Root is the file system files are served from
+	Root http.FileSystem
+
+	// Index is the file served for directory requests. If empty,
+	// directories are only served if Listing is enabled
+	Index string
+
+	// Listing enables listings of directories without an index file.
+	// Directories without an index file respond with 404 otherwise
+	Listing bool
+
+	// Dotfiles enables serving files and directories whose names
+	// start with a dot (e.g. .well-known). They respond with 404
+	// otherwise so that e.g. .git directories are not exposed
+	Dotfiles bool
+
+	// Precompressed enables serving precompressed .br and .gz
+	// siblings of requested files if the client accepts them
+	Precompressed bool
+
+	// Fingerprint matches the names of fingerprinted assets. Matching
+	// files are served with immutable cache headers. If nil,
+	// fingerprinted assets receive no special treatment
+	Fingerprint *regexp.Regexp
+
+	// ImmutableMaxAge is the max-age sent with fingerprinted assets.
+	// Defaults to one year
+	ImmutableMaxAge time.Duration
+}
+
+// New returns a Handler serving files from dir with DefaultIndex
+// as the index file and DefaultFingerprint as the fingerprint pattern
+func New(dir string) *Handler {
+	return &Handler{
+		Root:        http.Dir(dir),
+		Index:       DefaultIndex,
+		Fingerprint: DefaultFingerprint,
+	}
+}
+
+// NewFS returns a Handler serving files from fsys like New.
+// Assets can be embedded in the binary with an embed.FS
+//
+// Example usage:
+//
+//	//go:embed public
+//	var public embed.FS
+//
+//	sub, _ := fs.Sub(public, "public")
+//	v.StaticHandler("/assets", static.NewFS(sub))
+func NewFS(fsys fs.FS) *Handler {
+	return &Handler{
+		Root:        http.FS(fsys),
+		Index:       DefaultIndex,
+		Fingerprint: DefaultFingerprint,
+	}
+}
+
+// ServeHTTP serves the file named by the request path
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.ServeFile(w, r, r.URL.Path)
+}
+
+// ServeFile serves the file at name relative to Root. Conditional
+// and range requests are honored
+func (h *Handler) ServeFile(w http.ResponseWriter, r *http.Request, name string) {
+	// Clean confines the name to the root by
+	// resolving all .. segments against /
+	name = path.Clean("/" + name)
+	if !h.Dotfiles && hasDotSegment(name) {
+		http.NotFound(w, r)
+		return
+	}
+
+	f, err := h.Root.Open(name)
+	if err != nil {
+		serveError(w, err)
+		return
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		serveError(w, err)
+		return
+	}
+	if info.IsDir() {
+		h.serveDir(w, r, f, name)
+		return
+	}
+
+	content := http.File(f)
+	etag := fileETag(f, info)
+	if h.Precompressed {
+		w.Header().Add("Vary", "Accept-Encoding")
+		if cf, cinfo, enc := h.openCompressed(r, name); cf != nil {
+			defer cf.Close()
+
+			content = cf
+			etag = fileETag(cf, cinfo)
+			w.Header().Set("Content-Encoding", enc)
+			if ct := mime.TypeByExtension(filepath.Ext(name)); ct != "" {
+				w.Header().Set("Content-Type", ct)
+			}
+		}
+	}
+
+	w.Header().Set("ETag", etag)
+	if h.Fingerprint != nil && h.Fingerprint.MatchString(name) {
+		maxAge := h.ImmutableMaxAge
+		if maxAge <= 0 {
+			maxAge = 365 * 24 * time.Hour
+		}
+		w.Header().Set("Cache-Control", "public, max-age="+
+			strconv.FormatInt(int64(maxAge/time.Second), 10)+", immutable")
+	}
+
+	// http.ServeContent handles If-None-Match, If-Modified-Since,
+	// If-Range, and Range using the ETag set above and the
+	// modification time of the original file
+	http.ServeContent(w, r, name, info.ModTime(), content)
+}
+
+// serveDir serves the directory dir at name with its
+// index file or a listing
+func (h *Handler) serveDir(w http.ResponseWriter, r *http.Request, dir http.File, name string) {
+	if h.Index == "" && !h.Listing {
+		http.NotFound(w, r)
+		return
+	}
+	if !strings.HasSuffix(r.URL.Path, "/") {
+		// Redirect relative to the request path so
+		// that stripped prefixes are preserved
+		target := path.Base(r.URL.Path) + "/"
+		if r.URL.RawQuery != "" {
+			target += "?" + r.URL.RawQuery
+		}
+		w.Header().Set("Location", target)
+		w.WriteHeader(http.StatusMovedPermanently)
+		return
+	}
+
+	if h.Index != "" {
+		index := path.Join(name, h.Index)
+		if f, err := h.Root.Open(index); err == nil {
+			info, err := f.Stat()
+			f.Close()
+			if err == nil && !info.IsDir() {
+				h.ServeFile(w, r, index)
+				return
+			}
+		}
+	}
+	if !h.Listing {
+		http.NotFound(w, r)
+		return
+	}
+	h.list(w, dir)
+}
+
+// list writes an HTML listing of the directory dir
+func (h *Handler) list(w http.ResponseWriter, dir http.File) {
+	entries, err := dir.Readdir(-1)
+	if err != nil {
+		serveError(w, err)
+		return
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name() < entries[j].Name() })
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	fmt.Fprint(w, "<!DOCTYPE html>\n<pre>\n")
+	for _, entry := range entries {
+		name := entry.Name()
+		if !h.Dotfiles && strings.HasPrefix(name, ".") {
+			continue
+		}
+		if entry.IsDir() {
+			name += "/"
+		}
+		ref := url.URL{Path: name}
+		fmt.Fprintf(w, "<a href=\"%s\">%s</a>\n", ref.String(), html.EscapeString(name))
+	}
+	fmt.Fprint(w, "</pre>\n")
+}
+
+// openCompressed attempts to open a precompressed sibling of name accepted
+// by the client. Returns the sibling file, its info and content encoding
+// or a nil file if no acceptable sibling exists
+func (h *Handler) openCompressed(r *http.Request, name string) (http.File, os.FileInfo, string) {
+	for _, enc := range encodings {
+		if !accepts(r, enc.name) {
+			continue
+		}
+		f, err := h.Root.Open(name + enc.ext)
+		if err != nil {
+			continue
+		}
+		info, err := f.Stat()
+		if err != nil || info.IsDir() {
+			f.Close()
+			continue
+		}
+		return f, info, enc.name
+	}
+	return nil, nil, ""
+}
+
+// accepts returns whether the Accept-Encoding header of r accepts
+// encoding with a non-zero quality, either explicitly or through the
+// wildcard '*'. The headers package can't be used here since it
+// imports verto, which imports static
+func accepts(r *http.Request, encoding string) bool {
+	wildcard := 0.0
+	for _, value := range r.Header["Accept-Encoding"] {
+		for _, e := range strings.Split(value, ",") {
+			parts := strings.Split(e, ";")
+			name, q := strings.TrimSpace(parts[0]), 1.0
+			for _, p := range parts[1:] {
+				kv := strings.SplitN(strings.TrimSpace(p), "=", 2)
+				if len(kv) == 2 && strings.EqualFold(strings.TrimSpace(kv[0]), "q") {
+					f, err := strconv.ParseFloat(strings.TrimSpace(kv[1]), 64)
+					if err != nil || f < 0 || f > 1 {
+						f = 0
+					}
+					q = f
+				}
+			}
+			if strings.EqualFold(name, encoding) {
+				return q > 0
+			}
+			if name == "*" && wildcard == 0 {
+				wildcard = q
+			}
+		}
+	}
+	return wildcard > 0
+}
+
+// ETag returns a strong entity tag for a file derived from
+// its modification time and size
+func ETag(info os.FileInfo) string {
+	return fmt.Sprintf(`"%x-%x"`, info.ModTime().UnixNano(), info.Size())
+}
+
+// fileETag returns the ETag of f. Files without a modification
+// time, such as those of an embed.FS, are tagged with a hash of
+// their content instead
+func fileETag(f http.File, info os.FileInfo) string {
+	if !info.ModTime().IsZero() {
+		return ETag(info)
+	}
+	hash := sha256.New()
+	_, err := io.Copy(hash, f)
+	if _, serr := f.Seek(0, io.SeekStart); err != nil || serr != nil {
+		return ETag(info)
+	}
+	return fmt.Sprintf(`"%x"`, hash.Sum(nil)[:16])
+}
+
+// hasDotSegment returns whether a segment of
+// the cleaned name starts with a dot
+func hasDotSegment(name string) bool {
+	for _, segment := range strings.Split(name, "/") {
+		if strings.HasPrefix(segment, ".") {
+			return true
+		}
+	}
+	return false
+}
+
+// writes the appropriate error response for a file system error
+func serveError(w http.ResponseWriter, err error) {
+	switch {
+	case os.IsNotExist(err):
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Fprint(w, "Not Found.")
+	case os.IsPermission(err):
+		w.WriteHeader(http.StatusForbidden)
+		fmt.Fprint(w, "Forbidden.")
+	default:
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprint(w, "Internal Server Error.")
+	}
+}
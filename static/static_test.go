@@ -0,0 +1,134 @@
+package static
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+)
+
+func TestStaticHandler(t *testing.T) {
+	defer func() {
+		err := recover()
+		if err != nil {
+			t.Errorf(err.(error).Error())
+		}
+	}()
+
+	err := "Failed static handler."
+
+	dir, _ := ioutil.TempDir("", "static")
+	defer os.RemoveAll(dir)
+	ioutil.WriteFile(filepath.Join(dir, "a.txt"), []byte("hello"), 0644)
+	ioutil.WriteFile(filepath.Join(dir, "a.txt.gz"), []byte("compressed"), 0644)
+	ioutil.WriteFile(filepath.Join(dir, "app.0123abcd.js"), []byte("js"), 0644)
+
+	h := New(dir)
+
+	// Test plain serve
+	r, _ := http.NewRequest("GET", "http://test.com/a.txt", nil)
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	if w.Code != 200 || w.Body.String() != "hello" {
+		t.Errorf(err)
+	}
+	etag := w.Header().Get("ETag")
+	if etag == "" || w.Header().Get("Last-Modified") == "" {
+		t.Errorf(err)
+	}
+
+	// Test conditional GET
+	r, _ = http.NewRequest("GET", "http://test.com/a.txt", nil)
+	r.Header.Set("If-None-Match", etag)
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	if w.Code != http.StatusNotModified {
+		t.Errorf(err)
+	}
+
+	// Test precompressed sibling
+	h.Precompressed = true
+	r, _ = http.NewRequest("GET", "http://test.com/a.txt", nil)
+	r.Header.Set("Accept-Encoding", "br;q=0, gzip")
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	if w.Body.String() != "compressed" {
+		t.Errorf(err)
+	}
+	if w.Header().Get("Content-Encoding") != "gzip" {
+		t.Errorf(err)
+	}
+	if w.Header().Get("Content-Type") != "text/plain; charset=utf-8" {
+		t.Errorf(err)
+	}
+	if w.Header().Get("ETag") == etag {
+		t.Errorf(err)
+	}
+
+	// Test fingerprinted asset
+	r, _ = http.NewRequest("GET", "http://test.com/app.0123abcd.js", nil)
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	if w.Header().Get("Cache-Control") != "public, max-age=31536000, immutable" {
+		t.Errorf(err)
+	}
+
+	// Test not found
+	r, _ = http.NewRequest("GET", "http://test.com/b.txt", nil)
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	if w.Code != 404 {
+		t.Errorf(err)
+	}
+}
+
+func TestStaticHandlerFS(t *testing.T) {
+	defer func() {
+		err := recover()
+		if err != nil {
+			t.Errorf(err.(error).Error())
+		}
+	}()
+
+	err := "Failed static handler fs."
+
+	h := NewFS(fstest.MapFS{
+		"a.txt":    {Data: []byte("hello")},
+		"b.txt":    {Data: []byte("world")},
+		"a.txt.gz": {Data: []byte("compressed")},
+	})
+	h.Precompressed = true
+
+	serve := func(path string, header http.Header) *httptest.ResponseRecorder {
+		r, _ := http.NewRequest("GET", "http://test.com"+path, nil)
+		for k, v := range header {
+			r.Header[k] = v
+		}
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		return w
+	}
+
+	// Test files without modification times are tagged by content
+	w := serve("/a.txt", nil)
+	etag := w.Header().Get("ETag")
+	if w.Code != 200 || w.Body.String() != "hello" || etag == "" {
+		t.Errorf(err)
+	}
+	if serve("/b.txt", nil).Header().Get("ETag") == etag {
+		t.Errorf(err)
+	}
+	if serve("/a.txt", http.Header{"If-None-Match": {etag}}).Code != http.StatusNotModified {
+		t.Errorf(err)
+	}
+	w = serve("/a.txt", http.Header{"Accept-Encoding": {"gzip"}})
+	if w.Body.String() != "compressed" || w.Header().Get("ETag") == etag {
+		t.Errorf(err)
+	}
+	if serve("/c.txt", nil).Code != 404 {
+		t.Errorf(err)
+	}
+}
@@ -56,12 +56,15 @@ func (sl *StoppableListener) Accept() (net.Conn, error) {
 	}
 }
 
-// Close sends a stop command to the listener.
+// Close sends a stop command to the listener and closes
+// the underlying listener so that no further connections
+// are queued.
 func (sl *StoppableListener) Close() error {
 	select {
 	case <-sl.stop:
+		return nil
 	default:
 		close(sl.stop)
 	}
-	return nil
+	return sl.TCPListener.Close()
 }
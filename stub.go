@@ -0,0 +1,113 @@
+package verto
+
+import (
+	"net/http"
+)
+
+// StubKey is the endpoint metadata key under which
+// stub responses are stored
+const StubKey = "verto.stub"
+
+// Stub is a declared example response for a route
+type Stub struct {
+	// Status is the response status. Defaults to 200
+	Status int
+
+	// Body is the response body. []byte and string bodies are
+	// written as is, other bodies are passed to the ResponseHandler
+	Body interface{}
+
+	// Header holds additional response headers
+	Header http.Header
+}
+
+// Stub declares an example response for the route represented by
+// the Endpoint. Stubs are served in place of the route's handler if
+// the route has no handler or if Verto is in mock mode.
+//
+// Example usage:
+//
+//	v.Get("/users/{id}", nil).Stub(200, User{Id: "42", Name: "Jane"}, nil)
+//	v.SetMockMode(true)
+func (ep *Endpoint) Stub(status int, body interface{}, header http.Header) *Endpoint {
+	return ep.Meta(StubKey, &Stub{Status: status, Body: body, Header: header})
+}
+
+// SetMockMode sets whether routes with stubs respond with their
+// stub instead of running their handler. Mock mode lets clients
+// develop against an API before its handlers are implemented
+func (v *Verto) SetMockMode(mock bool) {
+	v.mock = mock
+}
+
+// serveStub writes the stub of the route matched for r if the route
+// has a stub and either Verto is in mock mode or unimplemented is true.
+// Returns whether a stub was served
+func (v *Verto) serveStub(w http.ResponseWriter, r *http.Request, unimplemented bool) bool {
+	if !v.mock && !unimplemented {
+		return false
+	}
+	c := v.context(w, r)
+	value, ok := c.RouteMeta(StubKey)
+	if !ok {
+		return false
+	}
+	stub, ok := value.(*Stub)
+	if !ok {
+		return false
+	}
+
+	for k, values := range stub.Header {
+		for _, value := range values {
+			w.Header().Add(k, value)
+		}
+	}
+	status := stub.Status
+	if status == 0 {
+		status = http.StatusOK
+	}
+
+	switch body := stub.Body.(type) {
+	case nil:
+		w.WriteHeader(status)
+	case []byte:
+		w.WriteHeader(status)
+		w.Write(body)
+	case string:
+		w.WriteHeader(status)
+		w.Write([]byte(body))
+	default:
+		// Let the ResponseHandler set its content type
+		// before the status is written
+		c := v.context(&statusWriter{ResponseWriter: w, status: status}, r)
+		v.responseHandler(c).Handle(body, c)
+	}
+	return true
+}
+
+// statusWriter is an http.ResponseWriter that writes
+// status instead of 200 for implicit WriteHeader calls
+type statusWriter struct {
+	http.ResponseWriter
+
+	status int
+	wrote  bool
+}
+
+func (w *statusWriter) WriteHeader(code int) {
+	if w.wrote {
+		return
+	}
+	w.wrote = true
+	if code == http.StatusOK {
+		code = w.status
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *statusWriter) Write(b []byte) (int, error) {
+	if !w.wrote {
+		w.WriteHeader(http.StatusOK)
+	}
+	return w.ResponseWriter.Write(b)
+}
@@ -0,0 +1,60 @@
+package verto
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestEndpointStub(t *testing.T) {
+	defer func() {
+		err := recover()
+		if err != nil {
+			t.Errorf(err.(error).Error())
+		}
+	}()
+
+	err := "Failed endpoint stub."
+
+	v := New()
+	v.Logger = &NilLogger{}
+	v.ResponseHandler = ResponseFunc(JSONResponseFunc)
+	v.Get("/users/{id}", nil).Stub(http.StatusAccepted, map[string]string{"id": "42"}, http.Header{"X-Stub": {"true"}})
+	v.Get("/orders", func(c *Context) (interface{}, error) {
+		return "real", nil
+	}).Stub(200, "stubbed", nil)
+	v.Get("/missing", nil)
+	h := &HttpHandler{v}
+
+	serve := func(path string) *httptest.ResponseRecorder {
+		r, _ := http.NewRequest("GET", "http://test.com"+path, nil)
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		return w
+	}
+
+	// Test unimplemented route serves stub
+	w := serve("/users/42")
+	if w.Code != http.StatusAccepted || w.Body.String() != `{"id":"42"}` || w.Header().Get("X-Stub") != "true" {
+		t.Errorf(err)
+	}
+
+	// Test implemented route ignores stub outside of mock mode
+	w = serve("/orders")
+	if w.Body.String() != `"real"` {
+		t.Errorf(err)
+	}
+
+	// Test unimplemented route without stub
+	w = serve("/missing")
+	if w.Code != http.StatusNotImplemented {
+		t.Errorf(err)
+	}
+
+	// Test mock mode
+	v.SetMockMode(true)
+	w = serve("/orders")
+	if w.Code != 200 || w.Body.String() != "stubbed" {
+		t.Errorf(err)
+	}
+}
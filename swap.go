@@ -0,0 +1,84 @@
+package verto
+
+import (
+	"errors"
+	"github.com/boxtown/verto/mux"
+)
+
+// ErrNoRollback is returned by Rollback if no route
+// table has been swapped out
+var ErrNoRollback = errors.New("verto: no route table to roll back to")
+
+// Stage returns a Verto instance with an empty route table for building
+// a complete alternate set of routes offline. The staged instance shares
+// injections, logger, event bus and audit trail with v and starts out with
+// copies of v's settings and handlers. The admin API is carried over if it
+// is enabled. Routes and global plugins added to the staged instance do not
+// affect v until the staged table is installed with Swap. Routes of the
+// staged table keep using the error and response handlers of the staged
+// instance after the swap.
+//
+// Example usage:
+//
+//	green := v.Stage()
+//	green.Use(recovery.New())
+//	green.Get("/users/{id}", getUserV2)
+//	v.Swap(green)
+//	...
+//	v.Rollback()
+func (v *Verto) Stage() *Verto {
+	staged := *v
+	staged.muxer = mux.New()
+	staged.muxer.Strict = v.muxer.Strict
+	staged.muxer.AutoOptions = v.muxer.AutoOptions
+	staged.muxer.AutoHead = v.muxer.AutoHead
+	staged.muxer.FormParams = v.muxer.FormParams
+	staged.muxer.NotFound = v.muxer.NotFound
+	staged.muxer.NotImplemented = v.muxer.NotImplemented
+	staged.muxer.MethodNotAllowed = v.muxer.MethodNotAllowed
+	staged.muxer.Options = v.muxer.Options
+	staged.muxer.Redirect = v.muxer.Redirect
+	staged.previous = nil
+	staged.setInjectionPlugins()
+	if v.admin != nil {
+		staged.registerAdmin(v, v.admin)
+	}
+	return &staged
+}
+
+// Swap atomically installs the route table of the staged instance as
+// v's route table. Requests in flight finish on the old table while new
+// requests are served by the staged table. Routes added to v afterwards
+// are added to the new table. The old table is kept for Rollback
+func (v *Verto) Swap(staged *Verto) {
+	v.mutex.Lock()
+	v.previous = v.muxer
+	v.muxer = staged.muxer
+	v.mutex.Unlock()
+
+	v.audit(AuditTableSwapped, AuditSourceAPI, "staged route table installed")
+}
+
+// Rollback atomically reinstalls the route table swapped out by the
+// last Swap. Rolling back twice undoes the rollback. Returns
+// ErrNoRollback if no table has been swapped out
+func (v *Verto) Rollback() error {
+	v.mutex.Lock()
+	if v.previous == nil {
+		v.mutex.Unlock()
+		return ErrNoRollback
+	}
+	v.muxer, v.previous = v.previous, v.muxer
+	v.mutex.Unlock()
+
+	v.audit(AuditTableSwapped, AuditSourceAPI, "route table rolled back")
+	return nil
+}
+
+// table returns the current route table
+func (v *Verto) table() *mux.PathMuxer {
+	v.mutex.RLock()
+	defer v.mutex.RUnlock()
+
+	return v.muxer
+}
@@ -0,0 +1,141 @@
+package verto
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestSwap(t *testing.T) {
+	defer func() {
+		err := recover()
+		if err != nil {
+			t.Errorf(err.(error).Error())
+		}
+	}()
+
+	err := "Failed swap."
+
+	v := New()
+	v.Logger = &NilLogger{}
+	v.Injections.Set("color", "blue")
+	v.Get("/color", func(c *Context) (interface{}, error) {
+		return "blue", nil
+	})
+	h := &HttpHandler{v}
+
+	serve := func(path string) *httptest.ResponseRecorder {
+		r, _ := http.NewRequest("GET", "http://test.com"+path, nil)
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		return w
+	}
+
+	if v.Rollback() != ErrNoRollback {
+		t.Errorf(err)
+	}
+
+	green := v.Stage()
+	green.Get("/color", func(c *Context) (interface{}, error) {
+		return "green:" + c.Injections().Get("color").(string), nil
+	})
+	green.Get("/new", func(c *Context) (interface{}, error) {
+		return "new", nil
+	})
+
+	// Test staged routes are not served before the swap
+	if serve("/color").Body.String() != "blue" || serve("/new").Code != 404 {
+		t.Errorf(err)
+	}
+
+	v.Swap(green)
+	if serve("/color").Body.String() != "green:blue" || serve("/new").Body.String() != "new" {
+		t.Errorf(err)
+	}
+
+	// Test routes added after the swap go to the live table
+	v.Get("/later", func(c *Context) (interface{}, error) {
+		return "later", nil
+	})
+	if serve("/later").Body.String() != "later" {
+		t.Errorf(err)
+	}
+
+	if v.Rollback() != nil || serve("/color").Body.String() != "blue" || serve("/new").Code != 404 {
+		t.Errorf(err)
+	}
+	if v.Rollback() != nil || serve("/color").Body.String() != "green:blue" {
+		t.Errorf(err)
+	}
+}
+
+func TestSwapConcurrent(t *testing.T) {
+	defer func() {
+		err := recover()
+		if err != nil {
+			t.Errorf(err.(error).Error())
+		}
+	}()
+
+	err := "Failed swap concurrent."
+
+	v := New()
+	v.Logger = &NilLogger{}
+	v.SetAutoOptions(true)
+	v.Get("/color", func(c *Context) (interface{}, error) {
+		return "blue", nil
+	}).Name("color")
+	v.File("/missing", "does-not-exist")
+	green := v.Stage()
+	green.Get("/color", func(c *Context) (interface{}, error) {
+		return "green", nil
+	}).Name("color")
+	green.File("/missing", "does-not-exist")
+	h := &HttpHandler{v}
+
+	// Serve requests touching the route table while swapping
+	// tables. Run with -race to detect unsynchronized reads
+	done := make(chan bool)
+	started := &sync.WaitGroup{}
+	failed := make(chan bool, 1)
+	started.Add(4)
+	for _, target := range []string{"GET /color", "GET /missing", "OPTIONS /color", "GET /none"} {
+		go func(method, path string) {
+			for i := 0; ; i++ {
+				if i == 1 {
+					started.Done()
+				}
+				select {
+				case <-done:
+					return
+				default:
+				}
+				r, _ := http.NewRequest(method, "http://test.com"+path, nil)
+				w := httptest.NewRecorder()
+				h.ServeHTTP(w, r)
+				if _, e := v.URL("color"); w.Code >= 500 || e != nil || len(v.Routes()) == 0 {
+					select {
+					case failed <- true:
+					default:
+					}
+				}
+			}
+		}(strings.Fields(target)[0], strings.Fields(target)[1])
+	}
+	started.Wait()
+	v.Swap(green)
+	for i := 0; i < 100; i++ {
+		if v.Rollback() != nil {
+			t.Errorf(err)
+		}
+	}
+	close(done)
+
+	select {
+	case <-failed:
+		t.Errorf(err)
+	default:
+	}
+}
@@ -0,0 +1,341 @@
+// Package templates provides a registry of html/template templates and
+// a ResponseHandler rendering them. Templates are loaded from a directory
+// or an fs.FS such as an embed.FS and named by their slash-separated path relative to it without the
+// extension (e.g. users/show). Sanitization helpers from the sanitize
+// package are available to all templates. In development, registries
+// can watch their directory and reload templates as they change.
+package templates
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/boxtown/verto"
+	"github.com/boxtown/verto/sanitize"
+	"html/template"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultWatchInterval is the interval at which AutoReload
+// polls the template directory for changes
+var DefaultWatchInterval = 500 * time.Millisecond
+
+// View is a response rendered by the template registry.
+// Resource functions return Views to render HTML
+//
+// Example usage:
+//
+//	v.Get("/users/{id}", func(c *verto.Context) (interface{}, error) {
+//		return &templates.View{Name: "users/show", Data: user}, nil
+//	})
+type View struct {
+	// Name is the name of the template to render
+	Name string
+
+	// Data is passed to the template
+	Data interface{}
+
+	// Status is the response status. Defaults to 200
+	Status int
+}
+
+// Registry is a set of named templates loaded from a directory.
+//
+// Example usage:
+//
+//	reg := templates.New("./views")
+//	if err := reg.Load(); err != nil {
+//		log.Fatal(err)
+//	}
+//	v.ResponseHandler = reg.ResponseHandler(v.ResponseHandler)
+//	reg.AutoReload(v)
+type Registry struct {
+	// Dir is the directory templates are loaded from
+	Dir string
+
+	// FS is the file system templates are loaded from. If set,
+	// it is used in place of Dir
+	FS fs.FS
+
+	// Ext is the extension of template files. Defaults to .html
+	Ext string
+
+	// Policy is the sanitization policy used by the sanitize
+	// template function. Defaults to sanitize.UGCPolicy
+	Policy *sanitize.Policy
+
+	// Logger receives reload failures of a watched registry
+	Logger verto.Logger
+
+	funcs   template.FuncMap
+	set     *template.Template
+	loadErr error
+	stop    chan struct{}
+	mutex   sync.RWMutex
+}
+
+// New returns a Registry loading templates from dir
+func New(dir string) *Registry {
+	return &Registry{Dir: dir, funcs: template.FuncMap{}}
+}
+
+// NewFS returns a Registry loading templates from fsys. Templates
+// can be embedded in the binary with an embed.FS
+//
+// Example usage:
+//
+//	//go:embed views
+//	var views embed.FS
+//
+//	sub, _ := fs.Sub(views, "views")
+//	reg := templates.NewFS(sub)
+func NewFS(fsys fs.FS) *Registry {
+	return &Registry{FS: fsys, funcs: template.FuncMap{}}
+}
+
+// Funcs adds fm to the functions available to templates. Funcs
+// must be called before Load. Returns the registry for chaining
+func (reg *Registry) Funcs(fm template.FuncMap) *Registry {
+	reg.mutex.Lock()
+	defer reg.mutex.Unlock()
+
+	for k, f := range fm {
+		reg.funcs[k] = f
+	}
+	return reg
+}
+
+// Load parses all templates in the registry's directory, replacing
+// any previously loaded templates. If parsing fails, previously loaded
+// templates are kept
+func (reg *Registry) Load() error {
+	ext := reg.ext()
+	set := template.New("").Funcs(reg.funcMap())
+
+	fsys := reg.fsys()
+	err := fs.WalkDir(fsys, ".", func(p string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() || path.Ext(p) != ext {
+			return err
+		}
+		b, err := fs.ReadFile(fsys, p)
+		if err != nil {
+			return err
+		}
+		_, err = set.New(strings.TrimSuffix(p, ext)).Parse(string(b))
+		return err
+	})
+
+	reg.mutex.Lock()
+	defer reg.mutex.Unlock()
+	reg.loadErr = err
+	if err != nil {
+		return err
+	}
+	reg.set = set
+	return nil
+}
+
+// Err returns the error of the last Load or nil if it succeeded
+func (reg *Registry) Err() error {
+	reg.mutex.RLock()
+	defer reg.mutex.RUnlock()
+
+	return reg.loadErr
+}
+
+// AutoReload watches the registry's directory with DefaultWatchInterval
+// if v runs in the development environment and returns whether it does.
+// Reload failures are logged to v's Logger unless the registry has its
+// own, and rendering fails with an error page showing the failure until
+// the templates are fixed
+func (reg *Registry) AutoReload(v *verto.Verto) bool {
+	if v.Environment() != verto.Development {
+		return false
+	}
+	if reg.Logger == nil {
+		reg.Logger = v.Logger
+	}
+	reg.Watch(DefaultWatchInterval)
+	return true
+}
+
+// Watch polls the registry's directory every interval and reloads
+// the templates when template files are added, removed or modified.
+// Watch does nothing if the registry is already watched
+func (reg *Registry) Watch(interval time.Duration) {
+	reg.mutex.Lock()
+	defer reg.mutex.Unlock()
+
+	if reg.stop != nil {
+		return
+	}
+	reg.stop = make(chan struct{})
+	go reg.watch(interval, reg.stop, reg.fingerprint())
+}
+
+// Stop stops watching the registry's directory
+func (reg *Registry) Stop() {
+	reg.mutex.Lock()
+	defer reg.mutex.Unlock()
+
+	if reg.stop != nil {
+		close(reg.stop)
+		reg.stop = nil
+	}
+}
+
+// watch reloads the templates whenever the fingerprint
+// of the directory changes until stop is closed
+func (reg *Registry) watch(interval time.Duration, stop chan struct{}, last string) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			fp := reg.fingerprint()
+			if fp == last {
+				continue
+			}
+			last = fp
+			if err := reg.Load(); err != nil {
+				if reg.Logger != nil {
+					reg.Logger.Errorf("templates: could not reload: %s", err.Error())
+				}
+			} else if reg.Logger != nil {
+				reg.Logger.Infof("templates: reloaded")
+			}
+		}
+	}
+}
+
+// fingerprint returns a summary of the names, sizes and
+// modification times of the template files in the directory
+func (reg *Registry) fingerprint() string {
+	ext := reg.ext()
+	buf := &bytes.Buffer{}
+	fs.WalkDir(reg.fsys(), ".", func(p string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() || path.Ext(p) != ext {
+			return nil
+		}
+		if info, err := d.Info(); err == nil {
+			fmt.Fprintf(buf, "%s:%d:%d;", p, info.Size(), info.ModTime().UnixNano())
+		}
+		return nil
+	})
+	return buf.String()
+}
+
+// fsys returns the file system templates are loaded from
+func (reg *Registry) fsys() fs.FS {
+	if reg.FS != nil {
+		return reg.FS
+	}
+	return os.DirFS(reg.Dir)
+}
+
+// Render executes the template name with data to w. The template is
+// executed into a buffer first so that nothing is written on failure
+func (reg *Registry) Render(w io.Writer, name string, data interface{}) error {
+	reg.mutex.RLock()
+	set := reg.set
+	reg.mutex.RUnlock()
+
+	if set == nil || set.Lookup(name) == nil {
+		return fmt.Errorf("templates: no template %q", name)
+	}
+	buf := &bytes.Buffer{}
+	if err := set.ExecuteTemplate(buf, name, data); err != nil {
+		return err
+	}
+	_, err := buf.WriteTo(w)
+	return err
+}
+
+// ResponseHandler returns a ResponseHandler rendering View responses
+// as HTML and passing all other responses to fallback. Rendering
+// failures result in a 500 response. In the development environment,
+// the response is an error page showing the failure and, if the last
+// Load failed, its error is shown in place of every view
+func (reg *Registry) ResponseHandler(fallback verto.ResponseHandler) verto.ResponseHandler {
+	return verto.ResponseFunc(func(response interface{}, c *verto.Context) {
+		view, ok := response.(*View)
+		if !ok {
+			if fallback != nil {
+				fallback.Handle(response, c)
+			}
+			return
+		}
+
+		buf := &bytes.Buffer{}
+		err := reg.Err()
+		if err == nil || !c.Debug() {
+			err = reg.Render(buf, view.Name, view.Data)
+		}
+		if err != nil {
+			if c.Logger != nil {
+				c.Logger.Errorf("templates: could not render %s: %s", view.Name, err.Error())
+			}
+			if c.Debug() {
+				c.Response.Header().Set("Content-Type", "text/html; charset=utf-8")
+				c.Response.WriteHeader(500)
+				errorPage.Execute(c.Response, map[string]string{"Name": view.Name, "Err": err.Error()})
+				return
+			}
+			c.Response.WriteHeader(500)
+			fmt.Fprint(c.Response, "Internal Server Error.")
+			return
+		}
+
+		c.Response.Header().Set("Content-Type", "text/html; charset=utf-8")
+		if view.Status != 0 {
+			c.Response.WriteHeader(view.Status)
+		}
+		buf.WriteTo(c.Response)
+	})
+}
+
+// errorPage is the page rendered for template
+// failures in the development environment
+var errorPage = template.Must(template.New("error").Parse(`<!DOCTYPE html>
+<html>
+<head><title>Template Error</title></head>
+<body style="font-family: sans-serif; margin: 2em;">
+<h1>Template Error</h1>
+<p>Could not render <code>{{.Name}}</code>:</p>
+<pre style="background: #fee; padding: 1em; white-space: pre-wrap;">{{.Err}}</pre>
+<p>Fix the template and reload the page.</p>
+</body>
+</html>
+`))
+
+// funcMap returns the sanitization helpers overlaid
+// with the functions added through Funcs
+func (reg *Registry) funcMap() template.FuncMap {
+	policy := reg.Policy
+	if policy == nil {
+		policy = sanitize.UGCPolicy()
+	}
+	fm := sanitize.FuncMap(policy)
+
+	reg.mutex.RLock()
+	defer reg.mutex.RUnlock()
+	for k, f := range reg.funcs {
+		fm[k] = f
+	}
+	return fm
+}
+
+func (reg *Registry) ext() string {
+	if reg.Ext == "" {
+		return ".html"
+	}
+	return reg.Ext
+}
@@ -0,0 +1,188 @@
+package templates
+
+import (
+	"bytes"
+	"github.com/boxtown/verto"
+	"html/template"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+func TestRegistry(t *testing.T) {
+	defer func() {
+		err := recover()
+		if err != nil {
+			t.Errorf(err.(error).Error())
+		}
+	}()
+
+	err := "Failed registry."
+
+	dir, _ := ioutil.TempDir("", "templates")
+	defer os.RemoveAll(dir)
+	os.MkdirAll(filepath.Join(dir, "users"), 0755)
+	ioutil.WriteFile(filepath.Join(dir, "users", "show.html"),
+		[]byte(`<h1>{{.Name}}</h1>{{sanitize .Bio}}{{shout "x"}}`), 0644)
+	ioutil.WriteFile(filepath.Join(dir, "ignored.txt"), []byte(`{{`), 0644)
+
+	reg := New(dir).Funcs(template.FuncMap{"shout": strings.ToUpper})
+	if e := reg.Load(); e != nil {
+		t.Fatalf(e.Error())
+	}
+
+	v := verto.New()
+	v.Logger = &verto.NilLogger{}
+	v.ResponseHandler = reg.ResponseHandler(v.ResponseHandler)
+	v.Get("/users/1", func(c *verto.Context) (interface{}, error) {
+		return &View{
+			Name: "users/show",
+			Data: map[string]string{"Name": "<x>", "Bio": "<b>bio</b><script>s</script>"},
+		}, nil
+	})
+	v.Get("/missing", func(c *verto.Context) (interface{}, error) {
+		return &View{Name: "missing"}, nil
+	})
+	v.Get("/plain", func(c *verto.Context) (interface{}, error) {
+		return "plain", nil
+	})
+	h := &verto.HttpHandler{v}
+
+	serve := func(path string) *httptest.ResponseRecorder {
+		r, _ := http.NewRequest("GET", "http://test.com"+path, nil)
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		return w
+	}
+
+	w := serve("/users/1")
+	if w.Code != 200 || w.Body.String() != "<h1>&lt;x&gt;</h1><b>bio</b>X" {
+		t.Errorf(err)
+	}
+	if w.Header().Get("Content-Type") != "text/html; charset=utf-8" {
+		t.Errorf(err)
+	}
+	if serve("/missing").Code != 500 {
+		t.Errorf(err)
+	}
+	if w = serve("/plain"); w.Body.String() != "plain" {
+		t.Errorf(err)
+	}
+
+	// Test failed reloads keep loaded templates
+	ioutil.WriteFile(filepath.Join(dir, "broken.html"), []byte(`{{`), 0644)
+	if reg.Load() == nil {
+		t.Errorf(err)
+	}
+	if serve("/users/1").Code != 200 {
+		t.Errorf(err)
+	}
+}
+
+func TestRegistryAutoReload(t *testing.T) {
+	defer func() {
+		err := recover()
+		if err != nil {
+			t.Errorf(err.(error).Error())
+		}
+	}()
+
+	err := "Failed registry auto reload."
+
+	dir, _ := ioutil.TempDir("", "templates")
+	defer os.RemoveAll(dir)
+	ioutil.WriteFile(filepath.Join(dir, "index.html"), []byte(`v1`), 0644)
+
+	reg := New(dir)
+	if e := reg.Load(); e != nil {
+		t.Fatalf(e.Error())
+	}
+
+	v := verto.New()
+	if reg.AutoReload(v) {
+		t.Errorf(err)
+	}
+	v.SetEnvironment(verto.Development)
+	v.Logger = &verto.NilLogger{}
+	v.ResponseHandler = reg.ResponseHandler(v.ResponseHandler)
+	v.Get("/", func(c *verto.Context) (interface{}, error) {
+		return &View{Name: "index"}, nil
+	})
+	h := &verto.HttpHandler{v}
+
+	serve := func() *httptest.ResponseRecorder {
+		r, _ := http.NewRequest("GET", "http://test.com/", nil)
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		return w
+	}
+	await := func(fn func(w *httptest.ResponseRecorder) bool) bool {
+		for i := 0; i < 200; i++ {
+			if fn(serve()) {
+				return true
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+		return false
+	}
+
+	interval := DefaultWatchInterval
+	DefaultWatchInterval = 10 * time.Millisecond
+	defer func() { DefaultWatchInterval = interval }()
+
+	if !reg.AutoReload(v) {
+		t.Fatalf(err)
+	}
+	defer reg.Stop()
+
+	// Test modified templates are reloaded
+	ioutil.WriteFile(filepath.Join(dir, "index.html"), []byte(`version 2`), 0644)
+	if !await(func(w *httptest.ResponseRecorder) bool { return w.Body.String() == "version 2" }) {
+		t.Errorf(err)
+	}
+
+	// Test parse errors are shown until fixed
+	ioutil.WriteFile(filepath.Join(dir, "index.html"), []byte(`{{.Broken`), 0644)
+	if !await(func(w *httptest.ResponseRecorder) bool {
+		return w.Code == 500 && strings.Contains(w.Body.String(), "Template Error")
+	}) {
+		t.Errorf(err)
+	}
+	if reg.Err() == nil {
+		t.Errorf(err)
+	}
+	ioutil.WriteFile(filepath.Join(dir, "index.html"), []byte(`version 3`), 0644)
+	if !await(func(w *httptest.ResponseRecorder) bool { return w.Body.String() == "version 3" }) {
+		t.Errorf(err)
+	}
+}
+
+func TestRegistryFS(t *testing.T) {
+	defer func() {
+		err := recover()
+		if err != nil {
+			t.Errorf(err.(error).Error())
+		}
+	}()
+
+	err := "Failed registry fs."
+
+	reg := NewFS(fstest.MapFS{
+		"users/show.html": {Data: []byte(`{{template "layout" .}}`)},
+		"layout.html":     {Data: []byte(`{{define "layout"}}<p>{{.}}</p>{{end}}`)},
+		"notes.txt":       {Data: []byte(`{{`)},
+	})
+	if e := reg.Load(); e != nil {
+		t.Fatalf(e.Error())
+	}
+	buf := &bytes.Buffer{}
+	if e := reg.Render(buf, "users/show", "x"); e != nil || buf.String() != "<p>x</p>" {
+		t.Errorf(err)
+	}
+}
@@ -0,0 +1,96 @@
+package verto
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"net/http"
+)
+
+// TLSInfo describes the TLS connection a request was received on
+type TLSInfo struct {
+	// Version is the negotiated TLS version (e.g. tls.VersionTLS13)
+	Version uint16
+
+	// CipherSuite is the negotiated cipher suite
+	CipherSuite uint16
+
+	// Protocol is the protocol negotiated through ALPN (e.g. h2)
+	Protocol string
+
+	// ServerName is the server name sent by the client through SNI
+	ServerName string
+
+	// PeerCertificates are the certificates presented by the
+	// client, leaf first. Empty unless client certificates are
+	// requested through the server's TLSConfig
+	PeerCertificates []*x509.Certificate
+
+	// Resumed is whether the session was resumed
+	Resumed bool
+}
+
+// VersionName returns the name of the negotiated TLS version (e.g. TLS 1.3)
+func (info *TLSInfo) VersionName() string {
+	return tls.VersionName(info.Version)
+}
+
+// CipherSuiteName returns the name of the negotiated cipher suite
+func (info *TLSInfo) CipherSuiteName() string {
+	return tls.CipherSuiteName(info.CipherSuite)
+}
+
+// ClientCertificate returns the leaf certificate presented
+// by the client or nil if none was presented
+func (info *TLSInfo) ClientCertificate() *x509.Certificate {
+	if len(info.PeerCertificates) == 0 {
+		return nil
+	}
+	return info.PeerCertificates[0]
+}
+
+// TLS returns the details of the TLS connection the request was
+// received on or nil if the request was not received over TLS.
+// Requests forwarded by TLS-terminating proxies are not received
+// over TLS
+func (c *Context) TLS() *TLSInfo {
+	if c.Request == nil || c.Request.TLS == nil {
+		return nil
+	}
+	state := c.Request.TLS
+	return &TLSInfo{
+		Version:          state.Version,
+		CipherSuite:      state.CipherSuite,
+		Protocol:         state.NegotiatedProtocol,
+		ServerName:       state.ServerName,
+		PeerCertificates: state.PeerCertificates,
+		Resumed:          state.DidResume,
+	}
+}
+
+// MinTLSVersion returns a Plugin that only lets through requests received
+// over TLS with at least version (e.g. tls.VersionTLS12). Other requests,
+// including requests not received over TLS, receive a 403 response.
+//
+// Example usage:
+//
+//	payments := v.Group("POST", "/payments")
+//	payments.Use(verto.MinTLSVersion(tls.VersionTLS12))
+func MinTLSVersion(version uint16) Plugin {
+	return TLSPolicy(func(info *TLSInfo) bool {
+		return info.Version >= version
+	})
+}
+
+// TLSPolicy returns a Plugin that only lets through requests received
+// over TLS whose connection details are accepted by allow. Other requests,
+// including requests not received over TLS, receive a 403 response
+func TLSPolicy(allow func(info *TLSInfo) bool) Plugin {
+	return PluginFunc(func(c *Context, next http.HandlerFunc) {
+		if info := c.TLS(); info != nil && allow(info) {
+			next(c.Response, c.Request)
+			return
+		}
+		c.Response.WriteHeader(http.StatusForbidden)
+		c.Response.Write([]byte("Forbidden."))
+	})
+}
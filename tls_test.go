@@ -0,0 +1,69 @@
+package verto
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestContextTLS(t *testing.T) {
+	defer func() {
+		err := recover()
+		if err != nil {
+			t.Errorf(err.(error).Error())
+		}
+	}()
+
+	err := "Failed context TLS."
+
+	v := New()
+	v.Logger = &NilLogger{}
+	v.Get("/info", func(c *Context) (interface{}, error) {
+		info := c.TLS()
+		if info == nil {
+			return "plain", nil
+		}
+		return info.VersionName() + " " + info.Protocol + " " + info.ServerName + " " + info.ClientCertificate().Subject.CommonName, nil
+	})
+	v.Group("GET", "/secure").Use(MinTLSVersion(tls.VersionTLS12)).Add("/data", func(c *Context) (interface{}, error) {
+		return "data", nil
+	})
+	h := &HttpHandler{v}
+
+	serve := func(path string, state *tls.ConnectionState) *httptest.ResponseRecorder {
+		r, _ := http.NewRequest("GET", "https://test.com"+path, nil)
+		r.TLS = state
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		return w
+	}
+
+	cert := &x509.Certificate{}
+	cert.Subject.CommonName = "client"
+	state := &tls.ConnectionState{
+		Version:            tls.VersionTLS13,
+		CipherSuite:        tls.TLS_AES_128_GCM_SHA256,
+		NegotiatedProtocol: "h2",
+		ServerName:         "api.test.com",
+		PeerCertificates:   []*x509.Certificate{cert},
+	}
+	if serve("/info", state).Body.String() != "TLS 1.3 h2 api.test.com client" {
+		t.Errorf(err)
+	}
+	if serve("/info", nil).Body.String() != "plain" {
+		t.Errorf(err)
+	}
+
+	// Test TLS policies
+	if serve("/secure/data", state).Code != 200 {
+		t.Errorf(err)
+	}
+	if serve("/secure/data", &tls.ConnectionState{Version: tls.VersionTLS10}).Code != 403 {
+		t.Errorf(err)
+	}
+	if serve("/secure/data", nil).Code != 403 {
+		t.Errorf(err)
+	}
+}
@@ -0,0 +1,86 @@
+package verto
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"hash"
+	"net/http"
+)
+
+// DownloadDigestKey is the endpoint metadata key enabling a Digest
+// trailer with the SHA-256 checksum of complete download responses.
+//
+// Example usage:
+//
+//	v.Download("/files/{id}", opener).Meta(verto.DownloadDigestKey, true)
+const DownloadDigestKey = "verto.download.digest"
+
+// AddTrailer declares name as a trailer of the response. Trailers must
+// be declared before the response body is written and their values set
+// with SetTrailer once the body is written. Declaring a trailer removes
+// any Content-Length so that the response is sent chunked, which trailers
+// require. Responses compressed by the compression plugin keep their
+// trailers
+func (c *Context) AddTrailer(name string) {
+	h := c.Response.Header()
+	h.Add("Trailer", http.CanonicalHeaderKey(name))
+	h.Del("Content-Length")
+}
+
+// SetTrailer sets the value of the trailer name. Trailers that were
+// not declared with AddTrailer are still sent if the response is chunked
+func (c *Context) SetTrailer(name, value string) {
+	c.Response.Header().Set(http.TrailerPrefix+http.CanonicalHeaderKey(name), value)
+}
+
+// digestWriter is an http.ResponseWriter that computes the SHA-256
+// checksum of complete (200) responses and sends it as a Digest trailer
+type digestWriter struct {
+	http.ResponseWriter
+
+	hash   hash.Hash
+	status int
+}
+
+func newDigestWriter(w http.ResponseWriter) *digestWriter {
+	return &digestWriter{ResponseWriter: w, hash: sha256.New()}
+}
+
+func (w *digestWriter) WriteHeader(code int) {
+	if w.status == 0 {
+		w.status = code
+		if code == http.StatusOK {
+			w.Header().Add("Trailer", "Digest")
+			w.Header().Del("Content-Length")
+		}
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *digestWriter) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.WriteHeader(http.StatusOK)
+	}
+	n, err := w.ResponseWriter.Write(b)
+	w.hash.Write(b[:n])
+	return n, err
+}
+
+// finish sets the Digest trailer for complete responses
+func (w *digestWriter) finish() {
+	if w.status == http.StatusOK {
+		w.Header().Set("Digest", "sha-256="+base64.StdEncoding.EncodeToString(w.hash.Sum(nil)))
+	}
+}
+
+// Flush implements http.Flusher
+func (w *digestWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Unwrap returns the wrapped ResponseWriter
+func (w *digestWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
@@ -0,0 +1,75 @@
+package verto
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+)
+
+func TestTrailers(t *testing.T) {
+	defer func() {
+		err := recover()
+		if err != nil {
+			t.Errorf(err.(error).Error())
+		}
+	}()
+
+	err := "Failed trailers."
+
+	v := New()
+	v.Logger = &NilLogger{}
+	v.GetHandler("/stream", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		c := v.context(w, r)
+		w.Header().Set("Content-Length", "4")
+		c.AddTrailer("Grpc-Status")
+		w.Write([]byte("data"))
+		c.SetTrailer("Grpc-Status", "0")
+		c.SetTrailer("X-Undeclared", "1")
+	}))
+	v.Download("/files/{name}", func(c *Context) (io.ReadSeeker, os.FileInfo, error) {
+		return bytes.NewReader([]byte("0123456789")), testFileInfo{"report.txt", 10}, nil
+	}).Meta(DownloadDigestKey, true)
+	s := httptest.NewServer(&HttpHandler{v})
+	defer s.Close()
+
+	res, e := http.Get(s.URL + "/stream")
+	if e != nil {
+		t.Fatalf(err)
+	}
+	b, _ := ioutil.ReadAll(res.Body)
+	res.Body.Close()
+	if string(b) != "data" || res.Trailer.Get("Grpc-Status") != "0" || res.Trailer.Get("X-Undeclared") != "1" {
+		t.Errorf(err)
+	}
+
+	// Test download digest
+	sum := sha256.Sum256([]byte("0123456789"))
+	res, e = http.Get(s.URL + "/files/report.txt")
+	if e != nil {
+		t.Fatalf(err)
+	}
+	b, _ = ioutil.ReadAll(res.Body)
+	res.Body.Close()
+	if string(b) != "0123456789" || res.Trailer.Get("Digest") != "sha-256="+base64.StdEncoding.EncodeToString(sum[:]) {
+		t.Errorf(err)
+	}
+
+	// Test partial content carries no digest
+	r, _ := http.NewRequest("GET", s.URL+"/files/report.txt", nil)
+	r.Header.Set("Range", "bytes=2-4")
+	res, e = http.DefaultClient.Do(r)
+	if e != nil {
+		t.Fatalf(err)
+	}
+	ioutil.ReadAll(res.Body)
+	res.Body.Close()
+	if res.StatusCode != http.StatusPartialContent || res.Trailer.Get("Digest") != "" {
+		t.Errorf(err)
+	}
+}
@@ -0,0 +1,67 @@
+package verto
+
+import (
+	"context"
+	"github.com/boxtown/verto/mux"
+	"net/http"
+)
+
+// TransformFunc transforms the response of a ResourceFunc
+// before it is passed to the ResponseHandler
+type TransformFunc func(response interface{}, c *Context) interface{}
+
+// transformsKey is the context key of the groupTransforms of a request
+type transformsKey struct{}
+
+// TransformResponse adds transform to the Group. Responses of
+// ResourceFuncs under the Group are passed through transform before they
+// are passed to the ResponseHandler, enabling envelopes, renamed fields
+// for legacy clients or masked data per API version. Transforms of inner
+// Groups run before transforms of outer Groups and transforms of the
+// same Group run in the order they were added. Errors and NoContent
+// responses are not transformed.
+//
+// Example usage:
+//
+//	v1 := v.Group("GET", "/v1")
+//	v1.TransformResponse(func(response interface{}, c *verto.Context) interface{} {
+//		return map[string]interface{}{"data": response}
+//	})
+func (g *Group) TransformResponse(transform TransformFunc) *Group {
+	owner := g.g
+	return g.UsePluginHandler(mux.PluginFunc(func(w http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+		transforms, _ := r.Context().Value(transformsKey{}).([]groupTransform)
+		transforms = append(transforms[:len(transforms):len(transforms)], groupTransform{owner, transform})
+		next(w, r.WithContext(context.WithValue(r.Context(), transformsKey{}, transforms)))
+	}))
+}
+
+// groupTransform is a TransformFunc and the Group it was added to
+type groupTransform struct {
+	owner mux.Group
+	fn    TransformFunc
+}
+
+// transform returns response passed through the
+// TransformFuncs of the Groups of the request of c
+func transform(response interface{}, c *Context) interface{} {
+	if c.Request == nil {
+		return response
+	}
+	transforms, _ := c.Request.Context().Value(transformsKey{}).([]groupTransform)
+
+	// Transforms are attached outermost Group first. Apply the
+	// Groups in reverse, each Group's transforms in order
+	end := len(transforms)
+	for end > 0 {
+		start := end - 1
+		for start > 0 && transforms[start-1].owner == transforms[end-1].owner {
+			start--
+		}
+		for _, t := range transforms[start:end] {
+			response = t.fn(response, c)
+		}
+		end = start
+	}
+	return response
+}
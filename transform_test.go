@@ -0,0 +1,59 @@
+package verto
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestTransformResponse(t *testing.T) {
+	defer func() {
+		err := recover()
+		if err != nil {
+			t.Errorf(err.(error).Error())
+		}
+	}()
+
+	err := "Failed transform response."
+
+	wrap := func(prefix string) TransformFunc {
+		return func(response interface{}, c *Context) interface{} {
+			return prefix + "(" + response.(string) + ")"
+		}
+	}
+
+	v := New()
+	v.Logger = &NilLogger{}
+	ok := func(c *Context) (interface{}, error) {
+		return "ok", nil
+	}
+	api := v.Group("GET", "/api")
+	api.TransformResponse(wrap("envelope")).TransformResponse(wrap("second"))
+	api.Add("/ok", ok)
+	api.Add("/bad", func(c *Context) (interface{}, error) {
+		return nil, errors.New("bad")
+	})
+	api.Group("/v1").TransformResponse(wrap("legacy")).Add("/ok", ok)
+	v.Get("/ok", ok)
+	h := &HttpHandler{v}
+
+	serve := func(path string) string {
+		r, _ := http.NewRequest("GET", "http://test.com"+path, nil)
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		return w.Body.String()
+	}
+
+	expected := map[string]string{
+		"/api/ok":    "second(envelope(ok))",
+		"/api/v1/ok": "second(envelope(legacy(ok)))",
+		"/api/bad":   "bad",
+		"/ok":        "ok",
+	}
+	for path, body := range expected {
+		if serve(path) != body {
+			t.Errorf(err)
+		}
+	}
+}
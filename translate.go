@@ -0,0 +1,135 @@
+package verto
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/boxtown/verto/form"
+	"io/fs"
+	"net/http"
+	"reflect"
+	"sync"
+)
+
+// Translation describes the HTTP response for a class of errors
+type Translation struct {
+	// Status is the HTTP status of the response
+	Status int
+
+	// Message is the public message of the response.
+	// Defaults to the status text
+	Message string
+
+	// Expose uses the error's own message as public message.
+	// It should only be set for errors whose messages are
+	// safe to show to clients (e.g. validation errors)
+	Expose bool
+}
+
+// ErrorTranslator maps errors returned by third-party libraries to HTTP
+// statuses and public messages so that handlers can return raw library
+// errors safely. Errors are matched in registration order against the
+// sentinel errors, error types and functions registered. ErrorTranslator
+// is safe for concurrent use.
+//
+// Example usage:
+//
+//	verto.DefaultTranslator.Register(redis.Nil, verto.Translation{Status: 404})
+//	verto.DefaultTranslator.RegisterType(validator.ValidationErrors{}, verto.Translation{
+//		Status: 422,
+//		Expose: true,
+//	})
+type ErrorTranslator struct {
+	entries []translationEntry
+	mutex   *sync.RWMutex
+}
+
+type translationEntry struct {
+	match func(err error) bool
+	t     Translation
+}
+
+// NewErrorTranslator returns an empty ErrorTranslator
+func NewErrorTranslator() *ErrorTranslator {
+	return &ErrorTranslator{
+		entries: make([]translationEntry, 0),
+		mutex:   &sync.RWMutex{},
+	}
+}
+
+// Register maps errors matching target with errors.Is to t
+func (et *ErrorTranslator) Register(target error, t Translation) {
+	et.RegisterFunc(func(err error) bool { return errors.Is(err, target) }, t)
+}
+
+// RegisterType maps errors wrapping an error of the
+// same type as example to t
+func (et *ErrorTranslator) RegisterType(example error, t Translation) {
+	et.RegisterFunc(matchType(example), t)
+}
+
+// RegisterFunc maps errors for which match returns true to t
+func (et *ErrorTranslator) RegisterFunc(match func(err error) bool, t Translation) {
+	et.mutex.Lock()
+	defer et.mutex.Unlock()
+
+	et.entries = append(et.entries, translationEntry{match: match, t: t})
+}
+
+// Translate returns err as an HTTPError wrapping err if a registration
+// matches it. Errors that already carry a status (HTTPErrors)
+// and errors without a match are returned as is
+func (et *ErrorTranslator) Translate(err error) error {
+	if et == nil || err == nil {
+		return err
+	}
+	var he *HTTPError
+	if errors.As(err, &he) {
+		return err
+	}
+
+	et.mutex.RLock()
+	defer et.mutex.RUnlock()
+
+	for _, entry := range et.entries {
+		if !entry.match(err) {
+			continue
+		}
+		message := entry.t.Message
+		if entry.t.Expose {
+			message = err.Error()
+		}
+		return &HTTPError{Status: entry.t.Status, Message: message, Err: err}
+	}
+	return err
+}
+
+// DefaultTranslator is the ErrorTranslator consulted by DefaultErrorFunc and
+// ProblemHandler. It translates sql.ErrNoRows and fs.ErrNotExist to 404,
+// fs.ErrPermission to 403, context.DeadlineExceeded to 504 and form
+// decoding errors to 422 with their messages
+var DefaultTranslator = defaultTranslator()
+
+func defaultTranslator() *ErrorTranslator {
+	et := NewErrorTranslator()
+	et.Register(sql.ErrNoRows, Translation{Status: http.StatusNotFound})
+	et.Register(fs.ErrNotExist, Translation{Status: http.StatusNotFound})
+	et.Register(fs.ErrPermission, Translation{Status: http.StatusForbidden})
+	et.Register(context.DeadlineExceeded, Translation{Status: http.StatusGatewayTimeout})
+	et.RegisterType(&form.Error{}, Translation{Status: http.StatusUnprocessableEntity, Expose: true})
+	return et
+}
+
+// matchType returns a function matching errors wrapping
+// an error of the same type as example
+func matchType(example error) func(err error) bool {
+	t := reflect.TypeOf(example)
+	return func(err error) bool {
+		for ; err != nil; err = errors.Unwrap(err) {
+			if reflect.TypeOf(err) == t {
+				return true
+			}
+		}
+		return false
+	}
+}
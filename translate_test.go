@@ -0,0 +1,81 @@
+package verto
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type testLibraryError struct {
+	code int
+}
+
+func (e *testLibraryError) Error() string {
+	return fmt.Sprintf("library error %d at db01.internal", e.code)
+}
+
+func TestErrorTranslator(t *testing.T) {
+	defer func() {
+		err := recover()
+		if err != nil {
+			t.Errorf(err.(error).Error())
+		}
+	}()
+
+	err := "Failed error translator."
+
+	et := NewErrorTranslator()
+	et.RegisterType(&testLibraryError{}, Translation{Status: 409, Message: "Conflict."})
+	et.RegisterFunc(func(e error) bool { return e.Error() == "exposed" }, Translation{Status: 422, Expose: true})
+
+	he, ok := et.Translate(fmt.Errorf("saving: %w", &testLibraryError{7})).(*HTTPError)
+	if !ok || he.Status != 409 || he.Message != "Conflict." || !errors.As(he, new(*testLibraryError)) {
+		t.Errorf(err)
+	}
+	if he, ok = et.Translate(errors.New("exposed")).(*HTTPError); !ok || he.Status != 422 || he.Message != "exposed" {
+		t.Errorf(err)
+	}
+
+	// Test errors carrying a status and unmatched errors are kept
+	own := NewError(400, "bad")
+	plain := errors.New("plain")
+	if et.Translate(own) != own || et.Translate(plain) != plain || et.Translate(nil) != nil {
+		t.Errorf(err)
+	}
+}
+
+func TestDefaultTranslator(t *testing.T) {
+	defer func() {
+		err := recover()
+		if err != nil {
+			t.Errorf(err.(error).Error())
+		}
+	}()
+
+	err := "Failed default translator."
+
+	v := New()
+	v.Logger = &NilLogger{}
+	v.Get("/users/{id}", func(c *Context) (interface{}, error) {
+		return nil, fmt.Errorf("loading user: %w", sql.ErrNoRows)
+	})
+	h := &HttpHandler{v}
+
+	r, _ := http.NewRequest("GET", "http://test.com/users/1", nil)
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	if w.Code != 404 || w.Body.String() != "Not Found." {
+		t.Errorf(err)
+	}
+
+	// Test problem documents use translations
+	v.ErrorHandler = ErrorFunc(ProblemJSONErrorFunc)
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	if w.Code != 404 {
+		t.Errorf(err)
+	}
+}
@@ -0,0 +1,191 @@
+package upload
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// DiskStorage is a Storage and ResumableStorage that stores
+// uploads as files inside a directory. Resumable uploads store
+// their declared size in a sibling file with an .info extension.
+type DiskStorage struct {
+	// Dir is the directory uploads are stored in
+	Dir string
+
+	// Perm is the permission used for new files. Defaults to 0600
+	Perm os.FileMode
+
+	appending map[string]bool
+	mutex     sync.Mutex
+}
+
+// NewDiskStorage returns a DiskStorage storing uploads in dir.
+// dir is created if it does not exist
+func NewDiskStorage(dir string) (*DiskStorage, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, err
+	}
+	return &DiskStorage{Dir: dir}, nil
+}
+
+// Create returns a file writer for a new object named name
+func (ds *DiskStorage) Create(name string) (io.WriteCloser, error) {
+	p, err := ds.path(name)
+	if err != nil {
+		return nil, err
+	}
+	return os.OpenFile(p, os.O_WRONLY|os.O_CREATE|os.O_EXCL, ds.perm())
+}
+
+// Remove removes the object named name along with any
+// resumable upload info
+func (ds *DiskStorage) Remove(name string) error {
+	p, err := ds.path(name)
+	if err != nil {
+		return err
+	}
+	os.Remove(p + ".info")
+	return os.Remove(p)
+}
+
+// Init reserves a resumable upload of size bytes at id
+func (ds *DiskStorage) Init(id string, size int64) error {
+	p, err := ds.path(id)
+	if err != nil {
+		return err
+	}
+	f, err := os.OpenFile(p, os.O_WRONLY|os.O_CREATE|os.O_EXCL, ds.perm())
+	if err != nil {
+		return err
+	}
+	f.Close()
+	return writeFile(p+".info", []byte(strconv.FormatInt(size, 10)), ds.perm())
+}
+
+// Offset returns the number of bytes received for upload
+// id and its declared size
+func (ds *DiskStorage) Offset(id string) (int64, int64, error) {
+	p, err := ds.path(id)
+	if err != nil {
+		return 0, 0, err
+	}
+	return ds.offset(p)
+}
+
+// Append writes the contents of r to upload id starting at offset.
+// Returns the number of bytes written. Writes are limited to the
+// declared size of the upload. Appends to the same upload are
+// serialized without holding a lock while r is read
+func (ds *DiskStorage) Append(id string, offset int64, r io.Reader) (int64, error) {
+	p, err := ds.path(id)
+	if err != nil {
+		return 0, err
+	}
+	if !ds.claim(id) {
+		return 0, ErrLocked
+	}
+	defer ds.release(id)
+
+	current, size, err := ds.offset(p)
+	if err != nil {
+		return 0, err
+	}
+	if current != offset {
+		return 0, ErrOffsetMismatch
+	}
+
+	f, err := os.OpenFile(p, os.O_WRONLY|os.O_APPEND, ds.perm())
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+	return io.Copy(f, io.LimitReader(r, size-offset))
+}
+
+// claim marks upload id as being appended to. Returns false
+// if another append to the upload is in progress
+func (ds *DiskStorage) claim(id string) bool {
+	ds.mutex.Lock()
+	defer ds.mutex.Unlock()
+
+	if ds.appending[id] {
+		return false
+	}
+	if ds.appending == nil {
+		ds.appending = make(map[string]bool)
+	}
+	ds.appending[id] = true
+	return true
+}
+
+// release unmarks upload id as being appended to
+func (ds *DiskStorage) release(id string) {
+	ds.mutex.Lock()
+	defer ds.mutex.Unlock()
+
+	delete(ds.appending, id)
+}
+
+// offset returns the current and declared sizes of the upload at p
+func (ds *DiskStorage) offset(p string) (int64, int64, error) {
+	info, err := os.Stat(p)
+	if os.IsNotExist(err) {
+		return 0, 0, ErrNotFound
+	}
+	if err != nil {
+		return 0, 0, err
+	}
+	f, err := os.Open(p + ".info")
+	if os.IsNotExist(err) {
+		return 0, 0, ErrNotFound
+	}
+	if err != nil {
+		return 0, 0, err
+	}
+	defer f.Close()
+
+	b := make([]byte, 20)
+	n, err := io.ReadFull(f, b)
+	if err != nil && err != io.ErrUnexpectedEOF {
+		return 0, 0, err
+	}
+	size, err := strconv.ParseInt(string(b[:n]), 10, 64)
+	if err != nil {
+		return 0, 0, err
+	}
+	return info.Size(), size, nil
+}
+
+// path returns the file path for name. Names containing
+// path separators are rejected
+func (ds *DiskStorage) path(name string) (string, error) {
+	if name == "" || name == "." || name == ".." ||
+		strings.ContainsAny(name, `/\`) || strings.HasSuffix(name, ".info") {
+		return "", ErrNotFound
+	}
+	return filepath.Join(ds.Dir, name), nil
+}
+
+func (ds *DiskStorage) perm() os.FileMode {
+	if ds.Perm == 0 {
+		return 0600
+	}
+	return ds.Perm
+}
+
+// writeFile writes b to a new file at p
+func writeFile(p string, b []byte, perm os.FileMode) error {
+	f, err := os.OpenFile(p, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, perm)
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(b)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
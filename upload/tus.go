@@ -0,0 +1,164 @@
+package upload
+
+import (
+	"github.com/boxtown/verto"
+	"github.com/boxtown/verto/mux"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// TusVersion is the version of the tus resumable upload
+// protocol implemented by Tus
+const TusVersion = "1.0.0"
+
+// ResumableStorage is the interface for storage backends
+// that support resumable uploads
+type ResumableStorage interface {
+	// Init reserves a new upload of size bytes at id
+	Init(id string, size int64) error
+
+	// Offset returns the number of bytes received for upload
+	// id and its declared size. Returns ErrNotFound if the
+	// upload does not exist
+	Offset(id string) (offset int64, size int64, err error)
+
+	// Append writes the contents of r to upload id starting at offset
+	// and returns the number of bytes written. Returns ErrOffsetMismatch
+	// if offset is not the current offset of the upload and ErrLocked if
+	// another append to the upload is in progress
+	Append(id string, offset int64, r io.Reader) (int64, error)
+}
+
+// Tus serves tus-style resumable upload endpoints. Uploads are
+// created with a POST declaring their size in the Upload-Length
+// header, queried with HEAD and appended to with PATCH requests
+// carrying an Upload-Offset header.
+//
+// Example usage:
+//
+//	storage, _ := upload.NewDiskStorage("/var/uploads")
+//	tus := &upload.Tus{Storage: storage, MaxSize: 1 << 30}
+//	tus.Register(v, "/files")
+type Tus struct {
+	// Storage is the storage backend for uploads
+	Storage ResumableStorage
+
+	// MaxSize is the maximum declared size of an upload.
+	// Zero means no limit
+	MaxSize int64
+
+	// OnComplete is an optional function called once
+	// an upload has received all of its bytes
+	OnComplete func(id string, size int64, r *http.Request)
+}
+
+// Register registers the tus endpoints on v under prefix
+func (t *Tus) Register(v *verto.Verto, prefix string) {
+	prefix = strings.TrimRight(prefix, "/")
+	v.AddHandler("OPTIONS", prefix, http.HandlerFunc(t.options))
+	v.AddHandler("POST", prefix, http.HandlerFunc(t.create))
+	v.AddHandler("HEAD", prefix+"/{id}", http.HandlerFunc(t.head))
+	v.AddHandler("PATCH", prefix+"/{id}", http.HandlerFunc(t.patch))
+}
+
+// options advertises the protocol capabilities of the server
+func (t *Tus) options(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Tus-Resumable", TusVersion)
+	w.Header().Set("Tus-Version", TusVersion)
+	w.Header().Set("Tus-Extension", "creation")
+	if t.MaxSize > 0 {
+		w.Header().Set("Tus-Max-Size", strconv.FormatInt(t.MaxSize, 10))
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// create reserves a new upload and responds with its location
+func (t *Tus) create(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Tus-Resumable", TusVersion)
+
+	size, err := strconv.ParseInt(r.Header.Get("Upload-Length"), 10, 64)
+	if err != nil || size < 0 {
+		tusError(w, http.StatusBadRequest)
+		return
+	}
+	if t.MaxSize > 0 && size > t.MaxSize {
+		tusError(w, http.StatusRequestEntityTooLarge)
+		return
+	}
+
+	id := randomID()
+	if err := t.Storage.Init(id, size); err != nil {
+		tusError(w, http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Location", strings.TrimRight(r.URL.Path, "/")+"/"+id)
+	w.WriteHeader(http.StatusCreated)
+}
+
+// head responds with the current offset of an upload
+func (t *Tus) head(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Tus-Resumable", TusVersion)
+	w.Header().Set("Cache-Control", "no-store")
+
+	offset, size, err := t.Storage.Offset(mux.Param(r, "id"))
+	if err != nil {
+		tusStorageError(w, err)
+		return
+	}
+	w.Header().Set("Upload-Offset", strconv.FormatInt(offset, 10))
+	w.Header().Set("Upload-Length", strconv.FormatInt(size, 10))
+	w.WriteHeader(http.StatusOK)
+}
+
+// patch appends the request body to an upload
+func (t *Tus) patch(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Tus-Resumable", TusVersion)
+
+	if r.Header.Get("Content-Type") != "application/offset+octet-stream" {
+		tusError(w, http.StatusUnsupportedMediaType)
+		return
+	}
+	offset, err := strconv.ParseInt(r.Header.Get("Upload-Offset"), 10, 64)
+	if err != nil || offset < 0 {
+		tusError(w, http.StatusBadRequest)
+		return
+	}
+
+	id := mux.Param(r, "id")
+	n, err := t.Storage.Append(id, offset, r.Body)
+	if err != nil && n == 0 {
+		tusStorageError(w, err)
+		return
+	}
+
+	offset += n
+	w.Header().Set("Upload-Offset", strconv.FormatInt(offset, 10))
+	if t.OnComplete != nil {
+		if _, size, err := t.Storage.Offset(id); err == nil && offset == size {
+			t.OnComplete(id, size, r)
+		}
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// writes a plain text error response with status
+func tusError(w http.ResponseWriter, status int) {
+	w.WriteHeader(status)
+	io.WriteString(w, http.StatusText(status)+".")
+}
+
+// writes the appropriate error response for a storage error
+func tusStorageError(w http.ResponseWriter, err error) {
+	switch err {
+	case ErrNotFound:
+		tusError(w, http.StatusNotFound)
+	case ErrOffsetMismatch:
+		tusError(w, http.StatusConflict)
+	case ErrLocked:
+		tusError(w, http.StatusLocked)
+	default:
+		tusError(w, http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,213 @@
+// Package upload provides streaming file uploads for Verto. Multipart
+// file parts are streamed directly to a Storage backend without buffering
+// whole files in memory. Resumable uploads are supported through a
+// tus-style protocol backed by a ResumableStorage.
+package upload
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"strings"
+)
+
+// ErrPartTooLarge is returned by Stream if a file part
+// exceeds the maximum part size
+var ErrPartTooLarge = errors.New("upload: part too large")
+
+// ErrTooManyParts is returned by Stream if the multipart
+// body contains more parts than allowed
+var ErrTooManyParts = errors.New("upload: too many parts")
+
+// ErrNotFound is returned by storages if an upload does not exist
+var ErrNotFound = errors.New("upload: not found")
+
+// ErrOffsetMismatch is returned by resumable storages if an
+// append does not start at the current offset of the upload
+var ErrOffsetMismatch = errors.New("upload: offset mismatch")
+
+// ErrLocked is returned by resumable storages if another
+// append to the same upload is in progress
+var ErrLocked = errors.New("upload: upload locked")
+
+// Storage is the interface for upload storage backends. Implementations
+// for S3-compatible object stores can stream the writer returned by
+// Create into a multipart upload.
+type Storage interface {
+	// Create returns a writer for a new object named name. The
+	// object is complete once the writer has been closed
+	Create(name string) (io.WriteCloser, error)
+
+	// Remove removes the object named name. Remove is used to clean
+	// up partially written objects when streaming fails
+	Remove(name string) error
+}
+
+// Part describes a file part that was streamed to storage
+type Part struct {
+	// Field is the form field name of the part
+	Field string
+
+	// FileName is the file name supplied by the client
+	FileName string
+
+	// Name is the name the part was stored under
+	Name string
+
+	// ContentType is the content type supplied by the client
+	ContentType string
+
+	// Size is the number of bytes stored
+	Size int64
+}
+
+// Options configures multipart streaming
+type Options struct {
+	// MaxPartSize is the maximum size of a single file part.
+	// Zero means no limit
+	MaxPartSize int64
+
+	// MaxParts is the maximum number of parts (files and fields).
+	// Zero means no limit
+	MaxParts int
+
+	// MaxFieldSize is the maximum size of a non-file field.
+	// Defaults to 64KB
+	MaxFieldSize int64
+
+	// NameFn returns the storage name for a file part. If nil,
+	// a random name retaining the part's file extension is used
+	NameFn func(p *multipart.Part) string
+}
+
+// Stream streams each file part of the multipart request body to storage
+// and returns descriptions of the stored parts along with any non-file
+// form fields. If an error occurs, parts stored so far are removed.
+func Stream(r *http.Request, storage Storage, opts *Options) ([]Part, url.Values, error) {
+	if opts == nil {
+		opts = &Options{}
+	}
+	mr, err := r.MultipartReader()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	parts := make([]Part, 0)
+	fields := make(url.Values)
+	cleanup := func() {
+		for _, p := range parts {
+			storage.Remove(p.Name)
+		}
+	}
+
+	for n := 0; ; n++ {
+		p, err := mr.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			cleanup()
+			return nil, nil, err
+		}
+		if opts.MaxParts > 0 && n >= opts.MaxParts {
+			p.Close()
+			cleanup()
+			return nil, nil, ErrTooManyParts
+		}
+
+		if p.FileName() == "" {
+			value, err := readField(p, opts.MaxFieldSize)
+			p.Close()
+			if err != nil {
+				cleanup()
+				return nil, nil, err
+			}
+			fields.Add(p.FormName(), value)
+			continue
+		}
+
+		stored, err := storePart(p, storage, opts)
+		p.Close()
+		if err != nil {
+			cleanup()
+			return nil, nil, err
+		}
+		parts = append(parts, stored)
+	}
+	return parts, fields, nil
+}
+
+// storePart streams a single file part into storage
+func storePart(p *multipart.Part, storage Storage, opts *Options) (Part, error) {
+	name := ""
+	if opts.NameFn != nil {
+		name = opts.NameFn(p)
+	} else {
+		name = randomID() + strings.ToLower(filepath.Ext(filepath.Base(p.FileName())))
+	}
+
+	w, err := storage.Create(name)
+	if err != nil {
+		return Part{}, err
+	}
+
+	var src io.Reader = p
+	if opts.MaxPartSize > 0 {
+		src = io.LimitReader(p, opts.MaxPartSize+1)
+	}
+	size, err := io.Copy(w, src)
+	if cerr := w.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil && opts.MaxPartSize > 0 && size > opts.MaxPartSize {
+		err = ErrPartTooLarge
+	}
+	if err != nil {
+		storage.Remove(name)
+		return Part{}, err
+	}
+
+	return Part{
+		Field:       p.FormName(),
+		FileName:    p.FileName(),
+		Name:        name,
+		ContentType: p.Header.Get("Content-Type"),
+		Size:        size,
+	}, nil
+}
+
+// readField reads a non-file form field of at most max bytes
+func readField(p *multipart.Part, max int64) (string, error) {
+	if max <= 0 {
+		max = 64 << 10
+	}
+	b := make([]byte, 0, 512)
+	buf := make([]byte, 512)
+	for {
+		n, err := p.Read(buf)
+		b = append(b, buf[:n]...)
+		if int64(len(b)) > max {
+			return "", ErrPartTooLarge
+		}
+		if err == io.EOF {
+			return string(b), nil
+		}
+		if err != nil {
+			return "", err
+		}
+	}
+}
+
+// randomID returns a random 128-bit hex encoded id
+func randomID() string {
+	b := make([]byte, 16)
+	if _, err := io.ReadFull(rand.Reader, b); err != nil {
+		panic("upload: could not read random bytes: " + err.Error())
+	}
+	return hex.EncodeToString(b)
+}
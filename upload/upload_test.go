@@ -0,0 +1,203 @@
+package upload
+
+import (
+	"bytes"
+	"github.com/boxtown/verto/mux"
+	"io"
+	"io/ioutil"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestStream(t *testing.T) {
+	defer func() {
+		err := recover()
+		if err != nil {
+			t.Errorf(err.(error).Error())
+		}
+	}()
+
+	err := "Failed stream."
+
+	dir, _ := ioutil.TempDir("", "upload")
+	defer os.RemoveAll(dir)
+	storage, _ := NewDiskStorage(dir)
+
+	newRequest := func() *http.Request {
+		body := &bytes.Buffer{}
+		mw := multipart.NewWriter(body)
+		mw.WriteField("title", "hello")
+		fw, _ := mw.CreateFormFile("file", "a.TXT")
+		fw.Write([]byte("hello world"))
+		mw.Close()
+
+		r, _ := http.NewRequest("POST", "http://test.com/upload", body)
+		r.Header.Set("Content-Type", mw.FormDataContentType())
+		return r
+	}
+
+	parts, fields, e := Stream(newRequest(), storage, nil)
+	if e != nil || len(parts) != 1 || fields.Get("title") != "hello" {
+		t.Fatalf(err)
+	}
+	p := parts[0]
+	if p.Field != "file" || p.FileName != "a.TXT" || p.Size != 11 || !strings.HasSuffix(p.Name, ".txt") {
+		t.Errorf(err)
+	}
+	b, _ := ioutil.ReadFile(filepath.Join(dir, p.Name))
+	if string(b) != "hello world" {
+		t.Errorf(err)
+	}
+
+	// Test part size limit cleans up
+	_, _, e = Stream(newRequest(), storage, &Options{MaxPartSize: 5})
+	if e != ErrPartTooLarge {
+		t.Errorf(err)
+	}
+	files, _ := ioutil.ReadDir(dir)
+	if len(files) != 1 {
+		t.Errorf(err)
+	}
+
+	// Test part count limit
+	_, _, e = Stream(newRequest(), storage, &Options{MaxParts: 1})
+	if e != ErrTooManyParts {
+		t.Errorf(err)
+	}
+}
+
+func TestTus(t *testing.T) {
+	defer func() {
+		err := recover()
+		if err != nil {
+			t.Errorf(err.(error).Error())
+		}
+	}()
+
+	err := "Failed tus."
+
+	dir, _ := ioutil.TempDir("", "upload")
+	defer os.RemoveAll(dir)
+	storage, _ := NewDiskStorage(dir)
+
+	completed := ""
+	tus := &Tus{Storage: storage, MaxSize: 10}
+	tus.OnComplete = func(id string, size int64, r *http.Request) {
+		completed = id
+	}
+
+	// Test create
+	r, _ := http.NewRequest("POST", "http://test.com/files", nil)
+	r.Header.Set("Upload-Length", "11")
+	w := httptest.NewRecorder()
+	tus.create(w, r)
+	if w.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf(err)
+	}
+
+	r.Header.Set("Upload-Length", "10")
+	w = httptest.NewRecorder()
+	tus.create(w, r)
+	if w.Code != http.StatusCreated || w.Header().Get("Tus-Resumable") != TusVersion {
+		t.Fatalf(err)
+	}
+	loc := w.Header().Get("Location")
+	if !strings.HasPrefix(loc, "/files/") {
+		t.Fatalf(err)
+	}
+	id := strings.TrimPrefix(loc, "/files/")
+
+	patch := func(offset, body string) *httptest.ResponseRecorder {
+		r, _ := http.NewRequest("PATCH", "http://test.com"+loc, strings.NewReader(body))
+		r = mux.WithParams(r, "id", id)
+		r.Header.Set("Content-Type", "application/offset+octet-stream")
+		r.Header.Set("Upload-Offset", offset)
+		w := httptest.NewRecorder()
+		tus.patch(w, r)
+		return w
+	}
+
+	// Test append and resume
+	w = patch("0", "hello")
+	if w.Code != http.StatusNoContent || w.Header().Get("Upload-Offset") != "5" {
+		t.Errorf(err)
+	}
+	w = patch("0", "hello")
+	if w.Code != http.StatusConflict {
+		t.Errorf(err)
+	}
+
+	r, _ = http.NewRequest("HEAD", "http://test.com"+loc, nil)
+	r = mux.WithParams(r, "id", id)
+	w = httptest.NewRecorder()
+	tus.head(w, r)
+	if w.Header().Get("Upload-Offset") != "5" || w.Header().Get("Upload-Length") != "10" {
+		t.Errorf(err)
+	}
+
+	w = patch("5", "world!!")
+	if w.Header().Get("Upload-Offset") != "10" || completed != id {
+		t.Errorf(err)
+	}
+	b, _ := ioutil.ReadFile(filepath.Join(dir, id))
+	if string(b) != "helloworld" {
+		t.Errorf(err)
+	}
+
+	// Test unknown and malicious ids
+	r, _ = http.NewRequest("HEAD", "http://test.com/files/x", nil)
+	r = mux.WithParams(r, "id", "../etc")
+	w = httptest.NewRecorder()
+	tus.head(w, r)
+	if w.Code != http.StatusNotFound {
+		t.Errorf(err)
+	}
+}
+
+func TestDiskStorageConcurrentAppend(t *testing.T) {
+	defer func() {
+		err := recover()
+		if err != nil {
+			t.Errorf(err.(error).Error())
+		}
+	}()
+
+	err := "Failed disk storage concurrent append."
+
+	dir, _ := ioutil.TempDir("", "upload")
+	defer os.RemoveAll(dir)
+	storage, _ := NewDiskStorage(dir)
+	storage.Init("slow", 10)
+	storage.Init("fast", 5)
+
+	// Test a blocked append does not hold up other uploads
+	pr, pw := io.Pipe()
+	done := make(chan int64)
+	go func() {
+		n, _ := storage.Append("slow", 0, pr)
+		done <- n
+	}()
+	pw.Write([]byte("hello"))
+
+	if n, e := storage.Append("fast", 0, strings.NewReader("world")); n != 5 || e != nil {
+		t.Errorf(err)
+	}
+
+	// Test concurrent appends to the same upload are rejected
+	if _, e := storage.Append("slow", 5, strings.NewReader("world")); e != ErrLocked {
+		t.Errorf(err)
+	}
+
+	pw.Close()
+	if <-done != 5 {
+		t.Errorf(err)
+	}
+	if n, e := storage.Append("slow", 5, strings.NewReader("world")); n != 5 || e != nil {
+		t.Errorf(err)
+	}
+}
@@ -0,0 +1,160 @@
+package verto
+
+import (
+	"fmt"
+	"strings"
+)
+
+// handlerMetaKey is the endpoint metadata key noting
+// whether a handler was registered for the endpoint
+const handlerMetaKey = "verto.handler"
+
+// Kinds of findings reported by Validate
+const (
+	// FindingShadowedRoute is reported for catch-all routes
+	// that are unreachable because a wildcard route at the
+	// same position is always matched first
+	FindingShadowedRoute = "route.shadowed"
+
+	// FindingEmptyGroup is reported for groups
+	// without any endpoints
+	FindingEmptyGroup = "group.empty"
+
+	// FindingNoHandler is reported for endpoints registered
+	// with a nil handler and no stub. Their plugin chains
+	// never reach a handler and requests receive a 501
+	FindingNoHandler = "endpoint.no-handler"
+
+	// FindingUnusedInjection is reported for injection keys
+	// that have never been retrieved
+	FindingUnusedInjection = "injection.unused"
+)
+
+// Finding is a problem with the route table, plugin
+// chains or injections reported by Validate
+type Finding struct {
+	Kind   string `json:"kind"`
+	Method string `json:"method,omitempty"`
+	Path   string `json:"path,omitempty"`
+	Detail string `json:"detail"`
+}
+
+// String returns a human readable description of the finding
+func (f Finding) String() string {
+	if f.Method == "" && f.Path == "" {
+		return fmt.Sprintf("%s: %s", f.Kind, f.Detail)
+	}
+	return fmt.Sprintf("%s: %s %s: %s", f.Kind, f.Method, f.Path, f.Detail)
+}
+
+// Validate analyzes the route table and plugin chains of v and
+// returns the problems found: catch-all routes shadowed by wildcard
+// routes, groups without endpoints, endpoints without a handler and
+// injection keys that have never been retrieved. Injection usage
+// is only meaningful once the instance has served traffic, so
+// RunOn only logs the route findings at startup.
+func (v *Verto) Validate() []Finding {
+	findings := v.validateRoutes()
+	if v.Injections != nil {
+		for _, key := range v.Injections.Unused() {
+			findings = append(findings, Finding{
+				Kind:   FindingUnusedInjection,
+				Detail: fmt.Sprintf("injection %q is registered but never retrieved", key),
+			})
+		}
+	}
+	return findings
+}
+
+// validateRoutes returns the findings for the route table of v
+func (v *Verto) validateRoutes() []Finding {
+	findings := make([]Finding, 0)
+	routes := v.table().Routes()
+
+	// The matcher prefers wildcard segments over catch-alls
+	// and never backtracks, so a catch-all is unreachable if
+	// a wildcard route shares its prefix
+	for _, rt := range routes {
+		segments := normalizeSegments(rt.Path())
+		n := len(segments) - 1
+		if segments[n] != "^" {
+			continue
+		}
+		for _, other := range routes {
+			if other.Method() != rt.Method() || other.Host() != rt.Host() {
+				continue
+			}
+			match := normalizeSegments(other.Path())
+			if len(match) > n && match[n] == "{}" && equalSegments(match[:n], segments[:n]) {
+				findings = append(findings, Finding{
+					Kind:   FindingShadowedRoute,
+					Method: rt.Method(),
+					Path:   rt.Path(),
+					Detail: fmt.Sprintf("catch-all is shadowed by wildcard route %s", other.Path()),
+				})
+				break
+			}
+		}
+	}
+
+	for _, g := range v.table().Groups() {
+		if len(g.Routes()) == 0 {
+			findings = append(findings, Finding{
+				Kind:   FindingEmptyGroup,
+				Method: g.Method(),
+				Path:   g.Path(),
+				Detail: "group has no endpoints",
+			})
+		}
+	}
+
+	for _, rt := range routes {
+		if has, ok := rt.Meta(handlerMetaKey); !ok || has.(bool) {
+			continue
+		}
+		if _, stubbed := rt.Meta(StubKey); stubbed {
+			continue
+		}
+		findings = append(findings, Finding{
+			Kind:   FindingNoHandler,
+			Method: rt.Method(),
+			Path:   rt.Path(),
+			Detail: "endpoint has no handler and never reaches one",
+		})
+	}
+	return findings
+}
+
+// reportFindings logs the route findings of v as warnings
+func (v *Verto) reportFindings() {
+	if v.Logger == nil {
+		return
+	}
+	for _, f := range v.validateRoutes() {
+		v.Logger.Warnf("verto: %s", f.String())
+	}
+}
+
+// normalizeSegments splits path into segments replacing
+// wildcard segments with a common placeholder
+func normalizeSegments(path string) []string {
+	segments := strings.Split(path, "/")
+	for i, s := range segments {
+		if strings.HasPrefix(s, "{") && strings.HasSuffix(s, "}") {
+			segments[i] = "{}"
+		}
+	}
+	return segments
+}
+
+func equalSegments(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,58 @@
+package verto
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	defer func() {
+		err := recover()
+		if err != nil {
+			t.Errorf(err.(error).Error())
+		}
+	}()
+
+	err := "Failed validate."
+
+	rf := func(c *Context) (interface{}, error) { return nil, nil }
+	v := New()
+	v.Logger = &NilLogger{}
+	v.Get("/files/{name}/meta", rf)
+	v.Get("/files/^", rf)
+	v.Get("/assets/^", rf)
+	v.Group("GET", "/empty")
+	v.Group("GET", "/api").Add("/users", rf)
+	v.Get("/todo", nil)
+	v.Get("/planned", nil).Stub(http.StatusOK, "planned", nil)
+	v.Injections.Set("db", "db")
+	v.Injections.Set("cache", "cache")
+	v.Injections.Get("db")
+
+	findings := v.Validate()
+	kinds := make(map[string]Finding)
+	for _, f := range findings {
+		kinds[f.Kind+" "+f.Path] = f
+	}
+	if len(findings) != 4 {
+		t.Errorf(err)
+	}
+	if f, ok := kinds[FindingShadowedRoute+" /files/^"]; !ok || f.Method != "GET" {
+		t.Errorf(err)
+	}
+	if _, ok := kinds[FindingEmptyGroup+" /empty"]; !ok {
+		t.Errorf(err)
+	}
+	if _, ok := kinds[FindingNoHandler+" /todo"]; !ok {
+		t.Errorf(err)
+	}
+	if f, ok := kinds[FindingUnusedInjection+" "]; !ok || f.String() != `injection.unused: injection "cache" is registered but never retrieved` {
+		t.Errorf(err)
+	}
+
+	// Test re-registering a handler clears the finding
+	v.Get("/todo", rf)
+	if len(v.validateRoutes()) != 2 {
+		t.Errorf(err)
+	}
+}
@@ -9,9 +9,11 @@
 package verto
 
 import (
+	"context"
 	"crypto/tls"
 	"encoding/json"
 	"encoding/xml"
+	"errors"
 	"fmt"
 	"github.com/boxtown/verto/mux"
 	"net"
@@ -91,18 +93,17 @@ type Endpoint struct {
 // that generated the Endpoint
 func (ep *Endpoint) Use(plugin Plugin) *Endpoint {
 	pluginFunc := func(w http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
-		ep.v.mutex.RLock()
-		c := NewContext(w, r, func() Injections { return ep.v.icloneMap[r] }, ep.v.Logger)
-		ep.v.mutex.RUnlock()
-
+		c := ep.v.context(w, r)
 		plugin.Handle(c, next)
 	}
+	ep.v.auditPlugin(ep.describe(), plugin)
 	return &Endpoint{ep.Endpoint.Use(mux.PluginFunc(pluginFunc)), ep.v}
 }
 
 // UsePluginHandler adds a mux.PluginHandler onto the chain of plugins to be executed
 // when the route represented by the Endpoint is requested.
 func (ep *Endpoint) UsePluginHandler(handler mux.PluginHandler) *Endpoint {
+	ep.v.auditPlugin(ep.describe(), handler)
 	return &Endpoint{ep.Endpoint.Use(handler), ep.v}
 }
 
@@ -111,9 +112,39 @@ func (ep *Endpoint) UsePluginHandler(handler mux.PluginHandler) *Endpoint {
 // http.Handler plugins will always call the next-in-line plugin if
 // one exists
 func (ep *Endpoint) UseHandler(handler http.Handler) *Endpoint {
+	ep.v.auditPlugin(ep.describe(), handler)
 	return &Endpoint{ep.Endpoint.UseHandler(handler), ep.v}
 }
 
+// Meta associates a metadata value with key on the route represented
+// by the Endpoint. Plugins can read route metadata during request
+// handling through Context.RouteMeta
+func (ep *Endpoint) Meta(key string, value interface{}) *Endpoint {
+	return &Endpoint{ep.Endpoint.Meta(key, value), ep.v}
+}
+
+// Name registers name as the name of the route represented by the
+// Endpoint. URLs for named routes can be built with Verto.URL
+func (ep *Endpoint) Name(name string) *Endpoint {
+	return &Endpoint{ep.Endpoint.Name(name), ep.v}
+}
+
+// Remove stops serving the route represented by the Endpoint.
+// Routes under the Endpoint's path are unaffected. Remove is
+// safe to call while Verto is serving requests
+func (ep *Endpoint) Remove() {
+	description := ep.describe()
+	ep.Endpoint.Remove()
+	ep.v.audit(AuditRouteRemoved, AuditSourceAPI, description)
+}
+
+// describe returns a description of the Endpoint's route
+// for logging and auditing
+func (ep *Endpoint) describe() string {
+	route := ep.Route()
+	return route.Method() + " " + route.Path()
+}
+
 // Group represents a group of routes in Verto. Routes are generally
 // grouped by a shared path prefix but can also be grouped by method
 // as well. Group allows the addition of plugins to be run whenever
@@ -129,26 +160,15 @@ type Group struct {
 // is returned. If the path already exists, this function will overwrite the
 // old handler with the passed in ResourceFunc.
 func (g *Group) Add(path string, rf ResourceFunc) *Endpoint {
-	handlerFunc := func(w http.ResponseWriter, r *http.Request) {
-		g.v.mutex.RLock()
-		c := NewContext(w, r, func() Injections { return g.v.icloneMap[r] }, g.v.Logger)
-		g.v.mutex.RUnlock()
-
-		response, err := rf(c)
-		if err != nil {
-			g.v.ErrorHandler.Handle(err, c)
-		} else {
-			g.v.ResponseHandler.Handle(response, c)
-		}
-	}
-	return &Endpoint{g.g.AddFunc(path, handlerFunc), g.v}
+	handler := g.v.resource(rf)
+	return g.v.endpoint(g.g.Add(path, g.v.serve(handler)), handler)
 }
 
 // AddHandler registers an http.Handler as the handler for the passed in path.
 // AddHandler behaves exactly the same as Add except that it takes in an http.Handler
 // instead of a ResourceFunc
 func (g *Group) AddHandler(path string, handler http.Handler) *Endpoint {
-	return &Endpoint{g.g.Add(path, handler), g.v}
+	return g.v.endpoint(g.g.Add(path, g.v.serve(handler)), handler)
 }
 
 // Group registers a sub-Group under the current Group at the
@@ -168,18 +188,17 @@ func (g *Group) Group(path string) *Group {
 // under the current group.
 func (g *Group) Use(plugin Plugin) *Group {
 	pluginFunc := func(w http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
-		g.v.mutex.RLock()
-		c := NewContext(w, r, func() Injections { return g.v.icloneMap[r] }, g.v.Logger)
-		g.v.mutex.RUnlock()
-
+		c := g.v.context(w, r)
 		plugin.Handle(c, next)
 	}
+	g.v.auditPlugin(g.describe(), plugin)
 	return &Group{g.g.Use(mux.PluginFunc(pluginFunc)), g.v}
 }
 
 // UsePluginHandler adds a mux.PluginHandler as a plugin to be executed for all
 // paths and sub-Groups under the current group.
 func (g *Group) UsePluginHandler(handler mux.PluginHandler) *Group {
+	g.v.auditPlugin(g.describe(), handler)
 	return &Group{g.g.Use(handler), g.v}
 }
 
@@ -187,9 +206,27 @@ func (g *Group) UsePluginHandler(handler mux.PluginHandler) *Group {
 // paths and sub-Groups under the current Group. http.Handler plugins
 // will always call the next-in-line plugin if one exists
 func (g *Group) UseHandler(handler http.Handler) *Group {
+	g.v.auditPlugin(g.describe(), handler)
 	return &Group{g.g.UseHandler(handler), g.v}
 }
 
+// Remove stops serving all routes under the Group, including
+// routes the Group subsumed when it was created. Remove is safe
+// to call while Verto is serving requests
+func (g *Group) Remove() {
+	routes := g.g.Routes()
+	g.g.Remove()
+	for _, route := range routes {
+		g.v.audit(AuditRouteRemoved, AuditSourceAPI, route.Method()+" "+route.Path())
+	}
+}
+
+// describe returns a description of the Group for
+// logging and auditing
+func (g *Group) describe() string {
+	return g.g.Method() + " " + g.g.Path()
+}
+
 // ResourceFunc is the Verto-specific function for endpoint resource handling.
 type ResourceFunc func(c *Context) (interface{}, error)
 
@@ -229,11 +266,72 @@ type Verto struct {
 	ResponseHandler ResponseHandler
 	TLSConfig       *tls.Config
 
-	verbose   bool
-	l         net.Listener
-	muxer     *mux.PathMuxer
-	icloneMap map[*http.Request]*IClone
-	mutex     *sync.RWMutex
+	// Audit records runtime mutations of the Verto instance
+	// such as added routes and plugins. Audit implements
+	// http.Handler so that it can be mounted as an endpoint
+	Audit *AuditTrail
+
+	// Events is the in-process event bus. Subsystems publish
+	// events on the bus (e.g. for webhook delivery)
+	Events *EventBus
+
+	// ClientKey optionally identifies the client of a request
+	// (e.g. by API key). If set, usage of deprecated Groups
+	// is logged per client
+	ClientKey func(r *http.Request) string
+
+	// Client is the http.Client used for outbound requests
+	// made through Context.Do. Defaults to http.DefaultClient
+	Client *http.Client
+
+	// Reload optionally reloads configuration. It is
+	// called by the admin API's reload endpoint
+	Reload func() error
+
+	// Flags provides the feature flags gating routes
+	// through Endpoint.Flag
+	Flags FlagProvider
+
+	// Timeouts are the timeouts of the server run by Run and RunOn
+	Timeouts Timeouts
+
+	// AllowInsecure allows serving without TLS
+	// in the production environment
+	AllowInsecure bool
+
+	// LogFields lists the request-scoped values promoted into
+	// access log records and Diagnostics of every request
+	LogFields []LogField
+
+	// ExitOnSmokeTestFailure exits the process if the
+	// startup smoke tests registered with SmokeTest fail
+	ExitOnSmokeTestFailure bool
+
+	// Discovery optionally describes resources in the responses
+	// to OPTIONS requests answered automatically (see SetAutoOptions).
+	// Groups can set their own Discovery with Group.Discover
+	Discovery *Discovery
+
+	verbose     bool
+	mock        bool
+	env         string
+	debug       bool
+	prettyJSON  bool
+	requireTLS  bool
+	debugRoutes bool
+	ready       bool
+	smokeTests  []string
+	previous    *mux.PathMuxer
+	ops         *operations
+	plugins     *pluginRegistry
+	admin       *admin
+	parent      *Verto
+	management  map[string]*Verto
+	l           net.Listener
+	muxer       *mux.PathMuxer
+	guards      []Plugin
+	workers     *WorkerPool
+	mutex       *sync.RWMutex
 }
 
 // HttpHandler is a wrapper around Verto such that it can run
@@ -242,46 +340,57 @@ type HttpHandler struct {
 	*Verto
 }
 
-// ServeHTTP serves requests directly to Verto's muxer.
+// ServeHTTP serves requests to Verto's guards and
+// then to Verto's current route table.
 func (handler *HttpHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
-	handler.muxer.ServeHTTP(w, r)
+	handler.guard(0, w, r)
 }
 
 // New returns a newly initialized Verto instance.
-// The path /shutdown is automatically reserved as a way to cleanly
-// shutdown the instance which is only available to calls from localhost.
+// Administrative endpoints such as shutdown are
+// mounted with EnableAdmin.
 func New() *Verto {
 	v := Verto{
 		Injections: NewContainer(),
 		Logger:     NewLogger(),
-
-		verbose:   false,
-		muxer:     mux.New(),
-		icloneMap: make(map[*http.Request]*IClone),
-		mutex:     &sync.RWMutex{},
+		Audit:      NewAuditTrail(1000),
+		Events:     NewEventBus(),
+
+		verbose: false,
+		ops:     &operations{},
+		plugins: &pluginRegistry{initialized: make(map[int]bool)},
+		muxer:   mux.New(),
+		mutex:   &sync.RWMutex{},
 	}
 	v.setInjectionPlugins()
-
-	// Reserve shutdown path
-	v.muxer.AddFunc(
-		"GET",
-		"/shutdown",
-		func(w http.ResponseWriter, r *http.Request) {
-			ip := GetIP(r)
-			if ip == "127.0.0.1" || ip == "::1" {
-				v.Stop()
-			} else {
-				v.muxer.NotFound.ServeHTTP(w, r)
-			}
-		})
-
-	// initialize clone plugins
+	v.muxer.NotFound = v.fallback(http.StatusNotFound)
+	v.muxer.NotImplemented = v.fallback(http.StatusNotImplemented)
+	v.muxer.MethodNotAllowed = v.fallback(http.StatusMethodNotAllowed)
+	v.muxer.Options = http.HandlerFunc(v.describe)
 
 	v.ErrorHandler = ErrorFunc(DefaultErrorFunc)
 	v.ResponseHandler = ResponseFunc(DefaultResponseFunc)
 	return &v
 }
 
+// URL builds the path of the route registered under name by
+// substituting params, given as key-value pairs, for the route's
+// named parameters.
+//
+// Example usage:
+//
+//	v.Get("/users/{id}", handler).Name("user.show")
+//	path, err := v.URL("user.show", "id", "42") // "/users/42"
+func (v *Verto) URL(name string, params ...string) (string, error) {
+	return v.table().URL(name, params...)
+}
+
+// Routes returns every route registered with Verto
+// sorted by path and then method
+func (v *Verto) Routes() []mux.Route {
+	return v.table().Routes()
+}
+
 // Add registers a specific method+path combination to
 // a resource function and returns an Endpoint representing
 // said resource
@@ -289,20 +398,8 @@ func (v *Verto) Add(
 	method, path string,
 	rf ResourceFunc) *Endpoint {
 
-	handlerFunc := func(w http.ResponseWriter, r *http.Request) {
-		v.mutex.RLock()
-		c := NewContext(w, r, func() Injections { return v.icloneMap[r] }, v.Logger)
-		v.mutex.RUnlock()
-
-		response, err := rf(c)
-		if err != nil {
-			v.ErrorHandler.Handle(err, c)
-		} else {
-			v.ResponseHandler.Handle(response, c)
-		}
-	}
-
-	return &Endpoint{v.muxer.AddFunc(method, path, handlerFunc), v}
+	handler := v.resource(rf)
+	return v.endpoint(v.table().Add(method, path, v.serve(handler)), handler)
 }
 
 // AddHandler registers a specific method+path combination to
@@ -312,11 +409,24 @@ func (v *Verto) AddHandler(
 	method, path string,
 	handler http.Handler) *Endpoint {
 
-	return &Endpoint{v.muxer.Add(method, path, handler), v}
+	return v.endpoint(v.table().Add(method, path, v.serve(handler)), handler)
+}
+
+// Remove stops serving the route registered for the method+path
+// combination and returns whether one was registered. Path is the
+// pattern the route was registered with (e.g. "/users/{id}").
+// Remove is safe to call while Verto is serving requests, so
+// long-running services can retire routes at runtime
+func (v *Verto) Remove(method, path string) bool {
+	if !v.table().Remove(method, path) {
+		return false
+	}
+	v.audit(AuditRouteRemoved, AuditSourceAPI, method+" "+path)
+	return true
 }
 
 func (v *Verto) Group(method, path string) *Group {
-	return &Group{v.muxer.Group(method, path), v}
+	return &Group{v.table().Group(method, path), v}
 }
 
 // Get is a wrapper function around Add() that sets the method
@@ -325,6 +435,30 @@ func (v *Verto) Get(path string, rf ResourceFunc) *Endpoint {
 	return v.Add("GET", path, rf)
 }
 
+// AddParamHandler registers a specific method+path combination to
+// a mux.ParamHandler receiving the matched path parameters by position
+// and name. Path parameters of the endpoint are never added to the
+// request's form values, avoiding the form parsing and url.Values
+// allocation of AddHandler.
+//
+// Example usage:
+//
+//	v.AddParamHandler("GET", "/users/{id}", mux.ParamHandlerFunc(
+//		func(w http.ResponseWriter, r *http.Request, ps mux.PathParams) {
+//			fmt.Fprint(w, ps.ByName("id"))
+//		}))
+func (v *Verto) AddParamHandler(
+	method, path string,
+	handler mux.ParamHandler) *Endpoint {
+
+	served := v.serve(mux.ParamHandlerFunc(handler.ServeHTTPParams))
+	ep := v.table().AddParamHandler(method, path, mux.ParamHandlerFunc(
+		func(w http.ResponseWriter, r *http.Request, ps mux.PathParams) {
+			served.ServeHTTP(w, r)
+		}))
+	return v.endpoint(ep, served)
+}
+
 // GetHandler is a wrapper function around AddHandler() that sets
 // the method as GET
 func (v *Verto) GetHandler(path string, handler http.Handler) *Endpoint {
@@ -367,6 +501,18 @@ func (v *Verto) DeleteHandler(path string, handler http.Handler) *Endpoint {
 	return v.AddHandler("DELETE", path, handler)
 }
 
+// Patch is a wrapper function around Add() that sets the method
+// as PATCH
+func (v *Verto) Patch(path string, rf ResourceFunc) *Endpoint {
+	return v.Add("PATCH", path, rf)
+}
+
+// PatchHandler is a wrapper function around AddHandler() that sets the method
+// as PATCH
+func (v *Verto) PatchHandler(path string, handler http.Handler) *Endpoint {
+	return v.AddHandler("PATCH", path, handler)
+}
+
 // SetVerbose sets whether the Verto instance is verbose or not.
 func (v *Verto) SetVerbose(verbose bool) {
 	v.verbose = verbose
@@ -377,58 +523,109 @@ func (v *Verto) SetVerbose(verbose bool) {
 // paths if they exist and vice versa. The default is true which means
 // Verto treats trailing slash as a different path than non-trailing slash
 func (v *Verto) SetStrict(strict bool) {
-	v.muxer.Strict = strict
+	v.table().Strict = strict
+}
+
+// SetAutoOptions sets whether OPTIONS requests for paths without an
+// OPTIONS handler are answered automatically with an Allow header
+// listing the methods registered for the path. The default is false
+func (v *Verto) SetAutoOptions(auto bool) {
+	v.table().AutoOptions = auto
+}
+
+// SetAutoHead sets whether HEAD requests for paths without a HEAD
+// handler are served by the path's GET handler with the response
+// body discarded. The default is false
+func (v *Verto) SetAutoHead(auto bool) {
+	v.table().AutoHead = auto
+}
+
+// SetFormParams sets whether matched path parameters are also
+// added to the request's form values, making them available through
+// Context.Get and http.Request.FormValue. Path parameters are always
+// available through Context.Param. The default is true for backward
+// compatibility; disabling it keeps path parameters from colliding
+// with query and body values and avoids parsing the form
+func (v *Verto) SetFormParams(enabled bool) {
+	v.table().FormParams = enabled
 }
 
 // Use wraps a Plugin as a mux.PluginHandler and calls Verto.Use().
 func (v *Verto) Use(plugin Plugin) *Verto {
 	pluginFunc := func(w http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
-		v.mutex.RLock()
-		c := NewContext(w, r, func() Injections { return v.icloneMap[r] }, v.Logger)
-		v.mutex.RUnlock()
-
+		c := v.context(w, r)
 		plugin.Handle(c, next)
 	}
-	v.muxer.Use(mux.PluginFunc(pluginFunc))
+	v.auditPlugin("global", plugin)
+	v.table().Use(mux.PluginFunc(pluginFunc))
+	return v
+}
+
+// Guard registers a Plugin that runs for every request before routing,
+// including requests that match no route. Guards can reject malformed
+// or hostile requests before any route matching is done. Guards run in
+// order of registration. Route metadata and injections are not available
+// to guards
+func (v *Verto) Guard(plugin Plugin) *Verto {
+	v.auditPlugin("guard", plugin)
+	v.guards = append(v.guards, plugin)
 	return v
 }
 
+// guard runs the guard at index i or, once all guards
+// passed, serves r from the current route table
+func (v *Verto) guard(i int, w http.ResponseWriter, r *http.Request) {
+	if i >= len(v.guards) {
+		v.table().ServeHTTP(w, r)
+		return
+	}
+	v.guards[i].Handle(v.context(w, r), func(w http.ResponseWriter, r *http.Request) {
+		v.guard(i+1, w, r)
+	})
+}
+
 // UsePluginHandler registers a mux.PluginHandler as a global plugin.
 // to run for all groups and paths registered to the Verto instance.
 // Plugins are called in order of definition.
 func (v *Verto) UsePluginHandler(handler mux.PluginHandler) *Verto {
-	v.muxer.Use(handler)
+	v.auditPlugin("global", handler)
+	v.table().Use(handler)
 	return v
 }
 
 // UseHandler wraps an http.Handler as a mux.PluginHandler and calls Verto.Use().
 func (v *Verto) UseHandler(handler http.Handler) *Verto {
-	v.muxer.UseHandler(handler)
+	v.auditPlugin("global", handler)
+	v.table().UseHandler(handler)
 	return v
 }
 
 // RunOn runs Verto on the specified address (e.g. ":8080").
-// RunOn by defaults adds a shutdown endpoint for Verto
-// at /shutdown which can only be called locally.
+// Management listeners registered through Management are
+// started alongside and stopped with the instance.
 func (v *Verto) RunOn(addr string) {
 	if v.verbose {
 		v.Logger.Info("Server initializing...")
 	}
 
-	listener, err := net.Listen("tcp", addr)
-	if err != nil {
-		panic(err)
+	if v.requireTLS && v.TLSConfig == nil && !v.AllowInsecure {
+		panic(ErrInsecure)
 	}
-	v.l, _ = WrapListener(listener)
-
-	if v.TLSConfig != nil {
-		v.l = tls.NewListener(v.l, v.TLSConfig)
+	if err := v.InitPlugins(); err != nil {
+		panic(err)
 	}
 
-	server := http.Server{
-		Handler: v.muxer,
-	}
+	v.l = v.listen(addr)
+	server := v.server()
+	v.serveManagement()
+	v.reportFindings()
+	v.startup()
 	server.Serve(v.l)
+	v.setReady(false)
+	v.drainWorkers()
+	if v.Events != nil {
+		v.Events.Publish(StoppedEvent, nil)
+	}
 
 	if v.verbose {
 		v.Logger.Info("Server shutting down.")
@@ -440,37 +637,217 @@ func (v *Verto) Run() {
 	v.RunOn(":8080")
 }
 
-// Stops the Verto instance
+// Stops the Verto instance and its management listeners
 func (v *Verto) Stop() {
-	v.l.Close()
+	if v.parent != nil {
+		v.parent.Stop()
+		return
+	}
+	if v.l != nil {
+		v.l.Close()
+	}
+	v.stopManagement()
 }
 
-func (v *Verto) setInjectionPlugins() {
-	v.UsePluginHandler(mux.PluginFunc(func(w http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
-		next(w, r)
+// listen returns a stoppable listener on addr, wrapped
+// with TLS if a TLSConfig is set. Panics if addr cannot
+// be listened on
+func (v *Verto) listen(addr string) net.Listener {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		panic(err)
+	}
+	var l net.Listener
+	l, _ = WrapListener(listener)
 
-		v.mutex.Lock()
-		delete(v.icloneMap, r)
-		v.mutex.Unlock()
-	}))
-	v.UsePluginHandler(mux.PluginFunc(func(w http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
-		v.mutex.Lock()
-		v.icloneMap[r] = v.Injections.Clone(w, r)
-		v.mutex.Unlock()
+	if v.TLSConfig != nil {
+		l = tls.NewListener(l, v.TLSConfig)
+	}
+	return l
+}
+
+// server returns an http.Server serving v's route table
+func (v *Verto) server() *http.Server {
+	return &http.Server{
+		Handler:           &HttpHandler{v},
+		ReadHeaderTimeout: v.Timeouts.ReadHeader,
+		ReadTimeout:       v.Timeouts.Read,
+		WriteTimeout:      v.Timeouts.Write,
+		IdleTimeout:       v.Timeouts.Idle,
+	}
+}
 
-		next(w, r)
+// setInjectionPlugins registers the plugin that attaches a per-request
+// clone of the injections container to each request's context. Requests
+// derived from it through WithContext or Clone share the same clone
+func (v *Verto) setInjectionPlugins() {
+	v.table().Use(mux.PluginFunc(func(w http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+		next(w, v.withInjections(w, r))
 	}))
 }
 
+// resource returns an http.Handler that runs rf and passes its
+// results to the ErrorHandler or ResponseHandler. Returns nil if
+// rf is nil
+func (v *Verto) resource(rf ResourceFunc) http.Handler {
+	if rf == nil {
+		return nil
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		cw := &clientWriter{ResponseWriter: w}
+		c := v.context(cw, r)
+		response, err := rf(c)
+
+		// Skip serializing responses for clients that are gone
+		// or requests whose deadline passed
+		if c.ClientClosed() {
+			err = ErrClientClosed
+		} else if c.DeadlineExceeded() {
+			err = ErrDeadlineExceeded
+		}
+		if err != nil {
+			if err != ErrClientClosed {
+				c.Capture(err, nil)
+			}
+			if c.RetrySafe() {
+				c.Response = &retryWriter{ResponseWriter: c.Response, after: c.retryAfter()}
+			}
+			v.errorHandler(c).Handle(err, c)
+			return
+		}
+		if response == NoContent {
+			c.Response.WriteHeader(http.StatusNoContent)
+			return
+		}
+		if response == nil && cw.wrote {
+			// The ResourceFunc wrote its own response
+			// (e.g. with Context.File)
+			return
+		}
+		v.responseHandler(c).Handle(transform(response, c), c)
+		if cw.err != nil {
+			v.errorHandler(c).Handle(ErrClientClosed, c)
+		}
+	})
+}
+
+// serve wraps the handler of a route with framework-level request
+// handling shared by all routes. Routes respond with 503 in maintenance
+// mode and routes without a handler respond with their stub or 501
+// Not Implemented
+func (v *Verto) serve(handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if v.serveMaintenance(w, r) {
+			return
+		}
+		handler := variantOf(r, handler)
+		if v.serveStub(w, r, handler == nil) {
+			return
+		}
+		if handler == nil {
+			v.table().NotImplemented.ServeHTTP(w, r)
+			return
+		}
+		v.captureLogFields(r)
+		handler.ServeHTTP(w, r)
+	})
+}
+
+// context returns a new Context for the request r
+func (v *Verto) context(w http.ResponseWriter, r *http.Request) *Context {
+	injections := func() Injections {
+		return RequestInjections(r)
+	}
+	c := NewContext(w, r, injections, v.Logger)
+	c.v = v
+	return c
+}
+
+// withInjections returns a copy of r carrying a new clone
+// of v's injections container in its context
+func (v *Verto) withInjections(w http.ResponseWriter, r *http.Request) *http.Request {
+	clone := v.Injections.Clone(w, nil)
+	r = r.WithContext(context.WithValue(r.Context(), injectionsKey{}, clone))
+	clone.r = r
+	return r
+}
+
+// audit records a framework mutation in the audit trail
+// and logs it
+func (v *Verto) audit(action, source, detail string) {
+	if v.Audit != nil {
+		v.Audit.Record(action, source, detail)
+	}
+	if v.Logger != nil {
+		v.Logger.Infof("audit: %s %s (source: %s)", action, detail, source)
+	}
+}
+
+// endpoint wraps the mux endpoint ep serving handler,
+// noting whether a handler exists, and audits its addition
+func (v *Verto) endpoint(ep mux.Endpoint, handler http.Handler) *Endpoint {
+	ep.Meta(handlerMetaKey, handler != nil)
+	return v.auditRoute(&Endpoint{ep, v})
+}
+
+// auditRoute records the addition of the route represented
+// by ep and returns ep
+func (v *Verto) auditRoute(ep *Endpoint) *Endpoint {
+	v.audit(AuditRouteAdded, AuditSourceAPI, ep.describe())
+	return ep
+}
+
+// auditPlugin records the addition of plugin to target and
+// tracks the plugin for initialization by InitPlugins
+func (v *Verto) auditPlugin(target string, plugin interface{}) {
+	v.audit(AuditPluginAdded, AuditSourceAPI, fmt.Sprintf("%s: %T", target, plugin))
+	v.plugins.add(plugin)
+}
+
 // -------------------------------
 // ---------- Helpers ------------
 
 // DefaultErrorFunc is the default error handling
 // function for Verto. DefaultErrorFunc sends a 500 response
 // and writes the error's error message to the response body.
+// Nothing is written for ErrClientClosed, a 504 response is sent
+// for ErrDeadlineExceeded and the status of an HTTPError is
+// sent for errors carrying one. Other errors
+// are translated by DefaultTranslator first. HTTPErrors with
+// details are written as JSON. In the development environment
+// the stack captured for the error is appended.
 func DefaultErrorFunc(err error, c *Context) {
-	c.Response.WriteHeader(500)
+	if err == ErrClientClosed {
+		return
+	}
+	err = DefaultTranslator.Translate(err)
+	status := ErrorStatus(err)
+	if err == ErrDeadlineExceeded {
+		c.Response.WriteHeader(status)
+		fmt.Fprint(c.Response, http.StatusText(status)+".")
+		return
+	}
+
+	var he *HTTPError
+	if errors.As(err, &he) {
+		if he.Details != nil {
+			c.Response.Header().Set("Content-Type", "application/json")
+			c.Response.WriteHeader(status)
+			json.NewEncoder(c.Response).Encode(struct {
+				Error   string      `json:"error"`
+				Details interface{} `json:"details"`
+			}{he.Error(), he.Details})
+			return
+		}
+		c.Response.WriteHeader(status)
+		fmt.Fprint(c.Response, he.Error())
+		return
+	}
+	c.Response.WriteHeader(status)
 	fmt.Fprint(c.Response, err.Error())
+	if d := c.Diagnostic(); d != nil && c.Debug() {
+		fmt.Fprint(c.Response, "\n\n"+d.Stack)
+	}
 }
 
 // DefaultResponseFunc is the default response handling
@@ -482,9 +859,20 @@ func DefaultResponseFunc(response interface{}, c *Context) {
 
 // JSONResponseFunc attempts to write the returned response to
 // the ResponseWriter as JSON. JSONResponseFunc Will return an HTTP 500
-// error if the marshalling failed
+// error if the marshalling failed. Relations declared on Resource
+// responses are rendered as fully-qualified links. JSON is indented
+// in the development environment
 func JSONResponseFunc(response interface{}, c *Context) {
-	if marshalled, err := json.Marshal(response); err != nil {
+	if res, ok := response.(*Resource); ok {
+		res.resolve(c)
+	}
+	marshal := json.Marshal
+	if c.v != nil && c.v.prettyJSON {
+		marshal = func(v interface{}) ([]byte, error) {
+			return json.MarshalIndent(v, "", "  ")
+		}
+	}
+	if marshalled, err := marshal(response); err != nil {
 		c.Response.WriteHeader(500)
 		fmt.Fprint(c.Response, "Could not marshal response as JSON")
 	} else {
@@ -494,8 +882,12 @@ func JSONResponseFunc(response interface{}, c *Context) {
 
 // XMLResponseFunc attempts to write the returned response to
 // the ResponseWriter as XML. XMLResponseFunc will return an HTTP 500
-// error if the marshalling failed.
+// error if the marshalling failed. Relations declared on Resource
+// responses are rendered as fully-qualified links
 func XMLResponseFunc(response interface{}, c *Context) {
+	if res, ok := response.(*Resource); ok {
+		res.resolve(c)
+	}
 	if marshalled, err := xml.Marshal(response); err != nil {
 		c.Response.WriteHeader(500)
 		fmt.Fprint(c.Response, "Could not marshal response as XML")
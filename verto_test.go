@@ -0,0 +1,61 @@
+package verto
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestVertoRemove(t *testing.T) {
+	defer func() {
+		err := recover()
+		if err != nil {
+			t.Errorf(err.(error).Error())
+		}
+	}()
+
+	err := "Failed verto remove."
+
+	v := New()
+	v.Logger = &NilLogger{}
+	rf := func(c *Context) (interface{}, error) {
+		return "ok", nil
+	}
+	status := func(method, path string) int {
+		r, _ := http.NewRequest(method, "http://test.com"+path, nil)
+		w := httptest.NewRecorder()
+		(&HttpHandler{v}).ServeHTTP(w, r)
+		return w.Code
+	}
+
+	ep := v.Get("/a", rf)
+	v.Get("/b/{id}", rf)
+	g := v.Group("GET", "/c")
+	g.Add("/d", rf)
+
+	// Test routes are removed by pattern, endpoint and group
+	if v.Remove("GET", "/b/1") || !v.Remove("GET", "/b/{id}") {
+		t.Errorf(err)
+	}
+	ep.Remove()
+	g.Remove()
+	for _, p := range []string{"/a", "/b/1", "/c/d"} {
+		if status("GET", p) != http.StatusNotFound {
+			t.Errorf(err)
+		}
+	}
+	if len(v.Routes()) != 0 {
+		t.Errorf(err)
+	}
+
+	// Test removals are audited
+	removed := 0
+	for _, entry := range v.Audit.Entries() {
+		if entry.Action == AuditRouteRemoved {
+			removed++
+		}
+	}
+	if removed != 3 {
+		t.Errorf(err)
+	}
+}
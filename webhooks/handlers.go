@@ -0,0 +1,146 @@
+package webhooks
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/boxtown/verto"
+	"github.com/boxtown/verto/mux"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// createRequest is the body of subscription creation requests
+type createRequest struct {
+	URL    string   `json:"url"`
+	Events []string `json:"events"`
+}
+
+// createResponse is the body of subscription creation responses
+type createResponse struct {
+	*Subscription
+
+	// Secret is the signing secret. It is only returned once
+	Secret string `json:"secret"`
+}
+
+// Register registers subscription management endpoints under prefix on v:
+//
+//	POST   prefix                              creates a subscription from {"url", "events"}
+//	GET    prefix                              lists subscriptions
+//	DELETE prefix/{id}                         deletes a subscription
+//	GET    prefix/dead-letters                 lists dead-lettered deliveries
+//	POST   prefix/dead-letters/{id}/redeliver  redelivers a dead-lettered delivery
+//
+// The endpoints should be protected, e.g. by admin groups with
+// authorization plugins covering prefix.
+func (d *Dispatcher) Register(v *verto.Verto, prefix string) {
+	prefix = strings.TrimRight(prefix, "/")
+	v.AddHandler("POST", prefix, http.HandlerFunc(d.create))
+	v.AddHandler("GET", prefix, http.HandlerFunc(d.list))
+	v.AddHandler("DELETE", prefix+"/{id}", http.HandlerFunc(d.delete))
+	v.AddHandler("GET", prefix+"/dead-letters", http.HandlerFunc(d.listDeadLetters))
+	v.AddHandler("POST", prefix+"/dead-letters/{id}/redeliver", http.HandlerFunc(d.redeliver))
+}
+
+// Subscribe creates an active subscription of url to events
+// with a newly generated secret
+func (d *Dispatcher) Subscribe(rawurl string, events ...string) (*Subscription, error) {
+	u, err := url.Parse(rawurl)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("webhooks: invalid subscription url %q", rawurl)
+	}
+	id, err := randomId()
+	if err != nil {
+		return nil, err
+	}
+	secret, err := randomId()
+	if err != nil {
+		return nil, err
+	}
+	s := &Subscription{
+		Id:      id,
+		URL:     u.String(),
+		Secret:  secret,
+		Events:  events,
+		Active:  true,
+		Created: time.Now().UTC(),
+	}
+	if err := d.Store.Create(s); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// create handles subscription creation requests
+func (d *Dispatcher) create(w http.ResponseWriter, r *http.Request) {
+	req := createRequest{}
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 64<<10)).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest)
+		return
+	}
+	s, err := d.Subscribe(req.URL, req.Events...)
+	if err != nil {
+		writeError(w, http.StatusBadRequest)
+		return
+	}
+	writeJSON(w, http.StatusCreated, createResponse{s, s.Secret})
+}
+
+// list handles subscription listing requests
+func (d *Dispatcher) list(w http.ResponseWriter, r *http.Request) {
+	subs, err := d.Store.List()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, subs)
+}
+
+// delete handles subscription deletion requests
+func (d *Dispatcher) delete(w http.ResponseWriter, r *http.Request) {
+	writeResult(w, d.Store.Delete(mux.Param(r, "id")))
+}
+
+// listDeadLetters handles dead letter listing requests
+func (d *Dispatcher) listDeadLetters(w http.ResponseWriter, r *http.Request) {
+	deliveries, err := d.DeadLetters.List()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, deliveries)
+}
+
+// redeliver handles redelivery requests
+func (d *Dispatcher) redeliver(w http.ResponseWriter, r *http.Request) {
+	writeResult(w, d.Redeliver(mux.Param(r, "id")))
+}
+
+// writes a 204 response or the error response for err
+func writeResult(w http.ResponseWriter, err error) {
+	switch err {
+	case nil:
+		w.WriteHeader(http.StatusNoContent)
+	case ErrNotFound:
+		writeError(w, http.StatusNotFound)
+	case ErrStopped, ErrQueueFull:
+		writeError(w, http.StatusServiceUnavailable)
+	default:
+		writeError(w, http.StatusInternalServerError)
+	}
+}
+
+// writes v as a JSON response with status
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+// writes a plain text error response with status
+func writeError(w http.ResponseWriter, status int) {
+	w.WriteHeader(status)
+	fmt.Fprint(w, http.StatusText(status)+".")
+}
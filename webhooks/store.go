@@ -0,0 +1,198 @@
+package webhooks
+
+import (
+	"errors"
+	"sort"
+	"sync"
+	"time"
+)
+
+// ErrNotFound is returned by stores if a subscription
+// or delivery does not exist
+var ErrNotFound = errors.New("webhooks: not found")
+
+// Subscription is a registered webhook endpoint
+type Subscription struct {
+	// Id is the identifier of the subscription
+	Id string `json:"id"`
+
+	// URL is the endpoint deliveries are POSTed to
+	URL string `json:"url"`
+
+	// Secret is the key deliveries are signed with
+	Secret string `json:"-"`
+
+	// Events lists the subscribed events. An empty list
+	// or the event '*' subscribes to all events
+	Events []string `json:"events"`
+
+	// Active is false if deliveries are paused
+	Active bool `json:"active"`
+
+	// Created is the time the subscription was created
+	Created time.Time `json:"created"`
+}
+
+// Matches returns whether the subscription receives event
+func (s *Subscription) Matches(event string) bool {
+	if len(s.Events) == 0 {
+		return true
+	}
+	for _, e := range s.Events {
+		if e == event || e == "*" {
+			return true
+		}
+	}
+	return false
+}
+
+// Store is the interface for subscription storage backends
+type Store interface {
+	// Create stores a new subscription
+	Create(s *Subscription) error
+
+	// Get returns the subscription with id or ErrNotFound
+	Get(id string) (*Subscription, error)
+
+	// List returns all subscriptions
+	List() ([]*Subscription, error)
+
+	// Delete deletes the subscription with id or returns ErrNotFound
+	Delete(id string) error
+}
+
+// DeadLetterStore is the interface for storing deliveries
+// that exhausted their delivery attempts
+type DeadLetterStore interface {
+	// Add stores a failed delivery
+	Add(d *Delivery) error
+
+	// List returns all failed deliveries
+	List() ([]*Delivery, error)
+
+	// Remove removes and returns the failed delivery
+	// with id or returns ErrNotFound
+	Remove(id string) (*Delivery, error)
+}
+
+// MemoryStore is an in-memory Store. MemoryStore is thread-safe
+type MemoryStore struct {
+	subs  map[string]*Subscription
+	mutex *sync.RWMutex
+}
+
+// NewMemoryStore returns an empty MemoryStore
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		subs:  make(map[string]*Subscription),
+		mutex: &sync.RWMutex{},
+	}
+}
+
+// Create stores a copy of s
+func (ms *MemoryStore) Create(s *Subscription) error {
+	ms.mutex.Lock()
+	defer ms.mutex.Unlock()
+
+	c := *s
+	ms.subs[c.Id] = &c
+	return nil
+}
+
+// Get returns a copy of the subscription with id
+func (ms *MemoryStore) Get(id string) (*Subscription, error) {
+	ms.mutex.RLock()
+	defer ms.mutex.RUnlock()
+
+	s, ok := ms.subs[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	c := *s
+	return &c, nil
+}
+
+// List returns copies of all subscriptions ordered by creation time
+func (ms *MemoryStore) List() ([]*Subscription, error) {
+	ms.mutex.RLock()
+	defer ms.mutex.RUnlock()
+
+	subs := make([]*Subscription, 0, len(ms.subs))
+	for _, s := range ms.subs {
+		c := *s
+		subs = append(subs, &c)
+	}
+	sort.Slice(subs, func(i, j int) bool {
+		return subs[i].Created.Before(subs[j].Created)
+	})
+	return subs, nil
+}
+
+// Delete deletes the subscription with id
+func (ms *MemoryStore) Delete(id string) error {
+	ms.mutex.Lock()
+	defer ms.mutex.Unlock()
+
+	if _, ok := ms.subs[id]; !ok {
+		return ErrNotFound
+	}
+	delete(ms.subs, id)
+	return nil
+}
+
+// MemoryDeadLetters is an in-memory DeadLetterStore that retains
+// a bounded number of deliveries. MemoryDeadLetters is thread-safe
+type MemoryDeadLetters struct {
+	// Max is the maximum number of retained deliveries.
+	// Once reached, the oldest deliveries are discarded.
+	// Defaults to 1000
+	Max int
+
+	deliveries []*Delivery
+	mutex      *sync.Mutex
+}
+
+// NewMemoryDeadLetters returns an empty MemoryDeadLetters
+func NewMemoryDeadLetters() *MemoryDeadLetters {
+	return &MemoryDeadLetters{
+		Max:        1000,
+		deliveries: make([]*Delivery, 0),
+		mutex:      &sync.Mutex{},
+	}
+}
+
+// Add stores d
+func (dl *MemoryDeadLetters) Add(d *Delivery) error {
+	dl.mutex.Lock()
+	defer dl.mutex.Unlock()
+
+	dl.deliveries = append(dl.deliveries, d)
+	if dl.Max > 0 && len(dl.deliveries) > dl.Max {
+		dl.deliveries = dl.deliveries[len(dl.deliveries)-dl.Max:]
+	}
+	return nil
+}
+
+// List returns all stored deliveries in the order they failed
+func (dl *MemoryDeadLetters) List() ([]*Delivery, error) {
+	dl.mutex.Lock()
+	defer dl.mutex.Unlock()
+
+	list := make([]*Delivery, len(dl.deliveries))
+	copy(list, dl.deliveries)
+	return list, nil
+}
+
+// Remove removes and returns the delivery with id
+func (dl *MemoryDeadLetters) Remove(id string) (*Delivery, error) {
+	dl.mutex.Lock()
+	defer dl.mutex.Unlock()
+
+	for i, d := range dl.deliveries {
+		if d.Id == id {
+			dl.deliveries = append(dl.deliveries[:i], dl.deliveries[i+1:]...)
+			return d, nil
+		}
+	}
+	return nil, ErrNotFound
+}
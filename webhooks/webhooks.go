@@ -0,0 +1,422 @@
+// Package webhooks provides webhook delivery for Verto. Subscribers
+// register URLs for event topics, events published on the Verto event
+// bus are delivered as HMAC-signed JSON POST requests with retries and
+// exponential backoff, and deliveries that keep failing are moved to a
+// dead letter store from which they can be inspected and redelivered.
+package webhooks
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/boxtown/verto"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Headers set on webhook deliveries
+const (
+	HeaderId        = "X-Webhook-Id"
+	HeaderEvent     = "X-Webhook-Event"
+	HeaderTimestamp = "X-Webhook-Timestamp"
+	HeaderSignature = "X-Webhook-Signature"
+)
+
+// ErrStopped is returned by Emit if the Dispatcher has been stopped
+var ErrStopped = errors.New("webhooks: dispatcher stopped")
+
+// ErrQueueFull is returned by Emit and Redeliver if the
+// delivery queue is full
+var ErrQueueFull = errors.New("webhooks: delivery queue full")
+
+// Payload is the JSON body of webhook deliveries
+type Payload struct {
+	Id    string      `json:"id"`
+	Event string      `json:"event"`
+	Time  time.Time   `json:"time"`
+	Data  interface{} `json:"data"`
+}
+
+// Delivery is a single webhook delivery to a subscription
+type Delivery struct {
+	Id             string    `json:"id"`
+	SubscriptionId string    `json:"subscription_id"`
+	URL            string    `json:"url"`
+	Event          string    `json:"event"`
+	Body           []byte    `json:"body"`
+	Attempts       int       `json:"attempts"`
+	LastAttempt    time.Time `json:"last_attempt"`
+	LastError      string    `json:"last_error"`
+
+	secret string
+}
+
+// Dispatcher delivers events to subscribed webhook endpoints.
+//
+// Example usage:
+//
+//	d := webhooks.New(webhooks.NewMemoryStore())
+//	d.Listen(v.Events, "order.created", "order.shipped")
+//	d.Register(v, "/admin/webhooks")
+//	d.Start()
+//	defer d.Stop()
+//
+//	v.Events.Publish("order.created", order)
+type Dispatcher struct {
+	// Store holds subscriptions
+	Store Store
+
+	// DeadLetters holds deliveries that exhausted their attempts
+	DeadLetters DeadLetterStore
+
+	// Client is the HTTP client used for deliveries.
+	// Defaults to a client with a 10 second timeout
+	Client *http.Client
+
+	// MaxAttempts is the number of delivery attempts before a
+	// delivery is dead-lettered. Defaults to 5
+	MaxAttempts int
+
+	// Backoff returns the delay before retry attempt n (1-based).
+	// Defaults to exponential backoff starting at one second and
+	// capped at one hour
+	Backoff func(n int) time.Duration
+
+	// Workers is the number of concurrent delivery workers.
+	// Defaults to 4
+	Workers int
+
+	// Logger is an optional logger for delivery failures
+	Logger verto.Logger
+
+	queue    chan *Delivery
+	quit     chan struct{}
+	wg       *sync.WaitGroup
+	mutex    *sync.Mutex
+	started  bool
+	stopped  bool
+	inflight *sync.WaitGroup
+}
+
+// New returns a Dispatcher for subscriptions in store with
+// an in-memory dead letter store
+func New(store Store) *Dispatcher {
+	return &Dispatcher{
+		Store:       store,
+		DeadLetters: NewMemoryDeadLetters(),
+		queue:       make(chan *Delivery, 1024),
+		quit:        make(chan struct{}),
+		wg:          &sync.WaitGroup{},
+		mutex:       &sync.Mutex{},
+		inflight:    &sync.WaitGroup{},
+	}
+}
+
+// Listen subscribes the Dispatcher to topics on bus. If no topics
+// are given, all events are delivered. Returns a function that
+// removes the subscriptions
+func (d *Dispatcher) Listen(bus *verto.EventBus, topics ...string) func() {
+	if len(topics) == 0 {
+		topics = []string{verto.AllEvents}
+	}
+	unsubscribes := make([]func(), len(topics))
+	for i, topic := range topics {
+		unsubscribes[i] = bus.Subscribe(topic, func(e verto.Event) {
+			if err := d.Emit(e.Topic, e.Data); err != nil && d.Logger != nil {
+				d.Logger.Errorf("webhooks: could not emit %s: %s", e.Topic, err.Error())
+			}
+		})
+	}
+	return func() {
+		for _, unsubscribe := range unsubscribes {
+			unsubscribe()
+		}
+	}
+}
+
+// Emit queues a delivery of event with data to every active
+// subscription of the event
+func (d *Dispatcher) Emit(event string, data interface{}) error {
+	subs, err := d.Store.List()
+	if err != nil {
+		return err
+	}
+
+	id, err := randomId()
+	if err != nil {
+		return err
+	}
+	body, err := json.Marshal(Payload{Id: id, Event: event, Time: time.Now().UTC(), Data: data})
+	if err != nil {
+		return err
+	}
+
+	var full error
+	for _, sub := range subs {
+		if !sub.Active || !sub.Matches(event) {
+			continue
+		}
+		did, err := randomId()
+		if err != nil {
+			return err
+		}
+		delivery := &Delivery{
+			Id:             did,
+			SubscriptionId: sub.Id,
+			URL:            sub.URL,
+			Event:          event,
+			Body:           body,
+			secret:         sub.Secret,
+		}
+		err = d.enqueue(delivery)
+		if err == ErrQueueFull {
+			// Dead-letter the delivery so that it can be redelivered
+			// and keep queueing deliveries to the other subscriptions
+			delivery.LastError = err.Error()
+			if d.DeadLetters != nil {
+				d.DeadLetters.Add(delivery)
+			}
+			full = err
+			continue
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return full
+}
+
+// Redeliver removes the dead-lettered delivery with id from the dead
+// letter store and queues it for delivery with a fresh attempt count.
+// The delivery is returned to the dead letter store if its subscription
+// can't be retrieved or the delivery can't be queued
+func (d *Dispatcher) Redeliver(id string) error {
+	delivery, err := d.DeadLetters.Remove(id)
+	if err != nil {
+		return err
+	}
+	sub, err := d.Store.Get(delivery.SubscriptionId)
+	if err == nil {
+		attempts := delivery.Attempts
+		delivery.Attempts = 0
+		delivery.URL = sub.URL
+		delivery.secret = sub.Secret
+		if err = d.enqueue(delivery); err != nil {
+			delivery.Attempts = attempts
+		}
+	}
+	if err != nil {
+		if aerr := d.DeadLetters.Add(delivery); aerr != nil && d.Logger != nil {
+			d.Logger.Errorf("webhooks: could not return delivery %s to the dead letters: %s",
+				delivery.Id, aerr.Error())
+		}
+		return err
+	}
+	return nil
+}
+
+// Start starts the delivery workers
+func (d *Dispatcher) Start() {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	if d.started {
+		return
+	}
+	d.started = true
+
+	workers := d.Workers
+	if workers <= 0 {
+		workers = 4
+	}
+	for i := 0; i < workers; i++ {
+		d.wg.Add(1)
+		go d.work()
+	}
+}
+
+// Stop stops accepting new events and waits for queued deliveries
+// and pending retries to complete or be dead-lettered
+func (d *Dispatcher) Stop() {
+	// Workers must run to drain queued deliveries
+	d.Start()
+
+	d.mutex.Lock()
+	if d.stopped {
+		d.mutex.Unlock()
+		return
+	}
+	d.stopped = true
+	d.mutex.Unlock()
+
+	// Pending retries sleep in goroutines, wake them so
+	// they dead-letter instead of waiting out their backoff
+	close(d.quit)
+	d.inflight.Wait()
+	close(d.queue)
+	d.wg.Wait()
+}
+
+// enqueue queues a delivery. Returns ErrQueueFull
+// instead of blocking if the queue is full
+func (d *Dispatcher) enqueue(delivery *Delivery) error {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	if d.stopped {
+		return ErrStopped
+	}
+	select {
+	case d.queue <- delivery:
+		d.inflight.Add(1)
+		return nil
+	default:
+		return ErrQueueFull
+	}
+}
+
+// work delivers queued deliveries until the queue is closed
+func (d *Dispatcher) work() {
+	defer d.wg.Done()
+	for delivery := range d.queue {
+		d.attempt(delivery)
+	}
+}
+
+// attempt makes a delivery attempt and schedules a retry
+// or dead-letters the delivery on failure
+func (d *Dispatcher) attempt(delivery *Delivery) {
+	delivery.Attempts++
+	delivery.LastAttempt = time.Now().UTC()
+	err := d.deliver(delivery)
+	if err == nil {
+		d.inflight.Done()
+		return
+	}
+	delivery.LastError = err.Error()
+
+	max := d.MaxAttempts
+	if max <= 0 {
+		max = 5
+	}
+	if delivery.Attempts >= max {
+		d.deadLetter(delivery)
+		return
+	}
+
+	go func() {
+		select {
+		case <-time.After(d.backoff(delivery.Attempts)):
+			d.queue <- delivery
+		case <-d.quit:
+			d.deadLetter(delivery)
+		}
+	}()
+}
+
+// deadLetter moves a failed delivery to the dead letter store
+func (d *Dispatcher) deadLetter(delivery *Delivery) {
+	defer d.inflight.Done()
+	if d.Logger != nil {
+		d.Logger.Warnf("webhooks: delivery %s to %s failed after %d attempts: %s",
+			delivery.Id, delivery.URL, delivery.Attempts, delivery.LastError)
+	}
+	if d.DeadLetters != nil {
+		d.DeadLetters.Add(delivery)
+	}
+}
+
+// deliver POSTs the delivery body to the subscription URL.
+// Any non-2xx response is considered a failure
+func (d *Dispatcher) deliver(delivery *Delivery) error {
+	req, err := http.NewRequest("POST", delivery.URL, bytes.NewReader(delivery.Body))
+	if err != nil {
+		return err
+	}
+	ts := time.Now().Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderId, delivery.Id)
+	req.Header.Set(HeaderEvent, delivery.Event)
+	req.Header.Set(HeaderTimestamp, strconv.FormatInt(ts, 10))
+	req.Header.Set(HeaderSignature, Sign(delivery.secret, ts, delivery.Body))
+
+	client := d.Client
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+	res, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	io.Copy(ioutil.Discard, io.LimitReader(res.Body, 64<<10))
+	res.Body.Close()
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return fmt.Errorf("webhooks: unexpected status %d", res.StatusCode)
+	}
+	return nil
+}
+
+// backoff returns the delay before retry attempt n
+func (d *Dispatcher) backoff(n int) time.Duration {
+	if d.Backoff != nil {
+		return d.Backoff(n)
+	}
+	delay := time.Second
+	for i := 1; i < n && delay < time.Hour; i++ {
+		delay *= 2
+	}
+	if delay > time.Hour {
+		delay = time.Hour
+	}
+	return delay
+}
+
+// Sign returns the signature of a delivery body sent at unix timestamp
+// ts signed with secret. The signature is the hex encoded HMAC-SHA256 of
+// "<ts>.<body>" prefixed with "sha256="
+func Sign(secret string, ts int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(ts, 10) + "."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify verifies the signature headers of a received delivery. Receivers
+// should reject deliveries whose timestamp is older than tolerance to
+// prevent replays. Verify reads and returns the request body
+func Verify(r *http.Request, secret string, tolerance time.Duration) ([]byte, bool) {
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		return nil, false
+	}
+	ts, err := strconv.ParseInt(r.Header.Get(HeaderTimestamp), 10, 64)
+	if err != nil {
+		return body, false
+	}
+	if tolerance > 0 {
+		age := time.Since(time.Unix(ts, 0))
+		if age > tolerance || age < -tolerance {
+			return body, false
+		}
+	}
+	expected := Sign(secret, ts, body)
+	return body, hmac.Equal([]byte(expected), []byte(r.Header.Get(HeaderSignature)))
+}
+
+// randomId returns a random 128-bit hex encoded id
+func randomId() (string, error) {
+	b := make([]byte, 16)
+	if _, err := io.ReadFull(rand.Reader, b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,285 @@
+package webhooks
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/boxtown/verto"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestDelivery(t *testing.T) {
+	defer func() {
+		err := recover()
+		if err != nil {
+			t.Errorf(err.(error).Error())
+		}
+	}()
+
+	err := "Failed delivery."
+
+	received := make(chan Payload, 1)
+	var secret string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, ok := Verify(r, secret, time.Minute)
+		if !ok {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		p := Payload{}
+		json.Unmarshal(body, &p)
+		received <- p
+	}))
+	defer srv.Close()
+
+	bus := verto.NewEventBus()
+	d := New(NewMemoryStore())
+	d.Listen(bus, "order.created")
+	d.Start()
+
+	sub, e := d.Subscribe(srv.URL, "order.created")
+	if e != nil {
+		t.Fatalf(err)
+	}
+	secret = sub.Secret
+
+	bus.Publish("order.ignored", 1)
+	bus.Publish("order.created", map[string]int{"id": 7})
+	select {
+	case p := <-received:
+		if p.Event != "order.created" || p.Data.(map[string]interface{})["id"] != float64(7) {
+			t.Errorf(err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf(err)
+	}
+	d.Stop()
+
+	if len(received) != 0 {
+		t.Errorf(err)
+	}
+	if d.Emit("order.created", nil) != ErrStopped {
+		t.Errorf(err)
+	}
+}
+
+func TestDeadLetters(t *testing.T) {
+	defer func() {
+		err := recover()
+		if err != nil {
+			t.Errorf(err.(error).Error())
+		}
+	}()
+
+	err := "Failed dead letters."
+
+	mutex := &sync.Mutex{}
+	attempts := 0
+	fail := true
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		attempts++
+		if fail {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer srv.Close()
+
+	d := New(NewMemoryStore())
+	d.MaxAttempts = 3
+	d.Backoff = func(n int) time.Duration { return time.Millisecond }
+	d.Start()
+
+	if _, e := d.Subscribe(srv.URL); e != nil {
+		t.Fatalf(err)
+	}
+	d.Emit("ping", nil)
+
+	var dead []*Delivery
+	for i := 0; i < 500; i++ {
+		dead, _ = d.DeadLetters.List()
+		if len(dead) > 0 {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if len(dead) != 1 || dead[0].Attempts != 3 || attempts != 3 {
+		t.Fatalf(err)
+	}
+
+	// Test redelivery through management endpoints
+	mutex.Lock()
+	fail = false
+	mutex.Unlock()
+
+	v := verto.New()
+	v.Logger = &verto.NilLogger{}
+	d.Register(v, "/webhooks")
+	h := &verto.HttpHandler{v}
+
+	w := httptest.NewRecorder()
+	r, _ := http.NewRequest("POST", "http://test.com/webhooks/dead-letters/"+dead[0].Id+"/redeliver", nil)
+	h.ServeHTTP(w, r)
+	if w.Code != http.StatusNoContent {
+		t.Errorf(err)
+	}
+	d.Stop()
+
+	dead, _ = d.DeadLetters.List()
+	if len(dead) != 0 || attempts != 4 {
+		t.Errorf(err)
+	}
+}
+
+func TestRedeliverMissingSubscription(t *testing.T) {
+	defer func() {
+		err := recover()
+		if err != nil {
+			t.Errorf(err.(error).Error())
+		}
+	}()
+
+	err := "Failed redeliver missing subscription."
+
+	d := New(NewMemoryStore())
+	d.DeadLetters.Add(&Delivery{Id: "d1", SubscriptionId: "missing", Attempts: 5})
+
+	// Test failed redeliveries stay dead-lettered
+	if d.Redeliver("d1") != ErrNotFound {
+		t.Errorf(err)
+	}
+	dead, _ := d.DeadLetters.List()
+	if len(dead) != 1 || dead[0].Id != "d1" || dead[0].Attempts != 5 {
+		t.Errorf(err)
+	}
+}
+
+func TestQueueFull(t *testing.T) {
+	defer func() {
+		err := recover()
+		if err != nil {
+			t.Errorf(err.(error).Error())
+		}
+	}()
+
+	err := "Failed queue full."
+
+	d := New(NewMemoryStore())
+	d.queue = make(chan *Delivery, 1)
+	sub, e := d.Subscribe("http://test.com/hook")
+	if e != nil {
+		t.Fatalf(err)
+	}
+
+	// Test emitting before Start fills the queue instead of blocking
+	if d.Emit("ping", nil) != nil || d.Emit("ping", nil) != ErrQueueFull {
+		t.Errorf(err)
+	}
+	dead, _ := d.DeadLetters.List()
+	if len(dead) != 1 || dead[0].SubscriptionId != sub.Id {
+		t.Fatalf(err)
+	}
+
+	// Test redeliveries into a full queue stay dead-lettered
+	if d.Redeliver(dead[0].Id) != ErrQueueFull {
+		t.Errorf(err)
+	}
+	if dead, _ = d.DeadLetters.List(); len(dead) != 1 {
+		t.Errorf(err)
+	}
+}
+
+func TestRegister(t *testing.T) {
+	defer func() {
+		err := recover()
+		if err != nil {
+			t.Errorf(err.(error).Error())
+		}
+	}()
+
+	err := "Failed register."
+
+	d := New(NewMemoryStore())
+	v := verto.New()
+	v.Logger = &verto.NilLogger{}
+	d.Register(v, "/webhooks")
+	h := &verto.HttpHandler{v}
+
+	w := httptest.NewRecorder()
+	r, _ := http.NewRequest("POST", "http://test.com/webhooks",
+		bytes.NewBufferString(`{"url":"https://example.com/hook","events":["a"]}`))
+	h.ServeHTTP(w, r)
+	created := map[string]interface{}{}
+	json.Unmarshal(w.Body.Bytes(), &created)
+	if w.Code != http.StatusCreated || created["secret"] == "" || created["id"] == nil {
+		t.Fatalf(err)
+	}
+
+	w = httptest.NewRecorder()
+	r, _ = http.NewRequest("POST", "http://test.com/webhooks", bytes.NewBufferString(`{"url":"ftp://x"}`))
+	h.ServeHTTP(w, r)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf(err)
+	}
+
+	w = httptest.NewRecorder()
+	r, _ = http.NewRequest("GET", "http://test.com/webhooks", nil)
+	h.ServeHTTP(w, r)
+	if w.Code != http.StatusOK || bytes.Contains(w.Body.Bytes(), []byte(created["secret"].(string))) {
+		t.Errorf(err)
+	}
+
+	w = httptest.NewRecorder()
+	r, _ = http.NewRequest("DELETE", "http://test.com/webhooks/"+created["id"].(string), nil)
+	h.ServeHTTP(w, r)
+	if w.Code != http.StatusNoContent {
+		t.Errorf(err)
+	}
+
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	if w.Code != http.StatusNotFound {
+		t.Errorf(err)
+	}
+}
+
+func TestSign(t *testing.T) {
+	defer func() {
+		err := recover()
+		if err != nil {
+			t.Errorf(err.(error).Error())
+		}
+	}()
+
+	err := "Failed sign."
+
+	body := []byte(`{"a":1}`)
+	ts := time.Now().Unix()
+	r, _ := http.NewRequest("POST", "http://test.com", bytes.NewReader(body))
+	r.Header.Set(HeaderTimestamp, strconv.FormatInt(ts, 10))
+	r.Header.Set(HeaderSignature, Sign("secret", ts, body))
+	if b, ok := Verify(r, "secret", time.Minute); !ok || string(b) != string(body) {
+		t.Errorf(err)
+	}
+
+	r, _ = http.NewRequest("POST", "http://test.com", bytes.NewReader(body))
+	r.Header.Set(HeaderTimestamp, strconv.FormatInt(ts, 10))
+	r.Header.Set(HeaderSignature, Sign("other", ts, body))
+	if _, ok := Verify(r, "secret", time.Minute); ok {
+		t.Errorf(err)
+	}
+
+	// Test replay tolerance
+	old := ts - 3600
+	r, _ = http.NewRequest("POST", "http://test.com", bytes.NewReader(body))
+	r.Header.Set(HeaderTimestamp, strconv.FormatInt(old, 10))
+	r.Header.Set(HeaderSignature, Sign("secret", old, body))
+	if _, ok := Verify(r, "secret", time.Minute); ok {
+		t.Errorf(err)
+	}
+}
@@ -0,0 +1,170 @@
+package verto
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/base64"
+	"errors"
+	"net"
+	"net/http"
+	"strings"
+)
+
+// ErrNotHijackable is returned if no ResponseWriter
+// in a chain of wrapping writers supports hijacking
+var ErrNotHijackable = errors.New("verto: response writer does not support hijacking")
+
+// webSocketGUID is the GUID appended to the client
+// key to compute the accept key (RFC 6455, section 1.3)
+const webSocketGUID = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+
+// WebSocketConn is a connection upgraded to the WebSocket protocol.
+// Verto performs the opening handshake only; frames are read from
+// and written to the connection with a WebSocket framing library
+type WebSocketConn struct {
+	// Conn is the hijacked network connection
+	net.Conn
+
+	// Buffered holds the buffered reader and writer of the
+	// connection. Data the client sent after the handshake may
+	// already be buffered, so reads should go through it
+	Buffered *bufio.ReadWriter
+
+	// Protocol is the negotiated subprotocol or an empty
+	// string if none was negotiated
+	Protocol string
+}
+
+// WebSocketFunc handles a connection upgraded by Verto.WebSocket.
+// The connection is closed when the function returns
+type WebSocketFunc func(c *Context, conn *WebSocketConn)
+
+// Hijack hijacks the connection of w. Wrapping ResponseWriters are
+// unwrapped through their Unwrap method until one implementing
+// http.Hijacker is found. ErrNotHijackable is returned if none is
+func Hijack(w http.ResponseWriter) (net.Conn, *bufio.ReadWriter, error) {
+	for {
+		switch rw := w.(type) {
+		case http.Hijacker:
+			return rw.Hijack()
+		case interface{ Unwrap() http.ResponseWriter }:
+			w = rw.Unwrap()
+		default:
+			return nil, nil, ErrNotHijackable
+		}
+	}
+}
+
+// IsWebSocketRequest returns whether r asks for
+// an upgrade to the WebSocket protocol
+func IsWebSocketRequest(r *http.Request) bool {
+	return headerHasToken(r.Header, "Connection", "upgrade") &&
+		headerHasToken(r.Header, "Upgrade", "websocket")
+}
+
+// Upgrade performs the WebSocket opening handshake for the request and
+// hijacks its connection. If protocols are given, the first of them
+// offered by the client is negotiated. Requests that are not valid
+// WebSocket handshakes result in a 400 HTTPError and unsupported
+// protocol versions in a 426 HTTPError. After a successful upgrade
+// nothing may be written to the Context's Response.
+//
+// Example usage:
+//
+//	v.Get("/ws", func(c *verto.Context) (interface{}, error) {
+//		conn, err := c.Upgrade("chat")
+//		if err != nil {
+//			return nil, err
+//		}
+//		defer conn.Close()
+//		...
+//		return nil, nil
+//	})
+func (c *Context) Upgrade(protocols ...string) (*WebSocketConn, error) {
+	r := c.Request
+	if r.Method != "GET" || !IsWebSocketRequest(r) {
+		return nil, NewError(http.StatusBadRequest, "Not a WebSocket handshake.")
+	}
+	if r.Header.Get("Sec-WebSocket-Version") != "13" {
+		c.Response.Header().Set("Sec-WebSocket-Version", "13")
+		return nil, NewError(http.StatusUpgradeRequired, "Unsupported WebSocket version.")
+	}
+	key := r.Header.Get("Sec-WebSocket-Key")
+	if decoded, err := base64.StdEncoding.DecodeString(key); err != nil || len(decoded) != 16 {
+		return nil, NewError(http.StatusBadRequest, "Invalid WebSocket key.")
+	}
+
+	protocol := negotiateProtocol(r, protocols)
+	conn, rw, err := Hijack(c.Response)
+	if err != nil {
+		return nil, err
+	}
+
+	rw.WriteString("HTTP/1.1 101 Switching Protocols\r\n")
+	rw.WriteString("Upgrade: websocket\r\n")
+	rw.WriteString("Connection: Upgrade\r\n")
+	rw.WriteString("Sec-WebSocket-Accept: " + acceptKey(key) + "\r\n")
+	if protocol != "" {
+		rw.WriteString("Sec-WebSocket-Protocol: " + protocol + "\r\n")
+	}
+	rw.WriteString("\r\n")
+	if err := rw.Flush(); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return &WebSocketConn{Conn: conn, Buffered: rw, Protocol: protocol}, nil
+}
+
+// WebSocket registers a GET endpoint at path upgrading requests to
+// the WebSocket protocol and passing the connection to fn. Protocols
+// are negotiated as with Context.Upgrade. Plugins run before the
+// upgrade so authentication and other plugins apply as usual.
+//
+// Example usage:
+//
+//	v.WebSocket("/ws", func(c *verto.Context, conn *verto.WebSocketConn) {
+//		...
+//	}).Use(auth)
+func (v *Verto) WebSocket(path string, fn WebSocketFunc, protocols ...string) *Endpoint {
+	return v.Get(path, func(c *Context) (interface{}, error) {
+		conn, err := c.Upgrade(protocols...)
+		if err != nil {
+			return nil, err
+		}
+		defer conn.Close()
+
+		fn(c, conn)
+		return nil, nil
+	})
+}
+
+// acceptKey returns the Sec-WebSocket-Accept value for key
+func acceptKey(key string) string {
+	h := sha1.New()
+	h.Write([]byte(key + webSocketGUID))
+	return base64.StdEncoding.EncodeToString(h.Sum(nil))
+}
+
+// negotiateProtocol returns the first of protocols
+// offered by the client of r or an empty string
+func negotiateProtocol(r *http.Request, protocols []string) string {
+	for _, p := range protocols {
+		if headerHasToken(r.Header, "Sec-WebSocket-Protocol", p) {
+			return p
+		}
+	}
+	return ""
+}
+
+// headerHasToken returns whether the comma separated
+// values of header name contain token, ignoring case
+func headerHasToken(h http.Header, name, token string) bool {
+	for _, value := range h[http.CanonicalHeaderKey(name)] {
+		for _, t := range strings.Split(value, ",") {
+			if strings.EqualFold(strings.TrimSpace(t), token) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,76 @@
+package verto
+
+import (
+	"bufio"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestWebSocket(t *testing.T) {
+	defer func() {
+		err := recover()
+		if err != nil {
+			t.Errorf(err.(error).Error())
+		}
+	}()
+
+	err := "Failed websocket."
+
+	v := New()
+	v.Logger = &NilLogger{}
+	v.WebSocket("/ws", func(c *Context, conn *WebSocketConn) {
+		line, _ := conn.Buffered.ReadString('\n')
+		conn.Write([]byte(conn.Protocol + ":" + line))
+	}, "chat", "superchat")
+	s := httptest.NewServer(&HttpHandler{v})
+	defer s.Close()
+
+	// Test the RFC 6455 handshake example and raw traffic after it
+	conn, _ := net.Dial("tcp", strings.TrimPrefix(s.URL, "http://"))
+	defer conn.Close()
+	conn.Write([]byte("GET /ws HTTP/1.1\r\nHost: test.com\r\n" +
+		"Upgrade: websocket\r\nConnection: keep-alive, Upgrade\r\n" +
+		"Sec-WebSocket-Key: dGhlIHNhbXBsZSBub25jZQ==\r\n" +
+		"Sec-WebSocket-Version: 13\r\nSec-WebSocket-Protocol: superchat, chat\r\n\r\nhello\n"))
+	br := bufio.NewReader(conn)
+	resp, _ := http.ReadResponse(br, nil)
+	if resp == nil || resp.StatusCode != http.StatusSwitchingProtocols {
+		t.Fatalf(err)
+	}
+	if resp.Header.Get("Sec-WebSocket-Accept") != "s3pPLMBiTxaQ9kYGzzhZRbK+xOo=" ||
+		resp.Header.Get("Sec-WebSocket-Protocol") != "chat" {
+		t.Errorf(err)
+	}
+	if line, _ := br.ReadString('\n'); line != "chat:hello\n" {
+		t.Errorf(err)
+	}
+
+	// Test invalid handshakes
+	serve := func(header http.Header) *httptest.ResponseRecorder {
+		r, _ := http.NewRequest("GET", "http://test.com/ws", nil)
+		r.Header = header
+		w := httptest.NewRecorder()
+		(&HttpHandler{v}).ServeHTTP(w, r)
+		return w
+	}
+	if serve(http.Header{}).Code != http.StatusBadRequest {
+		t.Errorf(err)
+	}
+	header := http.Header{"Connection": {"Upgrade"}, "Upgrade": {"websocket"}, "Sec-WebSocket-Version": {"8"}}
+	if w := serve(header); w.Code != http.StatusUpgradeRequired || w.Header().Get("Sec-WebSocket-Version") != "13" {
+		t.Errorf(err)
+	}
+	header.Set("Sec-WebSocket-Version", "13")
+	header.Set("Sec-WebSocket-Key", "short")
+	if serve(header).Code != http.StatusBadRequest {
+		t.Errorf(err)
+	}
+
+	// Test hijacking through wrapping writers
+	if _, _, e := Hijack(&retryWriter{ResponseWriter: httptest.NewRecorder()}); e != ErrNotHijackable {
+		t.Errorf(err)
+	}
+}
@@ -0,0 +1,230 @@
+package verto
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"runtime"
+	"sync"
+	"sync/atomic"
+)
+
+// ErrPoolFull is returned for tasks submitted to a
+// WorkerPool whose queue is full
+var ErrPoolFull = errors.New("verto: worker pool queue is full")
+
+// ErrPoolClosed is returned for tasks submitted
+// to a closed WorkerPool
+var ErrPoolClosed = errors.New("verto: worker pool is closed")
+
+// DefaultWorkerQueue is the queue length of the
+// WorkerPool created by Verto.Workers
+const DefaultWorkerQueue = 64
+
+// WorkerPoolStats are the queue metrics of a WorkerPool
+type WorkerPoolStats struct {
+	Workers   int   `json:"workers"`
+	Capacity  int   `json:"capacity"`
+	Queued    int   `json:"queued"`
+	Active    int64 `json:"active"`
+	Completed int64 `json:"completed"`
+	Rejected  int64 `json:"rejected"`
+}
+
+// WorkerPool runs tasks on a fixed number of goroutines fed by a bounded
+// queue. It lets handlers offload CPU-heavy work (e.g. image resizing) without
+// spawning a goroutine per request. Tasks submitted while the queue is full
+// are rejected with ErrPoolFull. Close drains queued tasks. WorkerPool is
+// thread-safe
+type WorkerPool struct {
+	workers   int
+	queue     chan func()
+	active    int64
+	completed int64
+	rejected  int64
+	closed    bool
+	wg        *sync.WaitGroup
+	mutex     *sync.RWMutex
+}
+
+// NewWorkerPool returns a running WorkerPool with workers
+// goroutines and room for queue waiting tasks
+func NewWorkerPool(workers, queue int) *WorkerPool {
+	if workers <= 0 {
+		panic("NewWorkerPool: workers must be positive.")
+	}
+	if queue < 0 {
+		queue = 0
+	}
+	pool := &WorkerPool{
+		workers: workers,
+		queue:   make(chan func(), queue),
+		wg:      &sync.WaitGroup{},
+		mutex:   &sync.RWMutex{},
+	}
+	pool.wg.Add(workers)
+	for i := 0; i < workers; i++ {
+		go pool.work()
+	}
+	return pool
+}
+
+// Go queues task to run on the pool without waiting for it
+func (pool *WorkerPool) Go(task func()) error {
+	pool.mutex.RLock()
+	defer pool.mutex.RUnlock()
+
+	if pool.closed {
+		return ErrPoolClosed
+	}
+	select {
+	case pool.queue <- task:
+		return nil
+	default:
+		atomic.AddInt64(&pool.rejected, 1)
+		return ErrPoolFull
+	}
+}
+
+// Submit queues task to run on the pool and waits for it to complete,
+// returning its error. If ctx is done first, Submit returns the context's
+// error. ctx is passed to task so it can abandon work no longer awaited
+func (pool *WorkerPool) Submit(ctx context.Context, task func(ctx context.Context) error) error {
+	done := make(chan error, 1)
+	err := pool.Go(func() {
+		if ctx.Err() != nil {
+			done <- ctx.Err()
+			return
+		}
+		done <- task(ctx)
+	})
+	if err != nil {
+		return err
+	}
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Stats returns the current queue metrics of the pool
+func (pool *WorkerPool) Stats() WorkerPoolStats {
+	return WorkerPoolStats{
+		Workers:   pool.workers,
+		Capacity:  cap(pool.queue),
+		Queued:    len(pool.queue),
+		Active:    atomic.LoadInt64(&pool.active),
+		Completed: atomic.LoadInt64(&pool.completed),
+		Rejected:  atomic.LoadInt64(&pool.rejected),
+	}
+}
+
+// Close stops accepting tasks and waits until queued and running
+// tasks are completed or ctx is done, returning the context's error
+// in the latter case
+func (pool *WorkerPool) Close(ctx context.Context) error {
+	pool.mutex.Lock()
+	if !pool.closed {
+		pool.closed = true
+		close(pool.queue)
+	}
+	pool.mutex.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		pool.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// work runs queued tasks until the queue is closed and drained
+func (pool *WorkerPool) work() {
+	defer pool.wg.Done()
+	for task := range pool.queue {
+		atomic.AddInt64(&pool.active, 1)
+		task()
+		atomic.AddInt64(&pool.active, -1)
+		atomic.AddInt64(&pool.completed, 1)
+	}
+}
+
+// SetWorkers replaces Verto's WorkerPool with a pool of workers
+// goroutines and room for queue waiting tasks. A previous
+// pool is closed after its queued tasks are completed
+func (v *Verto) SetWorkers(workers, queue int) *WorkerPool {
+	pool := NewWorkerPool(workers, queue)
+
+	v.mutex.Lock()
+	previous := v.workers
+	v.workers = pool
+	v.mutex.Unlock()
+
+	if previous != nil {
+		go previous.Close(context.Background())
+	}
+	return pool
+}
+
+// Workers returns Verto's WorkerPool, creating a pool with one worker
+// per CPU and DefaultWorkerQueue queued tasks if none is set. The pool
+// is drained when the server run by Run or RunOn shuts down
+func (v *Verto) Workers() *WorkerPool {
+	if v.parent != nil {
+		return v.parent.Workers()
+	}
+
+	v.mutex.Lock()
+	defer v.mutex.Unlock()
+
+	if v.workers == nil {
+		v.workers = NewWorkerPool(runtime.NumCPU(), DefaultWorkerQueue)
+	}
+	return v.workers
+}
+
+// drainWorkers closes Verto's WorkerPool if one was
+// created, waiting for queued tasks to complete
+func (v *Verto) drainWorkers() {
+	v.mutex.RLock()
+	pool := v.workers
+	v.mutex.RUnlock()
+
+	if pool != nil {
+		pool.Close(context.Background())
+	}
+}
+
+// Offload runs task on Verto's WorkerPool and waits for it to complete.
+// task receives the request's context, which is canceled if the client
+// goes away. Offload returns a 503 HTTPError if the pool's queue is full.
+//
+// Example usage:
+//
+//	v.Post("/thumbnails", func(c *verto.Context) (interface{}, error) {
+//		var thumb []byte
+//		err := c.Offload(func(ctx context.Context) (err error) {
+//			thumb, err = resize(ctx, c.Request.Body)
+//			return
+//		})
+//		return thumb, err
+//	})
+func (c *Context) Offload(task func(ctx context.Context) error) error {
+	ctx := c.Request.Context()
+	if c.v == nil {
+		return task(ctx)
+	}
+	err := c.v.Workers().Submit(ctx, task)
+	if err == ErrPoolFull || err == ErrPoolClosed {
+		return &HTTPError{Status: http.StatusServiceUnavailable, Message: err.Error(), Err: err}
+	}
+	return err
+}
@@ -0,0 +1,105 @@
+package verto
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestWorkerPool(t *testing.T) {
+	defer func() {
+		err := recover()
+		if err != nil {
+			t.Errorf(err.(error).Error())
+		}
+	}()
+
+	err := "Failed worker pool."
+
+	pool := NewWorkerPool(1, 1)
+	release := make(chan struct{})
+	started := make(chan struct{})
+	if pool.Go(func() { close(started); <-release }) != nil {
+		t.Errorf(err)
+	}
+	<-started
+
+	// Test queue bound
+	var ran int32
+	if pool.Go(func() { atomic.AddInt32(&ran, 1) }) != nil {
+		t.Errorf(err)
+	}
+	if pool.Go(func() {}) != ErrPoolFull {
+		t.Errorf(err)
+	}
+	stats := pool.Stats()
+	if stats.Workers != 1 || stats.Capacity != 1 || stats.Queued != 1 || stats.Active != 1 || stats.Rejected != 1 {
+		t.Errorf(err)
+	}
+
+	// Test submitters stop waiting when their context is done
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	close(release)
+	time.Sleep(10 * time.Millisecond)
+	if e := pool.Submit(ctx, func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}); e != context.DeadlineExceeded {
+		t.Errorf(err)
+	}
+
+	// Test close drains queued tasks
+	if pool.Close(context.Background()) != nil || atomic.LoadInt32(&ran) != 1 {
+		t.Errorf(err)
+	}
+	if pool.Go(func() {}) != ErrPoolClosed || pool.Stats().Completed != 3 {
+		t.Errorf(err)
+	}
+}
+
+func TestOffload(t *testing.T) {
+	defer func() {
+		err := recover()
+		if err != nil {
+			t.Errorf(err.(error).Error())
+		}
+	}()
+
+	err := "Failed offload."
+
+	v := New()
+	v.Logger = &NilLogger{}
+	pool := v.SetWorkers(2, 4)
+	defer pool.Close(context.Background())
+	if v.Workers() != pool {
+		t.Errorf(err)
+	}
+	v.Get("/work", func(c *Context) (interface{}, error) {
+		result := ""
+		e := c.Offload(func(ctx context.Context) error {
+			result = "done"
+			return nil
+		})
+		return result, e
+	})
+	h := &HttpHandler{v}
+
+	r, _ := http.NewRequest("GET", "http://test.com/work", nil)
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	if w.Code != 200 || w.Body.String() != "done" || pool.Stats().Completed != 1 {
+		t.Errorf(err)
+	}
+
+	// Test closed pools reject offloaded work
+	pool.Close(context.Background())
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	if w.Code != 503 {
+		t.Errorf(err)
+	}
+}